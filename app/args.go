@@ -0,0 +1,118 @@
+package app
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileLocation is a file path with the position where the cursor should start.
+type FileLocation struct {
+	Path    string
+	LineNum uint64 // Zero-indexed line number.
+	Col     uint64 // Zero-indexed column (grapheme clusters from the start of the line).
+}
+
+// ParseFileLocationArgs parses the positional command-line arguments into a file location.
+//
+// The path may end with a ":line" or ":line:col" suffix, which is how
+// compilers and grep report locations. If a file exists at the full path
+// (including the suffix), then the suffix is treated as part of the filename.
+//
+// A vim-style "+line" argument may appear either before or after the path,
+// and takes precedence over a line suffix in the path.
+//
+// Line and column numbers are one-indexed. If no line number is specified,
+// the cursor starts at defaultLineNum.
+func ParseFileLocationArgs(args []string, defaultLineNum uint64) (FileLocation, error) {
+	var path string
+	var plusLineNum *uint64
+	for _, arg := range args {
+		if len(arg) > 1 && arg[0] == '+' {
+			lineNum, err := parseOneIndexedNum(arg[1:])
+			if err != nil {
+				return FileLocation{}, errors.Wrapf(err, "invalid line number %q", arg)
+			}
+			plusLineNum = &lineNum
+			continue
+		}
+
+		if path != "" {
+			return FileLocation{}, errors.New("only one path may be specified")
+		}
+		path = arg
+	}
+
+	loc := splitLineAndColSuffix(path)
+	if plusLineNum != nil {
+		loc.LineNum, loc.Col = *plusLineNum, 0
+	} else if loc.Path == path {
+		loc.LineNum = defaultLineNum
+	}
+	return loc, nil
+}
+
+// splitLineAndColSuffix separates a ":line" or ":line:col" suffix from a path.
+// If a file exists at the full path, the path is returned unchanged.
+// Otherwise, prefer an interpretation where the file exists, falling back
+// to the longest valid suffix for a file that hasn't been created yet.
+func splitLineAndColSuffix(path string) FileLocation {
+	if fileExists(path) {
+		return FileLocation{Path: path}
+	}
+
+	var candidates []FileLocation
+	parts := strings.Split(path, ":")
+	n := len(parts)
+	if n >= 3 {
+		// <path>:<line>:<col>
+		prefix := strings.Join(parts[:n-2], ":")
+		lineNum, lineErr := parseOneIndexedNum(parts[n-2])
+		col, colErr := parseOneIndexedNum(parts[n-1])
+		if prefix != "" && lineErr == nil && colErr == nil {
+			candidates = append(candidates, FileLocation{Path: prefix, LineNum: lineNum, Col: col})
+		}
+	}
+
+	if n >= 2 {
+		// <path>:<line>
+		prefix := strings.Join(parts[:n-1], ":")
+		lineNum, err := parseOneIndexedNum(parts[n-1])
+		if prefix != "" && err == nil {
+			candidates = append(candidates, FileLocation{Path: prefix, LineNum: lineNum})
+		}
+	}
+
+	for _, c := range candidates {
+		if fileExists(c.Path) {
+			return c
+		}
+	}
+
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+
+	return FileLocation{Path: path}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// parseOneIndexedNum parses a one-indexed number and converts it to zero-indexed.
+func parseOneIndexedNum(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 1 {
+		return 0, errors.New("must be at least 1")
+	}
+
+	return n - 1, nil
+}
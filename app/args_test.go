@@ -0,0 +1,119 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileLocationArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	existingPathWithColon := filepath.Join(tmpDir, "foo:12")
+	err := os.WriteFile(existingPathWithColon, []byte("abc"), 0644)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		args           []string
+		defaultLineNum uint64
+		expected       FileLocation
+		expectErr      bool
+	}{
+		{
+			name:     "no args",
+			args:     nil,
+			expected: FileLocation{},
+		},
+		{
+			name:           "path only",
+			args:           []string{"foo.txt"},
+			defaultLineNum: 4,
+			expected:       FileLocation{Path: "foo.txt", LineNum: 4},
+		},
+		{
+			name:     "path with line",
+			args:     []string{"foo.txt:42"},
+			expected: FileLocation{Path: "foo.txt", LineNum: 41},
+		},
+		{
+			name:     "path with line and col",
+			args:     []string{"foo.txt:42:7"},
+			expected: FileLocation{Path: "foo.txt", LineNum: 41, Col: 6},
+		},
+		{
+			name:     "path with colon and line",
+			args:     []string{"foo:bar.txt:42"},
+			expected: FileLocation{Path: "foo:bar.txt", LineNum: 41},
+		},
+		{
+			name:     "path with colon that is not a line number",
+			args:     []string{"foo:bar.txt"},
+			expected: FileLocation{Path: "foo:bar.txt"},
+		},
+		{
+			name:     "path with trailing colon",
+			args:     []string{"foo.txt:"},
+			expected: FileLocation{Path: "foo.txt:"},
+		},
+		{
+			name:     "line number zero is part of path",
+			args:     []string{"foo.txt:0"},
+			expected: FileLocation{Path: "foo.txt:0"},
+		},
+		{
+			name:     "existing file with colon in name",
+			args:     []string{existingPathWithColon},
+			expected: FileLocation{Path: existingPathWithColon},
+		},
+		{
+			name:     "existing file with colon in name and line",
+			args:     []string{existingPathWithColon + ":3"},
+			expected: FileLocation{Path: existingPathWithColon, LineNum: 2},
+		},
+		{
+			name:     "plus line before path",
+			args:     []string{"+42", "foo.txt"},
+			expected: FileLocation{Path: "foo.txt", LineNum: 41},
+		},
+		{
+			name:     "plus line after path",
+			args:     []string{"foo.txt", "+42"},
+			expected: FileLocation{Path: "foo.txt", LineNum: 41},
+		},
+		{
+			name:     "plus line overrides path suffix",
+			args:     []string{"+3", "foo.txt:42:7"},
+			expected: FileLocation{Path: "foo.txt", LineNum: 2},
+		},
+		{
+			name:      "invalid plus line",
+			args:      []string{"+abc", "foo.txt"},
+			expectErr: true,
+		},
+		{
+			name:      "plus line zero",
+			args:      []string{"+0", "foo.txt"},
+			expectErr: true,
+		},
+		{
+			name:      "multiple paths",
+			args:      []string{"foo.txt", "bar.txt"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := ParseFileLocationArgs(tc.args, tc.defaultLineNum)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, loc)
+		})
+	}
+}
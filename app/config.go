@@ -9,7 +9,6 @@ import (
 
 	"github.com/adrg/xdg"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 
 	"github.com/aretext/aretext/config"
 )
@@ -26,7 +25,7 @@ func ConfigPath() (string, error) {
 func LoadOrCreateConfig(forceDefaultConfig bool) (config.RuleSet, error) {
 	if forceDefaultConfig {
 		log.Printf("Using default config\n")
-		return unmarshalRuleSet(DefaultConfigYaml)
+		return config.UnmarshalRuleSet(DefaultConfigYaml)
 	}
 
 	path, err := ConfigPath()
@@ -41,12 +40,12 @@ func LoadOrCreateConfig(forceDefaultConfig bool) (config.RuleSet, error) {
 		if err := saveDefaultConfig(path); err != nil {
 			return nil, errors.Wrapf(err, "Error writing default config to %q", path)
 		}
-		return unmarshalRuleSet(DefaultConfigYaml)
+		return config.UnmarshalRuleSet(DefaultConfigYaml)
 	} else if err != nil {
 		return nil, errors.Wrapf(err, "Error loading config from %q", path)
 	}
 
-	ruleSet, err := unmarshalRuleSet(data)
+	ruleSet, err := config.UnmarshalRuleSet(data)
 	if err != nil {
 		return nil, err
 	}
@@ -61,14 +60,6 @@ func LoadOrCreateConfig(forceDefaultConfig bool) (config.RuleSet, error) {
 	return ruleSet, nil
 }
 
-func unmarshalRuleSet(data []byte) (config.RuleSet, error) {
-	var rules []config.Rule
-	if err := yaml.Unmarshal(data, &rules); err != nil {
-		return nil, errors.Wrap(err, "yaml")
-	}
-	return config.RuleSet(rules), nil
-}
-
 func saveDefaultConfig(path string) error {
 	dirPath := filepath.Dir(path)
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
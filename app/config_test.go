@@ -5,10 +5,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
 )
 
 func TestDefaultConfigYamlValid(t *testing.T) {
-	rs, err := unmarshalRuleSet(DefaultConfigYaml)
+	rs, err := config.UnmarshalRuleSet(DefaultConfigYaml)
 	require.NoError(t, err)
 	assert.Greater(t, len(rs), 1)
 	require.NoError(t, rs.Validate())
@@ -3,6 +3,8 @@ package app
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"time"
 
@@ -16,29 +18,52 @@ import (
 	"github.com/aretext/aretext/state"
 )
 
+// termEventChanSize is the number of terminal events that can wait to be processed.
+// When the user pastes text or holds a key, events arrive faster than the editor redraws,
+// so the buffer must be large enough for the main loop to see that more events are pending
+// and process the whole burst before redrawing. Events stay in the order the terminal sent them,
+// because a single goroutine polls the screen and a single goroutine processes the events.
+const termEventChanSize = 1024
+
 // Editor is a terminal-based text editing program.
 type Editor struct {
 	inputInterpreter  *input.Interpreter
 	editorState       *state.EditorState
-	screen            tcell.Screen
+	screen            tcell.Screen // Nil if the editor is headless.
 	palette           *display.Palette
 	documentLoadCount int
 	termEventChan     chan tcell.Event
+	syncOnRedraw      bool
 }
 
 // NewEditor instantiates a new editor that uses the provided screen.
-func NewEditor(screen tcell.Screen, path string, lineNum uint64, configRuleSet config.RuleSet) *Editor {
+// If enableProjectConfig is true, rules from project config files apply after the rules in configRuleSet.
+func NewEditor(screen tcell.Screen, loc FileLocation, configRuleSet config.RuleSet, enableProjectConfig bool) *Editor {
 	screenWidth, screenHeight := screen.Size()
+	return newEditor(screen, screenWidth, screenHeight, suspendScreenFunc(screen), loc, configRuleSet, enableProjectConfig)
+}
+
+// NewHeadlessEditor instantiates a new editor without a screen.
+// The editor interprets input and updates its state as if it were displayed on a screen with the given size,
+// so it can be embedded in other programs or driven from tests with ProcessEvent and ProcessKeys.
+func NewHeadlessEditor(width, height int, loc FileLocation, configRuleSet config.RuleSet, enableProjectConfig bool) *Editor {
+	return newEditor(nil, width, height, nil, loc, configRuleSet, enableProjectConfig)
+}
+
+func newEditor(screen tcell.Screen, screenWidth, screenHeight int, suspendScreenFunc state.SuspendScreenFunc, loc FileLocation, configRuleSet config.RuleSet, enableProjectConfig bool) *Editor {
 	editorState := state.NewEditorState(
 		uint64(screenWidth),
 		uint64(screenHeight),
 		configRuleSet,
-		suspendScreenFunc(screen),
+		suspendScreenFunc,
 	)
+	if enableProjectConfig {
+		state.EnableProjectConfig(editorState)
+	}
 	inputInterpreter := input.NewInterpreter()
 	palette := display.NewPalette()
 	documentLoadCount := editorState.DocumentLoadCount()
-	termEventChan := make(chan tcell.Event, 1)
+	termEventChan := make(chan tcell.Event, termEventChanSize)
 	editor := &Editor{
 		inputInterpreter,
 		editorState,
@@ -46,6 +71,7 @@ func NewEditor(screen tcell.Screen, path string, lineNum uint64, configRuleSet c
 		palette,
 		documentLoadCount,
 		termEventChan,
+		false,
 	}
 
 	// Attempt to load the file.
@@ -53,12 +79,17 @@ func NewEditor(screen tcell.Screen, path string, lineNum uint64, configRuleSet c
 	// that the user can edit and save to the specified path.
 	state.LoadDocument(
 		editorState,
-		effectivePath(path),
+		effectivePath(loc.Path),
 		false,
 		func(p state.LocatorParams) uint64 {
-			return locate.StartOfLineNum(p.TextTree, lineNum)
+			lineNum := locate.ClosestValidLineNum(p.TextTree, loc.LineNum)
+			return locate.LineNumAndColToPos(p.TextTree, lineNum, loc.Col)
 		},
 	)
+	editor.handleIfDocumentLoaded()
+
+	// Discard events from the initial load, which happened before anyone could subscribe.
+	state.DispatchEvents(editorState)
 
 	return editor
 }
@@ -79,12 +110,28 @@ func effectivePath(path string) string {
 	return absPath
 }
 
+// LoadSession restores the documents from a session file.
+// If the session cannot be loaded, the editor shows an error and keeps the current document.
+func (e *Editor) LoadSession(path string) {
+	state.LoadSession(e.editorState, path)
+	e.handleIfDocumentLoaded()
+}
+
 // RunEventLoop processes events and draws to the screen, blocking until the user exits the program.
+// The editor must have a screen.
 func (e *Editor) RunEventLoop() {
+	// The terminal delivers ctrl-c as a key event while the screen is active,
+	// but while the screen is suspended for a shell command, ctrl-c sends SIGINT
+	// to the editor as well as the command. Trap the signal so it stops only the command.
+	interruptChan := make(chan os.Signal, 1)
+	signal.Notify(interruptChan, os.Interrupt)
+	defer signal.Stop(interruptChan)
+
 	e.redraw(true)
 	go e.pollTermEvents()
-	e.runMainEventLoop()
-	e.shutdown()
+	e.runMainEventLoop(interruptChan)
+	e.Close()
+	restoreDefaultCursorStyle(e.screen)
 }
 
 func (e *Editor) pollTermEvents() {
@@ -94,8 +141,25 @@ func (e *Editor) pollTermEvents() {
 	}
 }
 
-func (e *Editor) runMainEventLoop() {
+func (e *Editor) runMainEventLoop(interruptChan <-chan os.Signal) {
 	for {
+		// If the status message expires, wake up to clear it.
+		// The timer channel is nil (blocking forever) if the status message doesn't expire.
+		var statusMsgExpiredChan <-chan time.Time
+		var statusMsgTimer *time.Timer
+		if expireTime := e.editorState.StatusMsgExpireTime(); !expireTime.IsZero() {
+			statusMsgTimer = time.NewTimer(time.Until(expireTime))
+			statusMsgExpiredChan = statusMsgTimer.C
+		}
+
+		// Similarly, wake up to clear the highlight of copied text.
+		var yankHighlightExpiredChan <-chan time.Time
+		var yankHighlightTimer *time.Timer
+		if expireTime := e.editorState.YankHighlightExpireTime(); !expireTime.IsZero() {
+			yankHighlightTimer = time.NewTimer(time.Until(expireTime))
+			yankHighlightExpiredChan = yankHighlightTimer.C
+		}
+
 		select {
 		case event := <-e.termEventChan:
 			e.handleTermEvent(event)
@@ -106,9 +170,26 @@ func (e *Editor) runMainEventLoop() {
 
 		case <-e.editorState.FileWatcher().ChangedChan():
 			e.handleFileChanged()
+
+		case now := <-statusMsgExpiredChan:
+			state.ClearStatusMsgIfExpired(e.editorState, now)
+
+		case now := <-yankHighlightExpiredChan:
+			state.ClearYankHighlightIfExpired(e.editorState, now)
+
+		case <-interruptChan:
+			log.Printf("Ignoring interrupt signal\n")
+		}
+
+		if statusMsgTimer != nil {
+			statusMsgTimer.Stop()
+		}
+		if yankHighlightTimer != nil {
+			yankHighlightTimer.Stop()
 		}
 
 		e.handleIfDocumentLoaded()
+		state.DispatchEvents(e.editorState)
 
 		if e.editorState.QuitFlag() {
 			log.Printf("Quit flag set, exiting event loop...\n")
@@ -119,17 +200,65 @@ func (e *Editor) runMainEventLoop() {
 		// This helps avoid the overhead of redrawing after every keypress
 		// if the user pastes a lot of text into the terminal emulator.
 		if len(e.termEventChan) == 0 {
-			e.redraw(false)
+			e.beepIfRequested()
+			state.UpdateGitDiffIfStale(e.editorState)
+			e.redraw(e.syncOnRedraw)
+			e.syncOnRedraw = false
 		}
 	}
 }
 
 func (e *Editor) handleTermEvent(event tcell.Event) {
+	if _, ok := event.(*tcell.EventResize); ok {
+		// Redraw every cell after a resize, since the terminal may not preserve its previous contents.
+		e.syncOnRedraw = true
+	}
+
 	inputCtx := input.ContextFromEditorState(e.editorState)
 	actionFunc := e.inputInterpreter.ProcessEvent(event, inputCtx)
 	actionFunc(e.editorState)
 }
 
+// EditorState returns the state of the editor, which reflects every event processed so far.
+func (e *Editor) EditorState() *state.EditorState {
+	return e.editorState
+}
+
+// ProcessEvent handles a terminal event, such as a key press or resize, without redrawing the screen.
+// This allows a headless editor to be driven programmatically.
+func (e *Editor) ProcessEvent(event tcell.Event) {
+	e.handleTermEvent(event)
+	e.handleIfDocumentLoaded()
+	state.DispatchEvents(e.editorState)
+}
+
+// ProcessKeys handles a sequence of key events, using the notation accepted by input.ParseKeys.
+func (e *Editor) ProcessKeys(keys string) error {
+	events, err := input.ParseKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		e.ProcessEvent(event)
+	}
+	return nil
+}
+
+// WaitForTask blocks until the running task completes, then applies its result.
+// If no task is running, this returns immediately.
+func (e *Editor) WaitForTask() {
+	resultChan := e.editorState.TaskResultChan()
+	if resultChan == nil {
+		return
+	}
+
+	actionFunc := <-resultChan
+	actionFunc(e.editorState)
+	e.handleIfDocumentLoaded()
+	state.DispatchEvents(e.editorState)
+}
+
 func (e *Editor) handleFileChanged() {
 	log.Printf("File change detected, reloading file...\n")
 	state.AbortIfUnsavedChanges(e.editorState, state.ReloadDocument, false)
@@ -147,16 +276,45 @@ func (e *Editor) handleIfDocumentLoaded() {
 		styles := e.editorState.Styles()
 		e.palette = display.NewPaletteFromConfigStyles(styles)
 
+		// Enable or disable mouse events, since the configuration might have changed.
+		// Leaving the mouse disabled allows the terminal to handle text selection.
+		if e.screen != nil {
+			if e.editorState.MouseEnabled() {
+				e.screen.EnableMouse(tcell.MouseDragEvents)
+			} else {
+				e.screen.DisableMouse()
+			}
+		}
+
 		// Store the new document load count so we know when the next document loads.
 		e.documentLoadCount = documentLoadCount
 	}
 }
 
-func (e *Editor) shutdown() {
+// Close stops watching the document for changes and stops the language server, if any.
+// Call this after the editor is no longer needed.
+func (e *Editor) Close() {
 	e.editorState.FileWatcher().Stop()
+	state.ShutdownLanguageServer(e.editorState)
+}
+
+// beepIfRequested sounds the terminal bell if an action rang it since the last redraw.
+func (e *Editor) beepIfRequested() {
+	if !e.editorState.BellFlag() {
+		return
+	}
+
+	state.ClearBellFlag(e.editorState)
+	if err := e.screen.Beep(); err != nil {
+		log.Printf("Error sounding terminal bell: %v\n", err)
+	}
 }
 
 func (e *Editor) redraw(sync bool) {
+	if e.screen == nil {
+		return
+	}
+
 	inputMode := e.editorState.InputMode()
 	inputBufferString := e.inputInterpreter.InputBufferString(inputMode)
 	display.DrawEditor(e.screen, e.palette, e.editorState, inputBufferString)
@@ -170,6 +328,7 @@ func (e *Editor) redraw(sync bool) {
 func suspendScreenFunc(screen tcell.Screen) state.SuspendScreenFunc {
 	return func(f func() error) error {
 		// Suspend input processing and reset the terminal to its original state.
+		restoreDefaultCursorStyle(screen)
 		if err := screen.Suspend(); err != nil {
 			return errors.Wrap(err, "screen.Suspend()")
 		}
@@ -185,3 +344,11 @@ func suspendScreenFunc(screen tcell.Screen) state.SuspendScreenFunc {
 		return f()
 	}
 }
+
+// restoreDefaultCursorStyle resets the terminal cursor to its default shape before the screen stops drawing.
+// The screen sends the cursor style only when it shows the cursor, so this needs to show the screen once more.
+// The next redraw sets the cursor style for the input mode again.
+func restoreDefaultCursorStyle(screen tcell.Screen) {
+	screen.SetCursorStyle(tcell.CursorStyleDefault)
+	screen.Show()
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/state"
+)
+
+func TestResizeKeepsCursorVisible(t *testing.T) {
+	// Each line repeats a single letter, so the letter under the cursor identifies its line.
+	var manyLines []string
+	for i := 0; i < 50; i++ {
+		manyLines = append(manyLines, strings.Repeat(string(rune('a'+i%26)), 5))
+	}
+
+	// The letters cycle through the alphabet, so the letter under the cursor identifies its position.
+	var longLine strings.Builder
+	for i := 0; i < 200; i++ {
+		longLine.WriteRune(rune('a' + i%26))
+	}
+
+	testCases := []struct {
+		name               string
+		text               string
+		loc                FileLocation
+		initialWidth       int
+		initialHeight      int
+		resizedWidth       int
+		resizedHeight      int
+		expectedCursorRune rune
+	}{
+		{
+			name:               "shrink height with cursor near bottom",
+			text:               strings.Join(manyLines, "\n"),
+			loc:                FileLocation{LineNum: 15},
+			initialWidth:       20,
+			initialHeight:      20,
+			resizedWidth:       20,
+			resizedHeight:      6,
+			expectedCursorRune: 'p',
+		},
+		{
+			name:               "grow height with cursor at end",
+			text:               strings.Join(manyLines, "\n"),
+			loc:                FileLocation{LineNum: 49},
+			initialWidth:       20,
+			initialHeight:      6,
+			resizedWidth:       20,
+			resizedHeight:      30,
+			expectedCursorRune: 'x',
+		},
+		{
+			name:               "shrink width with soft-wrapped line",
+			text:               longLine.String(),
+			loc:                FileLocation{Col: 150},
+			initialWidth:       80,
+			initialHeight:      5,
+			resizedWidth:       10,
+			resizedHeight:      5,
+			expectedCursorRune: 'u',
+		},
+		{
+			name:               "grow width with view origin in soft-wrapped line",
+			text:               longLine.String(),
+			loc:                FileLocation{Col: 199},
+			initialWidth:       7,
+			initialHeight:      5,
+			resizedWidth:       30,
+			resizedHeight:      5,
+			expectedCursorRune: 'r',
+		},
+		{
+			name:               "shrink to a single column",
+			text:               strings.Join(manyLines, "\n"),
+			loc:                FileLocation{LineNum: 15, Col: 3},
+			initialWidth:       20,
+			initialHeight:      20,
+			resizedWidth:       1,
+			resizedHeight:      10,
+			expectedCursorRune: 'p',
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.txt")
+			err := os.WriteFile(path, []byte(tc.text), 0644)
+			require.NoError(t, err)
+
+			s := tcell.NewSimulationScreen("")
+			require.NoError(t, s.Init())
+			defer s.Fini()
+			s.SetSize(tc.initialWidth, tc.initialHeight)
+
+			tc.loc.Path = path
+			editor := NewEditor(s, tc.loc, nil, false)
+			defer editor.Close()
+			editor.redraw(true)
+
+			s.SetSize(tc.resizedWidth, tc.resizedHeight)
+			editor.handleTermEvent(tcell.NewEventResize(tc.resizedWidth, tc.resizedHeight))
+			editor.redraw(editor.syncOnRedraw)
+
+			col, row, visible := s.GetCursor()
+			require.True(t, visible)
+			assert.Less(t, row, tc.resizedHeight-1) // The last row is the status bar.
+
+			cells, width, _ := s.GetContents()
+			assert.Equal(t, tc.expectedCursorRune, cells[col+row*width].Runes[0])
+		})
+	}
+}
+
+func TestRunEventLoopBurstOfKeyEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+
+	s := tcell.NewSimulationScreen("")
+	require.NoError(t, s.Init())
+	defer s.Fini()
+	s.SetSize(80, 24)
+
+	editor := NewEditor(s, FileLocation{Path: path}, nil, false)
+	loopDone := make(chan struct{})
+	go func() {
+		editor.RunEventLoop()
+		close(loopDone)
+	}()
+
+	// Post the events as fast as the screen accepts them, like a paste into the terminal.
+	postKeys := func(keys string) {
+		for _, r := range keys {
+			if r == '\n' {
+				s.PostEventWait(tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone))
+			} else {
+				s.PostEventWait(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			}
+		}
+	}
+
+	var expected strings.Builder
+	for i := 0; i < 10000; i++ {
+		if i%80 == 79 {
+			expected.WriteRune('\n')
+		} else {
+			expected.WriteRune(rune('a' + i%26))
+		}
+	}
+
+	postKeys("i")
+	postKeys(expected.String())
+	s.PostEventWait(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	postKeys(":q!\n")
+
+	select {
+	case <-loopDone:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "Timed out waiting for the event loop to exit")
+	}
+
+	text := editor.EditorState().DocumentBuffer().TextTree().String()
+	assert.Equal(t, expected.String(), text)
+}
+
+func TestHeadlessEditor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	err := os.WriteFile(path, []byte("foo\nbar\nbaz\n"), 0644)
+	require.NoError(t, err)
+
+	editor := NewHeadlessEditor(80, 24, FileLocation{Path: path, LineNum: 1}, nil, false)
+	defer editor.Close()
+
+	// Delete the current line, then insert text at the start of the next line.
+	err = editor.ProcessKeys("ddihello <esc>")
+	require.NoError(t, err)
+	buffer := editor.EditorState().DocumentBuffer()
+	assert.Equal(t, "foo\nhello baz", buffer.TextTree().String())
+	assert.Equal(t, uint64(9), buffer.CursorPosition())
+
+	// Resize events update the view without a screen.
+	editor.ProcessEvent(tcell.NewEventResize(40, 10))
+	width, height := buffer.ViewSize()
+	assert.Equal(t, uint64(40), width)
+	assert.Equal(t, uint64(9), height)
+
+	// Save the document using the menu.
+	err = editor.ProcessKeys(":w<enter>")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\nhello baz\n", string(data))
+
+	err = editor.ProcessKeys("<invalid>")
+	assert.EqualError(t, err, "unrecognized key <invalid>")
+}
+
+func TestHeadlessEditorEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	err := os.WriteFile(path, []byte("foo\n"), 0644)
+	require.NoError(t, err)
+
+	editor := NewHeadlessEditor(80, 24, FileLocation{Path: path}, nil, false)
+	defer editor.Close()
+
+	var events []state.EventType
+	state.Subscribe(editor.EditorState(), func(event state.Event) {
+		events = append(events, event.Type)
+	})
+
+	err = editor.ProcessKeys("A")
+	require.NoError(t, err)
+	assert.Equal(t, []state.EventType{state.EventTypeInputModeChanged, state.EventTypeCursorMoved}, events)
+
+	// Each key is dispatched separately.
+	events = nil
+	err = editor.ProcessKeys("x<esc>")
+	require.NoError(t, err)
+	assert.Equal(t, []state.EventType{
+		state.EventTypeBufferModified,
+		state.EventTypeCursorMoved,
+		state.EventTypeInputModeChanged,
+		state.EventTypeCursorMoved,
+	}, events)
+
+	events = nil
+	err = editor.ProcessKeys(":w<enter>")
+	require.NoError(t, err)
+	assert.Contains(t, events, state.EventTypeDocumentSaved)
+}
@@ -29,18 +29,18 @@ func RuneWidth(r rune) uint64 {
 // but can't be 100% accurate without knowing how the terminal will render the glyphs.
 // Tab width is determined based on the position within the line.
 func GraphemeClusterWidth(gc []rune, offsetInLine uint64, tabSize uint64) uint64 {
+	return GraphemeClusterWidthWithTabStops(gc, offsetInLine, tabSize, nil)
+}
+
+// GraphemeClusterWidthWithTabStops is like GraphemeClusterWidth, except that tabs
+// expand to the next tab stop in varTabStops (see TabWidth).
+func GraphemeClusterWidthWithTabStops(gc []rune, offsetInLine uint64, tabSize uint64, varTabStops []uint64) uint64 {
 	if len(gc) == 0 {
 		return 0
 	}
 
-	// Tab width depends on offset in the line.
-	// For example, a tab at the start of the line occupies 4 spaces,
-	// but a tab at the second character occupies only 2 spaces.
-	// This ensures that characters after the tab "line up" at even multiples
-	// of the tab size.
 	if gc[0] == '\t' {
-		nextTabPosition := ((offsetInLine / tabSize) + 1) * tabSize
-		return nextTabPosition - offsetInLine
+		return TabWidth(offsetInLine, tabSize, varTabStops)
 	}
 
 	if isEmojiVariationSequence(gc) {
@@ -60,6 +60,42 @@ func GraphemeClusterWidth(gc []rune, offsetInLine uint64, tabSize uint64) uint64
 	return w
 }
 
+// TabWidth returns the width in cells of a tab at the given offset in the line.
+//
+// Tab width depends on offset in the line.
+// For example, a tab at the start of the line occupies 4 spaces,
+// but a tab at the second character occupies only 2 spaces.
+// This ensures that characters after the tab "line up" at tab stops.
+//
+// If varTabStops is empty, tab stops occur at even multiples of the tab size.
+// Otherwise, each entry is the number of columns between successive tab stops,
+// and the last entry repeats indefinitely (like vim's "vartabstop" option).
+// For example, [4, 8] places tab stops at columns 4, 12, 20, 28, ...
+func TabWidth(offsetInLine uint64, tabSize uint64, varTabStops []uint64) uint64 {
+	if len(varTabStops) == 0 {
+		nextTabPosition := ((offsetInLine / tabSize) + 1) * tabSize
+		return nextTabPosition - offsetInLine
+	}
+
+	var tabStop uint64
+	for _, width := range varTabStops[:len(varTabStops)-1] {
+		tabStop += width
+		if tabStop > offsetInLine {
+			return tabStop - offsetInLine
+		}
+	}
+
+	lastWidth := varTabStops[len(varTabStops)-1]
+	nextTabPosition := tabStop + (((offsetInLine-tabStop)/lastWidth)+1)*lastWidth
+	return nextTabPosition - offsetInLine
+}
+
+// IsTabStop returns whether a tab stop occurs at the given offset in the line.
+// Tab stops are the same as for TabWidth, and the start of the line is always a tab stop.
+func IsTabStop(offsetInLine uint64, tabSize uint64, varTabStops []uint64) bool {
+	return offsetInLine == 0 || TabWidth(offsetInLine-1, tabSize, varTabStops) == 1
+}
+
 // Emoji variation selectors modify the appearance of emojis.
 // http://www.unicode.org/reports/tr51/#Emoji_Variation_Sequences
 const emojiPresentationSelector = 0xFE0F // U+FE0F VARIATION SELECTOR-16 (VS16)
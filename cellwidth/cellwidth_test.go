@@ -144,3 +144,115 @@ func TestGraphemeClusterWidth(t *testing.T) {
 		})
 	}
 }
+
+func TestTabWidth(t *testing.T) {
+	testCases := []struct {
+		name          string
+		offset        uint64
+		tabSize       uint64
+		varTabStops   []uint64
+		expectedWidth uint64
+	}{
+		{
+			name:          "no tab stops, start of line",
+			offset:        0,
+			tabSize:       4,
+			expectedWidth: 4,
+		},
+		{
+			name:          "no tab stops, misaligned offset",
+			offset:        5,
+			tabSize:       4,
+			expectedWidth: 3,
+		},
+		{
+			name:          "single tab stop",
+			offset:        3,
+			tabSize:       4,
+			varTabStops:   []uint64{2},
+			expectedWidth: 1,
+		},
+		{
+			name:          "first tab stop, start of line",
+			offset:        0,
+			tabSize:       4,
+			varTabStops:   []uint64{4, 8},
+			expectedWidth: 4,
+		},
+		{
+			name:          "first tab stop, misaligned offset",
+			offset:        1,
+			tabSize:       4,
+			varTabStops:   []uint64{4, 8},
+			expectedWidth: 3,
+		},
+		{
+			name:          "second tab stop, aligned offset",
+			offset:        4,
+			tabSize:       4,
+			varTabStops:   []uint64{4, 8},
+			expectedWidth: 8,
+		},
+		{
+			name:          "second tab stop, misaligned offset",
+			offset:        10,
+			tabSize:       4,
+			varTabStops:   []uint64{4, 8},
+			expectedWidth: 2,
+		},
+		{
+			name:          "last tab stop repeats",
+			offset:        13,
+			tabSize:       4,
+			varTabStops:   []uint64{4, 8},
+			expectedWidth: 7,
+		},
+		{
+			name:          "multiple tab stops",
+			offset:        5,
+			tabSize:       4,
+			varTabStops:   []uint64{2, 3, 6},
+			expectedWidth: 6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			width := TabWidth(tc.offset, tc.tabSize, tc.varTabStops)
+			assert.Equal(t, tc.expectedWidth, width)
+		})
+	}
+}
+
+func TestIsTabStop(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tabSize     uint64
+		varTabStops []uint64
+		expected    []uint64
+	}{
+		{
+			name:     "fixed tab size",
+			tabSize:  4,
+			expected: []uint64{0, 4, 8, 12, 16},
+		},
+		{
+			name:        "var tab stops",
+			tabSize:     4,
+			varTabStops: []uint64{2, 3, 6},
+			expected:    []uint64{0, 2, 5, 11, 17},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var tabStops []uint64
+			for offset := uint64(0); offset <= 17; offset++ {
+				if IsTabStop(offset, tc.tabSize, tc.varTabStops) {
+					tabStops = append(tabStops, offset)
+				}
+			}
+			assert.Equal(t, tc.expected, tabStops)
+		})
+	}
+}
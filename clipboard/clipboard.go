@@ -14,6 +14,10 @@ const (
 	// Output of the last shell command inserted into the document.
 	PageShellCmdOutput
 
+	// Text inserted during the most recent insert mode session.
+	// This is equivalent to vim's "." register.
+	PageLastInserted
+
 	// Named pages "a" through "z".
 	PageLetterA
 	PageLetterB
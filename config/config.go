@@ -4,16 +4,64 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/cellwidth"
+	"github.com/aretext/aretext/snippet"
 )
 
 const DefaultSyntaxLanguage = "plaintext"
 const DefaultTabSize = 4
 const DefaultTabExpand = false
+const DefaultDetectIndent = true
+const DefaultModeline = true
 const DefaultShowTabs = false
 const DefaultShowSpaces = false
 const DefaultAutoIndent = false
+const DefaultContinueComments = false
+const DefaultAutoCloseTags = false
 const DefaultShowLineNumbers = false
+const DefaultRelativeLineNumbers = false
+const DefaultLineNumberSeparator = ""
+const DefaultCursorLineNumberAlign = LineNumberAlignLeft
+const DefaultSmartCase = true
+const DefaultWrapScan = true
+const DefaultHlSearch = false
+const DefaultStatusMsgTimeout = 0
+const DefaultWriteBackup = false
+const DefaultBackupDir = ""
+const DefaultBackupCount = 0
+const DefaultBackupAbortOnError = false
+const DefaultAutoWrite = false
 const DefaultLineWrap = LineWrapCharacter
+const DefaultInsertArrowKeys = InsertArrowKeysMove
+const DefaultVirtualEdit = VirtualEditNone
+const DefaultStartOfLine = true
+const DefaultBoundaryBehavior = BoundaryBehaviorStay
+const DefaultReselectAfterUndo = false
+const DefaultMatchPairs = "(:),[:],{:}"
+const DefaultMatchWords = ""
+const DefaultMatchTags = false
+const DefaultTextWidth = 80
+const DefaultColorColumn = ""
+const DefaultMouse = false
+const DefaultHyperlinks = false
+const DefaultCommitSubjectWidth = 50
+const DefaultCommitBodyWidth = 72
+const DefaultHighlightYank = false
+const DefaultHighlightYankDuration = 150
+const DefaultShowCmd = true
+const DefaultMultiCursor = false
+const DefaultDateFormat = "%Y-%m-%d"
+const DefaultTimeFormat = "%H:%M"
+const DefaultLinter = ""
+const DefaultLanguageServer = ""
+const DefaultCursorShapeNormal = CursorShapeBlock
+const DefaultCursorShapeInsert = CursorShapeBar
+const DefaultCursorShapeVisual = CursorShapeBlock
 
 // Config is a configuration for the editor.
 type Config struct {
@@ -23,9 +71,22 @@ type Config struct {
 	// Size of a tab character in columns.
 	TabSize int
 
+	// Widths of successive tab stops in columns.
+	// The last width repeats for all subsequent tab stops.
+	// If empty, tab stops occur every TabSize columns.
+	VarTabStops []int
+
 	// If enabled, the tab key inserts spaces.
 	TabExpand bool
 
+	// If enabled, guess TabExpand and TabSize from the indented lines of a document when opening it.
+	// If the document has no indented lines, the configured values are used.
+	DetectIndent bool
+
+	// If enabled, set TabExpand and TabSize from a vim modeline like "vim: ts=4 sw=4 et"
+	// in the first or last lines of a document when opening it.
+	Modeline bool
+
 	// If enabled, display tab characters in the document.
 	ShowTabs bool
 
@@ -35,18 +96,168 @@ type Config struct {
 	// If enabled, indent a new line to match indentation of the previous line.
 	AutoIndent bool
 
+	// If enabled, a new line started from a line comment begins with the same comment marker.
+	// The comment marker depends on the syntax language.
+	ContinueComments bool
+
+	// If enabled, typing ">" at the end of an HTML or XML opening tag inserts the matching closing tag.
+	AutoCloseTags bool
+
 	// If enabled, show line numbers in the left margin.
 	ShowLineNumbers bool
 
+	// If enabled along with ShowLineNumbers, each line number is the distance from the cursor's line,
+	// except the cursor's line shows its absolute line number.
+	RelativeLineNumbers bool
+
+	// Character drawn between the line numbers and the document text.
+	// If empty, the line numbers are separated from the text by a space.
+	LineNumberSeparator string
+
+	// Alignment of the cursor's absolute line number when RelativeLineNumbers is enabled.
+	CursorLineNumberAlign string
+
+	// If enabled, a search query without uppercase letters is case-insensitive.
+	// Otherwise, search is case-sensitive unless the query ends with "\c".
+	SmartCase bool
+
+	// If enabled, search continues from the other end of the document when it reaches the start or end.
+	WrapScan bool
+
+	// If enabled, highlight every match for the last search query.
+	HlSearch bool
+
+	// Number of seconds before clearing a success message from the status bar.
+	// Error messages remain until replaced. If zero, messages are never cleared automatically.
+	StatusMsgTimeout int
+
+	// If enabled, copy the file on disk to a backup before overwriting it on save.
+	WriteBackup bool
+
+	// Directory for backup files. Each backup has a timestamp in its name.
+	// If empty, the backup is written next to the file with "~" appended to the name.
+	BackupDir string
+
+	// Maximum number of backups to keep for each file in BackupDir.
+	// If zero, every backup is kept.
+	BackupCount int
+
+	// If enabled, abort the save when the backup cannot be written.
+	// Otherwise, save the file anyway and show a warning.
+	BackupAbortOnError bool
+
+	// If enabled, save the document before opening another document or running a menu command's shell command,
+	// if the document has unsaved changes.
+	AutoWrite bool
+
 	// LineWrap controls how lines are soft-wrapped.
 	LineWrap string
 
+	// InsertArrowKeys controls how arrow keys behave in insert mode.
+	InsertArrowKeys string
+
+	// VirtualEdit controls whether the cursor can move past the end of a line or into the middle of a tab.
+	VirtualEdit string
+
+	// If enabled, "gg", "G", and "N%" move the cursor to the first non-whitespace character of the line.
+	// Otherwise, they keep the cursor's column like "j" and "k".
+	StartOfLine bool
+
+	// BoundaryBehavior controls what happens when a vertical motion cannot move past the first or last line.
+	BoundaryBehavior string
+
+	// If enabled, undo and redo select the text they changed in visual mode.
+	// Otherwise, they move the cursor to the start of the change.
+	ReselectAfterUndo bool
+
+	// Comma-separated list of character pairs that "%" jumps between, such as "(:),[:],{:}".
+	// Each pair is an open character and a close character separated by a colon.
+	MatchPairs string
+
+	// Comma-separated list of keyword groups that "%" jumps between, such as "if:elif|else:fi,do:done".
+	// Each group is a colon-separated list of the keywords that open a block, then any keywords in the middle,
+	// then the keywords that close the block. Alternative keywords are separated by "|".
+	MatchWords string
+
+	// If enabled, "%" on an HTML or XML tag jumps to the matching opening or closing tag.
+	MatchTags bool
+
+	// Maximum width of a line in columns when formatting paragraphs with "gq".
+	TextWidth int
+
+	// Comma-separated list of columns to highlight, starting from one.
+	// A value prefixed with "+" or "-" is relative to TextWidth.
+	// If empty, no columns are highlighted.
+	ColorColumn string
+
+	// If enabled, clicking positions the cursor, dragging selects text, and the scroll wheel scrolls the view.
+	// Disable this to use the terminal's own text selection.
+	Mouse bool
+
+	// If enabled, URLs in the status bar and file paths in the quickfix menu are rendered as hyperlinks
+	// that open when clicked, in terminals that support OSC 8 hyperlinks.
+	Hyperlinks bool
+
+	// Maximum number of characters in the subject (first line) of a git commit message.
+	// Characters past the limit are highlighted. If zero, the subject has no limit.
+	CommitSubjectWidth int
+
+	// Maximum number of characters in each line of the body of a git commit message.
+	// Characters past the limit are highlighted. If zero, the body has no limit.
+	CommitBodyWidth int
+
+	// If enabled, briefly highlight text after copying it to the clipboard.
+	HighlightYank bool
+
+	// Number of milliseconds to highlight copied text if HighlightYank is enabled.
+	HighlightYankDuration int
+
+	// If enabled, show the keys typed so far for an incomplete command in the bottom-right of the status bar.
+	ShowCmd bool
+
+	// If enabled, ctrl-n in normal mode adds a cursor at the next occurrence of the word under the cursor,
+	// and edits in insert mode apply at every cursor. This is experimental.
+	MultiCursor bool
+
+	// strftime-like formats for the "insert date" and "insert time" menu commands, such as "%Y-%m-%d".
+	DateFormat string
+	TimeFormat string
+
+	// Shell command that checks the document after each save, such as "go vet $FILEPATH".
+	// Output lines like "file:line:col: message" for the document are shown as diagnostics.
+	// If empty, no linter runs.
+	Linter string
+
+	// Command that starts a language server, such as "gopls", for hover and go-to-definition.
+	// The editor communicates with the server over stdin and stdout using the Language Server Protocol.
+	// If empty, no language server runs.
+	LanguageServer string
+
+	// Shape of the terminal cursor in normal, insert, and visual mode.
+	// Menu and search mode use the insert mode shape.
+	// If the shape is "default", the editor leaves the terminal's cursor shape unchanged.
+	CursorShapeNormal string
+	CursorShapeInsert string
+	CursorShapeVisual string
+
 	// User-defined commands to include in the menu.
 	MenuCommands []MenuCommandConfig
 
 	// Glob patterns for directories to exclude from file search.
 	HideDirectories []string
 
+	// User-defined digraphs, mapping two characters to the character they insert.
+	// These take precedence over the built-in digraphs.
+	Digraphs map[string]string
+
+	// User-defined abbreviations for insert mode, mapping a trigger word to the text that replaces it.
+	// The trigger expands when followed by a character that cannot be part of a word.
+	Abbreviations map[string]string
+
+	// User-defined snippets for insert mode, mapping a trigger word to a template with tab stops.
+	// Typing the trigger followed by tab expands the template (see snippet.Parse for the syntax).
+	Snippets map[string]string
+
 	// Style overrides.
 	Styles map[string]StyleConfig
 }
@@ -56,12 +267,44 @@ const (
 	LineWrapWord      = "word"      // Break lines only between words.
 )
 
+const (
+	InsertArrowKeysMove   = "move"   // Move the cursor without leaving insert mode.
+	InsertArrowKeysIgnore = "ignore" // Do nothing.
+	InsertArrowKeysNormal = "normal" // Return to normal mode, then move the cursor.
+)
+
+const (
+	VirtualEditNone = "none" // The cursor stays on a character in the line.
+	VirtualEditAll  = "all"  // The cursor can move to any column, and inserting there pads the line with spaces.
+)
+
+const (
+	LineNumberAlignLeft  = "left"  // Aligned to the left edge of the margin, like vim with number and relativenumber.
+	LineNumberAlignRight = "right" // Aligned to the right, like the other line numbers.
+)
+
+const (
+	BoundaryBehaviorStay = "stay" // The cursor stays on the first or last line.
+	BoundaryBehaviorBeep = "beep" // The cursor stays on the first or last line, and the terminal beeps.
+)
+
+const (
+	CursorShapeDefault           = "default"           // The terminal's default cursor shape.
+	CursorShapeBlock             = "block"             // Steady block.
+	CursorShapeBlinkingBlock     = "blinkingBlock"     // Blinking block.
+	CursorShapeUnderline         = "underline"         // Steady underline.
+	CursorShapeBlinkingUnderline = "blinkingUnderline" // Blinking underline.
+	CursorShapeBar               = "bar"               // Steady vertical bar.
+	CursorShapeBlinkingBar       = "blinkingBar"       // Blinking vertical bar.
+)
+
 const (
 	CmdModeSilent        = "silent"        // accepts no input and any output is discarded.
 	CmdModeTerminal      = "terminal"      // takes control of the terminal.
 	CmdModeInsert        = "insert"        // output is inserted into the document at the cursor position, replacing any selection.
 	CmdModeInsertChoice  = "insertChoice"  // user can select one line from the output to insert into the document.
 	CmdModeFileLocations = "fileLocations" // output is interpreted as a list of file locations that can be opened in the editor.
+	CmdModeQuickfix      = "quickfix"      // output is interpreted as a list of problems reported by a linter or compiler.
 	CmdModeWorkingDir    = "workingDir"    // output is interpreted as a list of directories to set as the current working directory.
 )
 
@@ -104,6 +347,13 @@ const (
 	StyleTokenCustom14 = "tokenCustom14"
 	StyleTokenCustom15 = "tokenCustom15"
 	StyleTokenCustom16 = "tokenCustom16"
+
+	StyleStatusMsgSuccess = "statusMsgSuccess"
+	StyleStatusMsgError   = "statusMsgError"
+
+	StyleColorColumn   = "colorColumn"
+	StyleLineOverflow  = "lineOverflow"
+	StyleYankHighlight = "yankHighlight"
 )
 
 // StyleConfig is a configuration for how text should be displayed.
@@ -133,21 +383,193 @@ type StyleConfig struct {
 	StrikeThrough bool
 }
 
+// ParseColorColumn parses a comma-separated list of columns to highlight.
+// Each column is a number starting from one, or an offset prefixed with "+" or "-" relative to textWidth.
+// The returned columns start from one and are in the order they appear in the list.
+func ParseColorColumn(s string, textWidth int) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var cols []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("ColorColumn has invalid column %q", field)
+		}
+
+		if strings.HasPrefix(field, "+") || strings.HasPrefix(field, "-") {
+			n += textWidth
+		}
+
+		if n < 1 {
+			return nil, fmt.Errorf("ColorColumn must contain only columns greater than zero, but got %q", field)
+		}
+
+		cols = append(cols, n)
+	}
+	return cols, nil
+}
+
+// MatchPair is an open and close character that "%" jumps between.
+type MatchPair struct {
+	Open  rune
+	Close rune
+}
+
+// ParseMatchPairs parses a comma-separated list of character pairs like "(:),[:],{:}".
+// Each pair is an open character and a close character separated by a colon,
+// and a character can appear in only one pair.
+func ParseMatchPairs(s string) ([]MatchPair, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var pairs []MatchPair
+	seen := make(map[rune]struct{})
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		runes := []rune(field)
+		if len(runes) != 3 || runes[1] != ':' || runes[0] == runes[2] {
+			return nil, fmt.Errorf("MatchPairs has invalid pair %q", field)
+		}
+
+		for _, r := range []rune{runes[0], runes[2]} {
+			if _, ok := seen[r]; ok {
+				return nil, fmt.Errorf("MatchPairs must not contain %q in more than one pair", r)
+			}
+			seen[r] = struct{}{}
+		}
+
+		pairs = append(pairs, MatchPair{Open: runes[0], Close: runes[2]})
+	}
+	return pairs, nil
+}
+
+// MatchWordGroup is a set of keywords that "%" jumps between, such as "if", "else", and "fi".
+type MatchWordGroup struct {
+	Open          []string
+	LineStartOpen []string // Open keywords that start a block only when they are the first word on a line.
+	Middle        []string
+	Close         []string
+}
+
+// ParseMatchWords parses a comma-separated list of keyword groups like "if:elif|else:fi,do:done".
+// Each group is a colon-separated list with the keywords that open a block first, the keywords that close it last,
+// and any keywords in the middle in between. Alternative keywords are separated by "|".
+// An open keyword prefixed with "^", like "^if", opens a block only when it is the first word on a line.
+// A keyword can appear only once in the list.
+func ParseMatchWords(s string) ([]MatchWordGroup, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var groups []MatchWordGroup
+	seen := make(map[string]struct{})
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		parts := strings.Split(field, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("MatchWords has invalid group %q", field)
+		}
+
+		var keywordLists [][]string
+		for i, part := range parts {
+			keywords := strings.Split(part, "|")
+			for _, kw := range keywords {
+				if i == 0 {
+					kw = strings.TrimPrefix(kw, "^")
+				}
+
+				if kw == "" || strings.HasPrefix(kw, "^") || strings.IndexFunc(kw, unicode.IsSpace) >= 0 {
+					return nil, fmt.Errorf("MatchWords has invalid group %q", field)
+				}
+
+				if _, ok := seen[kw]; ok {
+					return nil, fmt.Errorf("MatchWords must not contain %q more than once", kw)
+				}
+				seen[kw] = struct{}{}
+			}
+			keywordLists = append(keywordLists, keywords)
+		}
+
+		group := MatchWordGroup{Close: keywordLists[len(keywordLists)-1]}
+		for _, kw := range keywordLists[0] {
+			if strings.HasPrefix(kw, "^") {
+				group.LineStartOpen = append(group.LineStartOpen, kw[1:])
+			} else {
+				group.Open = append(group.Open, kw)
+			}
+		}
+		for _, keywords := range keywordLists[1 : len(keywordLists)-1] {
+			group.Middle = append(group.Middle, keywords...)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
 // ConfigFromUntypedMap constructs a configuration from an untyped map.
 // The map is usually loaded from a JSON document.
 func ConfigFromUntypedMap(m map[string]any) Config {
 	return Config{
-		SyntaxLanguage:  stringOrDefault(m, "syntaxLanguage", DefaultSyntaxLanguage),
-		TabSize:         intOrDefault(m, "tabSize", DefaultTabSize),
-		TabExpand:       boolOrDefault(m, "tabExpand", DefaultTabExpand),
-		ShowTabs:        boolOrDefault(m, "showTabs", DefaultShowTabs),
-		ShowSpaces:      boolOrDefault(m, "showSpaces", DefaultShowSpaces),
-		AutoIndent:      boolOrDefault(m, "autoIndent", DefaultAutoIndent),
-		ShowLineNumbers: boolOrDefault(m, "showLineNumbers", DefaultShowLineNumbers),
-		LineWrap:        stringOrDefault(m, "lineWrap", DefaultLineWrap),
-		MenuCommands:    menuCommandsFromSlice(sliceOrNil(m, "menuCommands")),
-		HideDirectories: stringSliceOrNil(m, "hideDirectories"),
-		Styles:          stylesFromMap(mapOrNil(m, "styles")),
+		SyntaxLanguage:        stringOrDefault(m, "syntaxLanguage", DefaultSyntaxLanguage),
+		TabSize:               intOrDefault(m, "tabSize", DefaultTabSize),
+		VarTabStops:           intSliceOrNil(m, "varTabStops"),
+		TabExpand:             boolOrDefault(m, "tabExpand", DefaultTabExpand),
+		DetectIndent:          boolOrDefault(m, "detectIndent", DefaultDetectIndent),
+		Modeline:              boolOrDefault(m, "modeline", DefaultModeline),
+		ShowTabs:              boolOrDefault(m, "showTabs", DefaultShowTabs),
+		ShowSpaces:            boolOrDefault(m, "showSpaces", DefaultShowSpaces),
+		AutoIndent:            boolOrDefault(m, "autoIndent", DefaultAutoIndent),
+		ContinueComments:      boolOrDefault(m, "continueComments", DefaultContinueComments),
+		AutoCloseTags:         boolOrDefault(m, "autoCloseTags", DefaultAutoCloseTags),
+		ShowLineNumbers:       boolOrDefault(m, "showLineNumbers", DefaultShowLineNumbers),
+		RelativeLineNumbers:   boolOrDefault(m, "relativeLineNumbers", DefaultRelativeLineNumbers),
+		LineNumberSeparator:   stringOrDefault(m, "lineNumberSeparator", DefaultLineNumberSeparator),
+		CursorLineNumberAlign: stringOrDefault(m, "cursorLineNumberAlign", DefaultCursorLineNumberAlign),
+		SmartCase:             boolOrDefault(m, "smartCase", DefaultSmartCase),
+		WrapScan:              boolOrDefault(m, "wrapScan", DefaultWrapScan),
+		HlSearch:              boolOrDefault(m, "hlSearch", DefaultHlSearch),
+		StatusMsgTimeout:      intOrDefault(m, "statusMsgTimeout", DefaultStatusMsgTimeout),
+		WriteBackup:           boolOrDefault(m, "writeBackup", DefaultWriteBackup),
+		BackupDir:             stringOrDefault(m, "backupDir", DefaultBackupDir),
+		BackupCount:           intOrDefault(m, "backupCount", DefaultBackupCount),
+		BackupAbortOnError:    boolOrDefault(m, "backupAbortOnError", DefaultBackupAbortOnError),
+		AutoWrite:             boolOrDefault(m, "autoWrite", DefaultAutoWrite),
+		LineWrap:              stringOrDefault(m, "lineWrap", DefaultLineWrap),
+		InsertArrowKeys:       stringOrDefault(m, "insertArrowKeys", DefaultInsertArrowKeys),
+		VirtualEdit:           stringOrDefault(m, "virtualEdit", DefaultVirtualEdit),
+		StartOfLine:           boolOrDefault(m, "startOfLine", DefaultStartOfLine),
+		BoundaryBehavior:      stringOrDefault(m, "boundaryBehavior", DefaultBoundaryBehavior),
+		ReselectAfterUndo:     boolOrDefault(m, "reselectAfterUndo", DefaultReselectAfterUndo),
+		MatchPairs:            stringOrDefault(m, "matchPairs", DefaultMatchPairs),
+		MatchWords:            stringOrDefault(m, "matchWords", DefaultMatchWords),
+		MatchTags:             boolOrDefault(m, "matchTags", DefaultMatchTags),
+		TextWidth:             intOrDefault(m, "textWidth", DefaultTextWidth),
+		ColorColumn:           stringOrDefault(m, "colorColumn", DefaultColorColumn),
+		Mouse:                 boolOrDefault(m, "mouse", DefaultMouse),
+		Hyperlinks:            boolOrDefault(m, "hyperlinks", DefaultHyperlinks),
+		CommitSubjectWidth:    intOrDefault(m, "commitSubjectWidth", DefaultCommitSubjectWidth),
+		CommitBodyWidth:       intOrDefault(m, "commitBodyWidth", DefaultCommitBodyWidth),
+		HighlightYank:         boolOrDefault(m, "highlightYank", DefaultHighlightYank),
+		HighlightYankDuration: intOrDefault(m, "highlightYankDuration", DefaultHighlightYankDuration),
+		ShowCmd:               boolOrDefault(m, "showCmd", DefaultShowCmd),
+		MultiCursor:           boolOrDefault(m, "multiCursor", DefaultMultiCursor),
+		DateFormat:            stringOrDefault(m, "dateFormat", DefaultDateFormat),
+		TimeFormat:            stringOrDefault(m, "timeFormat", DefaultTimeFormat),
+		Linter:                stringOrDefault(m, "linter", DefaultLinter),
+		LanguageServer:        stringOrDefault(m, "languageServer", DefaultLanguageServer),
+		CursorShapeNormal:     stringOrDefault(m, "cursorShapeNormal", DefaultCursorShapeNormal),
+		CursorShapeInsert:     stringOrDefault(m, "cursorShapeInsert", DefaultCursorShapeInsert),
+		CursorShapeVisual:     stringOrDefault(m, "cursorShapeVisual", DefaultCursorShapeVisual),
+		MenuCommands:          menuCommandsFromSlice(sliceOrNil(m, "menuCommands")),
+		HideDirectories:       stringSliceOrNil(m, "hideDirectories"),
+		Digraphs:              stringMapOrNil(m, "digraphs"),
+		Abbreviations:         stringMapOrNil(m, "abbreviations"),
+		Snippets:              stringMapOrNil(m, "snippets"),
+		Styles:                stylesFromMap(mapOrNil(m, "styles")),
 	}
 }
 
@@ -157,10 +579,96 @@ func (c Config) Validate() error {
 		return errors.New("TabSize must be greater than zero")
 	}
 
+	if c.TextWidth < 1 {
+		return errors.New("TextWidth must be greater than zero")
+	}
+
+	if _, err := ParseColorColumn(c.ColorColumn, c.TextWidth); err != nil {
+		return err
+	}
+
+	if _, err := ParseMatchPairs(c.MatchPairs); err != nil {
+		return err
+	}
+
+	if _, err := ParseMatchWords(c.MatchWords); err != nil {
+		return err
+	}
+
+	if c.StatusMsgTimeout < 0 {
+		return errors.New("StatusMsgTimeout must be greater than or equal to zero")
+	}
+
+	if c.BackupCount < 0 {
+		return errors.New("BackupCount must be greater than or equal to zero")
+	}
+
+	if c.CommitSubjectWidth < 0 {
+		return errors.New("CommitSubjectWidth must be greater than or equal to zero")
+	}
+
+	if c.CommitBodyWidth < 0 {
+		return errors.New("CommitBodyWidth must be greater than or equal to zero")
+	}
+
+	if c.HighlightYankDuration < 1 {
+		return errors.New("HighlightYankDuration must be greater than zero")
+	}
+
+	for _, w := range c.VarTabStops {
+		if w < 1 {
+			return errors.New("VarTabStops must contain only values greater than zero")
+		}
+	}
+
 	if c.LineWrap != LineWrapCharacter && c.LineWrap != LineWrapWord {
 		return fmt.Errorf("LineWrap must be either %q or %q", LineWrapCharacter, LineWrapWord)
 	}
 
+	if c.InsertArrowKeys != InsertArrowKeysMove && c.InsertArrowKeys != InsertArrowKeysIgnore && c.InsertArrowKeys != InsertArrowKeysNormal {
+		return fmt.Errorf("InsertArrowKeys must be either %q, %q, or %q", InsertArrowKeysMove, InsertArrowKeysIgnore, InsertArrowKeysNormal)
+	}
+
+	if c.VirtualEdit != VirtualEditNone && c.VirtualEdit != VirtualEditAll {
+		return fmt.Errorf("VirtualEdit must be either %q or %q", VirtualEditNone, VirtualEditAll)
+	}
+
+	if utf8.RuneCountInString(c.LineNumberSeparator) > 1 {
+		return errors.New("LineNumberSeparator must be at most one character")
+	} else if r, _ := utf8.DecodeRuneInString(c.LineNumberSeparator); c.LineNumberSeparator != "" && (!unicode.IsPrint(r) || cellwidth.RuneWidth(r) != 1) {
+		return errors.New("LineNumberSeparator must be a printable character that is one column wide")
+	}
+
+	if c.CursorLineNumberAlign != LineNumberAlignLeft && c.CursorLineNumberAlign != LineNumberAlignRight {
+		return fmt.Errorf("CursorLineNumberAlign must be either %q or %q", LineNumberAlignLeft, LineNumberAlignRight)
+	}
+
+	if c.BoundaryBehavior != BoundaryBehaviorStay && c.BoundaryBehavior != BoundaryBehaviorBeep {
+		return fmt.Errorf("BoundaryBehavior must be either %q or %q", BoundaryBehaviorStay, BoundaryBehaviorBeep)
+	}
+
+	for _, shape := range []struct{ name, value string }{
+		{"CursorShapeNormal", c.CursorShapeNormal},
+		{"CursorShapeInsert", c.CursorShapeInsert},
+		{"CursorShapeVisual", c.CursorShapeVisual},
+	} {
+		switch shape.value {
+		case CursorShapeDefault, CursorShapeBlock, CursorShapeBlinkingBlock, CursorShapeUnderline, CursorShapeBlinkingUnderline, CursorShapeBar, CursorShapeBlinkingBar:
+		default:
+			return fmt.Errorf(
+				"%s must be either %q, %q, %q, %q, %q, %q, or %q",
+				shape.name,
+				CursorShapeDefault,
+				CursorShapeBlock,
+				CursorShapeBlinkingBlock,
+				CursorShapeUnderline,
+				CursorShapeBlinkingUnderline,
+				CursorShapeBar,
+				CursorShapeBlinkingBar,
+			)
+		}
+	}
+
 	for _, cmd := range c.MenuCommands {
 		if cmd.Name == "" {
 			return fmt.Errorf("Menu name cannot be empty")
@@ -170,23 +678,68 @@ func (c Config) Validate() error {
 			return fmt.Errorf("Menu command %q shellCmd cannot be empty", cmd.Name)
 		}
 
-		if cmd.Mode != CmdModeSilent && cmd.Mode != CmdModeTerminal && cmd.Mode != CmdModeInsert && cmd.Mode != CmdModeInsertChoice && cmd.Mode != CmdModeFileLocations && cmd.Mode != CmdModeWorkingDir {
+		if cmd.Mode != CmdModeSilent && cmd.Mode != CmdModeTerminal && cmd.Mode != CmdModeInsert && cmd.Mode != CmdModeInsertChoice && cmd.Mode != CmdModeFileLocations && cmd.Mode != CmdModeQuickfix && cmd.Mode != CmdModeWorkingDir {
 			return fmt.Errorf(
-				"Menu command %q must have mode set to either %q, %q, %q, %q, %q, or %q",
+				"Menu command %q must have mode set to either %q, %q, %q, %q, %q, %q, or %q",
 				cmd.Name,
 				CmdModeSilent,
 				CmdModeTerminal,
 				CmdModeInsert,
 				CmdModeInsertChoice,
 				CmdModeFileLocations,
+				CmdModeQuickfix,
 				CmdModeWorkingDir,
 			)
 		}
 	}
 
+	for k, v := range c.Digraphs {
+		if utf8.RuneCountInString(k) != 2 {
+			return fmt.Errorf("Digraph %q must have exactly two characters", k)
+		}
+
+		if utf8.RuneCountInString(v) != 1 {
+			return fmt.Errorf("Digraph %q must map to exactly one character", k)
+		}
+	}
+
+	for k := range c.Abbreviations {
+		if k == "" {
+			return errors.New("Abbreviation cannot be empty")
+		}
+
+		for _, r := range k {
+			if !IsAbbreviationRune(r) {
+				return fmt.Errorf("Abbreviation %q must contain only letters, digits, and underscores", k)
+			}
+		}
+	}
+
+	for k, v := range c.Snippets {
+		if k == "" {
+			return errors.New("Snippet trigger cannot be empty")
+		}
+
+		for _, r := range k {
+			if !IsAbbreviationRune(r) {
+				return fmt.Errorf("Snippet trigger %q must contain only letters, digits, and underscores", k)
+			}
+		}
+
+		if _, err := snippet.Parse(v); err != nil {
+			return fmt.Errorf("Snippet %q is invalid: %w", k, err)
+		}
+	}
+
 	return nil
 }
 
+// IsAbbreviationRune returns whether a rune can be part of an abbreviation or snippet trigger.
+// Any other rune ends the trigger, so typing it expands the abbreviation.
+func IsAbbreviationRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
 func stringOrDefault(m map[string]any, key string, defaultVal string) string {
 	v, ok := m[key]
 	if !ok {
@@ -267,6 +820,26 @@ func stringSliceOrNil(m map[string]any, key string) []string {
 	return stringSlice
 }
 
+func intSliceOrNil(m map[string]any, key string) []int {
+	slice := sliceOrNil(m, key)
+	if slice == nil {
+		return nil
+	}
+
+	intSlice := make([]int, 0, len(slice))
+	for i := 0; i < len(slice); i++ {
+		switch v := (slice[i]).(type) {
+		case int:
+			intSlice = append(intSlice, v)
+		case float64:
+			intSlice = append(intSlice, int(v))
+		default:
+			log.Printf("Could not decode int in slice for config key %q\n", key)
+		}
+	}
+	return intSlice
+}
+
 func mapOrNil(m map[string]any, key string) map[string]any {
 	v, ok := m[key]
 	if !ok {
@@ -282,6 +855,24 @@ func mapOrNil(m map[string]any, key string) map[string]any {
 	return subMap
 }
 
+func stringMapOrNil(m map[string]any, key string) map[string]string {
+	subMap := mapOrNil(m, key)
+	if subMap == nil {
+		return nil
+	}
+
+	stringMap := make(map[string]string, len(subMap))
+	for k, v := range subMap {
+		s, ok := v.(string)
+		if !ok {
+			log.Printf("Could not decode string in map for config key %q\n", key)
+			continue
+		}
+		stringMap[k] = s
+	}
+	return stringMap
+}
+
 func menuCommandsFromSlice(s []any) []MenuCommandConfig {
 	result := make([]MenuCommandConfig, 0, len(s))
 	for _, m := range s {
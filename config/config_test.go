@@ -16,11 +16,32 @@ func TestConfigFromUntypedMap(t *testing.T) {
 			name:  "empty map",
 			input: map[string]any{},
 			expected: Config{
-				SyntaxLanguage: "plaintext",
-				TabSize:        4,
-				LineWrap:       "character",
-				MenuCommands:   []MenuCommandConfig{},
-				Styles:         map[string]StyleConfig{},
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
 			},
 		},
 		{
@@ -50,10 +71,31 @@ func TestConfigFromUntypedMap(t *testing.T) {
 				},
 			},
 			expected: Config{
-				SyntaxLanguage: "customLang",
-				TabSize:        4,
-				LineWrap:       "character",
-				MenuCommands:   []MenuCommandConfig{},
+				SyntaxLanguage:        "customLang",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
 				Styles: map[string]StyleConfig{
 					"lineNum": {
 						Color: "olive",
@@ -77,6 +119,749 @@ func TestConfigFromUntypedMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "insert arrow keys",
+			input: map[string]any{
+				"insertArrowKeys": "ignore",
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "ignore",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "text width",
+			input: map[string]any{
+				"textWidth": 72,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             72,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "mouse",
+			input: map[string]any{
+				"mouse": true,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				Mouse:                 true,
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "git commit line lengths",
+			input: map[string]any{
+				"commitSubjectWidth": 60,
+				"commitBodyWidth":    0,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    60,
+				CommitBodyWidth:       0,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "cursor shapes",
+			input: map[string]any{
+				"cursorShapeNormal": "blinkingBlock",
+				"cursorShapeInsert": "underline",
+				"cursorShapeVisual": "default",
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "blinkingBlock",
+				CursorShapeInsert:     "underline",
+				CursorShapeVisual:     "default",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "highlight yank",
+			input: map[string]any{
+				"highlightYank":         true,
+				"highlightYankDuration": 300,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYank:         true,
+				HighlightYankDuration: 300,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "show cmd disabled",
+			input: map[string]any{
+				"showCmd": false,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               false,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "color column",
+			input: map[string]any{
+				"colorColumn": "+1,100",
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				ColorColumn:           "+1,100",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "virtual edit",
+			input: map[string]any{
+				"virtualEdit": "all",
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "all",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "start of line disabled and boundary behavior beep",
+			input: map[string]any{
+				"startOfLine":      false,
+				"boundaryBehavior": "beep",
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           false,
+				BoundaryBehavior:      "beep",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "smart case disabled",
+			input: map[string]any{
+				"smartCase": false,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "wrap scan disabled",
+			input: map[string]any{
+				"wrapScan": false,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "auto close tags enabled",
+			input: map[string]any{
+				"autoCloseTags": true,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				AutoCloseTags:         true,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "continue comments enabled",
+			input: map[string]any{
+				"continueComments": true,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				ContinueComments:      true,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "hl search enabled",
+			input: map[string]any{
+				"hlSearch": true,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				HlSearch:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "status message timeout",
+			input: map[string]any{
+				"statusMsgTimeout": 5,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				StatusMsgTimeout:      5,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "backup",
+			input: map[string]any{
+				"writeBackup":        true,
+				"backupDir":          "/tmp/backups",
+				"backupCount":        3,
+				"backupAbortOnError": true,
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				WriteBackup:           true,
+				BackupDir:             "/tmp/backups",
+				BackupCount:           3,
+				BackupAbortOnError:    true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "digraphs",
+			input: map[string]any{
+				"digraphs": map[string]any{
+					"ok": "✓",
+					"xx": 1,
+				},
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Digraphs:              map[string]string{"ok": "✓"},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "abbreviations",
+			input: map[string]any{
+				"abbreviations": map[string]any{
+					"teh": "the",
+					"xx":  1,
+				},
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Abbreviations:         map[string]string{"teh": "the"},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "snippets",
+			input: map[string]any{
+				"snippets": map[string]any{
+					"fn": "func ${1:name}() {\n\t$0\n}",
+				},
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Snippets:              map[string]string{"fn": "func ${1:name}() {\n\t$0\n}"},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
+		{
+			name: "var tab stops",
+			input: map[string]any{
+				"varTabStops": []any{4, 8.0},
+			},
+			expected: Config{
+				SyntaxLanguage:        "plaintext",
+				TabSize:               4,
+				SmartCase:             true,
+				WrapScan:              true,
+				VarTabStops:           []int{4, 8},
+				LineWrap:              "character",
+				InsertArrowKeys:       "move",
+				VirtualEdit:           "none",
+				StartOfLine:           true,
+				BoundaryBehavior:      "stay",
+				CursorLineNumberAlign: "left",
+				MatchPairs:            "(:),[:],{:}",
+				TextWidth:             80,
+				CommitSubjectWidth:    50,
+				CommitBodyWidth:       72,
+				HighlightYankDuration: 150,
+				ShowCmd:               true,
+				MultiCursor:           false,
+				DateFormat:            "%Y-%m-%d",
+				TimeFormat:            "%H:%M",
+				DetectIndent:          true,
+				Modeline:              true,
+				CursorShapeNormal:     "block",
+				CursorShapeInsert:     "bar",
+				CursorShapeVisual:     "block",
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -105,6 +890,146 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectErrMsg: "TabSize must be greater than zero",
 		},
+		{
+			name: "textWidth zero is invalid",
+			updateFunc: func(c *Config) {
+				c.TextWidth = 0
+			},
+			expectErrMsg: "TextWidth must be greater than zero",
+		},
+		{
+			name: "colorColumn not a number is invalid",
+			updateFunc: func(c *Config) {
+				c.ColorColumn = "80,abc"
+			},
+			expectErrMsg: `ColorColumn has invalid column "abc"`,
+		},
+		{
+			name: "colorColumn before the first column is invalid",
+			updateFunc: func(c *Config) {
+				c.ColorColumn = "-80"
+			},
+			expectErrMsg: `ColorColumn must contain only columns greater than zero, but got "-80"`,
+		},
+		{
+			name: "matchPairs without a colon is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchPairs = "(:),<>"
+			},
+			expectErrMsg: `MatchPairs has invalid pair "<>"`,
+		},
+		{
+			name: "matchPairs with the same open and close character is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchPairs = "|:|"
+			},
+			expectErrMsg: `MatchPairs has invalid pair "|:|"`,
+		},
+		{
+			name: "matchPairs with a character in more than one pair is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchPairs = "(:),(:]"
+			},
+			expectErrMsg: `MatchPairs must not contain '(' in more than one pair`,
+		},
+		{
+			name: "matchWords with one keyword list is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchWords = "if:fi,do"
+			},
+			expectErrMsg: `MatchWords has invalid group "do"`,
+		},
+		{
+			name: "matchWords with an empty keyword is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchWords = "if:|else:fi"
+			},
+			expectErrMsg: `MatchWords has invalid group "if:|else:fi"`,
+		},
+		{
+			name: "matchWords with a line start close keyword is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchWords = "^if:^fi"
+			},
+			expectErrMsg: `MatchWords has invalid group "^if:^fi"`,
+		},
+		{
+			name: "matchWords with a repeated line start keyword is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchWords = "if|^if:fi"
+			},
+			expectErrMsg: `MatchWords must not contain "if" more than once`,
+		},
+		{
+			name: "matchWords with a repeated keyword is invalid",
+			updateFunc: func(c *Config) {
+				c.MatchWords = "if:end,def:end"
+			},
+			expectErrMsg: `MatchWords must not contain "end" more than once`,
+		},
+		{
+			name: "statusMsgTimeout negative is invalid",
+			updateFunc: func(c *Config) {
+				c.StatusMsgTimeout = -1
+			},
+			expectErrMsg: "StatusMsgTimeout must be greater than or equal to zero",
+		},
+		{
+			name: "backupCount negative is invalid",
+			updateFunc: func(c *Config) {
+				c.BackupCount = -1
+			},
+			expectErrMsg: "BackupCount must be greater than or equal to zero",
+		},
+		{
+			name: "commitSubjectWidth zero is valid",
+			updateFunc: func(c *Config) {
+				c.CommitSubjectWidth = 0
+			},
+			expectErrMsg: "",
+		},
+		{
+			name: "commitSubjectWidth negative is invalid",
+			updateFunc: func(c *Config) {
+				c.CommitSubjectWidth = -1
+			},
+			expectErrMsg: "CommitSubjectWidth must be greater than or equal to zero",
+		},
+		{
+			name: "commitBodyWidth negative is invalid",
+			updateFunc: func(c *Config) {
+				c.CommitBodyWidth = -1
+			},
+			expectErrMsg: "CommitBodyWidth must be greater than or equal to zero",
+		},
+		{
+			name: "varTabStops zero is invalid",
+			updateFunc: func(c *Config) {
+				c.VarTabStops = []int{4, 0}
+			},
+			expectErrMsg: "VarTabStops must contain only values greater than zero",
+		},
+		{
+			name: "highlightYankDuration zero is invalid",
+			updateFunc: func(c *Config) {
+				c.HighlightYankDuration = 0
+			},
+			expectErrMsg: "HighlightYankDuration must be greater than zero",
+		},
+		{
+			name: "cursorShapeInsert default is valid",
+			updateFunc: func(c *Config) {
+				c.CursorShapeInsert = "default"
+			},
+			expectErrMsg: "",
+		},
+		{
+			name: "cursorShapeVisual is invalid",
+			updateFunc: func(c *Config) {
+				c.CursorShapeVisual = "invalid"
+			},
+			expectErrMsg: `CursorShapeVisual must be either "default", "block", "blinkingBlock", "underline", "blinkingUnderline", "bar", or "blinkingBar"`,
+		},
 		{
 			name: "lineWrap is invalid",
 			updateFunc: func(c *Config) {
@@ -112,6 +1037,111 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectErrMsg: `LineWrap must be either "character" or "word"`,
 		},
+		{
+			name: "insertArrowKeys is invalid",
+			updateFunc: func(c *Config) {
+				c.InsertArrowKeys = "invalid"
+			},
+			expectErrMsg: `InsertArrowKeys must be either "move", "ignore", or "normal"`,
+		},
+		{
+			name: "virtualEdit is invalid",
+			updateFunc: func(c *Config) {
+				c.VirtualEdit = "block"
+			},
+			expectErrMsg: `VirtualEdit must be either "none" or "all"`,
+		},
+		{
+			name: "lineNumberSeparator is too long",
+			updateFunc: func(c *Config) {
+				c.LineNumberSeparator = "||"
+			},
+			expectErrMsg: "LineNumberSeparator must be at most one character",
+		},
+		{
+			name: "lineNumberSeparator is not printable",
+			updateFunc: func(c *Config) {
+				c.LineNumberSeparator = "\t"
+			},
+			expectErrMsg: "LineNumberSeparator must be a printable character that is one column wide",
+		},
+		{
+			name: "lineNumberSeparator is wide",
+			updateFunc: func(c *Config) {
+				c.LineNumberSeparator = "界"
+			},
+			expectErrMsg: "LineNumberSeparator must be a printable character that is one column wide",
+		},
+		{
+			name: "cursorLineNumberAlign is invalid",
+			updateFunc: func(c *Config) {
+				c.CursorLineNumberAlign = "center"
+			},
+			expectErrMsg: `CursorLineNumberAlign must be either "left" or "right"`,
+		},
+		{
+			name: "boundaryBehavior is invalid",
+			updateFunc: func(c *Config) {
+				c.BoundaryBehavior = "flash"
+			},
+			expectErrMsg: `BoundaryBehavior must be either "stay" or "beep"`,
+		},
+		{
+			name: "digraph with one character is invalid",
+			updateFunc: func(c *Config) {
+				c.Digraphs = map[string]string{"a": "ä"}
+			},
+			expectErrMsg: `Digraph "a" must have exactly two characters`,
+		},
+		{
+			name: "digraph mapping to multiple characters is invalid",
+			updateFunc: func(c *Config) {
+				c.Digraphs = map[string]string{"a:": "ae"}
+			},
+			expectErrMsg: `Digraph "a:" must map to exactly one character`,
+		},
+		{
+			name: "abbreviation is valid",
+			updateFunc: func(c *Config) {
+				c.Abbreviations = map[string]string{"teh": "the", "fn_2": "func() {\n}"}
+			},
+			expectErrMsg: "",
+		},
+		{
+			name: "abbreviation is empty",
+			updateFunc: func(c *Config) {
+				c.Abbreviations = map[string]string{"": "the"}
+			},
+			expectErrMsg: "Abbreviation cannot be empty",
+		},
+		{
+			name: "abbreviation with punctuation is invalid",
+			updateFunc: func(c *Config) {
+				c.Abbreviations = map[string]string{"a.b": "ab"}
+			},
+			expectErrMsg: `Abbreviation "a.b" must contain only letters, digits, and underscores`,
+		},
+		{
+			name: "snippet is valid",
+			updateFunc: func(c *Config) {
+				c.Snippets = map[string]string{"fn": "func ${1:name}() {\n\t$0\n}"}
+			},
+			expectErrMsg: "",
+		},
+		{
+			name: "snippet trigger with punctuation is invalid",
+			updateFunc: func(c *Config) {
+				c.Snippets = map[string]string{"f-n": "func"}
+			},
+			expectErrMsg: `Snippet trigger "f-n" must contain only letters, digits, and underscores`,
+		},
+		{
+			name: "snippet template is invalid",
+			updateFunc: func(c *Config) {
+				c.Snippets = map[string]string{"fn": "func ${1:name"}
+			},
+			expectErrMsg: `Snippet "fn" is invalid: missing "}" for tab stop $1`,
+		},
 		{
 			name: "menu name is empty",
 			updateFunc: func(c *Config) {
@@ -143,7 +1173,7 @@ func TestValidateConfig(t *testing.T) {
 					Mode:     "invalid",
 				})
 			},
-			expectErrMsg: `Menu command "testcmd" must have mode set to either "silent", "terminal", "insert", "insertChoice", "fileLocations", or "workingDir"`,
+			expectErrMsg: `Menu command "testcmd" must have mode set to either "silent", "terminal", "insert", "insertChoice", "fileLocations", "quickfix", or "workingDir"`,
 		},
 	}
 
@@ -163,3 +1193,128 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseColorColumn(t *testing.T) {
+	testCases := []struct {
+		name      string
+		s         string
+		textWidth int
+		expected  []int
+	}{
+		{
+			name:      "empty",
+			s:         "",
+			textWidth: 80,
+			expected:  nil,
+		},
+		{
+			name:      "single column",
+			s:         "81",
+			textWidth: 80,
+			expected:  []int{81},
+		},
+		{
+			name:      "multiple columns",
+			s:         "81, 101",
+			textWidth: 80,
+			expected:  []int{81, 101},
+		},
+		{
+			name:      "relative to text width",
+			s:         "+1,-10",
+			textWidth: 72,
+			expected:  []int{73, 62},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cols, err := ParseColorColumn(tc.s, tc.textWidth)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cols)
+		})
+	}
+}
+
+func TestParseMatchPairs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		s        string
+		expected []MatchPair
+	}{
+		{
+			name:     "empty",
+			s:        "",
+			expected: nil,
+		},
+		{
+			name: "default",
+			s:    DefaultMatchPairs,
+			expected: []MatchPair{
+				{Open: '(', Close: ')'},
+				{Open: '[', Close: ']'},
+				{Open: '{', Close: '}'},
+			},
+		},
+		{
+			name: "with whitespace and non-ASCII characters",
+			s:    " <:> , «:» ",
+			expected: []MatchPair{
+				{Open: '<', Close: '>'},
+				{Open: '«', Close: '»'},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pairs, err := ParseMatchPairs(tc.s)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, pairs)
+		})
+	}
+}
+
+func TestParseMatchWords(t *testing.T) {
+	testCases := []struct {
+		name     string
+		s        string
+		expected []MatchWordGroup
+	}{
+		{
+			name:     "empty",
+			s:        "",
+			expected: nil,
+		},
+		{
+			name: "open and close",
+			s:    "do:done",
+			expected: []MatchWordGroup{
+				{Open: []string{"do"}, Close: []string{"done"}},
+			},
+		},
+		{
+			name: "multiple groups with middle keywords and alternatives",
+			s:    "if:elif:else:fi, def|class:end",
+			expected: []MatchWordGroup{
+				{Open: []string{"if"}, Middle: []string{"elif", "else"}, Close: []string{"fi"}},
+				{Open: []string{"def", "class"}, Close: []string{"end"}},
+			},
+		},
+		{
+			name: "open keywords at line start",
+			s:    "def|^if|^while:else:end",
+			expected: []MatchWordGroup{
+				{Open: []string{"def"}, LineStartOpen: []string{"if", "while"}, Middle: []string{"else"}, Close: []string{"end"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			groups, err := ParseMatchWords(tc.s)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, groups)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EditorConfigFileName is the name of an EditorConfig file (https://editorconfig.org).
+// Properties from EditorConfig files apply after the rules from the user's config file,
+// but before the rules from a project config file.
+const EditorConfigFileName = ".editorconfig"
+
+// maxEditorConfigNumericRange is the largest numeric range like "{1..10}" supported in a section glob.
+const maxEditorConfigNumericRange = 1000
+
+// editorConfigFile is a parsed EditorConfig file.
+type editorConfigFile struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+// editorConfigSection is a glob and the properties that apply to files matching it.
+// Property names and values are lowercase, since EditorConfig treats them as case-insensitive.
+type editorConfigSection struct {
+	glob  string
+	props map[string]string
+}
+
+// LoadEditorConfig finds the EditorConfig files for a path and translates their properties to configuration.
+// It reads the files in the directory containing path and each parent directory,
+// stopping at the root directory or a file with "root = true".
+// Properties from closer files replace properties from files further away.
+// The returned configuration is nil if no properties apply to the path.
+func LoadEditorConfig(path string) (map[string]any, error) {
+	var dirs []string
+	var files []editorConfigFile
+	dir := filepath.Dir(path)
+	for {
+		configPath := filepath.Join(dir, EditorConfigFileName)
+		data, err := os.ReadFile(configPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrap(err, "os.ReadFile")
+		} else if err == nil {
+			log.Printf("Loading EditorConfig from %q\n", configPath)
+			f := parseEditorConfig(data)
+			dirs = append(dirs, dir)
+			files = append(files, f)
+			if f.root {
+				break
+			}
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			break
+		}
+		dir = parentDir
+	}
+
+	// Apply files from the furthest directory to the closest, so closer files take precedence.
+	props := make(map[string]string)
+	for i := len(files) - 1; i >= 0; i-- {
+		for _, section := range files[i].sections {
+			if editorConfigGlobMatch(section.glob, dirs[i], path) {
+				for k, v := range section.props {
+					props[k] = v
+				}
+			}
+		}
+	}
+
+	c := configFromEditorConfigProps(props)
+	if len(c) == 0 {
+		return nil, nil
+	}
+	log.Printf("Resolved EditorConfig for path %q: %#v\n", path, c)
+	return c, nil
+}
+
+// parseEditorConfig parses the INI-like format of an EditorConfig file.
+// Invalid lines are ignored, like other EditorConfig implementations.
+func parseEditorConfig(data []byte) editorConfigFile {
+	var f editorConfigFile
+	var section *editorConfigSection
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			f.sections = append(f.sections, editorConfigSection{
+				glob:  line[1 : len(line)-1],
+				props: make(map[string]string),
+			})
+			section = &f.sections[len(f.sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		if section == nil {
+			// Properties before the first section apply to the file itself.
+			if key == "root" {
+				f.root = (value == "true")
+			}
+			continue
+		}
+
+		section.props[key] = value
+	}
+	return f
+}
+
+// editorConfigGlobMatch checks whether a section glob from the EditorConfig file in dir matches path.
+// A glob without a "/" matches the file name in any subdirectory, and a glob with a "/"
+// matches the path relative to dir.
+func editorConfigGlobMatch(glob string, dir string, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	prefix := "(?:.*/)?"
+	if strings.Contains(glob, "/") {
+		prefix = ""
+		glob = strings.TrimPrefix(glob, "/")
+	}
+
+	pattern, ok := editorConfigGlobToRegexp(glob)
+	if !ok {
+		log.Printf("Unsupported EditorConfig glob %q\n", glob)
+		return false
+	}
+
+	re, err := regexp.Compile("^" + prefix + pattern + "$")
+	if err != nil {
+		log.Printf("Could not compile EditorConfig glob %q: %v\n", glob, err)
+		return false
+	}
+	return re.MatchString(relPath)
+}
+
+var editorConfigNumericRangeRegexp = regexp.MustCompile(`^([+-]?\d+)\.\.([+-]?\d+)$`)
+
+// editorConfigGlobToRegexp translates an EditorConfig glob to a regular expression.
+// "*" matches any characters except "/", "**" matches any characters, "?" matches one character,
+// "[abc]" and "[!abc]" match characters in or not in a set, "{a,b}" matches any of the comma-separated
+// globs, and "{1..3}" matches integers in a range.
+func editorConfigGlobToRegexp(glob string) (string, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			} else {
+				sb.WriteString(`\\`)
+			}
+
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+
+		case '?':
+			sb.WriteString("[^/]")
+
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			i += end + 1
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+
+		case '{':
+			end := matchingBraceIdx(glob, i)
+			if end < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			inner := glob[i+1 : end]
+			i = end
+
+			if m := editorConfigNumericRangeRegexp.FindStringSubmatch(inner); m != nil {
+				s, ok := numericRangeRegexp(m[1], m[2])
+				if !ok {
+					return "", false
+				}
+				sb.WriteString(s)
+				continue
+			}
+
+			alternatives := splitTopLevelCommas(inner)
+			if len(alternatives) < 2 {
+				// A brace without commas is a literal, such as "{single}".
+				sb.WriteString(regexp.QuoteMeta("{" + inner + "}"))
+				continue
+			}
+
+			sb.WriteString("(?:")
+			for j, alt := range alternatives {
+				if j > 0 {
+					sb.WriteString("|")
+				}
+				s, ok := editorConfigGlobToRegexp(alt)
+				if !ok {
+					return "", false
+				}
+				sb.WriteString(s)
+			}
+			sb.WriteString(")")
+
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return sb.String(), true
+}
+
+// matchingBraceIdx returns the index of the "}" that closes the "{" at startIdx, or -1 if there is none.
+func matchingBraceIdx(s string, startIdx int) int {
+	depth := 0
+	for i := startIdx; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevelCommas splits a string on commas that aren't nested in braces.
+func splitTopLevelCommas(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// numericRangeRegexp returns a regular expression matching any integer from lo to hi.
+func numericRangeRegexp(loStr, hiStr string) (string, bool) {
+	lo, err := strconv.Atoi(loStr)
+	if err != nil {
+		return "", false
+	}
+	hi, err := strconv.Atoi(hiStr)
+	if err != nil {
+		return "", false
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if hi-lo > maxEditorConfigNumericRange {
+		return "", false
+	}
+
+	nums := make([]string, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		nums = append(nums, regexp.QuoteMeta(strconv.Itoa(n)))
+	}
+	return "(?:" + strings.Join(nums, "|") + ")", true
+}
+
+// configFromEditorConfigProps translates EditorConfig properties to configuration.
+// Only the indentation properties have an equivalent configuration, so other properties are ignored.
+// A property set to "unset" in a closer file has no valid value, so it removes the property.
+func configFromEditorConfigProps(props map[string]string) map[string]any {
+	c := make(map[string]any)
+
+	indentStyle := props["indent_style"]
+	switch indentStyle {
+	case "tab":
+		c["tabExpand"] = false
+	case "space":
+		c["tabExpand"] = true
+	}
+
+	// The tab size sets both the width of a tab and the number of spaces to indent,
+	// so use the indent size when indenting with spaces, and the tab width otherwise.
+	indentSize, hasIndentSize := editorConfigPositiveInt(props["indent_size"])
+	tabWidth, hasTabWidth := editorConfigPositiveInt(props["tab_width"])
+	if indentStyle == "tab" || props["indent_size"] == "tab" {
+		if hasTabWidth {
+			c["tabSize"] = tabWidth
+		} else if hasIndentSize {
+			c["tabSize"] = indentSize
+		}
+	} else if hasIndentSize {
+		c["tabSize"] = indentSize
+	} else if hasTabWidth {
+		c["tabSize"] = tabWidth
+	}
+
+	if len(c) > 0 {
+		// Explicit indentation takes precedence over guessing it from the document's content.
+		c["detectIndent"] = false
+	}
+
+	return c
+}
+
+func editorConfigPositiveInt(s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
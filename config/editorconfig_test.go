@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditorConfigGlobMatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		glob     string
+		relPath  string
+		expected bool
+	}{
+		{name: "star matches file name", glob: "*", relPath: "test.go", expected: true},
+		{name: "star matches file in subdirectory", glob: "*", relPath: "a/b/test.go", expected: true},
+		{name: "extension", glob: "*.go", relPath: "a/test.go", expected: true},
+		{name: "extension mismatch", glob: "*.go", relPath: "test.py", expected: false},
+		{name: "exact name", glob: "Makefile", relPath: "a/Makefile", expected: true},
+		{name: "exact name mismatch", glob: "Makefile", relPath: "a/Makefile.am", expected: false},
+		{name: "question mark", glob: "?.md", relPath: "a.md", expected: true},
+		{name: "question mark too many chars", glob: "?.md", relPath: "ab.md", expected: false},
+		{name: "character class", glob: "[ab].txt", relPath: "b.txt", expected: true},
+		{name: "character class mismatch", glob: "[ab].txt", relPath: "c.txt", expected: false},
+		{name: "negated character class", glob: "[!ab].txt", relPath: "c.txt", expected: true},
+		{name: "negated character class mismatch", glob: "[!ab].txt", relPath: "a.txt", expected: false},
+		{name: "braces", glob: "*.{js,py}", relPath: "test.py", expected: true},
+		{name: "braces mismatch", glob: "*.{js,py}", relPath: "test.go", expected: false},
+		{name: "nested braces", glob: "{a,{b,c}}.txt", relPath: "c.txt", expected: true},
+		{name: "braces without comma are literal", glob: "{single}.txt", relPath: "{single}.txt", expected: true},
+		{name: "numeric range", glob: "file{1..3}.txt", relPath: "file2.txt", expected: true},
+		{name: "numeric range mismatch", glob: "file{1..3}.txt", relPath: "file4.txt", expected: false},
+		{name: "escaped star", glob: `\*.txt`, relPath: "*.txt", expected: true},
+		{name: "escaped star mismatch", glob: `\*.txt`, relPath: "a.txt", expected: false},
+		{name: "path relative to config dir", glob: "lib/*.js", relPath: "lib/test.js", expected: true},
+		{name: "path relative to config dir mismatch", glob: "lib/*.js", relPath: "src/lib/test.js", expected: false},
+		{name: "path with leading slash", glob: "/lib/*.js", relPath: "lib/test.js", expected: true},
+		{name: "star does not match slash", glob: "lib/*.js", relPath: "lib/a/test.js", expected: false},
+		{name: "double star matches slash", glob: "lib/**.js", relPath: "lib/a/test.js", expected: true},
+		{name: "double star directory", glob: "**/vendor/*", relPath: "a/vendor/test.go", expected: true},
+		{name: "regexp metacharacters", glob: "a+b.(c)", relPath: "a+b.(c)", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := "project"
+			path := filepath.Join(dir, filepath.FromSlash(tc.relPath))
+			assert.Equal(t, tc.expected, editorConfigGlobMatch(tc.glob, dir, path))
+		})
+	}
+}
+
+func TestParseEditorConfig(t *testing.T) {
+	data := `# comment
+; another comment
+root = TRUE
+
+[*]
+Indent_Style = Space
+indent_size = 4
+
+invalid line
+[*.go]
+indent_style = tab
+`
+	expected := editorConfigFile{
+		root: true,
+		sections: []editorConfigSection{
+			{
+				glob:  "*",
+				props: map[string]string{"indent_style": "space", "indent_size": "4"},
+			},
+			{
+				glob:  "*.go",
+				props: map[string]string{"indent_style": "tab"},
+			},
+		},
+	}
+	assert.Equal(t, expected, parseEditorConfig([]byte(data)))
+}
+
+func TestLoadEditorConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		outerConfig string
+		innerConfig string
+		path        string
+		expected    map[string]any
+	}{
+		{
+			name:     "no config files",
+			path:     "inner/test.go",
+			expected: nil,
+		},
+		{
+			name:        "no matching sections",
+			innerConfig: "[*.py]\nindent_style = space\n",
+			path:        "inner/test.go",
+			expected:    nil,
+		},
+		{
+			name:        "unsupported properties",
+			innerConfig: "[*]\nend_of_line = lf\ninsert_final_newline = true\ncharset = utf-8\n",
+			path:        "inner/test.go",
+			expected:    nil,
+		},
+		{
+			name:        "indent with spaces",
+			innerConfig: "[*]\nindent_style = space\nindent_size = 2\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": true, "tabSize": 2, "detectIndent": false},
+		},
+		{
+			name:        "indent with tabs uses tab width",
+			innerConfig: "[*]\nindent_style = tab\nindent_size = 4\ntab_width = 8\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": false, "tabSize": 8, "detectIndent": false},
+		},
+		{
+			name:        "indent size tab uses tab width",
+			innerConfig: "[*]\nindent_size = tab\ntab_width = 3\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabSize": 3, "detectIndent": false},
+		},
+		{
+			name:        "tab width without indent size",
+			innerConfig: "[*]\ntab_width = 6\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabSize": 6, "detectIndent": false},
+		},
+		{
+			name:        "invalid indent size",
+			innerConfig: "[*]\nindent_size = 0\n",
+			path:        "inner/test.go",
+			expected:    nil,
+		},
+		{
+			name:        "later section overrides earlier section",
+			innerConfig: "[*]\nindent_style = space\nindent_size = 4\n\n[*.go]\nindent_style = tab\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": false, "tabSize": 4, "detectIndent": false},
+		},
+		{
+			name:        "inner config overrides outer config",
+			outerConfig: "root = true\n[*]\nindent_style = space\nindent_size = 4\n",
+			innerConfig: "[*]\nindent_size = 2\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": true, "tabSize": 2, "detectIndent": false},
+		},
+		{
+			name:        "inner config unsets property",
+			outerConfig: "root = true\n[*]\nindent_style = space\nindent_size = 4\n",
+			innerConfig: "[*]\nindent_size = unset\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": true, "detectIndent": false},
+		},
+		{
+			name:        "inner config with root ignores outer config",
+			outerConfig: "[*]\nindent_style = space\n",
+			innerConfig: "root = true\n[*]\nindent_size = 2\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabSize": 2, "detectIndent": false},
+		},
+		{
+			name:        "outer config path glob relative to outer directory",
+			outerConfig: "root = true\n[inner/*.go]\nindent_style = tab\n",
+			path:        "inner/test.go",
+			expected:    map[string]any{"tabExpand": false, "detectIndent": false},
+		},
+		{
+			name:        "inner config does not apply outside its directory",
+			outerConfig: "root = true\n",
+			innerConfig: "[*]\nindent_style = tab\n",
+			path:        "test.go",
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Always write a root config so config files outside the temp dir are ignored.
+			dir := t.TempDir()
+			outerConfig := tc.outerConfig
+			if outerConfig == "" {
+				outerConfig = "root = true\n"
+			}
+			err := os.WriteFile(filepath.Join(dir, EditorConfigFileName), []byte(outerConfig), 0644)
+			require.NoError(t, err)
+
+			innerDir := filepath.Join(dir, "inner")
+			err = os.Mkdir(innerDir, 0755)
+			require.NoError(t, err)
+			if tc.innerConfig != "" {
+				err = os.WriteFile(filepath.Join(innerDir, EditorConfigFileName), []byte(tc.innerConfig), 0644)
+				require.NoError(t, err)
+			}
+
+			c, err := LoadEditorConfig(filepath.Join(dir, filepath.FromSlash(tc.path)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, c)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFileName is the name of a project config file.
+// Rules from the closest project config file apply after the rules from the user's config file.
+const ProjectConfigFileName = ".aretext.yaml"
+
+// projectConfigKeys are the config keys that a project config file can set.
+// A project config file might come from a source the user doesn't trust, such as a cloned repository,
+// so it can set only options that describe how the project's files are formatted.
+// Options that run commands, such as menuCommands, linter, and languageServer, are ignored.
+var projectConfigKeys = map[string]struct{}{
+	"syntaxLanguage":     {},
+	"tabSize":            {},
+	"varTabStops":        {},
+	"tabExpand":          {},
+	"detectIndent":       {},
+	"autoIndent":         {},
+	"continueComments":   {},
+	"autoCloseTags":      {},
+	"textWidth":          {},
+	"colorColumn":        {},
+	"matchPairs":         {},
+	"matchWords":         {},
+	"matchTags":          {},
+	"commitSubjectWidth": {},
+	"commitBodyWidth":    {},
+}
+
+// UnmarshalRuleSet parses configuration rules from YAML.
+func UnmarshalRuleSet(data []byte) (RuleSet, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrap(err, "yaml")
+	}
+	return RuleSet(rules), nil
+}
+
+// FindProjectConfig searches for a project config file in the directory containing path,
+// then in each parent directory up to the root.
+// It returns the path to the closest project config file, or an empty string if there is none.
+func FindProjectConfig(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		configPath := filepath.Join(dir, ProjectConfigFileName)
+		if fileInfo, err := os.Stat(configPath); err == nil && fileInfo.Mode().IsRegular() {
+			return configPath
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return ""
+		}
+		dir = parentDir
+	}
+}
+
+// LoadProjectRuleSet loads and validates the rules from a project config file.
+func LoadProjectRuleSet(configPath string) (RuleSet, error) {
+	log.Printf("Loading project config from %q\n", configPath)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+
+	ruleSet, err := UnmarshalRuleSet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range ruleSet {
+		ruleSet[i].Config = filterProjectConfig(rule)
+	}
+
+	if err := ruleSet.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return ruleSet, nil
+}
+
+// filterProjectConfig removes config keys that a project config file is not allowed to set.
+func filterProjectConfig(rule Rule) map[string]any {
+	if rule.Config == nil {
+		return nil
+	}
+
+	filtered := make(map[string]any, len(rule.Config))
+	for key, val := range rule.Config {
+		if _, ok := projectConfigKeys[key]; !ok {
+			log.Printf("Ignoring %q in project config rule %q\n", key, rule.Name)
+			continue
+		}
+		filtered[key] = val
+	}
+	return filtered
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	projectDir := filepath.Join(dir, "project")
+	subDir := filepath.Join(projectDir, "a", "b")
+	err := os.MkdirAll(subDir, 0755)
+	require.NoError(t, err)
+
+	projectConfigPath := filepath.Join(projectDir, ProjectConfigFileName)
+	err = os.WriteFile(projectConfigPath, nil, 0644)
+	require.NoError(t, err)
+
+	// A directory with the config file name is not a config file.
+	err = os.Mkdir(filepath.Join(subDir, ProjectConfigFileName), 0755)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "file in project directory",
+			path:     filepath.Join(projectDir, "test.go"),
+			expected: projectConfigPath,
+		},
+		{
+			name:     "file in nested directory",
+			path:     filepath.Join(subDir, "test.go"),
+			expected: projectConfigPath,
+		},
+		{
+			name:     "file outside project directory",
+			path:     filepath.Join(dir, "test.go"),
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FindProjectConfig(tc.path))
+		})
+	}
+}
+
+func TestFindProjectConfigClosest(t *testing.T) {
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "sub")
+	err := os.Mkdir(subDir, 0755)
+	require.NoError(t, err)
+
+	for _, d := range []string{dir, subDir} {
+		err = os.WriteFile(filepath.Join(d, ProjectConfigFileName), nil, 0644)
+		require.NoError(t, err)
+	}
+
+	path := FindProjectConfig(filepath.Join(subDir, "test.go"))
+	assert.Equal(t, filepath.Join(subDir, ProjectConfigFileName), path)
+}
+
+func TestLoadProjectRuleSet(t *testing.T) {
+	testCases := []struct {
+		name          string
+		data          string
+		expected      RuleSet
+		expectedError string
+	}{
+		{
+			name: "valid config",
+			data: "- name: go\n  pattern: \"**/*.go\"\n  config:\n    tabSize: 8\n",
+			expected: RuleSet{
+				{
+					Name:    "go",
+					Pattern: "**/*.go",
+					Config:  map[string]any{"tabSize": 8},
+				},
+			},
+		},
+		{
+			name: "ignore keys that projects cannot set",
+			data: "- name: go\n  pattern: \"**/*.go\"\n  config:\n    tabSize: 8\n    languageServer: gopls\n    linter: make lint\n    menuCommands:\n      - name: run\n        shellCmd: ./run.sh\n",
+			expected: RuleSet{
+				{
+					Name:    "go",
+					Pattern: "**/*.go",
+					Config:  map[string]any{"tabSize": 8},
+				},
+			},
+		},
+		{
+			name:          "invalid yaml",
+			data:          "- name: [",
+			expectedError: "yaml",
+		},
+		{
+			name:          "invalid config",
+			data:          "- name: bad\n  pattern: \"**\"\n  config:\n    tabSize: 0\n",
+			expectedError: "invalid configuration: TabSize must be greater than zero",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ProjectConfigFileName)
+			err := os.WriteFile(path, []byte(tc.data), 0644)
+			require.NoError(t, err)
+
+			ruleSet, err := LoadProjectRuleSet(path)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, ruleSet)
+			}
+		})
+	}
+}
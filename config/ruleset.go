@@ -32,6 +32,19 @@ func (rs RuleSet) ConfigForPath(path string) Config {
 	return ConfigFromUntypedMap(c)
 }
 
+// KeysForPath returns the top-level configuration keys set by any rule that matches the file path.
+func (rs RuleSet) KeysForPath(path string) map[string]struct{} {
+	keys := make(map[string]struct{}, 0)
+	for _, rule := range rs {
+		if file.GlobMatch(rule.Pattern, path) {
+			for k := range rule.Config {
+				keys[k] = struct{}{}
+			}
+		}
+	}
+	return keys
+}
+
 // Validate checks whether every rule in the set has a valid configuration.
 func (rs RuleSet) Validate() error {
 	for _, r := range rs {
@@ -18,13 +18,35 @@ func TestConfigForPath(t *testing.T) {
 			ruleSet: nil,
 			path:    "test.go",
 			expectedConfig: Config{
-				SyntaxLanguage: DefaultSyntaxLanguage,
-				TabSize:        DefaultTabSize,
-				TabExpand:      DefaultTabExpand,
-				AutoIndent:     DefaultAutoIndent,
-				LineWrap:       DefaultLineWrap,
-				MenuCommands:   []MenuCommandConfig{},
-				Styles:         map[string]StyleConfig{},
+				SyntaxLanguage:        DefaultSyntaxLanguage,
+				TabSize:               DefaultTabSize,
+				TabExpand:             DefaultTabExpand,
+				SmartCase:             DefaultSmartCase,
+				WrapScan:              DefaultWrapScan,
+				AutoIndent:            DefaultAutoIndent,
+				LineWrap:              DefaultLineWrap,
+				InsertArrowKeys:       DefaultInsertArrowKeys,
+				VirtualEdit:           DefaultVirtualEdit,
+				StartOfLine:           DefaultStartOfLine,
+				BoundaryBehavior:      DefaultBoundaryBehavior,
+				CursorLineNumberAlign: DefaultCursorLineNumberAlign,
+				MatchPairs:            DefaultMatchPairs,
+				ReselectAfterUndo:     DefaultReselectAfterUndo,
+				TextWidth:             DefaultTextWidth,
+				CommitSubjectWidth:    DefaultCommitSubjectWidth,
+				CommitBodyWidth:       DefaultCommitBodyWidth,
+				HighlightYankDuration: DefaultHighlightYankDuration,
+				ShowCmd:               DefaultShowCmd,
+				MultiCursor:           DefaultMultiCursor,
+				DateFormat:            DefaultDateFormat,
+				TimeFormat:            DefaultTimeFormat,
+				DetectIndent:          DefaultDetectIndent,
+				Modeline:              DefaultModeline,
+				CursorShapeNormal:     DefaultCursorShapeNormal,
+				CursorShapeInsert:     DefaultCursorShapeInsert,
+				CursorShapeVisual:     DefaultCursorShapeVisual,
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
 			},
 		},
 		{
@@ -47,13 +69,35 @@ func TestConfigForPath(t *testing.T) {
 			},
 			path: "test.json",
 			expectedConfig: Config{
-				SyntaxLanguage: "json",
-				TabSize:        DefaultTabSize,
-				TabExpand:      DefaultTabExpand,
-				LineWrap:       DefaultLineWrap,
-				AutoIndent:     DefaultAutoIndent,
-				MenuCommands:   []MenuCommandConfig{},
-				Styles:         map[string]StyleConfig{},
+				SyntaxLanguage:        "json",
+				TabSize:               DefaultTabSize,
+				TabExpand:             DefaultTabExpand,
+				SmartCase:             DefaultSmartCase,
+				WrapScan:              DefaultWrapScan,
+				LineWrap:              DefaultLineWrap,
+				InsertArrowKeys:       DefaultInsertArrowKeys,
+				VirtualEdit:           DefaultVirtualEdit,
+				StartOfLine:           DefaultStartOfLine,
+				BoundaryBehavior:      DefaultBoundaryBehavior,
+				CursorLineNumberAlign: DefaultCursorLineNumberAlign,
+				MatchPairs:            DefaultMatchPairs,
+				ReselectAfterUndo:     DefaultReselectAfterUndo,
+				TextWidth:             DefaultTextWidth,
+				CommitSubjectWidth:    DefaultCommitSubjectWidth,
+				CommitBodyWidth:       DefaultCommitBodyWidth,
+				HighlightYankDuration: DefaultHighlightYankDuration,
+				ShowCmd:               DefaultShowCmd,
+				MultiCursor:           DefaultMultiCursor,
+				DateFormat:            DefaultDateFormat,
+				TimeFormat:            DefaultTimeFormat,
+				DetectIndent:          DefaultDetectIndent,
+				Modeline:              DefaultModeline,
+				CursorShapeNormal:     DefaultCursorShapeNormal,
+				CursorShapeInsert:     DefaultCursorShapeInsert,
+				CursorShapeVisual:     DefaultCursorShapeVisual,
+				AutoIndent:            DefaultAutoIndent,
+				MenuCommands:          []MenuCommandConfig{},
+				Styles:                map[string]StyleConfig{},
 			},
 		},
 	}
@@ -65,3 +109,134 @@ func TestConfigForPath(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigForPathOverlappingRules(t *testing.T) {
+	ruleSet := RuleSet{
+		{
+			Name:    "default",
+			Pattern: "**",
+			Config: map[string]any{
+				"tabSize":         4,
+				"tabExpand":       true,
+				"hideDirectories": []any{"**/.git"},
+			},
+		},
+		{
+			Name:    "go",
+			Pattern: "**/*.go",
+			Config: map[string]any{
+				"tabSize":   8,
+				"tabExpand": false,
+			},
+		},
+		{
+			Name:    "makefile",
+			Pattern: "**/Makefile",
+			Config: map[string]any{
+				"tabExpand": false,
+			},
+		},
+		{
+			Name:    "project",
+			Pattern: "**/myproject/**",
+			Config: map[string]any{
+				"tabSize":         2,
+				"hideDirectories": []any{"**/node_modules"},
+			},
+		},
+		{
+			Name:    "project go",
+			Pattern: "**/myproject/**/*.go",
+			Config: map[string]any{
+				"tabSize": 3,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                    string
+		path                    string
+		expectedTabSize         int
+		expectedTabExpand       bool
+		expectedHideDirectories []string
+	}{
+		{
+			name:                    "only default rule matches",
+			path:                    "/home/user/notes.txt",
+			expectedTabSize:         4,
+			expectedTabExpand:       true,
+			expectedHideDirectories: []string{"**/.git"},
+		},
+		{
+			name:                    "later extension rule overrides default",
+			path:                    "/home/user/main.go",
+			expectedTabSize:         8,
+			expectedTabExpand:       false,
+			expectedHideDirectories: []string{"**/.git"},
+		},
+		{
+			name:                    "filename rule overrides only the values it sets",
+			path:                    "/home/user/src/Makefile",
+			expectedTabSize:         4,
+			expectedTabExpand:       false,
+			expectedHideDirectories: []string{"**/.git"},
+		},
+		{
+			name:                    "directory rule after extension rule takes precedence",
+			path:                    "/home/user/myproject/lib/util.py",
+			expectedTabSize:         2,
+			expectedTabExpand:       true,
+			expectedHideDirectories: []string{"**/.git", "**/node_modules"},
+		},
+		{
+			name:                    "most specific rule last takes precedence",
+			path:                    "/home/user/myproject/cmd/main.go",
+			expectedTabSize:         3,
+			expectedTabExpand:       false,
+			expectedHideDirectories: []string{"**/.git", "**/node_modules"},
+		},
+		{
+			name:                    "filename rule inside directory rule",
+			path:                    "/home/user/myproject/Makefile",
+			expectedTabSize:         2,
+			expectedTabExpand:       false,
+			expectedHideDirectories: []string{"**/.git", "**/node_modules"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := ruleSet.ConfigForPath(tc.path)
+			assert.Equal(t, tc.expectedTabSize, c.TabSize)
+			assert.Equal(t, tc.expectedTabExpand, c.TabExpand)
+			assert.Equal(t, tc.expectedHideDirectories, c.HideDirectories)
+		})
+	}
+}
+
+func TestConfigForPathRuleOrder(t *testing.T) {
+	// The same two overlapping rules in opposite orders produce different configs,
+	// because the later rule always wins regardless of how specific its pattern is.
+	goRule := Rule{Name: "go", Pattern: "**/*.go", Config: map[string]any{"tabSize": 8}}
+	defaultRule := Rule{Name: "default", Pattern: "**", Config: map[string]any{"tabSize": 2}}
+
+	c := RuleSet{defaultRule, goRule}.ConfigForPath("/src/main.go")
+	assert.Equal(t, 8, c.TabSize)
+
+	c = RuleSet{goRule, defaultRule}.ConfigForPath("/src/main.go")
+	assert.Equal(t, 2, c.TabSize)
+}
+
+func TestKeysForPath(t *testing.T) {
+	ruleSet := RuleSet{
+		{Name: "default", Pattern: "**", Config: map[string]any{"autoIndent": true}},
+		{Name: "go", Pattern: "**/*.go", Config: map[string]any{"tabSize": 8, "tabExpand": false}},
+		{Name: "python", Pattern: "**/*.py", Config: map[string]any{"tabSize": 4}},
+	}
+
+	keys := ruleSet.KeysForPath("/src/main.go")
+	assert.Equal(t, map[string]struct{}{"autoIndent": {}, "tabSize": {}, "tabExpand": {}}, keys)
+
+	keys = ruleSet.KeysForPath("/src/README.md")
+	assert.Equal(t, map[string]struct{}{"autoIndent": {}}, keys)
+}
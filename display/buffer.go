@@ -3,10 +3,12 @@ package display
 import (
 	"io"
 	"log"
+	"sort"
 	"strconv"
 
 	"github.com/gdamore/tcell/v2"
 
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/state"
 	"github.com/aretext/aretext/syntax/parser"
@@ -14,21 +16,34 @@ import (
 )
 
 // DrawBuffer draws text buffer in the screen.
-func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState) {
+// If searchHighlight is true, this highlights every match for the last search query.
+func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState, searchHighlight bool) {
 	x, y, width, height := viewDimensions(buffer)
 	sr := NewScreenRegion(screen, x, y, width, height)
 	textTree := buffer.TextTree()
 	cursorPos := buffer.CursorPosition()
+	cursorVirtualOffset := buffer.CursorVirtualOffset() // Zero unless virtual edit is enabled.
 	selectedRegion := buffer.SelectedRegion()
+	yankHighlight := buffer.YankHighlight()
+	extraCursors := buffer.ExtraCursors()
 	viewTextOrigin := buffer.ViewTextOrigin()
 	pos := viewTextOrigin
 	showTabs := buffer.ShowTabs()
 	showSpaces := buffer.ShowSpaces()
 	lineNumMargin := buffer.LineNumMarginWidth() // Zero if line numbers disabled.
+	lineNums := lineNumConfig{
+		margin:          lineNumMargin,
+		separator:       buffer.LineNumSeparator(),
+		relative:        buffer.RelativeLineNums(),
+		cursorLineNum:   textTree.LineNumForPosition(cursorPos),
+		alignCursorLeft: buffer.CursorLineNumAlign() == config.LineNumberAlignLeft,
+	}
+	signColumnWidth := buffer.SignColumnWidth() // Zero if the document isn't tracked in git and has no diagnostics.
 	wrapConfig := buffer.LineWrapConfig()
 	wrappedLineIter := segment.NewWrappedLineIter(wrapConfig, textTree, pos)
 	wrappedLine := segment.Empty()
 	searchMatch := buffer.SearchMatch()
+	colorColumns := buffer.ColorColumns()
 
 	sr.HideCursor()
 
@@ -41,8 +56,13 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 		}
 		lineNum := textTree.LineNumForPosition(pos)
 		lineStartPos := textTree.LineStartPosition(lineNum)
+		lineLengthLimit := buffer.LineLengthLimit(lineNum) // Zero unless the document is a git commit message.
 		wrappedLineRunes := wrappedLine.Runes()
 		syntaxTokens := buffer.SyntaxTokensIntersectingRange(pos, pos+uint64(len(wrappedLineRunes)))
+		var searchHighlights []state.SearchMatch
+		if searchHighlight {
+			searchHighlights = buffer.SearchMatchesInRange(pos, pos+uint64(len(wrappedLineRunes)))
+		}
 		drawLineAndSetCursor(
 			sr,
 			palette,
@@ -50,13 +70,22 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 			row,
 			int(wrapConfig.MaxLineWidth),
 			lineNum,
-			lineNumMargin,
+			lineNums,
+			signColumnWidth,
+			buffer.GitDiffSignForLine,
+			buffer.HasDiagnosticForLine,
 			lineStartPos,
+			lineLengthLimit,
 			wrappedLineRunes,
 			syntaxTokens,
 			cursorPos,
+			cursorVirtualOffset,
+			extraCursors,
 			selectedRegion,
+			yankHighlight,
 			searchMatch,
+			searchHighlights,
+			colorColumns,
 			wrapConfig.WidthFunc,
 			showTabs,
 			showSpaces,
@@ -66,9 +95,35 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 
 	// Text view is empty, with cursor positioned in the first cell.
 	if pos-viewTextOrigin == 0 && pos == cursorPos {
-		sr.ShowCursor(int(lineNumMargin), 0)
-		drawLineNumIfNecessary(sr, palette, 0, 0, lineNumMargin)
+		showCursorAtVirtualOffset(sr, int(signColumnWidth+lineNumMargin), 0, cursorVirtualOffset, width)
+		drawGutter(sr, palette, 0, 0, lineNums, signColumnWidth, buffer.GitDiffSignForLine, buffer.HasDiagnosticForLine)
+	}
+}
+
+func searchHighlightsContainPosition(searchHighlights []state.SearchMatch, pos uint64) bool {
+	for _, match := range searchHighlights {
+		if match.ContainsPosition(pos) {
+			return true
+		}
+	}
+	return false
+}
+
+func extraCursorsContainPosition(extraCursors []uint64, pos uint64) bool {
+	i := sort.Search(len(extraCursors), func(i int) bool {
+		return extraCursors[i] >= pos
+	})
+	return i < len(extraCursors) && extraCursors[i] == pos
+}
+
+// colorColumnsIntersectRange returns whether any color column is in the range of cells [startCol, endCol).
+func colorColumnsIntersectRange(colorColumns []uint64, startCol uint64, endCol uint64) bool {
+	for _, c := range colorColumns {
+		if c >= startCol && c < endCol {
+			return true
+		}
 	}
+	return false
 }
 
 func viewDimensions(buffer *state.BufferState) (int, int, int, int) {
@@ -84,13 +139,22 @@ func drawLineAndSetCursor(
 	row int,
 	maxLineWidth int,
 	lineNum uint64,
-	lineNumMargin uint64,
+	lineNums lineNumConfig,
+	signColumnWidth uint64,
+	gitDiffSignFunc func(uint64) state.GitDiffSign,
+	hasDiagnosticFunc func(uint64) bool,
 	lineStartPos uint64,
+	lineLengthLimit uint64,
 	wrappedLineRunes []rune,
 	syntaxTokens []parser.Token,
 	cursorPos uint64,
+	cursorVirtualOffset uint64,
+	extraCursors []uint64,
 	selectedRegion selection.Region,
+	yankHighlight selection.Region,
 	searchMatch *state.SearchMatch,
+	searchHighlights []state.SearchMatch,
+	colorColumns []uint64,
 	gcWidthFunc segment.GraphemeClusterWidthFunc,
 	showTabs bool,
 	showSpaces bool,
@@ -101,11 +165,15 @@ func drawLineAndSetCursor(
 	col := 0
 	var gcBreaker segment.GraphemeClusterBreaker
 	var lastGcWasNewline bool
+	lineNumMargin := lineNums.margin
 
 	if startPos == lineStartPos {
-		drawLineNumIfNecessary(sr, palette, row, lineNum, lineNumMargin)
+		drawGutter(sr, palette, row, lineNum, lineNums, signColumnWidth, gitDiffSignFunc, hasDiagnosticFunc)
+	} else {
+		// Soft-wrapped continuation of the line, so draw the separator without a line number.
+		drawLineNumSeparatorIfNecessary(sr, palette, row, lineNums, int(signColumnWidth))
 	}
-	col += int(lineNumMargin)
+	col += int(signColumnWidth + lineNumMargin)
 
 	var i int
 	for i < len(wrappedLineRunes) || len(gcRunes) > 0 {
@@ -126,10 +194,16 @@ func drawLineAndSetCursor(
 		}
 
 		style := tcell.StyleDefault
-		if selectedRegion.ContainsPosition(pos) {
+		if extraCursorsContainPosition(extraCursors, pos) {
+			style = palette.StyleForExtraCursor()
+		} else if selectedRegion.ContainsPosition(pos) {
 			style = palette.StyleForSelection()
+		} else if yankHighlight.ContainsPosition(pos) {
+			style = palette.StyleForYankHighlight()
 		} else if searchMatch.ContainsPosition(pos) {
 			style = palette.StyleForSearchMatch()
+		} else if searchHighlightsContainPosition(searchHighlights, pos) {
+			style = palette.StyleForSearchMatch()
 		} else {
 			for len(syntaxTokens) > 0 {
 				token := syntaxTokens[0]
@@ -141,6 +215,14 @@ func drawLineAndSetCursor(
 				}
 				syntaxTokens = syntaxTokens[1:]
 			}
+
+			if lineLengthLimit > 0 && pos-lineStartPos >= lineLengthLimit && !lastGcWasNewline {
+				style = palette.StyleForLineOverflow()
+			}
+
+			if colorColumnsIntersectRange(colorColumns, totalWidth-gcWidth, totalWidth) {
+				style = palette.StyleForColorColumn(style)
+			}
 		}
 
 		drawGraphemeCluster(sr, col, row, gcRunes, int(gcWidth), style, showTabs, showSpaces)
@@ -151,7 +233,7 @@ func drawLineAndSetCursor(
 		}
 
 		if pos == cursorPos {
-			sr.ShowCursor(col, row)
+			showCursorAtVirtualOffset(sr, col, row, cursorVirtualOffset, int(signColumnWidth+lineNumMargin)+maxLineWidth)
 		}
 
 		i += len(gcRunes)
@@ -160,34 +242,108 @@ func drawLineAndSetCursor(
 		gcRunes = gcRunes[:0]
 	}
 
+	// Fill color columns past the end of the text.
+	textStartCol := int(signColumnWidth + lineNumMargin)
+	for _, c := range colorColumns {
+		if c < uint64(maxLineWidth) && int(c) >= col-textStartCol {
+			sr.SetContent(textStartCol+int(c), row, ' ', nil, palette.StyleForColorColumn(tcell.StyleDefault))
+		}
+	}
+
 	if lastGcWasNewline {
 		// Draw line number for an empty final line.
-		drawLineNumIfNecessary(sr, palette, row+1, lineNum+1, lineNumMargin)
+		drawGutter(sr, palette, row+1, lineNum+1, lineNums, signColumnWidth, gitDiffSignFunc, hasDiagnosticFunc)
 	}
 
 	if pos == cursorPos {
 		if lastGcWasNewline || (pos-startPos) == uint64(maxLineWidth) {
 			// If the line ended on a newline or soft-wrapped line, show the cursor at the start of the next line.
-			sr.ShowCursor(int(lineNumMargin), row+1)
+			sr.ShowCursor(int(signColumnWidth+lineNumMargin), row+1)
 		} else if pos == cursorPos {
 			// Otherwise, show the cursor at the end of the current line.
-			sr.ShowCursor(col, row)
+			showCursorAtVirtualOffset(sr, col, row, cursorVirtualOffset, int(signColumnWidth+lineNumMargin)+maxLineWidth)
 		}
 	}
 }
 
-func drawLineNumIfNecessary(sr *ScreenRegion, palette *Palette, row int, lineNum uint64, lineNumMargin uint64) {
-	if lineNumMargin == 0 {
+// showCursorAtVirtualOffset shows the cursor the given number of cells after col,
+// without moving it past the last column before maxCol.
+func showCursorAtVirtualOffset(sr *ScreenRegion, col int, row int, virtualOffset uint64, maxCol int) {
+	if virtualOffset > 0 {
+		col += int(virtualOffset) // Safe to downcast because we limit the column below.
+		if col >= maxCol {
+			col = maxCol - 1
+		}
+	}
+	sr.ShowCursor(col, row)
+}
+
+func drawGutter(sr *ScreenRegion, palette *Palette, row int, lineNum uint64, lineNums lineNumConfig, signColumnWidth uint64, gitDiffSignFunc func(uint64) state.GitDiffSign, hasDiagnosticFunc func(uint64) bool) {
+	if signColumnWidth > 0 && hasDiagnosticFunc(lineNum) {
+		// A diagnostic sign takes precedence over a git diff sign on the same line.
+		sr.SetContent(0, row, '!', nil, palette.StyleForDiagnostic())
+	} else {
+		drawGitDiffSignIfNecessary(sr, palette, row, lineNum, signColumnWidth, gitDiffSignFunc)
+	}
+	drawLineNumIfNecessary(sr, palette, row, lineNum, lineNums, int(signColumnWidth))
+}
+
+func drawGitDiffSignIfNecessary(sr *ScreenRegion, palette *Palette, row int, lineNum uint64, signColumnWidth uint64, gitDiffSignFunc func(uint64) state.GitDiffSign) {
+	if signColumnWidth == 0 {
+		return
+	}
+
+	sign := gitDiffSignFunc(lineNum)
+	style := palette.StyleForGitDiffSign(sign)
+	switch sign {
+	case state.GitDiffSignAdded:
+		sr.SetContent(0, row, '+', nil, style)
+	case state.GitDiffSignModified:
+		sr.SetContent(0, row, '~', nil, style)
+	case state.GitDiffSignRemoved:
+		sr.SetContent(0, row, '-', nil, style)
+	}
+}
+
+// lineNumConfig controls how line numbers are drawn in the gutter.
+type lineNumConfig struct {
+	margin          uint64 // Zero if line numbers are disabled.
+	separator       rune   // Drawn in the last column of the margin.
+	relative        bool   // If true, show the distance from the cursor's line instead of the line number.
+	cursorLineNum   uint64
+	alignCursorLeft bool // If true, the cursor's line number is left-aligned when line numbers are relative.
+}
+
+func drawLineNumIfNecessary(sr *ScreenRegion, palette *Palette, row int, lineNum uint64, lineNums lineNumConfig, marginStartCol int) {
+	if lineNums.margin == 0 {
 		return
 	}
 
 	style := palette.StyleForLineNum()
 	lineNumStr := strconv.FormatUint(lineNum+1, 10)
+	if lineNums.relative && lineNum > lineNums.cursorLineNum {
+		lineNumStr = strconv.FormatUint(lineNum-lineNums.cursorLineNum, 10)
+	} else if lineNums.relative && lineNum < lineNums.cursorLineNum {
+		lineNumStr = strconv.FormatUint(lineNums.cursorLineNum-lineNum, 10)
+	}
+
+	// Right-aligned in the margin, with one column on the right for the separator.
+	col := marginStartCol + int(lineNums.margin) - 1 - len(lineNumStr)
+	if lineNums.relative && lineNums.alignCursorLeft && lineNum == lineNums.cursorLineNum {
+		col = marginStartCol
+	}
 
-	// Right-aligned in the margin, with one space of padding on the right.
-	col := int(lineNumMargin) - 1 - len(lineNumStr)
 	for _, r := range lineNumStr {
 		sr.SetContent(col, row, r, nil, style)
 		col++
 	}
+
+	drawLineNumSeparatorIfNecessary(sr, palette, row, lineNums, marginStartCol)
+}
+
+func drawLineNumSeparatorIfNecessary(sr *ScreenRegion, palette *Palette, row int, lineNums lineNumConfig, marginStartCol int) {
+	if lineNums.margin == 0 || lineNums.separator == ' ' {
+		return
+	}
+	sr.SetContent(marginStartCol+int(lineNums.margin)-1, row, lineNums.separator, nil, palette.StyleForLineNum())
 }
@@ -1,11 +1,15 @@
 package display
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/state"
 	"github.com/aretext/aretext/syntax"
@@ -17,7 +21,7 @@ func drawBuffer(t *testing.T, screen tcell.Screen, setupState func(*state.Editor
 	setupState(editorState)
 	palette := NewPalette()
 	buffer := editorState.DocumentBuffer()
-	DrawBuffer(screen, palette, buffer)
+	DrawBuffer(screen, palette, buffer, editorState.SearchHighlightEnabled())
 	screen.Sync()
 }
 
@@ -462,6 +466,72 @@ func TestDrawBufferCursor(t *testing.T) {
 	}
 }
 
+func TestDrawBufferCursorVirtualEdit(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		cursorPosition    uint64
+		moveRight         uint64
+		expectedCursorCol int
+		expectedCursorRow int
+	}{
+		{
+			name:              "past end of line",
+			inputString:       "ab\ncd",
+			cursorPosition:    0,
+			moveRight:         3,
+			expectedCursorCol: 3,
+			expectedCursorRow: 0,
+		},
+		{
+			name:              "empty line",
+			inputString:       "ab\n\ncd",
+			cursorPosition:    3,
+			moveRight:         2,
+			expectedCursorCol: 2,
+			expectedCursorRow: 1,
+		},
+		{
+			name:              "middle of tab",
+			inputString:       "\tab",
+			cursorPosition:    0,
+			moveRight:         2,
+			expectedCursorCol: 2,
+			expectedCursorRow: 0,
+		},
+		{
+			name:              "limited to screen width",
+			inputString:       "ab",
+			cursorPosition:    0,
+			moveRight:         10,
+			expectedCursorCol: 4,
+			expectedCursorRow: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withSimScreen(t, func(s tcell.SimulationScreen) {
+				s.SetSize(5, 5)
+				drawBuffer(t, s, func(editorState *state.EditorState) {
+					for _, r := range tc.inputString {
+						state.InsertRune(editorState, r)
+					}
+					state.ToggleVirtualEdit(editorState)
+					state.MoveCursor(editorState, func(state.LocatorParams) uint64 {
+						return tc.cursorPosition
+					})
+					state.MoveCursorRight(editorState, tc.moveRight)
+				})
+				cursorCol, cursorRow, cursorVisible := s.GetCursor()
+				assert.True(t, cursorVisible)
+				assert.Equal(t, tc.expectedCursorCol, cursorCol)
+				assert.Equal(t, tc.expectedCursorRow, cursorRow)
+			})
+		})
+	}
+}
+
 func TestSyntaxHighlighting(t *testing.T) {
 	withSimScreen(t, func(s tcell.SimulationScreen) {
 		s.SetSize(18, 1)
@@ -505,6 +575,136 @@ func TestSyntaxHighlighting(t *testing.T) {
 	})
 }
 
+func TestColorColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.txt")
+	err := os.WriteFile(path, []byte("abc\nd"), 0644)
+	require.NoError(t, err)
+
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "global",
+			Pattern: "**",
+			Config:  map[string]any{"colorColumn": "2,5,+1", "showLineNumbers": true},
+		},
+	}
+
+	withSimScreen(t, func(s tcell.SimulationScreen) {
+		s.SetSize(9, 2)
+		editorState := state.NewEditorState(9, 3, configRuleSet, nil)
+		state.LoadDocument(editorState, path, true, func(state.LocatorParams) uint64 { return 0 })
+		DrawBuffer(s, NewPalette(), editorState.DocumentBuffer(), false)
+		s.Sync()
+
+		lineNumStyle := tcell.StyleDefault.Foreground(tcell.ColorOlive)
+		colorColumnStyle := tcell.StyleDefault.Background(tcell.ColorGray)
+		assertCellStyles(t, s, [][]tcell.Style{
+			{
+				// Gutter with line number.
+				tcell.StyleDefault,
+				lineNumStyle,
+				tcell.StyleDefault,
+
+				// `a`, then `b` in the second column.
+				tcell.StyleDefault,
+				colorColumnStyle,
+
+				// `c`, then empty cells with the fifth column highlighted.
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				colorColumnStyle,
+				tcell.StyleDefault,
+			},
+			{
+				tcell.StyleDefault,
+				lineNumStyle,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				colorColumnStyle,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				colorColumnStyle,
+				tcell.StyleDefault,
+			},
+		})
+	})
+}
+
+func TestGitCommitLineOverflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	err := os.WriteFile(path, []byte("abcde\n\nfghijk\n# comment"), 0644)
+	require.NoError(t, err)
+
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "global",
+			Pattern: "**",
+			Config: map[string]any{
+				"syntaxLanguage":     "gitcommit",
+				"commitSubjectWidth": 3,
+				"commitBodyWidth":    4,
+			},
+		},
+	}
+
+	withSimScreen(t, func(s tcell.SimulationScreen) {
+		s.SetSize(7, 4)
+		editorState := state.NewEditorState(7, 5, configRuleSet, nil)
+		state.LoadDocument(editorState, path, true, func(state.LocatorParams) uint64 { return 0 })
+		DrawBuffer(s, NewPalette(), editorState.DocumentBuffer(), false)
+		s.Sync()
+
+		overflowStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Underline(true)
+		commentStyle := tcell.StyleDefault.Foreground(tcell.ColorNavy)
+		assertCellContents(t, s, [][]rune{
+			{'a', 'b', 'c', 'd', 'e', ' ', ' '},
+			{' ', ' ', ' ', ' ', ' ', ' ', ' '},
+			{'f', 'g', 'h', 'i', 'j', 'k', ' '},
+			{'#', ' ', 'c', 'o', 'm', 'm', 'e'},
+		})
+		assertCellStyles(t, s, [][]tcell.Style{
+			{
+				// Subject past the third character.
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				overflowStyle,
+				overflowStyle,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+			},
+			{
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+			},
+			{
+				// Body past the fourth character.
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				tcell.StyleDefault,
+				overflowStyle,
+				overflowStyle,
+				tcell.StyleDefault,
+			},
+			{
+				// Comments have no limit.
+				commentStyle,
+				commentStyle,
+				commentStyle,
+				commentStyle,
+				commentStyle,
+				commentStyle,
+				commentStyle,
+			},
+		})
+	})
+}
+
 func TestSearchMatch(t *testing.T) {
 	withSimScreen(t, func(s tcell.SimulationScreen) {
 		s.SetSize(12, 1)
@@ -538,6 +738,91 @@ func TestSearchMatch(t *testing.T) {
 	})
 }
 
+func TestSearchHighlight(t *testing.T) {
+	testCases := []struct {
+		name           string
+		hlSearch       bool
+		clear          bool
+		expectedStyles [][]tcell.Style
+	}{
+		{
+			name:     "hlsearch disabled",
+			hlSearch: false,
+			expectedStyles: [][]tcell.Style{
+				{
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+				},
+			},
+		},
+		{
+			name:     "hlsearch enabled",
+			hlSearch: true,
+			expectedStyles: [][]tcell.Style{
+				{
+					tcell.StyleDefault.Reverse(true),
+					tcell.StyleDefault.Reverse(true),
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault.Reverse(true),
+					tcell.StyleDefault.Reverse(true),
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+				},
+			},
+		},
+		{
+			name:     "hlsearch enabled, then cleared",
+			hlSearch: true,
+			clear:    true,
+			expectedStyles: [][]tcell.Style{
+				{
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+					tcell.StyleDefault,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withSimScreen(t, func(s tcell.SimulationScreen) {
+				s.SetSize(8, 1)
+				drawBuffer(t, s, func(editorState *state.EditorState) {
+					for _, r := range "ab  ab" {
+						state.InsertRune(editorState, r)
+					}
+					state.MoveCursor(editorState, func(state.LocatorParams) uint64 { return 0 })
+					if tc.hlSearch {
+						state.ToggleSearchHighlight(editorState)
+					}
+					state.StartSearch(editorState, state.SearchDirectionForward)
+					for _, r := range "ab" {
+						state.AppendRuneToSearchQuery(editorState, r)
+					}
+					state.CompleteSearch(editorState, true)
+					if tc.clear {
+						state.ClearSearchHighlight(editorState)
+					}
+				})
+				assertCellStyles(t, s, tc.expectedStyles)
+			})
+		})
+	}
+}
+
 func TestSelection(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -773,6 +1058,80 @@ func TestShowLineNumbers(t *testing.T) {
 	}
 }
 
+func TestLineNumberSeparatorAndRelativeLineNumbers(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           map[string]any
+		expectedContents [][]rune
+	}{
+		{
+			name:   "separator",
+			config: map[string]any{"lineNumberSeparator": "|"},
+			expectedContents: [][]rune{
+				{' ', '1', '|', 'a', 'b', ' '},
+				{' ', '2', '|', 'c', 'd', 'e'},
+				{' ', ' ', '|', 'f', ' ', ' '},
+				{' ', '3', '|', 'g', ' ', ' '},
+			},
+		},
+		{
+			name:   "relative, cursor line right-aligned",
+			config: map[string]any{"relativeLineNumbers": true, "cursorLineNumberAlign": "right"},
+			expectedContents: [][]rune{
+				{' ', '1', ' ', 'a', 'b', ' '},
+				{' ', '2', ' ', 'c', 'd', 'e'},
+				{' ', ' ', ' ', 'f', ' ', ' '},
+				{' ', '1', ' ', 'g', ' ', ' '},
+			},
+		},
+		{
+			name:   "relative, cursor line left-aligned",
+			config: map[string]any{"relativeLineNumbers": true, "cursorLineNumberAlign": "left"},
+			expectedContents: [][]rune{
+				{' ', '1', ' ', 'a', 'b', ' '},
+				{'2', ' ', ' ', 'c', 'd', 'e'},
+				{' ', ' ', ' ', 'f', ' ', ' '},
+				{' ', '1', ' ', 'g', ' ', ' '},
+			},
+		},
+		{
+			name:   "relative with separator",
+			config: map[string]any{"relativeLineNumbers": true, "lineNumberSeparator": "│"},
+			expectedContents: [][]rune{
+				{' ', '1', '│', 'a', 'b', ' '},
+				{'2', ' ', '│', 'c', 'd', 'e'},
+				{' ', ' ', '│', 'f', ' ', ' '},
+				{' ', '1', '│', 'g', ' ', ' '},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.txt")
+			err := os.WriteFile(path, []byte("ab\ncdef\ng"), 0644)
+			require.NoError(t, err)
+
+			cfg := map[string]any{"showLineNumbers": true}
+			for k, v := range tc.config {
+				cfg[k] = v
+			}
+			configRuleSet := config.RuleSet{{Name: "global", Pattern: "**", Config: cfg}}
+
+			withSimScreen(t, func(s tcell.SimulationScreen) {
+				s.SetSize(6, 4)
+				editorState := state.NewEditorState(6, 5, configRuleSet, nil)
+				state.LoadDocument(editorState, path, true, func(state.LocatorParams) uint64 {
+					return 3 // Start of the second line.
+				})
+				DrawBuffer(s, NewPalette(), editorState.DocumentBuffer(), false)
+				s.Sync()
+				assertCellContents(t, s, tc.expectedContents)
+			})
+		})
+	}
+}
+
 func TestShowTabs(t *testing.T) {
 	testCases := []struct {
 		name             string
@@ -3,14 +3,19 @@ package display
 import (
 	"github.com/gdamore/tcell/v2"
 
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/state"
 )
 
 // DrawEditor draws the editor in the screen.
 func DrawEditor(screen tcell.Screen, palette *Palette, editorState *state.EditorState, inputBufferString string) {
 	screen.Fill(' ', tcell.StyleDefault)
-	DrawBuffer(screen, palette, editorState.DocumentBuffer())
-	DrawMenu(screen, palette, editorState.Menu())
+	screen.SetCursorStyle(cursorStyleForShape(editorState.CursorShape()))
+	DrawBuffer(screen, palette, editorState.DocumentBuffer(), editorState.SearchHighlightEnabled())
+	DrawMenu(screen, palette, editorState.Menu(), editorState.HyperlinksEnabled())
+	if !editorState.ShowCmd() {
+		inputBufferString = ""
+	}
 	DrawStatusBar(
 		screen,
 		palette,
@@ -18,7 +23,10 @@ func DrawEditor(screen tcell.Screen, palette *Palette, editorState *state.Editor
 		editorState.InputMode(),
 		inputBufferString,
 		editorState.IsRecordingUserMacro(),
+		editorState.DocumentBuffer().LineLengthHint(),
+		editorState.DocumentBuffer().DiagnosticMessage(),
 		editorState.FileWatcher().Path(),
+		editorState.HyperlinksEnabled(),
 	)
 	searchQuery, searchDirection := editorState.DocumentBuffer().SearchQueryAndDirection()
 	DrawSearchQuery(
@@ -29,3 +37,24 @@ func DrawEditor(screen tcell.Screen, palette *Palette, editorState *state.Editor
 		searchDirection,
 	)
 }
+
+// cursorStyleForShape returns the terminal cursor style for a cursor shape from the configuration.
+// Terminals that do not support changing the cursor style ignore it.
+func cursorStyleForShape(shape string) tcell.CursorStyle {
+	switch shape {
+	case config.CursorShapeBlock:
+		return tcell.CursorStyleSteadyBlock
+	case config.CursorShapeBlinkingBlock:
+		return tcell.CursorStyleBlinkingBlock
+	case config.CursorShapeUnderline:
+		return tcell.CursorStyleSteadyUnderline
+	case config.CursorShapeBlinkingUnderline:
+		return tcell.CursorStyleBlinkingUnderline
+	case config.CursorShapeBar:
+		return tcell.CursorStyleSteadyBar
+	case config.CursorShapeBlinkingBar:
+		return tcell.CursorStyleBlinkingBar
+	default:
+		return tcell.CursorStyleDefault
+	}
+}
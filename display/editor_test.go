@@ -0,0 +1,32 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aretext/aretext/config"
+)
+
+func TestCursorStyleForShape(t *testing.T) {
+	testCases := []struct {
+		shape    string
+		expected tcell.CursorStyle
+	}{
+		{shape: config.CursorShapeDefault, expected: tcell.CursorStyleDefault},
+		{shape: config.CursorShapeBlock, expected: tcell.CursorStyleSteadyBlock},
+		{shape: config.CursorShapeBlinkingBlock, expected: tcell.CursorStyleBlinkingBlock},
+		{shape: config.CursorShapeUnderline, expected: tcell.CursorStyleSteadyUnderline},
+		{shape: config.CursorShapeBlinkingUnderline, expected: tcell.CursorStyleBlinkingUnderline},
+		{shape: config.CursorShapeBar, expected: tcell.CursorStyleSteadyBar},
+		{shape: config.CursorShapeBlinkingBar, expected: tcell.CursorStyleBlinkingBar},
+		{shape: "", expected: tcell.CursorStyleDefault},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.shape, func(t *testing.T) {
+			assert.Equal(t, tc.expected, cursorStyleForShape(tc.shape))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package display
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// hyperlinkRegexp matches URLs that a terminal can open when clicked.
+var hyperlinkRegexp = regexp.MustCompile(`(?:https?|file)://[^\s"'<>]+`)
+
+// drawStringWithHyperlinks draws a string like drawStringNoWrap, but any URLs in the string are drawn as
+// OSC 8 hyperlinks. Terminals that do not support hyperlinks draw the URL as plain text.
+func drawStringWithHyperlinks(sr *ScreenRegion, s string, col int, row int, style tcell.Style) int {
+	var prevEnd int
+	for _, loc := range hyperlinkRegexp.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+
+		// Exclude punctuation that usually ends the surrounding sentence rather than the URL.
+		end = start + len(strings.TrimRight(s[start:end], ".,;:!?)]}"))
+
+		col = drawStringNoWrap(sr, s[prevEnd:start], col, row, style)
+		col = drawStringNoWrap(sr, s[start:end], col, row, style.Url(s[start:end]))
+		prevEnd = end
+	}
+	return drawStringNoWrap(sr, s[prevEnd:], col, row, style)
+}
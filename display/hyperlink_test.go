@@ -0,0 +1,127 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/state"
+)
+
+func TestDrawStringWithHyperlinks(t *testing.T) {
+	testCases := []struct {
+		name          string
+		s             string
+		expectedLinks []string // URL for each cell in the first row, or empty if the cell is not a link.
+	}{
+		{
+			name:          "no links",
+			s:             "abc",
+			expectedLinks: []string{"", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", ""},
+		},
+		{
+			name: "https url",
+			s:    "go to https://a.b/",
+			expectedLinks: []string{
+				"", "", "", "", "", "",
+				"https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/",
+				"https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/", "https://a.b/",
+				"", "",
+			},
+		},
+		{
+			name: "trailing punctuation is not part of the url",
+			s:    "(file:///x).",
+			expectedLinks: []string{
+				"",
+				"file:///x", "file:///x", "file:///x", "file:///x", "file:///x", "file:///x", "file:///x", "file:///x", "file:///x",
+				"", "", "", "", "", "", "", "", "", "",
+			},
+		},
+		{
+			name: "multiple urls",
+			s:    "http://a http://b",
+			expectedLinks: []string{
+				"http://a", "http://a", "http://a", "http://a", "http://a", "http://a", "http://a", "http://a",
+				"",
+				"http://b", "http://b", "http://b", "http://b", "http://b", "http://b", "http://b", "http://b",
+				"", "", "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withSimScreen(t, func(s tcell.SimulationScreen) {
+				s.SetSize(20, 1)
+				style := tcell.StyleDefault.Bold(true)
+				sr := NewScreenRegion(s, 0, 0, 20, 1)
+				drawStringWithHyperlinks(sr, tc.s, 0, 0, style)
+				s.Sync()
+
+				expectedText := tc.s + strings.Repeat(" ", 20-len(tc.s))
+				assertCellContents(t, s, [][]rune{[]rune(expectedText)})
+
+				cells, _, _ := s.GetContents()
+				require.Equal(t, len(tc.expectedLinks), len(cells))
+				for i, link := range tc.expectedLinks {
+					if i < len(tc.s) {
+						assert.Equal(t, style.Url(link), cells[i].Style, "Wrong style at column %d", i)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestDrawStatusBarHyperlinks(t *testing.T) {
+	testCases := []struct {
+		name         string
+		hyperlinks   bool
+		expectedLink string
+	}{
+		{name: "hyperlinks disabled", hyperlinks: false, expectedLink: ""},
+		{name: "hyperlinks enabled", hyperlinks: true, expectedLink: "http://x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withSimScreen(t, func(s tcell.SimulationScreen) {
+				s.SetSize(12, 1)
+				palette := NewPalette()
+				statusMsg := state.StatusMsg{Style: state.StatusMsgStyleSuccess, Text: "see http://x"}
+				DrawStatusBar(s, palette, statusMsg, state.InputModeNormal, "", false, "", "", "", tc.hyperlinks)
+				s.Sync()
+
+				style := palette.StyleForStatusMsg(state.StatusMsgStyleSuccess)
+				cells, _, _ := s.GetContents()
+				assert.Equal(t, style, cells[0].Style)
+				assert.Equal(t, style.Url(tc.expectedLink), cells[4].Style)
+				assert.Equal(t, style.Url(tc.expectedLink), cells[11].Style)
+			})
+		})
+	}
+}
+
+func TestDrawMenuItemHyperlink(t *testing.T) {
+	withSimScreen(t, func(s tcell.SimulationScreen) {
+		s.SetSize(10, 4)
+		palette := NewPalette()
+		editorState := state.NewEditorState(100, 100, nil, nil)
+		items := []menu.Item{
+			{Name: "linked", Link: "file:///linked"},
+			{Name: "unlinked"},
+		}
+		state.ShowMenu(editorState, state.MenuStyleFileLocation, items)
+		DrawMenu(s, palette, editorState.Menu(), true)
+		s.Sync()
+
+		cells, width, _ := s.GetContents()
+		assert.Equal(t, palette.StyleForMenuItem(true).Url("file:///linked"), cells[width+4].Style)
+		assert.Equal(t, palette.StyleForMenuItem(false), cells[2*width+4].Style)
+	})
+}
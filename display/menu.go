@@ -8,7 +8,8 @@ import (
 )
 
 // DrawMenu draws the menu at the top of the screen.
-func DrawMenu(screen tcell.Screen, palette *Palette, menu *state.MenuState) {
+// If hyperlinks is true, items with a link are drawn as terminal hyperlinks.
+func DrawMenu(screen tcell.Screen, palette *Palette, menu *state.MenuState, hyperlinks bool) {
 	if !menu.Visible() {
 		return
 	}
@@ -33,7 +34,7 @@ func DrawMenu(screen tcell.Screen, palette *Palette, menu *state.MenuState) {
 	for i := 0; i < len(items) && row < height; i++ {
 		menuItemRegion := NewScreenRegion(screen, 0, row, screenWidth, 1)
 		isSelected := i == selectedIdx
-		drawMenuItem(menuItemRegion, palette, items[i], isSelected)
+		drawMenuItem(menuItemRegion, palette, items[i], isSelected, hyperlinks)
 		row++
 	}
 
@@ -95,6 +96,12 @@ func menuIconForStyle(style state.MenuStyle) string {
 		return "+ "
 	case state.MenuStyleChildDir, state.MenuStyleParentDir, state.MenuStyleWorkingDir:
 		return "§ "
+	case state.MenuStyleStatusMsgHistory:
+		return "! "
+	case state.MenuStyleUndoList:
+		return "↶ "
+	case state.MenuStyleOutline:
+		return "# "
 	default:
 		panic("Unrecognized menu style")
 	}
@@ -112,12 +119,18 @@ func menuPromptForStyle(style state.MenuStyle) string {
 		return ""
 	case state.MenuStyleChildDir, state.MenuStyleParentDir, state.MenuStyleWorkingDir:
 		return "working directory"
+	case state.MenuStyleStatusMsgHistory:
+		return "messages"
+	case state.MenuStyleUndoList:
+		return "undo states"
+	case state.MenuStyleOutline:
+		return "headings"
 	default:
 		panic("Unrecognized menu style")
 	}
 }
 
-func drawMenuItem(sr *ScreenRegion, palette *Palette, item menu.Item, selected bool) {
+func drawMenuItem(sr *ScreenRegion, palette *Palette, item menu.Item, selected bool, hyperlinks bool) {
 	sr.Clear()
 
 	col := 2
@@ -127,5 +140,8 @@ func drawMenuItem(sr *ScreenRegion, palette *Palette, item menu.Item, selected b
 	col += 2
 
 	style := palette.StyleForMenuItem(selected)
+	if hyperlinks && item.Link != "" {
+		style = style.Url(item.Link)
+	}
 	drawStringNoWrap(sr, item.Name, col, 0, style)
 }
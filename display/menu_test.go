@@ -123,7 +123,7 @@ func TestDrawMenu(t *testing.T) {
 				s.SetSize(10, 6)
 				palette := NewPalette()
 				menu := tc.buildMenu()
-				DrawMenu(s, palette, menu)
+				DrawMenu(s, palette, menu, false)
 				s.Sync()
 				assertCellContents(t, s, tc.expectedContents)
 			})
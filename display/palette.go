@@ -13,8 +13,12 @@ import (
 // Palette controls the style of displayed text.
 type Palette struct {
 	lineNumStyle              tcell.Style
+	colorColumnStyle          tcell.Style
+	lineOverflowStyle         tcell.Style
 	selectionStyle            tcell.Style
 	searchMatchStyle          tcell.Style
+	yankHighlightStyle        tcell.Style
+	extraCursorStyle          tcell.Style
 	statusMsgSuccessStyle     tcell.Style
 	statusMsgErrorStyle       tcell.Style
 	statusInputModeStyle      tcell.Style
@@ -30,6 +34,10 @@ type Palette struct {
 	menuItemUnselectedStyle   tcell.Style
 	searchPrefixStyle         tcell.Style
 	searchQueryStyle          tcell.Style
+	gitDiffAddedStyle         tcell.Style
+	gitDiffModifiedStyle      tcell.Style
+	gitDiffRemovedStyle       tcell.Style
+	diagnosticStyle           tcell.Style
 	tokenRoleStyle            map[parser.TokenRole]tcell.Style
 }
 
@@ -37,8 +45,12 @@ func NewPalette() *Palette {
 	s := tcell.StyleDefault
 	return &Palette{
 		lineNumStyle:              s.Foreground(tcell.ColorOlive),
+		colorColumnStyle:          s.Background(tcell.ColorGray),
+		lineOverflowStyle:         s.Foreground(tcell.ColorRed).Underline(true),
 		selectionStyle:            s.Reverse(true).Dim(true),
 		searchMatchStyle:          s.Reverse(true),
+		yankHighlightStyle:        s.Background(tcell.ColorOlive).Foreground(tcell.ColorBlack),
+		extraCursorStyle:          s.Reverse(true),
 		statusMsgSuccessStyle:     s.Foreground(tcell.ColorGreen).Bold(true),
 		statusMsgErrorStyle:       s.Background(tcell.ColorMaroon).Foreground(tcell.ColorWhite).Bold(true),
 		statusInputModeStyle:      s.Bold(true),
@@ -54,6 +66,10 @@ func NewPalette() *Palette {
 		menuItemUnselectedStyle:   s,
 		searchPrefixStyle:         s,
 		searchQueryStyle:          s,
+		gitDiffAddedStyle:         s.Foreground(tcell.ColorGreen),
+		gitDiffModifiedStyle:      s.Foreground(tcell.ColorOlive),
+		gitDiffRemovedStyle:       s.Foreground(tcell.ColorRed),
+		diagnosticStyle:           s.Foreground(tcell.ColorRed).Bold(true),
 		tokenRoleStyle: map[parser.TokenRole]tcell.Style{
 			parser.TokenRoleOperator: s.Foreground(tcell.ColorPurple),
 			parser.TokenRoleKeyword:  s.Foreground(tcell.ColorOlive),
@@ -87,6 +103,16 @@ func NewPaletteFromConfigStyles(styles map[string]config.StyleConfig) *Palette {
 		switch k {
 		case config.StyleLineNum:
 			p.lineNumStyle = s
+		case config.StyleColorColumn:
+			p.colorColumnStyle = s
+		case config.StyleLineOverflow:
+			p.lineOverflowStyle = s
+		case config.StyleYankHighlight:
+			p.yankHighlightStyle = s
+		case config.StyleStatusMsgSuccess:
+			p.statusMsgSuccessStyle = s
+		case config.StyleStatusMsgError:
+			p.statusMsgErrorStyle = s
 		case config.StyleTokenOperator:
 			p.tokenRoleStyle[parser.TokenRoleOperator] = s
 		case config.StyleTokenKeyword:
@@ -140,6 +166,36 @@ func (p *Palette) StyleForLineNum() tcell.Style {
 	return p.lineNumStyle
 }
 
+func (p *Palette) StyleForGitDiffSign(sign state.GitDiffSign) tcell.Style {
+	switch sign {
+	case state.GitDiffSignAdded:
+		return p.gitDiffAddedStyle
+	case state.GitDiffSignModified:
+		return p.gitDiffModifiedStyle
+	case state.GitDiffSignRemoved:
+		return p.gitDiffRemovedStyle
+	default:
+		return tcell.StyleDefault
+	}
+}
+
+// StyleForDiagnostic is the style of the sign for a line with a linter diagnostic
+// and the diagnostic message in the status bar.
+func (p *Palette) StyleForDiagnostic() tcell.Style {
+	return p.diagnosticStyle
+}
+
+// StyleForColorColumn applies the color column background to a style,
+// preserving its foreground color and attributes.
+func (p *Palette) StyleForColorColumn(style tcell.Style) tcell.Style {
+	_, bg, _ := p.colorColumnStyle.Decompose()
+	return style.Background(bg)
+}
+
+func (p *Palette) StyleForLineOverflow() tcell.Style {
+	return p.lineOverflowStyle
+}
+
 func (p *Palette) StyleForSelection() tcell.Style {
 	return p.selectionStyle
 }
@@ -148,6 +204,14 @@ func (p *Palette) StyleForSearchMatch() tcell.Style {
 	return p.searchMatchStyle
 }
 
+func (p *Palette) StyleForYankHighlight() tcell.Style {
+	return p.yankHighlightStyle
+}
+
+func (p *Palette) StyleForExtraCursor() tcell.Style {
+	return p.extraCursorStyle
+}
+
 func (p *Palette) StyleForStatusInputMode() tcell.Style {
 	return p.statusInputModeStyle
 }
@@ -28,6 +28,23 @@ func TestPaletteFromConfigStyles(t *testing.T) {
 		config.StyleTokenCustom4: {
 			BackgroundColor: "yellow",
 		},
+		config.StyleStatusMsgSuccess: {
+			Color: "blue",
+		},
+		config.StyleColorColumn: {
+			BackgroundColor: "navy",
+		},
+		config.StyleLineOverflow: {
+			Color: "fuchsia",
+		},
+		config.StyleYankHighlight: {
+			Color:           "black",
+			BackgroundColor: "aqua",
+		},
+		config.StyleStatusMsgError: {
+			Color:           "white",
+			BackgroundColor: "red",
+		},
 	}
 
 	palette := NewPaletteFromConfigStyles(configStyles)
@@ -35,10 +52,14 @@ func TestPaletteFromConfigStyles(t *testing.T) {
 	s := tcell.StyleDefault
 	expected := &Palette{
 		lineNumStyle:              s.Foreground(tcell.ColorOlive),
+		colorColumnStyle:          s.Background(tcell.ColorNavy),
+		lineOverflowStyle:         s.Foreground(tcell.ColorFuchsia),
 		selectionStyle:            s.Reverse(true).Dim(true),
 		searchMatchStyle:          s.Reverse(true),
-		statusMsgSuccessStyle:     s.Foreground(tcell.ColorGreen).Bold(true),
-		statusMsgErrorStyle:       s.Background(tcell.ColorMaroon).Foreground(tcell.ColorWhite).Bold(true),
+		yankHighlightStyle:        s.Foreground(tcell.ColorBlack).Background(tcell.ColorAqua),
+		extraCursorStyle:          s.Reverse(true),
+		statusMsgSuccessStyle:     s.Foreground(tcell.ColorBlue),
+		statusMsgErrorStyle:       s.Foreground(tcell.ColorWhite).Background(tcell.ColorRed),
 		statusInputModeStyle:      s.Bold(true),
 		statusInputBufferStyle:    s.Bold(true),
 		statusRecordingMacroStyle: s.Bold(true),
@@ -52,6 +73,10 @@ func TestPaletteFromConfigStyles(t *testing.T) {
 		menuItemUnselectedStyle:   s,
 		searchPrefixStyle:         s,
 		searchQueryStyle:          s,
+		gitDiffAddedStyle:         s.Foreground(tcell.ColorGreen),
+		gitDiffModifiedStyle:      s.Foreground(tcell.ColorOlive),
+		gitDiffRemovedStyle:       s.Foreground(tcell.ColorRed),
+		diagnosticStyle:           s.Foreground(tcell.ColorRed).Bold(true),
 		tokenRoleStyle: map[parser.TokenRole]tcell.Style{
 			parser.TokenRoleOperator: s.Foreground(tcell.ColorPurple),
 			parser.TokenRoleKeyword:  s.Foreground(tcell.ColorOlive),
@@ -3,11 +3,18 @@ package display
 import (
 	"github.com/gdamore/tcell/v2"
 
+	"github.com/aretext/aretext/cellwidth"
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/file"
 	"github.com/aretext/aretext/state"
 )
 
+// maxPendingInputWidth is the maximum number of cells used to display keys typed for an incomplete command.
+// If the input is longer than this, only the most recent keys are displayed.
+const maxPendingInputWidth = 10
+
 // DrawStatusBar draws a status bar on the last line of the screen.
+// If hyperlinks is true, URLs in the status message are drawn as terminal hyperlinks.
 func DrawStatusBar(
 	screen tcell.Screen,
 	palette *Palette,
@@ -15,7 +22,10 @@ func DrawStatusBar(
 	inputMode state.InputMode,
 	inputBufferString string,
 	isRecordingUserMacro bool,
+	lineLengthHint string,
+	diagnosticMsg string,
 	filePath string,
+	hyperlinks bool,
 ) {
 	screenWidth, screenHeight := screen.Size()
 	if screenHeight == 0 {
@@ -29,24 +39,53 @@ func DrawStatusBar(
 		palette,
 		statusMsg,
 		inputMode,
-		inputBufferString,
 		isRecordingUserMacro,
+		lineLengthHint,
+		diagnosticMsg,
 		filePath)
-	drawStringNoWrap(sr, text, 0, 0, style)
+	if hyperlinks && len(statusMsg.Text) > 0 {
+		drawStringWithHyperlinks(sr, text, 0, 0, style)
+	} else {
+		drawStringNoWrap(sr, text, 0, 0, style)
+	}
+	drawPendingInput(sr, palette, inputBufferString)
+}
+
+// drawPendingInput draws the keys typed so far for an incomplete command, aligned to the right of the status bar.
+func drawPendingInput(sr *ScreenRegion, palette *Palette, inputBufferString string) {
+	runes := []rune(inputBufferString)
+	startIdx, width := len(runes), 0
+	for startIdx > 0 {
+		runeWidth := int(cellwidth.GraphemeClusterWidth(runes[startIdx-1:startIdx], 0, config.DefaultTabSize))
+		if width+runeWidth > maxPendingInputWidth {
+			break
+		}
+		startIdx--
+		width += runeWidth
+	}
+
+	screenWidth, _ := sr.Size()
+	if width == 0 || width > screenWidth {
+		return
+	}
+
+	col := screenWidth - width
+	if col > 0 {
+		// Separate the pending input from any other text in the status bar.
+		sr.SetContent(col-1, 0, ' ', nil, tcell.StyleDefault)
+	}
+	drawStringNoWrap(sr, string(runes[startIdx:]), col, 0, palette.StyleForStatusInputBuffer())
 }
 
 func statusBarContent(
 	palette *Palette,
 	statusMsg state.StatusMsg,
 	inputMode state.InputMode,
-	inputBufferString string,
 	isRecordingUserMacro bool,
+	lineLengthHint string,
+	diagnosticMsg string,
 	filePath string,
 ) (string, tcell.Style) {
-	if len(inputBufferString) > 0 {
-		return inputBufferString, palette.StyleForStatusInputBuffer()
-	}
-
 	if len(statusMsg.Text) > 0 {
 		return statusMsg.Text, palette.StyleForStatusMsg(statusMsg.Style)
 	}
@@ -63,6 +102,12 @@ func statusBarContent(
 	case state.InputModeTask:
 		return "Running... press ESC to abort", palette.StyleForStatusInputMode()
 	default:
+		if lineLengthHint != "" {
+			return lineLengthHint, palette.StyleForLineOverflow()
+		}
+		if diagnosticMsg != "" {
+			return diagnosticMsg, palette.StyleForDiagnostic()
+		}
 		relPath := file.RelativePathCwd(filePath)
 		return relPath, palette.StyleForStatusFilePath()
 	}
@@ -15,6 +15,8 @@ func TestDrawStatusBar(t *testing.T) {
 		inputMode            state.InputMode
 		inputBufferString    string
 		isRecordingUserMacro bool
+		lineLengthHint       string
+		diagnosticMsg        string
 		filePath             string
 		expectedContents     [][]rune
 	}{
@@ -27,6 +29,26 @@ func TestDrawStatusBar(t *testing.T) {
 				{'.', '/', 'f', 'o', 'o', '/', 'b', 'a', 'r', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
 			},
 		},
+		{
+			name:           "normal mode shows line length hint",
+			inputMode:      state.InputModeNormal,
+			lineLengthHint: "Subject too long",
+			filePath:       "./foo/bar",
+			expectedContents: [][]rune{
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{'S', 'u', 'b', 'j', 'e', 'c', 't', ' ', 't', 'o', 'o', ' ', 'l', 'o', 'n', 'g'},
+			},
+		},
+		{
+			name:          "normal mode shows diagnostic",
+			inputMode:     state.InputModeNormal,
+			diagnosticMsg: "unused variable",
+			filePath:      "./foo/bar",
+			expectedContents: [][]rune{
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{'u', 'n', 'u', 's', 'e', 'd', ' ', 'v', 'a', 'r', 'i', 'a', 'b', 'l', 'e', ' '},
+			},
+		},
 		{
 			name:      "insert mode shows INSERT",
 			inputMode: state.InputModeInsert,
@@ -81,7 +103,37 @@ func TestDrawStatusBar(t *testing.T) {
 			inputBufferString: `"aya`,
 			expectedContents: [][]rune{
 				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
-				{'"', 'a', 'y', 'a', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', '"', 'a', 'y', 'a'},
+			},
+		},
+		{
+			name:              "input buffer with file path",
+			inputMode:         state.InputModeNormal,
+			filePath:          "./foo/bar",
+			inputBufferString: "2d",
+			expectedContents: [][]rune{
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{'.', '/', 'f', 'o', 'o', '/', 'b', 'a', 'r', ' ', ' ', ' ', ' ', ' ', '2', 'd'},
+			},
+		},
+		{
+			name: "input buffer overlapping status message",
+			statusMsg: state.StatusMsg{
+				Text:  "long status message",
+				Style: state.StatusMsgStyleSuccess,
+			},
+			inputBufferString: "3y",
+			expectedContents: [][]rune{
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{'l', 'o', 'n', 'g', ' ', 's', 't', 'a', 't', 'u', 's', ' ', 'm', ' ', '3', 'y'},
+			},
+		},
+		{
+			name:              "input buffer longer than max width shows most recent keys",
+			inputBufferString: "123456789012d",
+			expectedContents: [][]rune{
+				{' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' ', ' '},
+				{' ', ' ', ' ', ' ', ' ', ' ', '4', '5', '6', '7', '8', '9', '0', '1', '2', 'd'},
 			},
 		},
 		{
@@ -107,7 +159,10 @@ func TestDrawStatusBar(t *testing.T) {
 					tc.inputMode,
 					tc.inputBufferString,
 					tc.isRecordingUserMacro,
+					tc.lineLengthHint,
+					tc.diagnosticMsg,
 					tc.filePath,
+					false,
 				)
 				s.Sync()
 				assertCellContents(t, s, tc.expectedContents)
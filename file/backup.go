@@ -0,0 +1,123 @@
+package file
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/renameio/v2"
+	"github.com/pkg/errors"
+)
+
+// backupTimestampFormat formats timestamps in backup file names.
+// The timestamps sort lexicographically in chronological order.
+const backupTimestampFormat = "20060102-150405.000"
+
+// backupTimestampGlob matches timestamps formatted with backupTimestampFormat.
+const backupTimestampGlob = "????????-??????.???"
+
+// BackupOptions control where backup files are written.
+type BackupOptions struct {
+	// Dir is the directory for backup files.
+	// If empty, the backup is written next to the original file with "~" appended to its name.
+	// A leading "~/" is expanded to the user's home directory.
+	Dir string
+
+	// MaxCount is the maximum number of backups to keep for each file in Dir.
+	// If zero, every backup is kept.
+	MaxCount int
+}
+
+// Backup copies the file at path before it is overwritten.
+// If Dir is set, the backup name includes the full path of the original file (with separators
+// replaced by "%") and a timestamp, and older backups beyond MaxCount are deleted.
+// The backup is written atomically, so an existing backup is never left partially written.
+// If the file does not exist, there is nothing to back up, so this returns an empty path.
+func Backup(path string, opts BackupOptions, now time.Time) (string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "os.Open")
+	}
+	defer f.Close()
+
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return "", errors.Wrap(err, "os.Stat")
+	}
+
+	backupPath := path + "~"
+	var backupPrefix string
+	if opts.Dir != "" {
+		dir, err := ExpandHomeDir(opts.Dir)
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", errors.Wrap(err, "os.MkdirAll")
+		}
+
+		backupPrefix = filepath.Join(dir, strings.ReplaceAll(path, string(filepath.Separator), "%")+".")
+		backupPath = backupPrefix + now.Format(backupTimestampFormat) + "~"
+	}
+
+	pf, err := renameio.NewPendingFile(backupPath, renameio.WithPermissions(fileInfo.Mode().Perm()))
+	if err != nil {
+		return "", errors.Wrap(err, "renameio.TempFile")
+	}
+	defer pf.Cleanup()
+
+	if _, err := io.Copy(pf, f); err != nil {
+		return "", errors.Wrap(err, "io.Copy")
+	}
+
+	if err := pf.CloseAtomicallyReplace(); err != nil {
+		return "", errors.Wrap(err, "renameio.CloseAtomicallyReplace")
+	}
+
+	if backupPrefix != "" && opts.MaxCount > 0 {
+		if err := removeOldBackups(backupPrefix, opts.MaxCount); err != nil {
+			return "", err
+		}
+	}
+
+	return backupPath, nil
+}
+
+// removeOldBackups deletes the oldest backups with the given prefix, keeping at most maxCount.
+func removeOldBackups(backupPrefix string, maxCount int) error {
+	backupPaths, err := filepath.Glob(escapeGlob(backupPrefix) + backupTimestampGlob + "~")
+	if err != nil {
+		return errors.Wrap(err, "filepath.Glob")
+	}
+
+	if len(backupPaths) <= maxCount {
+		return nil
+	}
+
+	sort.Strings(backupPaths)
+	for _, p := range backupPaths[:len(backupPaths)-maxCount] {
+		if err := os.Remove(p); err != nil {
+			return errors.Wrap(err, "os.Remove")
+		}
+	}
+	return nil
+}
+
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', '\\':
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupNextToFile(t *testing.T) {
+	path := createTestFile(t, "old contents")
+	err := os.Chmod(path, 0600)
+	require.NoError(t, err)
+
+	backupPath, err := Backup(path, BackupOptions{}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, path+"~", backupPath)
+
+	fileBytes, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "old contents", string(fileBytes))
+
+	fileInfo, err := os.Stat(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+}
+
+func TestBackupFileDoesNotExist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	backupPath, err := Backup(path, BackupOptions{}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "", backupPath)
+
+	_, err = os.Stat(path + "~")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBackupToDir(t *testing.T) {
+	path := createTestFile(t, "old contents")
+	backupDir := filepath.Join(t.TempDir(), "backups")
+	now := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	backupPath, err := Backup(path, BackupOptions{Dir: backupDir}, now)
+	require.NoError(t, err)
+	assert.Equal(t, backupDir, filepath.Dir(backupPath))
+	assert.True(t, strings.HasSuffix(backupPath, ".20220304-050607.000~"))
+	assert.NotContains(t, filepath.Base(backupPath), string(filepath.Separator))
+
+	fileBytes, err := os.ReadFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "old contents", string(fileBytes))
+}
+
+func TestBackupToDirRemovesOldBackups(t *testing.T) {
+	path := createTestFile(t, "old contents")
+	backupDir := t.TempDir()
+	opts := BackupOptions{Dir: backupDir, MaxCount: 2}
+	now := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	var backupPaths []string
+	for i := 0; i < 4; i++ {
+		backupPath, err := Backup(path, opts, now.Add(time.Duration(i)*time.Second))
+		require.NoError(t, err)
+		backupPaths = append(backupPaths, backupPath)
+	}
+
+	// Backups of a different file in the same directory should not be removed.
+	otherPath := createTestFile(t, "other contents")
+	otherBackupPath, err := Backup(otherPath, opts, now)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(backupDir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{
+		filepath.Base(backupPaths[2]),
+		filepath.Base(backupPaths[3]),
+		filepath.Base(otherBackupPath),
+	}, names)
+}
@@ -0,0 +1,38 @@
+package file
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// LoadGitHeadVersion returns the contents of a file as of the git HEAD commit.
+// This returns an error if git is not installed, the file is not in a git repository,
+// or the file is not tracked in the HEAD commit.
+func LoadGitHeadVersion(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrap(err, "filepath.Abs")
+	}
+
+	// The "./" prefix tells git to resolve the path relative to the working directory,
+	// which we set to the directory containing the file.
+	dir, name := filepath.Split(path)
+	cmd := exec.Command("git", "show", "HEAD:./"+name)
+	cmd.Dir = dir
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrap(err, "git show")
+	}
+
+	if !utf8.Valid(stdout.Bytes()) {
+		return "", errors.New("File in git HEAD is not valid UTF-8")
+	}
+
+	return stdout.String(), nil
+}
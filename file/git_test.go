@@ -0,0 +1,54 @@
+package file
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGitHeadVersion(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir := t.TempDir()
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test",
+			"GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test",
+			"GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	path := filepath.Join(tmpDir, "test.txt")
+	err := os.WriteFile(path, []byte("committed\n"), 0644)
+	require.NoError(t, err)
+	runGit("init")
+	runGit("add", "test.txt")
+	runGit("commit", "-m", "test")
+
+	err = os.WriteFile(path, []byte("modified\n"), 0644)
+	require.NoError(t, err)
+
+	content, err := LoadGitHeadVersion(path)
+	require.NoError(t, err)
+	assert.Equal(t, "committed\n", content)
+
+	_, err = LoadGitHeadVersion(filepath.Join(tmpDir, "untracked.txt"))
+	assert.Error(t, err)
+}
+
+func TestLoadGitHeadVersionOutsideRepo(t *testing.T) {
+	path := createTestFile(t, "abcd")
+	_, err := LoadGitHeadVersion(path)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -29,3 +30,16 @@ func RelativePath(p string, baseDir string) string {
 	}
 	return relPath
 }
+
+// ExpandHomeDir replaces a leading "~" in the path with the user's home directory.
+func ExpandHomeDir(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrap(err, "os.UserHomeDir")
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(p, "~")), nil
+}
@@ -2,8 +2,11 @@ package file
 
 import (
 	"io"
+	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/google/renameio/v2"
@@ -15,17 +18,49 @@ import (
 // Save writes the text to disk and starts a new watcher to detect subsequent changes.
 // This adds the POSIX end-of-file indicator (line feed at the end of the file).
 func Save(path string, tree *text.Tree, watcherPollInterval time.Duration) (*Watcher, error) {
-	// Use renameio to write the file to a temporary directory, then rename it to the target file.
+	checksum, err := writeTree(path, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start a new watcher for subsequent changes to the file.
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "os.Stat")
+	}
+	watcher := NewWatcher(watcherPollInterval, path, fileInfo.ModTime(), fileInfo.Size(), checksum)
+
+	return watcher, nil
+}
+
+// SaveCopy writes the text to disk without watching the file for changes.
+// This is used to write the document to a path other than the one being edited.
+func SaveCopy(path string, tree *text.Tree) error {
+	_, err := writeTree(path, tree)
+	return err
+}
+
+// writeTree atomically writes the text to disk and returns the checksum of the written contents.
+func writeTree(path string, tree *text.Tree) (string, error) {
+	// Use renameio to write a temporary file in the same directory, then rename it to the target file.
 	// This should reduce the risk of data corruption if the editor crashes mid-write,
 	// but is probably not 100% reliable (see http://danluu.com/deconstruct-files/).
 	// There is a good discussion of the Go libraries solving this problem in
 	// this GitHub issue comment: https://github.com/golang/go/issues/22397#issuecomment-380831736
-	pf, err := renameio.NewPendingFile(path, renameio.WithPermissions(0644), renameio.WithExistingPermissions())
+	pf, err := renameio.NewPendingFile(
+		path,
+		renameio.WithTempDir(filepath.Dir(path)),
+		renameio.WithPermissions(0644),
+		renameio.WithExistingPermissions(),
+	)
 	if err != nil {
-		return nil, errors.Wrapf(err, "renamio.TempFile")
+		return "", errors.Wrapf(err, "renamio.TempFile")
 	}
 	defer pf.Cleanup()
 
+	// Renaming the temporary file replaces the original, so copy the original file's owner and group.
+	preserveOwnership(pf.File, path)
+
 	// Compose a reader that calculates the checksum and appends the POSIX EOF indicator.
 	checksummer := NewChecksummer()
 	textReader := tree.ReaderAtPosition(0)
@@ -35,22 +70,66 @@ func Save(path string, tree *text.Tree, watcherPollInterval time.Duration) (*Wat
 	// Write to the file and calculate the checksum.
 	_, err = io.Copy(pf, r)
 	if err != nil {
-		return nil, errors.Wrap(err, "io.Copy")
+		return "", errors.Wrap(err, "io.Copy")
 	}
 
 	// Sync the file to disk so the watcher calculates the checksum correctly later.
 	err = pf.CloseAtomicallyReplace()
-	if err != nil {
-		return nil, errors.Wrap(err, "renamio.CloseAtomicallyReplace")
+	if errors.Is(err, syscall.EXDEV) || errors.Is(err, syscall.EBUSY) {
+		// The target can't be replaced by rename, for example because it is a bind mount
+		// in a container. Fall back to overwriting the target in place, which is not atomic.
+		log.Printf("Could not rename temporary file to %q (%s), so overwriting it instead\n", path, err)
+		if err := copyFileContents(pf.Name(), path); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", errors.Wrap(err, "renamio.CloseAtomicallyReplace")
 	}
 
-	// Start a new watcher for subsequent changes to the file.
+	return checksummer.Checksum(), nil
+}
+
+// preserveOwnership changes the owner and group of f to match the existing file at path.
+// This fails if the user does not have permission to change ownership,
+// in which case the file keeps the current user as its owner.
+func preserveOwnership(f *os.File, path string) {
 	fileInfo, err := os.Stat(path)
 	if err != nil {
-		return nil, errors.Wrapf(err, "os.Stat")
+		return
 	}
-	checksum := checksummer.Checksum()
-	watcher := NewWatcher(watcherPollInterval, path, fileInfo.ModTime(), fileInfo.Size(), checksum)
 
-	return watcher, nil
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+
+	if err := f.Chown(int(stat.Uid), int(stat.Gid)); err != nil {
+		log.Printf("Could not preserve ownership of %q: %s\n", path, err)
+	}
+}
+
+// copyFileContents overwrites the contents of the file at dstPath with the contents of the file at srcPath.
+// Unlike a rename, this preserves the inode of the destination file.
+func copyFileContents(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return errors.Wrap(err, "os.Open")
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return errors.Wrap(err, "os.OpenFile")
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return errors.Wrap(err, "io.Copy")
+	}
+
+	if err := dst.Sync(); err != nil {
+		return errors.Wrap(err, "os.File.Sync")
+	}
+
+	return dst.Close()
 }
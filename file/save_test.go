@@ -3,6 +3,7 @@ package file
 import (
 	"os"
 	"path"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,66 @@ func TestSaveModifyExistingFilePreservePermissions(t *testing.T) {
 	saveAndAssertContents(t, path, "new contents", 0600)
 }
 
+func TestSaveModifyExistingFilePreserveExecutablePermissions(t *testing.T) {
+	path := createTestFile(t, "old contents")
+
+	err := os.Chmod(path, 0755)
+	require.NoError(t, err)
+	saveAndAssertContents(t, path, "new contents", 0755)
+}
+
+func TestSaveModifyExistingFilePreserveOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Changing file ownership requires root")
+	}
+
+	path := createTestFile(t, "old contents")
+	err := os.Chown(path, 1234, 5678)
+	require.NoError(t, err)
+
+	saveAndAssertContents(t, path, "new contents", 0644)
+
+	fileInfo, err := os.Stat(path)
+	require.NoError(t, err)
+	stat := fileInfo.Sys().(*syscall.Stat_t)
+	assert.Equal(t, uint32(1234), stat.Uid)
+	assert.Equal(t, uint32(5678), stat.Gid)
+}
+
+func TestSaveRemovesTemporaryFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := path.Join(tmpDir, "test.txt")
+	saveAndAssertContents(t, path, "abcd1234", 0644)
+
+	// The temporary file is written in the same directory, then renamed to the target.
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "test.txt", entries[0].Name())
+}
+
+func TestCopyFileContents(t *testing.T) {
+	srcPath := createTestFile(t, "new")
+	dstPath := createTestFile(t, "old contents")
+	err := os.Chmod(dstPath, 0600)
+	require.NoError(t, err)
+	dstInfoBefore, err := os.Stat(dstPath)
+	require.NoError(t, err)
+
+	err = copyFileContents(srcPath, dstPath)
+	require.NoError(t, err)
+
+	fileBytes, err := os.ReadFile(dstPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(fileBytes))
+
+	// Overwriting in place preserves the original file's permissions and inode.
+	dstInfoAfter, err := os.Stat(dstPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), dstInfoAfter.Mode().Perm())
+	assert.True(t, os.SameFile(dstInfoBefore, dstInfoAfter))
+}
+
 func saveAndAssertContents(t *testing.T, path string, contents string, perms os.FileMode) {
 	tree, err := text.NewTreeFromString(contents)
 	require.NoError(t, err)
@@ -50,3 +111,16 @@ func saveAndAssertContents(t *testing.T, path string, contents string, perms os.
 	require.NoError(t, err)
 	assert.Equal(t, fileInfo.Mode().Perm(), perms)
 }
+
+func TestSaveCopy(t *testing.T) {
+	path := path.Join(t.TempDir(), "copy.txt")
+	tree, err := text.NewTreeFromString("abcd")
+	require.NoError(t, err)
+
+	err = SaveCopy(path, tree)
+	require.NoError(t, err)
+
+	fileBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd\n", string(fileBytes))
+}
@@ -0,0 +1,46 @@
+package file
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/google/renameio/v2"
+	"github.com/pkg/errors"
+)
+
+// Session records the documents open in the editor so they can be restored later.
+type Session struct {
+	WorkingDir string          `json:"workingDir"`
+	Current    TimelineState   `json:"current"`
+	Alternate  TimelineState   `json:"alternate"`
+	Past       []TimelineState `json:"past"`   // Oldest to newest.
+	Future     []TimelineState `json:"future"` // Newest to oldest.
+}
+
+// SaveSession atomically writes a session to a file as JSON.
+func SaveSession(path string, session Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "json.MarshalIndent")
+	}
+	data = append(data, '\n')
+
+	if err := renameio.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrap(err, "renameio.WriteFile")
+	}
+	return nil
+}
+
+// LoadSession reads a session file written by SaveSession.
+func LoadSession(path string) (Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Session{}, errors.Wrap(err, "os.ReadFile")
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return Session{}, errors.Wrap(err, "json.Unmarshal")
+	}
+	return session, nil
+}
@@ -0,0 +1,46 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	session := Session{
+		WorkingDir: "/home/user/project",
+		Current:    TimelineState{Path: "/home/user/project/c.txt", LineNum: 3, Col: 4},
+		Alternate:  TimelineState{Path: "/home/user/project/b.txt", LineNum: 2},
+		Past: []TimelineState{
+			{Path: "/home/user/project/a.txt", LineNum: 1, Col: 2},
+		},
+		Future: []TimelineState{
+			{Path: "/home/user/project/d.txt"},
+			{Path: "/home/user/project/b.txt", LineNum: 2},
+		},
+	}
+
+	err := SaveSession(path, session)
+	require.NoError(t, err)
+
+	loaded, err := LoadSession(path)
+	require.NoError(t, err)
+	assert.Equal(t, session, loaded)
+}
+
+func TestLoadSessionErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadSession(filepath.Join(dir, "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	invalidPath := filepath.Join(dir, "invalid.json")
+	err = os.WriteFile(invalidPath, []byte("not json"), 0644)
+	require.NoError(t, err)
+	_, err = LoadSession(invalidPath)
+	assert.Error(t, err)
+}
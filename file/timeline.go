@@ -3,9 +3,9 @@ package file
 // TimelineState represents the state of a file loaded in the editor
 // before or after a transition in the timeline.
 type TimelineState struct {
-	Path    string
-	LineNum uint64
-	Col     uint64
+	Path    string `json:"path"`
+	LineNum uint64 `json:"lineNum"`
+	Col     uint64 `json:"col"`
 }
 
 // Empty checks whether the timeline state is empty.
@@ -70,3 +70,20 @@ func (t *Timeline) PeekForward() TimelineState {
 	}
 	return t.futureStates[len(t.futureStates)-1]
 }
+
+// PastStates returns the states before the current state, from oldest to newest.
+func (t *Timeline) PastStates() []TimelineState {
+	return append([]TimelineState(nil), t.pastStates...)
+}
+
+// FutureStates returns the states after the current state, from newest to oldest.
+func (t *Timeline) FutureStates() []TimelineState {
+	return append([]TimelineState(nil), t.futureStates...)
+}
+
+// Restore replaces the past and future states of the timeline,
+// using the same ordering as PastStates and FutureStates.
+func (t *Timeline) Restore(pastStates []TimelineState, futureStates []TimelineState) {
+	t.pastStates = append(t.pastStates[:0], pastStates...)
+	t.futureStates = append(t.futureStates[:0], futureStates...)
+}
@@ -92,3 +92,21 @@ func assertPrevAndNext(t *testing.T, timeline *Timeline, prev, next TimelineStat
 	assert.Equal(t, prev, timeline.PeekBackward())
 	assert.Equal(t, next, timeline.PeekForward())
 }
+
+func TestTimelineRestore(t *testing.T) {
+	timeline := NewTimeline()
+	timeline.TransitionFrom(TimelineState{Path: "f1", LineNum: 1})
+	timeline.TransitionFrom(TimelineState{Path: "f2", LineNum: 2})
+	timeline.TransitionBackwardFrom(TimelineState{Path: "f3", LineNum: 3})
+	past, future := timeline.PastStates(), timeline.FutureStates()
+
+	restored := NewTimeline()
+	restored.Restore(past, future)
+	assert.Equal(t, past, restored.PastStates())
+	assert.Equal(t, future, restored.FutureStates())
+	assertPrevAndNext(t, restored, TimelineState{Path: "f1", LineNum: 1}, TimelineState{Path: "f3", LineNum: 3})
+
+	// Moving through the restored timeline does not modify the original.
+	restored.TransitionForwardFrom(TimelineState{Path: "f2", LineNum: 4})
+	assertPrevAndNext(t, timeline, TimelineState{Path: "f1", LineNum: 1}, TimelineState{Path: "f3", LineNum: 3})
+}
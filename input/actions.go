@@ -1,6 +1,8 @@
 package input
 
 import (
+	"unicode/utf8"
+
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/locate"
 	"github.com/aretext/aretext/selection"
@@ -21,6 +23,12 @@ func CursorLeft(count uint64) Action {
 	}
 }
 
+func CursorLeftAllowVirtual(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorLeft(s, count)
+	}
+}
+
 func CursorBack(count uint64) Action {
 	return func(s *state.EditorState) {
 		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
@@ -37,6 +45,12 @@ func CursorRight(count uint64) Action {
 	}
 }
 
+func CursorRightAllowVirtual(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorRight(s, count)
+	}
+}
+
 func CursorRightIncludeEndOfLineOrFile(s *state.EditorState) {
 	state.MoveCursor(s, func(params state.LocatorParams) uint64 {
 		return locate.NextCharInLine(params.TextTree, 1, true, params.CursorPos)
@@ -55,6 +69,18 @@ func CursorDown(count uint64) Action {
 	}
 }
 
+func CursorUpDisplayRow(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorToDisplayRowAbove(s, count)
+	}
+}
+
+func CursorDownDisplayRow(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorToDisplayRowBelow(s, count)
+	}
+}
+
 func CursorNextLine(count uint64) Action {
 	return func(s *state.EditorState) {
 		state.MoveCursorToLineBelow(s, count)
@@ -103,6 +129,7 @@ func CursorNextParagraph(s *state.EditorState) {
 
 func CursorToNextMatchingChar(char rune, count uint64, includeChar bool) Action {
 	return func(s *state.EditorState) {
+		state.SetLastMatchChar(s, char, false, includeChar)
 		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
 			found, pos := locate.NextMatchingCharInLine(params.TextTree, char, count, includeChar, params.CursorPos)
 			if !found {
@@ -115,6 +142,7 @@ func CursorToNextMatchingChar(char rune, count uint64, includeChar bool) Action
 
 func CursorToPrevMatchingChar(char rune, count uint64, includeChar bool) Action {
 	return func(s *state.EditorState) {
+		state.SetLastMatchChar(s, char, true, includeChar)
 		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
 			found, pos := locate.PrevMatchingCharInLine(params.TextTree, char, count, includeChar, params.CursorPos)
 			if !found {
@@ -125,39 +153,75 @@ func CursorToPrevMatchingChar(char rune, count uint64, includeChar bool) Action
 	}
 }
 
+func CursorRepeatLastMatchingChar(count uint64, oppositeDirection bool) Action {
+	return func(s *state.EditorState) {
+		state.RepeatLastMatchChar(s, count, oppositeDirection)
+	}
+}
+
 func ScrollUp(ctx Context, half bool) Action {
 	scrollLines := ctx.ScrollLines
+	if half {
+		scrollLines /= 2
+	}
 	if scrollLines < 1 {
 		scrollLines = 1
-	} else if half {
-		scrollLines /= 2
 	}
 
 	return func(s *state.EditorState) {
-		// Move the cursor to the start of a line above, then scroll up.
-		// (We don't scroll the view, because that happens automatically after every action.)
-		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-			return locate.StartOfLineAbove(params.TextTree, scrollLines, params.CursorPos)
-		})
-		state.ScrollViewByNumLines(s, state.ScrollDirectionBackward, scrollLines)
+		state.ScrollViewAndCursorByNumLines(s, state.ScrollDirectionBackward, scrollLines)
 	}
 }
 
 func ScrollDown(ctx Context, half bool) Action {
 	scrollLines := ctx.ScrollLines
+	if half {
+		scrollLines /= 2
+	}
 	if scrollLines < 1 {
 		scrollLines = 1
-	} else if half {
-		scrollLines /= 2
 	}
 
 	return func(s *state.EditorState) {
-		// Move the cursor to the start of a line below, then scroll down.
-		// (We don't scroll the view, because that happens automatically after every action.)
-		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-			return locate.StartOfLineBelow(params.TextTree, scrollLines, params.CursorPos)
-		})
-		state.ScrollViewByNumLines(s, state.ScrollDirectionForward, scrollLines)
+		state.ScrollViewAndCursorByNumLines(s, state.ScrollDirectionForward, scrollLines)
+	}
+}
+
+func ScrollCursorToTop(s *state.EditorState) {
+	state.ScrollViewCursorToTop(s)
+}
+
+func ScrollCursorToCenter(s *state.EditorState) {
+	state.ScrollViewCursorToCenter(s)
+}
+
+func ScrollCursorToBottom(s *state.EditorState) {
+	state.ScrollViewCursorToBottom(s)
+}
+
+func MouseScroll(direction state.ScrollDirection, numLines uint64) Action {
+	return func(s *state.EditorState) {
+		state.ScrollViewAndCursorByNumLines(s, direction, numLines)
+	}
+}
+
+func MouseClick(col, row uint64) Action {
+	return func(s *state.EditorState) {
+		if s.InputMode() == state.InputModeVisual {
+			state.SetInputMode(s, state.InputModeNormal)
+		}
+		state.MoveCursorToViewCell(s, col, row)
+		state.ScrollViewToCursor(s)
+	}
+}
+
+func MouseDrag(col, row uint64) Action {
+	return func(s *state.EditorState) {
+		if s.InputMode() != state.InputModeVisual {
+			state.ToggleVisualMode(s, selection.ModeChar)
+		}
+		state.MoveCursorToViewCell(s, col, row)
+		state.ScrollViewToCursor(s)
 	}
 }
 
@@ -194,23 +258,39 @@ func CursorStartOfLineNum(count uint64) Action {
 	}
 
 	return func(s *state.EditorState) {
-		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-			lineStartPos := locate.StartOfLineNum(params.TextTree, lineNum)
-			return locate.NextNonWhitespaceOrNewline(params.TextTree, lineStartPos)
+		state.MoveCursorToLineAt(s, func(params state.LocatorParams) uint64 {
+			return locate.StartOfLineNum(params.TextTree, lineNum)
+		})
+	}
+}
+
+func CursorStartOfLineAtPercent(percent uint64) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorToLineAt(s, func(params state.LocatorParams) uint64 {
+			return locate.StartOfLineAtPercent(params.TextTree, percent)
 		})
 	}
 }
 
 func CursorStartOfLastLine(s *state.EditorState) {
-	state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-		lineStartPos := locate.StartOfLastLine(params.TextTree)
-		return locate.NextNonWhitespaceOrNewline(params.TextTree, lineStartPos)
+	state.MoveCursorToLineAt(s, func(params state.LocatorParams) uint64 {
+		return locate.StartOfLastLine(params.TextTree)
 	})
 }
 
 func CursorMatchingCodeBlockDelimiter(s *state.EditorState) {
 	state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-		matchPos, hasMatch := locate.MatchingCodeBlockDelimiter(params.TextTree, params.SyntaxParser, params.CursorPos)
+		if params.MatchTags {
+			if matchPos, hasMatch := locate.MatchingTag(params.TextTree, params.CursorPos); hasMatch {
+				return matchPos
+			}
+		}
+
+		if matchPos, hasMatch := locate.MatchingKeyword(params.TextTree, params.SyntaxParser, params.CursorPos, params.MatchWords); hasMatch {
+			return matchPos
+		}
+
+		matchPos, hasMatch := locate.MatchingCodeBlockDelimiter(params.TextTree, params.SyntaxParser, params.CursorPos, params.MatchPairs)
 		if hasMatch {
 			return matchPos
 		} else {
@@ -263,6 +343,33 @@ func CursorNextUnmatchedCloseParen(s *state.EditorState) {
 	})
 }
 
+func CursorPrevGitHunk(s *state.EditorState) {
+	reverse := true
+	state.MoveCursorToNextGitHunk(s, reverse)
+}
+
+func CursorNextGitHunk(s *state.EditorState) {
+	state.MoveCursorToNextGitHunk(s, false)
+}
+
+func CursorPrevDiagnostic(s *state.EditorState) {
+	reverse := true
+	state.MoveCursorToNextDiagnostic(s, reverse)
+}
+
+func CursorNextDiagnostic(s *state.EditorState) {
+	state.MoveCursorToNextDiagnostic(s, false)
+}
+
+func CursorPrevHeading(s *state.EditorState) {
+	reverse := true
+	state.MoveCursorToNextHeading(s, reverse)
+}
+
+func CursorNextHeading(s *state.EditorState) {
+	state.MoveCursorToNextHeading(s, false)
+}
+
 func EnterInsertMode(s *state.EditorState) {
 	state.SetInputMode(s, state.InputModeInsert)
 }
@@ -274,6 +381,11 @@ func EnterInsertModeAtStartOfLine(s *state.EditorState) {
 
 func EnterInsertModeAtNextPos(s *state.EditorState) {
 	state.SetInputMode(s, state.InputModeInsert)
+	if s.DocumentBuffer().CursorVirtualOffset() > 0 {
+		// Append after the virtual column, not after the last character in the line.
+		state.MoveCursorRight(s, 1)
+		return
+	}
 	CursorRightIncludeEndOfLineOrFile(s)
 }
 
@@ -282,27 +394,80 @@ func EnterInsertModeAtEndOfLine(s *state.EditorState) {
 	CursorLineEndIncludeEndOfLineOrFile(s)
 }
 
+// EnterInsertModeWithCount executes an action that enters insert mode,
+// then repeats the inserted text count-1 additional times when the user returns to normal mode.
+// If repeatPrefix is not nil, it executes before each repetition.
+func EnterInsertModeWithCount(count uint64, enterAction Action, repeatPrefix Action) Action {
+	return func(s *state.EditorState) {
+		enterAction(s)
+		state.BeginInsertRepeat(s, count, state.MacroAction(repeatPrefix))
+	}
+}
+
 func ReturnToNormalMode(s *state.EditorState) {
 	state.SetInputMode(s, state.InputModeNormal)
 }
 
 func ReturnToNormalModeAfterInsert(s *state.EditorState) {
-	state.ClearAutoIndentWhitespaceLine(s, func(params state.LocatorParams) uint64 {
-		return locate.StartOfLineAtPos(params.TextTree, params.CursorPos)
-	})
-	state.MoveCursor(s, func(params state.LocatorParams) uint64 {
-		return locate.PrevCharInLine(params.TextTree, 1, false, params.CursorPos)
+	state.ReplayInsertRepeat(s)
+	state.ApplyAtAllCursors(s, func(s *state.EditorState) {
+		state.ClearAutoIndentWhitespaceLine(s, func(params state.LocatorParams) uint64 {
+			return locate.StartOfLineAtPos(params.TextTree, params.CursorPos)
+		})
+		state.MoveCursorLeft(s, 1)
 	})
 	state.SetInputMode(s, state.InputModeNormal)
 }
 
+func AddCursorAtNextMatch(s *state.EditorState) {
+	state.AddCursorAtNextMatch(s)
+}
+
+func RemoveExtraCursors(s *state.EditorState) {
+	state.RemoveExtraCursors(s)
+}
+
+// AtAllCursors performs an insert mode edit at the main cursor and at every extra cursor.
+func AtAllCursors(action Action) Action {
+	return func(s *state.EditorState) {
+		state.ApplyAtAllCursors(s, action)
+	}
+}
+
 func InsertRune(r rune) Action {
+	return func(s *state.EditorState) {
+		state.ExpandAbbreviation(s, r)
+		state.InsertRune(s, r)
+		if r == '>' {
+			state.AutoCloseTag(s)
+		}
+	}
+}
+
+// InsertRuneLiteral inserts a rune without expanding an abbreviation before it.
+func InsertRuneLiteral(r rune) Action {
 	return func(s *state.EditorState) {
 		state.InsertRune(s, r)
 	}
 }
 
+// InsertCodePoint inserts the character with a Unicode code point, like vim's ctrl-v u{hex} in insert mode.
+func InsertCodePoint(codePoint rune) Action {
+	return func(s *state.EditorState) {
+		if codePoint == utf8.RuneError {
+			state.SetStatusMsg(s, state.StatusMsg{
+				Style: state.StatusMsgStyleError,
+				Text:  "Invalid code point",
+			})
+		} else {
+			state.InsertRune(s, codePoint)
+		}
+	}
+}
+
 func InsertNewlineAndUpdateAutoIndentWhitespace(s *state.EditorState) {
+	state.ExpandAbbreviation(s, '\n')
+	state.ClearEmptyLineComment(s)
 	state.InsertNewline(s)
 	state.ClearAutoIndentWhitespaceLine(s, func(params state.LocatorParams) uint64 {
 		return locate.StartOfLineAbove(params.TextTree, 1, params.CursorPos)
@@ -310,6 +475,10 @@ func InsertNewlineAndUpdateAutoIndentWhitespace(s *state.EditorState) {
 }
 
 func InsertTab(s *state.EditorState) {
+	if state.ExpandSnippet(s) || state.JumpToNextSnippetStop(s) {
+		return
+	}
+	state.ExpandAbbreviation(s, '\t')
 	state.InsertTab(s)
 }
 
@@ -321,6 +490,7 @@ func DeletePrevChar(clipboardPage clipboard.PageId) Action {
 				params.TextTree,
 				params.AutoIndentEnabled,
 				params.TabSize,
+				params.VarTabStops,
 				params.CursorPos)
 			if prevInLinePos < prevAutoIndentPos {
 				return prevInLinePos
@@ -331,6 +501,63 @@ func DeletePrevChar(clipboardPage clipboard.PageId) Action {
 	}
 }
 
+func DeletePrevWordInLine(clipboardPage clipboard.PageId) Action {
+	return func(s *state.EditorState) {
+		state.DeleteToPos(s, func(params state.LocatorParams) uint64 {
+			lineStartPos := locate.StartOfLineAtPos(params.TextTree, params.CursorPos)
+			indentEndPos := locate.NextNonWhitespaceOrNewline(params.TextTree, lineStartPos)
+			if params.AutoIndentEnabled && params.CursorPos <= indentEndPos {
+				// Within the indentation, so delete to the previous tab stop like backspace.
+				return locate.PrevAutoIndent(
+					params.TextTree,
+					params.AutoIndentEnabled,
+					params.TabSize,
+					params.VarTabStops,
+					params.CursorPos)
+			}
+
+			pos := locate.PrevWordStart(params.TextTree, params.CursorPos, 1, false)
+			if pos < lineStartPos {
+				pos = lineStartPos
+			}
+			if params.AutoIndentEnabled && pos < indentEndPos {
+				// Preserve the auto-indent whitespace at the start of the line.
+				pos = indentEndPos
+			}
+			return pos
+		}, clipboardPage)
+	}
+}
+
+func DeleteToStartOfLineOrIndent(clipboardPage clipboard.PageId) Action {
+	return func(s *state.EditorState) {
+		state.DeleteToPos(s, func(params state.LocatorParams) uint64 {
+			lineStartPos := locate.StartOfLineAtPos(params.TextTree, params.CursorPos)
+			if params.AutoIndentEnabled {
+				// Preserve the auto-indent whitespace at the start of the line,
+				// unless the cursor is already at or within the indentation.
+				indentEndPos := locate.NextNonWhitespaceOrNewline(params.TextTree, lineStartPos)
+				if params.CursorPos > indentEndPos {
+					return indentEndPos
+				}
+			}
+			return lineStartPos
+		}, clipboardPage)
+	}
+}
+
+func InsertFromClipboard(clipboardPage clipboard.PageId) Action {
+	return func(s *state.EditorState) {
+		state.PasteInInsertMode(s, clipboardPage)
+	}
+}
+
+func InsertDigraph(digraph [2]rune) Action {
+	return func(s *state.EditorState) {
+		state.InsertDigraph(s, digraph[0], digraph[1])
+	}
+}
+
 func BeginNewLineBelow(s *state.EditorState) {
 	CursorLineEndIncludeEndOfLineOrFile(s)
 	state.InsertNewline(s)
@@ -346,19 +573,6 @@ func JoinLines(s *state.EditorState) {
 	state.JoinLines(s)
 }
 
-func DeleteLines(count uint64, clipboardPage clipboard.PageId) Action {
-	if count > 0 {
-		count--
-	}
-	return func(s *state.EditorState) {
-		targetLoc := func(params state.LocatorParams) uint64 {
-			return locate.StartOfLineBelow(params.TextTree, count, params.CursorPos)
-		}
-		state.DeleteLines(s, targetLoc, false, false, clipboardPage)
-		CursorLineStartNonWhitespace(s)
-	}
-}
-
 func DeletePrevCharInLine(clipboardPage clipboard.PageId) Action {
 	return func(s *state.EditorState) {
 		state.DeleteToPos(s, func(params state.LocatorParams) uint64 {
@@ -380,6 +594,7 @@ func DeleteNextCharInLine(count uint64, clipboardPage clipboard.PageId) Action {
 
 func DeleteToNextMatchingChar(char rune, count uint64, clipboardPage clipboard.PageId, includeChar bool) Action {
 	return func(s *state.EditorState) {
+		state.SetLastMatchChar(s, char, false, includeChar)
 		state.DeleteToPos(s, func(params state.LocatorParams) uint64 {
 			found, pos := locate.NextMatchingCharInLine(params.TextTree, char, count, includeChar, params.CursorPos)
 			if !found {
@@ -397,6 +612,7 @@ func DeleteToNextMatchingChar(char rune, count uint64, clipboardPage clipboard.P
 
 func DeleteToPrevMatchingChar(char rune, count uint64, clipboardPage clipboard.PageId, includeChar bool) Action {
 	return func(s *state.EditorState) {
+		state.SetLastMatchChar(s, char, true, includeChar)
 		state.DeleteToPos(s, func(params state.LocatorParams) uint64 {
 			found, pos := locate.PrevMatchingCharInLine(params.TextTree, char, count, includeChar, params.CursorPos)
 			if !found {
@@ -551,24 +767,6 @@ func ToggleCaseAtCursor(s *state.EditorState) {
 	state.ToggleCaseAtCursor(s)
 }
 
-func IndentLine(count uint64) Action {
-	return func(s *state.EditorState) {
-		targetLineLoc := func(p state.LocatorParams) uint64 {
-			return locate.StartOfLineBelow(p.TextTree, count-1, p.CursorPos)
-		}
-		state.IndentLines(s, targetLineLoc, 1)
-	}
-}
-
-func OutdentLine(count uint64) Action {
-	return func(s *state.EditorState) {
-		targetLineLoc := func(p state.LocatorParams) uint64 {
-			return locate.StartOfLineBelow(p.TextTree, count-1, p.CursorPos)
-		}
-		state.OutdentLines(s, targetLineLoc, 1)
-	}
-}
-
 func CopyToStartOfNextWord(count uint64, clipboardPage clipboard.PageId, withPunctuation bool) Action {
 	return func(s *state.EditorState) {
 		state.CopyRange(s, clipboardPage, func(params state.LocatorParams) (uint64, uint64) {
@@ -595,12 +793,6 @@ func CopyInnerWord(count uint64, clipboardPage clipboard.PageId) Action {
 	}
 }
 
-func CopyLines(clipboardPage clipboard.PageId) Action {
-	return func(s *state.EditorState) {
-		state.CopyLine(s, clipboardPage)
-	}
-}
-
 func PasteAfterCursor(clipboardPage clipboard.PageId) Action {
 	return func(s *state.EditorState) {
 		state.PasteAfterCursor(s, clipboardPage)
@@ -613,6 +805,18 @@ func PasteBeforeCursor(clipboardPage clipboard.PageId) Action {
 	}
 }
 
+func ShowLanguageServerHover(s *state.EditorState) {
+	state.ShowLanguageServerHover(s)
+}
+
+func GoToLanguageServerDefinition(s *state.EditorState) {
+	state.GoToLanguageServerDefinition(s)
+}
+
+func LoadAlternateDocument(s *state.EditorState) {
+	state.AutoWriteOrAbortIfUnsavedChanges(s, state.LoadAlternateDocument, true)
+}
+
 func ShowCommandMenu(ctx Context) Action {
 	return func(s *state.EditorState) {
 		// This sets the input mode to menu.
@@ -705,6 +909,18 @@ func Redo(s *state.EditorState) {
 	state.Redo(s)
 }
 
+func UndoEarlier(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.UndoEarlier(s, count)
+	}
+}
+
+func UndoLater(count uint64) Action {
+	return func(s *state.EditorState) {
+		state.UndoLater(s, count)
+	}
+}
+
 func ToggleVisualModeCharwise(s *state.EditorState) {
 	state.ToggleVisualMode(s, selection.ModeChar)
 }
@@ -713,6 +929,14 @@ func ToggleVisualModeLinewise(s *state.EditorState) {
 	state.ToggleVisualMode(s, selection.ModeLine)
 }
 
+func ReselectLastSelection(s *state.EditorState) {
+	state.ReselectLastSelection(s)
+}
+
+func SwapSelectionEnds(s *state.EditorState) {
+	state.SwapSelectionEnds(s)
+}
+
 func DeleteSelection(clipboardPage clipboard.PageId, selectionMode selection.Mode, selectionEndLoc state.Locator, replaceWithEmptyLine bool) Action {
 	return func(s *state.EditorState) {
 		state.MoveCursorToStartOfSelection(s)
@@ -756,6 +980,22 @@ func OutdentSelectionAndReturnToNormalMode(selectionEndLoc state.Locator, count
 	}
 }
 
+func FormatSelectionAndReturnToNormalMode(selectionEndLoc state.Locator) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorToStartOfSelection(s)
+		state.FormatLines(s, selectionEndLoc)
+		ReturnToNormalMode(s)
+	}
+}
+
+func AlignSelectionAndReturnToNormalMode(selectionEndLoc state.Locator, delimiter rune) Action {
+	return func(s *state.EditorState) {
+		state.MoveCursorToStartOfSelection(s)
+		state.AlignLines(s, selectionEndLoc, delimiter)
+		ReturnToNormalMode(s)
+	}
+}
+
 func ChangeSelection(clipboardPage clipboard.PageId, selectionMode selection.Mode, selectionEndLoc state.Locator) Action {
 	deleteSelectionAction := DeleteSelection(clipboardPage, selectionMode, selectionEndLoc, true)
 	return func(s *state.EditorState) {
@@ -771,6 +1011,12 @@ func CopySelectionAndReturnToNormalMode(clipboardPage clipboard.PageId) Action {
 	}
 }
 
+func ApplyOperator(op state.Operator, motion state.Motion, clipboardPage clipboard.PageId) Action {
+	return func(s *state.EditorState) {
+		state.ApplyOperator(s, op, motion, clipboardPage)
+	}
+}
+
 func ReplayLastActionMacro(count uint64) Action {
 	return func(s *state.EditorState) {
 		state.ReplayLastActionMacro(s, count)
@@ -1,11 +1,13 @@
 package input
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/gdamore/tcell/v2"
 
 	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/input/vm"
 	"github.com/aretext/aretext/state"
 )
@@ -17,6 +19,8 @@ type CommandParams struct {
 	MatchChar     rune
 	ReplaceChar   rune
 	InsertChar    rune
+	Digraph       [2]rune
+	CodePoint     rune
 }
 
 // Command defines a command that the input parser can recognize.
@@ -37,19 +41,32 @@ const defaultMaxCount = uint64(1024)
 
 // These commands control cursor movement in normal and visual mode.
 func cursorCommands() []Command {
-	decorate := func(action Action) Action {
+	decorateWrapped := func(wrappedAction Action) Action {
 		return func(s *state.EditorState) {
-			wrappedAction := func(s *state.EditorState) {
-				action(s)
-				state.ScrollViewToCursor(s)
-				state.SetStatusMsg(s, state.StatusMsg{})
-			}
 			state.CheckpointUndoLog(s)
 			wrappedAction(s)
 			state.AddToRecordingUserMacro(s, state.MacroAction(wrappedAction))
 		}
 	}
 
+	decorate := func(action Action) Action {
+		return decorateWrapped(func(s *state.EditorState) {
+			action(s)
+			state.ScrollViewToCursor(s)
+			state.SetStatusMsg(s, state.StatusMsg{})
+		})
+	}
+
+	// This clears the status message before the action instead of after,
+	// so the action can report why the cursor did not move.
+	decorateReportStatus := func(action Action) Action {
+		return decorateWrapped(func(s *state.EditorState) {
+			state.SetStatusMsg(s, state.StatusMsg{})
+			action(s)
+			state.ScrollViewToCursor(s)
+		})
+	}
+
 	return []Command{
 		{
 			Name: "cursor left (left arrow or h)",
@@ -57,7 +74,7 @@ func cursorCommands() []Command {
 				return verbCountThenExpr(altExpr(keyExpr(tcell.KeyLeft), runeExpr('h')))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorLeft(p.Count))
+				return decorate(CursorLeftAllowVirtual(p.Count))
 			},
 		},
 		{
@@ -66,7 +83,7 @@ func cursorCommands() []Command {
 				return verbCountThenExpr(altExpr(keyExpr(tcell.KeyRight), runeExpr('l'), runeExpr(' ')))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorRight(p.Count))
+				return decorate(CursorRightAllowVirtual(p.Count))
 			},
 		},
 		{
@@ -87,6 +104,24 @@ func cursorCommands() []Command {
 				return decorate(CursorDown(p.Count))
 			},
 		},
+		{
+			Name: "cursor up display row (gk)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("gk", "", captureOpts{count: true})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(CursorUpDisplayRow(p.Count))
+			},
+		},
+		{
+			Name: "cursor down display row (gj)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("gj", "", captureOpts{count: true})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(CursorDownDisplayRow(p.Count))
+			},
+		},
 		{
 			Name: "first non-whitespace of next line (enter)",
 			BuildExpr: func() vm.Expr {
@@ -217,6 +252,26 @@ func cursorCommands() []Command {
 				return decorate(CursorToPrevMatchingChar(p.MatchChar, p.Count, false))
 			},
 		},
+		{
+			Name: "repeat last matching char motion (;)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr(";", "", captureOpts{count: true})
+			},
+			MaxCount: defaultMaxCount,
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(CursorRepeatLastMatchingChar(p.Count, false))
+			},
+		},
+		{
+			Name: "repeat last matching char motion in opposite direction (,)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr(",", "", captureOpts{count: true})
+			},
+			MaxCount: defaultMaxCount,
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(CursorRepeatLastMatchingChar(p.Count, true))
+			},
+		},
 		{
 			Name: "cursor line start (0)",
 			BuildExpr: func() vm.Expr {
@@ -265,6 +320,16 @@ func cursorCommands() []Command {
 				return decorate(CursorStartOfLastLine)
 			},
 		},
+		{
+			Name: "cursor start of line at percent (N%)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("%", "", captureOpts{requiredCount: true})
+			},
+			MaxCount: 100,
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(CursorStartOfLineAtPercent(p.Count))
+			},
+		},
 		{
 			Name: "cursor matching code block delimiter (%)",
 			BuildExpr: func() vm.Expr {
@@ -310,6 +375,60 @@ func cursorCommands() []Command {
 				return decorate(CursorNextUnmatchedCloseParen)
 			},
 		},
+		{
+			Name: "cursor prev git hunk ([c)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("[c", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorPrevGitHunk)
+			},
+		},
+		{
+			Name: "cursor next git hunk (]c)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("]c", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorNextGitHunk)
+			},
+		},
+		{
+			Name: "cursor prev diagnostic ([d)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("[d", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorPrevDiagnostic)
+			},
+		},
+		{
+			Name: "cursor next diagnostic (]d)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("]d", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorNextDiagnostic)
+			},
+		},
+		{
+			Name: "cursor prev markdown heading ([[)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("[[", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorPrevHeading)
+			},
+		},
+		{
+			Name: "cursor next markdown heading (]])",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("]]", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateReportStatus(CursorNextHeading)
+			},
+		},
 		{
 			Name: "scroll up (ctrl-u)",
 			BuildExpr: func() vm.Expr {
@@ -346,6 +465,33 @@ func cursorCommands() []Command {
 				return decorate(ScrollDown(ctx, true))
 			},
 		},
+		{
+			Name: "scroll cursor to top (zt)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("zt", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(ScrollCursorToTop)
+			},
+		},
+		{
+			Name: "scroll cursor to center (zz)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("zz", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(ScrollCursorToCenter)
+			},
+		},
+		{
+			Name: "scroll cursor to bottom (zb)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("zb", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(ScrollCursorToBottom)
+			},
+		},
 	}
 }
 
@@ -355,13 +501,26 @@ type addToMacro struct {
 }
 
 func decorateNormalOrVisual(action Action, addToMacro addToMacro) Action {
-	return func(s *state.EditorState) {
-		wrappedAction := func(s *state.EditorState) {
-			action(s)
-			state.ScrollViewToCursor(s)
-			state.SetStatusMsg(s, state.StatusMsg{})
-		}
+	return decorateNormalOrVisualWrapped(func(s *state.EditorState) {
+		action(s)
+		state.ScrollViewToCursor(s)
+		state.SetStatusMsg(s, state.StatusMsg{})
+	}, addToMacro)
+}
 
+// decorateNormalOrVisualReportStatus is like decorateNormalOrVisual,
+// except that it clears the status message before the action instead of after,
+// so the action can set a status message of its own.
+func decorateNormalOrVisualReportStatus(action Action, addToMacro addToMacro) Action {
+	return decorateNormalOrVisualWrapped(func(s *state.EditorState) {
+		state.SetStatusMsg(s, state.StatusMsg{})
+		action(s)
+		state.ScrollViewToCursor(s)
+	}, addToMacro)
+}
+
+func decorateNormalOrVisualWrapped(wrappedAction Action, addToMacro addToMacro) Action {
+	return func(s *state.EditorState) {
 		state.CheckpointUndoLog(s)
 		wrappedAction(s)
 
@@ -377,70 +536,78 @@ func decorateNormalOrVisual(action Action, addToMacro addToMacro) Action {
 }
 
 func NormalModeCommands() []Command {
-	return append(cursorCommands(), []Command{
+	commands := append(cursorCommands(), []Command{
 		{
 			Name: "enter insert mode (i)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("i", "", captureOpts{})
+				return cmdExpr("i", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					EnterInsertMode,
+					EnterInsertModeWithCount(p.Count, EnterInsertMode, nil),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
 		{
 			Name: "enter insert mode at start of line (I)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("I", "", captureOpts{})
+				return cmdExpr("I", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					EnterInsertModeAtStartOfLine,
+					EnterInsertModeWithCount(p.Count, AtAllCursors(EnterInsertModeAtStartOfLine), nil),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
 		{
 			Name: "enter insert mode at next pos (a)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("a", "", captureOpts{})
+				return cmdExpr("a", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					EnterInsertModeAtNextPos,
+					EnterInsertModeWithCount(p.Count, AtAllCursors(EnterInsertModeAtNextPos), nil),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
 		{
 			Name: "enter insert mode at end of line (A)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("A", "", captureOpts{})
+				return cmdExpr("A", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					EnterInsertModeAtEndOfLine,
+					EnterInsertModeWithCount(p.Count, AtAllCursors(EnterInsertModeAtEndOfLine), nil),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
 		{
 			Name: "begin new line below (o)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("o", "", captureOpts{})
+				return cmdExpr("o", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					BeginNewLineBelow,
+					EnterInsertModeWithCount(p.Count, BeginNewLineBelow, BeginNewLineBelow),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
 		{
 			Name: "begin new line above (O)",
 			BuildExpr: func() vm.Expr {
-				return cmdExpr("O", "", captureOpts{})
+				return cmdExpr("O", "", captureOpts{count: true})
 			},
+			MaxCount: defaultMaxCount,
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
-					BeginNewLineAbove,
+					// Each repetition begins below the previously inserted line,
+					// so the cursor ends on the last inserted line.
+					EnterInsertModeWithCount(p.Count, BeginNewLineAbove, BeginNewLineBelow),
 					addToMacro{lastAction: true, user: true})
 			},
 		},
@@ -455,18 +622,6 @@ func NormalModeCommands() []Command {
 					addToMacro{lastAction: true, user: true})
 			},
 		},
-		{
-			Name: "delete line (dd)",
-			BuildExpr: func() vm.Expr {
-				return cmdExpr("dd", "", captureOpts{count: true, clipboardPage: true})
-			},
-			MaxCount: defaultMaxCount,
-			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
-					DeleteLines(p.Count, p.ClipboardPage),
-					addToMacro{lastAction: true, user: true})
-			},
-		},
 		{
 			Name: "delete prev char in line (dh)",
 			BuildExpr: func() vm.Expr {
@@ -754,30 +909,6 @@ func NormalModeCommands() []Command {
 					addToMacro{lastAction: true, user: true})
 			},
 		},
-		{
-			Name: "indent (>>)",
-			BuildExpr: func() vm.Expr {
-				return cmdExpr(">>", "", captureOpts{count: true})
-			},
-			MaxCount: defaultMaxCount,
-			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
-					IndentLine(p.Count),
-					addToMacro{lastAction: true, user: true})
-			},
-		},
-		{
-			Name: "outdent (<<)",
-			BuildExpr: func() vm.Expr {
-				return cmdExpr("<<", "", captureOpts{count: true})
-			},
-			MaxCount: defaultMaxCount,
-			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
-					OutdentLine(p.Count),
-					addToMacro{lastAction: true, user: true})
-			},
-		},
 		{
 			Name: "yank to start of next word (yw)",
 			BuildExpr: func() vm.Expr {
@@ -822,17 +953,6 @@ func NormalModeCommands() []Command {
 					addToMacro{lastAction: true, user: true})
 			},
 		},
-		{
-			Name: "yank line (yy)",
-			BuildExpr: func() vm.Expr {
-				return cmdExpr("yy", "", captureOpts{clipboardPage: true})
-			},
-			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
-					CopyLines(p.ClipboardPage),
-					addToMacro{lastAction: true, user: true})
-			},
-		},
 		{
 			Name: "put after cursor (p)",
 			BuildExpr: func() vm.Expr {
@@ -866,6 +986,39 @@ func NormalModeCommands() []Command {
 					addToMacro{})
 			},
 		},
+		{
+			Name: "show language server hover (K)",
+			BuildExpr: func() vm.Expr {
+				return runeExpr('K')
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisualReportStatus(
+					ShowLanguageServerHover,
+					addToMacro{})
+			},
+		},
+		{
+			Name: "go to language server definition (gd)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("gd", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisualReportStatus(
+					GoToLanguageServerDefinition,
+					addToMacro{})
+			},
+		},
+		{
+			Name: "open alternate document (ctrl-^)",
+			BuildExpr: func() vm.Expr {
+				return keyExpr(tcell.KeyCtrlCarat)
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisualReportStatus(
+					LoadAlternateDocument,
+					addToMacro{})
+			},
+		},
 		{
 			Name: "start forward search",
 			BuildExpr: func() vm.Expr {
@@ -894,7 +1047,7 @@ func NormalModeCommands() []Command {
 				return runeExpr('n')
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
+				return decorateNormalOrVisualReportStatus(
 					FindNextMatch,
 					addToMacro{user: true})
 			},
@@ -905,7 +1058,7 @@ func NormalModeCommands() []Command {
 				return runeExpr('N')
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
+				return decorateNormalOrVisualReportStatus(
 					FindPrevMatch,
 					addToMacro{user: true})
 			},
@@ -916,7 +1069,7 @@ func NormalModeCommands() []Command {
 				return cmdExpr("*", "", captureOpts{count: true})
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
+				return decorateNormalOrVisualReportStatus(
 					SearchWordUnderCursor(state.SearchDirectionForward, p.Count),
 					addToMacro{user: true})
 			},
@@ -927,7 +1080,7 @@ func NormalModeCommands() []Command {
 				return cmdExpr("#", "", captureOpts{count: true})
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorateNormalOrVisual(
+				return decorateNormalOrVisualReportStatus(
 					SearchWordUnderCursor(state.SearchDirectionBackward, p.Count),
 					addToMacro{user: true})
 			},
@@ -954,6 +1107,30 @@ func NormalModeCommands() []Command {
 					addToMacro{user: true})
 			},
 		},
+		{
+			Name: "undo earlier state (g-)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("g-", "", captureOpts{count: true})
+			},
+			MaxCount: defaultMaxCount,
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					UndoEarlier(p.Count),
+					addToMacro{user: true})
+			},
+		},
+		{
+			Name: "undo later state (g+)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("g+", "", captureOpts{count: true})
+			},
+			MaxCount: defaultMaxCount,
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					UndoLater(p.Count),
+					addToMacro{user: true})
+			},
+		},
 		{
 			Name: "enter visual mode charwise (v)",
 			BuildExpr: func() vm.Expr {
@@ -976,6 +1153,39 @@ func NormalModeCommands() []Command {
 					addToMacro{user: true})
 			},
 		},
+		{
+			Name: "reselect last visual selection (gv)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("gv", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					ReselectLastSelection,
+					addToMacro{user: true})
+			},
+		},
+		{
+			Name: "add cursor at next match (ctrl-n)",
+			BuildExpr: func() vm.Expr {
+				return keyExpr(tcell.KeyCtrlN)
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisualReportStatus(
+					AddCursorAtNextMatch,
+					addToMacro{user: true})
+			},
+		},
+		{
+			Name: "remove extra cursors (esc or ctrl-c)",
+			BuildExpr: func() vm.Expr {
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					RemoveExtraCursors,
+					addToMacro{user: true})
+			},
+		},
 		{
 			Name: "repeat last action (.)",
 			BuildExpr: func() vm.Expr {
@@ -989,6 +1199,131 @@ func NormalModeCommands() []Command {
 			},
 		},
 	}...)
+
+	// The input VM prefers commands that appear earlier in the list,
+	// so the commands above take precedence over operator commands with the same keys.
+	// For example, "cw" behaves like "ce" instead of deleting whitespace after the word.
+	return append(commands, operatorCommands()...)
+}
+
+// operator is an edit that can be composed with any motion, such as "d" in "dw".
+type operator struct {
+	name          string
+	key           string
+	lineKey       string // Key after the operator to apply it to lines, if not the operator key repeated.
+	op            state.Operator
+	addToMacro    addToMacro
+	clipboardPage bool
+}
+
+// motion is a cursor movement that can follow an operator, such as "w" in "dw".
+type motion struct {
+	name      string
+	key       string
+	count     bool
+	matchChar bool
+	maxCount  uint64
+	linewise  bool
+	inclusive bool
+	mustMove  bool
+	buildMove func(CommandParams) Action
+}
+
+// operatorCommands compose every operator with every motion.
+func operatorCommands() []Command {
+	operators := []operator{
+		{name: "delete", key: "d", op: state.OperatorDelete, addToMacro: addToMacro{lastAction: true, user: true}, clipboardPage: true},
+		{name: "change", key: "c", op: state.OperatorChange, addToMacro: addToMacro{lastAction: true, user: true}, clipboardPage: true},
+		{name: "yank", key: "y", op: state.OperatorYank, addToMacro: addToMacro{user: true}, clipboardPage: true},
+		{name: "indent", key: ">", op: state.OperatorIndent, addToMacro: addToMacro{lastAction: true, user: true}},
+		{name: "outdent", key: "<", op: state.OperatorOutdent, addToMacro: addToMacro{lastAction: true, user: true}},
+		{name: "format", key: "gq", lineKey: "q", op: state.OperatorFormat, addToMacro: addToMacro{lastAction: true, user: true}},
+	}
+
+	motions := []motion{
+		{name: "left", key: "h", count: true, buildMove: func(p CommandParams) Action { return CursorLeft(p.Count) }},
+		{name: "right", key: "l", count: true, buildMove: func(p CommandParams) Action { return CursorRight(p.Count) }},
+		{name: "up", key: "k", count: true, linewise: true, mustMove: true, buildMove: func(p CommandParams) Action { return CursorUp(p.Count) }},
+		{name: "down", key: "j", count: true, linewise: true, mustMove: true, buildMove: func(p CommandParams) Action { return CursorDown(p.Count) }},
+		{name: "next word start", key: "w", count: true, buildMove: func(p CommandParams) Action { return CursorNextWordStart(p.Count, false) }},
+		{name: "next word start with punctuation", key: "W", count: true, buildMove: func(p CommandParams) Action { return CursorNextWordStart(p.Count, true) }},
+		{name: "prev word start", key: "b", count: true, buildMove: func(p CommandParams) Action { return CursorPrevWordStart(p.Count, false) }},
+		{name: "prev word start with punctuation", key: "B", count: true, buildMove: func(p CommandParams) Action { return CursorPrevWordStart(p.Count, true) }},
+		{name: "next word end", key: "e", count: true, inclusive: true, buildMove: func(p CommandParams) Action { return CursorNextWordEnd(p.Count, false) }},
+		{name: "next word end with punctuation", key: "E", count: true, inclusive: true, buildMove: func(p CommandParams) Action { return CursorNextWordEnd(p.Count, true) }},
+		{name: "prev paragraph", key: "{", buildMove: func(p CommandParams) Action { return CursorPrevParagraph }},
+		{name: "next paragraph", key: "}", buildMove: func(p CommandParams) Action { return CursorNextParagraph }},
+		{name: "to next matching char", key: "f", count: true, matchChar: true, inclusive: true, mustMove: true, buildMove: func(p CommandParams) Action { return CursorToNextMatchingChar(p.MatchChar, p.Count, true) }},
+		{name: "to prev matching char", key: "F", count: true, matchChar: true, buildMove: func(p CommandParams) Action { return CursorToPrevMatchingChar(p.MatchChar, p.Count, true) }},
+		{name: "till next matching char", key: "t", count: true, matchChar: true, inclusive: true, mustMove: true, buildMove: func(p CommandParams) Action { return CursorToNextMatchingChar(p.MatchChar, p.Count, false) }},
+		{name: "till prev matching char", key: "T", count: true, matchChar: true, buildMove: func(p CommandParams) Action { return CursorToPrevMatchingChar(p.MatchChar, p.Count, false) }},
+		{name: "line start", key: "0", buildMove: func(p CommandParams) Action { return CursorLineStart }},
+		{name: "line start non-whitespace", key: "^", buildMove: func(p CommandParams) Action { return CursorLineStartNonWhitespace }},
+		{name: "line end", key: "$", inclusive: true, buildMove: func(p CommandParams) Action { return CursorLineEnd }},
+		{name: "start of line num", key: "gg", count: true, maxCount: math.MaxUint64, linewise: true, buildMove: func(p CommandParams) Action { return CursorStartOfLineNum(p.Count) }},
+		{name: "start of last line", key: "G", linewise: true, buildMove: func(p CommandParams) Action { return CursorStartOfLastLine }},
+		{name: "matching code block delimiter", key: "%", inclusive: true, mustMove: true, buildMove: func(p CommandParams) Action { return CursorMatchingCodeBlockDelimiter }},
+		{name: "prev unmatched open brace", key: "[{", buildMove: func(p CommandParams) Action { return CursorPrevUnmatchedOpenBrace }},
+		{name: "next unmatched close brace", key: "]}", buildMove: func(p CommandParams) Action { return CursorNextUnmatchedCloseBrace }},
+		{name: "prev unmatched open paren", key: "[(", buildMove: func(p CommandParams) Action { return CursorPrevUnmatchedOpenParen }},
+		{name: "next unmatched close paren", key: "])", buildMove: func(p CommandParams) Action { return CursorNextUnmatchedCloseParen }},
+	}
+
+	commands := make([]Command, 0, len(operators)*(len(motions)+1))
+	for _, op := range operators {
+		// Doubling an operator applies it to the current line and the lines below,
+		// such as "dd" to delete the current line or "3yy" to yank three lines.
+		lineKey := op.key
+		if op.lineKey != "" {
+			lineKey = op.lineKey
+		}
+		lineMotion := motion{
+			name:     "line",
+			key:      lineKey,
+			count:    true,
+			linewise: true,
+			buildMove: func(p CommandParams) Action {
+				return CursorDown(p.Count - 1)
+			},
+		}
+
+		for _, m := range append([]motion{lineMotion}, motions...) {
+			op, m := op, m
+			keys := op.key + m.key
+			if m.matchChar {
+				keys += "{char}"
+			}
+
+			maxCount := m.maxCount
+			if m.count && maxCount == 0 {
+				maxCount = defaultMaxCount
+			}
+
+			commands = append(commands, Command{
+				Name: fmt.Sprintf("%s %s (%s)", op.name, m.name, keys),
+				BuildExpr: func() vm.Expr {
+					return cmdExpr(op.key, m.key, captureOpts{
+						count:         m.count,
+						clipboardPage: op.clipboardPage,
+						matchChar:     m.matchChar,
+					})
+				},
+				MaxCount: maxCount,
+				BuildAction: func(ctx Context, p CommandParams) Action {
+					motion := state.Motion{
+						Move:      m.buildMove(p),
+						Linewise:  m.linewise,
+						Inclusive: m.inclusive,
+						MustMove:  m.mustMove,
+					}
+					return decorateNormalOrVisual(
+						ApplyOperator(op.op, motion, p.ClipboardPage),
+						op.addToMacro)
+				},
+			})
+		}
+	}
+	return commands
 }
 
 func VisualModeCommands() []Command {
@@ -1016,9 +1351,20 @@ func VisualModeCommands() []Command {
 			},
 		},
 		{
-			Name: "return to normal mode (esc)",
+			Name: "swap selection ends (o)",
+			BuildExpr: func() vm.Expr {
+				return altExpr(runeExpr('o'), runeExpr('O'))
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					SwapSelectionEnds,
+					addToMacro{user: true})
+			},
+		},
+		{
+			Name: "return to normal mode (esc or ctrl-c)",
 			BuildExpr: func() vm.Expr {
-				return keyExpr(tcell.KeyEscape)
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorateNormalOrVisual(
@@ -1091,6 +1437,17 @@ func VisualModeCommands() []Command {
 					addToMacro{lastAction: true, user: true})
 			},
 		},
+		{
+			Name: "format selection (gq)",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("gq", "", captureOpts{})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					FormatSelectionAndReturnToNormalMode(ctx.SelectionEndLocator),
+					addToMacro{lastAction: true, user: true})
+			},
+		},
 		{
 			Name: "outdent selection (<)",
 			BuildExpr: func() vm.Expr {
@@ -1103,6 +1460,17 @@ func VisualModeCommands() []Command {
 					addToMacro{lastAction: true, user: true})
 			},
 		},
+		{
+			Name: "align selection on char (ga{char})",
+			BuildExpr: func() vm.Expr {
+				return cmdExpr("ga", "", captureOpts{matchChar: true})
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorateNormalOrVisual(
+					AlignSelectionAndReturnToNormalMode(ctx.SelectionEndLocator, p.MatchChar),
+					addToMacro{lastAction: true, user: true})
+			},
+		},
 		{
 			Name: "yank selection (y)",
 			BuildExpr: func() vm.Expr {
@@ -1122,6 +1490,7 @@ func InsertModeCommands() []Command {
 		return func(s *state.EditorState) {
 			wrappedAction := func(s *state.EditorState) {
 				action(s)
+				state.AddToInsertRepeat(s, state.MacroAction(action))
 				state.ScrollViewToCursor(s)
 			}
 			wrappedAction(s)
@@ -1137,7 +1506,25 @@ func InsertModeCommands() []Command {
 				return insertExpr
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(InsertRune(p.InsertChar))
+				return decorate(AtAllCursors(InsertRune(p.InsertChar)))
+			},
+		},
+		{
+			Name: "insert rune literally (ctrl-v{char})",
+			BuildExpr: func() vm.Expr {
+				return insertLiteralExpr
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(InsertRuneLiteral(p.InsertChar)))
+			},
+		},
+		{
+			Name: "insert code point (ctrl-v{digits})",
+			BuildExpr: func() vm.Expr {
+				return insertCodePointExpr
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(InsertCodePoint(p.CodePoint)))
 			},
 		},
 		{
@@ -1146,7 +1533,52 @@ func InsertModeCommands() []Command {
 				return altExpr(keyExpr(tcell.KeyBackspace), keyExpr(tcell.KeyBackspace2))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(DeletePrevChar(clipboard.PageNull))
+				return decorate(AtAllCursors(DeletePrevChar(clipboard.PageNull)))
+			},
+		},
+		{
+			Name: "insert from clipboard (ctrl-r{register})",
+			BuildExpr: func() vm.Expr {
+				return insertClipboardPageExpr
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(InsertFromClipboard(p.ClipboardPage)))
+			},
+		},
+		{
+			Name: "insert last inserted text (ctrl-a)",
+			BuildExpr: func() vm.Expr {
+				return keyExpr(tcell.KeyCtrlA)
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(InsertFromClipboard(clipboard.PageLastInserted)))
+			},
+		},
+		{
+			Name: "insert digraph (ctrl-k{char}{char})",
+			BuildExpr: func() vm.Expr {
+				return digraphExpr
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(InsertDigraph(p.Digraph)))
+			},
+		},
+		{
+			Name: "delete prev word (ctrl-w)",
+			BuildExpr: func() vm.Expr {
+				return keyExpr(tcell.KeyCtrlW)
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(DeletePrevWordInLine(clipboard.PageNull)))
+			},
+		},
+		{
+			Name: "delete to start of line (ctrl-u)",
+			BuildExpr: func() vm.Expr {
+				return keyExpr(tcell.KeyCtrlU)
+			},
+			BuildAction: func(ctx Context, p CommandParams) Action {
+				return decorate(AtAllCursors(DeleteToStartOfLineOrIndent(clipboard.PageNull)))
 			},
 		},
 		{
@@ -1155,7 +1587,7 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyEnter)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(InsertNewlineAndUpdateAutoIndentWhitespace)
+				return decorate(AtAllCursors(InsertNewlineAndUpdateAutoIndentWhitespace))
 			},
 		},
 		{
@@ -1164,7 +1596,7 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyTab)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(InsertTab)
+				return decorate(AtAllCursors(InsertTab))
 			},
 		},
 		{
@@ -1173,7 +1605,7 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyLeft)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorLeft(1))
+				return decorate(insertArrowKeyAction(ctx, CursorLeft(1), CursorLeft(1)))
 			},
 		},
 		{
@@ -1182,7 +1614,7 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyRight)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorRightIncludeEndOfLineOrFile)
+				return decorate(insertArrowKeyAction(ctx, CursorRightIncludeEndOfLineOrFile, CursorRight(1)))
 			},
 		},
 		{
@@ -1191,7 +1623,7 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyUp)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorUp(1))
+				return decorate(insertArrowKeyAction(ctx, CursorUp(1), CursorUp(1)))
 			},
 		},
 		{
@@ -1200,13 +1632,13 @@ func InsertModeCommands() []Command {
 				return keyExpr(tcell.KeyDown)
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
-				return decorate(CursorDown(1))
+				return decorate(insertArrowKeyAction(ctx, CursorDown(1), CursorDown(1)))
 			},
 		},
 		{
 			Name: "escape to normal mode",
 			BuildExpr: func() vm.Expr {
-				return keyExpr(tcell.KeyEscape)
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorate(ReturnToNormalModeAfterInsert)
@@ -1215,12 +1647,28 @@ func InsertModeCommands() []Command {
 	}
 }
 
+// insertArrowKeyAction returns the action for an arrow key in insert mode,
+// depending on the configured behavior for arrow keys.
+func insertArrowKeyAction(ctx Context, insertModeAction Action, normalModeAction Action) Action {
+	switch ctx.InsertArrowKeys {
+	case config.InsertArrowKeysIgnore:
+		return EmptyAction
+	case config.InsertArrowKeysNormal:
+		return func(s *state.EditorState) {
+			ReturnToNormalModeAfterInsert(s)
+			normalModeAction(s)
+		}
+	default:
+		return insertModeAction
+	}
+}
+
 func MenuModeCommands() []Command {
 	return []Command{
 		{
 			Name: "escape to normal mode",
 			BuildExpr: func() vm.Expr {
-				return keyExpr(tcell.KeyEscape)
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return HideMenuAndReturnToNormalMode
@@ -1286,7 +1734,7 @@ func SearchModeCommands() []Command {
 		{
 			Name: "escape to normal mode",
 			BuildExpr: func() vm.Expr {
-				return keyExpr(tcell.KeyEscape)
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return decorate(AbortSearchAndReturnToNormalMode)
@@ -1328,7 +1776,7 @@ func TaskModeCommands() []Command {
 		{
 			Name: "cancel task",
 			BuildExpr: func() vm.Expr {
-				return keyExpr(tcell.KeyEscape)
+				return altExpr(keyExpr(tcell.KeyEscape), keyExpr(tcell.KeyCtrlC))
 			},
 			BuildAction: func(ctx Context, p CommandParams) Action {
 				return state.CancelTaskIfRunning
@@ -3,6 +3,7 @@ package input
 import (
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/state"
+	"github.com/aretext/aretext/syntax"
 )
 
 // Context influences how user input is interpreted.
@@ -10,13 +11,21 @@ type Context struct {
 	// InputMode is the current input mode of the editor.
 	InputMode state.InputMode
 
-	// ScrollLines is the number of lines to scroll up or down with Ctrl-F / Ctrl-N.
+	// ScrollLines is the number of lines to scroll up or down with Ctrl-F / Ctrl-B.
+	// This is the height of the document view, excluding the status bar.
 	// Ctrl-U / Ctrl-D scroll for half of that amount.
 	ScrollLines uint64
 
 	// Glob patterns for directories to hide from file search.
 	DirPatternsToHide []string
 
+	// InsertArrowKeys controls how arrow keys behave in insert mode.
+	InsertArrowKeys string
+
+	// SyntaxLanguage is the language of the current document,
+	// which determines whether language-specific menu commands are available.
+	SyntaxLanguage syntax.Language
+
 	// Information about the current selection (visual mode).
 	// If not in visual mode, the mode will be selection.ModeNone
 	// and the end locator will be nil.
@@ -25,12 +34,14 @@ type Context struct {
 }
 
 func ContextFromEditorState(editorState *state.EditorState) Context {
-	_, screenHeight := editorState.ScreenSize()
-	scrollLines := uint64(screenHeight)
+	_, viewHeight := editorState.DocumentBuffer().ViewSize()
+	scrollLines := uint64(viewHeight)
 	return Context{
 		InputMode:           editorState.InputMode(),
 		ScrollLines:         scrollLines,
 		DirPatternsToHide:   editorState.DirPatternsToHide(),
+		InsertArrowKeys:     editorState.InsertArrowKeys(),
+		SyntaxLanguage:      editorState.DocumentBuffer().SyntaxLanguage(),
 		SelectionMode:       editorState.DocumentBuffer().SelectionMode(),
 		SelectionEndLocator: editorState.DocumentBuffer().SelectionEndLocator(),
 	}
@@ -42,32 +42,36 @@ const (
 	captureIdMatchChar
 	captureIdReplaceChar
 	captureIdInsertChar
+	captureIdDigraph
+	captureIdLiteralChar
+	captureIdCodePoint
+	captureIdLookahead // Ends a command without being part of it, so the mode processes the event again.
 )
 
 // Pre-compute and share these expressions to reduce number of allocations.
-var verbCountExpr, objectCountExpr, clipboardPageExpr, matchCharExpr, replaceCharExpr, insertExpr vm.Expr
+var verbCountExpr, requiredVerbCountExpr, objectCountExpr, clipboardPageExpr, insertClipboardPageExpr, digraphExpr, matchCharExpr, replaceCharExpr, insertExpr, insertLiteralExpr, insertCodePointExpr vm.Expr
 
 func init() {
-	verbCountExpr = vm.OptionExpr{
-		Child: vm.CaptureExpr{
-			CaptureId: captureIdVerbCount,
-			Child: vm.ConcatExpr{
-				Children: []vm.Expr{
-					vm.EventRangeExpr{
-						StartEvent: runeToVmEvent('1'),
+	requiredVerbCountExpr = vm.CaptureExpr{
+		CaptureId: captureIdVerbCount,
+		Child: vm.ConcatExpr{
+			Children: []vm.Expr{
+				vm.EventRangeExpr{
+					StartEvent: runeToVmEvent('1'),
+					EndEvent:   runeToVmEvent('9'),
+				},
+				vm.StarExpr{
+					Child: vm.EventRangeExpr{
+						StartEvent: runeToVmEvent('0'),
 						EndEvent:   runeToVmEvent('9'),
 					},
-					vm.StarExpr{
-						Child: vm.EventRangeExpr{
-							StartEvent: runeToVmEvent('0'),
-							EndEvent:   runeToVmEvent('9'),
-						},
-					},
 				},
 			},
 		},
 	}
 
+	verbCountExpr = vm.OptionExpr{Child: requiredVerbCountExpr}
+
 	objectCountExpr = vm.OptionExpr{
 		Child: vm.CaptureExpr{
 			CaptureId: captureIdObjectCount,
@@ -105,6 +109,42 @@ func init() {
 		},
 	}
 
+	// In insert mode, ctrl-r accepts any rune as a clipboard page.
+	// Runes that do not name a page resolve to the null page, which is empty.
+	insertClipboardPageExpr = vm.ConcatExpr{
+		Children: []vm.Expr{
+			keyExpr(tcell.KeyCtrlR),
+			vm.CaptureExpr{
+				CaptureId: captureIdClipboardPage,
+				Child: vm.EventRangeExpr{
+					StartEvent: runeToVmEvent(rune(0)),
+					EndEvent:   runeToVmEvent(utf8.MaxRune),
+				},
+			},
+		},
+	}
+
+	digraphExpr = vm.ConcatExpr{
+		Children: []vm.Expr{
+			keyExpr(tcell.KeyCtrlK),
+			vm.CaptureExpr{
+				CaptureId: captureIdDigraph,
+				Child: vm.ConcatExpr{
+					Children: []vm.Expr{
+						vm.EventRangeExpr{
+							StartEvent: runeToVmEvent(rune(0)),
+							EndEvent:   runeToVmEvent(utf8.MaxRune),
+						},
+						vm.EventRangeExpr{
+							StartEvent: runeToVmEvent(rune(0)),
+							EndEvent:   runeToVmEvent(utf8.MaxRune),
+						},
+					},
+				},
+			},
+		},
+	}
+
 	matchCharExpr = vm.CaptureExpr{
 		CaptureId: captureIdMatchChar,
 		Child: vm.EventRangeExpr{
@@ -138,10 +178,106 @@ func init() {
 			EndEvent:   runeToVmEvent(utf8.MaxRune),
 		},
 	}
+
+	// In insert mode, ctrl-v inserts the next rune without expanding an abbreviation,
+	// or the next control key, such as tab, as a control character.
+	// Digits, "u", and "U" start a code point instead.
+	insertLiteralExpr = vm.ConcatExpr{
+		Children: []vm.Expr{
+			keyExpr(tcell.KeyCtrlV),
+			vm.CaptureExpr{
+				CaptureId: captureIdLiteralChar,
+				Child: altExpr(
+					runeRangeExpr(rune(0), '0'-1),
+					runeRangeExpr('9'+1, 'U'-1),
+					runeRangeExpr('U'+1, 'u'-1),
+					runeRangeExpr('u'+1, utf8.MaxRune),
+					vm.EventRangeExpr{
+						StartEvent: keyToVmEvent(tcell.KeyNUL),
+						EndEvent:   keyToVmEvent(tcell.KeyUS),
+					},
+				),
+			},
+		},
+	}
+
+	// In insert mode, ctrl-v inserts a character by its code point:
+	// up to three decimal digits, "u" and up to four hex digits, or "U" and up to eight hex digits.
+	// A code with fewer digits ends at the next key that is not a digit, which is then handled as usual.
+	nonRuneKeyExpr := altExpr(
+		vm.EventRangeExpr{
+			StartEvent: keyToVmEvent(tcell.KeyNUL),
+			EndEvent:   keyToVmEvent(tcell.KeyRune - 1),
+		},
+		vm.EventRangeExpr{
+			StartEvent: keyToVmEvent(tcell.KeyRune + 1),
+			EndEvent:   keyToVmEvent(tcell.KeyF64),
+		},
+	)
+	decimalDigitExpr := runeRangeExpr('0', '9')
+	nonDecimalDigitExpr := altExpr(
+		runeRangeExpr(rune(0), '0'-1),
+		runeRangeExpr('9'+1, utf8.MaxRune),
+		nonRuneKeyExpr,
+	)
+	hexDigitExpr := altExpr(
+		runeRangeExpr('0', '9'),
+		runeRangeExpr('A', 'F'),
+		runeRangeExpr('a', 'f'),
+	)
+	nonHexDigitExpr := altExpr(
+		runeRangeExpr(rune(0), '0'-1),
+		runeRangeExpr('9'+1, 'A'-1),
+		runeRangeExpr('F'+1, 'a'-1),
+		runeRangeExpr('f'+1, utf8.MaxRune),
+		nonRuneKeyExpr,
+	)
+	insertCodePointExpr = vm.ConcatExpr{
+		Children: []vm.Expr{
+			keyExpr(tcell.KeyCtrlV),
+			altExpr(
+				codePointExpr(nil, decimalDigitExpr, nonDecimalDigitExpr, 3),
+				codePointExpr(runeExpr('u'), hexDigitExpr, nonHexDigitExpr, 4),
+				codePointExpr(runeExpr('U'), hexDigitExpr, nonHexDigitExpr, 8),
+			),
+		},
+	}
+}
+
+// codePointExpr matches an optional prefix followed by one to maxDigits digits.
+// If there are fewer than maxDigits digits, a key that is not a digit must end the code.
+func codePointExpr(prefixExpr vm.Expr, digitExpr vm.Expr, nonDigitExpr vm.Expr, maxDigits int) vm.Expr {
+	alternatives := make([]vm.Expr, 0, maxDigits)
+	for numDigits := 1; numDigits <= maxDigits; numDigits++ {
+		code := vm.ConcatExpr{Children: make([]vm.Expr, 0, numDigits+1)}
+		if prefixExpr != nil {
+			code.Children = append(code.Children, prefixExpr)
+		}
+		for i := 0; i < numDigits; i++ {
+			code.Children = append(code.Children, digitExpr)
+		}
+
+		expr := vm.ConcatExpr{
+			Children: []vm.Expr{vm.CaptureExpr{CaptureId: captureIdCodePoint, Child: code}},
+		}
+		if numDigits < maxDigits {
+			expr.Children = append(expr.Children, vm.CaptureExpr{CaptureId: captureIdLookahead, Child: nonDigitExpr})
+		}
+		alternatives = append(alternatives, expr)
+	}
+	return altExpr(alternatives...)
+}
+
+func runeRangeExpr(start rune, end rune) vm.Expr {
+	return vm.EventRangeExpr{
+		StartEvent: runeToVmEvent(start),
+		EndEvent:   runeToVmEvent(end),
+	}
 }
 
 type captureOpts struct {
 	count         bool
+	requiredCount bool // Match only if a count is provided, so the same keys without a count can be another command.
 	clipboardPage bool
 	matchChar     bool
 	replaceChar   bool
@@ -187,7 +323,9 @@ func cmdExpr(verb string, object string, opts captureOpts) vm.Expr {
 		expr = vm.ConcatExpr{Children: []vm.Expr{verbExpr, objExpr}}
 	}
 
-	if opts.count {
+	if opts.requiredCount {
+		expr = vm.ConcatExpr{Children: []vm.Expr{requiredVerbCountExpr, expr}}
+	} else if opts.count {
 		expr = vm.ConcatExpr{Children: []vm.Expr{verbCountExpr, expr}}
 	}
 
@@ -230,6 +368,12 @@ func capturesToCommandParams(captures []vm.Capture, events []vm.Event) CommandPa
 			p.ReplaceChar = eventsToReplaceChar(captureEvents)
 		case captureIdInsertChar:
 			p.InsertChar = eventsToChar(captureEvents)
+		case captureIdDigraph:
+			p.Digraph = eventsToDigraph(captureEvents)
+		case captureIdLiteralChar:
+			p.InsertChar = eventsToLiteralChar(captureEvents)
+		case captureIdCodePoint:
+			p.CodePoint = eventsToCodePoint(captureEvents)
 		}
 	}
 	return p
@@ -251,7 +395,15 @@ func eventsToClipboardPage(events []vm.Event) clipboard.PageId {
 	if len(events) != 1 {
 		return clipboard.PageNull
 	}
-	return clipboard.PageIdForLetter(vmEventToRune(events[0]))
+
+	r := vmEventToRune(events[0])
+	if r == '"' {
+		// Vim calls this the "unnamed" register.
+		return clipboard.PageDefault
+	} else if r == '.' {
+		return clipboard.PageLastInserted
+	}
+	return clipboard.PageIdForLetter(r)
 }
 
 func eventsToChar(events []vm.Event) rune {
@@ -261,6 +413,45 @@ func eventsToChar(events []vm.Event) rune {
 	return vmEventToRune(events[0])
 }
 
+func eventsToDigraph(events []vm.Event) [2]rune {
+	if len(events) != 2 {
+		return [2]rune{}
+	}
+	return [2]rune{vmEventToRune(events[0]), vmEventToRune(events[1])}
+}
+
+// eventsToLiteralChar converts a rune or a control key, such as tab or ctrl-a, to a character.
+func eventsToLiteralChar(events []vm.Event) rune {
+	if len(events) != 1 {
+		return '\x00'
+	}
+
+	if key := vmEventToKey(events[0]); key != tcell.KeyRune {
+		return rune(key) // Control keys have the same value as the control character.
+	}
+	return vmEventToRune(events[0])
+}
+
+// eventsToCodePoint converts decimal digits, or "u" or "U" followed by hex digits, to a code point.
+// This returns utf8.RuneError if the code is not a valid code point.
+func eventsToCodePoint(events []vm.Event) rune {
+	var sb strings.Builder
+	for _, e := range events {
+		sb.WriteRune(vmEventToRune(e))
+	}
+
+	s, base := sb.String(), 10
+	if strings.HasPrefix(s, "u") || strings.HasPrefix(s, "U") {
+		s, base = s[1:], 16
+	}
+
+	n, err := strconv.ParseUint(s, base, 32)
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return utf8.RuneError
+	}
+	return rune(n)
+}
+
 func eventsToReplaceChar(events []vm.Event) rune {
 	if len(events) != 1 {
 		return '\x00'
@@ -12,9 +12,10 @@ import (
 	"github.com/aretext/aretext/state"
 )
 
-// Interpreter translates key events to commands.
+// Interpreter translates key and mouse events to commands.
 type Interpreter struct {
 	modes map[state.InputMode]*mode
+	mouse mouseState
 }
 
 // NewInterpreter creates a new interpreter.
@@ -73,6 +74,8 @@ func (inp *Interpreter) ProcessEvent(event tcell.Event, ctx Context) Action {
 	switch event := event.(type) {
 	case *tcell.EventKey:
 		return inp.processKeyEvent(event, ctx)
+	case *tcell.EventMouse:
+		return inp.processMouseEvent(event, ctx)
 	case *tcell.EventResize:
 		return inp.processResizeEvent(event)
 	default:
@@ -147,13 +150,17 @@ type mode struct {
 }
 
 func (m *mode) ProcessKeyEvent(event *tcell.EventKey, ctx Context) Action {
-	vmEvent := eventKeyToVmEvent(event)
-	m.eventBuffer = append(m.eventBuffer, vmEvent)
 	if event.Key() == tcell.KeyRune {
 		m.inputBuffer.WriteRune(event.Rune())
 	}
+	return m.processVmEvent(eventKeyToVmEvent(event), ctx)
+}
+
+func (m *mode) processVmEvent(vmEvent vm.Event, ctx Context) Action {
+	m.eventBuffer = append(m.eventBuffer, vmEvent)
 
 	action := EmptyAction
+	lookahead, hasLookahead := vm.Event(0), false
 	result := m.runtime.ProcessEvent(vmEvent)
 	if result.Accepted {
 		for _, capture := range result.Captures {
@@ -182,6 +189,12 @@ func (m *mode) ProcessKeyEvent(event *tcell.EventKey, ctx Context) Action {
 				break
 			}
 		}
+
+		for _, capture := range result.Captures {
+			if capture.Id == captureIdLookahead {
+				lookahead, hasLookahead = m.eventBuffer[capture.StartIdx], true
+			}
+		}
 	}
 
 	if result.Reset {
@@ -189,6 +202,19 @@ func (m *mode) ProcessKeyEvent(event *tcell.EventKey, ctx Context) Action {
 		m.inputBuffer.Reset()
 	}
 
+	if hasLookahead {
+		// The event that ended the command might start the next command,
+		// so process it again after the runtime has reset.
+		if vmEventToKey(lookahead) == tcell.KeyRune {
+			m.inputBuffer.WriteRune(vmEventToRune(lookahead))
+		}
+		commandAction, nextAction := action, m.processVmEvent(lookahead, ctx)
+		action = func(s *state.EditorState) {
+			commandAction(s)
+			nextAction(s)
+		}
+	}
+
 	return action
 }
 
@@ -4,13 +4,16 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/input/vm"
+	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/state"
 )
 
@@ -178,6 +181,112 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedCursorPos: 11,
 			expectedText:      "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
 		},
+		{
+			name:        "cursor till next matching in line, then repeat",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor till next matching in line, then repeat once",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor till next matching in line, then repeat twice",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor till next matching in line, then repeat past last match",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor till next matching in line, then repeat in opposite direction",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ',', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor to next matching in line, then repeat",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor to next matching in line, then repeat with count",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 5,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "cursor till prev matching in line, then repeat",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'T', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a.b.c.d",
+		},
+		{
+			name:        "delete till next matching in line, then repeat",
+			initialText: "a.b.c.d",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ';', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      ".b.c.d",
+		},
 		{
 			name:        "cursor till prev matching in line",
 			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
@@ -507,6 +616,133 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedCursorPos: 2,
 			expectedText:      "foo",
 		},
+		{
+			name:        "insert then delete grapheme cluster with combining marks",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '\u0301', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '\u0302', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '\u0323', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyBackspace2, '\u007f', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "x",
+		},
+		{
+			name:        "insert with count",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 8,
+			expectedText:      "foofoofoo",
+		},
+		{
+			name:        "append at end of line with count",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 5,
+			expectedText:      "abxyxy\ncd",
+		},
+		{
+			name:        "insert at start of line with count",
+			initialText: "  ab",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'I', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "  --ab",
+		},
+		{
+			name:        "begin new line below with count",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 13,
+			expectedText:      "ab\nfoo\nfoo\nfoo\ncd",
+		},
+		{
+			name:        "begin new line above with count",
+			initialText: "ab",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "foo\nfoo\nab",
+		},
+		{
+			name:        "insert with count, newline and backspace",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyBackspace2, '\u007f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 7,
+			expectedText:      "ab\ncab\nc",
+		},
+		{
+			name:        "insert with count, then undo",
+			initialText: "ab",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab",
+		},
+		{
+			name:        "insert with count, then replay last action",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "xxxx",
+		},
 		{
 			name:        "insert at start of line",
 			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
@@ -1730,6 +1966,43 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedCursorPos: 0,
 			expectedText:      "dolor\nsit amet consectetur\nadipiscing elit",
 		},
+		{
+			name:        "ctrl-c cancels pending count and operator",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlC, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "Lorem ipsum dolor",
+		},
+		{
+			name:        "ctrl-c in insert mode returns to normal mode",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlC, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "Lorem ipsum dolor",
+		},
+		{
+			name:        "ctrl-c in visual mode returns to normal mode",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlC, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "Loem ipsum dolor",
+		},
 		{
 			name:        "visual linewise delete",
 			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
@@ -1752,6 +2025,79 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedCursorPos: 0,
 			expectedText:      "",
 		},
+		{
+			name:        "visual reselect after delete",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ipsum dolor",
+		},
+		{
+			name:        "visual reselect after yank",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "Lorem  dolor",
+		},
+		{
+			name:        "visual swap selection ends and extend",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 5,
+			expectedText:      "Lorem dolor",
+		},
+		{
+			name:        "visual swap selection ends and shrink",
+			initialText: "Lorem ipsum dolor",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 7,
+			expectedText:      "Lorem i dolor",
+		},
+		{
+			name:        "visual linewise swap selection ends with O",
+			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
 		{
 			name:        "visual charwise delete",
 			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
@@ -1847,20 +2193,93 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedText:      "Lorem ipsum dolor\n\t\tsit amet consectetur\n\t\tadipiscing elit",
 		},
 		{
-			name:        "visual mode outdent",
-			initialText: "Lorem ipsum dolor\n\tsit amet consectetur\n\t\tadipiscing\nelit\n\n",
+			name:        "visual linewise delete, then repeat last action",
+			initialText: "a\nb\nc\nd\ne\nf\ng",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
 			},
-			expectedCursorPos: 18,
-			expectedText:      "Lorem ipsum dolor\nsit amet consectetur\n\tadipiscing\nelit\n\n",
+			expectedCursorPos: 0,
+			expectedText:      "d",
 		},
 		{
-			name:        "visual mode outdent with count",
-			initialText: "Lorem ipsum dolor\n\t\tsit amet consectetur\n\t\t\tadipiscing\nelit\n\n",
+			name:        "visual charwise delete, then repeat last action",
+			initialText: "Lorem ipsum dolor sit amet",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "em um dolor sit amet",
+		},
+		{
+			name:        "visual charwise delete across lines, then repeat last action",
+			initialText: "abcd\nefgh\nijkl\nmnop\nqrst",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 5,
+			expectedText:      "agh\niop\nqrst",
+		},
+		{
+			name:        "visual change, then repeat last action",
+			initialText: "Lorem ipsum dolor sit amet",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 10,
+			expectedText:      "Xem ipsum Xor sit amet",
+		},
+		{
+			name:        "visual toggle case, then repeat last action",
+			initialText: "Lorem ipsum dolor sit amet",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '~', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "lORem IPSum dolor sit amet",
+		},
+		{
+			name:        "visual mode outdent",
+			initialText: "Lorem ipsum dolor\n\tsit amet consectetur\n\t\tadipiscing\nelit\n\n",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+			},
+			expectedCursorPos: 18,
+			expectedText:      "Lorem ipsum dolor\nsit amet consectetur\n\tadipiscing\nelit\n\n",
+		},
+		{
+			name:        "visual mode outdent with count",
+			initialText: "Lorem ipsum dolor\n\t\tsit amet consectetur\n\t\t\tadipiscing\nelit\n\n",
 			events: []tcell.Event{
 				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
@@ -1941,201 +2360,2446 @@ func TestInterpreterStateIntegration(t *testing.T) {
 			expectedText:      "lOREM ipsum dolor\nsit amet consectetur\nadipiscing elit",
 		},
 		{
-			name:        "visual mode, then replay last action",
-			initialText: "",
+			name:        "visual linewise, align on char",
+			initialText: "a = 1\nbbb = 2\ncc = 3\nd = 4",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "a   = 1\nbbb = 2\ncc  = 3\nd = 4",
+		},
+		{
+			name:        "visual linewise, align on char, then undo",
+			initialText: "a = 1\nbbb = 2\ncc = 3\nd = 4",
+			events: []tcell.Event{
 				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
 			},
 			expectedCursorPos: 2,
-			expectedText:      "a\nb\nc\nd",
+			expectedText:      "a = 1\nbbb = 2\ncc = 3\nd = 4",
 		},
 		{
-			name:        "edit text, enter and exit visual mode, then replay last action",
-			initialText: "abc",
+			name:        "insert mode paste from default clipboard page",
+			initialText: "foo bar",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, '"', tcell.ModNone),
+			},
+			expectedCursorPos: 12,
+			expectedText:      "foo bar foo ",
+		},
+		{
+			name:        "insert mode paste from named clipboard page",
+			initialText: "foo\nbar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '"', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+			},
+			expectedCursorPos: 11,
+			expectedText:      "foo\nbarfoo\n",
+		},
+		{
+			name:        "insert mode paste from empty clipboard page",
+			initialText: "foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
 			},
-			expectedCursorPos: 0,
-			expectedText:      "xxabc",
+			expectedCursorPos: 4,
+			expectedText:      "foox",
 		},
 		{
-			name:        "visual mode escape to normal mode",
-			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
+			name:        "insert mode paste from nonexistent clipboard page",
+			initialText: "foo",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "foox",
+		},
+		{
+			name:        "insert mode paste then undo",
+			initialText: "foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, '"', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "foo bar",
+		},
+		{
+			name:        "insert mode ctrl-a inserts last inserted text",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
 			},
-			expectedCursorPos: 21,
-			expectedText:      "Lorem ipsum dolor\ntest\nsit amet consectetur\nadipiscing elit",
+			expectedCursorPos: 6,
+			expectedText:      "foofoo",
 		},
 		{
-			name:        "record and replay user macro",
-			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
+			name:        "insert mode ctrl-a with newlines",
+			initialText: "x",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
+			},
+			expectedCursorPos: 17,
+			expectedText:      "x\nfoo\nbar foo\nbar",
+		},
+		{
+			name:        "insert mode ctrl-a after backspace",
+			initialText: "",
+			events: []tcell.Event{
 				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '{', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyBackspace, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, ',', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '^', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "foofoo",
+		},
+		{
+			name:        "insert mode ctrl-a after insert with count",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
 			},
-			expectedCursorPos: 45,
-			expectedText:      "{Lorem ipsum dolor},\n{sit amet consectetur},\n{adipiscing elit},",
+			expectedCursorPos: 12,
+			expectedText:      "foofoofoofoo",
 		},
 		{
-			name:        "record and replay user macro with text search",
-			initialText: "foo bar baz bat",
+			name:        "insert mode ctrl-a uses most recent insert",
+			initialText: "",
 			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
 				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
 			},
-			expectedCursorPos: 7,
-			expectedText:      "foo ar az bat",
+			expectedCursorPos: 9,
+			expectedText:      "foobarbar",
 		},
-	}
+		{
+			name:        "insert mode ctrl-a before any insert",
+			initialText: "foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlA, '\x01', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "foox",
+		},
+		{
+			name:        "insert mode paste from last inserted clipboard page",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "foofoo",
+		},
+		{
+			name:        "insert mode digraph",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlK, '\x0b', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "x→y",
+		},
+		{
+			name:        "insert mode unknown digraph",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlK, '\x0b', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "x",
+		},
+		{
+			name:        "insert mode digraph, then replay last action",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlK, '\x0b', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '\'', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "éé",
+		},
+		{
+			name:        "operator delete to next word end",
+			initialText: "foo bar baz",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "foo  baz",
+		},
+		{
+			name:        "operator delete with count to next word end",
+			initialText: "foo bar baz qux",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      " baz qux",
+		},
+		{
+			name:        "operator delete to next paragraph",
+			initialText: "ab\ncd\n\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "\nef",
+		},
+		{
+			name:        "operator delete to start of last line",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab",
+		},
+		{
+			name:        "operator delete to start of first line",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ef",
+		},
+		{
+			name:        "operator delete to matching code block delimiter",
+			initialText: "a(b)c",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "ac",
+		},
+		{
+			name:        "operator change to line end from last char in line",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "abX",
+		},
+		{
+			name:        "operator yank to line end from last char in line, then put",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "abcc",
+		},
+		{
+			name:        "operator change down on last line fails",
+			initialText: "a\nb\nc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a\nb\nc",
+		},
+		{
+			name:        "operator yank down on last line fails",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "ab\ncd\naef",
+		},
+		{
+			name:        "operator delete down on last line fails",
+			initialText: "a\nb\nc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a\nb\nc",
+		},
+		{
+			name:        "operator delete to next matching char with no match fails",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "abc",
+		},
+		{
+			name:        "operator delete left with count",
+			initialText: "abcd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "ad",
+		},
+		{
+			name:        "operator delete to next word end, then repeat last action",
+			initialText: "foo bar baz",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      " baz",
+		},
+		{
+			name:        "operator delete to start of last line, then undo",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd",
+		},
+		{
+			name:        "operator change down",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "xy\nef",
+		},
+		{
+			name:        "operator change to line end",
+			initialText: "ab cd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "ab x",
+		},
+		{
+			name:        "operator yank down, then put",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "ab\ncd\nab\ncd",
+		},
+		{
+			name:        "operator yank to line end, then put",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "abcbc",
+		},
+		{
+			name:        "operator yank till next matching char, then put",
+			initialText: "abcxd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "abcabcxd",
+		},
+		{
+			name:        "operator indent down",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "\tab\n\tcd\nef",
+		},
+		{
+			name:        "operator outdent up",
+			initialText: "\tab\n\tcd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd",
+		},
+		{
+			name:        "delete lines with count",
+			initialText: "ab\ncd\nef\ngh",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab",
+		},
+		{
+			name:        "delete lines with count past end of document",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '5', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab",
+		},
+		{
+			name:        "delete last line",
+			initialText: "ab\ncd\n  ef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "ab\ncd",
+		},
+		{
+			name:        "delete lines with count, then undo",
+			initialText: "ab\ncd\nef\ngh",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd\nef\ngh",
+		},
+		{
+			name:        "yank lines with count, then put",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			},
+			expectedCursorPos: 9,
+			expectedText:      "ab\ncd\nef\nab\ncd",
+		},
+		{
+			name:        "yank last lines with count past end of document, then put",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			},
+			expectedCursorPos: 6,
+			expectedText:      "ab\ncd\ncd",
+		},
+		{
+			name:        "change line preserves indentation",
+			initialText: "ab\n    cd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 8,
+			expectedText:      "ab\n    xy\nef",
+		},
+		{
+			name:        "change lines with count",
+			initialText: "ab\n\tcd\nef\ngh",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 5,
+			expectedText:      "ab\n\txy\ngh",
+		},
+		{
+			name:        "change last line",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "ab\nxy",
+		},
+		{
+			name:        "change lines with count, then undo",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'y', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd\nef",
+		},
+		{
+			name:        "indent lines with count, then undo",
+			initialText: "ab\ncd\nef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd\nef",
+		},
+		{
+			name:        "operator delete to next paragraph from middle of line",
+			initialText: "ab cd\nef\n\ngh",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "ab \n\ngh",
+		},
+		{
+			name:        "operator delete to next paragraph from start of line, then put",
+			initialText: "ab cd\nef\n\ngh",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			},
+			expectedCursorPos: 1,
+			expectedText:      "\nab cd\nef\ngh",
+		},
+		{
+			name:        "cursor motions then one edit, then undo twice",
+			initialText: "abc def\nghi jkl",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 13,
+			expectedText:      "abc def\nghi jkl",
+		},
+		{
+			name:        "edit, cursor motions, edit, then undo",
+			initialText: "abc def\nghi jkl",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 11,
+			expectedText:      "bc def\nghi jkl",
+		},
+		{
+			name:        "cursor motions after undo preserve redo",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "bc def",
+		},
+		{
+			name:        "edit with no effect does not create undo step",
+			initialText: "abc\n\ndef",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "abc\n\ndef",
+		},
+		{
+			name:        "search between edits, then undo twice",
+			initialText: "abc def\nghi",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "abc def\nghi",
+		},
+		{
+			name:        "undo twice then redo single-op edits one at a time",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "bc",
+		},
+		{
+			name:        "undo earlier state recovers undone branch",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "bc def",
+		},
+		{
+			name:        "undo earlier state twice returns to initial state",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "abc def",
+		},
+		{
+			name:        "undo earlier state with count",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "abc def",
+		},
+		{
+			name:        "undo later state after undo earlier state",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "def",
+		},
+		{
+			name:        "undo later state at newest state does nothing",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "def",
+		},
+		{
+			name:        "redo after undo earlier state follows recovered branch",
+			initialText: "abc def",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, '\x12', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "bc def",
+		},
+		{
+			name:        "virtual edit, insert past end of line",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "ab x\ncd",
+		},
+		{
+			name:        "virtual edit, append in virtual column after vertical motion",
+			initialText: "abcd\n\nab",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '$', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 10,
+			expectedText:      "abcd\n\nab  x",
+		},
+		{
+			name:        "virtual edit, escape without insert moves left one column",
+			initialText: "ab\ncd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "ab  x\ncd",
+		},
+		{
+			name:        "cursor to line at percent",
+			initialText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '5', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 8,
+			expectedText:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+		},
+		{
+			name:        "cursor to line at percent rounds up",
+			initialText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+		},
+		{
+			name:        "cursor to line at one hundred percent",
+			initialText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 18,
+			expectedText:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+		},
+		{
+			name:        "cursor to line at percent over one hundred",
+			initialText: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '5', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9",
+		},
+		{
+			name:        "cursor to line at percent first non-blank",
+			initialText: "a\n  b\nc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '5', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "a\n  b\nc",
+		},
+		{
+			name:        "cursor matching code block delimiter without count",
+			initialText: "(ab)",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone),
+			},
+			expectedCursorPos: 3,
+			expectedText:      "(ab)",
+		},
+		{
+			name:        "format line",
+			initialText: "aaa bbb",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb",
+		},
+		{
+			name:        "format lines with count",
+			initialText: "aaa\nbbb\nccc\nddd",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb ccc\nddd",
+		},
+		{
+			name:        "format paragraph",
+			initialText: "aaa\nbbb\n\nccc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb\n\nccc",
+		},
+		{
+			name:        "format paragraph then undo",
+			initialText: "aaa\nbbb\n\nccc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "aaa\nbbb\n\nccc",
+		},
+		{
+			name:        "format selection",
+			initialText: "aaa\nbbb\nccc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb\nccc",
+		},
+		{
+			name:        "operator indent with failed motion",
+			initialText: "ab",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'z', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "ab",
+		},
+		{
+			name:        "visual mode, then replay last action",
+			initialText: "",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, '\t', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 2,
+			expectedText:      "a\nb\nc\nd",
+		},
+		{
+			name:        "edit text, enter and exit visual mode, then replay last action",
+			initialText: "abc",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "xxabc",
+		},
+		{
+			name:        "visual mode escape to normal mode",
+			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+			},
+			expectedCursorPos: 21,
+			expectedText:      "Lorem ipsum dolor\ntest\nsit amet consectetur\nadipiscing elit",
+		},
+		{
+			name:        "record and replay user macro",
+			initialText: "Lorem ipsum dolor\nsit amet consectetur\nadipiscing elit",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '{', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '}', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ',', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '^', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),
+			},
+			expectedCursorPos: 45,
+			expectedText:      "{Lorem ipsum dolor},\n{sit amet consectetur},\n{adipiscing elit},",
+		},
+		{
+			name:        "record and replay user macro with text search",
+			initialText: "foo bar baz bat",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+			},
+			expectedCursorPos: 7,
+			expectedText:      "foo ar az bat",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interpreter := NewInterpreter()
+			editorState := state.NewEditorState(100, 100, nil, nil)
+
+			// Write the initial text to a temp file, which we will load into the editor.
+			// Append a final "\n" to the contents as the POSIX end-of-file indicator.
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+
+			err = os.WriteFile(path, []byte(tc.initialText+"\n"), 0644)
+			require.NoError(t, err)
+
+			// Load the initial text tempfile.
+			state.LoadDocument(
+				editorState,
+				path,
+				false,
+				func(state.LocatorParams) uint64 { return 0 },
+			)
+
+			// Replay the input events.
+			for _, event := range tc.events {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			// Verify that the cursor position and text match what we expect.
+			buffer := editorState.DocumentBuffer()
+			reader := buffer.TextTree().ReaderAtPosition(0)
+			data, err := io.ReadAll(&reader)
+			require.NoError(t, err)
+			text := string(data)
+			assert.Equal(t, tc.expectedCursorPos, buffer.CursorPosition())
+			assert.Equal(t, tc.expectedText, text)
+		})
+	}
+}
+
+func TestEnterAndExitVisualModeThenReplayLastAction(t *testing.T) {
+	testCases := []struct {
+		name               string
+		enterVisualModeKey rune
+	}{
+		{
+			name:               "charwise (v)",
+			enterVisualModeKey: 'v',
+		},
+		{
+			name:               "linewise (V)",
+			enterVisualModeKey: 'V',
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			inputEvents := []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, tc.enterVisualModeKey, tcell.ModNone), // enter visual mode
+				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),                 // exit visual mode
+				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),                   // replay last action
+			}
+
+			editorState := state.NewEditorState(100, 100, nil, nil)
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, state.InputModeNormal, editorState.InputMode())
+		})
+	}
+}
+
+func TestCtrlCClearsPendingInput(t *testing.T) {
+	interpreter := NewInterpreter()
+	editorState := state.NewEditorState(100, 100, nil, nil)
+	for _, event := range []tcell.Event{
+		tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+		tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+	} {
+		action := interpreter.ProcessEvent(event, ContextFromEditorState(editorState))
+		action(editorState)
+	}
+	assert.Equal(t, "2d", interpreter.InputBufferString(state.InputModeNormal))
+
+	event := tcell.NewEventKey(tcell.KeyCtrlC, '\x00', tcell.ModNone)
+	action := interpreter.ProcessEvent(event, ContextFromEditorState(editorState))
+	action(editorState)
+	assert.Equal(t, "", interpreter.InputBufferString(state.InputModeNormal))
+	assert.Equal(t, state.InputModeNormal, editorState.InputMode())
+}
+
+func TestInsertArrowKeys(t *testing.T) {
+	testCases := []struct {
+		name              string
+		insertArrowKeys   string
+		expectedCursorPos uint64
+		expectedInputMode state.InputMode
+	}{
+		{
+			name:              "move",
+			insertArrowKeys:   config.InsertArrowKeysMove,
+			expectedCursorPos: 2,
+			expectedInputMode: state.InputModeInsert,
+		},
+		{
+			name:              "ignore",
+			insertArrowKeys:   config.InsertArrowKeysIgnore,
+			expectedCursorPos: 3,
+			expectedInputMode: state.InputModeInsert,
+		},
+		{
+			name:              "normal",
+			insertArrowKeys:   config.InsertArrowKeysNormal,
+			expectedCursorPos: 1,
+			expectedInputMode: state.InputModeNormal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  map[string]any{"insertArrowKeys": tc.insertArrowKeys},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+			err = os.WriteFile(path, []byte("abcd\n"), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents := []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'l', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyLeft, '\x00', tcell.ModNone),
+			}
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+			assert.Equal(t, tc.expectedInputMode, editorState.InputMode())
+			assert.Equal(t, "abcd", editorState.DocumentBuffer().TextTree().String())
+		})
+	}
+}
+
+func TestInsertModeDeleteWordAndLine(t *testing.T) {
+	testCases := []struct {
+		name              string
+		autoIndent        bool
+		initialText       string
+		events            []tcell.Event
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:        "delete prev word",
+			autoIndent:  false,
+			initialText: "foo bar baz",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 8,
+			expectedText:      "foo bar ",
+		},
+		{
+			name:        "delete prev word twice",
+			autoIndent:  false,
+			initialText: "foo bar baz",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "foo ",
+		},
+		{
+			name:        "delete prev word does not cross line",
+			autoIndent:  false,
+			initialText: "foo\nbar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'I', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "foo\nbar",
+		},
+		{
+			name:        "delete prev word through indentation without auto-indent",
+			autoIndent:  false,
+			initialText: "    foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
+		{
+			name:        "delete to start of line",
+			autoIndent:  false,
+			initialText: "    foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
+		{
+			name:        "delete to start of line from middle of line",
+			autoIndent:  false,
+			initialText: "foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "bar",
+		},
+		{
+			name:        "delete prev word stops at auto-indent",
+			autoIndent:  true,
+			initialText: "    foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "    ",
+		},
+		{
+			name:        "delete prev word in auto-indent deletes to prev tab stop",
+			autoIndent:  true,
+			initialText: "        foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "    ",
+		},
+		{
+			name:        "delete to start of line stops at auto-indent",
+			autoIndent:  true,
+			initialText: "    foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+			},
+			expectedCursorPos: 4,
+			expectedText:      "    ",
+		},
+		{
+			name:        "delete to start of line twice deletes auto-indent",
+			autoIndent:  true,
+			initialText: "    foo bar",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+			},
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
+		{
+			name:        "delete prev word on auto-indented new line",
+			autoIndent:  true,
+			initialText: "    foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 9,
+			expectedText:      "    foo\n\t",
+		},
+		{
+			name:        "delete prev word across indentation of auto-indented new line",
+			autoIndent:  true,
+			initialText: "    foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+				tcell.NewEventKey(tcell.KeyCtrlW, '\x17', tcell.ModCtrl),
+			},
+			expectedCursorPos: 8,
+			expectedText:      "    foo\n",
+		},
+		{
+			name:        "delete to start of line on auto-indented new line",
+			autoIndent:  true,
+			initialText: "    foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlU, '\x15', tcell.ModCtrl),
+			},
+			expectedCursorPos: 9,
+			expectedText:      "    foo\n\t",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  map[string]any{"autoIndent": tc.autoIndent, "detectIndent": false},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+			err = os.WriteFile(path, []byte(tc.initialText+"\n"), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			interpreter := NewInterpreter()
+			for _, event := range tc.events {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+		})
+	}
+}
+
+func TestContinueComments(t *testing.T) {
+	testCases := []struct {
+		name              string
+		initialText       string
+		events            []tcell.Event
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:        "begin new line below comment",
+			initialText: "\t// foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone),
+			},
+			expectedCursorPos: 15,
+			expectedText:      "\t// foo\n\t// bar",
+		},
+		{
+			name:        "begin new line below code",
+			initialText: "\tfoo()",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 9,
+			expectedText:      "\tfoo()\n\tx",
+		},
+		{
+			name:        "enter twice ends comment",
+			initialText: "\t// foo",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+			},
+			expectedCursorPos: 11,
+			expectedText:      "\t// foo\n\n\tx",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config: map[string]any{
+						"autoIndent":       true,
+						"continueComments": true,
+						"syntaxLanguage":   "go",
+					},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+			err = os.WriteFile(path, []byte(tc.initialText+"\n"), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			interpreter := NewInterpreter()
+			for _, event := range tc.events {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+		})
+	}
+}
+
+func TestMouse(t *testing.T) {
+	testCases := []struct {
+		name              string
+		events            []tcell.Event
+		expectedMode      state.InputMode
+		expectedCursorPos uint64
+		expectedSelection selection.Region
+	}{
+		{
+			name: "click positions cursor",
+			events: []tcell.Event{
+				tcell.NewEventMouse(2, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeNormal,
+			expectedCursorPos: 7,
+		},
+		{
+			name: "click in status bar",
+			events: []tcell.Event{
+				tcell.NewEventMouse(2, 99, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(3, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(3, 1, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeNormal,
+			expectedCursorPos: 0,
+		},
+		{
+			name: "drag selects text",
+			events: []tcell.Event{
+				tcell.NewEventMouse(1, 0, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(1, 0, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeVisual,
+			expectedCursorPos: 7,
+			expectedSelection: selection.Region{StartPos: 1, EndPos: 8},
+		},
+		{
+			name: "click after drag clears selection",
+			events: []tcell.Event{
+				tcell.NewEventMouse(1, 0, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.ButtonNone, tcell.ModNone),
+				tcell.NewEventMouse(0, 2, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(0, 2, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeNormal,
+			expectedCursorPos: 9,
+		},
+		{
+			name: "click in insert mode",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventMouse(5, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(5, 1, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeInsert,
+			expectedCursorPos: 8,
+		},
+		{
+			name: "drag in insert mode",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventMouse(1, 0, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(1, 2, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(1, 2, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeInsert,
+			expectedCursorPos: 10,
+		},
+		{
+			name: "scroll wheel",
+			events: []tcell.Event{
+				tcell.NewEventMouse(0, 0, tcell.WheelDown, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeNormal,
+			expectedCursorPos: 12,
+		},
+		{
+			name: "ignore mouse in menu mode",
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.Button1, tcell.ModNone),
+				tcell.NewEventMouse(2, 1, tcell.ButtonNone, tcell.ModNone),
+			},
+			expectedMode:      state.InputModeMenu,
+			expectedCursorPos: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			editorState := state.NewEditorState(100, 100, nil, nil)
+			for _, r := range "abcd\nefg\nhi\njk" {
+				state.InsertRune(editorState, r)
+			}
+			state.MoveCursor(editorState, func(state.LocatorParams) uint64 { return 0 })
+
+			interpreter := NewInterpreter()
+			for _, event := range tc.events {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedMode, editorState.InputMode())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+			assert.Equal(t, tc.expectedSelection, editorState.DocumentBuffer().SelectedRegion())
+		})
+	}
+}
+
+func TestInsertLiteral(t *testing.T) {
+	testCases := []struct {
+		name              string
+		keys              string
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "tab expanded without ctrl-v",
+			keys:              "i<tab>x<esc>",
+			expectedText:      "    x",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "literal tab",
+			keys:              "i<c-v><tab>x<esc>",
+			expectedText:      "\tx",
+			expectedCursorPos: 1,
+		},
+		{
+			name:              "literal control character",
+			keys:              "i<c-v><c-a><esc>",
+			expectedText:      "\x01",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "decimal code point",
+			keys:              "i<c-v>233x<esc>",
+			expectedText:      "éx",
+			expectedCursorPos: 1,
+		},
+		{
+			name:              "decimal code point ended by non-digit",
+			keys:              "i<c-v>65 b<esc>",
+			expectedText:      "A b",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "decimal code point ended by escape",
+			keys:              "i<c-v>65<esc>",
+			expectedText:      "A",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "decimal code point ended by enter",
+			keys:              "i<c-v>65<enter>x<esc>",
+			expectedText:      "A\nx",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "decimal code point ended by another code point",
+			keys:              "i<c-v>65<c-v>66<esc>",
+			expectedText:      "AB",
+			expectedCursorPos: 1,
+		},
+		{
+			name:              "hex code point",
+			keys:              "i<c-v>u00e9<esc>",
+			expectedText:      "é",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "hex code point ended by non-hex digit",
+			keys:              "i<c-v>u3BB.<esc>",
+			expectedText:      "λ.",
+			expectedCursorPos: 1,
+		},
+		{
+			name:              "hex code point ended by escape",
+			keys:              "i<c-v>u3bb<esc>",
+			expectedText:      "λ",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "hex code point ended by escape, then replay last action",
+			keys:              "i<c-v>u3bb<esc>.",
+			expectedText:      "λλ",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "long hex code point",
+			keys:              "i<c-v>U0001f600<esc>",
+			expectedText:      "😀",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "invalid code point",
+			keys:              "i<c-v>UFFFFFFFF<esc>",
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "code point, then replay last action",
+			keys:              "i<c-v>u00e9<esc>.",
+			expectedText:      "éé",
+			expectedCursorPos: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  map[string]any{"tabExpand": true, "tabSize": 4},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+		})
+	}
+}
+
+func TestVerifyGeneratedPrograms(t *testing.T) {
+	testCases := []struct {
+		name string
+		path string
+	}{
+		{name: "normal mode", path: NormalModeProgramPath},
+		{name: "insert mode", path: InsertModeProgramPath},
+		{name: "visual mode", path: VisualModeProgramPath},
+		{name: "menu mode", path: MenuModeProgramPath},
+		{name: "search mode", path: SearchModeProgramPath},
+		{name: "task mode", path: TaskModeProgramPath},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prog := mustLoadProgram(tc.path)
+			err := vm.VerifyProgram(prog)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCountLimits(t *testing.T) {
+	testCases := []string{
+		"1025fx",
+		"1025Fx",
+		"1025tx",
+		"1025Tx",
+		"1025dd",
+		"1025dl",
+		"1025x",
+		"1025dfx",
+		"1025dFx",
+		"1025dtx",
+		"1025dTx",
+		"1025cfx",
+		"1025cFx",
+		"1025ctx",
+		"1025cTx",
+		"1025.",
+		"1025>>",
+		"1025<<",
+		"v33>",
+		"v33<",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			interpreter := NewInterpreter()
+			editorState := state.NewEditorState(100, 100, nil, nil)
+
+			for _, r := range tc {
+				event := tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			msg := editorState.StatusMsg()
+			assert.Equal(t, state.StatusMsgStyleError, msg.Style)
+			assert.Contains(t, msg.Text, "count")
+		})
+	}
+}
+
+func TestStatusMsgOrdering(t *testing.T) {
+	testCases := []struct {
+		name            string
+		events          string
+		expectedStyle   state.StatusMsgStyle
+		expectedMsgText string
+	}{
+		{
+			name:            "cursor motion clears previous status",
+			events:          "l",
+			expectedStyle:   state.StatusMsgStyleSuccess,
+			expectedMsgText: "",
+		},
+		{
+			name:            "edit clears previous status",
+			events:          "x",
+			expectedStyle:   state.StatusMsgStyleSuccess,
+			expectedMsgText: "",
+		},
+		{
+			name:            "next git hunk reports status",
+			events:          "]c",
+			expectedStyle:   state.StatusMsgStyleError,
+			expectedMsgText: "Document is not tracked in a git repository",
+		},
+		{
+			name:            "prev git hunk reports status",
+			events:          "[c",
+			expectedStyle:   state.StatusMsgStyleError,
+			expectedMsgText: "Document is not tracked in a git repository",
+		},
+		{
+			name:            "next git hunk in visual mode reports status",
+			events:          "v]c",
+			expectedStyle:   state.StatusMsgStyleError,
+			expectedMsgText: "Document is not tracked in a git repository",
+		},
+		{
+			name:            "next diagnostic reports status",
+			events:          "]d",
+			expectedStyle:   state.StatusMsgStyleError,
+			expectedMsgText: "No diagnostics",
+		},
+		{
+			name:            "next heading reports status",
+			events:          "]]",
+			expectedStyle:   state.StatusMsgStyleError,
+			expectedMsgText: "No headings in document",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interpreter := NewInterpreter()
+			editorState := state.NewEditorState(100, 100, nil, nil)
+			state.InsertRune(editorState, 'a')
+			state.SetStatusMsg(editorState, state.StatusMsg{
+				Style: state.StatusMsgStyleError,
+				Text:  "previous status",
+			})
+
+			for _, r := range tc.events {
+				event := tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			msg := editorState.StatusMsg()
+			assert.Equal(t, tc.expectedStyle, msg.Style)
+			assert.Equal(t, tc.expectedMsgText, msg.Text)
+		})
+	}
+}
+
+func BenchmarkNewInterpreter(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		NewInterpreter()
+	}
+}
+
+func BenchmarkProcessEvent(b *testing.B) {
+	benchmarks := []struct {
+		name   string
+		mode   state.InputMode
+		events []tcell.Event
+	}{
+		{
+			name: "i",
+			mode: state.InputModeNormal,
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+			},
+		},
+		{
+			name: "1234gg",
+			mode: state.InputModeNormal,
+			events: []tcell.Event{
+				tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '4', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+			},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			logWriter := log.Writer()
+			defer func() {
+				log.SetOutput(logWriter)
+			}()
+			log.SetOutput(io.Discard)
+
+			interpreter := NewInterpreter()
+			inputCtx := Context{InputMode: bm.mode}
+			b.ResetTimer()
+			for _, event := range bm.events {
+				interpreter.ProcessEvent(event, inputCtx)
+			}
+		})
+	}
+}
+
+func TestGitRebaseMenuCommands(t *testing.T) {
+	testCases := []struct {
+		name           string
+		syntaxLanguage string
+		keys           string
+		expectedText   string
+	}{
+		{
+			name:           "cycle rebase action",
+			syntaxLanguage: "gitrebase",
+			keys:           "j:ra<enter>",
+			expectedText:   "pick 1234abc first\nreword 5678def second",
+		},
+		{
+			name:           "move rebase commit up",
+			syntaxLanguage: "gitrebase",
+			keys:           "j:rk<enter>",
+			expectedText:   "pick 5678def second\npick 1234abc first",
+		},
+		{
+			name:           "move rebase commit down",
+			syntaxLanguage: "gitrebase",
+			keys:           ":rj<enter>",
+			expectedText:   "pick 5678def second\npick 1234abc first",
+		},
+		{
+			name:           "not available for other languages",
+			syntaxLanguage: "plaintext",
+			keys:           "j:ra<enter>",
+			expectedText:   "pick 1234abc first\npick 5678def second",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  map[string]any{"syntaxLanguage": tc.syntaxLanguage},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			path := filepath.Join(t.TempDir(), "git-rebase-todo")
+			err := os.WriteFile(path, []byte("pick 1234abc first\npick 5678def second\n"), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+		})
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	testCases := []struct {
+		name              string
+		keys              string
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "expand before space",
+			keys:              "iteh x<esc>",
+			expectedText:      "the x",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "expand before newline",
+			keys:              "iteh<enter>x<esc>",
+			expectedText:      "the\nx",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "expand before tab",
+			keys:              "iteh<tab><esc>",
+			expectedText:      "the\t",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "ctrl-v avoids expansion",
+			keys:              "iteh<c-v> x<esc>",
+			expectedText:      "teh x",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "undo expansion with insert",
+			keys:              "iteh x<esc>u",
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "repeat insert with expansion",
+			keys:              "iteh <esc>0.",
+			expectedText:      "the the ",
+			expectedCursorPos: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config: map[string]any{
+						"abbreviations": map[string]any{"teh": "the"},
+					},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			path := filepath.Join(t.TempDir(), "test.txt")
+			err := os.WriteFile(path, []byte(""), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+		})
+	}
+}
+
+func TestMultiCursor(t *testing.T) {
+	testCases := []struct {
+		name              string
+		disabled          bool
+		initialText       string
+		keys              string
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "insert at every match",
+			initialText:       "foo bar foo baz foo",
+			keys:              "<c-n><c-n>ix<esc>",
+			expectedText:      "xfoo bar xfoo baz xfoo",
+			expectedCursorPos: 18,
+		},
+		{
+			name:              "append at every match",
+			initialText:       "foo foo",
+			keys:              "<c-n>ax<esc>",
+			expectedText:      "fxoo fxoo",
+			expectedCursorPos: 6,
+		},
+		{
+			name:              "delete at every cursor",
+			initialText:       "a foo b foo",
+			keys:              "w<c-n>i<backspace><esc>",
+			expectedText:      "afoo bfoo",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "insert newline at every cursor",
+			initialText:       "foo foo",
+			keys:              "<c-n>i<enter><esc>",
+			expectedText:      "\nfoo \nfoo",
+			expectedCursorPos: 6,
+		},
+		{
+			name:              "stop when every match has a cursor",
+			initialText:       "foo foo",
+			keys:              "<c-n><c-n><c-n>ix<esc>",
+			expectedText:      "xfoo xfoo",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "escape removes extra cursors",
+			initialText:       "foo foo",
+			keys:              "<c-n><esc>ix<esc>",
+			expectedText:      "foo xfoo",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "undo edits at every cursor",
+			initialText:       "foo foo",
+			keys:              "<c-n>ix<esc>u",
+			expectedText:      "foo foo",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "disabled",
+			disabled:          true,
+			initialText:       "foo foo",
+			keys:              "<c-n>ix<esc>",
+			expectedText:      "xfoo foo",
+			expectedCursorPos: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  map[string]any{"multiCursor": !tc.disabled},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			path := filepath.Join(t.TempDir(), "test.txt")
+			err := os.WriteFile(path, []byte(tc.initialText), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
+		})
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	testCases := []struct {
+		name              string
+		keys              string
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "expand and fill tab stops",
+			keys:              "ifn<tab>foo<tab>x<tab>y<esc>",
+			expectedText:      "func foo(x) {\n\ty\n}",
+			expectedCursorPos: 15,
+		},
+		{
+			name:              "keep placeholder",
+			keys:              "ifn<tab><tab><tab>y<esc>",
+			expectedText:      "func name() {\n\ty\n}",
+			expectedCursorPos: 15,
+		},
+		{
+			name:              "tab after final stop inserts tab",
+			keys:              "ifn<tab><tab><tab><tab><esc>",
+			expectedText:      "func name() {\n\t\t\n}",
+			expectedCursorPos: 15,
+		},
+		{
+			name:              "escape ends session",
+			keys:              "ifn<tab><esc>a<tab><esc>",
+			expectedText:      "func \tname() {\n\t\n}",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "undo expansion with insert",
+			keys:              "ifn<tab>foo<esc>u",
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			interpreter := NewInterpreter()
-			editorState := state.NewEditorState(100, 100, nil, nil)
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config: map[string]any{
+						"snippets": map[string]any{"fn": "func ${1:name}($2) {\n\t$0\n}"},
+					},
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
 
-			// Write the initial text to a temp file, which we will load into the editor.
-			// Append a final "\n" to the contents as the POSIX end-of-file indicator.
-			tmpFile, err := os.CreateTemp("", "")
+			path := filepath.Join(t.TempDir(), "test.txt")
+			err := os.WriteFile(path, []byte(""), 0644)
 			require.NoError(t, err)
-			path := tmpFile.Name()
-			defer os.Remove(path)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
 
-			err = os.WriteFile(path, []byte(tc.initialText+"\n"), 0644)
+			inputEvents, err := ParseKeys(tc.keys)
 			require.NoError(t, err)
 
-			// Load the initial text tempfile.
-			state.LoadDocument(
-				editorState,
-				path,
-				false,
-				func(state.LocatorParams) uint64 { return 0 },
-			)
-
-			// Replay the input events.
-			for _, event := range tc.events {
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
 				inputCtx := ContextFromEditorState(editorState)
 				action := interpreter.ProcessEvent(event, inputCtx)
 				action(editorState)
 			}
 
-			// Verify that the cursor position and text match what we expect.
-			buffer := editorState.DocumentBuffer()
-			reader := buffer.TextTree().ReaderAtPosition(0)
-			data, err := io.ReadAll(&reader)
-			require.NoError(t, err)
-			text := string(data)
-			assert.Equal(t, tc.expectedCursorPos, buffer.CursorPosition())
-			assert.Equal(t, tc.expectedText, text)
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
 		})
 	}
 }
 
-func TestEnterAndExitVisualModeThenReplayLastAction(t *testing.T) {
+func TestAutoCloseTags(t *testing.T) {
 	testCases := []struct {
-		name               string
-		enterVisualModeKey rune
+		name              string
+		keys              string
+		expectedText      string
+		expectedCursorPos uint64
 	}{
 		{
-			name:               "charwise (v)",
-			enterVisualModeKey: 'v',
+			name:              "close tag",
+			keys:              "i<lt>div>x<esc>",
+			expectedText:      "<div>x</div>",
+			expectedCursorPos: 5,
 		},
 		{
-			name:               "linewise (V)",
-			enterVisualModeKey: 'V',
+			name:              "nested tags",
+			keys:              "i<lt>ul><lt>li>x<esc>",
+			expectedText:      "<ul><li>x</li></ul>",
+			expectedCursorPos: 8,
+		},
+		{
+			name:              "void element",
+			keys:              "i<lt>p><lt>br>x<esc>",
+			expectedText:      "<p><br>x</p>",
+			expectedCursorPos: 7,
+		},
+		{
+			name:              "ctrl-v avoids closing tag",
+			keys:              "i<lt>div<c-v>><esc>",
+			expectedText:      "<div>",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "undo closing tag with insert",
+			keys:              "i<lt>div>x<esc>u",
+			expectedText:      "",
+			expectedCursorPos: 0,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			inputEvents := []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, tc.enterVisualModeKey, tcell.ModNone), // enter visual mode
-				tcell.NewEventKey(tcell.KeyEsc, '\x00', tcell.ModNone),                 // exit visual mode
-				tcell.NewEventKey(tcell.KeyRune, '.', tcell.ModNone),                   // replay last action
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config: map[string]any{
+						"autoCloseTags": true,
+					},
+				},
 			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			path := filepath.Join(t.TempDir(), "test.html")
+			err := os.WriteFile(path, []byte(""), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
 
-			editorState := state.NewEditorState(100, 100, nil, nil)
 			interpreter := NewInterpreter()
 			for _, event := range inputEvents {
 				inputCtx := ContextFromEditorState(editorState)
@@ -2143,123 +4807,373 @@ func TestEnterAndExitVisualModeThenReplayLastAction(t *testing.T) {
 				action(editorState)
 			}
 
-			assert.Equal(t, state.InputModeNormal, editorState.InputMode())
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
 		})
 	}
 }
 
-func TestVerifyGeneratedPrograms(t *testing.T) {
+func TestSetOptionMenuCommands(t *testing.T) {
 	testCases := []struct {
-		name string
-		path string
+		name            string
+		keys            string
+		expectedTabSize uint64
+		expectedText    string
 	}{
-		{name: "normal mode", path: NormalModeProgramPath},
-		{name: "insert mode", path: InsertModeProgramPath},
-		{name: "visual mode", path: VisualModeProgramPath},
-		{name: "menu mode", path: MenuModeProgramPath},
-		{name: "search mode", path: SearchModeProgramPath},
-		{name: "task mode", path: TaskModeProgramPath},
+		{
+			name:            "set option",
+			keys:            ":set ts=2 et<enter>i<tab><esc>",
+			expectedTabSize: 2,
+			expectedText:    "  ",
+		},
+		{
+			name:            "set local option",
+			keys:            ":setlocal tabsize=8<enter>",
+			expectedTabSize: 8,
+			expectedText:    "",
+		},
+		{
+			name:            "invalid option",
+			keys:            ":set ts=0<enter>",
+			expectedTabSize: 4,
+			expectedText:    "",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			prog := mustLoadProgram(tc.path)
-			err := vm.VerifyProgram(prog)
-			assert.NoError(t, err)
+			editorState := editorStateWithText(t, "")
+
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
+				inputCtx := ContextFromEditorState(editorState)
+				action := interpreter.ProcessEvent(event, inputCtx)
+				action(editorState)
+			}
+
+			assert.Equal(t, tc.expectedTabSize, editorState.DocumentBuffer().TabSize())
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
 		})
 	}
 }
 
-func TestCountLimits(t *testing.T) {
-	testCases := []string{
-		"1025fx",
-		"1025Fx",
-		"1025tx",
-		"1025Tx",
-		"1025dd",
-		"1025dl",
-		"1025x",
-		"1025dfx",
-		"1025dFx",
-		"1025dtx",
-		"1025dTx",
-		"1025cfx",
-		"1025cFx",
-		"1025ctx",
-		"1025cTx",
-		"1025.",
-		"1025>>",
-		"1025<<",
-		"v33>",
-		"v33<",
+func TestLineRangeMenuCommands(t *testing.T) {
+	testCases := []struct {
+		name           string
+		initialText    string
+		keys           string
+		expectedText   string
+		expectedCursor uint64
+	}{
+		{
+			name:           "delete current line",
+			initialText:    "a\nb\nc",
+			keys:           "j:d<enter>",
+			expectedText:   "a\nc",
+			expectedCursor: 2,
+		},
+		{
+			name:           "delete range",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":2,3d<enter>",
+			expectedText:   "a\nd",
+			expectedCursor: 2,
+		},
+		{
+			name:           "delete inverted range",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":3,2delete<enter>",
+			expectedText:   "a\nd",
+			expectedCursor: 2,
+		},
+		{
+			name:           "delete selected lines",
+			initialText:    "a\nb\nc\nd",
+			keys:           "Vj:d<enter>",
+			expectedText:   "c\nd",
+			expectedCursor: 0,
+		},
+		{
+			name:           "delete then undo",
+			initialText:    "a\nb\nc",
+			keys:           ":%d<enter>u",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "delete invalid range",
+			initialText:    "a\nb\nc",
+			keys:           ":2,9d<enter>",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "yank range with offsets",
+			initialText:    "a\nb\nc\nd",
+			keys:           "j:.,.+1y<enter>Gp",
+			expectedText:   "a\nb\nc\nd\nb\nc",
+			expectedCursor: 8,
+		},
+		{
+			name:           "move range to top",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":3,$mo 0<enter>",
+			expectedText:   "c\nd\na\nb",
+			expectedCursor: 2,
+		},
+		{
+			name:           "move range with vim alias",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":3,4m 0<enter>",
+			expectedText:   "c\nd\na\nb",
+			expectedCursor: 2,
+		},
+		{
+			name:           "move current line with vim alias",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":m $<enter>",
+			expectedText:   "b\nc\nd\na",
+			expectedCursor: 6,
+		},
+		{
+			name:           "range with unknown alias",
+			initialText:    "a\nb\nc\nd",
+			keys:           ":3,4x 0<enter>",
+			expectedText:   "a\nb\nc\nd",
+			expectedCursor: 0,
+		},
+		{
+			name:           "move range before first line",
+			initialText:    "a\nb\nc\nd",
+			keys:           "G:-1,.move 0<enter>",
+			expectedText:   "c\nd\na\nb",
+			expectedCursor: 2,
+		},
+		{
+			name:           "copy range to end of document",
+			initialText:    "a\nb\nc",
+			keys:           ":1,2t $<enter>",
+			expectedText:   "a\nb\nc\na\nb",
+			expectedCursor: 8,
+		},
+		{
+			name:           "copy then undo",
+			initialText:    "a\nb\nc",
+			keys:           ":%copy 0<enter>u",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "delete to register then paste",
+			initialText:    "a\nb\nc",
+			keys:           ":1d a<enter>yy\"ap",
+			expectedText:   "b\na\nc",
+			expectedCursor: 2,
+		},
+		{
+			name:           "delete to invalid register",
+			initialText:    "a\nb\nc",
+			keys:           ":1d ab<enter>",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "normal keys on range",
+			initialText:    "a\nb\nc",
+			keys:           ":2,$norm A;<enter>",
+			expectedText:   "a\nb;\nc;",
+			expectedCursor: 6,
+		},
+		{
+			name:           "normal keys on selected lines then undo",
+			initialText:    "a\nb\nc",
+			keys:           "Vj:norm I-<enter>u",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "normal keys without range at cursor",
+			initialText:    "abc\ndef",
+			keys:           "l:norm x<enter>",
+			expectedText:   "ac\ndef",
+			expectedCursor: 1,
+		},
+		{
+			name:           "sort range",
+			initialText:    "d\nc\nb\na",
+			keys:           ":2,3sort<enter>",
+			expectedText:   "d\nb\nc\na",
+			expectedCursor: 2,
+		},
+		{
+			name:           "sort entire document by default",
+			initialText:    "d\nc\nb\na",
+			keys:           ":sort<enter>",
+			expectedText:   "a\nb\nc\nd",
+			expectedCursor: 0,
+		},
+		{
+			name:           "sort reverse with flags",
+			initialText:    "10\n9\n10\n100",
+			keys:           ":sort! nu<enter>",
+			expectedText:   "100\n10\n9",
+			expectedCursor: 0,
+		},
+		{
+			name:           "sort with flags in any order",
+			initialText:    "b\nA\na\nB",
+			keys:           ":sort ui<enter>",
+			expectedText:   "A\nb",
+			expectedCursor: 0,
+		},
+		{
+			name:           "sort with invalid flag",
+			initialText:    "b\na",
+			keys:           ":sort x<enter>",
+			expectedText:   "b\na",
+			expectedCursor: 0,
+		},
 	}
 
 	for _, tc := range testCases {
-		t.Run(tc, func(t *testing.T) {
-			interpreter := NewInterpreter()
-			editorState := state.NewEditorState(100, 100, nil, nil)
+		t.Run(tc.name, func(t *testing.T) {
+			editorState := editorStateWithText(t, tc.initialText)
 
-			for _, r := range tc {
-				event := tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+			inputEvents, err := ParseKeys(tc.keys)
+			require.NoError(t, err)
+
+			interpreter := NewInterpreter()
+			for _, event := range inputEvents {
 				inputCtx := ContextFromEditorState(editorState)
 				action := interpreter.ProcessEvent(event, inputCtx)
 				action(editorState)
 			}
 
-			msg := editorState.StatusMsg()
-			assert.Equal(t, state.StatusMsgStyleError, msg.Style)
-			assert.Contains(t, msg.Text, "count")
+			assert.Equal(t, tc.expectedText, editorState.DocumentBuffer().TextTree().String())
+			assert.Equal(t, tc.expectedCursor, editorState.DocumentBuffer().CursorPosition())
+			assert.Equal(t, state.InputModeNormal, editorState.InputMode())
 		})
 	}
 }
 
-func BenchmarkNewInterpreter(b *testing.B) {
-	for n := 0; n < b.N; n++ {
-		NewInterpreter()
-	}
-}
-
-func BenchmarkProcessEvent(b *testing.B) {
-	benchmarks := []struct {
-		name   string
-		mode   state.InputMode
-		events []tcell.Event
+func TestMatchingDelimiterConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		config            map[string]any
+		initialText       string
+		initialCursorPos  uint64
+		expectedCursorPos uint64
 	}{
 		{
-			name: "i",
-			mode: state.InputModeNormal,
-			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
-			},
+			name:              "default match pairs",
+			config:            map[string]any{},
+			initialText:       "foo(bar)",
+			initialCursorPos:  3,
+			expectedCursorPos: 7,
 		},
 		{
-			name: "1234gg",
-			mode: state.InputModeNormal,
-			events: []tcell.Event{
-				tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, '4', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
-				tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
-			},
+			name:              "custom match pairs",
+			config:            map[string]any{"matchPairs": "<:>"},
+			initialText:       "foo<bar>",
+			initialCursorPos:  3,
+			expectedCursorPos: 7,
+		},
+		{
+			name:              "match words",
+			config:            map[string]any{"matchWords": "if:else:fi"},
+			initialText:       "if a\nthen b\nelse c\nfi",
+			initialCursorPos:  0,
+			expectedCursorPos: 12,
+		},
+		{
+			name:              "match tags",
+			config:            map[string]any{"matchTags": true, "matchPairs": "(:),[:],{:},<:>"},
+			initialText:       "<p>foo</p>",
+			initialCursorPos:  0,
+			expectedCursorPos: 6,
+		},
+		{
+			name:              "match pairs if tag has no match",
+			config:            map[string]any{"matchTags": true, "matchPairs": "(:),[:],{:},<:>"},
+			initialText:       "a <b> c",
+			initialCursorPos:  2,
+			expectedCursorPos: 4,
 		},
 	}
 
-	for _, bm := range benchmarks {
-		b.Run(bm.name, func(b *testing.B) {
-			logWriter := log.Writer()
-			defer func() {
-				log.SetOutput(logWriter)
-			}()
-			log.SetOutput(io.Discard)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "test",
+					Pattern: "**",
+					Config:  tc.config,
+				},
+			}
+			editorState := state.NewEditorState(100, 100, configRuleSet, nil)
+
+			tmpFile, err := os.CreateTemp("", "")
+			require.NoError(t, err)
+			path := tmpFile.Name()
+			defer os.Remove(path)
+			err = os.WriteFile(path, []byte(tc.initialText+"\n"), 0644)
+			require.NoError(t, err)
+			state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return tc.initialCursorPos })
 
 			interpreter := NewInterpreter()
-			inputCtx := Context{InputMode: bm.mode}
-			b.ResetTimer()
-			for _, event := range bm.events {
-				interpreter.ProcessEvent(event, inputCtx)
-			}
+			inputCtx := ContextFromEditorState(editorState)
+			action := interpreter.ProcessEvent(tcell.NewEventKey(tcell.KeyRune, '%', tcell.ModNone), inputCtx)
+			action(editorState)
+
+			assert.Equal(t, tc.expectedCursorPos, editorState.DocumentBuffer().CursorPosition())
 		})
 	}
 }
+
+func TestLoadAlternateDocument(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.txt")
+	err := os.WriteFile(pathA, []byte("a1\na2\na3\n"), 0644)
+	require.NoError(t, err)
+	pathB := filepath.Join(dir, "b.txt")
+	err = os.WriteFile(pathB, []byte("b1\nb2\n"), 0644)
+	require.NoError(t, err)
+
+	editorState := state.NewEditorState(100, 100, nil, nil)
+	interpreter := NewInterpreter()
+	processEvents := func(events ...tcell.Event) {
+		for _, event := range events {
+			inputCtx := ContextFromEditorState(editorState)
+			action := interpreter.ProcessEvent(event, inputCtx)
+			action(editorState)
+		}
+	}
+	processKeys := func(s string) {
+		for _, r := range s {
+			processEvents(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	// Edit file A and move to the last line.
+	state.LoadDocument(editorState, pathA, true, func(state.LocatorParams) uint64 { return 0 })
+	processKeys("2j")
+	assert.Equal(t, uint64(6), editorState.DocumentBuffer().CursorPosition())
+
+	// Edit file B from the menu.
+	processKeys(":e " + pathB)
+	processEvents(tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone))
+	assert.Equal(t, pathB, editorState.FileWatcher().Path())
+
+	// Ctrl-^ returns to the same line in file A.
+	processEvents(tcell.NewEventKey(tcell.KeyCtrlCarat, '\x1e', tcell.ModCtrl))
+	assert.Equal(t, pathA, editorState.FileWatcher().Path())
+	assert.Equal(t, uint64(6), editorState.DocumentBuffer().CursorPosition())
+
+	// ":e#" returns to file B.
+	processKeys(":e#")
+	processEvents(tcell.NewEventKey(tcell.KeyEnter, '\r', tcell.ModNone))
+	assert.Equal(t, pathB, editorState.FileWatcher().Path())
+}
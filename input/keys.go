@@ -0,0 +1,173 @@
+package input
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/aretext/aretext/state"
+)
+
+// maxExecuteKeysDepth limits how many times executed keys can trigger another execution
+// (for example, by invoking the "normal" menu command) to prevent infinite recursion.
+const maxExecuteKeysDepth = 8
+
+// executeKeysDepth is the number of nested calls to ExecuteNormalModeKeys currently running.
+// The editor processes input on a single goroutine, so this does not need synchronization.
+var executeKeysDepth int
+
+var specialKeys = map[string]tcell.Key{
+	"esc":       tcell.KeyEscape,
+	"escape":    tcell.KeyEscape,
+	"cr":        tcell.KeyEnter,
+	"enter":     tcell.KeyEnter,
+	"return":    tcell.KeyEnter,
+	"tab":       tcell.KeyTab,
+	"bs":        tcell.KeyBackspace2,
+	"backspace": tcell.KeyBackspace2,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+}
+
+var specialRunes = map[string]rune{
+	"lt":    '<',
+	"space": ' ',
+}
+
+// ParseKeys parses a string into a sequence of key events.
+// Special keys use vim-style notation, such as "<esc>", "<enter>", "<tab>", or "<c-r>" for ctrl-r.
+// Use "<lt>" for a literal "<" that would otherwise start a special key.
+func ParseKeys(keys string) ([]*tcell.EventKey, error) {
+	var events []*tcell.EventKey
+	for len(keys) > 0 {
+		if keys[0] == '<' {
+			if end := strings.IndexByte(keys, '>'); end > 0 {
+				name := strings.ToLower(keys[1:end])
+				event, err := specialKeyEvent(name)
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, event)
+				keys = keys[end+1:]
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(keys)
+		events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		keys = keys[size:]
+	}
+	return events, nil
+}
+
+func specialKeyEvent(name string) (*tcell.EventKey, error) {
+	if key, ok := specialKeys[name]; ok {
+		return tcell.NewEventKey(key, 0, tcell.ModNone), nil
+	}
+
+	if r, ok := specialRunes[name]; ok {
+		return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), nil
+	}
+
+	if len(name) == 3 && strings.HasPrefix(name, "c-") && name[2] >= 'a' && name[2] <= 'z' {
+		key := tcell.KeyCtrlA + tcell.Key(name[2]-'a')
+		return tcell.NewEventKey(key, 0, tcell.ModCtrl), nil
+	}
+
+	return nil, fmt.Errorf("unrecognized key <%s>", name)
+}
+
+// ExecuteNormalModeKeys interprets keys as if the user typed them in normal mode,
+// similar to vim's ":normal" command. The keys use the notation accepted by ParseKeys.
+//
+// If the keys leave the editor in insert, visual, menu, or search mode,
+// this returns to normal mode as if the user pressed escape.
+// It returns an error if the keys cannot be parsed, or if they end with an incomplete command.
+func ExecuteNormalModeKeys(s *state.EditorState, keys string) error {
+	events, err := ParseKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	if executeKeysDepth >= maxExecuteKeysDepth {
+		return fmt.Errorf("exceeded max depth of %d nested key executions", maxExecuteKeysDepth)
+	}
+	executeKeysDepth++
+	defer func() { executeKeysDepth-- }()
+
+	log.Printf("Executing normal mode keys %q\n", keys)
+	state.SetInputMode(s, state.InputModeNormal)
+	inp := NewInterpreter()
+	for _, event := range events {
+		action := inp.ProcessEvent(event, ContextFromEditorState(s))
+		action(s)
+	}
+
+	incompleteInput := inp.InputBufferString(s.InputMode())
+
+	// Escape from any mode that would otherwise wait for more input.
+	// Escaping from the menu can return to visual mode, so this may take more than one try.
+	escEvent := tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)
+	for i := 0; i < 3 && needsEscapeToNormalMode(s.InputMode()); i++ {
+		action := inp.ProcessEvent(escEvent, ContextFromEditorState(s))
+		action(s)
+	}
+
+	if incompleteInput != "" {
+		return fmt.Errorf("incomplete command %q", incompleteInput)
+	}
+
+	return nil
+}
+
+// ExecuteNormalModeKeysOnLines executes keys with the cursor at the start of each line in a range,
+// similar to vim's ":{range}normal" command. A single undo reverts the changes to every line.
+//
+// Lines are visited from top to bottom. If the keys on a line add or delete lines,
+// the remaining lines in the range are adjusted so each original line is visited once,
+// assuming the changes are at or below the current line.
+// This stops at the first line where executing the keys returns an error.
+func ExecuteNormalModeKeysOnLines(s *state.EditorState, r state.LineRange, keys string) error {
+	var err error
+	state.GroupUndo(s, func(s *state.EditorState) {
+		lineNum, endLineNum := int64(r.StartLine), int64(r.EndLine)
+		for lineNum <= endLineNum && lineNum < int64(numLines(s)) {
+			targetLineNum := uint64(lineNum)
+			state.MoveCursor(s, func(p state.LocatorParams) uint64 {
+				return p.TextTree.LineStartPosition(targetLineNum)
+			})
+
+			numLinesBefore := int64(numLines(s))
+			if err = ExecuteNormalModeKeys(s, keys); err != nil {
+				return
+			}
+
+			// If the keys deleted the current line, the next line moved up to the current line number.
+			delta := int64(numLines(s)) - numLinesBefore
+			if delta < -1 {
+				delta = -1
+			}
+			lineNum += 1 + delta
+			endLineNum += int64(numLines(s)) - numLinesBefore
+		}
+	})
+	return err
+}
+
+func numLines(s *state.EditorState) uint64 {
+	return s.DocumentBuffer().TextTree().NumLines()
+}
+
+func needsEscapeToNormalMode(mode state.InputMode) bool {
+	switch mode {
+	case state.InputModeInsert, state.InputModeVisual, state.InputModeMenu, state.InputModeSearch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,268 @@
+package input
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/state"
+)
+
+func TestParseKeys(t *testing.T) {
+	testCases := []struct {
+		name        string
+		keys        string
+		expected    []*tcell.EventKey
+		expectedErr string
+	}{
+		{
+			name:     "empty",
+			keys:     "",
+			expected: nil,
+		},
+		{
+			name: "runes",
+			keys: "dw€",
+			expected: []*tcell.EventKey{
+				tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '€', tcell.ModNone),
+			},
+		},
+		{
+			name: "special keys",
+			keys: "ix<Esc><enter><tab><bs><lt><space><c-r>",
+			expected: []*tcell.EventKey{
+				tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyCtrlR, 0, tcell.ModCtrl),
+			},
+		},
+		{
+			name: "unclosed angle bracket",
+			keys: "f<",
+			expected: []*tcell.EventKey{
+				tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+				tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+			},
+		},
+		{
+			name:        "unrecognized special key",
+			keys:        "x<foo>",
+			expectedErr: "unrecognized key <foo>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := ParseKeys(tc.keys)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tc.expected), len(events))
+			for i := 0; i < len(events); i++ {
+				assert.Equal(t, tc.expected[i].Key(), events[i].Key())
+				assert.Equal(t, tc.expected[i].Rune(), events[i].Rune())
+			}
+		})
+	}
+}
+
+func TestExecuteNormalModeKeys(t *testing.T) {
+	testCases := []struct {
+		name              string
+		initialText       string
+		keys              string
+		expectedErr       string
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "delete line",
+			initialText:       "foo\nbar\nbaz",
+			keys:              "jdd",
+			expectedCursorPos: 4,
+			expectedText:      "foo\nbaz",
+		},
+		{
+			name:              "insert mode exits to normal mode",
+			initialText:       "foo",
+			keys:              "Abar",
+			expectedCursorPos: 5,
+			expectedText:      "foobar",
+		},
+		{
+			name:              "insert with special keys",
+			initialText:       "foo",
+			keys:              "ox<lt>y<esc>",
+			expectedCursorPos: 6,
+			expectedText:      "foo\nx<y",
+		},
+		{
+			name:              "visual mode exits to normal mode",
+			initialText:       "foo bar",
+			keys:              "vw",
+			expectedCursorPos: 4,
+			expectedText:      "foo bar",
+		},
+		{
+			name:              "undo",
+			initialText:       "foo bar",
+			keys:              "dwu",
+			expectedCursorPos: 0,
+			expectedText:      "foo bar",
+		},
+		{
+			name:              "nested execution from menu",
+			initialText:       "foo bar",
+			keys:              ":norm dw<lt>enter><enter>",
+			expectedCursorPos: 0,
+			expectedText:      "bar",
+		},
+		{
+			name:              "menu left open exits to normal mode",
+			initialText:       "foo",
+			keys:              ":quit",
+			expectedCursorPos: 0,
+			expectedText:      "foo",
+		},
+		{
+			name:              "incomplete command",
+			initialText:       "foo bar",
+			keys:              "xd",
+			expectedErr:       "incomplete command \"d\"",
+			expectedCursorPos: 0,
+			expectedText:      "oo bar",
+		},
+		{
+			name:              "unrecognized key",
+			initialText:       "foo",
+			keys:              "x<bogus>",
+			expectedErr:       "unrecognized key <bogus>",
+			expectedCursorPos: 0,
+			expectedText:      "foo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			editorState := editorStateWithText(t, tc.initialText)
+			err := ExecuteNormalModeKeys(editorState, tc.keys)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			buffer := editorState.DocumentBuffer()
+			assert.Equal(t, state.InputModeNormal, editorState.InputMode())
+			assert.Equal(t, tc.expectedCursorPos, buffer.CursorPosition())
+			assert.Equal(t, tc.expectedText, buffer.TextTree().String())
+		})
+	}
+}
+
+func TestExecuteNormalModeKeysOnLines(t *testing.T) {
+	testCases := []struct {
+		name              string
+		initialText       string
+		lineRange         state.LineRange
+		keys              string
+		expectedErr       string
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "append to each line",
+			initialText:       "foo\nbar\nbaz",
+			lineRange:         state.LineRange{StartLine: 0, EndLine: 2},
+			keys:              "A;",
+			expectedCursorPos: 13,
+			expectedText:      "foo;\nbar;\nbaz;",
+		},
+		{
+			name:              "insert at start of each line in range",
+			initialText:       "  foo\nbar\nbaz",
+			lineRange:         state.LineRange{StartLine: 0, EndLine: 1},
+			keys:              "i//<space><esc>",
+			expectedCursorPos: 11,
+			expectedText:      "//   foo\n// bar\nbaz",
+		},
+		{
+			name:              "delete each line",
+			initialText:       "foo\nbar\nbaz\nqux",
+			lineRange:         state.LineRange{StartLine: 1, EndLine: 2},
+			keys:              "dd",
+			expectedCursorPos: 4,
+			expectedText:      "foo\nqux",
+		},
+		{
+			name:              "add a line below each line",
+			initialText:       "foo\nbar\nbaz",
+			lineRange:         state.LineRange{StartLine: 0, EndLine: 1},
+			keys:              "o-<esc>",
+			expectedCursorPos: 10,
+			expectedText:      "foo\n-\nbar\n-\nbaz",
+		},
+		{
+			name:              "error stops execution",
+			initialText:       "foo\nbar",
+			lineRange:         state.LineRange{StartLine: 0, EndLine: 1},
+			keys:              "xd",
+			expectedErr:       "incomplete command \"d\"",
+			expectedCursorPos: 0,
+			expectedText:      "oo\nbar",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			editorState := editorStateWithText(t, tc.initialText)
+			err := ExecuteNormalModeKeysOnLines(editorState, tc.lineRange, tc.keys)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			buffer := editorState.DocumentBuffer()
+			assert.Equal(t, state.InputModeNormal, editorState.InputMode())
+			assert.Equal(t, tc.expectedCursorPos, buffer.CursorPosition())
+			assert.Equal(t, tc.expectedText, buffer.TextTree().String())
+
+			// A single undo reverts the changes to every line.
+			state.Undo(editorState)
+			assert.Equal(t, tc.initialText, buffer.TextTree().String())
+		})
+	}
+}
+
+func TestExecuteNormalModeKeysMaxDepth(t *testing.T) {
+	editorState := editorStateWithText(t, "foo")
+	executeKeysDepth = maxExecuteKeysDepth
+	defer func() { executeKeysDepth = 0 }()
+	err := ExecuteNormalModeKeys(editorState, "x")
+	assert.Error(t, err)
+	assert.Equal(t, "foo", editorState.DocumentBuffer().TextTree().String())
+}
+
+func editorStateWithText(t *testing.T, s string) *state.EditorState {
+	editorState := state.NewEditorState(100, 100, nil, nil)
+	tmpFile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	path := tmpFile.Name()
+	t.Cleanup(func() { os.Remove(path) })
+	err = os.WriteFile(path, []byte(s+"\n"), 0644)
+	require.NoError(t, err)
+	state.LoadDocument(editorState, path, false, func(state.LocatorParams) uint64 { return 0 })
+	return editorState
+}
@@ -1,8 +1,14 @@
 package input
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/menu"
 	"github.com/aretext/aretext/state"
+	"github.com/aretext/aretext/syntax"
 )
 
 func menuItems(ctx Context) []menu.Item {
@@ -21,9 +27,14 @@ func menuItems(ctx Context) []menu.Item {
 			Action:  state.Quit,
 		},
 		{
-			Name:    "save document",
-			Aliases: []string{"s", "w"},
-			Action: func(s *state.EditorState) {
+			Name:       "save document",
+			Aliases:    []string{"s", "w"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				if strings.TrimSpace(path) != "" {
+					state.WriteDocumentToPath(s, path, false)
+					return
+				}
 				state.AbortIfFileExistsWithChangedContent(s, state.SaveDocument)
 			},
 		},
@@ -38,9 +49,16 @@ func menuItems(ctx Context) []menu.Item {
 			},
 		},
 		{
-			Name:    "force save document",
-			Aliases: []string{"s!", "w!"},
-			Action:  state.SaveDocument,
+			Name:       "force save document",
+			Aliases:    []string{"s!", "w!"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				if strings.TrimSpace(path) != "" {
+					state.WriteDocumentToPath(s, path, true)
+					return
+				}
+				state.SaveDocument(s)
+			},
 		},
 		{
 			Name:    "force save document and quit",
@@ -50,6 +68,22 @@ func menuItems(ctx Context) []menu.Item {
 				state.Quit(s)
 			},
 		},
+		{
+			Name:       "save document as",
+			Aliases:    []string{"saveas"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				state.SaveDocumentAs(s, path, false)
+			},
+		},
+		{
+			Name:       "force save document as",
+			Aliases:    []string{"saveas!"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				state.SaveDocumentAs(s, path, true)
+			},
+		},
 		{
 			Name:    "force reload",
 			Aliases: []string{"r!"},
@@ -59,27 +93,82 @@ func menuItems(ctx Context) []menu.Item {
 			Name:    "find and open",
 			Aliases: []string{"f"},
 			Action: func(s *state.EditorState) {
-				state.AbortIfUnsavedChanges(s, ShowFileMenu(ctx), true)
+				state.AutoWriteOrAbortIfUnsavedChanges(s, ShowFileMenu(ctx), true)
 			},
 		},
 		{
 			Name:    "open previous document",
 			Aliases: []string{"p"},
 			Action: func(s *state.EditorState) {
-				state.AbortIfUnsavedChanges(s, state.LoadPrevDocument, true)
+				state.AutoWriteOrAbortIfUnsavedChanges(s, state.LoadPrevDocument, true)
 			},
 		},
 		{
 			Name:    "open next document",
 			Aliases: []string{"n"},
 			Action: func(s *state.EditorState) {
-				state.AbortIfUnsavedChanges(s, state.LoadNextDocument, true)
+				state.AutoWriteOrAbortIfUnsavedChanges(s, state.LoadNextDocument, true)
 			},
 		},
 		{
-			Name:    "child directory",
-			Aliases: []string{"cd"},
+			Name:    "open alternate document",
+			Aliases: []string{"e#"},
+			Action:  LoadAlternateDocument,
+		},
+		{
+			Name:       "edit document",
+			Aliases:    []string{"e", "edit"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				state.AutoWriteOrAbortIfUnsavedChanges(s, func(s *state.EditorState) {
+					state.EditDocument(s, path)
+				}, true)
+			},
+		},
+		{
+			Name:    "quickfix list",
+			Aliases: []string{"cl", "clist"},
+			Action:  state.ShowQuickfixMenu,
+		},
+		{
+			Name:    "quickfix next",
+			Aliases: []string{"cn", "cnext"},
+			Action: func(s *state.EditorState) {
+				state.GoToNextQuickfixEntry(s, false)
+			},
+		},
+		{
+			Name:    "quickfix prev",
+			Aliases: []string{"cp", "cprev"},
 			Action: func(s *state.EditorState) {
+				state.GoToNextQuickfixEntry(s, true)
+			},
+		},
+		{
+			Name:       "save session",
+			Aliases:    []string{"mks", "mksession"},
+			AcceptsArg: true,
+			Action:     state.SaveSession,
+		},
+		{
+			Name:       "load session",
+			Aliases:    []string{"so", "source"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, path string) {
+				state.AbortIfUnsavedChanges(s, func(s *state.EditorState) {
+					state.LoadSession(s, path)
+				}, true)
+			},
+		},
+		{
+			Name:       "child directory",
+			Aliases:    []string{"cd"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, dirPath string) {
+				if strings.TrimSpace(dirPath) != "" {
+					state.SetWorkingDirectory(s, dirPath)
+					return
+				}
 				state.ShowChildDirsMenu(s, ctx.DirPatternsToHide)
 			},
 		},
@@ -88,6 +177,11 @@ func menuItems(ctx Context) []menu.Item {
 			Aliases: []string{"pd"},
 			Action:  state.ShowParentDirsMenu,
 		},
+		{
+			Name:    "print working directory",
+			Aliases: []string{"pwd"},
+			Action:  state.ShowWorkingDirectory,
+		},
 		{
 			Name:    "toggle show tabs",
 			Aliases: []string{"ta"},
@@ -113,6 +207,176 @@ func menuItems(ctx Context) []menu.Item {
 			Aliases: []string{"ai"},
 			Action:  state.ToggleAutoIndent,
 		},
+		{
+			Name:    "toggle virtual edit",
+			Aliases: []string{"ve"},
+			Action:  state.ToggleVirtualEdit,
+		},
+		{
+			Name:    "toggle search highlight",
+			Aliases: []string{"hls"},
+			Action:  state.ToggleSearchHighlight,
+		},
+		{
+			Name:    "clear search highlight",
+			Aliases: []string{"noh", "nohlsearch"},
+			Action:  state.ClearSearchHighlight,
+		},
+		{
+			Name:       "set option",
+			Aliases:    []string{"set"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, args string) {
+				state.SetOptions(s, args, false)
+			},
+		},
+		{
+			Name:       "set local option",
+			Aliases:    []string{"setl", "setlocal"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, args string) {
+				state.SetOptions(s, args, true)
+			},
+		},
+		{
+			Name:    "inspect syntax token",
+			Aliases: []string{"tok", "token"},
+			Action:  state.InspectSyntaxToken,
+		},
+		{
+			Name:    "show messages",
+			Aliases: []string{"mes", "messages"},
+			Action:  state.ShowStatusMsgHistory,
+		},
+		{
+			Name:    "show undo list",
+			Aliases: []string{"undol", "undolist"},
+			Action:  state.ShowUndoListMenu,
+		},
+		{
+			Name:    "refresh git diff",
+			Aliases: []string{"gd"},
+			Action:  state.RefreshGitDiff,
+		},
+		{
+			Name:    "revert git hunk",
+			Aliases: []string{"gr"},
+			Action:  state.RevertGitHunk,
+		},
+		{
+			Name:       "insert date",
+			Aliases:    []string{"date"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, format string) {
+				state.InsertDate(s, time.Now(), format)
+			},
+		},
+		{
+			Name:       "insert time",
+			Aliases:    []string{"time"},
+			AcceptsArg: true,
+			Action: func(s *state.EditorState, format string) {
+				state.InsertTime(s, time.Now(), format)
+			},
+		},
+		{
+			Name:    "insert file name",
+			Aliases: []string{"filename"},
+			Action:  state.InsertFileName,
+		},
+	}
+
+	// Line commands accept a range like "1,5d" and default to the selected lines or the current line.
+	items = append(items, []menu.Item{
+		{
+			Name:         "delete lines",
+			Aliases:      []string{"d", "delete"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			Action: func(s *state.EditorState, r state.LineRange, register string) {
+				if page, ok := clipboardPageForRegister(s, register); ok {
+					state.DeleteLineRange(s, r, page)
+				}
+			},
+		},
+		{
+			Name:         "yank lines",
+			Aliases:      []string{"y", "yank"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			Action: func(s *state.EditorState, r state.LineRange, register string) {
+				if page, ok := clipboardPageForRegister(s, register); ok {
+					state.YankLineRange(s, r, page)
+				}
+			},
+		},
+		// In normal mode, "m" alone records a macro, but "m" with a range or target line moves lines.
+		{
+			Name:         "move lines",
+			Aliases:      []string{"m", "mo", "move"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			Action:       state.MoveLineRange,
+		},
+		{
+			Name:         "copy lines",
+			Aliases:      []string{"t", "co", "copy"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			Action:       state.CopyLineRange,
+		},
+	}...)
+
+	items = append(items, sortMenuItems()...)
+
+	items = append(items, menu.Item{
+		Name:         "execute normal mode keys",
+		Aliases:      []string{"norm", "normal"},
+		AcceptsArg:   true,
+		AcceptsRange: true,
+		Action: func(s *state.EditorState, r *state.LineRange, keys string) {
+			var err error
+			if r != nil {
+				err = ExecuteNormalModeKeysOnLines(s, *r, keys)
+			} else {
+				err = ExecuteNormalModeKeys(s, keys)
+			}
+			if err != nil {
+				state.SetStatusMsg(s, state.StatusMsg{
+					Style: state.StatusMsgStyleError,
+					Text:  fmt.Sprintf("Could not execute keys: %s", err),
+				})
+			}
+		},
+	})
+
+	if state.LanguageHasOutline(ctx.SyntaxLanguage) {
+		items = append(items, menu.Item{
+			Name:    "show outline",
+			Aliases: []string{"ol", "outline"},
+			Action:  state.ShowOutlineMenu,
+		})
+	}
+
+	// Commands for editing the todo list of a git interactive rebase.
+	if ctx.SyntaxLanguage == syntax.LanguageGitRebase {
+		items = append(items, []menu.Item{
+			{
+				Name:    "cycle rebase action",
+				Aliases: []string{"ra"},
+				Action:  state.CycleGitRebaseAction,
+			},
+			{
+				Name:    "move rebase commit up",
+				Aliases: []string{"rk"},
+				Action:  state.MoveLineUp,
+			},
+			{
+				Name:    "move rebase commit down",
+				Aliases: []string{"rj"},
+				Action:  state.MoveLineDown,
+			},
+		}...)
 	}
 
 	// User-defined macros are available only in normal mode, not visual mode.
@@ -135,3 +399,83 @@ func menuItems(ctx Context) []menu.Item {
 
 	return items
 }
+
+// clipboardPageForRegister returns the clipboard page for a register typed after a line command, like the "a" in "d a".
+// An empty register is the default page. If the register is not a letter from "a" to "z",
+// this sets an error status and returns false.
+func clipboardPageForRegister(s *state.EditorState, register string) (clipboard.PageId, bool) {
+	register = strings.TrimSpace(register)
+	if register == "" {
+		return clipboard.PageDefault, true
+	}
+
+	if r := []rune(register); len(r) == 1 {
+		if page := clipboard.PageIdForLetter(r[0]); page != clipboard.PageNull {
+			return page, true
+		}
+	}
+
+	state.SetStatusMsg(s, state.StatusMsg{
+		Style: state.StatusMsgStyleError,
+		Text:  fmt.Sprintf("Invalid register %q", register),
+	})
+	return clipboard.PageNull, false
+}
+
+// sortMenuItems returns menu items that sort lines, following vim's ":sort" syntax.
+// "sort!" sorts in reverse order, and both accept flags in any order (for example, "sort n" or "sort! ui").
+func sortMenuItems() []menu.Item {
+	sortAction := func(reverse bool) func(*state.EditorState, state.LineRange, string) {
+		return func(s *state.EditorState, r state.LineRange, flags string) {
+			opts, err := parseSortFlags(flags)
+			if err != nil {
+				state.SetStatusMsg(s, state.StatusMsg{
+					Style: state.StatusMsgStyleError,
+					Text:  err.Error(),
+				})
+				return
+			}
+			opts.Reverse = reverse
+			state.SortLines(s, r.StartLine, r.EndLine, opts)
+		}
+	}
+
+	return []menu.Item{
+		{
+			Name:         "sort lines",
+			Aliases:      []string{"sort"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			DefaultRange: "%",
+			Action:       sortAction(false),
+		},
+		{
+			Name:         "sort lines (reverse)",
+			Aliases:      []string{"sort!"},
+			AcceptsRange: true,
+			AcceptsArg:   true,
+			DefaultRange: "%",
+			Action:       sortAction(true),
+		},
+	}
+}
+
+// parseSortFlags parses vim-style sort flags: "n" (numeric), "i" (ignore case), and "u" (unique).
+func parseSortFlags(flags string) (state.SortOptions, error) {
+	var opts state.SortOptions
+	for _, r := range flags {
+		switch r {
+		case 'n':
+			opts.Numeric = true
+		case 'i':
+			opts.IgnoreCase = true
+		case 'u':
+			opts.Unique = true
+		case ' ':
+			// Allow spaces between flags, as in "sort n u".
+		default:
+			return opts, fmt.Errorf("Invalid sort flag %q", r)
+		}
+	}
+	return opts, nil
+}
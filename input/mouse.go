@@ -0,0 +1,66 @@
+package input
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/aretext/aretext/state"
+)
+
+// mouseScrollLines is the number of lines to scroll for each movement of the scroll wheel.
+const mouseScrollLines = 3
+
+// mouseState tracks the primary mouse button across events to detect drags.
+type mouseState struct {
+	buttonDown  bool
+	pressInView bool
+	pressCol    int
+	pressRow    int
+}
+
+func (inp *Interpreter) processMouseEvent(event *tcell.EventMouse, ctx Context) Action {
+	if ctx.InputMode != state.InputModeNormal && ctx.InputMode != state.InputModeInsert && ctx.InputMode != state.InputModeVisual {
+		// Ignore the mouse in menus, search, and while a task is running.
+		inp.mouse = mouseState{}
+		return EmptyAction
+	}
+
+	col, row := event.Position()
+	buttons := event.Buttons()
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		return MouseScroll(state.ScrollDirectionBackward, mouseScrollLines)
+
+	case buttons&tcell.WheelDown != 0:
+		return MouseScroll(state.ScrollDirectionForward, mouseScrollLines)
+
+	case buttons&tcell.Button1 != 0 && !inp.mouse.buttonDown:
+		// ScrollLines is the height of the document view, so a click below it is in the status bar.
+		inp.mouse = mouseState{
+			buttonDown:  true,
+			pressInView: col >= 0 && row >= 0 && uint64(row) < ctx.ScrollLines,
+			pressCol:    col,
+			pressRow:    row,
+		}
+		if !inp.mouse.pressInView {
+			return EmptyAction
+		}
+		return MouseClick(uint64(col), uint64(row))
+
+	case buttons&tcell.Button1 != 0:
+		if !inp.mouse.pressInView || col < 0 || row < 0 || (col == inp.mouse.pressCol && row == inp.mouse.pressRow) {
+			return EmptyAction
+		}
+
+		if ctx.InputMode == state.InputModeInsert {
+			// Dragging in insert mode moves the cursor without selecting.
+			return MouseClick(uint64(col), uint64(row))
+		}
+		return MouseDrag(uint64(col), uint64(row))
+
+	default:
+		// The button was released.
+		inp.mouse = mouseState{}
+		return EmptyAction
+	}
+}
@@ -153,12 +153,13 @@ func PrevMatchingCharInLine(tree *text.Tree, char rune, count uint64, includeCha
 
 // PrevAutoIndent locates the previous tab stop if autoIndent is enabled.
 // If autoIndent is disabled or the characters before the cursor are not spaces/tabs, it returns the original position.
-func PrevAutoIndent(tree *text.Tree, autoIndentEnabled bool, tabSize uint64, pos uint64) uint64 {
+// Tab stops are determined by tabSize and varTabStops, as in cellwidth.TabWidth.
+func PrevAutoIndent(tree *text.Tree, autoIndentEnabled bool, tabSize uint64, varTabStops []uint64, pos uint64) uint64 {
 	if !autoIndentEnabled {
 		return pos
 	}
 
-	prevTabAlignedPos := findPrevTabAlignedPos(tree, tabSize, pos)
+	prevTabAlignedPos := findPrevTabAlignedPos(tree, tabSize, varTabStops, pos)
 	prevWhitespaceStartPos := findPrevWhitespaceStartPos(tree, tabSize, pos)
 	if prevTabAlignedPos < prevWhitespaceStartPos {
 		return prevWhitespaceStartPos
@@ -167,7 +168,7 @@ func PrevAutoIndent(tree *text.Tree, autoIndentEnabled bool, tabSize uint64, pos
 	}
 }
 
-func findPrevTabAlignedPos(tree *text.Tree, tabSize uint64, startPos uint64) uint64 {
+func findPrevTabAlignedPos(tree *text.Tree, tabSize uint64, varTabStops []uint64, startPos uint64) uint64 {
 	pos := StartOfLineAtPos(tree, startPos)
 	reader := tree.ReaderAtPosition(pos)
 	iter := segment.NewGraphemeClusterIter(reader)
@@ -175,7 +176,7 @@ func findPrevTabAlignedPos(tree *text.Tree, tabSize uint64, startPos uint64) uin
 	var offset uint64
 	lastAlignedPos := pos
 	for pos < startPos {
-		if offset%tabSize == 0 {
+		if cellwidth.IsTabStop(offset, tabSize, varTabStops) {
 			lastAlignedPos = pos
 		}
 		err := iter.NextSegment(seg)
@@ -184,7 +185,7 @@ func findPrevTabAlignedPos(tree *text.Tree, tabSize uint64, startPos uint64) uin
 		} else if err != nil {
 			panic(err)
 		}
-		offset += cellwidth.GraphemeClusterWidth(seg.Runes(), offset, tabSize)
+		offset += cellwidth.GraphemeClusterWidthWithTabStops(seg.Runes(), offset, tabSize, varTabStops)
 		pos += seg.NumRunes()
 	}
 	return lastAlignedPos
@@ -189,6 +189,13 @@ func TestPrevCharInLine(t *testing.T) {
 			count:       1,
 			expectedPos: 2,
 		},
+		{
+			name:        "multiple combining marks on one base character",
+			inputString: "xa\u0301\u0302\u0323y",
+			pos:         5,
+			count:       1,
+			expectedPos: 1,
+		},
 		{
 			name:        "move cursor multiple chars within line",
 			inputString: "abcdefgh",
@@ -529,6 +536,7 @@ func TestPrevAutoIndent(t *testing.T) {
 		name              string
 		inputString       string
 		autoIndentEnabled bool
+		varTabStops       []uint64
 		pos               uint64
 		expectedPos       uint64
 	}{
@@ -593,6 +601,13 @@ func TestPrevAutoIndent(t *testing.T) {
 			pos:               3,
 			expectedPos:       3,
 		},
+		{
+			name:              "combining marks after indentation, autoindent enabled",
+			inputString:       "    e\u0301\u0302",
+			autoIndentEnabled: true,
+			pos:               7,
+			expectedPos:       7,
+		},
 		{
 			name:              "spaces within line aligned, autoindent enabled",
 			inputString:       "abcd    ef",
@@ -628,13 +643,29 @@ func TestPrevAutoIndent(t *testing.T) {
 			pos:               8,
 			expectedPos:       6,
 		},
+		{
+			name:              "spaces to var tab stop, autoindent enabled",
+			inputString:       "          ",
+			autoIndentEnabled: true,
+			varTabStops:       []uint64{2, 8},
+			pos:               10,
+			expectedPos:       2,
+		},
+		{
+			name:              "spaces to first var tab stop, autoindent enabled",
+			inputString:       "  ",
+			autoIndentEnabled: true,
+			varTabStops:       []uint64{2, 8},
+			pos:               2,
+			expectedPos:       0,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			textTree, err := text.NewTreeFromString(tc.inputString)
 			require.NoError(t, err)
-			actualPos := PrevAutoIndent(textTree, tc.autoIndentEnabled, 4, tc.pos)
+			actualPos := PrevAutoIndent(textTree, tc.autoIndentEnabled, 4, tc.varTabStops, tc.pos)
 			assert.Equal(t, tc.expectedPos, actualPos)
 		})
 	}
@@ -5,30 +5,37 @@ import (
 	"github.com/aretext/aretext/text"
 )
 
-// MatchingCodeBlockDelimiter locates the matching paren, brace, or bracket at a position, if it exists.
-func MatchingCodeBlockDelimiter(textTree *text.Tree, syntaxParser *parser.P, pos uint64) (uint64, bool) {
+// DelimiterPair is an open and close delimiter, such as a paren.
+type DelimiterPair struct {
+	Open  rune
+	Close rune
+}
+
+// DefaultDelimiterPairs are the parens, brackets, and braces that delimit code blocks.
+var DefaultDelimiterPairs = []DelimiterPair{
+	{Open: '(', Close: ')'},
+	{Open: '[', Close: ']'},
+	{Open: '{', Close: '}'},
+}
+
+// MatchingCodeBlockDelimiter locates the matching delimiter from one of the pairs at a position, if it exists.
+func MatchingCodeBlockDelimiter(textTree *text.Tree, syntaxParser *parser.P, pos uint64, pairs []DelimiterPair) (uint64, bool) {
 	reader := textTree.ReaderAtPosition(pos)
 	r, _, err := reader.ReadRune()
-	if err != nil || !(r == '(' || r == ')' || r == '{' || r == '}' || r == '[' || r == ']') {
+	if err != nil {
 		return 0, false
 	}
 
-	switch r {
-	case '(':
-		return searchForwardMatch(textTree, syntaxParser, pos, '(', ')')
-	case ')':
-		return searchBackwardMatch(textTree, syntaxParser, pos, '(', ')')
-	case '[':
-		return searchForwardMatch(textTree, syntaxParser, pos, '[', ']')
-	case ']':
-		return searchBackwardMatch(textTree, syntaxParser, pos, '[', ']')
-	case '{':
-		return searchForwardMatch(textTree, syntaxParser, pos, '{', '}')
-	case '}':
-		return searchBackwardMatch(textTree, syntaxParser, pos, '{', '}')
-	default:
-		return 0, false
+	for _, pair := range pairs {
+		switch r {
+		case pair.Open:
+			return searchForwardMatch(textTree, syntaxParser, pos, pair.Open, pair.Close)
+		case pair.Close:
+			return searchBackwardMatch(textTree, syntaxParser, pos, pair.Open, pair.Close)
+		}
 	}
+
+	return 0, false
 }
 
 // NextUnmatchedCloseBrace locates the next unmatched close brace after a position.
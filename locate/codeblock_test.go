@@ -17,6 +17,7 @@ func TestMatchingCodeBlockDelimiter(t *testing.T) {
 		inputString    string
 		pos            uint64
 		syntaxLanguage syntax.Language
+		pairs          []DelimiterPair // Defaults to DefaultDelimiterPairs if nil.
 		expectMatch    bool
 		expectPos      uint64
 	}{
@@ -59,6 +60,28 @@ func TestMatchingCodeBlockDelimiter(t *testing.T) {
 			expectMatch: true,
 			expectPos:   10,
 		},
+		{
+			name:        "match custom pair",
+			inputString: "foo <bar <baz>> qux",
+			pos:         4,
+			pairs:       []DelimiterPair{{Open: '<', Close: '>'}},
+			expectMatch: true,
+			expectPos:   14,
+		},
+		{
+			name:        "do not match delimiter missing from custom pairs",
+			inputString: "foo ( bar ) baz",
+			pos:         4,
+			pairs:       []DelimiterPair{{Open: '<', Close: '>'}},
+			expectMatch: false,
+		},
+		{
+			name:        "do not match with no pairs",
+			inputString: "foo ( bar ) baz",
+			pos:         4,
+			pairs:       []DelimiterPair{},
+			expectMatch: false,
+		},
 		{
 			name:        "match with nesting",
 			inputString: "Lorem (ipsum (dolor (sit (amet) consectetur) adipiscing) elit) sed",
@@ -125,13 +148,17 @@ func TestMatchingCodeBlockDelimiter(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			textTree, syntaxParser := textTreeAndSyntaxParser(t, tc.inputString, tc.syntaxLanguage)
-			actualPos, ok := MatchingCodeBlockDelimiter(textTree, syntaxParser, tc.pos)
+			pairs := tc.pairs
+			if pairs == nil {
+				pairs = DefaultDelimiterPairs
+			}
+			actualPos, ok := MatchingCodeBlockDelimiter(textTree, syntaxParser, tc.pos, pairs)
 			assert.Equal(t, tc.expectMatch, ok)
 			if ok {
 				assert.Equal(t, tc.expectPos, actualPos)
 
 				// Verify that we get back the original position from the matched position.
-				originalPos, ok := MatchingCodeBlockDelimiter(textTree, syntaxParser, actualPos)
+				originalPos, ok := MatchingCodeBlockDelimiter(textTree, syntaxParser, actualPos, pairs)
 				assert.True(t, ok)
 				assert.Equal(t, tc.pos, originalPos)
 			}
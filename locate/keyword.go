@@ -0,0 +1,264 @@
+package locate
+
+import (
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/syntax/parser"
+	"github.com/aretext/aretext/text"
+)
+
+// KeywordGroup is a set of keywords that delimit a block, such as "if", "else", and "fi" in a shell script.
+// A block starts with an Open keyword and ends with a Close keyword, with any number of Middle keywords in between.
+// A LineStartOpen keyword starts a block only when it is the first word on its line,
+// so Ruby's "if" opens a block in "if x" but not in "return if x".
+type KeywordGroup struct {
+	Open          []string
+	LineStartOpen []string
+	Middle        []string
+	Close         []string
+}
+
+type keywordRole int
+
+const (
+	keywordRoleNone = keywordRole(iota)
+	keywordRoleOpen
+	keywordRoleMiddle
+	keywordRoleClose
+)
+
+// role returns the role of the keyword that starts at pos.
+func (g KeywordGroup) role(textTree *text.Tree, pos uint64, word string) keywordRole {
+	for _, kw := range g.Open {
+		if kw == word {
+			return keywordRoleOpen
+		}
+	}
+	for _, kw := range g.LineStartOpen {
+		if kw == word {
+			if NextNonWhitespaceOrNewline(textTree, StartOfLineAtPos(textTree, pos)) == pos {
+				return keywordRoleOpen
+			}
+			return keywordRoleNone
+		}
+	}
+	for _, kw := range g.Middle {
+		if kw == word {
+			return keywordRoleMiddle
+		}
+	}
+	for _, kw := range g.Close {
+		if kw == word {
+			return keywordRoleClose
+		}
+	}
+	return keywordRoleNone
+}
+
+// keywordMatcher finds keywords in the text.
+// A keyword must be an entire run of keyword runes, so "if" matches in "if (x)" but not in "gif" or "if_x".
+type keywordMatcher struct {
+	extraRunes   map[rune]struct{} // Runes in keywords that are not letters, digits, or underscores, such as "#" in "#if".
+	maxRuneCount int
+}
+
+func newKeywordMatcher(groups []KeywordGroup) keywordMatcher {
+	m := keywordMatcher{extraRunes: make(map[rune]struct{})}
+	for _, g := range groups {
+		for _, keywords := range [][]string{g.Open, g.LineStartOpen, g.Middle, g.Close} {
+			for _, kw := range keywords {
+				for _, r := range kw {
+					if !isKeywordWordRune(r) {
+						m.extraRunes[r] = struct{}{}
+					}
+				}
+				if n := utf8.RuneCountInString(kw); n > m.maxRuneCount {
+					m.maxRuneCount = n
+				}
+			}
+		}
+	}
+	return m
+}
+
+func isKeywordWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func (m keywordMatcher) isKeywordRune(r rune) bool {
+	if isKeywordWordRune(r) {
+		return true
+	}
+	_, ok := m.extraRunes[r]
+	return ok
+}
+
+// MatchingKeyword locates the keyword that pairs with the keyword at a position, like vim's matchit plugin.
+// From an Open or Middle keyword, it locates the next Middle or Close keyword in the same block.
+// From a Close keyword, it locates the Open keyword that starts the block.
+// Blocks can be nested, and keywords in a different string or comment than the starting keyword are ignored.
+// The returned position is the start of the matching keyword.
+func MatchingKeyword(textTree *text.Tree, syntaxParser *parser.P, pos uint64, groups []KeywordGroup) (uint64, bool) {
+	if len(groups) == 0 {
+		return 0, false
+	}
+
+	m := newKeywordMatcher(groups)
+	startPos, word := m.keywordAtPos(textTree, pos)
+	if word == "" {
+		return 0, false
+	}
+
+	for _, g := range groups {
+		switch g.role(textTree, startPos, word) {
+		case keywordRoleOpen, keywordRoleMiddle:
+			return m.searchForwardKeyword(textTree, syntaxParser, startPos, uint64(utf8.RuneCountInString(word)), g)
+		case keywordRoleClose:
+			return m.searchBackwardKeyword(textTree, syntaxParser, startPos, g)
+		}
+	}
+
+	return 0, false
+}
+
+// keywordAtPos returns the start position and text of the run of keyword runes containing pos.
+// If the run is longer than any keyword, this returns an empty string.
+func (m keywordMatcher) keywordAtPos(textTree *text.Tree, pos uint64) (uint64, string) {
+	var afterPos []rune
+	reader := textTree.ReaderAtPosition(pos)
+	for len(afterPos) <= m.maxRuneCount {
+		r, _, err := reader.ReadRune()
+		if err != nil || !m.isKeywordRune(r) {
+			break
+		}
+		afterPos = append(afterPos, r)
+	}
+
+	if len(afterPos) == 0 {
+		return 0, ""
+	}
+
+	var beforePos []rune
+	reverseReader := textTree.ReverseReaderAtPosition(pos)
+	for len(beforePos)+len(afterPos) <= m.maxRuneCount {
+		r, _, err := reverseReader.ReadRune()
+		if err != nil || !m.isKeywordRune(r) {
+			break
+		}
+		beforePos = append(beforePos, r)
+	}
+
+	if len(beforePos)+len(afterPos) > m.maxRuneCount {
+		return 0, ""
+	}
+
+	// The runes before pos were read in reverse order.
+	runes := make([]rune, 0, len(beforePos)+len(afterPos))
+	for i := len(beforePos) - 1; i >= 0; i-- {
+		runes = append(runes, beforePos[i])
+	}
+	runes = append(runes, afterPos...)
+	return pos - uint64(len(beforePos)), string(runes)
+}
+
+func (m keywordMatcher) searchForwardKeyword(textTree *text.Tree, syntaxParser *parser.P, startPos uint64, numRunes uint64, g KeywordGroup) (uint64, bool) {
+	startToken := stringOrCommentTokenAtPos(syntaxParser, startPos)
+	pos := startPos + numRunes
+	reader := textTree.ReaderAtPosition(pos)
+	var depth int
+	var run []rune
+	var tooLong bool
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil && err != io.EOF {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		if err == nil && m.isKeywordRune(r) {
+			if len(run) < m.maxRuneCount {
+				run = append(run, r)
+			} else {
+				tooLong = true
+			}
+			pos++
+			continue
+		}
+
+		if len(run) > 0 && !tooLong {
+			runStartPos := pos - uint64(len(run))
+			if startToken == stringOrCommentTokenAtPos(syntaxParser, runStartPos) {
+				switch g.role(textTree, runStartPos, string(run)) {
+				case keywordRoleOpen:
+					depth++
+				case keywordRoleMiddle:
+					if depth == 0 {
+						return runStartPos, true
+					}
+				case keywordRoleClose:
+					if depth == 0 {
+						return runStartPos, true
+					}
+					depth--
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return 0, false
+		}
+
+		run, tooLong = run[:0], false
+		pos++
+	}
+}
+
+func (m keywordMatcher) searchBackwardKeyword(textTree *text.Tree, syntaxParser *parser.P, startPos uint64, g KeywordGroup) (uint64, bool) {
+	startToken := stringOrCommentTokenAtPos(syntaxParser, startPos)
+	pos := startPos
+	reader := textTree.ReverseReaderAtPosition(pos)
+	var depth int
+	var run []rune // In reverse order.
+	var tooLong bool
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil && err != io.EOF {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		if err == nil && m.isKeywordRune(r) {
+			if len(run) < m.maxRuneCount {
+				run = append(run, r)
+			} else {
+				tooLong = true
+			}
+			pos--
+			continue
+		}
+
+		if len(run) > 0 && !tooLong && startToken == stringOrCommentTokenAtPos(syntaxParser, pos) {
+			word := make([]rune, 0, len(run))
+			for i := len(run) - 1; i >= 0; i-- {
+				word = append(word, run[i])
+			}
+
+			switch g.role(textTree, pos, string(word)) {
+			case keywordRoleOpen:
+				if depth == 0 {
+					return pos, true
+				}
+				depth--
+			case keywordRoleClose:
+				depth++
+			}
+		}
+
+		if err == io.EOF {
+			return 0, false
+		}
+
+		run, tooLong = run[:0], false
+		pos--
+	}
+}
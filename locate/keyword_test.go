@@ -0,0 +1,248 @@
+package locate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aretext/aretext/syntax"
+)
+
+func TestMatchingKeyword(t *testing.T) {
+	shellGroups := []KeywordGroup{
+		{Open: []string{"if"}, Middle: []string{"elif", "else"}, Close: []string{"fi"}},
+		{Open: []string{"do"}, Close: []string{"done"}},
+	}
+
+	preprocessorGroups := []KeywordGroup{
+		{Open: []string{"#if", "#ifdef", "#ifndef"}, Middle: []string{"#elif", "#else"}, Close: []string{"#endif"}},
+	}
+
+	rubyGroups := []KeywordGroup{
+		{
+			Open:          []string{"def", "do"},
+			LineStartOpen: []string{"if", "unless"},
+			Middle:        []string{"elsif", "else"},
+			Close:         []string{"end"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		inputString    string
+		pos            uint64
+		groups         []KeywordGroup
+		syntaxLanguage syntax.Language
+		expectMatch    bool
+		expectPos      uint64
+	}{
+		{
+			name:        "empty",
+			inputString: "",
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: false,
+		},
+		{
+			name:        "no groups",
+			inputString: "if x; then y; fi",
+			pos:         0,
+			expectMatch: false,
+		},
+		{
+			name:        "not on keyword",
+			inputString: "if x; then y; fi",
+			pos:         3,
+			groups:      shellGroups,
+			expectMatch: false,
+		},
+		{
+			name:        "keyword is part of a longer word",
+			inputString: "gif x; fi",
+			pos:         1,
+			groups:      shellGroups,
+			expectMatch: false,
+		},
+		{
+			name:        "open to close",
+			inputString: "if x; then y; fi",
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   14,
+		},
+		{
+			name:        "from the middle of a keyword",
+			inputString: "if x; then y; fi",
+			pos:         1,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   14,
+		},
+		{
+			name:        "close to open",
+			inputString: "if x; then y; fi",
+			pos:         15,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   0,
+		},
+		{
+			name:        "open to middle",
+			inputString: "if x; then y; else z; fi",
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   14,
+		},
+		{
+			name:        "middle to middle",
+			inputString: "if x; then y; elif z; then w; else v; fi",
+			pos:         14,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   30,
+		},
+		{
+			name:        "middle to close",
+			inputString: "if x; then y; else z; fi",
+			pos:         14,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   22,
+		},
+		{
+			name: "nested open to close",
+			inputString: `if a; then
+	if b; then
+		c
+	fi
+fi`,
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   31,
+		},
+		{
+			name: "nested close to open",
+			inputString: `if a; then
+	if b; then
+		c
+	fi
+fi`,
+			pos:         31,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   0,
+		},
+		{
+			name: "nested skips middle of inner block",
+			inputString: `if a; then
+	if b; then
+		c
+	else
+		d
+	fi
+else
+	e
+fi`,
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   41,
+		},
+		{
+			name:        "different group",
+			inputString: "for x in y; do if z; then w; fi; done",
+			pos:         12,
+			groups:      shellGroups,
+			expectMatch: true,
+			expectPos:   33,
+		},
+		{
+			name:        "open with no close",
+			inputString: "if x; then y",
+			pos:         0,
+			groups:      shellGroups,
+			expectMatch: false,
+		},
+		{
+			name: "preprocessor directives",
+			inputString: `#ifdef FOO
+#if BAR
+#endif
+#else
+#endif`,
+			pos:         0,
+			groups:      preprocessorGroups,
+			expectMatch: true,
+			expectPos:   26,
+		},
+		{
+			name: "line start open to close",
+			inputString: `def f(x)
+  if x
+    y
+  end
+end`,
+			pos:         11,
+			groups:      rubyGroups,
+			expectMatch: true,
+			expectPos:   24,
+		},
+		{
+			name: "modifier if inside def, open to close",
+			inputString: `def f(x)
+  return 1 if x
+  y
+end`,
+			pos:         0,
+			groups:      rubyGroups,
+			expectMatch: true,
+			expectPos:   29,
+		},
+		{
+			name: "modifier if inside def, close to open",
+			inputString: `def f(x)
+  return 1 if x
+  y
+end`,
+			pos:         29,
+			groups:      rubyGroups,
+			expectMatch: true,
+			expectPos:   0,
+		},
+		{
+			name: "modifier if is not a keyword",
+			inputString: `def f(x)
+  return 1 if x
+end`,
+			pos:         20,
+			groups:      rubyGroups,
+			expectMatch: false,
+		},
+		{
+			name: "ignore keywords in Go comments and strings",
+			inputString: `if x {
+	// fi
+	y := "fi"
+} fi`,
+			pos:            0,
+			groups:         shellGroups,
+			syntaxLanguage: syntax.LanguageGo,
+			expectMatch:    true,
+			expectPos:      27,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, syntaxParser := textTreeAndSyntaxParser(t, tc.inputString, tc.syntaxLanguage)
+			actualPos, ok := MatchingKeyword(textTree, syntaxParser, tc.pos, tc.groups)
+			assert.Equal(t, tc.expectMatch, ok)
+			if ok {
+				assert.Equal(t, tc.expectPos, actualPos)
+			}
+		})
+	}
+}
@@ -106,6 +106,16 @@ func StartOfLineNum(tree *text.Tree, lineNum uint64) uint64 {
 	return tree.LineStartPosition(ClosestValidLineNum(tree, lineNum))
 }
 
+// StartOfLineAtPercent locates the start of the line the given percentage of the way through the document.
+// Like vim's "N%" command, the target line (1-indexed) is the number of lines times the percent, rounded up.
+func StartOfLineAtPercent(tree *text.Tree, percent uint64) uint64 {
+	lineNum := (percent*tree.NumLines() + 99) / 100
+	if lineNum > 0 {
+		lineNum-- // Convert 1-indexed line to 0-indexed line num.
+	}
+	return StartOfLineNum(tree, lineNum)
+}
+
 // StartOfLastLine locates the start of the last line.
 func StartOfLastLine(tree *text.Tree) uint64 {
 	lineNum := ClosestValidLineNum(tree, tree.NumLines())
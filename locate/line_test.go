@@ -355,6 +355,70 @@ func TestStartOfLineNum(t *testing.T) {
 	}
 }
 
+func TestStartOfLineAtPercent(t *testing.T) {
+	// Ten lines, each with one digit, so line n starts at position 2n.
+	const tenLines = "0\n1\n2\n3\n4\n5\n6\n7\n8\n9"
+
+	testCases := []struct {
+		name        string
+		inputString string
+		percent     uint64
+		expectedPos uint64
+	}{
+		{
+			name:        "empty",
+			inputString: "",
+			percent:     50,
+			expectedPos: 0,
+		},
+		{
+			name:        "single line",
+			inputString: "abcd",
+			percent:     100,
+			expectedPos: 0,
+		},
+		{
+			name:        "one percent of ten lines",
+			inputString: tenLines,
+			percent:     1,
+			expectedPos: 0,
+		},
+		{
+			name:        "fifty percent of ten lines",
+			inputString: tenLines,
+			percent:     50,
+			expectedPos: 8,
+		},
+		{
+			name:        "fifty-one percent of ten lines rounds up",
+			inputString: tenLines,
+			percent:     51,
+			expectedPos: 10,
+		},
+		{
+			name:        "one hundred percent of ten lines",
+			inputString: tenLines,
+			percent:     100,
+			expectedPos: 18,
+		},
+		{
+			name:        "fifty percent of three lines",
+			inputString: "ab\ncd\nef",
+			percent:     50,
+			expectedPos: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			actualPos := StartOfLineAtPercent(textTree, tc.percent)
+			assert.Equal(t, tc.expectedPos, actualPos)
+		})
+	}
+}
+
 func TestStartOfLastLine(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -67,6 +67,38 @@ func maxLinesAboveCursorScrollForward(viewHeight uint64) uint64 {
 	}
 }
 
+// ViewOriginWithCursorAtTop returns a view origin that displays the cursor near the top of the view,
+// leaving the scroll margin above the cursor.
+func ViewOriginWithCursorAtTop(cursorPos uint64, tree *text.Tree, wrapConfig segment.LineWrapConfig, viewHeight uint64) uint64 {
+	return scrollToCursor(cursorPos, ScrollMarginForNumLines(viewHeight), tree, wrapConfig)
+}
+
+// ViewOriginWithCursorAtCenter returns a view origin that displays the cursor in the middle of the view.
+func ViewOriginWithCursorAtCenter(cursorPos uint64, tree *text.Tree, wrapConfig segment.LineWrapConfig, viewHeight uint64) uint64 {
+	return scrollToCursor(cursorPos, viewHeight/2, tree, wrapConfig)
+}
+
+// ViewOriginWithCursorAtBottom returns a view origin that displays the cursor near the bottom of the view,
+// leaving the scroll margin below the cursor.
+func ViewOriginWithCursorAtBottom(cursorPos uint64, tree *text.Tree, wrapConfig segment.LineWrapConfig, viewHeight uint64) uint64 {
+	if viewHeight == 0 {
+		return scrollToCursor(cursorPos, 0, tree, wrapConfig)
+	}
+	return scrollToCursor(cursorPos, viewHeight-1-ScrollMarginForNumLines(viewHeight), tree, wrapConfig)
+}
+
+// ScrollMarginForNumLines returns the scroll margin for a view displaying the given number of lines.
+// The margin is reduced for short views so that the cursor can be displayed outside the margins.
+func ScrollMarginForNumLines(numLines uint64) uint64 {
+	if numLines > ScrollMargin*2 {
+		return ScrollMargin
+	} else if numLines >= 3 {
+		return 1
+	} else {
+		return 0
+	}
+}
+
 // visibleRangeWithinMargin returns a range of visible characters, excluding the scroll margin at the top and bottom.
 // Cursor movements within this range will NOT trigger scrolling.
 // This is an important performance optimization because scrolling is computationally expensive.
@@ -77,12 +109,7 @@ func visibleRangeWithinMargin(tree *text.Tree, viewOrigin uint64, wrapConfig seg
 		return posRange{}
 	}
 
-	margin := 0
-	if len(lines) > ScrollMargin*2 {
-		margin = ScrollMargin
-	} else if len(lines) >= 3 {
-		margin = 1
-	}
+	margin := int(ScrollMarginForNumLines(uint64(len(lines))))
 
 	rng := posRange{
 		startPos: lines[margin].startPos,
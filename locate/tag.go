@@ -0,0 +1,176 @@
+package locate
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/aretext/aretext/text"
+)
+
+// maxTagLen is the maximum number of runes in an HTML or XML tag, including attributes.
+const maxTagLen = 1024
+
+// htmlTag is an opening or closing HTML or XML tag.
+type htmlTag struct {
+	name    string
+	closing bool
+	endPos  uint64 // Position after the ">" that ends the tag.
+}
+
+// MatchingTag locates the matching closing tag for an HTML or XML opening tag at a position, or vice versa.
+// The position can be anywhere in the tag, from the "<" to the ">",
+// except after a ">" in a quoted attribute value.
+// Tags with the same name can be nested, and tag names are compared case-insensitively.
+// The returned position is the "<" that starts the matching tag.
+func MatchingTag(textTree *text.Tree, pos uint64) (uint64, bool) {
+	startPos, ok := tagStartContainingPos(textTree, pos)
+	if !ok {
+		return 0, false
+	}
+
+	tag, ok := parseTagAt(textTree, startPos)
+	if !ok || tag.endPos <= pos {
+		return 0, false
+	}
+
+	if tag.closing {
+		return searchBackwardTag(textTree, startPos, tag.name)
+	} else {
+		return searchForwardTag(textTree, tag.endPos, tag.name)
+	}
+}
+
+// tagStartContainingPos locates the "<" before a position, unless a ">" ends a tag in between.
+func tagStartContainingPos(textTree *text.Tree, pos uint64) (uint64, bool) {
+	// Start reading at pos, so the first rune read is the one at pos.
+	reader := textTree.ReverseReaderAtPosition(pos + 1)
+	for i := uint64(0); i < maxTagLen && i <= pos; i++ {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return 0, false
+		}
+
+		if r == '<' {
+			return pos - i, true
+		} else if r == '>' && i > 0 {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// parseTagAt parses an opening or closing tag that starts with the "<" at a position.
+// This fails for self-closing tags like "<br/>", comments, declarations, and processing instructions.
+func parseTagAt(textTree *text.Tree, pos uint64) (htmlTag, bool) {
+	reader := textTree.ReaderAtPosition(pos)
+	var runes []rune
+	var quote rune
+	for len(runes) < maxTagLen {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return htmlTag{}, false
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		runes = append(runes, r)
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+		} else if r == '"' || r == '\'' {
+			quote = r
+		} else if r == '>' {
+			break
+		}
+	}
+
+	if len(runes) < 3 || runes[0] != '<' || runes[len(runes)-1] != '>' {
+		return htmlTag{}, false
+	}
+
+	tag := htmlTag{endPos: pos + uint64(len(runes))}
+	i := 1
+	if runes[i] == '/' {
+		tag.closing = true
+		i++
+	}
+
+	nameStart := i
+	for i < len(runes) && isTagNameRune(runes[i]) {
+		if i == nameStart && !unicode.IsLetter(runes[i]) {
+			return htmlTag{}, false
+		}
+		i++
+	}
+	tag.name = string(runes[nameStart:i])
+
+	if tag.name == "" || (!tag.closing && runes[len(runes)-2] == '/') {
+		return htmlTag{}, false
+	}
+
+	return tag, true
+}
+
+func isTagNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':' || r == '.'
+}
+
+func searchForwardTag(textTree *text.Tree, pos uint64, name string) (uint64, bool) {
+	depth := 1
+	reader := textTree.ReaderAtPosition(pos)
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return 0, false
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		if r == '<' {
+			if tag, ok := parseTagAt(textTree, pos); ok && strings.EqualFold(tag.name, name) {
+				if tag.closing {
+					depth--
+				} else {
+					depth++
+				}
+
+				if depth == 0 {
+					return pos, true
+				}
+			}
+		}
+
+		pos++
+	}
+}
+
+func searchBackwardTag(textTree *text.Tree, pos uint64, name string) (uint64, bool) {
+	depth := 1
+	reader := textTree.ReverseReaderAtPosition(pos)
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return 0, false
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		pos--
+
+		if r == '<' {
+			if tag, ok := parseTagAt(textTree, pos); ok && strings.EqualFold(tag.name, name) {
+				if tag.closing {
+					depth++
+				} else {
+					depth--
+				}
+
+				if depth == 0 {
+					return pos, true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package locate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestMatchingTag(t *testing.T) {
+	testCases := []struct {
+		name        string
+		inputString string
+		pos         uint64
+		expectMatch bool
+		expectPos   uint64
+	}{
+		{
+			name:        "empty",
+			inputString: "",
+			pos:         0,
+			expectMatch: false,
+		},
+		{
+			name:        "not in tag",
+			inputString: "<p>foo</p>",
+			pos:         4,
+			expectMatch: false,
+		},
+		{
+			name:        "open to close",
+			inputString: "<p>foo</p>",
+			pos:         0,
+			expectMatch: true,
+			expectPos:   6,
+		},
+		{
+			name:        "close to open",
+			inputString: "<p>foo</p>",
+			pos:         9,
+			expectMatch: true,
+			expectPos:   0,
+		},
+		{
+			name:        "from tag name with attributes",
+			inputString: `<div class="a>b">foo</div>`,
+			pos:         2,
+			expectMatch: true,
+			expectPos:   20,
+		},
+		{
+			name:        "from attribute",
+			inputString: `<div class="a>b">foo</div>`,
+			pos:         6,
+			expectMatch: true,
+			expectPos:   20,
+		},
+		{
+			name:        "nested tags with the same name",
+			inputString: "<div><div></div></div>",
+			pos:         0,
+			expectMatch: true,
+			expectPos:   16,
+		},
+		{
+			name:        "nested tags backward",
+			inputString: "<div><div></div></div>",
+			pos:         17,
+			expectMatch: true,
+			expectPos:   0,
+		},
+		{
+			name:        "ignore self-closing tag with the same name",
+			inputString: "<div><div/></div>",
+			pos:         0,
+			expectMatch: true,
+			expectPos:   11,
+		},
+		{
+			name:        "case-insensitive names",
+			inputString: "<DIV>foo</div>",
+			pos:         0,
+			expectMatch: true,
+			expectPos:   8,
+		},
+		{
+			name:        "self-closing tag",
+			inputString: "<br/>",
+			pos:         0,
+			expectMatch: false,
+		},
+		{
+			name:        "unmatched open tag",
+			inputString: "<p>foo",
+			pos:         0,
+			expectMatch: false,
+		},
+		{
+			name:        "comparison operator",
+			inputString: "a < b > c",
+			pos:         2,
+			expectMatch: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			actualPos, ok := MatchingTag(textTree, tc.pos)
+			assert.Equal(t, tc.expectMatch, ok)
+			if ok {
+				assert.Equal(t, tc.expectPos, actualPos)
+			}
+		})
+	}
+}
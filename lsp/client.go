@@ -0,0 +1,279 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+)
+
+// shutdownTimeout is the maximum time to wait for a language server to exit before killing it.
+const shutdownTimeout = time.Second
+
+// Document is the content of a file open in the editor.
+type Document struct {
+	Path       string // Absolute path to the file.
+	LanguageID string
+	Text       string
+}
+
+// Client sends requests to a language server.
+// It is safe to use from multiple goroutines.
+type Client struct {
+	conn    *Conn
+	cmd     *exec.Cmd      // Nil if the client was not started from a command.
+	stdin   io.WriteCloser // Nil if the client was not started from a command.
+	rootDir string
+
+	mu          sync.Mutex
+	initialized bool
+	openDocs    map[string]openDocument // Keyed by URI.
+}
+
+type openDocument struct {
+	version int
+	text    string
+}
+
+// StartClient starts a language server as a subprocess and connects to it over stdin and stdout.
+// The server's working directory and workspace root is rootDir.
+// The server is initialized on the first request, so starting it does not block the editor.
+func StartClient(command string, rootDir string) (*Client, error) {
+	args, err := shlex.Split(command)
+	if err != nil {
+		return nil, errors.Wrap(err, "shlex.Split")
+	} else if len(args) == 0 {
+		return nil, errors.New("Language server command is empty")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = rootDir
+	cmd.Env = os.Environ()
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "cmd.StdinPipe")
+	}
+
+	// Close the pipe when the server exits so that pending calls fail instead of blocking.
+	stdoutReader, stdoutWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "cmd.Start")
+	}
+
+	log.Printf("Started language server %q with pid %d\n", command, cmd.Process.Pid)
+	go func() {
+		err := cmd.Wait()
+		log.Printf("Language server %q exited: %v\n", command, err)
+		if err != nil {
+			stdoutWriter.CloseWithError(errors.Wrap(err, "Language server exited"))
+		} else {
+			stdoutWriter.CloseWithError(errors.New("Language server exited"))
+		}
+	}()
+
+	c := newClient(stdoutReader, stdin, rootDir)
+	c.cmd = cmd
+	c.stdin = stdin
+	return c, nil
+}
+
+func newClient(r io.Reader, w io.Writer, rootDir string) *Client {
+	return &Client{
+		conn:     NewConn(r, w, handleServerMessage),
+		rootDir:  rootDir,
+		openDocs: make(map[string]openDocument),
+	}
+}
+
+// handleServerMessage responds to requests and notifications from the server.
+// The client does not advertise capabilities that require a response,
+// but some servers send requests like "window/workDoneProgress/create" anyway, which accept a null result.
+func handleServerMessage(method string, params json.RawMessage) (any, error) {
+	if method == "window/showMessage" || method == "window/logMessage" {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(params, &msg); err == nil {
+			log.Printf("Language server message: %s\n", msg.Message)
+		}
+	}
+	return nil, nil
+}
+
+// Done returns a channel that is closed when the connection to the server closes, usually because the server exited.
+func (c *Client) Done() <-chan struct{} {
+	return c.conn.Done()
+}
+
+// Err returns the reason the connection to the server closed, or nil if it is still open.
+func (c *Client) Err() error {
+	return c.conn.Err()
+}
+
+// Hover returns documentation for the symbol at a position in the document.
+// If the server has no documentation for the position, this returns an empty string.
+func (c *Client) Hover(ctx context.Context, doc Document, pos Position) (string, error) {
+	params, err := c.syncDocument(ctx, doc, pos)
+	if err != nil {
+		return "", err
+	}
+
+	var result *hover
+	if err := c.conn.Call(ctx, "textDocument/hover", params, &result); err != nil {
+		return "", err
+	}
+
+	if result == nil {
+		return "", nil
+	}
+	return string(result.Contents), nil
+}
+
+// Definition returns the locations where the symbol at a position in the document is defined.
+func (c *Client) Definition(ctx context.Context, doc Document, pos Position) ([]Location, error) {
+	params, err := c.syncDocument(ctx, doc, pos)
+	if err != nil {
+		return nil, err
+	}
+
+	var result definitionResult
+	if err := c.conn.Call(ctx, "textDocument/definition", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Shutdown asks the server to exit, then waits for it to exit.
+// If the server does not exit before the context is done, this kills it.
+func (c *Client) Shutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	c.mu.Lock()
+	initialized := c.initialized
+	c.mu.Unlock()
+
+	if initialized {
+		if err := c.conn.Call(ctx, "shutdown", nil, nil); err != nil {
+			log.Printf("Error shutting down language server: %v\n", err)
+		} else if err := c.conn.Notify("exit", nil); err != nil {
+			log.Printf("Error sending exit notification to language server: %v\n", err)
+		}
+	}
+
+	if c.cmd == nil {
+		return
+	}
+
+	if !initialized {
+		// The server may be waiting for the initialize request, so closing its stdin is the only way to stop it.
+		c.stdin.Close()
+	}
+
+	select {
+	case <-c.conn.Done():
+	case <-ctx.Done():
+		log.Printf("Killing language server with pid %d\n", c.cmd.Process.Pid)
+		if err := c.cmd.Process.Kill(); err != nil {
+			log.Printf("Error killing language server: %v\n", err)
+		}
+	}
+}
+
+// syncDocument initializes the server if necessary, then sends the current text of the document.
+// It returns the params for a request at a position in the document.
+func (c *Client) syncDocument(ctx context.Context, doc Document, pos Position) (textDocumentPositionParams, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	uri := URIForPath(doc.Path)
+	params := textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Position:     pos,
+	}
+
+	if err := c.initializeIfNecessary(ctx); err != nil {
+		return params, err
+	}
+
+	openDoc, ok := c.openDocs[uri]
+	if !ok {
+		openDoc = openDocument{version: 1, text: doc.Text}
+		err := c.conn.Notify("textDocument/didOpen", didOpenTextDocumentParams{
+			TextDocument: textDocumentItem{
+				URI:        uri,
+				LanguageID: doc.LanguageID,
+				Version:    openDoc.version,
+				Text:       doc.Text,
+			},
+		})
+		if err != nil {
+			return params, err
+		}
+	} else if openDoc.text != doc.Text {
+		openDoc = openDocument{version: openDoc.version + 1, text: doc.Text}
+		err := c.conn.Notify("textDocument/didChange", didChangeTextDocumentParams{
+			TextDocument: versionedTextDocumentIdentifier{
+				URI:     uri,
+				Version: openDoc.version,
+			},
+			ContentChanges: []textDocumentContentChangeEvent{
+				{Text: doc.Text},
+			},
+		})
+		if err != nil {
+			return params, err
+		}
+	}
+
+	c.openDocs[uri] = openDoc
+	return params, nil
+}
+
+// initializeIfNecessary performs the initialization handshake the first time it is called.
+// The caller must hold the client's mutex.
+func (c *Client) initializeIfNecessary(ctx context.Context) error {
+	if c.initialized {
+		return nil
+	}
+
+	params := initializeParams{
+		ProcessID:  os.Getpid(),
+		ClientInfo: clientInfo{Name: "aretext"},
+		RootURI:    URIForPath(c.rootDir),
+		Capabilities: map[string]any{
+			"textDocument": map[string]any{
+				"synchronization": map[string]any{
+					"dynamicRegistration": false,
+				},
+				"hover": map[string]any{
+					"contentFormat": []string{"plaintext", "markdown"},
+				},
+				"definition": map[string]any{
+					"linkSupport": true,
+				},
+			},
+		},
+	}
+
+	if err := c.conn.Call(ctx, "initialize", params, nil); err != nil {
+		return errors.Wrap(err, "initialize")
+	}
+
+	if err := c.conn.Notify("initialized", struct{}{}); err != nil {
+		return errors.Wrap(err, "initialized")
+	}
+
+	c.initialized = true
+	return nil
+}
@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer records the messages it receives and responds to requests with canned results.
+type fakeServer struct {
+	mu       sync.Mutex
+	methods  []string
+	texts    []string
+	versions []int
+	results  map[string]any
+}
+
+func (s *fakeServer) handle(method string, params json.RawMessage) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods = append(s.methods, method)
+
+	switch method {
+	case "textDocument/didOpen":
+		var p didOpenTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		s.texts = append(s.texts, p.TextDocument.Text)
+		s.versions = append(s.versions, p.TextDocument.Version)
+	case "textDocument/didChange":
+		var p didChangeTextDocumentParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		s.texts = append(s.texts, p.ContentChanges[0].Text)
+		s.versions = append(s.versions, p.TextDocument.Version)
+	}
+
+	if result, ok := s.results[method]; ok {
+		return result, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeServer) receivedMethods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.methods...)
+}
+
+func startFakeServer(t *testing.T, results map[string]any) (*Client, *fakeServer) {
+	server := &fakeServer{results: results}
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	t.Cleanup(func() {
+		clientReader.Close()
+		serverReader.Close()
+	})
+	NewConn(serverReader, serverWriter, server.handle)
+	return newClient(clientReader, clientWriter, "/test"), server
+}
+
+func TestClientHover(t *testing.T) {
+	client, server := startFakeServer(t, map[string]any{
+		"initialize": map[string]any{"capabilities": map[string]any{}},
+		"textDocument/hover": map[string]any{
+			"contents": map[string]any{"kind": "plaintext", "value": "func foo()"},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	doc := Document{Path: "/test/foo.go", LanguageID: "go", Text: "package foo"}
+	text, err := client.Hover(ctx, doc, Position{Line: 0, Character: 9})
+	require.NoError(t, err)
+	assert.Equal(t, "func foo()", text)
+
+	// The same text is not sent again.
+	_, err = client.Hover(ctx, doc, Position{Line: 0, Character: 9})
+	require.NoError(t, err)
+
+	// Changed text is sent with a new version.
+	doc.Text = "package bar"
+	_, err = client.Hover(ctx, doc, Position{Line: 0, Character: 9})
+	require.NoError(t, err)
+
+	// Wait for the notification after the last request to arrive.
+	require.NoError(t, client.conn.Call(ctx, "test/sync", nil, nil))
+
+	assert.Equal(t, []string{
+		"initialize",
+		"initialized",
+		"textDocument/didOpen",
+		"textDocument/hover",
+		"textDocument/hover",
+		"textDocument/didChange",
+		"textDocument/hover",
+		"test/sync",
+	}, server.receivedMethods())
+	assert.Equal(t, []string{"package foo", "package bar"}, server.texts)
+	assert.Equal(t, []int{1, 2}, server.versions)
+}
+
+func TestClientHoverNoResult(t *testing.T) {
+	client, _ := startFakeServer(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	text, err := client.Hover(ctx, Document{Path: "/test/foo.go"}, Position{})
+	require.NoError(t, err)
+	assert.Equal(t, "", text)
+}
+
+func TestClientDefinition(t *testing.T) {
+	client, _ := startFakeServer(t, map[string]any{
+		"textDocument/definition": []map[string]any{
+			{
+				"uri": "file:///test/bar.go",
+				"range": map[string]any{
+					"start": map[string]any{"line": 3, "character": 5},
+					"end":   map[string]any{"line": 3, "character": 8},
+				},
+			},
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	locs, err := client.Definition(ctx, Document{Path: "/test/foo.go"}, Position{})
+	require.NoError(t, err)
+	assert.Equal(t, []Location{
+		{
+			URI: "file:///test/bar.go",
+			Range: Range{
+				Start: Position{Line: 3, Character: 5},
+				End:   Position{Line: 3, Character: 8},
+			},
+		},
+	}, locs)
+}
+
+func TestClientServerExited(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	client := newClient(clientReader, io.Discard, "/test")
+	serverWriter.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.Hover(ctx, Document{Path: "/test/foo.go"}, Position{})
+	assert.Error(t, err)
+	<-client.Done()
+	assert.EqualError(t, client.Err(), "Connection closed")
+}
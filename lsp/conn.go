@@ -0,0 +1,259 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Handler responds to a request or notification from the other side of a connection.
+// The result is sent as the response to a request and ignored for a notification.
+type Handler func(method string, params json.RawMessage) (any, error)
+
+// ResponseError is an error returned in a JSON-RPC response.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+// Error codes defined by JSON-RPC.
+const (
+	ErrorCodeMethodNotFound = -32601
+	ErrorCodeInternalError  = -32603
+)
+
+// message is a JSON-RPC 2.0 request, notification, or response.
+// Requests and notifications have a method, and responses have a result or error.
+// Notifications have no ID.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *ResponseError  `json:"error,omitempty"`
+}
+
+// Conn is a JSON-RPC 2.0 connection using the base protocol of the Language Server Protocol,
+// in which each message has a Content-Length header.
+// Calls can be made concurrently from multiple goroutines.
+type Conn struct {
+	w       io.Writer
+	writeMu sync.Mutex
+
+	handler Handler
+
+	mu      sync.Mutex
+	nextId  int64
+	pending map[int64]chan *message
+	err     error // Set when the connection closes.
+	done    chan struct{}
+}
+
+// NewConn constructs a connection that reads messages from r and writes messages to w.
+// It reads in a separate goroutine until r returns an error, such as io.EOF when the other side exits.
+// Requests and notifications from the other side are passed to handler.
+func NewConn(r io.Reader, w io.Writer, handler Handler) *Conn {
+	c := &Conn{
+		w:       w,
+		handler: handler,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(bufio.NewReader(r))
+	return c
+}
+
+// Done returns a channel that is closed when the connection closes.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection closed, or nil if it is still open.
+// The connection closes when reading fails, usually because the other side exited, or when a write fails.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Call sends a request and waits for the response.
+// If result is not nil, the result of the response is unmarshalled into it.
+func (c *Conn) Call(ctx context.Context, method string, params any, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	id := c.nextId
+	c.nextId++
+	responseChan := make(chan *message, 1)
+	c.pending[id] = responseChan
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	idJson, _ := json.Marshal(id)
+	if err := c.send(&message{ID: idJson, Method: method}, params); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.Err()
+	case response := <-responseChan:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		return errors.Wrap(json.Unmarshal(response.Result, result), "json.Unmarshal")
+	}
+}
+
+// Notify sends a notification, which has no response.
+func (c *Conn) Notify(method string, params any) error {
+	if err := c.Err(); err != nil {
+		return err
+	}
+	return c.send(&message{Method: method}, params)
+}
+
+func (c *Conn) send(msg *message, params any) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return errors.Wrap(err, "json.Marshal")
+		}
+		msg.Params = data
+	}
+	return c.write(msg)
+}
+
+func (c *Conn) write(msg *message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal")
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		// The other side may have read part of the message, so the connection can't be used anymore.
+		err = errors.Wrap(err, "Write")
+		c.close(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Conn) readLoop(r *bufio.Reader) {
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			c.close(err)
+			return
+		}
+
+		if msg.Method != "" {
+			c.handle(msg)
+			continue
+		}
+
+		id, err := strconv.ParseInt(string(msg.ID), 10, 64)
+		if err != nil {
+			log.Printf("Ignoring response with unexpected ID %s\n", msg.ID)
+			continue
+		}
+
+		c.mu.Lock()
+		responseChan, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			responseChan <- msg
+		}
+	}
+}
+
+// handle passes a request or notification to the handler, then responds to requests.
+func (c *Conn) handle(msg *message) {
+	result, err := c.handler(msg.Method, msg.Params)
+	if len(msg.ID) == 0 {
+		return // Notifications have no response.
+	}
+
+	response := &message{JSONRPC: "2.0", ID: msg.ID}
+	if err != nil {
+		var responseErr *ResponseError
+		if !errors.As(err, &responseErr) {
+			responseErr = &ResponseError{Code: ErrorCodeInternalError, Message: err.Error()}
+		}
+		response.Error = responseErr
+	} else if response.Result, err = json.Marshal(result); err != nil {
+		response.Result = nil
+		response.Error = &ResponseError{Code: ErrorCodeInternalError, Message: err.Error()}
+	}
+
+	if err := c.write(response); err != nil {
+		log.Printf("Error responding to %q: %v\n", msg.Method, err)
+	}
+}
+
+func (c *Conn) close(err error) {
+	if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = errors.New("Connection closed")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return // Already closed.
+	}
+	c.err = err
+	close(c.done)
+}
+
+// readMessage reads the headers and content of one message.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	contentLength, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || contentLength < 0 {
+		return nil, errors.New("Invalid Content-Length header")
+	}
+
+	data := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+	return &msg, nil
+}
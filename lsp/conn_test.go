@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connPair returns two connections that communicate with each other over in-memory pipes.
+func connPair(t *testing.T, handlerA, handlerB Handler) (*Conn, *Conn) {
+	ar, bw := io.Pipe()
+	br, aw := io.Pipe()
+	t.Cleanup(func() {
+		ar.Close()
+		br.Close()
+	})
+	return NewConn(ar, aw, handlerA), NewConn(br, bw, handlerB)
+}
+
+func nullHandler(method string, params json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+func TestConnCall(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        Handler
+		expectedResult map[string]string
+		expectedErr    string
+	}{
+		{
+			name: "result",
+			handler: func(method string, params json.RawMessage) (any, error) {
+				var p map[string]string
+				if err := json.Unmarshal(params, &p); err != nil {
+					return nil, err
+				}
+				return map[string]string{"method": method, "echo": p["value"]}, nil
+			},
+			expectedResult: map[string]string{"method": "test/echo", "echo": "hello"},
+		},
+		{
+			name:           "null result",
+			handler:        nullHandler,
+			expectedResult: nil,
+		},
+		{
+			name: "response error",
+			handler: func(method string, params json.RawMessage) (any, error) {
+				return nil, &ResponseError{Code: ErrorCodeMethodNotFound, Message: "method not found"}
+			},
+			expectedErr: "method not found",
+		},
+		{
+			name: "handler error",
+			handler: func(method string, params json.RawMessage) (any, error) {
+				return nil, errors.New("something went wrong")
+			},
+			expectedErr: "something went wrong",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, _ := connPair(t, nullHandler, tc.handler)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var result map[string]string
+			err := client.Call(ctx, "test/echo", map[string]string{"value": "hello"}, &result)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestConnConcurrentCalls(t *testing.T) {
+	client, _ := connPair(t, nullHandler, func(method string, params json.RawMessage) (any, error) {
+		var n int
+		err := json.Unmarshal(params, &n)
+		return n * 2, err
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results := make([]int, 10)
+	errs := make(chan error, len(results))
+	for i := range results {
+		go func(i int) {
+			errs <- client.Call(ctx, "test/double", i, &results[i])
+		}(i)
+	}
+
+	for range results {
+		require.NoError(t, <-errs)
+	}
+	for i, r := range results {
+		assert.Equal(t, i*2, r)
+	}
+}
+
+func TestConnNotify(t *testing.T) {
+	received := make(chan string, 1)
+	client, _ := connPair(t, nullHandler, func(method string, params json.RawMessage) (any, error) {
+		received <- method + " " + string(params)
+		return nil, nil
+	})
+
+	require.NoError(t, client.Notify("test/notify", []int{1, 2}))
+	select {
+	case msg := <-received:
+		assert.Equal(t, "test/notify [1,2]", msg)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Timed out")
+	}
+}
+
+func TestConnClosed(t *testing.T) {
+	r, serverWriter := io.Pipe()
+	client := NewConn(r, io.Discard, nullHandler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- client.Call(ctx, "test/never", nil, nil)
+	}()
+
+	// The other side exits before responding.
+	serverWriter.Close()
+
+	select {
+	case err := <-errChan:
+		assert.EqualError(t, err, "Connection closed")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Timed out")
+	}
+
+	<-client.Done()
+	assert.EqualError(t, client.Err(), "Connection closed")
+	assert.EqualError(t, client.Notify("test/notify", nil), "Connection closed")
+}
+
+func TestConnCallCancelled(t *testing.T) {
+	r, _ := io.Pipe()
+	client := NewConn(r, io.Discard, nullHandler)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.Call(ctx, "test/never", nil, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,178 @@
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// This file defines the subset of the Language Server Protocol used by the client.
+// See https://microsoft.github.io/language-server-protocol/specification for the full protocol.
+
+// Position is a location in a text document.
+// Lines are zero-indexed, and the character offset counts UTF-16 code units from the start of the line.
+type Position struct {
+	Line      uint64 `json:"line"`
+	Character uint64 `json:"character"`
+}
+
+// Range is the text between two positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a file.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// locationLink is an alternative to Location that a server can return for a definition.
+type locationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+type versionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// textDocumentContentChangeEvent replaces the full content of a document.
+// Every server accepts this, even if it prefers incremental changes.
+type textDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument   versionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []textDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type didCloseTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type clientInfo struct {
+	Name string `json:"name"`
+}
+
+type initializeParams struct {
+	ProcessID    int            `json:"processId"`
+	ClientInfo   clientInfo     `json:"clientInfo"`
+	RootURI      string         `json:"rootUri"`
+	Capabilities map[string]any `json:"capabilities"`
+}
+
+type hover struct {
+	Contents hoverContents `json:"contents"`
+}
+
+// hoverContents is the text of a hover result, which a server can send as a MarkupContent object,
+// a MarkedString (a string or an object with a language and value), or an array of MarkedStrings.
+type hoverContents string
+
+func (h *hoverContents) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*h = hoverContents(s)
+		return nil
+	}
+
+	var obj struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		*h = hoverContents(obj.Value)
+		return nil
+	}
+
+	var arr []hoverContents
+	if err := json.Unmarshal(data, &arr); err != nil {
+		return errors.New("Unsupported hover contents")
+	}
+	parts := make([]string, 0, len(arr))
+	for _, part := range arr {
+		parts = append(parts, string(part))
+	}
+	*h = hoverContents(strings.Join(parts, "\n"))
+	return nil
+}
+
+// definitionResult is the result of a definition request,
+// which a server can send as a Location, an array of Locations, or an array of LocationLinks.
+type definitionResult []Location
+
+func (d *definitionResult) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = nil
+		return nil
+	}
+
+	var loc Location
+	if err := json.Unmarshal(data, &loc); err == nil && loc.URI != "" {
+		*d = []Location{loc}
+		return nil
+	}
+
+	var items []struct {
+		Location
+		locationLink
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return errors.New("Unsupported definition result")
+	}
+
+	locs := make([]Location, 0, len(items))
+	for _, item := range items {
+		if item.URI != "" {
+			locs = append(locs, item.Location)
+		} else if item.TargetURI != "" {
+			locs = append(locs, Location{URI: item.TargetURI, Range: item.TargetSelectionRange})
+		}
+	}
+	*d = locs
+	return nil
+}
+
+// URIForPath returns a file URI for an absolute path.
+func URIForPath(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// PathForURI returns the path for a file URI.
+func PathForURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.Wrap(err, "url.Parse")
+	}
+	if u.Scheme != "file" {
+		return "", errors.Errorf("Unsupported URI scheme %q", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
@@ -0,0 +1,136 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoverContents(t *testing.T) {
+	testCases := []struct {
+		name         string
+		data         string
+		expectedText string
+	}{
+		{
+			name:         "markup content",
+			data:         `{"kind": "markdown", "value": "**foo**"}`,
+			expectedText: "**foo**",
+		},
+		{
+			name:         "marked string",
+			data:         `"foo"`,
+			expectedText: "foo",
+		},
+		{
+			name:         "marked string with language",
+			data:         `{"language": "go", "value": "func foo()"}`,
+			expectedText: "func foo()",
+		},
+		{
+			name:         "array of marked strings",
+			data:         `["foo", {"language": "go", "value": "func foo()"}]`,
+			expectedText: "foo\nfunc foo()",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var contents hoverContents
+			err := json.Unmarshal([]byte(tc.data), &contents)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedText, string(contents))
+		})
+	}
+}
+
+func TestDefinitionResult(t *testing.T) {
+	loc := Location{
+		URI: "file:///foo.go",
+		Range: Range{
+			Start: Position{Line: 1, Character: 2},
+			End:   Position{Line: 1, Character: 5},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		data         string
+		expectedLocs []Location
+	}{
+		{
+			name:         "null",
+			data:         `null`,
+			expectedLocs: nil,
+		},
+		{
+			name:         "location",
+			data:         `{"uri": "file:///foo.go", "range": {"start": {"line": 1, "character": 2}, "end": {"line": 1, "character": 5}}}`,
+			expectedLocs: []Location{loc},
+		},
+		{
+			name:         "array of locations",
+			data:         `[{"uri": "file:///foo.go", "range": {"start": {"line": 1, "character": 2}, "end": {"line": 1, "character": 5}}}]`,
+			expectedLocs: []Location{loc},
+		},
+		{
+			name: "array of location links",
+			data: `[{
+				"targetUri": "file:///foo.go",
+				"targetRange": {"start": {"line": 0, "character": 0}, "end": {"line": 3, "character": 0}},
+				"targetSelectionRange": {"start": {"line": 1, "character": 2}, "end": {"line": 1, "character": 5}}
+			}]`,
+			expectedLocs: []Location{loc},
+		},
+		{
+			name:         "empty array",
+			data:         `[]`,
+			expectedLocs: []Location{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result definitionResult
+			err := json.Unmarshal([]byte(tc.data), &result)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedLocs, []Location(result))
+		})
+	}
+}
+
+func TestURIForPath(t *testing.T) {
+	testCases := []struct {
+		name        string
+		path        string
+		expectedURI string
+	}{
+		{
+			name:        "simple path",
+			path:        "/home/user/foo.go",
+			expectedURI: "file:///home/user/foo.go",
+		},
+		{
+			name:        "path with spaces",
+			path:        "/home/user/my project/foo.go",
+			expectedURI: "file:///home/user/my%20project/foo.go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uri := URIForPath(tc.path)
+			assert.Equal(t, tc.expectedURI, uri)
+			path, err := PathForURI(uri)
+			require.NoError(t, err)
+			assert.Equal(t, tc.path, path)
+		})
+	}
+}
+
+func TestPathForURIUnsupportedScheme(t *testing.T) {
+	_, err := PathForURI("https://example.com/foo.go")
+	assert.EqualError(t, err, `Unsupported URI scheme "https"`)
+}
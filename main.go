@@ -52,7 +52,9 @@ var line = flag.Int("line", 1, "line number to view after opening the document")
 var logpath = flag.String("log", "", "log to file")
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 var editconfig = flag.Bool("editconfig", false, "open the aretext configuration file")
-var noconfig = flag.Bool("noconfig", false, "force default configuration")
+var noconfig = flag.Bool("noconfig", false, "force default configuration and ignore project config and .editorconfig files")
+var workingdir = flag.String("workingdir", "", "set the working directory before opening the document")
+var session = flag.String("session", "", "restore the documents from a session file saved with the \"save session\" menu command")
 var versionFlag = flag.Bool("version", false, "print version")
 
 func main() {
@@ -92,16 +94,26 @@ func main() {
 		lineNum = uint64(*line) - 1 // convert 1-based line arg to 0-based lineNum.
 	}
 
-	path := flag.Arg(0)
+	if *workingdir != "" {
+		if err := os.Chdir(*workingdir); err != nil {
+			exitWithError(fmt.Errorf("could not change working directory: %w", err))
+		}
+	}
+
+	loc, err := app.ParseFileLocationArgs(flag.Args(), lineNum)
+	if err != nil {
+		exitWithError(err)
+	}
+
 	if *editconfig {
 		configPath, err := app.ConfigPath()
 		if err != nil {
 			exitWithError(err)
 		}
-		path = configPath
+		loc = app.FileLocation{Path: configPath, LineNum: lineNum}
 	}
 
-	err := runEditor(path, lineNum)
+	err = runEditor(loc)
 	if err != nil {
 		exitWithError(err)
 	}
@@ -109,18 +121,19 @@ func main() {
 
 func printUsage() {
 	f := flag.CommandLine.Output()
-	fmt.Fprintf(f, "Usage: %s [options...] [path]\n", os.Args[0])
+	fmt.Fprintf(f, "Usage: %s [options...] [+line] [path[:line[:col]]]\n", os.Args[0])
 	flag.PrintDefaults()
 }
 
-func runEditor(path string, lineNum uint64) error {
+func runEditor(loc app.FileLocation) error {
 	log.Printf("version: %s\n", version)
 	log.Printf("go version: %s\n", goVersion)
 	log.Printf("vcs.revision: %s\n", vcsRevision)
 	log.Printf("vcs.time: %s\n", vcsTime)
 	log.Printf("vcs.modified: %t\n", vcsModified)
-	log.Printf("path arg: %q\n", path)
-	log.Printf("lineNum: %d\n", lineNum)
+	log.Printf("path arg: %q\n", loc.Path)
+	log.Printf("lineNum: %d\n", loc.LineNum)
+	log.Printf("col: %d\n", loc.Col)
 	log.Printf("$TERM env var: %q\n", os.Getenv("TERM"))
 
 	configRuleSet, err := app.LoadOrCreateConfig(*noconfig)
@@ -138,7 +151,10 @@ func runEditor(path string, lineNum uint64) error {
 	}
 	defer screen.Fini()
 
-	editor := app.NewEditor(screen, path, uint64(lineNum), configRuleSet)
+	editor := app.NewEditor(screen, loc, configRuleSet, !*noconfig)
+	if *session != "" {
+		editor.LoadSession(*session)
+	}
 	editor.RunEventLoop()
 	return nil
 }
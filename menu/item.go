@@ -6,10 +6,33 @@ type Item struct {
 	// This is also used when searching for menu items.
 	Name string
 
+	// Link is a URL for the item, such as a file URL for a file location.
+	// If hyperlinks are enabled, the displayed name links to this URL.
+	Link string
+
 	// Aliases are a search terms for which this item will always rank first.
 	Aliases []string
 
 	// Action is the action to perform when the user selects the menu item.
-	// This should be a function that accepts a single *EditorState arg.
+	// This should be a function that accepts a single *EditorState arg,
+	// or a function that accepts *EditorState and string args if AcceptsArg is true.
+	// If AcceptsRange is true, the function also accepts a LineRange arg after the *EditorState,
+	// or a *LineRange arg that is nil if the user did not type a range or select lines.
 	Action any
+
+	// AcceptsArg allows the user to type an alias followed by a space and an argument.
+	AcceptsArg bool
+
+	// Arg is the argument the user typed after the alias.
+	Arg string
+
+	// AcceptsRange allows the user to type a line range before the alias, such as "1,5" in "1,5sort".
+	AcceptsRange bool
+
+	// Range is the line range the user typed before the alias, or an empty string if the user did not type a range.
+	Range string
+
+	// DefaultRange is the line range to use if the user did not type one and nothing is selected.
+	// If empty, the default is the current line.
+	DefaultRange string
 }
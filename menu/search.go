@@ -11,24 +11,30 @@ const (
 	maxSearchQueryLen    = 1024
 )
 
+// lineRangeChars are the characters that can appear in a line range before an alias.
+const lineRangeChars = "0123456789.,$%+-"
+
 // Search performs approximate text searches for menu items matching a query string.
 type Search struct {
 	query             string
 	emptyQueryShowAll bool
+	acceptsRange      bool
 	fuzzyIndex        *fuzzy.Index
-	aliasIndex        map[string]int
+	aliasIndex        map[string][]int
 	items             []Item
 	results           []Item
 }
 
 func NewSearch(items []Item, emptyQueryShowAll bool) *Search {
 	itemNames := make([]string, len(items))
-	aliasIndex := make(map[string]int, 0)
+	aliasIndex := make(map[string][]int, 0)
+	var acceptsRange bool
 	for itemId, item := range items {
+		acceptsRange = acceptsRange || item.AcceptsRange
 		// Truncate long names to avoid perf issues when fuzzy searching.
 		itemNames[itemId] = truncateString(item.Name, maxSearchItemNameLen)
 		for _, alias := range item.Aliases {
-			aliasIndex[alias] = itemId
+			aliasIndex[alias] = append(aliasIndex[alias], itemId)
 		}
 	}
 
@@ -39,6 +45,7 @@ func NewSearch(items []Item, emptyQueryShowAll bool) *Search {
 
 	return &Search{
 		emptyQueryShowAll: emptyQueryShowAll,
+		acceptsRange:      acceptsRange,
 		fuzzyIndex:        fuzzy.NewIndex(itemNames),
 		aliasIndex:        aliasIndex,
 		items:             items,
@@ -71,12 +78,30 @@ func (s *Search) SetQuery(q string) {
 
 	// Truncate long queries to avoid perf issues when fuzzy searching.
 	truncatedQuery := truncateString(q, maxSearchQueryLen)
+
+	// A query with a line range must match an item that accepts the range.
+	// Falling back to a fuzzy match could run a different command, possibly on the wrong lines.
+	if s.acceptsRange && lineRangePrefixLen(truncatedQuery) > 0 {
+		s.results = nil
+		if item, ok := s.itemWithRange(truncatedQuery); ok {
+			s.results = []Item{item}
+		}
+		return
+	}
+
 	resultItemIds := s.fuzzyIndex.Search(truncatedQuery)
 	results := make([]Item, 0, len(resultItemIds)+1)
 	itemIdMatchingAlias := -1
-	if itemId, ok := s.aliasIndex[strings.ToLower(truncatedQuery)]; ok {
+	if itemId, ok := s.itemForAlias(truncatedQuery, func(Item) bool { return true }); ok {
 		itemIdMatchingAlias = itemId
 		results = append(results, s.items[itemId])
+	} else if alias, arg, ok := strings.Cut(truncatedQuery, " "); ok {
+		if itemId, ok := s.itemForAlias(alias, func(item Item) bool { return item.AcceptsArg }); ok {
+			itemIdMatchingAlias = itemId
+			item := s.items[itemId]
+			item.Arg = arg
+			results = append(results, item)
+		}
 	}
 	for _, itemId := range resultItemIds {
 		if itemId != itemIdMatchingAlias {
@@ -86,6 +111,54 @@ func (s *Search) SetQuery(q string) {
 	s.results = results
 }
 
+// itemWithRange finds an item that accepts a range matching a query like "1,5sort" or "%d".
+// The query is a line range followed by an alias, optionally followed by a space and an argument.
+func (s *Search) itemWithRange(q string) (Item, bool) {
+	rangeLen := lineRangePrefixLen(q)
+	if rangeLen <= 0 {
+		return Item{}, false
+	}
+
+	lineRange, rest := q[:rangeLen], strings.TrimLeft(q[rangeLen:], " ")
+	if itemId, ok := s.itemForAlias(rest, func(item Item) bool { return item.AcceptsRange }); ok {
+		item := s.items[itemId]
+		item.Range = lineRange
+		return item, true
+	}
+
+	if alias, arg, ok := strings.Cut(rest, " "); ok {
+		if itemId, ok := s.itemForAlias(alias, func(item Item) bool { return item.AcceptsRange && item.AcceptsArg }); ok {
+			item := s.items[itemId]
+			item.Range = lineRange
+			item.Arg = arg
+			return item, true
+		}
+	}
+
+	return Item{}, false
+}
+
+// itemForAlias finds an item with an alias that satisfies a predicate.
+// Items can share an alias, such as "m" for both recording a macro and moving lines with a range,
+// in which case the last item that satisfies the predicate wins.
+func (s *Search) itemForAlias(alias string, pred func(Item) bool) (int, bool) {
+	itemIds := s.aliasIndex[strings.ToLower(alias)]
+	for i := len(itemIds) - 1; i >= 0; i-- {
+		if pred(s.items[itemIds[i]]) {
+			return itemIds[i], true
+		}
+	}
+	return 0, false
+}
+
+// lineRangePrefixLen returns the length of the line range before the alias in a query like "1,5sort".
+// If the query does not start with a line range followed by an alias, this returns zero or a negative number.
+func lineRangePrefixLen(q string) int {
+	return strings.IndexFunc(q, func(r rune) bool {
+		return !strings.ContainsRune(lineRangeChars, r)
+	})
+}
+
 // Results returns the menu items matching the current query.
 // Items are sorted descending by relevance to the query,
 // with ties broken by lexicographic ordering.
@@ -89,6 +89,130 @@ func TestSearch(t *testing.T) {
 				{Name: "write three"},
 			},
 		},
+		{
+			name:  "alias with arg",
+			query: "norm dd",
+			items: []Item{
+				{Name: "normal", Aliases: []string{"norm"}, AcceptsArg: true},
+				{Name: "quit"},
+			},
+			expected: []Item{
+				{Name: "normal", Aliases: []string{"norm"}, AcceptsArg: true, Arg: "dd"},
+			},
+		},
+		{
+			name:  "alias with arg preserves spaces in arg",
+			query: "NORM d  w ",
+			items: []Item{
+				{Name: "normal", Aliases: []string{"norm"}, AcceptsArg: true},
+			},
+			expected: []Item{
+				{Name: "normal", Aliases: []string{"norm"}, AcceptsArg: true, Arg: "d  w "},
+			},
+		},
+		{
+			name:  "alias with arg for item that does not accept args",
+			query: "w foo",
+			items: []Item{
+				{Name: "write", Aliases: []string{"w"}},
+			},
+			expected: []Item{},
+		},
+		{
+			name:  "range before alias",
+			query: "1,5d",
+			items: []Item{
+				{Name: "delete lines", Aliases: []string{"d"}, AcceptsRange: true},
+			},
+			expected: []Item{
+				{Name: "delete lines", Aliases: []string{"d"}, AcceptsRange: true, Range: "1,5"},
+			},
+		},
+		{
+			name:  "range before alias with space and flags",
+			query: ".,$ sort n",
+			items: []Item{
+				{Name: "sort lines", Aliases: []string{"sort"}, AcceptsRange: true},
+				{Name: "sort lines (numeric)", Aliases: []string{"sort n"}, AcceptsRange: true},
+			},
+			expected: []Item{
+				{Name: "sort lines (numeric)", Aliases: []string{"sort n"}, AcceptsRange: true, Range: ".,$"},
+			},
+		},
+		{
+			name:  "range before alias with arg",
+			query: "%mo 0",
+			items: []Item{
+				{Name: "move lines", Aliases: []string{"mo"}, AcceptsRange: true, AcceptsArg: true},
+			},
+			expected: []Item{
+				{Name: "move lines", Aliases: []string{"mo"}, AcceptsRange: true, AcceptsArg: true, Range: "%", Arg: "0"},
+			},
+		},
+		{
+			name:  "range before alias for item that does not accept a range",
+			query: "1,5w",
+			items: []Item{
+				{Name: "write", Aliases: []string{"w"}},
+			},
+			expected: []Item{
+				{Name: "write", Aliases: []string{"w"}}, // fuzzy match, not an alias match
+			},
+		},
+		{
+			name:  "range before unknown alias",
+			query: "3,4m 0",
+			items: []Item{
+				{Name: "copy lines", Aliases: []string{"t", "co"}, AcceptsRange: true, AcceptsArg: true},
+				{Name: "move lines", Aliases: []string{"mo"}, AcceptsRange: true, AcceptsArg: true},
+				{Name: "replay macro", Aliases: []string{"m"}},
+			},
+			expected: nil,
+		},
+		{
+			name:  "shared alias without range or arg",
+			query: "m",
+			items: []Item{
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true},
+				{Name: "record macro", Aliases: []string{"m"}},
+			},
+			expected: []Item{
+				{Name: "record macro", Aliases: []string{"m"}},
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true}, // fuzzy match, not an alias match
+			},
+		},
+		{
+			name:  "shared alias with range and arg",
+			query: "3,4m 0",
+			items: []Item{
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true},
+				{Name: "record macro", Aliases: []string{"m"}},
+			},
+			expected: []Item{
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true, Range: "3,4", Arg: "0"},
+			},
+		},
+		{
+			name:  "shared alias with arg",
+			query: "m 0",
+			items: []Item{
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true},
+				{Name: "record macro", Aliases: []string{"m"}},
+			},
+			expected: []Item{
+				{Name: "move lines", Aliases: []string{"m"}, AcceptsRange: true, AcceptsArg: true, Arg: "0"},
+				{Name: "record macro", Aliases: []string{"m"}}, // fuzzy match, not an alias match
+			},
+		},
+		{
+			name:  "range before alias for item that does not accept a range, with items that accept a range",
+			query: "1,5w",
+			items: []Item{
+				{Name: "write", Aliases: []string{"w"}},
+				{Name: "delete lines", Aliases: []string{"d"}, AcceptsRange: true},
+			},
+			expected: nil,
+		},
 		{
 			name:  "commands",
 			query: "togle", // deliberate typo, should still fuzzy-match "toggle"
@@ -37,6 +37,11 @@ func (s *Selector) Mode() Mode {
 	return s.mode
 }
 
+// AnchorPos returns the position where the selection started.
+func (s *Selector) AnchorPos() uint64 {
+	return s.anchorPos
+}
+
 // SetMode sets the selection mode.
 func (s *Selector) SetMode(mode Mode) {
 	s.mode = mode
@@ -0,0 +1,74 @@
+package shellcmd
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Diagnostic represents a problem reported by a linter at a location in a file.
+type Diagnostic struct {
+	Path    string // Path to the file.
+	LineNum uint64 // Line number in the file, starting from one.
+	Col     uint64 // Column in the line, starting from one. Zero if the linter did not report a column.
+	Message string // Description of the problem.
+}
+
+// DiagnosticsFromLines parses each line as a diagnostic.
+// The supported formats are:
+//
+//	<file>:<line>:<col>: <message>
+//	<file>:<line>: <message>
+//
+// which most linters and compilers can output.
+// Lines in any other format, such as summaries, are skipped.
+func DiagnosticsFromLines(r io.Reader) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if d, ok := parseDiagnostic(strings.TrimSpace(scanner.Text())); ok {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "scanner.Scan")
+	}
+
+	return diagnostics, nil
+}
+
+func parseDiagnostic(s string) (Diagnostic, bool) {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) < 3 || parts[0] == "" {
+		return Diagnostic{}, false
+	}
+
+	lineNum, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || lineNum == 0 {
+		return Diagnostic{}, false
+	}
+
+	var col uint64
+	message := strings.Join(parts[2:], ":")
+	if len(parts) == 4 {
+		if c, err := strconv.ParseUint(parts[2], 10, 64); err == nil {
+			col, message = c, parts[3]
+		}
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return Diagnostic{}, false
+	}
+
+	return Diagnostic{
+		Path:    parts[0],
+		LineNum: lineNum,
+		Col:     col,
+		Message: message,
+	}, true
+}
@@ -0,0 +1,67 @@
+package shellcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsFromLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Diagnostic
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:  "file, line, and column",
+			input: "main.go:12:5: undefined: foo",
+			expected: []Diagnostic{
+				{Path: "main.go", LineNum: 12, Col: 5, Message: "undefined: foo"},
+			},
+		},
+		{
+			name:  "file and line",
+			input: "script.sh:3: missing quote",
+			expected: []Diagnostic{
+				{Path: "script.sh", LineNum: 3, Message: "missing quote"},
+			},
+		},
+		{
+			name:  "message with colons and no column",
+			input: "config.py:7: error: bad value: 3",
+			expected: []Diagnostic{
+				{Path: "config.py", LineNum: 7, Message: "error: bad value: 3"},
+			},
+		},
+		{
+			name:  "multiple lines",
+			input: "a.go:1:2: first\n\n  b.go:3:4: second  \n",
+			expected: []Diagnostic{
+				{Path: "a.go", LineNum: 1, Col: 2, Message: "first"},
+				{Path: "b.go", LineNum: 3, Col: 4, Message: "second"},
+			},
+		},
+		{
+			name:  "skip lines in other formats",
+			input: "Checking...\nmain.go:abc: not a line number\nmain.go:0:1: line zero\nmain.go:2:1:\nmain.go:4:1: problem\nFound 1 problem",
+			expected: []Diagnostic{
+				{Path: "main.go", LineNum: 4, Col: 1, Message: "problem"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diagnostics, err := DiagnosticsFromLines(strings.NewReader(tc.input))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, diagnostics)
+		})
+	}
+}
@@ -41,6 +41,28 @@ func RunAndCaptureOutput(ctx context.Context, cmd string, env []string) (string,
 	return buf.String(), nil
 }
 
+// RunAndCaptureCombinedOutput runs the command and returns its stdout and stderr as a single string.
+// Unlike RunAndCaptureOutput, a non-zero exit status is not an error,
+// because commands like linters exit with a failure status when they report problems.
+func RunAndCaptureCombinedOutput(ctx context.Context, cmd string, env []string) (string, error) {
+	var buf bytes.Buffer
+	err := runInShell(ctx, cmd, env, nil, &buf, &buf)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return "", ctxErr
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", err
+	}
+
+	if !utf8.Valid(buf.Bytes()) {
+		return "", errors.New("Shell command output is not valid UTF-8")
+	}
+
+	return buf.String(), nil
+}
+
 func clearTerminal(ctx context.Context) {
 	clearCmd := exec.CommandContext(ctx, "clear")
 	clearCmd.Stdout = os.Stdout
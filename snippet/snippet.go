@@ -0,0 +1,170 @@
+package snippet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stop is a position in the expanded text that the cursor visits in order.
+// The stop covers the runes from StartOffset (inclusive) to EndOffset (exclusive),
+// which is empty unless the stop has placeholder text.
+type Stop struct {
+	Num         int
+	StartOffset uint64
+	EndOffset   uint64
+}
+
+// Template is the text of a snippet with its tab stops.
+type Template struct {
+	Text  string
+	Stops []Stop // Ordered by the number of the stop, with $0 last.
+}
+
+// Parse parses a snippet template.
+//
+// The template text is inserted as written, except for:
+//   - "$1", "$2", etc. are tab stops, visited in order of their numbers.
+//   - "${1:name}" is a tab stop with placeholder text "name".
+//   - "$0" is the final tab stop. If the template has no "$0", the final stop is at the end of the text.
+//   - "\$" is a literal "$", and "\\" is a literal "\".
+//
+// Each tab stop number may appear at most once.
+func Parse(template string) (Template, error) {
+	var sb strings.Builder
+	var stops []Stop
+	var offset uint64
+	seen := make(map[int]struct{})
+	hasFinalStop := false
+
+	addStop := func(num int, placeholder string) error {
+		if _, ok := seen[num]; ok {
+			return fmt.Errorf("tab stop $%d appears more than once", num)
+		}
+		seen[num] = struct{}{}
+		if num == 0 {
+			hasFinalStop = true
+		}
+
+		stop := Stop{Num: num, StartOffset: offset}
+		for _, r := range placeholder {
+			sb.WriteRune(r)
+			offset++
+		}
+		stop.EndOffset = offset
+		stops = append(stops, stop)
+		return nil
+	}
+
+	runes := []rune(template)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes) && (runes[i+1] == '$' || runes[i+1] == '\\'):
+			sb.WriteRune(runes[i+1])
+			offset++
+			i++
+
+		case r == '$' && i+1 < len(runes) && isDigit(runes[i+1]):
+			num, n := parseNum(runes[i+1:])
+			if err := addStop(num, ""); err != nil {
+				return Template{}, err
+			}
+			i += n
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			num, n := parseNum(runes[i+2:])
+			if n == 0 {
+				return Template{}, fmt.Errorf("expected tab stop number after \"${\"")
+			}
+			j := i + 2 + n
+			var placeholder []rune
+			if j < len(runes) && runes[j] == ':' {
+				j++
+				for j < len(runes) && runes[j] != '}' {
+					placeholder = append(placeholder, runes[j])
+					j++
+				}
+			}
+			if j >= len(runes) || runes[j] != '}' {
+				return Template{}, fmt.Errorf("missing \"}\" for tab stop $%d", num)
+			}
+			if err := addStop(num, string(placeholder)); err != nil {
+				return Template{}, err
+			}
+			i = j
+
+		default:
+			sb.WriteRune(r)
+			offset++
+		}
+	}
+
+	if !hasFinalStop {
+		stops = append(stops, Stop{Num: 0, StartOffset: offset, EndOffset: offset})
+	}
+
+	sort.SliceStable(stops, func(i, j int) bool {
+		a, b := stops[i].Num, stops[j].Num
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+
+	return Template{Text: sb.String(), Stops: stops}, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// parseNum parses the decimal number at the start of runes, returning the number and how many runes it used.
+func parseNum(runes []rune) (int, int) {
+	var num, n int
+	for n < len(runes) && isDigit(runes[n]) && n < 6 {
+		num = num*10 + int(runes[n]-'0')
+		n++
+	}
+	return num, n
+}
+
+// WithIndent returns a copy of the template with indent inserted after each line feed,
+// so every line of the expanded text matches the indentation of the first line.
+func (t Template) WithIndent(indent string) Template {
+	if indent == "" {
+		return t
+	}
+
+	indentLen := uint64(len([]rune(indent)))
+	var sb strings.Builder
+	var lineStartOffsets []uint64 // Offsets in the original text where each indented line starts.
+	var offset uint64
+	for _, r := range t.Text {
+		sb.WriteRune(r)
+		offset++
+		if r == '\n' {
+			sb.WriteString(indent)
+			lineStartOffsets = append(lineStartOffsets, offset)
+		}
+	}
+
+	shift := func(offset uint64) uint64 {
+		// Offsets at the start of an indented line move past the indent.
+		n := sort.Search(len(lineStartOffsets), func(i int) bool {
+			return lineStartOffsets[i] > offset
+		})
+		return offset + uint64(n)*indentLen
+	}
+
+	stops := make([]Stop, len(t.Stops))
+	for i, stop := range t.Stops {
+		stops[i] = Stop{
+			Num:         stop.Num,
+			StartOffset: shift(stop.StartOffset),
+			EndOffset:   shift(stop.EndOffset),
+		}
+	}
+
+	return Template{Text: sb.String(), Stops: stops}
+}
@@ -0,0 +1,183 @@
+package snippet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		expected Template
+	}{
+		{
+			name:     "empty",
+			template: "",
+			expected: Template{
+				Text:  "",
+				Stops: []Stop{{Num: 0, StartOffset: 0, EndOffset: 0}},
+			},
+		},
+		{
+			name:     "no tab stops",
+			template: "hello",
+			expected: Template{
+				Text:  "hello",
+				Stops: []Stop{{Num: 0, StartOffset: 5, EndOffset: 5}},
+			},
+		},
+		{
+			name:     "numbered tab stops",
+			template: "for $1 := range $2 {\n\t$0\n}",
+			expected: Template{
+				Text: "for  := range  {\n\t\n}",
+				Stops: []Stop{
+					{Num: 1, StartOffset: 4, EndOffset: 4},
+					{Num: 2, StartOffset: 14, EndOffset: 14},
+					{Num: 0, StartOffset: 18, EndOffset: 18},
+				},
+			},
+		},
+		{
+			name:     "tab stops out of order",
+			template: "$2 $0 $1",
+			expected: Template{
+				Text: "  ",
+				Stops: []Stop{
+					{Num: 1, StartOffset: 2, EndOffset: 2},
+					{Num: 2, StartOffset: 0, EndOffset: 0},
+					{Num: 0, StartOffset: 1, EndOffset: 1},
+				},
+			},
+		},
+		{
+			name:     "placeholders",
+			template: "func ${1:name}(${2:args}) {}",
+			expected: Template{
+				Text: "func name(args) {}",
+				Stops: []Stop{
+					{Num: 1, StartOffset: 5, EndOffset: 9},
+					{Num: 2, StartOffset: 10, EndOffset: 14},
+					{Num: 0, StartOffset: 18, EndOffset: 18},
+				},
+			},
+		},
+		{
+			name:     "placeholder without text",
+			template: "${1}x",
+			expected: Template{
+				Text: "x",
+				Stops: []Stop{
+					{Num: 1, StartOffset: 0, EndOffset: 0},
+					{Num: 0, StartOffset: 1, EndOffset: 1},
+				},
+			},
+		},
+		{
+			name:     "multi-digit tab stop",
+			template: "$10$2",
+			expected: Template{
+				Text: "",
+				Stops: []Stop{
+					{Num: 2, StartOffset: 0, EndOffset: 0},
+					{Num: 10, StartOffset: 0, EndOffset: 0},
+					{Num: 0, StartOffset: 0, EndOffset: 0},
+				},
+			},
+		},
+		{
+			name:     "escaped dollar and backslash",
+			template: `\$1 \\$1 \n`,
+			expected: Template{
+				Text: `$1 \ \n`,
+				Stops: []Stop{
+					{Num: 1, StartOffset: 4, EndOffset: 4},
+					{Num: 0, StartOffset: 7, EndOffset: 7},
+				},
+			},
+		},
+		{
+			name:     "dollar without number",
+			template: "$x $",
+			expected: Template{
+				Text:  "$x $",
+				Stops: []Stop{{Num: 0, StartOffset: 4, EndOffset: 4}},
+			},
+		},
+		{
+			name:     "non-ascii placeholder",
+			template: "${1:café}!",
+			expected: Template{
+				Text: "café!",
+				Stops: []Stop{
+					{Num: 1, StartOffset: 0, EndOffset: 4},
+					{Num: 0, StartOffset: 5, EndOffset: 5},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template, err := Parse(tc.template)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, template)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		template    string
+		expectedErr string
+	}{
+		{
+			name:        "duplicate tab stop",
+			template:    "$1 $1",
+			expectedErr: "tab stop $1 appears more than once",
+		},
+		{
+			name:        "duplicate final tab stop",
+			template:    "$0 ${0:x}",
+			expectedErr: "tab stop $0 appears more than once",
+		},
+		{
+			name:        "missing closing brace",
+			template:    "${1:name",
+			expectedErr: `missing "}" for tab stop $1`,
+		},
+		{
+			name:        "missing number in braces",
+			template:    "${name}",
+			expectedErr: `expected tab stop number after "${"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.template)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestWithIndent(t *testing.T) {
+	template, err := Parse("if $1 {\n\t${2:body}\n}$0")
+	require.NoError(t, err)
+
+	indented := template.WithIndent("  ")
+	assert.Equal(t, Template{
+		Text: "if  {\n  \tbody\n  }",
+		Stops: []Stop{
+			{Num: 1, StartOffset: 3, EndOffset: 3},
+			{Num: 2, StartOffset: 9, EndOffset: 13},
+			{Num: 0, StartOffset: 17, EndOffset: 17},
+		},
+	}, indented)
+
+	assert.Equal(t, template, template.WithIndent(""))
+}
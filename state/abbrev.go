@@ -0,0 +1,64 @@
+package state
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/config"
+)
+
+// ExpandAbbreviation replaces the word before the cursor with its expansion
+// if the word is a user-defined abbreviation and the boundary rune cannot be part of a word.
+// Insert mode calls this before inserting the boundary rune, so typing "teh " expands "teh".
+// The word must start a line or follow a rune that cannot be part of a word,
+// so "xteh " does not expand "teh".
+func ExpandAbbreviation(state *EditorState, boundary rune) {
+	if len(state.abbreviations) == 0 || config.IsAbbreviationRune(boundary) {
+		return
+	}
+
+	word, wordStartPos := wordBeforeCursor(state)
+	if word == "" {
+		return
+	}
+
+	expansion, ok := state.abbreviations[word]
+	if !ok {
+		return
+	}
+
+	deleteRunes(state, wordStartPos, uint64(utf8.RuneCountInString(word)), true)
+	mustInsertTextAtPosition(state, expansion, wordStartPos, true)
+	state.documentBuffer.cursor = cursorState{
+		position: wordStartPos + uint64(utf8.RuneCountInString(expansion)),
+	}
+}
+
+// wordBeforeCursor returns the abbreviation runes immediately before the cursor and the position where they start.
+// If the rune before the cursor cannot be part of an abbreviation, the word is empty.
+func wordBeforeCursor(state *EditorState) (string, uint64) {
+	buffer := state.documentBuffer
+	cursorPos := buffer.cursor.position
+	reader := buffer.textTree.ReverseReaderAtPosition(cursorPos)
+	var wordRunes []rune
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		if !config.IsAbbreviationRune(r) {
+			break
+		}
+		wordRunes = append(wordRunes, r)
+	}
+
+	// The runes were read in reverse order.
+	for i, j := 0, len(wordRunes)-1; i < j; i, j = i+1, j-1 {
+		wordRunes[i], wordRunes[j] = wordRunes[j], wordRunes[i]
+	}
+
+	return string(wordRunes), cursorPos - uint64(len(wordRunes))
+}
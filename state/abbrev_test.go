@@ -0,0 +1,147 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestExpandAbbreviation(t *testing.T) {
+	abbreviations := map[string]string{
+		"teh":  "the",
+		"fn":   "func() {\n}",
+		"café": "coffee",
+	}
+
+	testCases := []struct {
+		name              string
+		inputString       string
+		cursorPos         uint64
+		boundary          rune
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "empty document",
+			inputString:       "",
+			cursorPos:         0,
+			boundary:          ' ',
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "expand at start of document",
+			inputString:       "teh",
+			cursorPos:         3,
+			boundary:          ' ',
+			expectedText:      "the",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "expand after space",
+			inputString:       "a teh",
+			cursorPos:         5,
+			boundary:          '.',
+			expectedText:      "a the",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "expand after punctuation",
+			inputString:       "(teh",
+			cursorPos:         4,
+			boundary:          ')',
+			expectedText:      "(the",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "expand at start of line",
+			inputString:       "abc\nteh",
+			cursorPos:         7,
+			boundary:          '\n',
+			expectedText:      "abc\nthe",
+			expectedCursorPos: 7,
+		},
+		{
+			name:              "expand in middle of line",
+			inputString:       "a teh b",
+			cursorPos:         5,
+			boundary:          ' ',
+			expectedText:      "a the b",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "expand to multiple lines",
+			inputString:       "fn",
+			cursorPos:         2,
+			boundary:          ' ',
+			expectedText:      "func() {\n}",
+			expectedCursorPos: 10,
+		},
+		{
+			name:              "expand non-ascii trigger",
+			inputString:       "café",
+			cursorPos:         4,
+			boundary:          ' ',
+			expectedText:      "coffee",
+			expectedCursorPos: 6,
+		},
+		{
+			name:              "word boundary rune does not expand",
+			inputString:       "teh",
+			cursorPos:         3,
+			boundary:          'x',
+			expectedText:      "teh",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "underscore does not expand",
+			inputString:       "teh",
+			cursorPos:         3,
+			boundary:          '_',
+			expectedText:      "teh",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "trigger within a longer word",
+			inputString:       "xteh",
+			cursorPos:         4,
+			boundary:          ' ',
+			expectedText:      "xteh",
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "cursor in the middle of the trigger",
+			inputString:       "teh",
+			cursorPos:         2,
+			boundary:          ' ',
+			expectedText:      "teh",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "not an abbreviation",
+			inputString:       "the",
+			cursorPos:         3,
+			boundary:          ' ',
+			expectedText:      "the",
+			expectedCursorPos: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.abbreviations = abbreviations
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.cursorPos
+			ExpandAbbreviation(state, tc.boundary)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package state
+
+// ringBell sets a flag that makes the terminal beep after the current action.
+func ringBell(state *EditorState) {
+	state.bellFlag = true
+}
+
+// ClearBellFlag clears the flag set by ringBell.
+// The main event loop calls this after it beeps.
+func ClearBellFlag(state *EditorState) {
+	state.bellFlag = false
+}
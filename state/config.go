@@ -25,6 +25,17 @@ func ToggleAutoIndent(s *EditorState) {
 	toggleFlagAndSetStatus(s, &s.documentBuffer.autoIndent, "Enabled auto-indent", "Disabled auto-indent")
 }
 
+// ToggleSearchHighlight enables or disables highlighting every match for the last search query.
+func ToggleSearchHighlight(s *EditorState) {
+	s.searchHighlightCleared = false
+	toggleFlagAndSetStatus(s, &s.documentBuffer.hlSearch, "Enabled search highlight", "Disabled search highlight")
+}
+
+// ToggleVirtualEdit enables or disables moving the cursor past the end of a line or into the middle of a tab.
+func ToggleVirtualEdit(s *EditorState) {
+	toggleFlagAndSetStatus(s, &s.documentBuffer.virtualEdit, "Enabled virtual edit", "Disabled virtual edit")
+}
+
 func toggleFlagAndSetStatus(s *EditorState, flagValue *bool, enabledMsg string, disabledMsg string) {
 	*flagValue = !(*flagValue)
 
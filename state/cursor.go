@@ -2,8 +2,8 @@ package state
 
 import (
 	"io"
+	"strings"
 
-	"github.com/aretext/aretext/cellwidth"
 	"github.com/aretext/aretext/locate"
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/text"
@@ -52,7 +52,7 @@ func MoveCursor(state *EditorState, loc Locator) {
 	}
 
 	var logicalOffset uint64
-	if newPos == cursorPos {
+	if newPos == cursorPos && !buffer.virtualEdit {
 		// This handles the case where the user is moving the cursor up to a shorter line,
 		// then tries to move the cursor to the right at the end of the line.
 		// The cursor doesn't actually move, so when the user moves up another line,
@@ -70,6 +70,7 @@ func MoveCursor(state *EditorState, loc Locator) {
 func MoveCursorToLineAbove(state *EditorState, count uint64) {
 	buffer := state.documentBuffer
 	targetLineStartPos := locate.StartOfLineAbove(buffer.textTree, count, buffer.cursor.position)
+	ringBellIfSameLine(state, targetLineStartPos)
 	moveCursorToLine(buffer, targetLineStartPos)
 }
 
@@ -77,26 +78,53 @@ func MoveCursorToLineAbove(state *EditorState, count uint64) {
 func MoveCursorToLineBelow(state *EditorState, count uint64) {
 	buffer := state.documentBuffer
 	targetLineStartPos := locate.StartOfLineBelow(buffer.textTree, count, buffer.cursor.position)
+	ringBellIfSameLine(state, targetLineStartPos)
 	moveCursorToLine(buffer, targetLineStartPos)
 }
 
+// MoveCursorToLineAt moves the cursor to the line containing the position returned by the locator.
+// If the startOfLine option is enabled, the cursor moves to the first non-whitespace character in the line.
+// Otherwise, it preserves the offset within the line, like MoveCursorToLineBelow.
+func MoveCursorToLineAt(state *EditorState, loc Locator) {
+	buffer := state.documentBuffer
+	targetLineStartPos := locate.StartOfLineAtPos(buffer.textTree, loc(locatorParamsForBuffer(buffer)))
+	if buffer.startOfLine {
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			return locate.NextNonWhitespaceOrNewline(params.TextTree, targetLineStartPos)
+		})
+	} else {
+		moveCursorToLine(buffer, targetLineStartPos)
+	}
+}
+
+// ringBellIfSameLine rings the bell if the boundaryBehavior option is "beep"
+// and a vertical motion could not leave the cursor's line because it is the first or last line.
+func ringBellIfSameLine(state *EditorState, targetLineStartPos uint64) {
+	buffer := state.documentBuffer
+	if buffer.beepAtBoundary && targetLineStartPos == locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position) {
+		ringBell(state)
+	}
+}
+
 func moveCursorToLine(buffer *BufferState, targetLineStartPos uint64) {
 	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
 	if targetLineStartPos == lineStartPos {
 		return
 	}
 
+	gcWidthFunc := buffer.gcWidthFunc()
+
 	targetOffset := findOffsetFromLineStart(
 		buffer.textTree,
 		lineStartPos,
 		buffer.cursor,
-		buffer.tabSize)
+		gcWidthFunc)
 
 	newPos, actualOffset := advanceToOffset(
 		buffer.textTree,
 		targetLineStartPos,
 		targetOffset,
-		buffer.tabSize)
+		gcWidthFunc)
 
 	buffer.cursor = cursorState{
 		position:      newPos,
@@ -104,7 +132,199 @@ func moveCursorToLine(buffer *BufferState, targetLineStartPos uint64) {
 	}
 }
 
-func findOffsetFromLineStart(textTree *text.Tree, lineStartPos uint64, cursor cursorState, tabSize uint64) uint64 {
+// MoveCursorToDisplayRowAbove moves the cursor up by the specified number of rows on the screen, preserving the column.
+// Unlike MoveCursorToLineAbove, this moves between the rows of a soft-wrapped line.
+func MoveCursorToDisplayRowAbove(state *EditorState, count uint64) {
+	buffer := state.documentBuffer
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	rows := displayRowsInLine(buffer, lineStartPos)
+	rowIdx := displayRowIdxForPos(rows, buffer.cursor.position)
+	targetOffset := findOffsetFromLineStart(buffer.textTree, rows[rowIdx].startPos, buffer.cursor, buffer.gcWidthFunc())
+	startLineStartPos, startRowIdx := lineStartPos, rowIdx
+
+	for ; count > 0; count-- {
+		if rowIdx > 0 {
+			rowIdx--
+			continue
+		}
+
+		prevLineStartPos := locate.StartOfLineAbove(buffer.textTree, 1, lineStartPos)
+		if prevLineStartPos == lineStartPos {
+			if buffer.beepAtBoundary && lineStartPos == startLineStartPos && rowIdx == startRowIdx {
+				ringBell(state)
+			}
+			break
+		}
+		lineStartPos = prevLineStartPos
+		rows = displayRowsInLine(buffer, lineStartPos)
+		rowIdx = len(rows) - 1
+	}
+
+	moveCursorToDisplayRow(buffer, rows[rowIdx], targetOffset)
+}
+
+// MoveCursorToDisplayRowBelow moves the cursor down by the specified number of rows on the screen, preserving the column.
+// Unlike MoveCursorToLineBelow, this moves between the rows of a soft-wrapped line.
+func MoveCursorToDisplayRowBelow(state *EditorState, count uint64) {
+	buffer := state.documentBuffer
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	rows := displayRowsInLine(buffer, lineStartPos)
+	rowIdx := displayRowIdxForPos(rows, buffer.cursor.position)
+	targetOffset := findOffsetFromLineStart(buffer.textTree, rows[rowIdx].startPos, buffer.cursor, buffer.gcWidthFunc())
+	startLineStartPos, startRowIdx := lineStartPos, rowIdx
+
+	for ; count > 0; count-- {
+		if rowIdx+1 < len(rows) {
+			rowIdx++
+			continue
+		}
+
+		nextLineStartPos := locate.StartOfLineBelow(buffer.textTree, 1, lineStartPos)
+		if nextLineStartPos == lineStartPos {
+			if buffer.beepAtBoundary && lineStartPos == startLineStartPos && rowIdx == startRowIdx {
+				ringBell(state)
+			}
+			break
+		}
+		lineStartPos = nextLineStartPos
+		rows = displayRowsInLine(buffer, lineStartPos)
+		rowIdx = 0
+	}
+
+	moveCursorToDisplayRow(buffer, rows[rowIdx], targetOffset)
+}
+
+// MoveCursorToViewCell moves the cursor to the character displayed at a cell on the screen.
+// A cell in the gutter moves the cursor to the start of the row, and a cell past the end of a row
+// moves the cursor to the last character in the row (or after it, in insert mode).
+// A row past the end of the document moves the cursor to the last row.
+// If the cell is outside the document view, the cursor does not move.
+func MoveCursorToViewCell(state *EditorState, col, row uint64) {
+	buffer := state.documentBuffer
+	if col < buffer.view.x || row < buffer.view.y {
+		return
+	}
+
+	col, row = col-buffer.view.x, row-buffer.view.y
+	if col >= buffer.view.width || row >= buffer.view.height {
+		return
+	}
+
+	var targetOffset uint64
+	gutterWidth := buffer.SignColumnWidth() + buffer.LineNumMarginWidth()
+	if col > gutterWidth {
+		targetOffset = col - gutterWidth
+	}
+
+	targetRow := displayRowInView(buffer, row)
+	moveCursorToDisplayRow(buffer, targetRow, targetOffset)
+
+	// In insert mode, a cell past the last character in the line moves the cursor after that character.
+	if state.inputMode == InputModeInsert && !buffer.virtualEdit && buffer.cursor.logicalOffset > 0 {
+		nextPos := locate.NextCharInLine(buffer.textTree, 1, true, buffer.cursor.position)
+		nextOffset := findOffsetFromLineStart(buffer.textTree, targetRow.startPos, cursorState{position: nextPos}, buffer.gcWidthFunc())
+		if nextOffset <= targetOffset && (nextPos < targetRow.endPos || nextPos == buffer.textTree.NumChars()) {
+			buffer.cursor = cursorState{position: nextPos}
+		}
+	}
+}
+
+// displayRowInView returns the row displayed at the given row of the document view.
+// If the row is past the end of the document, this returns the last row.
+func displayRowInView(buffer *BufferState, rowIdx uint64) displayRow {
+	wrappedLineIter := segment.NewWrappedLineIter(buffer.LineWrapConfig(), buffer.textTree, buffer.view.textOrigin)
+	wrappedLine := segment.Empty()
+	pos := buffer.view.textOrigin
+	row := displayRow{startPos: pos, endPos: pos}
+	prevHadNewline := false
+
+	for i := uint64(0); i <= rowIdx; i++ {
+		err := wrappedLineIter.NextSegment(wrappedLine)
+		if err == io.EOF {
+			if prevHadNewline {
+				// If the text ends with a newline, then there is one empty row at the end.
+				row = displayRow{startPos: pos, endPos: pos}
+			}
+			break
+		} else if err != nil {
+			panic(err)
+		}
+
+		row = displayRow{startPos: pos, endPos: pos + wrappedLine.NumRunes()}
+		pos = row.endPos
+		prevHadNewline = wrappedLine.HasNewline()
+	}
+
+	return row
+}
+
+// displayRow is a range of positions displayed on one row of the screen after soft-wrapping a line.
+type displayRow struct {
+	startPos uint64 // inclusive
+	endPos   uint64 // exclusive, including the newline at the end of the line.
+}
+
+// displayRowsInLine returns the rows displayed for the line starting at lineStartPos.
+// There is always at least one row, even for an empty line at the end of the document.
+func displayRowsInLine(buffer *BufferState, lineStartPos uint64) []displayRow {
+	wrappedLineIter := segment.NewWrappedLineIter(buffer.LineWrapConfig(), buffer.textTree, lineStartPos)
+	wrappedLine := segment.Empty()
+	pos := lineStartPos
+	var rows []displayRow
+
+	for {
+		err := wrappedLineIter.NextSegment(wrappedLine)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err)
+		}
+
+		row := displayRow{startPos: pos, endPos: pos + wrappedLine.NumRunes()}
+		rows = append(rows, row)
+		pos = row.endPos
+
+		if wrappedLine.HasNewline() {
+			break
+		}
+	}
+
+	if len(rows) == 0 {
+		rows = append(rows, displayRow{startPos: pos, endPos: pos})
+	}
+
+	return rows
+}
+
+// displayRowIdxForPos returns the index of the row where a cursor at pos is displayed.
+// A cursor at the end of a soft-wrapped row is displayed at the start of the next row.
+func displayRowIdxForPos(rows []displayRow, pos uint64) int {
+	for i, row := range rows {
+		if pos < row.endPos {
+			return i
+		}
+	}
+	return len(rows) - 1
+}
+
+func moveCursorToDisplayRow(buffer *BufferState, row displayRow, targetOffset uint64) {
+	gcWidthFunc := buffer.gcWidthFunc()
+	newPos, actualOffset := advanceToOffset(buffer.textTree, row.startPos, targetOffset, gcWidthFunc)
+
+	// If the row is soft-wrapped and shorter than the target offset,
+	// stop at the last character in the row rather than continuing onto the next row.
+	if newPos >= row.endPos && row.endPos > row.startPos {
+		newPos = locate.PrevChar(buffer.textTree, 1, row.endPos)
+		actualOffset = findOffsetFromLineStart(buffer.textTree, row.startPos, cursorState{position: newPos}, gcWidthFunc)
+	}
+
+	buffer.cursor = cursorState{
+		position:      newPos,
+		logicalOffset: targetOffset - actualOffset,
+	}
+}
+
+func findOffsetFromLineStart(textTree *text.Tree, lineStartPos uint64, cursor cursorState, gcWidthFunc segment.GraphemeClusterWidthFunc) uint64 {
 	reader := textTree.ReaderAtPosition(lineStartPos)
 	segmentIter := segment.NewGraphemeClusterIter(reader)
 	seg := segment.Empty()
@@ -118,19 +338,19 @@ func findOffsetFromLineStart(textTree *text.Tree, lineStartPos uint64, cursor cu
 			panic(err)
 		}
 
-		offset += cellwidth.GraphemeClusterWidth(seg.Runes(), offset, tabSize)
+		offset += gcWidthFunc(seg.Runes(), offset)
 		pos += seg.NumRunes()
 	}
 
 	return offset + cursor.logicalOffset
 }
 
-func advanceToOffset(textTree *text.Tree, lineStartPos uint64, targetOffset uint64, tabSize uint64) (uint64, uint64) {
+func advanceToOffset(textTree *text.Tree, lineStartPos uint64, targetOffset uint64, gcWidthFunc segment.GraphemeClusterWidthFunc) (uint64, uint64) {
 	reader := textTree.ReaderAtPosition(lineStartPos)
 	segmentIter := segment.NewGraphemeClusterIter(reader)
 	seg := segment.Empty()
 	var endOfLineOrFile bool
-	var prevPosOffset, posOffset, cellOffset uint64
+	var prevPosOffset, posOffset, prevCellOffset, cellOffset uint64
 
 	for {
 		err := segmentIter.NextSegment(seg)
@@ -146,26 +366,136 @@ func advanceToOffset(textTree *text.Tree, lineStartPos uint64, targetOffset uint
 			break
 		}
 
-		gcWidth := cellwidth.GraphemeClusterWidth(seg.Runes(), cellOffset, tabSize)
+		gcWidth := gcWidthFunc(seg.Runes(), cellOffset)
 		if cellOffset+gcWidth > targetOffset {
 			break
 		}
 
+		prevCellOffset = cellOffset
 		cellOffset += gcWidth
 		prevPosOffset = posOffset
 		posOffset += seg.NumRunes()
 	}
 
 	if endOfLineOrFile {
-		if cellOffset > 0 {
-			cellOffset--
-		}
-		return lineStartPos + prevPosOffset, cellOffset
+		// Return the offset at the start of the last character in the line,
+		// which may be more than one cell wide.
+		return lineStartPos + prevPosOffset, prevCellOffset
 	}
 
 	return lineStartPos + posOffset, cellOffset
 }
 
+// MoveCursorLeft moves the cursor left by the specified number of cells, stopping at the start of the line.
+// If virtual edit is enabled, the cursor moves one cell at a time through tabs and past the end of the line.
+func MoveCursorLeft(state *EditorState, count uint64) {
+	buffer := state.documentBuffer
+	if !buffer.virtualEdit {
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			return locate.PrevCharInLine(params.TextTree, count, false, params.CursorPos)
+		})
+		return
+	}
+
+	gcWidthFunc := buffer.gcWidthFunc()
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	cursor := buffer.cursor
+	for i := uint64(0); i < count; i++ {
+		if cursor.logicalOffset > 0 {
+			cursor.logicalOffset--
+			continue
+		}
+
+		prevPos := locate.PrevCharInLine(buffer.textTree, 1, false, cursor.position)
+		if prevPos == cursor.position {
+			break
+		}
+
+		// Move to the last cell of the previous character, which may be a tab.
+		offset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursor, gcWidthFunc)
+		prevOffset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursorState{position: prevPos}, gcWidthFunc)
+		cursor = cursorState{
+			position:      prevPos,
+			logicalOffset: offset - prevOffset - 1,
+		}
+	}
+	buffer.cursor = cursor
+}
+
+// MoveCursorRight moves the cursor right by the specified number of cells, stopping at the last character in the line.
+// If virtual edit is enabled, the cursor moves one cell at a time through tabs and can move past the end of the line.
+func MoveCursorRight(state *EditorState, count uint64) {
+	buffer := state.documentBuffer
+	if !buffer.virtualEdit {
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			return locate.NextCharInLine(params.TextTree, count, false, params.CursorPos)
+		})
+		return
+	}
+
+	gcWidthFunc := buffer.gcWidthFunc()
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	cursor := buffer.cursor
+	for i := uint64(0); i < count; i++ {
+		nextPos := locate.NextCharInLine(buffer.textTree, 1, false, cursor.position)
+		if nextPos == cursor.position {
+			// At the end of the line, so move into virtual space.
+			cursor.logicalOffset++
+			continue
+		}
+
+		// Move through each cell of a character wider than one cell, such as a tab.
+		offset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursorState{position: cursor.position}, gcWidthFunc)
+		nextOffset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursorState{position: nextPos}, gcWidthFunc)
+		if offset+cursor.logicalOffset+1 < nextOffset {
+			cursor.logicalOffset++
+			continue
+		}
+
+		cursor = cursorState{position: nextPos}
+	}
+	buffer.cursor = cursor
+}
+
+// fillVirtualSpace inserts spaces so the cursor position matches the column where the cursor is displayed.
+// This is used before inserting text when the cursor is past the end of a line or in the middle of a tab.
+func fillVirtualSpace(state *EditorState) {
+	buffer := state.documentBuffer
+	if !buffer.virtualEdit || buffer.cursor.logicalOffset == 0 {
+		return
+	}
+
+	pos, offset := buffer.cursor.position, buffer.cursor.logicalOffset
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, pos)
+	startOffset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursorState{position: pos}, buffer.gcWidthFunc())
+	nextPos := locate.NextCharInLine(buffer.textTree, 1, true, pos)
+	nextOffset := findOffsetFromLineStart(buffer.textTree, lineStartPos, cursorState{position: nextPos}, buffer.gcWidthFunc())
+	width := nextOffset - startOffset
+
+	if width == 0 {
+		// The cursor is on a line feed or at the end of the document, so pad before it.
+		mustInsertTextAtPosition(state, strings.Repeat(" ", int(offset)), pos, true)
+		pos += offset
+	} else if offset >= width {
+		// The cursor is past the last character in the line, so pad after it.
+		mustInsertTextAtPosition(state, strings.Repeat(" ", int(offset-width)), nextPos, true)
+		pos = nextPos + offset - width
+	} else if isTabAtPosition(buffer.textTree, pos) {
+		// The cursor is in the middle of a tab, so replace the tab with spaces.
+		deleteRunes(state, pos, 1, true)
+		mustInsertTextAtPosition(state, strings.Repeat(" ", int(width)), pos, true)
+		pos += offset
+	}
+
+	buffer.cursor = cursorState{position: pos}
+}
+
+func isTabAtPosition(textTree *text.Tree, pos uint64) bool {
+	reader := textTree.ReaderAtPosition(pos)
+	r, _, err := reader.ReadRune()
+	return err == nil && r == '\t'
+}
+
 // MoveCursorToStartOfSelection moves the cursor to the start of the current selection.
 // If nothing is selected, this does nothing.
 func MoveCursorToStartOfSelection(state *EditorState) {
@@ -173,6 +503,12 @@ func MoveCursorToStartOfSelection(state *EditorState) {
 		return
 	}
 	selectedRegion := state.documentBuffer.SelectedRegion()
+	if selectedRegion.EndPos > selectedRegion.StartPos {
+		// Anchor the selection at its end so the selected region doesn't change,
+		// which allows "gv" to reselect the same region later.
+		selector := state.documentBuffer.selector
+		selector.Start(selector.Mode(), selectedRegion.EndPos-1)
+	}
 	MoveCursor(state, func(p LocatorParams) uint64 {
 		return selectedRegion.StartPos
 	})
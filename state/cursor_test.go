@@ -281,6 +281,619 @@ func TestMoveCursorToLineBelow(t *testing.T) {
 	}
 }
 
+func TestMoveCursorUpAndDownWithVarTabStops(t *testing.T) {
+	// With tab stops at columns 4, 12, 20, ...
+	// the "x" on the first line and the "c" on the last line are both at column 12.
+	const inputString = "\t\tx\nabcdefghijklmnop\n\tab\tc"
+
+	testCases := []struct {
+		name           string
+		moveUp         bool
+		count          uint64
+		initialCursor  cursorState
+		expectedCursor cursorState
+	}{
+		{
+			name:           "move down from character after tabs",
+			count:          1,
+			initialCursor:  cursorState{position: 2},
+			expectedCursor: cursorState{position: 16},
+		},
+		{
+			name:           "move up to character after tabs",
+			moveUp:         true,
+			count:          1,
+			initialCursor:  cursorState{position: 16},
+			expectedCursor: cursorState{position: 2},
+		},
+		{
+			name:           "move up into second tab",
+			moveUp:         true,
+			count:          1,
+			initialCursor:  cursorState{position: 9},
+			expectedCursor: cursorState{position: 1, logicalOffset: 1},
+		},
+		{
+			name:           "move down to character after first tab",
+			count:          1,
+			initialCursor:  cursorState{position: 8},
+			expectedCursor: cursorState{position: 22},
+		},
+		{
+			name:           "move down into tab after characters",
+			count:          1,
+			initialCursor:  cursorState{position: 11},
+			expectedCursor: cursorState{position: 24, logicalOffset: 1},
+		},
+		{
+			name:           "move up between lines with mixed tabs",
+			moveUp:         true,
+			count:          2,
+			initialCursor:  cursorState{position: 25},
+			expectedCursor: cursorState{position: 2},
+		},
+		{
+			name:           "move down between lines with mixed tabs",
+			count:          2,
+			initialCursor:  cursorState{position: 2},
+			expectedCursor: cursorState{position: 25},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			state.documentBuffer.tabSize = 4
+			state.documentBuffer.varTabStops = []uint64{4, 8}
+			if tc.moveUp {
+				MoveCursorToLineAbove(state, tc.count)
+			} else {
+				MoveCursorToLineBelow(state, tc.count)
+			}
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+		})
+	}
+}
+
+func TestMoveCursorUpAndDownWithVirtualEdit(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abcdefgh\nab\n\nabcdefgh")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.virtualEdit = true
+	state.documentBuffer.cursor = cursorState{position: 6}
+
+	// Move down to a shorter line, so the cursor is past the end of the line.
+	MoveCursorToLineBelow(state, 1)
+	assert.Equal(t, cursorState{position: 10, logicalOffset: 5}, state.documentBuffer.cursor)
+	assert.Equal(t, uint64(5), state.documentBuffer.CursorVirtualOffset())
+
+	// Move down to an empty line, and the cursor stays in the same column.
+	MoveCursorToLineBelow(state, 1)
+	assert.Equal(t, cursorState{position: 12, logicalOffset: 6}, state.documentBuffer.cursor)
+	assert.Equal(t, uint64(6), state.documentBuffer.CursorVirtualOffset())
+
+	// Move down to a longer line, returning to the original column.
+	MoveCursorToLineBelow(state, 1)
+	assert.Equal(t, cursorState{position: 19}, state.documentBuffer.cursor)
+	assert.Equal(t, uint64(0), state.documentBuffer.CursorVirtualOffset())
+
+	// Move right into virtual space, then up, keeping the virtual column.
+	MoveCursorRight(state, 4)
+	assert.Equal(t, cursorState{position: 20, logicalOffset: 3}, state.documentBuffer.cursor)
+	MoveCursorToLineAbove(state, 2)
+	assert.Equal(t, cursorState{position: 10, logicalOffset: 9}, state.documentBuffer.cursor)
+
+	// Any other cursor movement leaves virtual space.
+	MoveCursor(state, func(params LocatorParams) uint64 {
+		return params.CursorPos
+	})
+	assert.Equal(t, cursorState{position: 10}, state.documentBuffer.cursor)
+}
+
+func TestMoveCursorToLineAt(t *testing.T) {
+	testCases := []struct {
+		name           string
+		startOfLine    bool
+		initialCursor  cursorState
+		targetPos      uint64
+		expectedCursor cursorState
+	}{
+		{
+			name:           "start of line enabled moves to first non-whitespace",
+			startOfLine:    true,
+			initialCursor:  cursorState{position: 5},
+			targetPos:      13,
+			expectedCursor: cursorState{position: 14},
+		},
+		{
+			name:           "start of line enabled on same line",
+			startOfLine:    true,
+			initialCursor:  cursorState{position: 15},
+			targetPos:      12,
+			expectedCursor: cursorState{position: 14},
+		},
+		{
+			name:           "start of line disabled keeps column",
+			startOfLine:    false,
+			initialCursor:  cursorState{position: 5},
+			targetPos:      13,
+			expectedCursor: cursorState{position: 17},
+		},
+		{
+			name:           "start of line disabled on shorter line",
+			startOfLine:    false,
+			initialCursor:  cursorState{position: 7},
+			targetPos:      9,
+			expectedCursor: cursorState{position: 10, logicalOffset: 6},
+		},
+		{
+			name:           "start of line disabled on same line",
+			startOfLine:    false,
+			initialCursor:  cursorState{position: 15},
+			targetPos:      12,
+			expectedCursor: cursorState{position: 15},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString("abcdefgh\nab\n  abcdefgh")
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.startOfLine = tc.startOfLine
+			state.documentBuffer.cursor = tc.initialCursor
+			MoveCursorToLineAt(state, func(LocatorParams) uint64 { return tc.targetPos })
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+		})
+	}
+}
+
+func TestMoveCursorAtBoundaryRingsBell(t *testing.T) {
+	testCases := []struct {
+		name           string
+		beepAtBoundary bool
+		cursorPos      uint64
+		moveFunc       func(*EditorState)
+		expectBell     bool
+	}{
+		{
+			name:           "line below from last line",
+			beepAtBoundary: true,
+			cursorPos:      4,
+			moveFunc:       func(state *EditorState) { MoveCursorToLineBelow(state, 1) },
+			expectBell:     true,
+		},
+		{
+			name:           "line above from first line",
+			beepAtBoundary: true,
+			cursorPos:      1,
+			moveFunc:       func(state *EditorState) { MoveCursorToLineAbove(state, 1) },
+			expectBell:     true,
+		},
+		{
+			name:           "line below from first line",
+			beepAtBoundary: true,
+			cursorPos:      1,
+			moveFunc:       func(state *EditorState) { MoveCursorToLineBelow(state, 1) },
+			expectBell:     false,
+		},
+		{
+			name:           "line below past last line moves as far as possible",
+			beepAtBoundary: true,
+			cursorPos:      1,
+			moveFunc:       func(state *EditorState) { MoveCursorToLineBelow(state, 5) },
+			expectBell:     false,
+		},
+		{
+			name:           "display row below from last line",
+			beepAtBoundary: true,
+			cursorPos:      4,
+			moveFunc:       func(state *EditorState) { MoveCursorToDisplayRowBelow(state, 1) },
+			expectBell:     true,
+		},
+		{
+			name:           "display row above from first line",
+			beepAtBoundary: true,
+			cursorPos:      1,
+			moveFunc:       func(state *EditorState) { MoveCursorToDisplayRowAbove(state, 1) },
+			expectBell:     true,
+		},
+		{
+			name:           "boundary behavior stay",
+			beepAtBoundary: false,
+			cursorPos:      4,
+			moveFunc:       func(state *EditorState) { MoveCursorToLineBelow(state, 1) },
+			expectBell:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString("abc\ndef")
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.beepAtBoundary = tc.beepAtBoundary
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			tc.moveFunc(state)
+			assert.Equal(t, tc.expectBell, state.BellFlag())
+			ClearBellFlag(state)
+			assert.False(t, state.BellFlag())
+		})
+	}
+}
+
+func TestMoveCursorLeftAndRight(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		virtualEdit    bool
+		moveLeft       bool
+		count          uint64
+		initialCursor  cursorState
+		expectedCursor cursorState
+	}{
+		{
+			name:           "right stops at end of line",
+			inputString:    "ab\ncd",
+			count:          3,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 1},
+		},
+		{
+			name:           "right past end of line with virtual edit",
+			inputString:    "ab\ncd",
+			virtualEdit:    true,
+			count:          3,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 1, logicalOffset: 2},
+		},
+		{
+			name:           "right on empty line with virtual edit",
+			inputString:    "\n",
+			virtualEdit:    true,
+			count:          2,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 0, logicalOffset: 2},
+		},
+		{
+			name:           "right over tab",
+			inputString:    "a\tb",
+			count:          2,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 2},
+		},
+		{
+			name:           "right into middle of tab with virtual edit",
+			inputString:    "a\tb",
+			virtualEdit:    true,
+			count:          2,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 1, logicalOffset: 1},
+		},
+		{
+			name:           "right through tab with virtual edit",
+			inputString:    "a\tb",
+			virtualEdit:    true,
+			count:          4,
+			initialCursor:  cursorState{position: 0},
+			expectedCursor: cursorState{position: 2},
+		},
+		{
+			name:           "left from virtual space with virtual edit",
+			inputString:    "ab",
+			virtualEdit:    true,
+			moveLeft:       true,
+			count:          1,
+			initialCursor:  cursorState{position: 1, logicalOffset: 3},
+			expectedCursor: cursorState{position: 1, logicalOffset: 2},
+		},
+		{
+			name:           "left into last cell of tab with virtual edit",
+			inputString:    "a\tb",
+			virtualEdit:    true,
+			moveLeft:       true,
+			count:          1,
+			initialCursor:  cursorState{position: 2},
+			expectedCursor: cursorState{position: 1, logicalOffset: 2},
+		},
+		{
+			name:           "left stops at start of line with virtual edit",
+			inputString:    "ab\ncd",
+			virtualEdit:    true,
+			moveLeft:       true,
+			count:          5,
+			initialCursor:  cursorState{position: 4, logicalOffset: 1},
+			expectedCursor: cursorState{position: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			state.documentBuffer.virtualEdit = tc.virtualEdit
+			if tc.moveLeft {
+				MoveCursorLeft(state, tc.count)
+			} else {
+				MoveCursorRight(state, tc.count)
+			}
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+		})
+	}
+}
+
+func TestMoveCursorToDisplayRow(t *testing.T) {
+	// With a view width of 5 and character breaks, the rows are:
+	//     abcde
+	//     fghij
+	//     kl
+	//     xy
+	//     mnopq
+	//     rst
+	const inputString = "abcdefghijkl\nxy\nmnopqrst"
+
+	testCases := []struct {
+		name           string
+		inputString    string
+		allowCharBreak bool
+		moveUp         bool
+		count          uint64
+		initialCursor  cursorState
+		expectedCursor cursorState
+	}{
+		{
+			name:           "down to next row in wrapped line",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          1,
+			initialCursor:  cursorState{position: 1},
+			expectedCursor: cursorState{position: 6},
+		},
+		{
+			name:           "down to last row in wrapped line",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          2,
+			initialCursor:  cursorState{position: 1},
+			expectedCursor: cursorState{position: 11},
+		},
+		{
+			name:           "down to shorter row",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          2,
+			initialCursor:  cursorState{position: 3},
+			expectedCursor: cursorState{position: 11, logicalOffset: 2},
+		},
+		{
+			name:           "down from shorter row preserves column",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          2,
+			initialCursor:  cursorState{position: 11, logicalOffset: 2},
+			expectedCursor: cursorState{position: 19},
+		},
+		{
+			name:           "down across lines",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          4,
+			initialCursor:  cursorState{position: 3},
+			expectedCursor: cursorState{position: 19},
+		},
+		{
+			name:           "down past last row",
+			inputString:    inputString,
+			allowCharBreak: true,
+			count:          5,
+			initialCursor:  cursorState{position: 22},
+			expectedCursor: cursorState{position: 22},
+		},
+		{
+			name:           "up to previous row in wrapped line",
+			inputString:    inputString,
+			allowCharBreak: true,
+			moveUp:         true,
+			count:          1,
+			initialCursor:  cursorState{position: 6},
+			expectedCursor: cursorState{position: 1},
+		},
+		{
+			name:           "up to last row of previous line",
+			inputString:    inputString,
+			allowCharBreak: true,
+			moveUp:         true,
+			count:          2,
+			initialCursor:  cursorState{position: 16},
+			expectedCursor: cursorState{position: 10},
+		},
+		{
+			name:           "up to shorter row",
+			inputString:    inputString,
+			allowCharBreak: true,
+			moveUp:         true,
+			count:          1,
+			initialCursor:  cursorState{position: 19},
+			expectedCursor: cursorState{position: 14, logicalOffset: 2},
+		},
+		{
+			name:           "up past first row",
+			inputString:    inputString,
+			allowCharBreak: true,
+			moveUp:         true,
+			count:          5,
+			initialCursor:  cursorState{position: 2},
+			expectedCursor: cursorState{position: 2},
+		},
+		{
+			name:           "up to row shortened by word wrap",
+			inputString:    "ab cdefg",
+			moveUp:         true,
+			count:          1,
+			initialCursor:  cursorState{position: 7},
+			expectedCursor: cursorState{position: 2, logicalOffset: 2},
+		},
+		{
+			name:           "down from row shortened by word wrap",
+			inputString:    "ab cdefg",
+			count:          1,
+			initialCursor:  cursorState{position: 2, logicalOffset: 2},
+			expectedCursor: cursorState{position: 7},
+		},
+		{
+			name:           "down to empty line",
+			inputString:    "abcdefg\n\nxyz",
+			allowCharBreak: true,
+			count:          2,
+			initialCursor:  cursorState{position: 2},
+			expectedCursor: cursorState{position: 8, logicalOffset: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(5, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			state.documentBuffer.lineWrapAllowCharBreaks = tc.allowCharBreak
+			if tc.moveUp {
+				MoveCursorToDisplayRowAbove(state, tc.count)
+			} else {
+				MoveCursorToDisplayRowBelow(state, tc.count)
+			}
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+		})
+	}
+}
+
+func TestMoveCursorToViewCell(t *testing.T) {
+	// With a view width of 10 and character breaks, the rows are:
+	//     abcdefghij
+	//     klmn
+	//     xy
+	//     (empty)
+	const inputString = "abcdefghijklmn\nxy\n"
+
+	testCases := []struct {
+		name           string
+		inputString    string
+		inputMode      InputMode
+		showLineNum    bool
+		col, row       uint64
+		expectedCursor cursorState
+	}{
+		{
+			name:           "empty document",
+			inputString:    "",
+			col:            3,
+			row:            1,
+			expectedCursor: cursorState{position: 0, logicalOffset: 3},
+		},
+		{
+			name:           "character in first row",
+			inputString:    inputString,
+			col:            3,
+			row:            0,
+			expectedCursor: cursorState{position: 3},
+		},
+		{
+			name:           "character in soft-wrapped row",
+			inputString:    inputString,
+			col:            2,
+			row:            1,
+			expectedCursor: cursorState{position: 12},
+		},
+		{
+			name:           "past end of line",
+			inputString:    inputString,
+			col:            7,
+			row:            2,
+			expectedCursor: cursorState{position: 16, logicalOffset: 6},
+		},
+		{
+			name:           "past end of line in insert mode",
+			inputString:    inputString,
+			inputMode:      InputModeInsert,
+			col:            7,
+			row:            2,
+			expectedCursor: cursorState{position: 17},
+		},
+		{
+			name:           "last character of line in insert mode",
+			inputString:    inputString,
+			inputMode:      InputModeInsert,
+			col:            1,
+			row:            2,
+			expectedCursor: cursorState{position: 16},
+		},
+		{
+			name:           "empty row at end of document",
+			inputString:    inputString,
+			col:            0,
+			row:            3,
+			expectedCursor: cursorState{position: 18},
+		},
+		{
+			name:           "row past end of document",
+			inputString:    "ab\ncd",
+			col:            1,
+			row:            3,
+			expectedCursor: cursorState{position: 4},
+		},
+		{
+			name:           "status bar",
+			inputString:    inputString,
+			col:            1,
+			row:            4,
+			expectedCursor: cursorState{position: 0},
+		},
+		{
+			name:           "gutter",
+			inputString:    "ab\ncd",
+			showLineNum:    true,
+			col:            1,
+			row:            1,
+			expectedCursor: cursorState{position: 3},
+		},
+		{
+			name:           "after gutter",
+			inputString:    "ab\ncd",
+			showLineNum:    true,
+			col:            4,
+			row:            1,
+			expectedCursor: cursorState{position: 4},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(10, 5, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.lineWrapAllowCharBreaks = true
+			state.documentBuffer.showLineNum = tc.showLineNum
+			if tc.inputMode == InputModeInsert {
+				SetInputMode(state, InputModeInsert)
+			}
+			MoveCursorToViewCell(state, tc.col, tc.row)
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+		})
+	}
+}
+
 func TestMoveCursorToStartOfSelection(t *testing.T) {
 	testCases := []struct {
 		name              string
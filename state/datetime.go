@@ -0,0 +1,136 @@
+package state
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/locate"
+)
+
+// InsertDate inserts the date at the cursor, formatted with a strftime-like format such as "%Y-%m-%d".
+// If the format is empty, this uses the configured date format.
+func InsertDate(state *EditorState, now time.Time, format string) {
+	if format == "" {
+		format = state.documentBuffer.dateFormat
+	}
+	insertTextAtCursor(state, strftime(now, format))
+}
+
+// InsertTime inserts the time at the cursor, formatted with a strftime-like format such as "%H:%M".
+// If the format is empty, this uses the configured time format.
+func InsertTime(state *EditorState, now time.Time, format string) {
+	if format == "" {
+		format = state.documentBuffer.timeFormat
+	}
+	insertTextAtCursor(state, strftime(now, format))
+}
+
+// InsertFileName inserts the name of the document's file at the cursor.
+func InsertFileName(state *EditorState) {
+	path := state.fileWatcher.Path()
+	if path == "" {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Document has no file name",
+		})
+		return
+	}
+	insertTextAtCursor(state, filepath.Base(path))
+}
+
+// insertTextAtCursor inserts text before the cursor, then moves the cursor to the last inserted character,
+// like pasting with "P".
+func insertTextAtCursor(state *EditorState, text string) {
+	if text == "" {
+		return
+	}
+
+	pos := state.documentBuffer.cursor.position
+	if err := insertTextAtPosition(state, text, pos, true); err != nil {
+		log.Printf("Error inserting text: %v\n", err)
+		return
+	}
+
+	MoveCursor(state, func(params LocatorParams) uint64 {
+		posAfterInsert := pos + uint64(utf8.RuneCountInString(text))
+		newPos := locate.PrevChar(params.TextTree, 1, posAfterInsert)
+		return locate.ClosestCharOnLine(params.TextTree, newPos)
+	})
+}
+
+// strftime formats a time using C strftime conversions like "%Y" for the year.
+// Unsupported conversions are copied to the output unchanged.
+func strftime(t time.Time, format string) string {
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 == len(format) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch format[i] {
+		case 'Y':
+			fmt.Fprintf(&sb, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&sb, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&sb, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&sb, "%02d", t.Day())
+		case 'e':
+			fmt.Fprintf(&sb, "%2d", t.Day())
+		case 'j':
+			fmt.Fprintf(&sb, "%03d", t.YearDay())
+		case 'H':
+			fmt.Fprintf(&sb, "%02d", t.Hour())
+		case 'I':
+			fmt.Fprintf(&sb, "%02d", (t.Hour()+11)%12+1)
+		case 'M':
+			fmt.Fprintf(&sb, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&sb, "%02d", t.Second())
+		case 'p':
+			sb.WriteString(t.Format("PM"))
+		case 'a':
+			sb.WriteString(t.Format("Mon"))
+		case 'A':
+			sb.WriteString(t.Format("Monday"))
+		case 'b', 'h':
+			sb.WriteString(t.Format("Jan"))
+		case 'B':
+			sb.WriteString(t.Format("January"))
+		case 'u':
+			fmt.Fprintf(&sb, "%d", (int(t.Weekday())+6)%7+1)
+		case 'w':
+			fmt.Fprintf(&sb, "%d", int(t.Weekday()))
+		case 'z':
+			sb.WriteString(t.Format("-0700"))
+		case 'Z':
+			sb.WriteString(t.Format("MST"))
+		case 's':
+			fmt.Fprintf(&sb, "%d", t.Unix())
+		case 'F':
+			sb.WriteString(strftime(t, "%Y-%m-%d"))
+		case 'T':
+			sb.WriteString(strftime(t, "%H:%M:%S"))
+		case 'R':
+			sb.WriteString(strftime(t, "%H:%M"))
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(format[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/text"
+)
+
+func TestStrftime(t *testing.T) {
+	now := time.Date(2023, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{name: "empty", format: "", expected: ""},
+		{name: "literal text", format: "today", expected: "today"},
+		{name: "date", format: "%Y-%m-%d", expected: "2023-03-05"},
+		{name: "short year", format: "%y", expected: "23"},
+		{name: "space-padded day", format: "%e", expected: " 5"},
+		{name: "day of year", format: "%j", expected: "064"},
+		{name: "24-hour time", format: "%H:%M:%S", expected: "14:07:09"},
+		{name: "12-hour time", format: "%I:%M %p", expected: "02:07 PM"},
+		{name: "weekday and month names", format: "%a %A %b %B", expected: "Sun Sunday Mar March"},
+		{name: "weekday numbers", format: "%u %w", expected: "7 0"},
+		{name: "time zone", format: "%z %Z", expected: "+0000 UTC"},
+		{name: "unix seconds", format: "%s", expected: "1678025229"},
+		{name: "shorthand", format: "%F %T %R", expected: "2023-03-05 14:07:09 14:07"},
+		{name: "escaped percent", format: "100%%", expected: "100%"},
+		{name: "unsupported conversion", format: "%Q", expected: "%Q"},
+		{name: "trailing percent", format: "%Y%", expected: "2023%"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, strftime(now, tc.format))
+		})
+	}
+}
+
+func TestInsertDateAndTime(t *testing.T) {
+	now := time.Date(2023, time.March, 5, 14, 7, 9, 0, time.UTC)
+
+	testCases := []struct {
+		name           string
+		inputString    string
+		initialCursor  cursorState
+		insertFunc     func(*EditorState)
+		expectedCursor cursorState
+		expectedText   string
+	}{
+		{
+			name:          "insert date with configured format",
+			inputString:   "ab",
+			initialCursor: cursorState{position: 1},
+			insertFunc: func(state *EditorState) {
+				InsertDate(state, now, "")
+			},
+			expectedCursor: cursorState{position: 10},
+			expectedText:   "a2023-03-05b",
+		},
+		{
+			name:          "insert date with explicit format",
+			inputString:   "ab",
+			initialCursor: cursorState{position: 1},
+			insertFunc: func(state *EditorState) {
+				InsertDate(state, now, "%d/%m/%Y")
+			},
+			expectedCursor: cursorState{position: 10},
+			expectedText:   "a05/03/2023b",
+		},
+		{
+			name:          "insert time with configured format",
+			inputString:   "",
+			initialCursor: cursorState{position: 0},
+			insertFunc: func(state *EditorState) {
+				InsertTime(state, now, "")
+			},
+			expectedCursor: cursorState{position: 4},
+			expectedText:   "14:07",
+		},
+		{
+			name:          "insert time with explicit format",
+			inputString:   "ab",
+			initialCursor: cursorState{position: 0},
+			insertFunc: func(state *EditorState) {
+				InsertTime(state, now, "%H:%M:%S ")
+			},
+			expectedCursor: cursorState{position: 8},
+			expectedText:   "14:07:09 ab",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			tc.insertFunc(state)
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
+func TestInsertDateConfiguredFormat(t *testing.T) {
+	now := time.Date(2023, time.March, 5, 14, 7, 9, 0, time.UTC)
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "journal",
+			Pattern: "**/*.md",
+			Config:  map[string]any{"dateFormat": "%A, %B %e", "timeFormat": "%I%p"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "journal.md")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	state := NewEditorState(100, 100, configRuleSet, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	InsertDate(state, now, "")
+	assert.Equal(t, "Sunday, March  5", state.documentBuffer.textTree.String())
+
+	LoadDocument(state, path, true, startOfDocLocator)
+	InsertTime(state, now, "")
+	assert.Equal(t, "02PM", state.documentBuffer.textTree.String())
+}
+
+func TestInsertFileName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("ab"), 0644))
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	state.documentBuffer.cursor = cursorState{position: 1}
+	InsertFileName(state)
+	assert.Equal(t, "anotes.txtb", state.documentBuffer.textTree.String())
+	assert.Equal(t, cursorState{position: 9}, state.documentBuffer.cursor)
+}
+
+func TestInsertFileNameWithoutFile(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	InsertFileName(state)
+	assert.Equal(t, "", state.documentBuffer.textTree.String())
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+	assert.Equal(t, "Document has no file name", state.statusMsg.Text)
+}
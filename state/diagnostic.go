@@ -0,0 +1,205 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/shellcmd"
+)
+
+// Diagnostic is a problem reported by a linter on a line of the document.
+type Diagnostic struct {
+	LineNum uint64 // Zero-indexed line number.
+	Col     uint64 // Zero-indexed column, measured in grapheme clusters.
+	Message string
+}
+
+// Diagnostics returns the diagnostics from the last linter run, sorted by line and column.
+func (s *BufferState) Diagnostics() []Diagnostic {
+	return s.diagnostics
+}
+
+// HasDiagnosticForLine returns whether the linter reported a problem on a line.
+func (s *BufferState) HasDiagnosticForLine(lineNum uint64) bool {
+	i := s.searchDiagnostics(lineNum)
+	return i < len(s.diagnostics) && s.diagnostics[i].LineNum == lineNum
+}
+
+// DiagnosticMessage returns the message of the first diagnostic on the line with the cursor.
+// If the line has no diagnostics, this returns an empty string.
+func (s *BufferState) DiagnosticMessage() string {
+	if len(s.diagnostics) == 0 {
+		return ""
+	}
+
+	lineNum := s.textTree.LineNumForPosition(s.cursor.position)
+	i := s.searchDiagnostics(lineNum)
+	if i == len(s.diagnostics) || s.diagnostics[i].LineNum != lineNum {
+		return ""
+	}
+	return s.diagnostics[i].Message
+}
+
+// searchDiagnostics returns the index of the first diagnostic at or after a line.
+func (s *BufferState) searchDiagnostics(lineNum uint64) int {
+	return sort.Search(len(s.diagnostics), func(i int) bool {
+		return s.diagnostics[i].LineNum >= lineNum
+	})
+}
+
+// runLinterIfConfigured runs the configured linter command asynchronously,
+// then replaces the document's diagnostics with the problems the linter reported for the document.
+func runLinterIfConfigured(state *EditorState) {
+	linter := state.documentBuffer.linter
+	if linter == "" {
+		return
+	}
+
+	log.Printf("Running linter: %q\n", linter)
+	path := state.fileWatcher.Path()
+	env := envVars(state) // Read-only copy of env vars is safe to pass to other goroutines.
+	StartTask(state, func(ctx context.Context) func(*EditorState) {
+		output, err := shellcmd.RunAndCaptureCombinedOutput(ctx, linter, env)
+		return func(state *EditorState) {
+			if err != nil {
+				SetStatusMsg(state, StatusMsg{
+					Style: StatusMsgStyleError,
+					Text:  fmt.Sprintf("Linter failed: %s", errors.Cause(err)),
+				})
+				return
+			}
+
+			if state.fileWatcher.Path() != path {
+				// The user opened a different document while the linter was running.
+				return
+			}
+
+			setDiagnosticsFromLinterOutput(state, output)
+		}
+	})
+}
+
+func setDiagnosticsFromLinterOutput(state *EditorState, output string) {
+	buffer := state.documentBuffer
+	buffer.diagnostics = nil
+
+	results, err := shellcmd.DiagnosticsFromLines(strings.NewReader(output))
+	if err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Could not parse linter output: %s", errors.Cause(err)),
+		})
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Printf("Error getting working directory: %v\n", err)
+		return
+	}
+
+	// The linter may report problems in other files, so keep only the ones for this document.
+	path := state.fileWatcher.Path()
+	numLines := buffer.textTree.NumLines()
+	for _, r := range results {
+		if absPath(r.Path, cwd) != path {
+			continue
+		}
+
+		lineNum := translateFileLocationLineNum(r.LineNum)
+		if lineNum >= numLines && numLines > 0 {
+			lineNum = numLines - 1
+		}
+
+		var col uint64
+		if r.Col > 0 {
+			col = r.Col - 1
+		}
+
+		buffer.diagnostics = append(buffer.diagnostics, Diagnostic{
+			LineNum: lineNum,
+			Col:     col,
+			Message: r.Message,
+		})
+	}
+
+	sort.SliceStable(buffer.diagnostics, func(i, j int) bool {
+		a, b := buffer.diagnostics[i], buffer.diagnostics[j]
+		return a.LineNum < b.LineNum || (a.LineNum == b.LineNum && a.Col < b.Col)
+	})
+
+	if n := len(buffer.diagnostics); n == 1 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Linter found 1 problem",
+		})
+	} else if n > 1 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Linter found %d problems", n),
+		})
+	}
+}
+
+// MoveCursorToNextDiagnostic moves the cursor to the next problem reported by the linter.
+// If reverse is true, it moves to the previous problem instead.
+// This wraps around at the start and end of the document.
+func MoveCursorToNextDiagnostic(state *EditorState, reverse bool) {
+	buffer := state.documentBuffer
+	diagnostics := buffer.diagnostics
+	if len(diagnostics) == 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No diagnostics",
+		})
+		return
+	}
+
+	cursorLineNum := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	var target Diagnostic
+	if reverse {
+		target = diagnostics[len(diagnostics)-1]
+		for i := len(diagnostics) - 1; i >= 0; i-- {
+			if diagnostics[i].LineNum < cursorLineNum {
+				target = diagnostics[i]
+				break
+			}
+		}
+	} else {
+		target = diagnostics[0]
+		for _, d := range diagnostics {
+			if d.LineNum > cursorLineNum {
+				target = d
+				break
+			}
+		}
+	}
+
+	buffer.cursor = cursorState{
+		position: locate.LineNumAndColToPos(buffer.textTree, target.LineNum, target.Col),
+	}
+}
+
+// adjustDiagnosticsForEdit updates diagnostics after an edit starting on lineNum
+// removed numLinesRemoved line feeds and added numLinesAdded line feeds.
+// Diagnostics on the edited lines are cleared, since the problem may have been fixed,
+// and diagnostics on later lines move with their lines.
+func adjustDiagnosticsForEdit(buffer *BufferState, lineNum uint64, numLinesRemoved uint64, numLinesAdded uint64) {
+	diagnostics := buffer.diagnostics[:0]
+	for _, d := range buffer.diagnostics {
+		if d.LineNum >= lineNum && d.LineNum <= lineNum+numLinesRemoved {
+			continue
+		} else if d.LineNum > lineNum {
+			d.LineNum = d.LineNum - numLinesRemoved + numLinesAdded
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	buffer.diagnostics = diagnostics
+}
@@ -0,0 +1,206 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/text"
+)
+
+func TestLinterOnSave(t *testing.T) {
+	setupShellCmdTest(t, func(state *EditorState, dir string) {
+		path := filepath.Join(dir, "test.txt")
+		otherPath := filepath.Join(dir, "other.txt")
+		require.NoError(t, os.WriteFile(path, []byte("ab\ncd\nef\n"), 0644))
+
+		// The linter reports problems in this document, another file, and a summary line,
+		// then exits with a failure status like most linters.
+		linter := "printf '%s:3:2: second problem\\n%s:1: other file\\n%s:1:1: first problem\\nFound 2 problems\\n' " +
+			"\"$FILEPATH\" " + otherPath + " \"$FILEPATH\"; exit 1"
+		state.configRuleSet = config.RuleSet{
+			{
+				Name:    "lint",
+				Pattern: "**/*.txt",
+				Config:  map[string]any{"linter": linter},
+			},
+		}
+		LoadDocument(state, path, true, startOfDocLocator)
+		defer state.fileWatcher.Stop()
+
+		SaveDocument(state)
+		assert.Equal(t, InputModeTask, state.InputMode())
+		select {
+		case action := <-state.TaskResultChan():
+			action(state)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "Timed out")
+		}
+
+		assert.Equal(t, []Diagnostic{
+			{LineNum: 0, Col: 0, Message: "first problem"},
+			{LineNum: 2, Col: 1, Message: "second problem"},
+		}, state.documentBuffer.Diagnostics())
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "Linter found 2 problems"}, state.StatusMsg())
+		assert.Equal(t, "first problem", state.documentBuffer.DiagnosticMessage())
+		assert.Equal(t, uint64(1), state.documentBuffer.SignColumnWidth())
+
+		// Reloading the document clears the diagnostics.
+		ReloadDocument(state)
+		assert.Empty(t, state.documentBuffer.Diagnostics())
+	})
+}
+
+func TestLinterFailed(t *testing.T) {
+	setupShellCmdTest(t, func(state *EditorState, dir string) {
+		path := filepath.Join(dir, "test.txt")
+		require.NoError(t, os.WriteFile(path, []byte("ab\n"), 0644))
+		state.configRuleSet = config.RuleSet{
+			{
+				Name:    "lint",
+				Pattern: "**/*.txt",
+				Config:  map[string]any{"linter": "printf '\\377'"},
+			},
+		}
+		LoadDocument(state, path, true, startOfDocLocator)
+		defer state.fileWatcher.Stop()
+
+		SaveDocument(state)
+		select {
+		case action := <-state.TaskResultChan():
+			action(state)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "Timed out")
+		}
+
+		assert.Empty(t, state.documentBuffer.Diagnostics())
+		assert.Equal(t, StatusMsgStyleError, state.StatusMsg().Style)
+		assert.Contains(t, state.StatusMsg().Text, "Linter failed")
+	})
+}
+
+func TestMoveCursorToNextDiagnostic(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cursorPos         uint64
+		reverse           bool
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "next diagnostic",
+			cursorPos:         0,
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "next diagnostic wraps to start",
+			cursorPos:         12,
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "prev diagnostic",
+			cursorPos:         12,
+			reverse:           true,
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "prev diagnostic wraps to end",
+			cursorPos:         0,
+			reverse:           true,
+			expectedCursorPos: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString("abc\ndefg\nhij\nklm")
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor = cursorState{position: tc.cursorPos}
+			buffer.diagnostics = []Diagnostic{
+				{LineNum: 1, Col: 1, Message: "first"},
+				{LineNum: 2, Col: 1, Message: "second"},
+			}
+			MoveCursorToNextDiagnostic(state, tc.reverse)
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+		})
+	}
+}
+
+func TestMoveCursorToNextDiagnosticNoDiagnostics(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	MoveCursorToNextDiagnostic(state, false)
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "No diagnostics"}, state.StatusMsg())
+}
+
+func TestDiagnosticsAdjustForEdits(t *testing.T) {
+	testCases := []struct {
+		name                string
+		editFunc            func(*EditorState)
+		expectedDiagnostics []Diagnostic
+	}{
+		{
+			name: "insert on line with diagnostic clears it",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "x", 5, true)
+			},
+			expectedDiagnostics: []Diagnostic{
+				{LineNum: 0, Message: "a"},
+				{LineNum: 3, Message: "d"},
+			},
+		},
+		{
+			name: "insert line feed moves later diagnostics",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "\n\n", 4, true)
+			},
+			expectedDiagnostics: []Diagnostic{
+				{LineNum: 0, Message: "a"},
+				{LineNum: 5, Message: "d"},
+			},
+		},
+		{
+			name: "delete lines clears their diagnostics and moves later diagnostics",
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 3, 5, true)
+			},
+			expectedDiagnostics: []Diagnostic{
+				{LineNum: 1, Message: "d"},
+			},
+		},
+		{
+			name: "edit on line without diagnostic",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "x", 8, true)
+			},
+			expectedDiagnostics: []Diagnostic{
+				{LineNum: 0, Message: "a"},
+				{LineNum: 1, Message: "b"},
+				{LineNum: 3, Message: "d"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString("abc\ndef\nghi\njkl")
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.diagnostics = []Diagnostic{
+				{LineNum: 0, Message: "a"},
+				{LineNum: 1, Message: "b"},
+				{LineNum: 3, Message: "d"},
+			}
+			tc.editFunc(state)
+			assert.Equal(t, tc.expectedDiagnostics, buffer.Diagnostics())
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"log"
+	"unicode/utf8"
+)
+
+// builtinDigraphs map two-character sequences to special characters.
+// These are a subset of the RFC 1345 digraphs used by vim.
+var builtinDigraphs = map[string]rune{
+	// Latin letters with diacritics.
+	"A!": 'À', "A'": 'Á', "A>": 'Â', "A?": 'Ã', "A:": 'Ä', "AA": 'Å', "AE": 'Æ',
+	"a!": 'à', "a'": 'á', "a>": 'â', "a?": 'ã', "a:": 'ä', "aa": 'å', "ae": 'æ',
+	"C,": 'Ç', "c,": 'ç',
+	"E!": 'È', "E'": 'É', "E>": 'Ê', "E:": 'Ë',
+	"e!": 'è', "e'": 'é', "e>": 'ê', "e:": 'ë',
+	"I!": 'Ì', "I'": 'Í', "I>": 'Î', "I:": 'Ï',
+	"i!": 'ì', "i'": 'í', "i>": 'î', "i:": 'ï',
+	"N?": 'Ñ', "n?": 'ñ',
+	"O!": 'Ò', "O'": 'Ó', "O>": 'Ô', "O?": 'Õ', "O:": 'Ö', "O/": 'Ø', "OE": 'Œ',
+	"o!": 'ò', "o'": 'ó', "o>": 'ô', "o?": 'õ', "o:": 'ö', "o/": 'ø', "oe": 'œ',
+	"U!": 'Ù', "U'": 'Ú', "U>": 'Û', "U:": 'Ü',
+	"u!": 'ù', "u'": 'ú', "u>": 'û', "u:": 'ü',
+	"Y'": 'Ý', "y'": 'ý', "y:": 'ÿ',
+	"ss": 'ß',
+
+	// Greek letters.
+	"A*": 'Α', "B*": 'Β', "G*": 'Γ', "D*": 'Δ', "E*": 'Ε', "Z*": 'Ζ', "Y*": 'Η', "H*": 'Θ',
+	"I*": 'Ι', "K*": 'Κ', "L*": 'Λ', "M*": 'Μ', "N*": 'Ν', "C*": 'Ξ', "O*": 'Ο', "P*": 'Π',
+	"R*": 'Ρ', "S*": 'Σ', "T*": 'Τ', "U*": 'Υ', "F*": 'Φ', "X*": 'Χ', "Q*": 'Ψ', "W*": 'Ω',
+	"a*": 'α', "b*": 'β', "g*": 'γ', "d*": 'δ', "e*": 'ε', "z*": 'ζ', "y*": 'η', "h*": 'θ',
+	"i*": 'ι', "k*": 'κ', "l*": 'λ', "m*": 'μ', "n*": 'ν', "c*": 'ξ', "o*": 'ο', "p*": 'π',
+	"r*": 'ρ', "s*": 'σ', "t*": 'τ', "u*": 'υ', "f*": 'φ', "x*": 'χ', "q*": 'ψ', "w*": 'ω',
+
+	// Arrows.
+	"<-": '←', "-!": '↑', "->": '→', "-v": '↓', "<>": '↔', "UD": '↕', "<=": '⇐', "=>": '⇒', "==": '⇔',
+
+	// Mathematical symbols.
+	"+-": '±', "*X": '×', "-:": '÷', "!=": '≠', "=<": '≤', ">=": '≥', "?=": '≅', "?2": '≈',
+	"00": '∞', "RT": '√', "FA": '∀', "dP": '∂', "TE": '∃', "/0": '∅', "(-": '∈', "-)": '∋',
+	"Iz": '∫', "*P": '∏', "+Z": '∑', "AN": '∧', "OR": '∨', "(U": '∩', ")U": '∪', "NO": '¬',
+
+	// Currency.
+	"Ct": '¢', "Pd": '£', "Ye": '¥', "Eu": '€',
+
+	// Punctuation and other symbols.
+	"!I": '¡', "?I": '¿', "<<": '«', ">>": '»', "SE": '§', "PI": '¶', "Co": '©', "Rg": '®',
+	"TM": '™', "DG": '°', "My": 'µ', ".M": '·', "NS": '\u00a0', "-N": '–', "-M": '—',
+	"'6": '‘', "'9": '’', "\"6": '“', "\"9": '”', ".,": '…', "oo": '•', "OK": '✓', "XX": '✗',
+	"1S": '¹', "2S": '²', "3S": '³', "14": '¼', "12": '½', "34": '¾',
+}
+
+// InsertDigraph inserts the character for a two-character digraph at the cursor.
+// User-defined digraphs from the config take precedence over the built-in digraphs.
+// As in vim, if the digraph is not defined, this also tries the characters in reverse order.
+// If neither order is defined, this does nothing.
+func InsertDigraph(state *EditorState, first rune, second rune) {
+	r, ok := lookupDigraph(state, first, second)
+	if !ok {
+		r, ok = lookupDigraph(state, second, first)
+	}
+
+	if !ok {
+		log.Printf("Unrecognized digraph %q\n", string([]rune{first, second}))
+		return
+	}
+
+	InsertRune(state, r)
+}
+
+func lookupDigraph(state *EditorState, first rune, second rune) (rune, bool) {
+	key := string([]rune{first, second})
+	if s, ok := state.customDigraphs[key]; ok {
+		// Safe b/c we validated that the config maps each digraph to exactly one rune.
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, true
+	}
+
+	r, ok := builtinDigraphs[key]
+	return r, ok
+}
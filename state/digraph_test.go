@@ -0,0 +1,93 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestInsertDigraph(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		customDigraphs    map[string]string
+		first, second     rune
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "builtin digraph",
+			inputString:       "bc",
+			initialCursorPos:  1,
+			first:             'a',
+			second:            ':',
+			expectedCursorPos: 2,
+			expectedText:      "bäc",
+		},
+		{
+			name:              "builtin digraph at end of document",
+			inputString:       "a ",
+			initialCursorPos:  2,
+			first:             '-',
+			second:            '>',
+			expectedCursorPos: 3,
+			expectedText:      "a →",
+		},
+		{
+			name:              "builtin digraph in reverse order",
+			inputString:       "",
+			initialCursorPos:  0,
+			first:             ':',
+			second:            'a',
+			expectedCursorPos: 1,
+			expectedText:      "ä",
+		},
+		{
+			name:              "custom digraph",
+			inputString:       "",
+			initialCursorPos:  0,
+			customDigraphs:    map[string]string{"ok": "👍"},
+			first:             'o',
+			second:            'k',
+			expectedCursorPos: 1,
+			expectedText:      "👍",
+		},
+		{
+			name:              "custom digraph overrides builtin",
+			inputString:       "",
+			initialCursorPos:  0,
+			customDigraphs:    map[string]string{"a:": "α"},
+			first:             'a',
+			second:            ':',
+			expectedCursorPos: 1,
+			expectedText:      "α",
+		},
+		{
+			name:              "unknown digraph",
+			inputString:       "abc",
+			initialCursorPos:  1,
+			first:             'q',
+			second:            'q',
+			expectedCursorPos: 1,
+			expectedText:      "abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor.position = tc.initialCursorPos
+			state.customDigraphs = tc.customDigraphs
+			InsertDigraph(state, tc.first, tc.second)
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/errors"
 
@@ -38,6 +40,7 @@ func LoadDocument(state *EditorState, path string, requireExists bool, cursorLoc
 	if !timelineState.Empty() {
 		state.fileTimeline.TransitionFrom(timelineState)
 	}
+	setAlternateDocument(state, timelineState)
 
 	setCursorAfterLoad(state, cursorLoc)
 
@@ -46,6 +49,7 @@ func LoadDocument(state *EditorState, path string, requireExists bool, cursorLoc
 	} else {
 		reportCreateSuccess(state, path)
 	}
+	reportProjectConfigError(state)
 }
 
 // ReloadDocument reloads the current document.
@@ -62,6 +66,8 @@ func ReloadDocument(state *EditorState) {
 	oldShowTabs := state.documentBuffer.showTabs
 	oldShowSpaces := state.documentBuffer.showSpaces
 	oldShowLineNum := state.documentBuffer.showLineNum
+	oldRelativeLineNum := state.documentBuffer.relativeLineNum
+	oldHlSearch := state.documentBuffer.hlSearch
 
 	// Reload the document.
 	_, err := loadDocumentAndResetState(state, path, true)
@@ -99,8 +105,11 @@ func ReloadDocument(state *EditorState) {
 	state.documentBuffer.showTabs = oldShowTabs
 	state.documentBuffer.showSpaces = oldShowSpaces
 	state.documentBuffer.showLineNum = oldShowLineNum
+	state.documentBuffer.relativeLineNum = oldRelativeLineNum
+	state.documentBuffer.hlSearch = oldHlSearch
 
 	reportReloadSuccess(state, path)
+	reportProjectConfigError(state)
 }
 
 func translateLineNum(lineMatches []text.LineMatch, lineNum uint64) uint64 {
@@ -139,10 +148,12 @@ func LoadPrevDocument(state *EditorState) {
 	}
 
 	state.fileTimeline.TransitionBackwardFrom(timelineState)
+	setAlternateDocument(state, timelineState)
 	setCursorAfterLoad(state, func(p LocatorParams) uint64 {
 		return locate.LineNumAndColToPos(p.TextTree, prev.LineNum, prev.Col)
 	})
 	reportOpenSuccess(state, path)
+	reportProjectConfigError(state)
 }
 
 // LoadNextDocument loads the next document from the timeline in the editor.
@@ -166,10 +177,59 @@ func LoadNextDocument(state *EditorState) {
 	}
 
 	state.fileTimeline.TransitionForwardFrom(timelineState)
+	setAlternateDocument(state, timelineState)
 	setCursorAfterLoad(state, func(p LocatorParams) uint64 {
 		return locate.LineNumAndColToPos(p.TextTree, next.LineNum, next.Col)
 	})
 	reportOpenSuccess(state, path)
+	reportProjectConfigError(state)
+}
+
+// LoadAlternateDocument loads the document that was open before the current document, like vim's ":e#".
+// The cursor is moved to its position from when the document was last open.
+// Loading the alternate document makes the current document the new alternate, so repeating this swaps between them.
+func LoadAlternateDocument(state *EditorState) {
+	alt := state.alternateDocument
+	if alt.Empty() {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No alternate document to open",
+		})
+		return
+	}
+
+	LoadDocument(state, alt.Path, false, func(p LocatorParams) uint64 {
+		lineNum := locate.ClosestValidLineNum(p.TextTree, alt.LineNum)
+		return locate.LineNumAndColToPos(p.TextTree, lineNum, alt.Col)
+	})
+}
+
+// EditDocument loads the document at a path, like vim's ":e".
+// The path "#" is the alternate document, and an empty path reloads the current document.
+// A relative path is relative to the working directory.
+func EditDocument(state *EditorState, path string) {
+	path = strings.TrimSpace(path)
+	switch path {
+	case "":
+		ReloadDocument(state)
+	case "#":
+		LoadAlternateDocument(state)
+	default:
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			reportLoadError(state, errors.Wrap(err, "filepath.Abs"), path)
+			return
+		}
+		LoadDocument(state, absPath, false, func(LocatorParams) uint64 { return 0 })
+	}
+}
+
+// setAlternateDocument records the document open before a load as the alternate document,
+// unless the load reopened the same document.
+func setAlternateDocument(state *EditorState, prevState file.TimelineState) {
+	if !prevState.Empty() && prevState.Path != state.fileWatcher.Path() {
+		state.alternateDocument = prevState
+	}
 }
 
 func currentTimelineState(state *EditorState) file.TimelineState {
@@ -183,7 +243,6 @@ func currentTimelineState(state *EditorState) file.TimelineState {
 }
 
 func loadDocumentAndResetState(state *EditorState, path string, requireExists bool) (fileExists bool, err error) {
-	cfg := state.configRuleSet.ConfigForPath(path)
 	tree, watcher, err := file.Load(path, file.DefaultPollInterval)
 	if err := errors.Cause(err); errors.Is(err, fs.ErrNotExist) && !requireExists {
 		tree = text.NewTree()
@@ -194,8 +253,11 @@ func loadDocumentAndResetState(state *EditorState, path string, requireExists bo
 		fileExists = true
 	}
 
+	cfg, configKeys, configErr := configForPath(state, path)
 	CancelTaskIfRunning(state)
+	state.projectConfigErr = configErr
 	state.documentLoadCount++
+	recordEvent(state, EventTypeDocumentLoaded)
 	state.documentBuffer.textTree = tree
 	state.fileWatcher.Stop()
 	state.fileWatcher = watcher
@@ -204,24 +266,137 @@ func loadDocumentAndResetState(state *EditorState, path string, requireExists bo
 	state.documentBuffer.cursor = cursorState{}
 	state.documentBuffer.view.textOrigin = 0
 	state.documentBuffer.selector.Clear()
+	state.documentBuffer.lastSelection = lastSelectionState{}
+	state.documentBuffer.extraCursors = nil
+	state.documentBuffer.diagnostics = nil
 	state.documentBuffer.search = searchState{}
 	state.documentBuffer.tabSize = uint64(cfg.TabSize) // safe b/c we validated the config.
+	state.documentBuffer.varTabStops = varTabStopsFromConfig(cfg)
 	state.documentBuffer.tabExpand = cfg.TabExpand
+	if cfg.DetectIndent {
+		applyGuessedIndentation(state, configKeys)
+	}
+	if cfg.Modeline {
+		applyModeline(state, configKeys)
+	}
 	state.documentBuffer.showTabs = cfg.ShowTabs
 	state.documentBuffer.showSpaces = cfg.ShowSpaces
 	state.documentBuffer.autoIndent = cfg.AutoIndent
+	state.documentBuffer.continueComments = cfg.ContinueComments
+	state.documentBuffer.autoCloseTags = cfg.AutoCloseTags
 	state.documentBuffer.showLineNum = cfg.ShowLineNumbers
+	state.documentBuffer.relativeLineNum = cfg.RelativeLineNumbers
+	state.documentBuffer.lineNumSeparator = lineNumSeparatorFromConfig(cfg)
+	state.documentBuffer.cursorLineNumAlign = cfg.CursorLineNumberAlign
+	state.documentBuffer.smartCase = cfg.SmartCase
+	state.documentBuffer.wrapScan = cfg.WrapScan
+	state.documentBuffer.hlSearch = cfg.HlSearch
 	state.documentBuffer.lineWrapAllowCharBreaks = bool(cfg.LineWrap == config.LineWrapCharacter)
+	state.documentBuffer.virtualEdit = bool(cfg.VirtualEdit == config.VirtualEditAll)
+	state.documentBuffer.startOfLine = cfg.StartOfLine
+	state.documentBuffer.beepAtBoundary = bool(cfg.BoundaryBehavior == config.BoundaryBehaviorBeep)
+	state.documentBuffer.reselectAfterUndo = cfg.ReselectAfterUndo
+	state.documentBuffer.matchPairs = matchPairsFromConfig(cfg)
+	state.documentBuffer.matchWords = matchWordsFromConfig(cfg)
+	state.documentBuffer.matchTags = cfg.MatchTags
+	state.documentBuffer.textWidth = uint64(cfg.TextWidth) // safe b/c we validated the config.
+	state.documentBuffer.colorColumns = colorColumnsFromConfig(cfg)
+	state.documentBuffer.commitSubjectWidth = uint64(cfg.CommitSubjectWidth) // safe b/c we validated the config.
+	state.documentBuffer.commitBodyWidth = uint64(cfg.CommitBodyWidth)       // safe b/c we validated the config.
+	state.documentBuffer.dateFormat = cfg.DateFormat
+	state.documentBuffer.timeFormat = cfg.TimeFormat
+	state.documentBuffer.multiCursor = cfg.MultiCursor
+	state.documentBuffer.linter = cfg.Linter
+	state.languageServer.disabled = false
+	state.documentBuffer.highlightYank = cfg.HighlightYank
+	state.documentBuffer.highlightYankDuration = time.Duration(cfg.HighlightYankDuration) * time.Millisecond
+	clearYankHighlight(state.documentBuffer)
 	state.documentBuffer.undoLog.TrackLoad()
 	state.menu = &MenuState{}
 	state.customMenuItems = customMenuItems(cfg)
 	state.dirPatternsToHide = cfg.HideDirectories
+	state.insertArrowKeys = cfg.InsertArrowKeys
+	state.mouseEnabled = cfg.Mouse
+	state.hyperlinksEnabled = cfg.Hyperlinks
+	state.cursorShapeNormal = cfg.CursorShapeNormal
+	state.cursorShapeInsert = cfg.CursorShapeInsert
+	state.cursorShapeVisual = cfg.CursorShapeVisual
+	state.showCmd = cfg.ShowCmd
+	state.customDigraphs = cfg.Digraphs
+	state.abbreviations = cfg.Abbreviations
+	state.snippets = cfg.Snippets
+	endSnippetSession(state)
+	state.writeBackup = cfg.WriteBackup
+	state.backupOptions = file.BackupOptions{Dir: cfg.BackupDir, MaxCount: cfg.BackupCount}
+	state.backupAbortOnError = cfg.BackupAbortOnError
+	state.autoWrite = cfg.AutoWrite
+	state.statusMsgTimeout = time.Duration(cfg.StatusMsgTimeout) * time.Second
 	state.styles = cfg.Styles
 	setSyntaxAndRetokenize(state.documentBuffer, syntax.Language(cfg.SyntaxLanguage))
+	loadGitDiffBase(state.documentBuffer, path)
 
 	return fileExists, nil
 }
 
+// EnableProjectConfig enables rules from project config files for documents loaded after this is called.
+func EnableProjectConfig(state *EditorState) {
+	state.projectConfigEnabled = true
+}
+
+// configForPath resolves the configuration for a document.
+// If project config is enabled, rules from the closest project config file apply after the user's rules.
+// If the project config cannot be loaded, this falls back to the user's rules and returns the error.
+// Options set globally while editing apply after all config rules.
+// This also returns the keys set explicitly by any matching rule or while editing.
+func configForPath(state *EditorState, path string) (config.Config, map[string]struct{}, error) {
+	ruleSet, err := ruleSetForPath(state, path)
+	if len(state.globalOptions) > 0 {
+		ruleSet = append(ruleSet[:len(ruleSet):len(ruleSet)], config.Rule{
+			Name:    "set",
+			Pattern: "**",
+			Config:  state.globalOptions,
+		})
+	}
+	return ruleSet.ConfigForPath(path), ruleSet.KeysForPath(path), err
+}
+
+func ruleSetForPath(state *EditorState, path string) (config.RuleSet, error) {
+	if !state.projectConfigEnabled {
+		return state.configRuleSet, nil
+	}
+
+	ruleSet := make(config.RuleSet, 0, len(state.configRuleSet)+1)
+	ruleSet = append(ruleSet, state.configRuleSet...)
+
+	editorConfig, err := config.LoadEditorConfig(path)
+	if err != nil {
+		log.Printf("Error loading EditorConfig for %q: %v\n", path, err)
+		err = errors.Wrapf(err, "%s", config.EditorConfigFileName)
+		return state.configRuleSet, err
+	} else if editorConfig != nil {
+		ruleSet = append(ruleSet, config.Rule{
+			Name:    config.EditorConfigFileName,
+			Pattern: "**",
+			Config:  editorConfig,
+		})
+	}
+
+	projectConfigPath := config.FindProjectConfig(path)
+	if projectConfigPath == "" {
+		return ruleSet, nil
+	}
+
+	projectRuleSet, err := config.LoadProjectRuleSet(projectConfigPath)
+	if err != nil {
+		log.Printf("Error loading project config from %q: %v\n", projectConfigPath, err)
+		err = errors.Wrapf(err, "%s", file.RelativePathCwd(projectConfigPath))
+		return ruleSet, err
+	}
+
+	ruleSet = append(ruleSet, projectRuleSet...)
+	return ruleSet, nil
+}
+
 func setCursorAfterLoad(state *EditorState, cursorLoc Locator) {
 	// First, scroll to the last line.
 	MoveCursor(state, func(p LocatorParams) uint64 {
@@ -236,6 +411,70 @@ func setCursorAfterLoad(state *EditorState, cursorLoc Locator) {
 	ScrollViewToCursor(state)
 }
 
+func varTabStopsFromConfig(cfg config.Config) []uint64 {
+	if len(cfg.VarTabStops) == 0 {
+		return nil
+	}
+
+	varTabStops := make([]uint64, 0, len(cfg.VarTabStops))
+	for _, w := range cfg.VarTabStops {
+		varTabStops = append(varTabStops, uint64(w)) // safe b/c we validated the config.
+	}
+	return varTabStops
+}
+
+func colorColumnsFromConfig(cfg config.Config) []uint64 {
+	cols, err := config.ParseColorColumn(cfg.ColorColumn, cfg.TextWidth)
+	if err != nil || len(cols) == 0 {
+		return nil
+	}
+
+	colorColumns := make([]uint64, 0, len(cols))
+	for _, c := range cols {
+		colorColumns = append(colorColumns, uint64(c-1)) // safe b/c we validated the config.
+	}
+	return colorColumns
+}
+
+func lineNumSeparatorFromConfig(cfg config.Config) rune {
+	if cfg.LineNumberSeparator == "" {
+		return ' '
+	}
+	r, _ := utf8.DecodeRuneInString(cfg.LineNumberSeparator)
+	return r
+}
+
+func matchPairsFromConfig(cfg config.Config) []locate.DelimiterPair {
+	pairs, err := config.ParseMatchPairs(cfg.MatchPairs)
+	if err != nil || len(pairs) == 0 {
+		return nil
+	}
+
+	matchPairs := make([]locate.DelimiterPair, 0, len(pairs))
+	for _, p := range pairs {
+		matchPairs = append(matchPairs, locate.DelimiterPair{Open: p.Open, Close: p.Close})
+	}
+	return matchPairs
+}
+
+func matchWordsFromConfig(cfg config.Config) []locate.KeywordGroup {
+	groups, err := config.ParseMatchWords(cfg.MatchWords)
+	if err != nil || len(groups) == 0 {
+		return nil
+	}
+
+	matchWords := make([]locate.KeywordGroup, 0, len(groups))
+	for _, g := range groups {
+		matchWords = append(matchWords, locate.KeywordGroup{
+			Open:          g.Open,
+			LineStartOpen: g.LineStartOpen,
+			Middle:        g.Middle,
+			Close:         g.Close,
+		})
+	}
+	return matchWords
+}
+
 func customMenuItems(cfg config.Config) []menu.Item {
 	// Deduplicate commands with the same name.
 	// Later commands take priority.
@@ -274,7 +513,9 @@ func actionForCustomMenuItem(cmd config.MenuCommandConfig) func(*EditorState) {
 		}
 	} else {
 		return func(state *EditorState) {
-			RunShellCmd(state, cmd.ShellCmd, cmd.Mode)
+			if autoWriteIfUnsavedChanges(state) {
+				RunShellCmd(state, cmd.ShellCmd, cmd.Mode)
+			}
 		}
 	}
 }
@@ -306,6 +547,19 @@ func reportReloadSuccess(state *EditorState, path string) {
 	})
 }
 
+// reportProjectConfigError replaces the status msg after loading a document
+// if the project config could not be loaded, so the user knows why it wasn't applied.
+func reportProjectConfigError(state *EditorState) {
+	if state.projectConfigErr == nil {
+		return
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  fmt.Sprintf("Could not load project config: %s", state.projectConfigErr),
+	})
+}
+
 func reportLoadError(state *EditorState, err error, path string) {
 	log.Printf("Error loading file at %q: %v\n", path, err)
 	SetStatusMsg(state, StatusMsg{
@@ -317,6 +571,11 @@ func reportLoadError(state *EditorState, err error, path string) {
 // SaveDocument saves the currently loaded document to disk.
 func SaveDocument(state *EditorState) {
 	path := state.fileWatcher.Path()
+	ok, backupErr := backupBeforeSave(state, path)
+	if !ok {
+		return
+	}
+
 	tree := state.documentBuffer.textTree
 	newWatcher, err := file.Save(path, tree, file.DefaultPollInterval)
 	if err != nil {
@@ -327,7 +586,11 @@ func SaveDocument(state *EditorState) {
 	state.fileWatcher.Stop()
 	state.fileWatcher = newWatcher
 	state.documentBuffer.undoLog.TrackSave()
+	loadGitDiffBase(state.documentBuffer, path)
+	recordEvent(state, EventTypeDocumentSaved)
 	reportSaveSuccess(state, path)
+	reportBackupWarning(state, backupErr, path)
+	runLinterIfConfigured(state)
 }
 
 // SaveDocumentIfUnsavedChanges saves the document only if it has been edited
@@ -341,6 +604,172 @@ func SaveDocumentIfUnsavedChanges(state *EditorState) {
 	}
 }
 
+// WriteDocumentToPath writes the document to a different file without changing the path of the loaded document.
+// If the target file already exists, this aborts unless force is true.
+func WriteDocumentToPath(state *EditorState, path string, force bool) {
+	path, ok := prepareSaveToPath(state, path, force)
+	if !ok {
+		return
+	} else if path == state.fileWatcher.Path() {
+		saveDocumentToCurrentPath(state, force)
+		return
+	}
+
+	ok, backupErr := backupBeforeSave(state, path)
+	if !ok {
+		return
+	}
+
+	err := file.SaveCopy(path, state.documentBuffer.textTree)
+	if err != nil {
+		reportSaveError(state, err, path)
+		return
+	}
+
+	log.Printf("Successfully wrote copy of document to %q", path)
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  fmt.Sprintf("Wrote %s", file.RelativePathCwd(path)),
+	})
+	reportBackupWarning(state, backupErr, path)
+}
+
+// SaveDocumentAs saves the document to a different file, then continues editing the document at the new path.
+// If the new path has a different file extension, this detects the syntax language again.
+// If the target file already exists, this aborts unless force is true.
+func SaveDocumentAs(state *EditorState, path string, force bool) {
+	path, ok := prepareSaveToPath(state, path, force)
+	if !ok {
+		return
+	} else if path == state.fileWatcher.Path() {
+		saveDocumentToCurrentPath(state, force)
+		return
+	}
+
+	ok, backupErr := backupBeforeSave(state, path)
+	if !ok {
+		return
+	}
+
+	tree := state.documentBuffer.textTree
+	newWatcher, err := file.Save(path, tree, file.DefaultPollInterval)
+	if err != nil {
+		reportSaveError(state, err, path)
+		return
+	}
+
+	oldPath := state.fileWatcher.Path()
+	state.fileWatcher.Stop()
+	state.fileWatcher = newWatcher
+	state.documentBuffer.undoLog.TrackSave()
+	loadGitDiffBase(state.documentBuffer, path)
+
+	if filepath.Ext(path) != filepath.Ext(oldPath) {
+		cfg, _, _ := configForPath(state, path)
+		setSyntaxAndRetokenize(state.documentBuffer, syntax.Language(cfg.SyntaxLanguage))
+		state.documentBuffer.linter = cfg.Linter
+	}
+
+	recordEvent(state, EventTypeDocumentSaved)
+	reportSaveSuccess(state, path)
+	reportBackupWarning(state, backupErr, path)
+	runLinterIfConfigured(state)
+}
+
+// prepareSaveToPath resolves the absolute path for saving the document and creates any missing parent directories.
+// If the path is not the current document's path, this checks that the file does not already exist unless force is true.
+// It returns false and shows an error status msg if the document should not be saved.
+func prepareSaveToPath(state *EditorState, path string, force bool) (string, bool) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No file name specified",
+		})
+		return "", false
+	}
+
+	resolvedPath, err := filepath.Abs(path)
+	if err != nil {
+		reportSaveError(state, errors.Wrap(err, "filepath.Abs"), path)
+		return "", false
+	}
+	path = resolvedPath
+
+	if path == state.fileWatcher.Path() {
+		return path, true
+	}
+
+	if _, err := os.Stat(path); err == nil && !force {
+		log.Printf("Aborting save because file already exists at %q\n", path)
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("%s already exists.  Add \"!\" to overwrite.", file.RelativePathCwd(path)),
+		})
+		return "", false
+	}
+
+	dirPath := filepath.Dir(path)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		log.Printf("Error creating directory %q: %v", dirPath, err)
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Could not create directory %q: %s", file.RelativePathCwd(dirPath), errors.Cause(err)),
+		})
+		return "", false
+	}
+
+	return path, true
+}
+
+// saveDocumentToCurrentPath saves the document to its current path,
+// aborting if the file changed on disk unless force is true.
+func saveDocumentToCurrentPath(state *EditorState, force bool) {
+	if force {
+		SaveDocument(state)
+	} else {
+		AbortIfFileExistsWithChangedContent(state, SaveDocument)
+	}
+}
+
+// backupBeforeSave copies the file at path to a backup before it is overwritten, if backups are enabled.
+// If the backup fails and the config requires a backup, this shows an error status msg and returns false.
+// Otherwise, it returns the backup error (if any) so the caller can warn the user after saving.
+func backupBeforeSave(state *EditorState, path string) (bool, error) {
+	if !state.writeBackup {
+		return true, nil
+	}
+
+	backupPath, err := file.Backup(path, state.backupOptions, time.Now())
+	if err != nil {
+		log.Printf("Error backing up file %q: %v", path, err)
+		if state.backupAbortOnError {
+			SetStatusMsg(state, StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  fmt.Sprintf("Could not back up %q: %s", file.RelativePathCwd(path), errors.Cause(err)),
+			})
+			return false, nil
+		}
+		return true, err
+	}
+
+	if backupPath != "" {
+		log.Printf("Backed up file %q to %q", path, backupPath)
+	}
+	return true, nil
+}
+
+func reportBackupWarning(state *EditorState, backupErr error, path string) {
+	if backupErr == nil {
+		return
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  fmt.Sprintf("Saved %s, but could not back up the previous version: %s", file.RelativePathCwd(path), errors.Cause(backupErr)),
+	})
+}
+
 func reportSaveError(state *EditorState, err error, path string) {
 	log.Printf("Error saving file to %q: %v", path, err)
 	SetStatusMsg(state, StatusMsg{
@@ -372,6 +801,27 @@ func AbortIfUnsavedChanges(state *EditorState, f func(*EditorState), showStatus
 	}
 }
 
+// AutoWriteOrAbortIfUnsavedChanges executes a function that replaces the document, like AbortIfUnsavedChanges,
+// except that if autoWrite is enabled, it first saves the document instead of aborting, like vim's "autowrite".
+// If the save fails, the function is not executed.
+func AutoWriteOrAbortIfUnsavedChanges(state *EditorState, f func(*EditorState), showStatus bool) {
+	if autoWriteIfUnsavedChanges(state) {
+		AbortIfUnsavedChanges(state, f, showStatus)
+	}
+}
+
+// autoWriteIfUnsavedChanges saves the document if autoWrite is enabled and the document has unsaved changes.
+// It returns false if the save failed, in which case the error has already been reported.
+func autoWriteIfUnsavedChanges(state *EditorState) bool {
+	if !state.autoWrite || !state.documentBuffer.undoLog.HasUnsavedChanges() || state.fileWatcher.Path() == "" {
+		return true
+	}
+
+	log.Printf("Automatically saving document with unsaved changes\n")
+	AbortIfFileExistsWithChangedContent(state, SaveDocument)
+	return !state.documentBuffer.undoLog.HasUnsavedChanges()
+}
+
 // AbortIfFileExistsWithChangedContent aborts with an error message if the file exists with a different checksum than the last load/save.
 func AbortIfFileExistsWithChangedContent(state *EditorState, f func(*EditorState)) {
 	path := state.fileWatcher.Path()
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/file"
 	"github.com/aretext/aretext/syntax"
 )
 
@@ -160,6 +162,70 @@ func TestLoadNextDocument(t *testing.T) {
 	assert.Equal(t, uint64(5), state.documentBuffer.cursor.position)
 }
 
+func TestLoadAlternateDocument(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+
+	// No alternate document before loading a second document.
+	LoadAlternateDocument(state)
+	assert.Equal(t, "No alternate document to open", state.statusMsg.Text)
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+
+	// Load a document and move the cursor to the third line.
+	path, cleanup := createTestFile(t, "abcd\nefghi\njklmnop\nqrst")
+	defer cleanup()
+	LoadDocument(state, path, true, startOfDocLocator)
+	MoveCursor(state, func(LocatorParams) uint64 { return 13 })
+
+	// Load another document and move the cursor to the second line.
+	path2, cleanup2 := createTestFile(t, "qrs\ntuv\nwxyz")
+	defer cleanup2()
+	LoadDocument(state, path2, true, startOfDocLocator)
+	MoveCursor(state, func(LocatorParams) uint64 { return 5 })
+
+	// Swap to the first document.
+	LoadAlternateDocument(state)
+	assert.Equal(t, path, state.fileWatcher.Path())
+	assert.Equal(t, uint64(13), state.documentBuffer.cursor.position)
+
+	// Swap back to the second document.
+	LoadAlternateDocument(state)
+	assert.Equal(t, path2, state.fileWatcher.Path())
+	assert.Equal(t, uint64(5), state.documentBuffer.cursor.position)
+
+	// Reloading the document keeps the alternate document.
+	ReloadDocument(state)
+	assert.Equal(t, path, state.alternateDocument.Path)
+
+	// Returning to the previous document from the timeline also sets the alternate document.
+	LoadPrevDocument(state)
+	assert.Equal(t, path2, state.alternateDocument.Path)
+}
+
+func TestEditDocument(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	path, cleanup := createTestFile(t, "abcd")
+	defer cleanup()
+	path2, cleanup2 := createTestFile(t, "efgh")
+	defer cleanup2()
+
+	EditDocument(state, path)
+	assert.Equal(t, path, state.fileWatcher.Path())
+	assert.Equal(t, "abcd", state.documentBuffer.textTree.String())
+
+	EditDocument(state, " "+path2+" ")
+	assert.Equal(t, path2, state.fileWatcher.Path())
+	assert.Equal(t, "efgh", state.documentBuffer.textTree.String())
+
+	EditDocument(state, "#")
+	assert.Equal(t, path, state.fileWatcher.Path())
+
+	err := os.WriteFile(path, []byte("xyz"), 0644)
+	require.NoError(t, err)
+	EditDocument(state, "")
+	assert.Equal(t, path, state.fileWatcher.Path())
+	assert.Equal(t, "xyz", state.documentBuffer.textTree.String())
+}
+
 func TestLoadDocumentIncrementLoadCount(t *testing.T) {
 	// Start with an empty document.
 	state := NewEditorState(100, 100, nil, nil)
@@ -175,6 +241,163 @@ func TestLoadDocumentIncrementLoadCount(t *testing.T) {
 	assert.Equal(t, state.DocumentLoadCount(), 1)
 }
 
+func TestLoadDocumentProjectConfig(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "global",
+			Pattern: "**",
+			Config:  map[string]any{"tabSize": 2, "tabExpand": true},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		enabled           bool
+		projectConfig     string
+		expectedTabSize   uint64
+		expectedTabExpand bool
+		expectedStatusMsg StatusMsg
+	}{
+		{
+			name:              "project config overrides global config",
+			enabled:           true,
+			projectConfig:     "- name: project\n  pattern: \"**/*.go\"\n  config:\n    tabSize: 8\n",
+			expectedTabSize:   8,
+			expectedTabExpand: true,
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: "Opened"},
+		},
+		{
+			name:              "project config disabled",
+			enabled:           false,
+			projectConfig:     "- name: project\n  pattern: \"**/*.go\"\n  config:\n    tabSize: 8\n",
+			expectedTabSize:   2,
+			expectedTabExpand: true,
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: "Opened"},
+		},
+		{
+			name:              "project config parse error falls back to global config",
+			enabled:           true,
+			projectConfig:     "- name: [",
+			expectedTabSize:   2,
+			expectedTabExpand: true,
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleError, Text: "Could not load project config"},
+		},
+		{
+			name:              "invalid project config falls back to global config",
+			enabled:           true,
+			projectConfig:     "- name: project\n  pattern: \"**\"\n  config:\n    tabSize: 0\n",
+			expectedTabSize:   2,
+			expectedTabExpand: true,
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleError, Text: "Could not load project config"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, config.ProjectConfigFileName), []byte(tc.projectConfig), 0644)
+			require.NoError(t, err)
+
+			path := filepath.Join(dir, "src", "main.go")
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			require.NoError(t, err)
+			err = os.WriteFile(path, []byte("package main"), 0644)
+			require.NoError(t, err)
+
+			state := NewEditorState(100, 100, configRuleSet, nil)
+			if tc.enabled {
+				EnableProjectConfig(state)
+			}
+			LoadDocument(state, path, true, startOfDocLocator)
+			defer state.fileWatcher.Stop()
+
+			assert.Equal(t, tc.expectedTabSize, state.documentBuffer.tabSize)
+			assert.Equal(t, tc.expectedTabExpand, state.documentBuffer.tabExpand)
+			assert.Equal(t, tc.expectedStatusMsg.Style, state.statusMsg.Style)
+			assert.Contains(t, state.statusMsg.Text, tc.expectedStatusMsg.Text)
+		})
+	}
+}
+
+func TestLoadDocumentEditorConfig(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "global",
+			Pattern: "**",
+			Config:  map[string]any{"autoIndent": true},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		enabled           bool
+		editorConfig      string
+		projectConfig     string
+		expectedTabSize   uint64
+		expectedTabExpand bool
+	}{
+		{
+			name:              "editorconfig overrides global config",
+			enabled:           true,
+			editorConfig:      "root = true\n[*.go]\nindent_style = tab\ntab_width = 8\n",
+			expectedTabSize:   8,
+			expectedTabExpand: false,
+		},
+		{
+			name:              "editorconfig disabled",
+			enabled:           false,
+			editorConfig:      "root = true\n[*.go]\nindent_style = tab\ntab_width = 8\n",
+			expectedTabSize:   3,
+			expectedTabExpand: true,
+		},
+		{
+			name:              "editorconfig section does not match",
+			enabled:           true,
+			editorConfig:      "root = true\n[*.py]\nindent_style = tab\ntab_width = 8\n",
+			expectedTabSize:   3,
+			expectedTabExpand: true,
+		},
+		{
+			name:              "project config overrides editorconfig",
+			enabled:           true,
+			editorConfig:      "root = true\n[*.go]\nindent_style = tab\ntab_width = 8\n",
+			projectConfig:     "- name: project\n  pattern: \"**/*.go\"\n  config:\n    tabSize: 4\n",
+			expectedTabSize:   4,
+			expectedTabExpand: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, config.EditorConfigFileName), []byte(tc.editorConfig), 0644)
+			require.NoError(t, err)
+			if tc.projectConfig != "" {
+				err = os.WriteFile(filepath.Join(dir, config.ProjectConfigFileName), []byte(tc.projectConfig), 0644)
+				require.NoError(t, err)
+			}
+
+			// The document is indented with three spaces, which is used unless editorconfig sets the indentation.
+			path := filepath.Join(dir, "src", "main.go")
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			require.NoError(t, err)
+			err = os.WriteFile(path, []byte("func main() {\n   a()\n   b()\n}\n"), 0644)
+			require.NoError(t, err)
+
+			state := NewEditorState(100, 100, configRuleSet, nil)
+			if tc.enabled {
+				EnableProjectConfig(state)
+			}
+			LoadDocument(state, path, true, startOfDocLocator)
+			defer state.fileWatcher.Stop()
+
+			assert.Equal(t, tc.expectedTabSize, state.documentBuffer.tabSize)
+			assert.Equal(t, tc.expectedTabExpand, state.documentBuffer.tabExpand)
+			assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+		})
+	}
+}
+
 func TestReloadDocumentAlignCursorAndScroll(t *testing.T) {
 	// Load the initial document.
 	initialText := "abcd\nefghi\njklmnop\nqrst"
@@ -313,6 +536,90 @@ func TestSaveDocument(t *testing.T) {
 	assert.Equal(t, "x\n", string(contents))
 }
 
+func TestSaveDocumentWriteBackup(t *testing.T) {
+	path, cleanup := createTestFile(t, "abc")
+	defer cleanup()
+	defer os.Remove(path + "~")
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+	state.writeBackup = true
+
+	InsertRune(state, 'x')
+	SaveDocument(state)
+	defer state.fileWatcher.Stop()
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+
+	// The backup contains the contents of the file before the save.
+	contents, err := os.ReadFile(path + "~")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(contents))
+
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "xabc\n", string(contents))
+}
+
+func TestSaveDocumentBackupError(t *testing.T) {
+	testCases := []struct {
+		name              string
+		abortOnError      bool
+		expectSaved       bool
+		expectedStatusMsg string
+	}{
+		{
+			name:              "warn and save",
+			abortOnError:      false,
+			expectSaved:       true,
+			expectedStatusMsg: "could not back up the previous version",
+		},
+		{
+			name:              "abort",
+			abortOnError:      true,
+			expectSaved:       false,
+			expectedStatusMsg: "Could not back up",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "test.txt")
+			err := os.WriteFile(path, []byte("abc"), 0644)
+			require.NoError(t, err)
+
+			// A regular file in place of the backup directory causes the backup to fail.
+			notADirPath := filepath.Join(dir, "notadir")
+			err = os.WriteFile(notADirPath, nil, 0644)
+			require.NoError(t, err)
+
+			state := NewEditorState(100, 100, nil, nil)
+			LoadDocument(state, path, true, startOfDocLocator)
+			defer state.fileWatcher.Stop()
+			state.writeBackup = true
+			state.backupOptions = file.BackupOptions{Dir: filepath.Join(notADirPath, "backups")}
+			state.backupAbortOnError = tc.abortOnError
+
+			InsertRune(state, 'x')
+			SaveDocument(state)
+			defer state.fileWatcher.Stop()
+
+			assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+			assert.Contains(t, state.statusMsg.Text, tc.expectedStatusMsg)
+			assert.Equal(t, !tc.expectSaved, state.documentBuffer.undoLog.HasUnsavedChanges())
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tc.expectSaved {
+				assert.Equal(t, "xabc\n", string(contents))
+			} else {
+				assert.Equal(t, "abc", string(contents))
+			}
+		})
+	}
+}
+
 func TestSaveDocumentIfUnsavedChanges(t *testing.T) {
 	// Start with an empty document.
 	state := NewEditorState(100, 100, nil, nil)
@@ -346,6 +653,95 @@ func TestSaveDocumentIfUnsavedChanges(t *testing.T) {
 	assert.Equal(t, "x\n", string(contents))
 }
 
+func TestAutoWriteOrAbortIfUnsavedChanges(t *testing.T) {
+	testCases := []struct {
+		name             string
+		autoWrite        bool
+		expectSwitched   bool
+		expectedContents string
+	}{
+		{
+			name:             "autoWrite disabled",
+			autoWrite:        false,
+			expectSwitched:   false,
+			expectedContents: "abcd",
+		},
+		{
+			name:             "autoWrite enabled",
+			autoWrite:        true,
+			expectSwitched:   true,
+			expectedContents: "xabcd\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			configRuleSet := config.RuleSet{
+				{
+					Name:    "autowrite",
+					Pattern: "**",
+					Config:  map[string]any{"autoWrite": tc.autoWrite},
+				},
+			}
+			state := NewEditorState(100, 100, configRuleSet, nil)
+			path, cleanup := createTestFile(t, "abcd")
+			defer cleanup()
+			path2, cleanup2 := createTestFile(t, "efgh")
+			defer cleanup2()
+
+			LoadDocument(state, path, true, startOfDocLocator)
+			defer state.fileWatcher.Stop()
+			InsertRune(state, 'x')
+
+			AutoWriteOrAbortIfUnsavedChanges(state, func(state *EditorState) {
+				LoadDocument(state, path2, true, startOfDocLocator)
+			}, true)
+
+			if tc.expectSwitched {
+				assert.Equal(t, path2, state.fileWatcher.Path())
+			} else {
+				assert.Equal(t, path, state.fileWatcher.Path())
+				assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+			}
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedContents, string(contents))
+		})
+	}
+}
+
+func TestAutoWriteBeforeCustomMenuShellCmd(t *testing.T) {
+	setupShellCmdTest(t, func(state *EditorState, dir string) {
+		filePath := filepath.Join(dir, "test-input.txt")
+		os.WriteFile(filePath, []byte("xyz"), 0644)
+		LoadDocument(state, filePath, true, startOfDocLocator)
+		defer state.fileWatcher.Stop()
+		state.autoWrite = true
+		InsertRune(state, 'w')
+
+		// The command should see the saved changes.
+		outputPath := filepath.Join(dir, "test-output.txt")
+		action := actionForCustomMenuItem(config.MenuCommandConfig{
+			Name:     "copy",
+			ShellCmd: fmt.Sprintf(`cat "$FILEPATH" > %s`, outputPath),
+			Mode:     config.CmdModeSilent,
+		})
+		action(state)
+
+		select {
+		case action := <-state.TaskResultChan():
+			action(state)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "Timed out")
+		}
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, "wxyz\n", string(data))
+	})
+}
+
 func TestAbortIfFileExistsWithChangedContent(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -456,6 +852,144 @@ func TestAbortIfFileExistsWithChangedContentFileDeleted(t *testing.T) {
 	assert.Equal(t, "Operation executed", state.statusMsg.Text)
 }
 
+func TestWriteDocumentToPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "original.txt")
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, false, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	// Write to a path in a directory that does not yet exist.
+	InsertRune(state, 'x')
+	copyPath := filepath.Join(dir, "subdir", "copy.txt")
+	WriteDocumentToPath(state, copyPath, false)
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+	assert.Contains(t, state.statusMsg.Text, "Wrote")
+
+	contents, err := os.ReadFile(copyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+
+	// The document is still associated with the original path, which has not been saved.
+	assert.Equal(t, path, state.fileWatcher.Path())
+	assert.True(t, state.documentBuffer.undoLog.HasUnsavedChanges())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWriteDocumentToPathExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, filepath.Join(dir, "original.txt"), false, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+	InsertRune(state, 'x')
+
+	existingPath := filepath.Join(dir, "existing.txt")
+	err := os.WriteFile(existingPath, []byte("abc"), 0644)
+	require.NoError(t, err)
+
+	// Without force, the existing file should not be overwritten.
+	WriteDocumentToPath(state, existingPath, false)
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+	assert.Contains(t, state.statusMsg.Text, "already exists")
+	contents, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(contents))
+
+	// With force, the existing file should be overwritten.
+	WriteDocumentToPath(state, existingPath, true)
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+	contents, err = os.ReadFile(existingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+}
+
+func TestWriteDocumentToPathEmpty(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	WriteDocumentToPath(state, " ", false)
+	assert.Equal(t, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "No file name specified",
+	}, state.statusMsg)
+}
+
+func TestWriteDocumentToPathCannotCreateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, filepath.Join(dir, "original.txt"), false, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	// A regular file blocks creation of a directory with the same name.
+	blockingPath := filepath.Join(dir, "notadir")
+	err := os.WriteFile(blockingPath, []byte("abc"), 0644)
+	require.NoError(t, err)
+
+	WriteDocumentToPath(state, filepath.Join(blockingPath, "copy.txt"), false)
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+	assert.Contains(t, state.statusMsg.Text, "Could not create directory")
+}
+
+func TestSaveDocumentAs(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "json",
+			Pattern: "**/*.json",
+			Config:  map[string]any{"syntaxLanguage": "json"},
+		},
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "original.txt")
+	state := NewEditorState(100, 100, configRuleSet, nil)
+	LoadDocument(state, path, false, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+	assert.Equal(t, syntax.LanguagePlaintext, state.documentBuffer.syntaxLanguage)
+
+	InsertRune(state, 'x')
+	newPath := filepath.Join(dir, "renamed.json")
+	SaveDocumentAs(state, newPath, false)
+	defer state.fileWatcher.Stop()
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+	assert.Contains(t, state.statusMsg.Text, "Saved")
+
+	// The document is now associated with the new path, and the syntax language is detected from the new extension.
+	assert.Equal(t, newPath, state.fileWatcher.Path())
+	assert.False(t, state.documentBuffer.undoLog.HasUnsavedChanges())
+	assert.Equal(t, syntax.LanguageJson, state.documentBuffer.syntaxLanguage)
+
+	contents, err := os.ReadFile(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+}
+
+func TestSaveDocumentAsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "original.txt")
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, false, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+	InsertRune(state, 'x')
+
+	existingPath := filepath.Join(dir, "existing.txt")
+	err := os.WriteFile(existingPath, []byte("abc"), 0644)
+	require.NoError(t, err)
+
+	// Without force, the existing file should not be overwritten.
+	SaveDocumentAs(state, existingPath, false)
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+	assert.Equal(t, path, state.fileWatcher.Path())
+	assert.True(t, state.documentBuffer.undoLog.HasUnsavedChanges())
+
+	// With force, the existing file should be overwritten.
+	SaveDocumentAs(state, existingPath, true)
+	defer state.fileWatcher.Stop()
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+	assert.Equal(t, existingPath, state.fileWatcher.Path())
+	contents, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(contents))
+}
+
 func TestDeduplicateCustomMenuItems(t *testing.T) {
 	// Configure custom menu items with duplicate names.
 	configRuleSet := config.RuleSet{
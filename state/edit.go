@@ -12,6 +12,7 @@ import (
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/locate"
 	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/syntax"
 	"github.com/aretext/aretext/syntax/parser"
 	"github.com/aretext/aretext/text"
 	"github.com/aretext/aretext/text/segment"
@@ -20,6 +21,8 @@ import (
 
 // InsertRune inserts a rune at the current cursor location.
 func InsertRune(state *EditorState, r rune) {
+	fillVirtualSpace(state)
+	deleteSnippetPlaceholder(state)
 	buffer := state.documentBuffer
 	startPos := buffer.cursor.position
 	if err := insertTextAtPosition(state, string(r), startPos, true); err != nil {
@@ -45,6 +48,18 @@ func insertTextAtPosition(state *EditorState, s string, pos uint64, updateUndoLo
 
 	edit := parser.NewInsertEdit(pos, n)
 	retokenizeAfterEdit(buffer, edit)
+	markGitDiffStale(buffer)
+	clearYankHighlight(buffer)
+	if n > 0 {
+		recordEvent(state, EventTypeBufferModified)
+		adjustSnippetStopsForInsert(state, pos, n)
+		adjustExtraCursorsForInsert(buffer, pos, n)
+		adjustInsertSessionForInsert(state, s, pos, n)
+		if len(buffer.diagnostics) > 0 {
+			lineNum := buffer.textTree.LineNumForPosition(pos)
+			adjustDiagnosticsForEdit(buffer, lineNum, 0, uint64(strings.Count(s, "\n")))
+		}
+	}
 
 	if updateUndoLog && len(s) > 0 {
 		op := undo.InsertOp(pos, s)
@@ -66,8 +81,11 @@ func mustInsertRuneAtPosition(state *EditorState, r rune, pos uint64, updateUndo
 }
 
 // InsertNewline inserts a newline at the current cursor position.
+// If continueComments is enabled and the cursor is after the start of a line comment,
+// the new line begins with the comment marker.
 func InsertNewline(state *EditorState) {
 	cursorPos := state.documentBuffer.cursor.position
+	leader := lineCommentLeader(state.documentBuffer, cursorPos)
 	mustInsertRuneAtPosition(state, '\n', cursorPos, true)
 	cursorPos++
 
@@ -78,9 +96,69 @@ func InsertNewline(state *EditorState) {
 		cursorPos = indentFromPos(state, cursorPos, numCols)
 	}
 
+	if leader != "" {
+		mustInsertTextAtPosition(state, leader, cursorPos, true)
+		cursorPos += uint64(utf8.RuneCountInString(leader))
+	}
+
 	buffer.cursor = cursorState{position: cursorPos}
 }
 
+// lineCommentLeader returns the text that continues a line comment on a new line.
+// This is the comment marker, followed by a space if the comment has one after the marker.
+// If continueComments is disabled, or the text before pos on its line is not in a line comment
+// that starts after the indentation, this returns an empty string.
+func lineCommentLeader(buffer *BufferState, pos uint64) string {
+	marker := syntax.LineCommentForLanguage(buffer.syntaxLanguage)
+	if !buffer.continueComments || marker == "" {
+		return ""
+	}
+
+	tree := buffer.textTree
+	markerPos := locate.NextNonWhitespaceOrNewline(tree, locate.StartOfLineAtPos(tree, pos))
+	markerLen := uint64(utf8.RuneCountInString(marker))
+	if pos < markerPos+markerLen {
+		return ""
+	}
+
+	n := pos - markerPos
+	if n > markerLen+1 {
+		n = markerLen + 1
+	}
+
+	s := copyText(tree, markerPos, n)
+	if s == marker+" " {
+		return s
+	} else if strings.HasPrefix(s, marker) {
+		return marker
+	} else {
+		return ""
+	}
+}
+
+// ClearEmptyLineComment removes the comment marker from a line containing only indentation and
+// a line comment marker, if the cursor is at the end of the line and continueComments is enabled.
+// This allows the user to end a comment continued on a new line by pressing enter again.
+func ClearEmptyLineComment(state *EditorState) {
+	buffer := state.documentBuffer
+	tree := buffer.textTree
+	cursorPos := buffer.cursor.position
+	leader := lineCommentLeader(buffer, cursorPos)
+	if leader == "" {
+		return
+	}
+
+	lineStartPos := locate.StartOfLineAtPos(tree, cursorPos)
+	lineEndPos := locate.NextLineBoundary(tree, true, lineStartPos)
+	markerPos := locate.NextNonWhitespaceOrNewline(tree, lineStartPos)
+	if cursorPos != lineEndPos || lineEndPos-markerPos != uint64(utf8.RuneCountInString(leader)) {
+		return
+	}
+
+	deleteRunes(state, markerPos, lineEndPos-markerPos, true)
+	buffer.cursor = cursorState{position: markerPos}
+}
+
 func deleteToNextNonWhitespace(state *EditorState, startPos uint64) {
 	pos := locate.NextNonWhitespaceOrNewline(state.documentBuffer.textTree, startPos)
 	count := pos - startPos
@@ -165,9 +243,17 @@ func ClearAutoIndentWhitespaceLine(state *EditorState, startOfLineLoc Locator) {
 
 // InsertTab inserts a tab at the current cursor position.
 func InsertTab(state *EditorState) {
-	cursorPos := state.documentBuffer.cursor.position
-	newCursorPos := insertTabsAtPos(state, cursorPos, tabText(state, 1))
-	state.documentBuffer.cursor = cursorState{position: newCursorPos}
+	fillVirtualSpace(state)
+	buffer := state.documentBuffer
+	cursorPos := buffer.cursor.position
+	tab := "\t"
+	if buffer.tabExpand {
+		// Inserted tab should end at the next tab stop.
+		offset := offsetInLine(buffer, cursorPos)
+		tab = strings.Repeat(" ", int(cellwidth.TabWidth(offset, buffer.tabSize, buffer.varTabStops)))
+	}
+	mustInsertTextAtPosition(state, tab, cursorPos, true)
+	buffer.cursor = cursorState{position: cursorPos + uint64(len(tab))}
 }
 
 func tabText(state *EditorState, count uint64) string {
@@ -186,21 +272,6 @@ func tabText(state *EditorState, count uint64) string {
 	return string(buf)
 }
 
-func insertTabsAtPos(state *EditorState, pos uint64, tabs string) uint64 {
-	n := uint64(len(tabs))
-
-	if state.documentBuffer.tabExpand {
-		// Inserted tab should end at a tab stop (aligned to multiples of tabSize from start of line).
-		offset := offsetInLine(state.documentBuffer, pos) % state.documentBuffer.tabSize
-		if offset > 0 {
-			n -= offset
-		}
-	}
-
-	mustInsertTextAtPosition(state, tabs[:n], pos, true)
-	return pos + n
-}
-
 func offsetInLine(buffer *BufferState, startPos uint64) uint64 {
 	var offset uint64
 	textTree := buffer.textTree
@@ -215,7 +286,7 @@ func offsetInLine(buffer *BufferState, startPos uint64) uint64 {
 		} else if err != nil {
 			panic(err)
 		}
-		offset += cellwidth.GraphemeClusterWidth(seg.Runes(), offset, buffer.tabSize)
+		offset += cellwidth.GraphemeClusterWidthWithTabStops(seg.Runes(), offset, buffer.tabSize, buffer.varTabStops)
 		pos += seg.NumRunes()
 	}
 	return offset
@@ -341,8 +412,21 @@ func deleteRunes(state *EditorState, pos uint64, count uint64, updateUndoLog boo
 
 	edit := parser.NewDeleteEdit(pos, count)
 	retokenizeAfterEdit(buffer, edit)
+	markGitDiffStale(buffer)
+	clearYankHighlight(buffer)
 
 	deletedText := string(deletedRunes)
+	if deletedText != "" {
+		recordEvent(state, EventTypeBufferModified)
+		adjustSnippetStopsForDelete(state, pos, uint64(len(deletedRunes)))
+		adjustExtraCursorsForDelete(buffer, pos, uint64(len(deletedRunes)))
+		adjustInsertSessionForDelete(state, pos, uint64(len(deletedRunes)))
+		if len(buffer.diagnostics) > 0 {
+			lineNum := buffer.textTree.LineNumForPosition(pos)
+			adjustDiagnosticsForEdit(buffer, lineNum, uint64(strings.Count(deletedText, "\n")), 0)
+		}
+	}
+
 	if updateUndoLog && deletedText != "" {
 		op := undo.DeleteOp(pos, deletedText)
 		buffer.undoLog.TrackOp(op)
@@ -386,8 +470,13 @@ func ReplaceChar(state *EditorState, newChar rune) {
 }
 
 // BeginNewLineAbove starts a new line above the current line, positioning the cursor at the end of the new line.
+// If continueComments is enabled and the current line is a line comment, the new line begins with the comment marker.
 func BeginNewLineAbove(state *EditorState) {
 	autoIndent := state.documentBuffer.autoIndent
+	tree := state.documentBuffer.textTree
+	lineEndPos := locate.NextLineBoundary(tree, true, locate.StartOfLineAtPos(tree, state.documentBuffer.cursor.position))
+	leader := lineCommentLeader(state.documentBuffer, lineEndPos)
+
 	MoveCursor(state, func(params LocatorParams) uint64 {
 		pos := locate.PrevLineBoundary(params.TextTree, params.CursorPos)
 		if autoIndent {
@@ -407,6 +496,12 @@ func BeginNewLineAbove(state *EditorState) {
 			return pos
 		}
 	})
+
+	if leader != "" {
+		cursorPos := state.documentBuffer.cursor.position
+		mustInsertTextAtPosition(state, leader, cursorPos, true)
+		state.documentBuffer.cursor = cursorState{position: cursorPos + uint64(utf8.RuneCountInString(leader))}
+	}
 }
 
 // JoinLines joins the next line with the current line.
@@ -522,7 +617,7 @@ func IndentLines(state *EditorState, targetLineLoc Locator, count uint64) {
 		endOfLinePos := locate.NextLineBoundary(buffer.textTree, true, startOfLinePos)
 		if startOfLinePos < endOfLinePos {
 			// Indent if line is non-empty.
-			insertTabsAtPos(state, startOfLinePos, tabs)
+			mustInsertTextAtPosition(state, tabs, startOfLinePos, true)
 		}
 	})
 }
@@ -576,6 +671,81 @@ func numRunesInIndent(buffer *BufferState, startOfLinePos uint64, count uint64)
 	return pos - startOfLinePos
 }
 
+// AlignLines inserts spaces before the first occurrence of delimiter in every line
+// from the current cursor position to the position found by targetLineLoc,
+// so that the delimiters line up in the same column.
+// Lines that do not contain the delimiter are left unchanged.
+func AlignLines(state *EditorState, targetLineLoc Locator, delimiter rune) {
+	buffer := state.documentBuffer
+	currentLine := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	targetPos := targetLineLoc(locatorParamsForBuffer(buffer))
+	targetLine := buffer.textTree.LineNumForPosition(targetPos)
+	if targetLine < currentLine {
+		currentLine, targetLine = targetLine, currentLine
+	}
+
+	type delimiterLoc struct {
+		pos uint64
+		col uint64
+	}
+
+	var maxCol uint64
+	delimiterLocs := make([]delimiterLoc, 0, targetLine-currentLine+1)
+	for lineNum := currentLine; lineNum <= targetLine; lineNum++ {
+		startOfLinePos := locate.StartOfLineNum(buffer.textTree, lineNum)
+		pos, col, ok := findDelimiterInLine(buffer, startOfLinePos, delimiter)
+		if !ok {
+			continue
+		}
+		delimiterLocs = append(delimiterLocs, delimiterLoc{pos: pos, col: col})
+		if col > maxCol {
+			maxCol = col
+		}
+	}
+
+	// Insert from the last line to the first so earlier positions remain valid.
+	for i := len(delimiterLocs) - 1; i >= 0; i-- {
+		loc := delimiterLocs[i]
+		if loc.col < maxCol {
+			padding := strings.Repeat(" ", int(maxCol-loc.col))
+			mustInsertTextAtPosition(state, padding, loc.pos, true)
+		}
+	}
+
+	startOfFirstLinePos := locate.StartOfLineNum(buffer.textTree, currentLine)
+	newCursorPos := locate.NextNonWhitespaceOrNewline(buffer.textTree, startOfFirstLinePos)
+	buffer.cursor = cursorState{position: newCursorPos}
+}
+
+// findDelimiterInLine returns the position and cell offset of the first grapheme cluster
+// in the line that starts with delimiter.
+func findDelimiterInLine(buffer *BufferState, startOfLinePos uint64, delimiter rune) (uint64, uint64, bool) {
+	var offset uint64
+	pos := startOfLinePos
+	gcWidthFunc := buffer.gcWidthFunc()
+	reader := buffer.textTree.ReaderAtPosition(pos)
+	iter := segment.NewGraphemeClusterIter(reader)
+	seg := segment.Empty()
+	for {
+		err := iter.NextSegment(seg)
+		if err == io.EOF {
+			return 0, 0, false
+		} else if err != nil {
+			panic(err)
+		}
+
+		gc := seg.Runes()
+		if gc[0] == '\n' || gc[0] == '\r' {
+			return 0, 0, false
+		} else if gc[0] == delimiter {
+			return pos, offset, true
+		}
+
+		offset += gcWidthFunc(gc, offset)
+		pos += seg.NumRunes()
+	}
+}
+
 // CopyRange copies the characters in a range to the default page in the clipboard.
 func CopyRange(state *EditorState, page clipboard.PageId, loc RangeLocator) {
 	startPos, endPos := loc(locatorParamsForBuffer(state.documentBuffer))
@@ -584,6 +754,7 @@ func CopyRange(state *EditorState, page clipboard.PageId, loc RangeLocator) {
 	}
 	text := copyText(state.documentBuffer.textTree, startPos, endPos-startPos)
 	state.clipboard.Set(page, clipboard.PageContent{Text: text})
+	highlightYankedText(state, startPos, endPos)
 }
 
 // CopyLine copies the line under the cursor to the default page in the clipboard.
@@ -597,6 +768,26 @@ func CopyLine(state *EditorState, page clipboard.PageId) {
 		Linewise: true,
 	}
 	state.clipboard.Set(page, content)
+	highlightYankedText(state, startPos, endPos)
+}
+
+// CopyLines copies every line from the cursor's current line to the line of a target position.
+func CopyLines(state *EditorState, page clipboard.PageId, targetLineLoc Locator) {
+	buffer := state.documentBuffer
+	currentLine := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	targetLine := buffer.textTree.LineNumForPosition(targetLineLoc(locatorParamsForBuffer(buffer)))
+	if targetLine < currentLine {
+		currentLine, targetLine = targetLine, currentLine
+	}
+
+	startPos := locate.StartOfLineNum(buffer.textTree, currentLine)
+	endPos := locate.NextLineBoundary(buffer.textTree, true, locate.StartOfLineNum(buffer.textTree, targetLine))
+	content := clipboard.PageContent{
+		Text:     copyText(buffer.textTree, startPos, endPos-startPos),
+		Linewise: true,
+	}
+	state.clipboard.Set(page, content)
+	highlightYankedText(state, startPos, endPos)
 }
 
 // CopySelection copies the current selection to the clipboard.
@@ -608,8 +799,8 @@ func CopySelection(state *EditorState, page clipboard.PageId) {
 		content.Linewise = true
 	}
 	state.clipboard.Set(page, content)
-
-	MoveCursor(state, func(LocatorParams) uint64 { return r.StartPos })
+	highlightYankedText(state, r.StartPos, r.EndPos)
+	MoveCursorToStartOfSelection(state)
 }
 
 // copyText copies part of the document text to a string.
@@ -669,6 +860,27 @@ func PasteAfterCursor(state *EditorState, page clipboard.PageId) {
 	}
 }
 
+// PasteInInsertMode inserts the contents of a page in the clipboard at the cursor position,
+// then moves the cursor after the inserted text. Linewise content is inserted with a final line feed.
+func PasteInInsertMode(state *EditorState, page clipboard.PageId) {
+	content := state.clipboard.Get(page)
+	text := content.Text
+	if content.Linewise {
+		text += "\n"
+	}
+
+	pos := state.documentBuffer.cursor.position
+	err := insertTextAtPosition(state, text, pos, true)
+	if err != nil {
+		log.Printf("Error pasting text: %v\n", err)
+		return
+	}
+
+	state.documentBuffer.cursor = cursorState{
+		position: pos + uint64(utf8.RuneCountInString(text)),
+	}
+}
+
 // PasteBeforeCursor inserts the text from the clipboard before the cursor position.
 func PasteBeforeCursor(state *EditorState, page clipboard.PageId) {
 	content := state.clipboard.Get(page)
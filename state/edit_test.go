@@ -9,6 +9,7 @@ import (
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/locate"
 	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/syntax"
 	"github.com/aretext/aretext/text"
 )
 
@@ -61,6 +62,88 @@ func TestInsertRune(t *testing.T) {
 	}
 }
 
+func TestInsertRuneWithVirtualEdit(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		virtualEdit    bool
+		initialCursor  cursorState
+		expectedCursor cursorState
+		expectedText   string
+	}{
+		{
+			name:           "past end of line pads with spaces",
+			inputString:    "ab\ncd",
+			virtualEdit:    true,
+			initialCursor:  cursorState{position: 1, logicalOffset: 3},
+			expectedCursor: cursorState{position: 5},
+			expectedText:   "ab  x\ncd",
+		},
+		{
+			name:           "empty line pads with spaces",
+			inputString:    "a\n\nb",
+			virtualEdit:    true,
+			initialCursor:  cursorState{position: 2, logicalOffset: 2},
+			expectedCursor: cursorState{position: 5},
+			expectedText:   "a\n  x\nb",
+		},
+		{
+			name:           "end of document pads with spaces",
+			inputString:    "",
+			virtualEdit:    true,
+			initialCursor:  cursorState{position: 0, logicalOffset: 2},
+			expectedCursor: cursorState{position: 3},
+			expectedText:   "  x",
+		},
+		{
+			name:           "middle of tab replaces tab with spaces",
+			inputString:    "a\tb",
+			virtualEdit:    true,
+			initialCursor:  cursorState{position: 1, logicalOffset: 1},
+			expectedCursor: cursorState{position: 3},
+			expectedText:   "a x  b",
+		},
+		{
+			name:           "virtual edit disabled ignores logical offset",
+			inputString:    "ab",
+			virtualEdit:    false,
+			initialCursor:  cursorState{position: 1, logicalOffset: 3},
+			expectedCursor: cursorState{position: 2, logicalOffset: 3},
+			expectedText:   "axb",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			state.documentBuffer.virtualEdit = tc.virtualEdit
+			InsertRune(state, 'x')
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
+func TestInsertRuneWithVirtualEditUndo(t *testing.T) {
+	textTree, err := text.NewTreeFromString("ab")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.virtualEdit = true
+	state.documentBuffer.cursor = cursorState{position: 1, logicalOffset: 2}
+	InsertRune(state, 'x')
+	assert.Equal(t, "ab x", textTree.String())
+
+	// Undo removes the padding along with the inserted text.
+	CheckpointUndoLog(state)
+	Undo(state)
+	assert.Equal(t, "ab", textTree.String())
+}
+
 func TestDeleteToPos(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -244,6 +327,162 @@ func TestInsertNewline(t *testing.T) {
 	}
 }
 
+func TestInsertNewlineContinueComments(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		language          syntax.Language
+		continueComments  bool
+		cursorPos         uint64
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "continue comments disabled",
+			inputString:       "\t// abc",
+			language:          syntax.LanguageGo,
+			continueComments:  false,
+			cursorPos:         7,
+			expectedCursorPos: 9,
+			expectedText:      "\t// abc\n\t",
+		},
+		{
+			name:              "end of comment line",
+			inputString:       "\t// abc",
+			language:          syntax.LanguageGo,
+			continueComments:  true,
+			cursorPos:         7,
+			expectedCursorPos: 12,
+			expectedText:      "\t// abc\n\t// ",
+		},
+		{
+			name:              "comment without space after marker",
+			inputString:       "//abc",
+			language:          syntax.LanguageGo,
+			continueComments:  true,
+			cursorPos:         5,
+			expectedCursorPos: 8,
+			expectedText:      "//abc\n//",
+		},
+		{
+			name:              "middle of comment line",
+			inputString:       "# abc def",
+			language:          syntax.LanguagePython,
+			continueComments:  true,
+			cursorPos:         5,
+			expectedCursorPos: 8,
+			expectedText:      "# abc\n# def",
+		},
+		{
+			name:              "before comment marker",
+			inputString:       "\t// abc",
+			language:          syntax.LanguageGo,
+			continueComments:  true,
+			cursorPos:         1,
+			expectedCursorPos: 3,
+			expectedText:      "\t\n\t// abc",
+		},
+		{
+			name:              "code with trailing comment",
+			inputString:       "x := 1 // abc",
+			language:          syntax.LanguageGo,
+			continueComments:  true,
+			cursorPos:         13,
+			expectedCursorPos: 14,
+			expectedText:      "x := 1 // abc\n",
+		},
+		{
+			name:              "marker from another language",
+			inputString:       "# abc",
+			language:          syntax.LanguageGo,
+			continueComments:  true,
+			cursorPos:         5,
+			expectedCursorPos: 6,
+			expectedText:      "# abc\n",
+		},
+		{
+			name:              "language without line comments",
+			inputString:       "// abc",
+			language:          syntax.LanguagePlaintext,
+			continueComments:  true,
+			cursorPos:         6,
+			expectedCursorPos: 7,
+			expectedText:      "// abc\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			state.documentBuffer.autoIndent = true
+			state.documentBuffer.tabSize = 4
+			state.documentBuffer.syntaxLanguage = tc.language
+			state.documentBuffer.continueComments = tc.continueComments
+			InsertNewline(state)
+			assert.Equal(t, cursorState{position: tc.expectedCursorPos}, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
+func TestClearEmptyLineComment(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		cursorPos         uint64
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "empty comment with space",
+			inputString:       "// abc\n\t// ",
+			cursorPos:         11,
+			expectedCursorPos: 8,
+			expectedText:      "// abc\n\t",
+		},
+		{
+			name:              "empty comment without space",
+			inputString:       "//",
+			cursorPos:         2,
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
+		{
+			name:              "comment with text",
+			inputString:       "// abc",
+			cursorPos:         6,
+			expectedCursorPos: 6,
+			expectedText:      "// abc",
+		},
+		{
+			name:              "cursor not at end of line",
+			inputString:       "// \nabc",
+			cursorPos:         2,
+			expectedCursorPos: 2,
+			expectedText:      "// \nabc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			state.documentBuffer.syntaxLanguage = syntax.LanguageGo
+			state.documentBuffer.continueComments = true
+			ClearEmptyLineComment(state)
+			assert.Equal(t, cursorState{position: tc.expectedCursorPos}, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
 func TestClearAutoIndentWhitespaceLine(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -336,6 +575,7 @@ func TestInsertTab(t *testing.T) {
 		expectedText   string
 		expectedCursor cursorState
 		tabExpand      bool
+		varTabStops    []uint64
 	}{
 		{
 			name:           "insert tab, no expand",
@@ -368,6 +608,24 @@ func TestInsertTab(t *testing.T) {
 			expectedText:   "\t\t    ab",
 			expectedCursor: cursorState{position: 6},
 		},
+		{
+			name:           "insert tab, expand to var tab stop",
+			tabExpand:      true,
+			varTabStops:    []uint64{2, 8},
+			inputString:    "abcd",
+			initialCursor:  cursorState{position: 3},
+			expectedText:   "abc       d",
+			expectedCursor: cursorState{position: 10},
+		},
+		{
+			name:           "insert tab, expand after tab at var tab stop",
+			tabExpand:      true,
+			varTabStops:    []uint64{2, 8},
+			inputString:    "\tab",
+			initialCursor:  cursorState{position: 1},
+			expectedText:   "\t        ab",
+			expectedCursor: cursorState{position: 9},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -379,6 +637,7 @@ func TestInsertTab(t *testing.T) {
 			state.documentBuffer.cursor = tc.initialCursor
 			state.documentBuffer.tabSize = 4
 			state.documentBuffer.tabExpand = tc.tabExpand
+			state.documentBuffer.varTabStops = tc.varTabStops
 			InsertTab(state)
 			assert.Equal(t, tc.expectedText, textTree.String())
 			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
@@ -1187,6 +1446,119 @@ func TestOutdentLines(t *testing.T) {
 	}
 }
 
+func TestAlignLines(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		cursorPos      uint64
+		targetLinePos  uint64
+		delimiter      rune
+		expectedCursor cursorState
+		expectedText   string
+	}{
+		{
+			name:           "empty",
+			inputString:    "",
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "",
+		},
+		{
+			name:           "single line",
+			inputString:    "a = 1",
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a = 1",
+		},
+		{
+			name:           "align assignments",
+			inputString:    "a = 1\nbbb = 2\ncc = 3",
+			cursorPos:      0,
+			targetLinePos:  14,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a   = 1\nbbb = 2\ncc  = 3",
+		},
+		{
+			name:           "target line before cursor",
+			inputString:    "a = 1\nbbb = 2\ncc = 3",
+			cursorPos:      14,
+			targetLinePos:  0,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a   = 1\nbbb = 2\ncc  = 3",
+		},
+		{
+			name:           "align only first delimiter in each line",
+			inputString:    "a: b: c\nxyz: d",
+			cursorPos:      0,
+			targetLinePos:  8,
+			delimiter:      ':',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a  : b: c\nxyz: d",
+		},
+		{
+			name:           "lines without delimiter unchanged",
+			inputString:    "a = 1\n// comment\nbbb = 2\n\nc = 3",
+			cursorPos:      0,
+			targetLinePos:  26,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a   = 1\n// comment\nbbb = 2\n\nc   = 3",
+		},
+		{
+			name:           "lines outside range unchanged",
+			inputString:    "aaaaa = 0\na = 1\nbb = 2\ncccccc = 3",
+			cursorPos:      10,
+			targetLinePos:  16,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 10},
+			expectedText:   "aaaaa = 0\na  = 1\nbb = 2\ncccccc = 3",
+		},
+		{
+			name:           "indented lines",
+			inputString:    "\tx := 1\n\tlongName := 2",
+			cursorPos:      0,
+			targetLinePos:  9,
+			delimiter:      ':',
+			expectedCursor: cursorState{position: 1},
+			expectedText:   "\tx        := 1\n\tlongName := 2",
+		},
+		{
+			name:           "wide characters",
+			inputString:    "界 = 1\nabc = 2",
+			cursorPos:      0,
+			targetLinePos:  6,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "界  = 1\nabc = 2",
+		},
+		{
+			name:           "already aligned",
+			inputString:    "a   = 1\nbbb = 2",
+			cursorPos:      0,
+			targetLinePos:  8,
+			delimiter:      '=',
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "a   = 1\nbbb = 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			targetLineLoc := func(p LocatorParams) uint64 { return tc.targetLinePos }
+			AlignLines(state, targetLineLoc, tc.delimiter)
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
 func TestBeginNewLineAbove(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -1261,6 +1633,20 @@ func TestBeginNewLineAbove(t *testing.T) {
 	}
 }
 
+func TestBeginNewLineAboveContinueComments(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc\n\t// def")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.cursor = cursorState{position: 9}
+	state.documentBuffer.autoIndent = true
+	state.documentBuffer.syntaxLanguage = syntax.LanguageGo
+	state.documentBuffer.continueComments = true
+	BeginNewLineAbove(state)
+	assert.Equal(t, cursorState{position: 8}, state.documentBuffer.cursor)
+	assert.Equal(t, "abc\n\t// \n\t// def", textTree.String())
+}
+
 func TestJoinLines(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -1798,3 +2184,78 @@ func TestPasteBeforeCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestPasteInInsertMode(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		initialCursor  cursorState
+		clipboard      clipboard.PageContent
+		expectedCursor cursorState
+		expectedText   string
+	}{
+		{
+			name:           "empty document, empty clipboard",
+			inputString:    "",
+			initialCursor:  cursorState{position: 0},
+			clipboard:      clipboard.PageContent{},
+			expectedCursor: cursorState{position: 0},
+			expectedText:   "",
+		},
+		{
+			name:          "paste at cursor",
+			inputString:   "abcd",
+			initialCursor: cursorState{position: 2},
+			clipboard: clipboard.PageContent{
+				Text: "xyz",
+			},
+			expectedCursor: cursorState{position: 5},
+			expectedText:   "abxyzcd",
+		},
+		{
+			name:          "paste at end of document",
+			inputString:   "abcd",
+			initialCursor: cursorState{position: 4},
+			clipboard: clipboard.PageContent{
+				Text: "xyz",
+			},
+			expectedCursor: cursorState{position: 7},
+			expectedText:   "abcdxyz",
+		},
+		{
+			name:          "paste linewise",
+			inputString:   "abcd",
+			initialCursor: cursorState{position: 2},
+			clipboard: clipboard.PageContent{
+				Text:     "xyz",
+				Linewise: true,
+			},
+			expectedCursor: cursorState{position: 6},
+			expectedText:   "abxyz\ncd",
+		},
+		{
+			name:          "multi-byte unicode",
+			inputString:   "abc",
+			initialCursor: cursorState{position: 1},
+			clipboard: clipboard.PageContent{
+				Text: "丂丄丅丆丏 ¢ह€한",
+			},
+			expectedCursor: cursorState{position: 11},
+			expectedText:   "a丂丄丅丆丏 ¢ह€한bc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = tc.initialCursor
+			state.clipboard.Set(clipboard.PageDefault, tc.clipboard)
+			PasteInInsertMode(state, clipboard.PageDefault)
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package state
+
+// EventType is a kind of change to the editor state.
+type EventType int
+
+const (
+	EventTypeBufferModified   = EventType(iota) // Text was inserted or deleted in the document.
+	EventTypeCursorMoved                        // The cursor moved to a different position.
+	EventTypeInputModeChanged                   // The editor changed to a different input mode.
+	EventTypeDocumentSaved                      // The document was written to disk.
+	EventTypeDocumentLoaded                     // A document was loaded or reloaded, replacing the text, cursor, and input mode.
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventTypeBufferModified:
+		return "buffer modified"
+	case EventTypeCursorMoved:
+		return "cursor moved"
+	case EventTypeInputModeChanged:
+		return "input mode changed"
+	case EventTypeDocumentSaved:
+		return "document saved"
+	case EventTypeDocumentLoaded:
+		return "document loaded"
+	default:
+		panic("invalid event type")
+	}
+}
+
+// Event describes a change to the editor state.
+// Subscribers can read the editor state to find the details of the change.
+type Event struct {
+	Type EventType
+}
+
+// eventState tracks subscribers and the changes they have not yet been notified about.
+type eventState struct {
+	nextSubscriberId int
+	subscribers      []eventSubscriber
+	pending          []EventType // Each type appears at most once, in the order it first occurred.
+	lastCursorPos    uint64      // Cursor position when subscribers were last notified.
+}
+
+type eventSubscriber struct {
+	id      int
+	handler func(Event)
+}
+
+// Subscribe registers a handler to be called for each change to the editor state.
+// Changes are recorded as they happen and delivered by DispatchEvents, so the handler
+// always runs on the goroutine that processes input, never concurrently with other changes.
+// The returned function removes the subscription.
+func Subscribe(state *EditorState, handler func(Event)) func() {
+	es := &state.events
+	id := es.nextSubscriberId
+	es.nextSubscriberId++
+	es.subscribers = append(es.subscribers, eventSubscriber{id: id, handler: handler})
+
+	return func() {
+		for i, sub := range es.subscribers {
+			if sub.id == id {
+				es.subscribers = append(es.subscribers[:i:i], es.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// DispatchEvents notifies subscribers of every change since the last dispatch.
+// The main event loop calls this after each action, so an action that changes the
+// same thing many times (for example, inserting several characters) produces one event.
+// Changes made by a handler are delivered on the next dispatch.
+func DispatchEvents(state *EditorState) {
+	es := &state.events
+	if cursorPos := state.documentBuffer.cursor.position; cursorPos != es.lastCursorPos {
+		recordEvent(state, EventTypeCursorMoved)
+		es.lastCursorPos = cursorPos
+	}
+
+	if len(es.pending) == 0 {
+		return
+	}
+
+	pending := es.pending
+	es.pending = nil
+
+	// Copy the subscribers so handlers can subscribe or unsubscribe while iterating.
+	subscribers := append([]eventSubscriber(nil), es.subscribers...)
+	for _, eventType := range pending {
+		event := Event{Type: eventType}
+		for _, sub := range subscribers {
+			sub.handler(event)
+		}
+	}
+}
+
+// recordEvent records a change to deliver to subscribers on the next dispatch.
+func recordEvent(state *EditorState, eventType EventType) {
+	for _, t := range state.events.pending {
+		if t == eventType {
+			return
+		}
+	}
+	state.events.pending = append(state.events.pending, eventType)
+}
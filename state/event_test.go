@@ -0,0 +1,146 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/text"
+)
+
+func TestDispatchEvents(t *testing.T) {
+	testCases := []struct {
+		name           string
+		initialText    string
+		fn             func(*EditorState)
+		expectedEvents []EventType
+	}{
+		{
+			name:           "no changes",
+			initialText:    "abc",
+			fn:             func(state *EditorState) {},
+			expectedEvents: nil,
+		},
+		{
+			name:        "insert text",
+			initialText: "abc",
+			fn: func(state *EditorState) {
+				SetInputMode(state, InputModeInsert)
+				InsertRune(state, 'x')
+				InsertRune(state, 'y')
+			},
+			expectedEvents: []EventType{
+				EventTypeInputModeChanged,
+				EventTypeBufferModified,
+				EventTypeCursorMoved,
+			},
+		},
+		{
+			name:        "move cursor",
+			initialText: "abc",
+			fn: func(state *EditorState) {
+				MoveCursorRight(state, 2)
+			},
+			expectedEvents: []EventType{EventTypeCursorMoved},
+		},
+		{
+			name:        "move cursor back to the same position",
+			initialText: "abc",
+			fn: func(state *EditorState) {
+				MoveCursorRight(state, 2)
+				MoveCursorLeft(state, 2)
+			},
+			expectedEvents: nil,
+		},
+		{
+			name:        "delete text",
+			initialText: "abc",
+			fn: func(state *EditorState) {
+				DeleteToPos(state, func(LocatorParams) uint64 { return 1 }, clipboard.PageDefault)
+			},
+			expectedEvents: []EventType{EventTypeBufferModified},
+		},
+		{
+			name:        "delete nothing",
+			initialText: "",
+			fn: func(state *EditorState) {
+				DeleteToPos(state, func(LocatorParams) uint64 { return 1 }, clipboard.PageDefault)
+			},
+			expectedEvents: nil,
+		},
+		{
+			name:        "set same input mode",
+			initialText: "abc",
+			fn: func(state *EditorState) {
+				SetInputMode(state, InputModeNormal)
+			},
+			expectedEvents: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.initialText)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			DispatchEvents(state)
+
+			var events []EventType
+			Subscribe(state, func(e Event) {
+				events = append(events, e.Type)
+			})
+
+			tc.fn(state)
+			DispatchEvents(state)
+			assert.Equal(t, tc.expectedEvents, events)
+
+			// Every event was delivered, so there is nothing left to dispatch.
+			events = nil
+			DispatchEvents(state)
+			assert.Nil(t, events)
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+
+	var countA, countB int
+	unsubscribeA := Subscribe(state, func(Event) { countA++ })
+	Subscribe(state, func(Event) { countB++ })
+
+	InsertRune(state, 'x')
+	DispatchEvents(state)
+	assert.Equal(t, 2, countA) // buffer modified and cursor moved
+	assert.Equal(t, 2, countB)
+
+	unsubscribeA()
+	unsubscribeA() // Unsubscribing twice has no effect.
+	InsertRune(state, 'y')
+	DispatchEvents(state)
+	assert.Equal(t, 2, countA)
+	assert.Equal(t, 4, countB)
+}
+
+func TestEventsRecordedByHandlerDispatchedNextTime(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+
+	var events []EventType
+	Subscribe(state, func(e Event) {
+		events = append(events, e.Type)
+		if e.Type == EventTypeInputModeChanged {
+			InsertRune(state, 'x')
+		}
+	})
+
+	SetInputMode(state, InputModeInsert)
+	DispatchEvents(state)
+	assert.Equal(t, []EventType{EventTypeInputModeChanged}, events)
+
+	events = nil
+	DispatchEvents(state)
+	assert.Equal(t, []EventType{EventTypeBufferModified, EventTypeCursorMoved}, events)
+}
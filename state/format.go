@@ -0,0 +1,125 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/text/segment"
+)
+
+// FormatLines reflows every line from the current cursor position to the position found by targetLineLoc,
+// re-wrapping words so that each line fits within the configured text width.
+// Blank lines separate paragraphs, and each paragraph keeps the indentation and line comment marker of its first line.
+// The cursor moves to the start of the last formatted line.
+func FormatLines(state *EditorState, targetLineLoc Locator) {
+	buffer := state.documentBuffer
+	startLineNum := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	targetPos := targetLineLoc(locatorParamsForBuffer(buffer))
+	endLineNum := buffer.textTree.LineNumForPosition(targetPos)
+	if endLineNum < startLineNum {
+		startLineNum, endLineNum = endLineNum, startLineNum
+	}
+
+	startPos := locate.StartOfLineNum(buffer.textTree, startLineNum)
+	endPos := locate.NextLineBoundary(buffer.textTree, true, locate.StartOfLineNum(buffer.textTree, endLineNum))
+	oldText := copyText(buffer.textTree, startPos, endPos-startPos)
+	commentMarker := syntax.LineCommentForLanguage(buffer.syntaxLanguage)
+	formattedLines := formatLines(strings.Split(oldText, "\n"), commentMarker, buffer.textWidth, buffer.gcWidthFunc())
+	newText := strings.Join(formattedLines, "\n")
+
+	if newText != oldText {
+		deleteRunes(state, startPos, endPos-startPos, true)
+		mustInsertTextAtPosition(state, newText, startPos, true)
+	}
+
+	lastLineNum := startLineNum + uint64(len(formattedLines)) - 1
+	lastLineStartPos := locate.StartOfLineNum(buffer.textTree, lastLineNum)
+	buffer.cursor = cursorState{
+		position: locate.NextNonWhitespaceOrNewline(buffer.textTree, lastLineStartPos),
+	}
+}
+
+// formatLines joins the words in each paragraph, then splits them into lines no wider than textWidth.
+// A word wider than textWidth is placed on its own line.
+// Lines that are blank (or contain only a comment marker) are kept as-is.
+func formatLines(lines []string, commentMarker string, textWidth uint64, gcWidthFunc segment.GraphemeClusterWidthFunc) []string {
+	result := make([]string, 0, len(lines))
+	var prefix string
+	var paragraphIsComment bool
+	var words []string
+
+	flushParagraph := func() {
+		if len(words) == 0 {
+			return
+		}
+
+		prefixWidth := stringCellWidth(prefix, 0, gcWidthFunc)
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		lineWidth := prefixWidth
+		for i, word := range words {
+			wordWidth := stringCellWidth(word, lineWidth+1, gcWidthFunc)
+			if i > 0 && lineWidth+1+wordWidth > textWidth {
+				result = append(result, sb.String())
+				sb.Reset()
+				sb.WriteString(prefix)
+				lineWidth = prefixWidth
+			} else if i > 0 {
+				sb.WriteRune(' ')
+				lineWidth++
+			}
+			sb.WriteString(word)
+			lineWidth += wordWidth
+		}
+		result = append(result, sb.String())
+		words = nil
+	}
+
+	for _, line := range lines {
+		linePrefix, lineText := splitFormatPrefix(line, commentMarker)
+		lineWords := strings.Fields(lineText)
+		if len(lineWords) == 0 {
+			flushParagraph()
+			result = append(result, line)
+			continue
+		}
+
+		// A line comment following code (or code following a comment) starts a new paragraph.
+		isComment := commentMarker != "" && strings.HasSuffix(strings.TrimRight(linePrefix, " "), commentMarker)
+		if len(words) > 0 && isComment != paragraphIsComment {
+			flushParagraph()
+		}
+
+		if len(words) == 0 {
+			prefix, paragraphIsComment = linePrefix, isComment
+		}
+		words = append(words, lineWords...)
+	}
+
+	flushParagraph()
+	return result
+}
+
+// splitFormatPrefix splits a line into its prefix (indentation and line comment marker, if any) and the remaining text.
+func splitFormatPrefix(line string, commentMarker string) (string, string) {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if commentMarker == "" || !strings.HasPrefix(line[indent:], commentMarker) {
+		return line[:indent], line[indent:]
+	}
+
+	prefixLen := indent + len(commentMarker)
+	if strings.HasPrefix(line[prefixLen:], " ") {
+		prefixLen++
+	}
+	return line[:prefixLen], line[prefixLen:]
+}
+
+// stringCellWidth returns the number of cells occupied by s, starting at the given offset in the line.
+func stringCellWidth(s string, offsetInLine uint64, gcWidthFunc segment.GraphemeClusterWidthFunc) uint64 {
+	var width uint64
+	for _, r := range s {
+		width += gcWidthFunc([]rune{r}, offsetInLine+width)
+	}
+	return width
+}
@@ -0,0 +1,171 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/text"
+)
+
+func TestFormatLines(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		language          syntax.Language
+		textWidth         uint64
+		cursorPos         uint64
+		targetPos         uint64
+		expectedCursorPos uint64
+		expectedText      string
+	}{
+		{
+			name:              "empty document",
+			inputString:       "",
+			textWidth:         10,
+			expectedCursorPos: 0,
+			expectedText:      "",
+		},
+		{
+			name:              "split long line",
+			inputString:       "aaa bbb ccc ddd eee",
+			textWidth:         10,
+			cursorPos:         0,
+			targetPos:         0,
+			expectedCursorPos: 16,
+			expectedText:      "aaa bbb\nccc ddd\neee",
+		},
+		{
+			name:              "line exactly text width",
+			inputString:       "aaaa bbbbb",
+			textWidth:         10,
+			cursorPos:         0,
+			targetPos:         0,
+			expectedCursorPos: 0,
+			expectedText:      "aaaa bbbbb",
+		},
+		{
+			name:              "join hard-wrapped lines",
+			inputString:       "aaa\nbbb\nccc\nddd",
+			textWidth:         10,
+			cursorPos:         0,
+			targetPos:         12,
+			expectedCursorPos: 8,
+			expectedText:      "aaa bbb\nccc ddd",
+		},
+		{
+			name:              "word longer than text width",
+			inputString:       "a bbbbbbbbbbbb c",
+			textWidth:         10,
+			cursorPos:         0,
+			targetPos:         0,
+			expectedCursorPos: 15,
+			expectedText:      "a\nbbbbbbbbbbbb\nc",
+		},
+		{
+			name:              "blank line separates paragraphs",
+			inputString:       "aaa\nbbb\n\nccc\nddd",
+			textWidth:         10,
+			cursorPos:         0,
+			targetPos:         13,
+			expectedCursorPos: 9,
+			expectedText:      "aaa bbb\n\nccc ddd",
+		},
+		{
+			name:              "preserve indentation",
+			inputString:       "\taaa bbb\n\tccc ddd",
+			textWidth:         12,
+			cursorPos:         0,
+			targetPos:         9,
+			expectedCursorPos: 10,
+			expectedText:      "\taaa bbb\n\tccc ddd",
+		},
+		{
+			name:              "indentation counts toward text width",
+			inputString:       "\taaa bbb ccc",
+			textWidth:         12,
+			cursorPos:         0,
+			targetPos:         0,
+			expectedCursorPos: 10,
+			expectedText:      "\taaa bbb\n\tccc",
+		},
+		{
+			name:              "preserve comment prefix",
+			inputString:       "\t// aaa\n\t// bbb ccc ddd eee",
+			language:          syntax.LanguageGo,
+			textWidth:         18,
+			cursorPos:         0,
+			targetPos:         9,
+			expectedCursorPos: 17,
+			expectedText:      "\t// aaa bbb ccc\n\t// ddd eee",
+		},
+		{
+			name:              "comment and code are separate paragraphs",
+			inputString:       "# aaa\n# bbb\nx = 1\ny = 2",
+			language:          syntax.LanguagePython,
+			textWidth:         80,
+			cursorPos:         0,
+			targetPos:         18,
+			expectedCursorPos: 10,
+			expectedText:      "# aaa bbb\nx = 1 y = 2",
+		},
+		{
+			name:              "empty comment line separates paragraphs",
+			inputString:       "// aaa\n//\n// bbb\n// ccc",
+			language:          syntax.LanguageGo,
+			textWidth:         80,
+			cursorPos:         0,
+			targetPos:         17,
+			expectedCursorPos: 10,
+			expectedText:      "// aaa\n//\n// bbb ccc",
+		},
+		{
+			name:              "target before cursor",
+			inputString:       "aaa\nbbb\nccc",
+			textWidth:         80,
+			cursorPos:         8,
+			targetPos:         0,
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb ccc",
+		},
+		{
+			name:              "only lines in range",
+			inputString:       "aaa\nbbb\nccc",
+			textWidth:         80,
+			cursorPos:         0,
+			targetPos:         4,
+			expectedCursorPos: 0,
+			expectedText:      "aaa bbb\nccc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			state.documentBuffer.syntaxLanguage = tc.language
+			state.documentBuffer.textWidth = tc.textWidth
+			FormatLines(state, func(LocatorParams) uint64 { return tc.targetPos })
+			assert.Equal(t, cursorState{position: tc.expectedCursorPos}, state.documentBuffer.cursor)
+			assert.Equal(t, tc.expectedText, textTree.String())
+		})
+	}
+}
+
+func TestFormatLinesUndo(t *testing.T) {
+	textTree, err := text.NewTreeFromString("aaa\nbbb\nccc")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	FormatLines(state, func(LocatorParams) uint64 { return 8 })
+	CheckpointUndoLog(state)
+	assert.Equal(t, "aaa bbb ccc", textTree.String())
+
+	Undo(state)
+	assert.Equal(t, "aaa\nbbb\nccc", textTree.String())
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/syntax/parser"
+)
+
+// gitCommitScissorsLine marks the start of text that git removes from a commit message,
+// such as the diff shown by "git commit --verbose".
+const gitCommitScissorsLine = "# ------------------------ >8 ------------------------"
+
+// LineLengthLimit returns the maximum number of characters expected in a line, or zero if the line has no limit.
+// Only git commit messages have limits: one for the subject (the first line) and another for the body.
+// Comment lines and everything after the scissors line are exempt, since git removes them from the message.
+func (s *BufferState) LineLengthLimit(lineNum uint64) uint64 {
+	if s.syntaxLanguage != syntax.LanguageGitCommit {
+		return 0
+	}
+
+	// The git commit parser produces a comment token for each line starting with "#", and no other tokens,
+	// so the comment tokens up to the start of the line are enough to find comments and the scissors line.
+	lineStartPos := s.textTree.LineStartPosition(lineNum)
+	for _, token := range s.SyntaxTokensIntersectingRange(0, lineStartPos+1) {
+		if token.Role != parser.TokenRoleComment {
+			continue
+		}
+
+		if token.StartPos == lineStartPos {
+			return 0
+		}
+
+		if token.StartPos < lineStartPos && isGitCommitScissorsToken(s, token) {
+			return 0
+		}
+	}
+
+	if lineNum == 0 {
+		return s.commitSubjectWidth
+	}
+	return s.commitBodyWidth
+}
+
+func isGitCommitScissorsToken(s *BufferState, token parser.Token) bool {
+	// The token may include the line feed, so check the length before copying the text.
+	tokenLen := token.EndPos - token.StartPos
+	if tokenLen < uint64(len(gitCommitScissorsLine)) || tokenLen > uint64(len(gitCommitScissorsLine))+2 {
+		return false
+	}
+	tokenText := copyText(s.textTree, token.StartPos, tokenLen)
+	return strings.TrimRight(tokenText, "\r\n") == gitCommitScissorsLine
+}
+
+// LineLengthHint describes how far the line with the cursor exceeds its length limit.
+// If the line has no limit or is within the limit, this returns an empty string.
+func (s *BufferState) LineLengthHint() string {
+	lineNum := s.textTree.LineNumForPosition(s.cursor.position)
+	limit := s.LineLengthLimit(lineNum)
+	if limit == 0 {
+		return ""
+	}
+
+	lineStartPos := s.textTree.LineStartPosition(lineNum)
+	lineLen := locate.NextLineBoundary(s.textTree, true, lineStartPos) - lineStartPos
+	if lineLen <= limit {
+		return ""
+	}
+
+	if lineNum == 0 {
+		return fmt.Sprintf("Subject has %d characters, more than the limit of %d", lineLen, limit)
+	}
+	return fmt.Sprintf("Line has %d characters, more than the limit of %d", lineLen, limit)
+}
@@ -0,0 +1,145 @@
+package state
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/text"
+)
+
+func TestLineLengthLimit(t *testing.T) {
+	commitMsg := strings.Join([]string{
+		"Subject",
+		"",
+		"Body",
+		"# Comment",
+		"Body after comment",
+		gitCommitScissorsLine,
+		"diff --git a/foo b/foo",
+	}, "\n")
+
+	testCases := []struct {
+		name          string
+		language      syntax.Language
+		lineNum       uint64
+		expectedLimit uint64
+	}{
+		{
+			name:          "subject",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       0,
+			expectedLimit: 50,
+		},
+		{
+			name:          "blank line after subject",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       1,
+			expectedLimit: 72,
+		},
+		{
+			name:          "body",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       2,
+			expectedLimit: 72,
+		},
+		{
+			name:          "comment",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       3,
+			expectedLimit: 0,
+		},
+		{
+			name:          "body after comment",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       4,
+			expectedLimit: 72,
+		},
+		{
+			name:          "scissors",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       5,
+			expectedLimit: 0,
+		},
+		{
+			name:          "after scissors",
+			language:      syntax.LanguageGitCommit,
+			lineNum:       6,
+			expectedLimit: 0,
+		},
+		{
+			name:          "other language",
+			language:      syntax.LanguagePlaintext,
+			lineNum:       0,
+			expectedLimit: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(commitMsg)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			setSyntaxAndRetokenize(buffer, tc.language)
+			assert.Equal(t, tc.expectedLimit, buffer.LineLengthLimit(tc.lineNum))
+		})
+	}
+}
+
+func TestLineLengthHint(t *testing.T) {
+	testCases := []struct {
+		name         string
+		inputString  string
+		cursorPos    uint64
+		expectedHint string
+	}{
+		{
+			name:         "subject within limit",
+			inputString:  strings.Repeat("a", 50),
+			cursorPos:    0,
+			expectedHint: "",
+		},
+		{
+			name:         "subject exceeds limit",
+			inputString:  strings.Repeat("a", 51),
+			cursorPos:    0,
+			expectedHint: "Subject has 51 characters, more than the limit of 50",
+		},
+		{
+			name:         "body exceeds limit",
+			inputString:  "Subject\n\n" + strings.Repeat("a", 80),
+			cursorPos:    12,
+			expectedHint: "Line has 80 characters, more than the limit of 72",
+		},
+		{
+			name:         "cursor on a different line",
+			inputString:  "Subject\n\n" + strings.Repeat("a", 80),
+			cursorPos:    0,
+			expectedHint: "",
+		},
+		{
+			name:         "comment exceeds limit",
+			inputString:  "# " + strings.Repeat("a", 80),
+			cursorPos:    0,
+			expectedHint: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.cursorPos
+			setSyntaxAndRetokenize(buffer, syntax.LanguageGitCommit)
+			assert.Equal(t, tc.expectedHint, buffer.LineLengthHint())
+		})
+	}
+}
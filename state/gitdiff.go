@@ -0,0 +1,263 @@
+package state
+
+import (
+	"io"
+	"log"
+	"strings"
+
+	"github.com/aretext/aretext/file"
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/text"
+)
+
+// GitDiffSign is a marker for a line that differs from the git HEAD version of the document.
+type GitDiffSign int
+
+const (
+	GitDiffSignNone     = GitDiffSign(iota) // Line is unchanged.
+	GitDiffSignAdded                        // Line was added.
+	GitDiffSignModified                     // Line was modified.
+	GitDiffSignRemoved                      // One or more lines were removed before this line.
+)
+
+// gitDiffState tracks differences between the document and the git HEAD version of the document.
+type gitDiffState struct {
+	// headText is the content of the document in the git HEAD commit.
+	// This is nil if the document is not tracked in a git repository.
+	headText *string
+
+	// hunks are the differences between headText and the current document.
+	hunks []text.DiffHunk
+
+	// signs map line numbers in the current document to the sign displayed for that line.
+	signs map[uint64]GitDiffSign
+
+	// stale indicates that the document has been edited since the hunks were last computed.
+	stale bool
+}
+
+// RefreshGitDiff reloads the git HEAD version of the document and recomputes the diff.
+// If the document is not tracked in a git repository, no diff signs are shown.
+func RefreshGitDiff(state *EditorState) {
+	loadGitDiffBase(state.documentBuffer, state.fileWatcher.Path())
+}
+
+// UpdateGitDiffIfStale recomputes the diff if the document has been edited since the last update.
+func UpdateGitDiffIfStale(state *EditorState) {
+	buffer := state.documentBuffer
+	if buffer.gitDiff.stale {
+		updateGitDiff(buffer)
+	}
+}
+
+func loadGitDiffBase(buffer *BufferState, path string) {
+	buffer.gitDiff = gitDiffState{}
+
+	if path == "" {
+		return
+	}
+
+	headText, err := file.LoadGitHeadVersion(path)
+	if err != nil {
+		log.Printf("Could not load git HEAD version of %q: %v\n", path, err)
+		return
+	}
+
+	// Ensure the last line ends with a line feed so it can match the last line of the document.
+	if len(headText) > 0 && !strings.HasSuffix(headText, "\n") {
+		headText += "\n"
+	}
+
+	buffer.gitDiff.headText = &headText
+	updateGitDiff(buffer)
+}
+
+func markGitDiffStale(buffer *BufferState) {
+	if buffer.gitDiff.headText != nil {
+		buffer.gitDiff.stale = true
+	}
+}
+
+func updateGitDiff(buffer *BufferState) {
+	gitDiff := &buffer.gitDiff
+	gitDiff.stale = false
+	gitDiff.hunks = nil
+	gitDiff.signs = nil
+
+	if gitDiff.headText == nil {
+		return
+	}
+
+	// The document omits the POSIX end-of-file indicator, so add it back
+	// to compare with the version committed to git.
+	// An empty document matches an empty file, with or without the indicator.
+	eof := "\n"
+	if buffer.textTree.NumChars() == 0 && *gitDiff.headText == "" {
+		eof = ""
+	}
+	headReader := strings.NewReader(*gitDiff.headText)
+	treeReader := buffer.textTree.ReaderAtPosition(0)
+	docReader := io.MultiReader(&treeReader, strings.NewReader(eof))
+	hunks, err := text.Diff(headReader, docReader)
+	if err != nil {
+		log.Printf("Could not diff document with git HEAD version: %v\n", err)
+		return
+	}
+
+	gitDiff.hunks = hunks
+	gitDiff.signs = gitDiffSignsForHunks(hunks, buffer.textTree.NumLines())
+}
+
+func gitDiffSignsForHunks(hunks []text.DiffHunk, numLines uint64) map[uint64]GitDiffSign {
+	signs := make(map[uint64]GitDiffSign)
+	for _, h := range hunks {
+		if h.RightNumLines == 0 {
+			// Show removed lines on the line after the removal,
+			// or on the last line if the removed lines were at the end of the document.
+			signs[gitDiffHunkSignLineNum(h, numLines)] = GitDiffSignRemoved
+			continue
+		}
+
+		sign := GitDiffSignModified
+		if h.LeftNumLines == 0 {
+			sign = GitDiffSignAdded
+		}
+
+		for i := uint64(0); i < h.RightNumLines; i++ {
+			signs[h.RightStartLineNum+i] = sign
+		}
+	}
+	return signs
+}
+
+// MoveCursorToNextGitHunk moves the cursor to the first line of the next hunk
+// that differs from the git HEAD version of the document.
+// If reverse is true, it moves to the previous hunk instead.
+// This wraps around at the start and end of the document.
+func MoveCursorToNextGitHunk(state *EditorState, reverse bool) {
+	buffer := state.documentBuffer
+	hunks, ok := gitDiffHunksOrShowStatus(state)
+	if !ok {
+		return
+	}
+
+	numLines := buffer.textTree.NumLines()
+	cursorLineNum := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	var targetLineNum uint64
+	if reverse {
+		targetLineNum = gitDiffHunkSignLineNum(hunks[len(hunks)-1], numLines)
+		for i := len(hunks) - 1; i >= 0; i-- {
+			if lineNum := gitDiffHunkSignLineNum(hunks[i], numLines); lineNum < cursorLineNum {
+				targetLineNum = lineNum
+				break
+			}
+		}
+	} else {
+		targetLineNum = gitDiffHunkSignLineNum(hunks[0], numLines)
+		for _, h := range hunks {
+			if lineNum := gitDiffHunkSignLineNum(h, numLines); lineNum > cursorLineNum {
+				targetLineNum = lineNum
+				break
+			}
+		}
+	}
+
+	lineStartPos := locate.StartOfLineNum(buffer.textTree, targetLineNum)
+	buffer.cursor = cursorState{
+		position: locate.NextNonWhitespaceOrNewline(buffer.textTree, lineStartPos),
+	}
+}
+
+// RevertGitHunk replaces the hunk at the cursor with the git HEAD version of those lines.
+func RevertGitHunk(state *EditorState) {
+	buffer := state.documentBuffer
+	hunks, ok := gitDiffHunksOrShowStatus(state)
+	if !ok {
+		return
+	}
+
+	numLines := buffer.textTree.NumLines()
+	cursorLineNum := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	for _, h := range hunks {
+		if cursorLineNum == gitDiffHunkSignLineNum(h, numLines) || (cursorLineNum >= h.RightStartLineNum && cursorLineNum < h.RightStartLineNum+h.RightNumLines) {
+			revertGitDiffHunk(state, h)
+			lineStartPos := locate.StartOfLineNum(buffer.textTree, h.RightStartLineNum)
+			buffer.cursor = cursorState{
+				position: locate.NextNonWhitespaceOrNewline(buffer.textTree, lineStartPos),
+			}
+			return
+		}
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "No git changes at the cursor",
+	})
+}
+
+func gitDiffHunksOrShowStatus(state *EditorState) ([]text.DiffHunk, bool) {
+	buffer := state.documentBuffer
+	if buffer.gitDiff.headText == nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Document is not tracked in a git repository",
+		})
+		return nil, false
+	}
+
+	if buffer.gitDiff.stale {
+		updateGitDiff(buffer)
+	}
+
+	if len(buffer.gitDiff.hunks) == 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleSuccess,
+			Text:  "No changes from git HEAD",
+		})
+		return nil, false
+	}
+
+	return buffer.gitDiff.hunks, true
+}
+
+// gitDiffHunkSignLineNum returns the line in the document where a hunk's sign is displayed.
+func gitDiffHunkSignLineNum(h text.DiffHunk, numLines uint64) uint64 {
+	lineNum := h.RightStartLineNum
+	if lineNum >= numLines && numLines > 0 {
+		lineNum = numLines - 1
+	}
+	return lineNum
+}
+
+func revertGitDiffHunk(state *EditorState, h text.DiffHunk) {
+	buffer := state.documentBuffer
+	headLines := strings.SplitAfter(*buffer.gitDiff.headText, "\n")
+	headHunkText := strings.Join(headLines[h.LeftStartLineNum:h.LeftStartLineNum+h.LeftNumLines], "")
+
+	// The diff treats the document as if every line ended with a line feed,
+	// including the last line. If the hunk includes the last line,
+	// adjust the replaced range and text to omit the final line feed.
+	numChars, numLines := buffer.textTree.NumChars(), buffer.textTree.NumLines()
+	startPos := buffer.textTree.LineStartPosition(h.RightStartLineNum)
+	endLineNum := h.RightStartLineNum + h.RightNumLines
+	if endLineNum < numLines {
+		endPos := buffer.textTree.LineStartPosition(endLineNum)
+		deleteRunes(state, startPos, endPos-startPos, true)
+	} else if len(headHunkText) > 0 {
+		if h.RightStartLineNum >= numLines {
+			// Inserting lines after the last line, so start from the end of the last line.
+			startPos = numChars
+			headHunkText = "\n" + headHunkText
+		}
+		deleteRunes(state, startPos, numChars-startPos, true)
+		headHunkText = strings.TrimSuffix(headHunkText, "\n")
+	} else if startPos > 0 {
+		// Deleting the last lines, so also delete the line feed before them.
+		deleteRunes(state, startPos-1, numChars-startPos+1, true)
+	} else {
+		deleteRunes(state, 0, numChars, true)
+	}
+
+	mustInsertTextAtPosition(state, headHunkText, startPos, true)
+	updateGitDiff(buffer)
+}
@@ -0,0 +1,305 @@
+package state
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func createTestGitRepoWithFile(t *testing.T, contents string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir := t.TempDir()
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test",
+			"GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test",
+			"GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	path := filepath.Join(tmpDir, "test.txt")
+	err := os.WriteFile(path, []byte(contents), 0644)
+	require.NoError(t, err)
+	runGit("init")
+	runGit("add", "test.txt")
+	runGit("commit", "-m", "test")
+	return path
+}
+
+func TestGitDiffSignsAfterEdit(t *testing.T) {
+	path := createTestGitRepoWithFile(t, "a\nb\nc\nd\n")
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	buffer := state.documentBuffer
+	assert.Equal(t, uint64(1), buffer.SignColumnWidth())
+	for lineNum := uint64(0); lineNum < 4; lineNum++ {
+		assert.Equal(t, GitDiffSignNone, buffer.GitDiffSignForLine(lineNum))
+	}
+
+	// Modify the first line and delete the third line.
+	mustInsertTextAtPosition(state, "x", 0, true)
+	deleteRunes(state, 5, 2, true)
+	assert.Equal(t, "xa\nb\nd", buffer.textTree.String())
+
+	// Signs aren't updated until the diff is recomputed.
+	assert.Equal(t, GitDiffSignNone, buffer.GitDiffSignForLine(0))
+	UpdateGitDiffIfStale(state)
+	assert.Equal(t, GitDiffSignModified, buffer.GitDiffSignForLine(0))
+	assert.Equal(t, GitDiffSignNone, buffer.GitDiffSignForLine(1))
+	assert.Equal(t, GitDiffSignRemoved, buffer.GitDiffSignForLine(2))
+
+	// Add a line at the end of the document.
+	mustInsertTextAtPosition(state, "\ne", buffer.textTree.NumChars(), true)
+	UpdateGitDiffIfStale(state)
+	assert.Equal(t, GitDiffSignAdded, buffer.GitDiffSignForLine(3))
+}
+
+func TestGitDiffOutsideRepo(t *testing.T) {
+	path, cleanup := createTestFile(t, "abcd")
+	defer cleanup()
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	mustInsertTextAtPosition(state, "x", 0, true)
+	UpdateGitDiffIfStale(state)
+	assert.Equal(t, uint64(0), state.documentBuffer.SignColumnWidth())
+	assert.Equal(t, GitDiffSignNone, state.documentBuffer.GitDiffSignForLine(0))
+	assert.Equal(t, StatusMsgStyleSuccess, state.statusMsg.Style)
+}
+
+func TestGitDiffSignsForHunks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hunks    []text.DiffHunk
+		numLines uint64
+		expected map[uint64]GitDiffSign
+	}{
+		{
+			name:     "no hunks",
+			numLines: 3,
+			expected: map[uint64]GitDiffSign{},
+		},
+		{
+			name: "added lines",
+			hunks: []text.DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 0, RightStartLineNum: 1, RightNumLines: 2},
+			},
+			numLines: 4,
+			expected: map[uint64]GitDiffSign{
+				1: GitDiffSignAdded,
+				2: GitDiffSignAdded,
+			},
+		},
+		{
+			name: "modified lines",
+			hunks: []text.DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 1, RightStartLineNum: 1, RightNumLines: 2},
+			},
+			numLines: 4,
+			expected: map[uint64]GitDiffSign{
+				1: GitDiffSignModified,
+				2: GitDiffSignModified,
+			},
+		},
+		{
+			name: "removed lines",
+			hunks: []text.DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 2, RightStartLineNum: 1, RightNumLines: 0},
+			},
+			numLines: 2,
+			expected: map[uint64]GitDiffSign{
+				1: GitDiffSignRemoved,
+			},
+		},
+		{
+			name: "removed lines at end of document",
+			hunks: []text.DiffHunk{
+				{LeftStartLineNum: 2, LeftNumLines: 2, RightStartLineNum: 2, RightNumLines: 0},
+			},
+			numLines: 2,
+			expected: map[uint64]GitDiffSign{
+				1: GitDiffSignRemoved,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signs := gitDiffSignsForHunks(tc.hunks, tc.numLines)
+			assert.Equal(t, tc.expected, signs)
+		})
+	}
+}
+
+func TestMoveCursorToNextGitHunk(t *testing.T) {
+	path := createTestGitRepoWithFile(t, "a\nb\nc\nd\ne\nf\n")
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	// No changes yet.
+	MoveCursorToNextGitHunk(state, false)
+	assert.Equal(t, uint64(0), state.documentBuffer.cursor.position)
+	assert.Equal(t, "No changes from git HEAD", state.statusMsg.Text)
+
+	// Modify line "b" and line "e".
+	mustInsertTextAtPosition(state, "x", 2, true)
+	mustInsertTextAtPosition(state, "y", 9, true)
+	assert.Equal(t, "a\nxb\nc\nd\nye\nf", state.documentBuffer.textTree.String())
+
+	MoveCursorToNextGitHunk(state, false)
+	assert.Equal(t, uint64(2), state.documentBuffer.cursor.position)
+	MoveCursorToNextGitHunk(state, false)
+	assert.Equal(t, uint64(9), state.documentBuffer.cursor.position)
+
+	// Wrap around to the first hunk.
+	MoveCursorToNextGitHunk(state, false)
+	assert.Equal(t, uint64(2), state.documentBuffer.cursor.position)
+
+	// Reverse, wrapping around to the last hunk.
+	MoveCursorToNextGitHunk(state, true)
+	assert.Equal(t, uint64(9), state.documentBuffer.cursor.position)
+	MoveCursorToNextGitHunk(state, true)
+	assert.Equal(t, uint64(2), state.documentBuffer.cursor.position)
+}
+
+func TestMoveCursorToNextGitHunkNotTracked(t *testing.T) {
+	path, cleanup := createTestFile(t, "abcd")
+	defer cleanup()
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	MoveCursorToNextGitHunk(state, false)
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+	assert.Equal(t, "Document is not tracked in a git repository", state.statusMsg.Text)
+}
+
+func TestRevertGitHunk(t *testing.T) {
+	testCases := []struct {
+		name          string
+		headText      string
+		editFunc      func(*EditorState)
+		cursorLineNum uint64
+		expectedText  string
+	}{
+		{
+			name:     "revert modified line",
+			headText: "a\nb\nc\n",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "xyz", 2, true)
+			},
+			cursorLineNum: 1,
+			expectedText:  "a\nb\nc",
+		},
+		{
+			name:     "revert added lines",
+			headText: "a\nb\nc\n",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "x\ny\n", 2, true)
+			},
+			cursorLineNum: 2,
+			expectedText:  "a\nb\nc",
+		},
+		{
+			name:     "revert removed lines",
+			headText: "a\nb\nc\nd\n",
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 2, 4, true)
+			},
+			cursorLineNum: 1,
+			expectedText:  "a\nb\nc\nd",
+		},
+		{
+			name:     "revert modified last line",
+			headText: "a\nb\nc\n",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "x", 5, true)
+			},
+			cursorLineNum: 2,
+			expectedText:  "a\nb\nc",
+		},
+		{
+			name:     "revert added lines at end",
+			headText: "a\nb\n",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "\nc\nd", 3, true)
+			},
+			cursorLineNum: 3,
+			expectedText:  "a\nb",
+		},
+		{
+			name:     "revert removed lines at end",
+			headText: "a\nb\nc\n",
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 1, 4, true)
+			},
+			cursorLineNum: 0,
+			expectedText:  "a\nb\nc",
+		},
+		{
+			name:     "revert all lines removed",
+			headText: "a\nb\n",
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 0, 3, true)
+			},
+			cursorLineNum: 0,
+			expectedText:  "a\nb",
+		},
+		{
+			name:     "revert first lines added to empty file",
+			headText: "",
+			editFunc: func(state *EditorState) {
+				mustInsertTextAtPosition(state, "a\nb", 0, true)
+			},
+			cursorLineNum: 1,
+			expectedText:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := createTestGitRepoWithFile(t, tc.headText)
+
+			state := NewEditorState(100, 100, nil, nil)
+			LoadDocument(state, path, true, startOfDocLocator)
+			defer state.fileWatcher.Stop()
+
+			originalText := state.documentBuffer.textTree.String()
+			tc.editFunc(state)
+			editedText := state.documentBuffer.textTree.String()
+			state.documentBuffer.cursor.position = state.documentBuffer.textTree.LineStartPosition(tc.cursorLineNum)
+
+			CheckpointUndoLog(state)
+			RevertGitHunk(state)
+			assert.Equal(t, tc.expectedText, state.documentBuffer.textTree.String())
+			assert.Equal(t, originalText, state.documentBuffer.textTree.String())
+			assert.Empty(t, state.documentBuffer.gitDiff.hunks)
+
+			// Reverting the hunk is a single undo operation.
+			Undo(state)
+			assert.Equal(t, editedText, state.documentBuffer.textTree.String())
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package state
+
+import (
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/locate"
+)
+
+// gitRebaseActions are the actions that CycleGitRebaseAction cycles through, in order.
+// Each action has a full name and the single-letter abbreviation that git accepts.
+var gitRebaseActions = []struct {
+	name   string
+	abbrev string
+}{
+	{"pick", "p"},
+	{"reword", "r"},
+	{"edit", "e"},
+	{"squash", "s"},
+	{"fixup", "f"},
+	{"drop", "d"},
+}
+
+// CycleGitRebaseAction replaces the action at the start of the current line of a git interactive rebase todo
+// with the next action in the list (pick, reword, edit, squash, fixup, drop), wrapping from drop back to pick.
+// Abbreviated actions cycle through the other abbreviations, so "p" becomes "r".
+// If the line does not start with one of these actions, this does nothing.
+func CycleGitRebaseAction(state *EditorState) {
+	buffer := state.documentBuffer
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	wordStartPos := locate.NextNonWhitespaceOrNewline(buffer.textTree, lineStartPos)
+	lineEndPos := locate.NextLineBoundary(buffer.textTree, true, wordStartPos)
+	word := firstWord(copyText(buffer.textTree, wordStartPos, lineEndPos-wordStartPos))
+
+	for i, action := range gitRebaseActions {
+		next := gitRebaseActions[(i+1)%len(gitRebaseActions)]
+		var replacement string
+		if word == action.name {
+			replacement = next.name
+		} else if word == action.abbrev {
+			replacement = next.abbrev
+		} else {
+			continue
+		}
+
+		deleteRunes(state, wordStartPos, uint64(utf8.RuneCountInString(word)), true)
+		mustInsertTextAtPosition(state, replacement, wordStartPos, true)
+		buffer.cursor = cursorState{position: wordStartPos}
+		return
+	}
+}
+
+// firstWord returns the text before the first space or tab.
+func firstWord(s string) string {
+	for i, r := range s {
+		if r == ' ' || r == '\t' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// MoveLineUp swaps the current line with the line above it, keeping the cursor on the moved line.
+// In a git interactive rebase todo, this moves a commit earlier in the history.
+// If the cursor is on the first line, this does nothing.
+func MoveLineUp(state *EditorState) {
+	lineNum := state.documentBuffer.textTree.LineNumForPosition(state.documentBuffer.cursor.position)
+	if lineNum == 0 {
+		return
+	}
+	swapLineWithNext(state, lineNum-1, false)
+}
+
+// MoveLineDown swaps the current line with the line below it, keeping the cursor on the moved line.
+// In a git interactive rebase todo, this moves a commit later in the history.
+// If the cursor is on the last line, this does nothing.
+func MoveLineDown(state *EditorState) {
+	tree := state.documentBuffer.textTree
+	lineNum := tree.LineNumForPosition(state.documentBuffer.cursor.position)
+	if lineNum+1 >= tree.NumLines() {
+		return
+	}
+	swapLineWithNext(state, lineNum, true)
+}
+
+// swapLineWithNext swaps the line at lineNum with the line after it.
+// The cursor moves with the line that was originally at lineNum if cursorOnFirst is true,
+// otherwise with the line originally at lineNum+1, preserving its offset from the start of the line.
+// The line ending between the two lines stays in place, so a line without a trailing newline remains last.
+func swapLineWithNext(state *EditorState, lineNum uint64, cursorOnFirst bool) {
+	buffer := state.documentBuffer
+	tree := buffer.textTree
+	firstStartPos := tree.LineStartPosition(lineNum)
+	firstEndPos := locate.NextLineBoundary(tree, true, firstStartPos)
+	secondStartPos := tree.LineStartPosition(lineNum + 1)
+	secondEndPos := locate.NextLineBoundary(tree, true, secondStartPos)
+
+	firstLine := copyText(tree, firstStartPos, firstEndPos-firstStartPos)
+	lineEnding := copyText(tree, firstEndPos, secondStartPos-firstEndPos)
+	secondLine := copyText(tree, secondStartPos, secondEndPos-secondStartPos)
+
+	var cursorPos uint64
+	if cursorOnFirst {
+		cursorOffset := buffer.cursor.position - firstStartPos
+		cursorPos = firstStartPos + uint64(utf8.RuneCountInString(secondLine+lineEnding)) + cursorOffset
+	} else {
+		cursorOffset := buffer.cursor.position - secondStartPos
+		cursorPos = firstStartPos + cursorOffset
+	}
+
+	deleteRunes(state, firstStartPos, secondEndPos-firstStartPos, true)
+	mustInsertTextAtPosition(state, secondLine+lineEnding+firstLine, firstStartPos, true)
+	buffer.cursor = cursorState{position: cursorPos}
+}
@@ -0,0 +1,236 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestCycleGitRebaseAction(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "empty document",
+			inputString:       "",
+			initialCursorPos:  0,
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "pick to reword",
+			inputString:       "pick 1234abc first commit",
+			initialCursorPos:  10,
+			expectedText:      "reword 1234abc first commit",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "fixup to drop",
+			inputString:       "fixup 1234abc first commit",
+			initialCursorPos:  0,
+			expectedText:      "drop 1234abc first commit",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "drop wraps to pick",
+			inputString:       "drop 1234abc first commit",
+			initialCursorPos:  0,
+			expectedText:      "pick 1234abc first commit",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "abbreviated action",
+			inputString:       "s 1234abc first commit",
+			initialCursorPos:  0,
+			expectedText:      "f 1234abc first commit",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "abbreviated drop wraps to pick",
+			inputString:       "d 1234abc first commit",
+			initialCursorPos:  0,
+			expectedText:      "p 1234abc first commit",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "current line only",
+			inputString:       "pick 1234abc first\npick 5678def second\npick 9abcdef third",
+			initialCursorPos:  25,
+			expectedText:      "pick 1234abc first\nreword 5678def second\npick 9abcdef third",
+			expectedCursorPos: 19,
+		},
+		{
+			name:              "leading whitespace",
+			inputString:       "  edit 1234abc first commit",
+			initialCursorPos:  0,
+			expectedText:      "  squash 1234abc first commit",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "action without commit",
+			inputString:       "pick",
+			initialCursorPos:  0,
+			expectedText:      "reword",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "exec is not cycled",
+			inputString:       "exec make test",
+			initialCursorPos:  3,
+			expectedText:      "exec make test",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "comment is not cycled",
+			inputString:       "# pick 1234abc",
+			initialCursorPos:  3,
+			expectedText:      "# pick 1234abc",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "word starting with action is not cycled",
+			inputString:       "picked 1234abc",
+			initialCursorPos:  0,
+			expectedText:      "picked 1234abc",
+			expectedCursorPos: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.initialCursorPos
+			CycleGitRebaseAction(state)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+		})
+	}
+}
+
+func TestMoveLine(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		down              bool
+		expectedText      string
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "empty document up",
+			inputString:       "",
+			initialCursorPos:  0,
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "empty document down",
+			inputString:       "",
+			initialCursorPos:  0,
+			down:              true,
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "up from first line",
+			inputString:       "pick a first\npick b second",
+			initialCursorPos:  2,
+			expectedText:      "pick a first\npick b second",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "down from last line",
+			inputString:       "pick a first\npick b second",
+			initialCursorPos:  15,
+			down:              true,
+			expectedText:      "pick a first\npick b second",
+			expectedCursorPos: 15,
+		},
+		{
+			name:              "up from last line",
+			inputString:       "pick a first\npick b second",
+			initialCursorPos:  15,
+			expectedText:      "pick b second\npick a first",
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "down from first line",
+			inputString:       "pick a first\npick b second",
+			initialCursorPos:  2,
+			down:              true,
+			expectedText:      "pick b second\npick a first",
+			expectedCursorPos: 16,
+		},
+		{
+			name:              "up from middle line",
+			inputString:       "pick a first\npick b second\npick c third",
+			initialCursorPos:  18,
+			expectedText:      "pick b second\npick a first\npick c third",
+			expectedCursorPos: 5,
+		},
+		{
+			name:              "down from middle line",
+			inputString:       "pick a first\npick b second\npick c third",
+			initialCursorPos:  18,
+			down:              true,
+			expectedText:      "pick a first\npick c third\npick b second",
+			expectedCursorPos: 31,
+		},
+		{
+			name:              "down to empty last line",
+			inputString:       "pick a first\n",
+			initialCursorPos:  0,
+			down:              true,
+			expectedText:      "\npick a first",
+			expectedCursorPos: 1,
+		},
+		{
+			name:              "preserves carriage return line ending",
+			inputString:       "pick a first\r\npick b second",
+			initialCursorPos:  0,
+			down:              true,
+			expectedText:      "pick b second\r\npick a first",
+			expectedCursorPos: 15,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.initialCursorPos
+			if tc.down {
+				MoveLineDown(state)
+			} else {
+				MoveLineUp(state)
+			}
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+		})
+	}
+}
+
+func TestMoveLineUndo(t *testing.T) {
+	textTree, err := text.NewTreeFromString("pick a first\npick b second")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	MoveLineDown(state)
+	CheckpointUndoLog(state)
+	Undo(state)
+	assert.Equal(t, "pick a first\npick b second", textTree.String())
+}
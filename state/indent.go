@@ -0,0 +1,124 @@
+package state
+
+import (
+	"log"
+
+	"github.com/aretext/aretext/text"
+)
+
+const (
+	// maxIndentSampleLines is the maximum number of indented lines used to guess a document's indentation.
+	maxIndentSampleLines = 100
+
+	// maxIndentScanLines limits how far into the document to look for indented lines,
+	// so opening a large file without indentation stays fast.
+	maxIndentScanLines = 1000
+)
+
+// indentGuess is the indentation style guessed from a document's content.
+type indentGuess struct {
+	tabExpand bool
+	tabSize   uint64 // Zero if the width could not be guessed, such as when the document is indented with tabs.
+}
+
+// guessIndentation guesses whether a document is indented with tabs or spaces by sampling its indented lines,
+// similar to vim-sleuth. For space-indented documents, it also guesses the indent width from the most common
+// increase in indentation between lines. The second return value is false if there are no indented lines,
+// or if the same number of lines are indented with tabs and spaces.
+func guessIndentation(tree *text.Tree) (indentGuess, bool) {
+	var numTabLines, numSpaceLines int
+	var widthCounts [9]int // Count of each increase in indentation from two to eight spaces.
+	prevSpaces := -1       // Leading spaces on the previous line, or -1 if unknown.
+
+	numLines := tree.NumLines()
+	for lineNum := uint64(0); lineNum < numLines && lineNum < maxIndentScanLines; lineNum++ {
+		if numTabLines+numSpaceLines >= maxIndentSampleLines {
+			break
+		}
+
+		startsWithTab, numSpaces, isBlank := leadingIndentation(tree, lineNum)
+		switch {
+		case isBlank:
+			// Blank lines don't tell us anything about the indentation.
+			continue
+		case startsWithTab:
+			numTabLines++
+			prevSpaces = -1
+		case numSpaces == 1:
+			// A single space is usually alignment, such as a "*" continuing a block comment.
+			prevSpaces = -1
+		default:
+			if numSpaces > 0 {
+				numSpaceLines++
+			}
+			if prevSpaces >= 0 && numSpaces > prevSpaces {
+				if delta := numSpaces - prevSpaces; delta >= 2 && delta < len(widthCounts) {
+					widthCounts[delta]++
+				}
+			}
+			prevSpaces = numSpaces
+		}
+	}
+
+	if numTabLines == numSpaceLines {
+		return indentGuess{}, false
+	} else if numTabLines > numSpaceLines {
+		return indentGuess{tabExpand: false}, true
+	}
+
+	// Choose the most common width, preferring the smaller width if there's a tie,
+	// since a larger increase could be several levels of a smaller width.
+	guess := indentGuess{tabExpand: true}
+	maxCount := 0
+	for width, count := range widthCounts {
+		if count > maxCount {
+			guess.tabSize = uint64(width)
+			maxCount = count
+		}
+	}
+	return guess, true
+}
+
+// leadingIndentation reads the whitespace at the start of a line.
+func leadingIndentation(tree *text.Tree, lineNum uint64) (startsWithTab bool, numSpaces int, isBlank bool) {
+	reader := tree.ReaderAtPosition(tree.LineStartPosition(lineNum))
+	for i := 0; ; i++ {
+		r, _, err := reader.ReadRune()
+		if err != nil || r == '\n' || r == '\r' {
+			return startsWithTab, numSpaces, true
+		}
+
+		switch r {
+		case '\t':
+			if i == 0 {
+				startsWithTab = true
+			}
+		case ' ':
+			if !startsWithTab {
+				numSpaces++
+			}
+		default:
+			return startsWithTab, numSpaces, false
+		}
+	}
+}
+
+// applyGuessedIndentation sets the document's tab settings to match its existing indentation.
+// Options set explicitly by config rules or the "set" command take precedence over the guess.
+func applyGuessedIndentation(state *EditorState, configKeys map[string]struct{}) {
+	buffer := state.documentBuffer
+	guess, ok := guessIndentation(buffer.textTree)
+	if !ok {
+		return
+	}
+
+	log.Printf("Guessed indentation from document content: tabExpand=%t, tabSize=%d\n", guess.tabExpand, guess.tabSize)
+
+	if _, ok := configKeys["tabExpand"]; !ok {
+		buffer.tabExpand = guess.tabExpand
+	}
+
+	if _, ok := configKeys["tabSize"]; !ok && guess.tabSize > 0 {
+		buffer.tabSize = guess.tabSize
+	}
+}
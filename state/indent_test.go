@@ -0,0 +1,167 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/text"
+)
+
+func TestGuessIndentation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		inputString   string
+		expectedOk    bool
+		expectedGuess indentGuess
+	}{
+		{
+			name:        "empty",
+			inputString: "",
+			expectedOk:  false,
+		},
+		{
+			name:        "no indented lines",
+			inputString: "foo\nbar\n\nbaz\n",
+			expectedOk:  false,
+		},
+		{
+			name:          "tabs",
+			inputString:   "func main() {\n\tif x {\n\t\ty()\n\t}\n}\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: false},
+		},
+		{
+			name:          "two spaces",
+			inputString:   "a:\n  b:\n    c: 1\n  d: 2\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 2},
+		},
+		{
+			name:          "four spaces",
+			inputString:   "def f():\n    if x:\n        return 1\n\n    return 2\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 4},
+		},
+		{
+			name:          "spaces with unknown width",
+			inputString:   "    foo\n    bar\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 0},
+		},
+		{
+			name:          "mixed with more tabs",
+			inputString:   "a\n\tb\n\tc\n    d\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: false},
+		},
+		{
+			name:          "mixed with more spaces",
+			inputString:   "a\n  b\n  c\n\td\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 2},
+		},
+		{
+			name:        "same number of tab and space lines is ambiguous",
+			inputString: "a\n\tb\nc\n    d\n",
+			expectedOk:  false,
+		},
+		{
+			name:          "tie between widths prefers smaller width",
+			inputString:   "a\n  b\nc\n    d\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 2},
+		},
+		{
+			name:          "most common width",
+			inputString:   "a\n    b\n        c\nd\n  e\nf\n    g\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: true, tabSize: 4},
+		},
+		{
+			name:          "block comment alignment ignored",
+			inputString:   "/**\n * foo\n * bar\n */\nfunc f() {\n\tx()\n}\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: false},
+		},
+		{
+			name:          "whitespace-only lines ignored",
+			inputString:   "a\n    \n  \nb\n\tc\n",
+			expectedOk:    true,
+			expectedGuess: indentGuess{tabExpand: false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			guess, ok := guessIndentation(tree)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedGuess, guess)
+		})
+	}
+}
+
+func TestLoadDocumentDetectIndent(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "default",
+			Pattern: "**",
+			Config:  map[string]any{"autoIndent": true},
+		},
+		{
+			Name:    "no detect",
+			Pattern: "**/*.go",
+			Config:  map[string]any{"detectIndent": false},
+		},
+		{
+			Name:    "explicit",
+			Pattern: "**/*.py",
+			Config:  map[string]any{"tabSize": 8, "tabExpand": false},
+		},
+	}
+
+	dir := t.TempDir()
+	spacesPath := filepath.Join(dir, "spaces.txt")
+	noIndentPath := filepath.Join(dir, "noindent.txt")
+	goPath := filepath.Join(dir, "test.go")
+	pyPath := filepath.Join(dir, "test.py")
+	require.NoError(t, os.WriteFile(spacesPath, []byte("a\n  b\n    c\n"), 0644))
+	require.NoError(t, os.WriteFile(noIndentPath, []byte("a\nb\n"), 0644))
+	require.NoError(t, os.WriteFile(goPath, []byte("a\n  b\n    c\n"), 0644))
+	require.NoError(t, os.WriteFile(pyPath, []byte("a\n  b\n    c\n"), 0644))
+
+	state := NewEditorState(100, 100, configRuleSet, nil)
+	defer state.fileWatcher.Stop()
+
+	// The guess replaces the default tab settings.
+	LoadDocument(state, spacesPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(2), state.documentBuffer.tabSize)
+
+	// Without indented lines, the default tab settings apply.
+	LoadDocument(state, noIndentPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(config.DefaultTabSize), state.documentBuffer.tabSize)
+
+	// Detection can be disabled in the config.
+	LoadDocument(state, goPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(config.DefaultTabSize), state.documentBuffer.tabSize)
+
+	// Tab settings from a config rule override the guess.
+	LoadDocument(state, pyPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(8), state.documentBuffer.tabSize)
+
+	// Options set with "set" override the guess.
+	SetOptions(state, "tabSize=3", false)
+	LoadDocument(state, spacesPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(3), state.documentBuffer.tabSize)
+}
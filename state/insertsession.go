@@ -0,0 +1,99 @@
+package state
+
+import (
+	"github.com/aretext/aretext/clipboard"
+)
+
+// insertSession tracks the text inserted since the editor entered insert mode.
+// When the editor leaves insert mode, the text is stored in the last inserted clipboard page,
+// so ctrl-a can insert it again.
+type insertSession struct {
+	// active is true while the editor is in insert mode.
+	active bool
+
+	// startPos is the position in the document of the first inserted rune.
+	startPos uint64
+
+	// text is the inserted text that has not been deleted since.
+	text []rune
+}
+
+// startInsertSession begins tracking text inserted at the cursor.
+func startInsertSession(state *EditorState) {
+	state.insertSession = insertSession{
+		active:   true,
+		startPos: state.documentBuffer.cursor.position,
+	}
+}
+
+// endInsertSession stops tracking inserted text and stores the text in the last inserted clipboard page.
+// If the session inserted nothing, the page keeps the text from the previous session.
+func endInsertSession(state *EditorState) {
+	session := state.insertSession
+	state.insertSession = insertSession{}
+	if session.active && len(session.text) > 0 {
+		state.clipboard.Set(clipboard.PageLastInserted, clipboard.PageContent{
+			Text: string(session.text),
+		})
+	}
+}
+
+// adjustInsertSessionForInsert records text s with n runes inserted at pos.
+// Text inserted outside the range of the session starts a new session, since vim treats
+// moving the cursor in insert mode as starting a new insert.
+func adjustInsertSessionForInsert(state *EditorState, s string, pos uint64, n uint64) {
+	session := &state.insertSession
+	if !session.active {
+		return
+	}
+
+	endPos := session.startPos + uint64(len(session.text))
+	if pos >= session.startPos && pos <= endPos {
+		offset := pos - session.startPos
+		text := make([]rune, 0, uint64(len(session.text))+n)
+		text = append(text, session.text[:offset]...)
+		text = append(text, []rune(s)...)
+		text = append(text, session.text[offset:]...)
+		session.text = text
+	} else if len(state.documentBuffer.extraCursors) > 0 {
+		// With multiple cursors, the same text is inserted at every cursor,
+		// so track only the text at the main cursor.
+		if pos < session.startPos {
+			session.startPos += n
+		}
+	} else {
+		session.startPos = pos
+		session.text = []rune(s)
+	}
+}
+
+// adjustInsertSessionForDelete removes deleted text from the session after n runes were deleted at pos.
+func adjustInsertSessionForDelete(state *EditorState, pos uint64, n uint64) {
+	session := &state.insertSession
+	if !session.active {
+		return
+	}
+
+	endPos := session.startPos + uint64(len(session.text))
+	if pos+n <= session.startPos {
+		session.startPos -= n
+		return
+	} else if pos >= endPos {
+		return
+	}
+
+	// Remove the part of the deleted range that overlaps the inserted text.
+	overlapStart, overlapEnd := pos, pos+n
+	if overlapStart < session.startPos {
+		overlapStart = session.startPos
+	}
+	if overlapEnd > endPos {
+		overlapEnd = endPos
+	}
+	text := session.text[:overlapStart-session.startPos]
+	text = append(text, session.text[overlapEnd-session.startPos:]...)
+	session.text = text
+	if pos < session.startPos {
+		session.startPos = pos
+	}
+}
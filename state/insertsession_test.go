@@ -0,0 +1,110 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/text"
+)
+
+func TestLastInsertedText(t *testing.T) {
+	testCases := []struct {
+		name         string
+		inputString  string
+		cursorPos    uint64
+		insertFunc   func(*EditorState)
+		expectedText string
+	}{
+		{
+			name:        "insert runes",
+			inputString: "ab",
+			cursorPos:   1,
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				InsertRune(state, 'y')
+			},
+			expectedText: "xy",
+		},
+		{
+			name:        "insert with newlines",
+			inputString: "",
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				InsertNewline(state)
+				InsertRune(state, 'y')
+				InsertNewline(state)
+			},
+			expectedText: "x\ny\n",
+		},
+		{
+			name:        "delete inserted text",
+			inputString: "",
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				InsertRune(state, 'y')
+				DeleteToPos(state, func(p LocatorParams) uint64 { return p.CursorPos - 1 }, clipboard.PageNull)
+			},
+			expectedText: "x",
+		},
+		{
+			name:        "delete before inserted text",
+			inputString: "ab",
+			cursorPos:   2,
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				deleteRunes(state, 0, 1, true)
+				state.documentBuffer.cursor.position--
+				InsertRune(state, 'y')
+			},
+			expectedText: "xy",
+		},
+		{
+			name:        "backspace past start of insert",
+			inputString: "ab",
+			cursorPos:   2,
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				DeleteToPos(state, func(p LocatorParams) uint64 { return p.CursorPos - 2 }, clipboard.PageNull)
+				InsertRune(state, 'y')
+			},
+			expectedText: "y",
+		},
+		{
+			name:        "cursor moves before insert",
+			inputString: "abc",
+			cursorPos:   3,
+			insertFunc: func(state *EditorState) {
+				InsertRune(state, 'x')
+				state.documentBuffer.cursor.position = 0
+				InsertRune(state, 'y')
+			},
+			expectedText: "y",
+		},
+		{
+			name:        "nothing inserted keeps previous text",
+			inputString: "abc",
+			insertFunc: func(state *EditorState) {
+				state.documentBuffer.cursor.position = 1
+			},
+			expectedText: "previous",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			state.clipboard.Set(clipboard.PageLastInserted, clipboard.PageContent{Text: "previous"})
+			SetInputMode(state, InputModeInsert)
+			tc.insertFunc(state)
+			SetInputMode(state, InputModeNormal)
+			assert.Equal(t, clipboard.PageContent{Text: tc.expectedText}, state.clipboard.Get(clipboard.PageLastInserted))
+		})
+	}
+}
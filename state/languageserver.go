@@ -0,0 +1,259 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/lsp"
+	"github.com/aretext/aretext/text"
+)
+
+// languageServerState is the connection to the language server configured for the document, if any.
+type languageServerState struct {
+	client  *lsp.Client // Nil until the first request.
+	command string      // Command that started the client.
+
+	// disabled is set when the server exits unexpectedly, so it doesn't restart on every request.
+	// Loading a document clears it.
+	disabled bool
+}
+
+// ShowLanguageServerHover asks the language server for documentation about the symbol under the cursor
+// and shows the first line in the status bar.
+func ShowLanguageServerHover(state *EditorState) {
+	client := languageServerClient(state)
+	if client == nil {
+		return
+	}
+
+	doc := languageServerDocument(state)
+	pos := lspPositionForPos(state.documentBuffer.textTree, state.documentBuffer.cursor.position)
+	StartTask(state, func(ctx context.Context) func(*EditorState) {
+		contents, err := client.Hover(ctx, doc, pos)
+		return func(state *EditorState) {
+			if err != nil {
+				reportLanguageServerError(state, client, err)
+				return
+			}
+
+			summary := hoverSummary(contents)
+			if summary == "" {
+				SetStatusMsg(state, StatusMsg{
+					Style: StatusMsgStyleError,
+					Text:  "No hover information",
+				})
+				return
+			}
+
+			SetStatusMsg(state, StatusMsg{
+				Style: StatusMsgStyleSuccess,
+				Text:  summary,
+			})
+		}
+	})
+}
+
+// GoToLanguageServerDefinition asks the language server where the symbol under the cursor is defined,
+// then moves the cursor to the definition, loading another document if necessary.
+func GoToLanguageServerDefinition(state *EditorState) {
+	client := languageServerClient(state)
+	if client == nil {
+		return
+	}
+
+	doc := languageServerDocument(state)
+	pos := lspPositionForPos(state.documentBuffer.textTree, state.documentBuffer.cursor.position)
+	StartTask(state, func(ctx context.Context) func(*EditorState) {
+		locs, err := client.Definition(ctx, doc, pos)
+		return func(state *EditorState) {
+			if err != nil {
+				reportLanguageServerError(state, client, err)
+				return
+			}
+
+			if len(locs) == 0 {
+				SetStatusMsg(state, StatusMsg{
+					Style: StatusMsgStyleError,
+					Text:  "No definition found",
+				})
+				return
+			}
+
+			goToLocation(state, locs[0])
+		}
+	})
+}
+
+func goToLocation(state *EditorState, loc lsp.Location) {
+	path, err := lsp.PathForURI(loc.URI)
+	if err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Could not go to definition: %s", err),
+		})
+		return
+	}
+
+	locator := func(p LocatorParams) uint64 {
+		return posForLspPosition(p.TextTree, loc.Range.Start)
+	}
+
+	if path == state.fileWatcher.Path() {
+		buffer := state.documentBuffer
+		buffer.cursor = cursorState{position: locator(LocatorParams{TextTree: buffer.textTree})}
+		ScrollViewToCursor(state)
+		return
+	}
+
+	AutoWriteOrAbortIfUnsavedChanges(state, func(state *EditorState) {
+		LoadDocument(state, path, true, locator)
+	}, true)
+}
+
+// languageServerClient returns the client for the document's language server, starting the server if necessary.
+// If the document has no language server or the server could not start, this shows an error and returns nil.
+// The language server comes from the user's config only, since a project config file might not be trusted
+// to choose a program to run.
+func languageServerClient(state *EditorState) *lsp.Client {
+	command := state.configRuleSet.ConfigForPath(state.fileWatcher.Path()).LanguageServer
+	if command == "" {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No language server configured",
+		})
+		return nil
+	}
+
+	if state.languageServer.disabled {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Language server is disabled until the document is reloaded",
+		})
+		return nil
+	}
+
+	if client := state.languageServer.client; client != nil {
+		if state.languageServer.command == command {
+			return client
+		}
+
+		// The document uses a different language server, so stop the old one without blocking the editor.
+		go client.Shutdown(context.Background())
+		state.languageServer.client = nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Printf("Error getting working directory: %v\n", err)
+		return nil
+	}
+
+	client, err := lsp.StartClient(command, cwd)
+	if err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Could not start language server: %s", errors.Cause(err)),
+		})
+		return nil
+	}
+
+	state.languageServer.client = client
+	state.languageServer.command = command
+	return client
+}
+
+// reportLanguageServerError shows an error from a request to the language server.
+// If the server exited, this disables it so the next request doesn't restart a server that keeps crashing.
+func reportLanguageServerError(state *EditorState, client *lsp.Client, err error) {
+	select {
+	case <-client.Done():
+		log.Printf("Disabling language server: %v\n", client.Err())
+		if state.languageServer.client == client {
+			state.languageServer.client = nil
+			state.languageServer.disabled = true
+		}
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Language server stopped unexpectedly, so it is disabled until the document is reloaded",
+		})
+	default:
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Language server error: %s", errors.Cause(err)),
+		})
+	}
+}
+
+// ShutdownLanguageServer stops the language server if it is running.
+// This blocks until the server exits or a timeout elapses.
+func ShutdownLanguageServer(state *EditorState) {
+	if client := state.languageServer.client; client != nil {
+		client.Shutdown(context.Background())
+		state.languageServer.client = nil
+	}
+}
+
+func languageServerDocument(state *EditorState) lsp.Document {
+	return lsp.Document{
+		Path:       state.fileWatcher.Path(),
+		LanguageID: string(state.documentBuffer.syntaxLanguage),
+		Text:       state.documentBuffer.textTree.String(),
+	}
+}
+
+// hoverSummary returns the first line of hover contents that is not blank or a markdown code fence.
+func hoverSummary(contents string) string {
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "```") {
+			return line
+		}
+	}
+	return ""
+}
+
+// lspPositionForPos converts a position in the text to a line and UTF-16 offset.
+func lspPositionForPos(tree *text.Tree, pos uint64) lsp.Position {
+	lineNum := tree.LineNumForPosition(pos)
+	lineStartPos := tree.LineStartPosition(lineNum)
+	reader := tree.ReaderAtPosition(lineStartPos)
+	var character uint64
+	for i := lineStartPos; i < pos; i++ {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+		character += uint64(utf16.RuneLen(r))
+	}
+	return lsp.Position{Line: lineNum, Character: character}
+}
+
+// posForLspPosition converts a line and UTF-16 offset to a position in the text.
+// Lines past the end of the text move to the last line, and offsets past the end of a line move to the end of the line.
+func posForLspPosition(tree *text.Tree, lspPos lsp.Position) uint64 {
+	lineNum := locate.ClosestValidLineNum(tree, lspPos.Line)
+	pos := tree.LineStartPosition(lineNum)
+	reader := tree.ReaderAtPosition(pos)
+	var character uint64
+	for character < lspPos.Character {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF || r == '\n' {
+			break
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+		character += uint64(utf16.RuneLen(r))
+		pos++
+	}
+	return pos
+}
@@ -0,0 +1,200 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/lsp"
+	"github.com/aretext/aretext/text"
+)
+
+func TestLspPositionConversion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		inputString string
+		pos         uint64
+		lspPos      lsp.Position
+	}{
+		{
+			name:        "empty document",
+			inputString: "",
+			pos:         0,
+			lspPos:      lsp.Position{Line: 0, Character: 0},
+		},
+		{
+			name:        "ascii on first line",
+			inputString: "abc\ndef",
+			pos:         2,
+			lspPos:      lsp.Position{Line: 0, Character: 2},
+		},
+		{
+			name:        "ascii on second line",
+			inputString: "abc\ndef",
+			pos:         5,
+			lspPos:      lsp.Position{Line: 1, Character: 1},
+		},
+		{
+			name:        "multi-byte rune counts as one code unit",
+			inputString: "héllo",
+			pos:         3,
+			lspPos:      lsp.Position{Line: 0, Character: 3},
+		},
+		{
+			name:        "rune outside basic multilingual plane counts as two code units",
+			inputString: "a😀b",
+			pos:         2,
+			lspPos:      lsp.Position{Line: 0, Character: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			assert.Equal(t, tc.lspPos, lspPositionForPos(tree, tc.pos))
+			assert.Equal(t, tc.pos, posForLspPosition(tree, tc.lspPos))
+		})
+	}
+}
+
+func TestPosForLspPositionOutOfBounds(t *testing.T) {
+	tree, err := text.NewTreeFromString("abc\ndef")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), posForLspPosition(tree, lsp.Position{Line: 0, Character: 10}))
+	assert.Equal(t, uint64(6), posForLspPosition(tree, lsp.Position{Line: 5, Character: 2}))
+}
+
+func TestHoverSummary(t *testing.T) {
+	testCases := []struct {
+		name            string
+		contents        string
+		expectedSummary string
+	}{
+		{
+			name:            "empty",
+			contents:        "",
+			expectedSummary: "",
+		},
+		{
+			name:            "plaintext",
+			contents:        "func foo()\n\nfoo does something.",
+			expectedSummary: "func foo()",
+		},
+		{
+			name:            "markdown code block",
+			contents:        "```go\nfunc foo()\n```\n\nfoo does something.",
+			expectedSummary: "func foo()",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedSummary, hoverSummary(tc.contents))
+		})
+	}
+}
+
+func TestGoToLocationInDocument(t *testing.T) {
+	tree, err := text.NewTreeFromString("abc\ndef\nghi")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = tree
+	goToLocation(state, lsp.Location{
+		URI:   lsp.URIForPath(state.fileWatcher.Path()),
+		Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 1}},
+	})
+	assert.Equal(t, uint64(9), state.documentBuffer.cursor.position)
+}
+
+func TestGoToLocationInOtherDocument(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.txt")
+	otherPath := filepath.Join(dir, "other.txt")
+	require.NoError(t, os.WriteFile(path, []byte("abc\n"), 0644))
+	require.NoError(t, os.WriteFile(otherPath, []byte("abc\ndef\n"), 0644))
+
+	state := NewEditorState(100, 100, nil, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	goToLocation(state, lsp.Location{
+		URI:   lsp.URIForPath(otherPath),
+		Range: lsp.Range{Start: lsp.Position{Line: 1, Character: 2}},
+	})
+	assert.Equal(t, otherPath, state.fileWatcher.Path())
+	assert.Equal(t, uint64(6), state.documentBuffer.cursor.position)
+}
+
+func TestLanguageServerNotConfigured(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	ShowLanguageServerHover(state)
+	assert.Equal(t, InputModeNormal, state.InputMode())
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "No language server configured"}, state.StatusMsg())
+}
+
+func TestLanguageServerIgnoresProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.go")
+	require.NoError(t, os.WriteFile(path, []byte("package test\n"), 0644))
+	projectConfig := "- name: project\n  pattern: \"**/*.go\"\n  config:\n    languageServer: \"false\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.ProjectConfigFileName), []byte(projectConfig), 0644))
+
+	state := NewEditorState(100, 100, nil, nil)
+	EnableProjectConfig(state)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	ShowLanguageServerHover(state)
+	assert.Equal(t, InputModeNormal, state.InputMode())
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "No language server configured"}, state.StatusMsg())
+}
+
+func TestLanguageServerExited(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.go")
+	require.NoError(t, os.WriteFile(path, []byte("package test\n"), 0644))
+
+	state := NewEditorState(100, 100, config.RuleSet{
+		{
+			Name:    "lsp",
+			Pattern: "**/*.go",
+			Config:  map[string]any{"languageServer": "false"},
+		},
+	}, nil)
+	LoadDocument(state, path, true, startOfDocLocator)
+	defer state.fileWatcher.Stop()
+
+	// The server exits immediately, so the request fails and the server is disabled.
+	GoToLanguageServerDefinition(state)
+	assert.Equal(t, InputModeTask, state.InputMode())
+	select {
+	case action := <-state.TaskResultChan():
+		action(state)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Timed out")
+	}
+
+	assert.Equal(t, InputModeNormal, state.InputMode())
+	assert.Equal(t, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "Language server stopped unexpectedly, so it is disabled until the document is reloaded",
+	}, state.StatusMsg())
+
+	// The next request does not restart the server.
+	ShowLanguageServerHover(state)
+	assert.Equal(t, InputModeNormal, state.InputMode())
+	assert.Equal(t, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "Language server is disabled until the document is reloaded",
+	}, state.StatusMsg())
+
+	// Reloading the document enables the server again.
+	ReloadDocument(state)
+	assert.False(t, state.languageServer.disabled)
+}
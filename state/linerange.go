@@ -0,0 +1,261 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/selection"
+)
+
+// LineRange is a range of lines in the document, from StartLine to EndLine inclusive.
+// Line numbers start from zero.
+type LineRange struct {
+	StartLine uint64
+	EndLine   uint64
+}
+
+// ParseLineRange parses a vim-style line range, such as "10,20", ".,$", "%", or ".+1,.+3".
+// Each address in the range is one of:
+//   - a line number, starting from one.
+//   - "." for the current line.
+//   - "$" for the last line.
+//   - an address followed by offsets like "+3" or "-1". An offset without an address is relative to the current line,
+//     and "+" or "-" without a number means one line.
+//
+// "%" is the entire document. A range with one address is that line, and an empty address is the current line.
+// If the first address is after the second address, they are swapped.
+// The current line is zero-based, like the returned range.
+func ParseLineRange(s string, currentLine uint64, numLines uint64) (LineRange, error) {
+	if s == "%" {
+		return LineRange{StartLine: 0, EndLine: numLines - 1}, nil
+	}
+
+	startStr, endStr, hasEnd := strings.Cut(s, ",")
+	if !hasEnd {
+		endStr = startStr
+	}
+
+	startAddr, err := parseLineAddress(startStr, currentLine, numLines)
+	if err != nil {
+		return LineRange{}, err
+	}
+
+	endAddr, err := parseLineAddress(endStr, currentLine, numLines)
+	if err != nil {
+		return LineRange{}, err
+	}
+
+	for _, addr := range []int64{startAddr, endAddr} {
+		if addr < 1 || addr > int64(numLines) {
+			return LineRange{}, fmt.Errorf("Invalid range: line %d does not exist", addr)
+		}
+	}
+
+	if startAddr > endAddr {
+		startAddr, endAddr = endAddr, startAddr
+	}
+
+	return LineRange{StartLine: uint64(startAddr - 1), EndLine: uint64(endAddr - 1)}, nil
+}
+
+// parseLineAddress parses a single address in a line range, returning a line number that starts from one.
+// The result may be outside the document, so the caller must check it.
+func parseLineAddress(s string, currentLine uint64, numLines uint64) (int64, error) {
+	addr := int64(currentLine) + 1
+	rest := s
+	switch {
+	case strings.HasPrefix(rest, "."):
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "$"):
+		addr = int64(numLines)
+		rest = rest[1:]
+	case len(rest) > 0 && isAsciiDigit(rest[0]):
+		n, numLen := parseLeadingNumber(rest)
+		addr = n
+		rest = rest[numLen:]
+	}
+
+	for len(rest) > 0 {
+		sign := int64(1)
+		switch rest[0] {
+		case '+':
+		case '-':
+			sign = -1
+		default:
+			return 0, fmt.Errorf("Invalid range address %q", s)
+		}
+		rest = rest[1:]
+
+		offset, numLen := parseLeadingNumber(rest)
+		if numLen == 0 {
+			offset = 1
+		}
+		rest = rest[numLen:]
+		addr += sign * offset
+	}
+
+	return addr, nil
+}
+
+// parseLeadingNumber parses the decimal digits at the start of s, returning the number and how many bytes it used.
+func parseLeadingNumber(s string) (int64, int) {
+	n := 0
+	for n < len(s) && isAsciiDigit(s[n]) {
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	num, err := strconv.ParseInt(s[:n], 10, 64)
+	if err != nil {
+		// The number is too large, so it is outside the document.
+		return int64(^uint64(0) >> 1), n
+	}
+	return num, n
+}
+
+func isAsciiDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// selectedLineRange returns the lines in the visual mode selection.
+// If nothing is selected, the second return value is false.
+func selectedLineRange(buffer *BufferState) (LineRange, bool) {
+	if buffer.selector.Mode() == selection.ModeNone {
+		return LineRange{}, false
+	}
+
+	region := buffer.SelectedRegion()
+	startLineNum := buffer.textTree.LineNumForPosition(region.StartPos)
+	endLineNum := buffer.textTree.LineNumForPosition(region.EndPos)
+	if region.EndPos > region.StartPos && endLineNum > startLineNum && buffer.textTree.LineStartPosition(endLineNum) == region.EndPos {
+		// Region ends at the start of a line, so that line isn't part of the selection.
+		endLineNum--
+	}
+	return LineRange{StartLine: startLineNum, EndLine: endLineNum}, true
+}
+
+// DeleteLineRange deletes the lines in a range and copies them to a clipboard page, like vim's ":d".
+// The cursor moves to the start of the line after the deleted lines.
+func DeleteLineRange(state *EditorState, r LineRange, page clipboard.PageId) {
+	buffer := state.documentBuffer
+	buffer.cursor = cursorState{position: buffer.textTree.LineStartPosition(r.StartLine)}
+	DeleteLines(state, func(p LocatorParams) uint64 {
+		return locate.StartOfLineNum(p.TextTree, r.EndLine)
+	}, false, false, page)
+}
+
+// YankLineRange copies the lines in a range to a clipboard page, like vim's ":y".
+// The cursor does not move.
+func YankLineRange(state *EditorState, r LineRange, page clipboard.PageId) {
+	tree := state.documentBuffer.textTree
+	startPos := locate.StartOfLineNum(tree, r.StartLine)
+	endPos := locate.NextLineBoundary(tree, true, locate.StartOfLineNum(tree, r.EndLine))
+	state.clipboard.Set(page, clipboard.PageContent{
+		Text:     copyText(tree, startPos, endPos-startPos),
+		Linewise: true,
+	})
+	highlightYankedText(state, startPos, endPos)
+}
+
+// MoveLineRange moves the lines in a range below the line at a target address, like vim's ":m".
+// The target uses the same syntax as an address in ParseLineRange, and "0" moves the lines to the top of the document.
+// The cursor moves to the last of the moved lines.
+func MoveLineRange(state *EditorState, r LineRange, target string) {
+	insertLine, ok := parseTargetLine(state, target)
+	if !ok {
+		return
+	}
+
+	if insertLine > r.StartLine && insertLine <= r.EndLine {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Cannot move lines into themselves",
+		})
+		return
+	}
+
+	buffer := state.documentBuffer
+	tree := buffer.textTree
+	numMoved := r.EndLine - r.StartLine + 1
+	var regionStartLine, regionEndLine, newLastMovedLine uint64
+	var reorder func(lines []string) []string
+	if insertLine <= r.StartLine {
+		regionStartLine, regionEndLine = insertLine, r.EndLine
+		newLastMovedLine = insertLine + numMoved - 1
+		reorder = func(lines []string) []string {
+			split := r.StartLine - insertLine
+			return append(append([]string(nil), lines[split:]...), lines[:split]...)
+		}
+	} else {
+		regionStartLine, regionEndLine = r.StartLine, insertLine-1
+		newLastMovedLine = insertLine - 1
+		reorder = func(lines []string) []string {
+			return append(append([]string(nil), lines[numMoved:]...), lines[:numMoved]...)
+		}
+	}
+
+	if regionStartLine < regionEndLine {
+		startPos := locate.StartOfLineNum(tree, regionStartLine)
+		endPos := locate.NextLineBoundary(tree, true, locate.StartOfLineNum(tree, regionEndLine))
+		lines := strings.Split(copyText(tree, startPos, endPos-startPos), "\n")
+		deleteRunes(state, startPos, endPos-startPos, true)
+		mustInsertTextAtPosition(state, strings.Join(reorder(lines), "\n"), startPos, true)
+	}
+
+	moveCursorToFirstNonWhitespaceInLine(buffer, newLastMovedLine)
+}
+
+// CopyLineRange copies the lines in a range below the line at a target address, like vim's ":t".
+// The target uses the same syntax as an address in ParseLineRange, and "0" copies the lines to the top of the document.
+// The cursor moves to the last of the copied lines.
+func CopyLineRange(state *EditorState, r LineRange, target string) {
+	insertLine, ok := parseTargetLine(state, target)
+	if !ok {
+		return
+	}
+
+	buffer := state.documentBuffer
+	tree := buffer.textTree
+	startPos := locate.StartOfLineNum(tree, r.StartLine)
+	endPos := locate.NextLineBoundary(tree, true, locate.StartOfLineNum(tree, r.EndLine))
+	lines := copyText(tree, startPos, endPos-startPos)
+
+	if insertLine == 0 {
+		mustInsertTextAtPosition(state, lines+"\n", 0, true)
+	} else {
+		insertPos := locate.NextLineBoundary(tree, true, locate.StartOfLineNum(tree, insertLine-1))
+		mustInsertTextAtPosition(state, "\n"+lines, insertPos, true)
+	}
+
+	moveCursorToFirstNonWhitespaceInLine(buffer, insertLine+r.EndLine-r.StartLine)
+}
+
+// parseTargetLine parses the target address of a move or copy, returning the zero-based line
+// number before which to insert the lines. This is equal to the number of lines in the document
+// if the lines go at the end. If the address is invalid, this sets an error status and returns false.
+func parseTargetLine(state *EditorState, target string) (uint64, bool) {
+	tree := state.documentBuffer.textTree
+	currentLine := tree.LineNumForPosition(state.documentBuffer.cursor.position)
+	targetAddr, err := parseLineAddress(strings.TrimSpace(target), currentLine, tree.NumLines())
+	if err == nil && (targetAddr < 0 || targetAddr > int64(tree.NumLines())) {
+		err = fmt.Errorf("Invalid range: line %d does not exist", targetAddr)
+	}
+	if err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  err.Error(),
+		})
+		return 0, false
+	}
+	return uint64(targetAddr), true
+}
+
+func moveCursorToFirstNonWhitespaceInLine(buffer *BufferState, lineNum uint64) {
+	buffer.cursor = cursorState{
+		position: locate.NextNonWhitespaceOrNewline(buffer.textTree, locate.StartOfLineNum(buffer.textTree, lineNum)),
+	}
+}
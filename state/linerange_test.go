@@ -0,0 +1,349 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/text"
+)
+
+func TestParseLineRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		s             string
+		currentLine   uint64
+		numLines      uint64
+		expected      LineRange
+		expectedError string
+	}{
+		{
+			name:     "single line number",
+			s:        "3",
+			numLines: 10,
+			expected: LineRange{StartLine: 2, EndLine: 2},
+		},
+		{
+			name:     "absolute range",
+			s:        "2,5",
+			numLines: 10,
+			expected: LineRange{StartLine: 1, EndLine: 4},
+		},
+		{
+			name:        "current line",
+			s:           ".",
+			currentLine: 4,
+			numLines:    10,
+			expected:    LineRange{StartLine: 4, EndLine: 4},
+		},
+		{
+			name:        "current line to last line",
+			s:           ".,$",
+			currentLine: 4,
+			numLines:    10,
+			expected:    LineRange{StartLine: 4, EndLine: 9},
+		},
+		{
+			name:        "entire document",
+			s:           "%",
+			currentLine: 4,
+			numLines:    10,
+			expected:    LineRange{StartLine: 0, EndLine: 9},
+		},
+		{
+			name:        "offsets from current line",
+			s:           ".+1,.+3",
+			currentLine: 4,
+			numLines:    10,
+			expected:    LineRange{StartLine: 5, EndLine: 7},
+		},
+		{
+			name:        "offsets without address",
+			s:           "-,+2",
+			currentLine: 4,
+			numLines:    10,
+			expected:    LineRange{StartLine: 3, EndLine: 6},
+		},
+		{
+			name:     "offset from last line",
+			s:        "$-2,$",
+			numLines: 10,
+			expected: LineRange{StartLine: 7, EndLine: 9},
+		},
+		{
+			name:        "empty address is current line",
+			s:           ",7",
+			currentLine: 2,
+			numLines:    10,
+			expected:    LineRange{StartLine: 2, EndLine: 6},
+		},
+		{
+			name:     "inverted range is swapped",
+			s:        "8,3",
+			numLines: 10,
+			expected: LineRange{StartLine: 2, EndLine: 7},
+		},
+		{
+			name:          "line zero",
+			s:             "0,3",
+			numLines:      10,
+			expectedError: "Invalid range: line 0 does not exist",
+		},
+		{
+			name:          "past last line",
+			s:             "5,11",
+			numLines:      10,
+			expectedError: "Invalid range: line 11 does not exist",
+		},
+		{
+			name:          "before first line",
+			s:             ".-5",
+			currentLine:   2,
+			numLines:      10,
+			expectedError: "Invalid range: line -2 does not exist",
+		},
+		{
+			name:          "invalid address",
+			s:             "1,'a",
+			numLines:      10,
+			expectedError: "Invalid range address \"'a\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := ParseLineRange(tc.s, tc.currentLine, tc.numLines)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, r)
+		})
+	}
+}
+
+func TestDeleteLineRange(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		lineRange      LineRange
+		expectedText   string
+		expectedCursor uint64
+		expectedClip   string
+	}{
+		{
+			name:           "single line",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 1, EndLine: 1},
+			expectedText:   "a\nc",
+			expectedCursor: 2,
+			expectedClip:   "b",
+		},
+		{
+			name:           "multiple lines",
+			inputString:    "a\nb\nc\nd",
+			lineRange:      LineRange{StartLine: 1, EndLine: 2},
+			expectedText:   "a\nd",
+			expectedCursor: 2,
+			expectedClip:   "b\nc",
+		},
+		{
+			name:           "through last line",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 1, EndLine: 2},
+			expectedText:   "a",
+			expectedCursor: 0,
+			expectedClip:   "b\nc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			DeleteLineRange(state, tc.lineRange, clipboard.PageDefault)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor.position)
+			assert.Equal(t, clipboard.PageContent{Text: tc.expectedClip, Linewise: true}, state.clipboard.Get(clipboard.PageDefault))
+		})
+	}
+}
+
+func TestYankLineRange(t *testing.T) {
+	textTree, err := text.NewTreeFromString("a\nbc\nd\ne")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.cursor.position = 7
+	YankLineRange(state, LineRange{StartLine: 1, EndLine: 2}, clipboard.PageLetterA)
+	assert.Equal(t, "a\nbc\nd\ne", textTree.String())
+	assert.Equal(t, uint64(7), state.documentBuffer.cursor.position)
+	assert.Equal(t, clipboard.PageContent{Text: "bc\nd", Linewise: true}, state.clipboard.Get(clipboard.PageLetterA))
+}
+
+func TestMoveLineRange(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		lineRange      LineRange
+		target         string
+		expectedText   string
+		expectedCursor uint64
+		expectedError  string
+	}{
+		{
+			name:           "move down",
+			inputString:    "a\nb\nc\nd\ne",
+			lineRange:      LineRange{StartLine: 0, EndLine: 1},
+			target:         "4",
+			expectedText:   "c\nd\na\nb\ne",
+			expectedCursor: 6,
+		},
+		{
+			name:           "move up",
+			inputString:    "a\nb\nc\nd\ne",
+			lineRange:      LineRange{StartLine: 3, EndLine: 4},
+			target:         "1",
+			expectedText:   "a\nd\ne\nb\nc",
+			expectedCursor: 4,
+		},
+		{
+			name:           "move to top",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 2, EndLine: 2},
+			target:         "0",
+			expectedText:   "c\na\nb",
+			expectedCursor: 0,
+		},
+		{
+			name:           "move to bottom",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 0, EndLine: 0},
+			target:         "$",
+			expectedText:   "b\nc\na",
+			expectedCursor: 4,
+		},
+		{
+			name:           "move below itself",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 0, EndLine: 1},
+			target:         "2",
+			expectedText:   "a\nb\nc",
+			expectedCursor: 2,
+		},
+		{
+			name:          "move into itself",
+			inputString:   "a\nb\nc",
+			lineRange:     LineRange{StartLine: 0, EndLine: 2},
+			target:        "2",
+			expectedText:  "a\nb\nc",
+			expectedError: "Cannot move lines into themselves",
+		},
+		{
+			name:          "target past last line",
+			inputString:   "a\nb\nc",
+			lineRange:     LineRange{StartLine: 0, EndLine: 0},
+			target:        "4",
+			expectedText:  "a\nb\nc",
+			expectedError: "Invalid range: line 4 does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			MoveLineRange(state, tc.lineRange, tc.target)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			if tc.expectedError != "" {
+				assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: tc.expectedError}, state.StatusMsg())
+				return
+			}
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor.position)
+
+			// Moving is a single undo operation.
+			Undo(state)
+			assert.Equal(t, tc.inputString, textTree.String())
+		})
+	}
+}
+
+func TestCopyLineRange(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		lineRange      LineRange
+		target         string
+		expectedText   string
+		expectedCursor uint64
+		expectedError  string
+	}{
+		{
+			name:           "copy to top",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 1, EndLine: 2},
+			target:         "0",
+			expectedText:   "b\nc\na\nb\nc",
+			expectedCursor: 2,
+		},
+		{
+			name:           "copy to end of document",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 0, EndLine: 1},
+			target:         "$",
+			expectedText:   "a\nb\nc\na\nb",
+			expectedCursor: 8,
+		},
+		{
+			name:           "copy below itself",
+			inputString:    "  a\nb",
+			lineRange:      LineRange{StartLine: 0, EndLine: 0},
+			target:         "1",
+			expectedText:   "  a\n  a\nb",
+			expectedCursor: 6,
+		},
+		{
+			name:           "copy into itself",
+			inputString:    "a\nb\nc",
+			lineRange:      LineRange{StartLine: 0, EndLine: 2},
+			target:         "1",
+			expectedText:   "a\na\nb\nc\nb\nc",
+			expectedCursor: 6,
+		},
+		{
+			name:          "target past last line",
+			inputString:   "a\nb\nc",
+			lineRange:     LineRange{StartLine: 0, EndLine: 0},
+			target:        "4",
+			expectedText:  "a\nb\nc",
+			expectedError: "Invalid range: line 4 does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			CopyLineRange(state, tc.lineRange, tc.target)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			if tc.expectedError != "" {
+				assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: tc.expectedError}, state.StatusMsg())
+				return
+			}
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor.position)
+
+			// Copying is a single undo operation.
+			Undo(state)
+			assert.Equal(t, tc.inputString, textTree.String())
+		})
+	}
+}
@@ -14,6 +14,10 @@ type LocatorParams struct {
 	CursorPos         uint64
 	AutoIndentEnabled bool
 	TabSize           uint64
+	VarTabStops       []uint64
+	MatchPairs        []locate.DelimiterPair
+	MatchWords        []locate.KeywordGroup
+	MatchTags         bool
 }
 
 func locatorParamsForBuffer(buffer *BufferState) LocatorParams {
@@ -23,6 +27,10 @@ func locatorParamsForBuffer(buffer *BufferState) LocatorParams {
 		CursorPos:         buffer.cursor.position,
 		AutoIndentEnabled: buffer.autoIndent,
 		TabSize:           buffer.tabSize,
+		VarTabStops:       buffer.varTabStops,
+		MatchPairs:        buffer.matchPairs,
+		MatchWords:        buffer.matchWords,
+		MatchTags:         buffer.matchTags,
 	}
 }
 
@@ -14,6 +14,15 @@ type MacroState struct {
 	isReplayingUserMacro   bool
 	userMacroActions       []MacroAction
 	stagedUserMacroActions []MacroAction
+	insertRepeat           insertRepeatState
+}
+
+// insertRepeatState records an insert session that should be repeated
+// when the user returns to normal mode. For example, "3ifoo<esc>" inserts "foo" three times.
+type insertRepeatState struct {
+	count   uint64
+	prefix  MacroAction // Executed before each repetition, such as to begin a new line.
+	actions []MacroAction
 }
 
 // AddToLastActionMacro adds an action to the "last action" macro.
@@ -35,6 +44,40 @@ func ReplayLastActionMacro(s *EditorState, count uint64) {
 	}
 }
 
+// BeginInsertRepeat starts recording the current insert session so it can be repeated
+// count-1 additional times when the user returns to normal mode.
+// If prefix is not nil, it executes before each repetition.
+func BeginInsertRepeat(s *EditorState, count uint64, prefix MacroAction) {
+	s.macroState.insertRepeat = insertRepeatState{count: count, prefix: prefix}
+}
+
+// AddToInsertRepeat adds an action to the insert session being recorded, if any.
+func AddToInsertRepeat(s *EditorState, action MacroAction) {
+	r := &s.macroState.insertRepeat
+	if r.count > 1 {
+		r.actions = append(r.actions, action)
+	}
+}
+
+// ReplayInsertRepeat repeats the recorded insert session, then stops recording.
+// All repetitions are part of the same undo entry as the original insert session.
+func ReplayInsertRepeat(s *EditorState) {
+	r := s.macroState.insertRepeat
+	s.macroState.insertRepeat = insertRepeatState{}
+	if r.count > 1 {
+		// The last inserted text should be the text typed once, not every repetition.
+		endInsertSession(s)
+	}
+	for i := uint64(1); i < r.count; i++ {
+		if r.prefix != nil {
+			r.prefix(s)
+		}
+		for _, action := range r.actions {
+			action(s)
+		}
+	}
+}
+
 // ToggleUserMacroRecording stops/starts recording a user-defined macro.
 // If recording stops before any actions have been recorded, the previously-recorded
 // macro will be preserved.
@@ -205,3 +205,31 @@ func TestReplayCheckpointUndo(t *testing.T) {
 	Undo(state)
 	assert.Equal(t, "", state.documentBuffer.textTree.String())
 }
+
+func TestInsertRepeat(t *testing.T) {
+	var logger actionLogger
+	state := NewEditorState(100, 100, nil, nil)
+	BeginInsertRepeat(state, 3, logger.buildAction("prefix"))
+	AddToInsertRepeat(state, logger.buildAction("a"))
+	AddToInsertRepeat(state, logger.buildAction("b"))
+	ReplayInsertRepeat(state)
+	expected := []actionLogEntry{
+		{name: "prefix"}, {name: "a"}, {name: "b"},
+		{name: "prefix"}, {name: "a"}, {name: "b"},
+	}
+	assert.Equal(t, expected, logger.logEntries)
+
+	// Replaying again does nothing, because the insert session has ended.
+	logger.clear()
+	ReplayInsertRepeat(state)
+	assert.Equal(t, 0, len(logger.logEntries))
+}
+
+func TestInsertRepeatWithoutCount(t *testing.T) {
+	var logger actionLogger
+	state := NewEditorState(100, 100, nil, nil)
+	BeginInsertRepeat(state, 1, nil)
+	AddToInsertRepeat(state, logger.buildAction("a"))
+	ReplayInsertRepeat(state)
+	assert.Equal(t, 0, len(logger.logEntries))
+}
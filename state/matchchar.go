@@ -0,0 +1,53 @@
+package state
+
+import (
+	"github.com/aretext/aretext/locate"
+)
+
+// matchCharState records the most recent f, F, t, or T motion so it can be repeated.
+type matchCharState struct {
+	char        rune
+	reverse     bool
+	includeChar bool
+	valid       bool
+}
+
+// SetLastMatchChar records a motion to a matching char in the current line
+// so it can be repeated by RepeatLastMatchChar.
+func SetLastMatchChar(state *EditorState, char rune, reverse bool, includeChar bool) {
+	state.lastMatchChar = matchCharState{
+		char:        char,
+		reverse:     reverse,
+		includeChar: includeChar,
+		valid:       true,
+	}
+}
+
+// RepeatLastMatchChar moves the cursor to the count'th next match of the last f, F, t, or T motion.
+// If oppositeDirection is true, it searches in the opposite direction of the original motion.
+//
+// When repeating a t or T motion with the cursor already adjacent to the matching char,
+// the original motion would not move the cursor, so this skips to the next match instead.
+func RepeatLastMatchChar(state *EditorState, count uint64, oppositeDirection bool) {
+	last := state.lastMatchChar
+	if !last.valid {
+		return
+	}
+
+	findMatch := locate.NextMatchingCharInLine
+	if last.reverse != oppositeDirection {
+		findMatch = locate.PrevMatchingCharInLine
+	}
+
+	MoveCursor(state, func(params LocatorParams) uint64 {
+		found, pos := findMatch(params.TextTree, last.char, count, last.includeChar, params.CursorPos)
+		if found && pos == params.CursorPos && !last.includeChar {
+			found, pos = findMatch(params.TextTree, last.char, count+1, last.includeChar, params.CursorPos)
+		}
+
+		if !found {
+			return params.CursorPos
+		}
+		return pos
+	})
+}
@@ -0,0 +1,117 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestRepeatLastMatchChar(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		char              rune
+		reverse           bool
+		includeChar       bool
+		count             uint64
+		oppositeDirection bool
+		expectedCursorPos uint64
+	}{
+		{
+			name:              "repeat find next",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  1,
+			char:              '.',
+			includeChar:       true,
+			count:             1,
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "repeat till next adjacent to match",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  0,
+			char:              '.',
+			count:             1,
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "repeat till next not adjacent to match",
+			inputString:       "a..b...c",
+			initialCursorPos:  2,
+			char:              '.',
+			count:             1,
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "repeat till next with count adjacent to match",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  0,
+			char:              '.',
+			count:             2,
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "repeat till next with no further match",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  4,
+			char:              '.',
+			count:             1,
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "repeat till prev adjacent to match",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  6,
+			char:              '.',
+			reverse:           true,
+			count:             1,
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "repeat till next in opposite direction adjacent to match",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  4,
+			char:              '.',
+			count:             1,
+			oppositeDirection: true,
+			expectedCursorPos: 2,
+		},
+		{
+			name:              "repeat find prev in opposite direction",
+			inputString:       "a.b.c.d",
+			initialCursorPos:  1,
+			char:              '.',
+			reverse:           true,
+			includeChar:       true,
+			count:             1,
+			oppositeDirection: true,
+			expectedCursorPos: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.initialCursorPos}
+			SetLastMatchChar(state, tc.char, tc.reverse, tc.includeChar)
+			RepeatLastMatchChar(state, tc.count, tc.oppositeDirection)
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+		})
+	}
+}
+
+func TestRepeatLastMatchCharWithoutPreviousMotion(t *testing.T) {
+	textTree, err := text.NewTreeFromString("a.b.c.d")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	RepeatLastMatchChar(state, 1, false)
+	assert.Equal(t, uint64(0), state.documentBuffer.cursor.position)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/aretext/aretext/file"
 	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/selection"
 )
 
 type MenuStyle int
@@ -24,12 +25,15 @@ const (
 	MenuStyleParentDir
 	MenuStyleInsertChoice
 	MenuStyleWorkingDir
+	MenuStyleStatusMsgHistory
+	MenuStyleUndoList
+	MenuStyleOutline
 )
 
 // EmptyQueryShowAll returns whether an empty query should show all items.
 func (s MenuStyle) EmptyQueryShowAll() bool {
 	switch s {
-	case MenuStyleFilePath, MenuStyleFileLocation, MenuStyleChildDir, MenuStyleParentDir, MenuStyleInsertChoice, MenuStyleWorkingDir:
+	case MenuStyleFilePath, MenuStyleFileLocation, MenuStyleChildDir, MenuStyleParentDir, MenuStyleInsertChoice, MenuStyleWorkingDir, MenuStyleStatusMsgHistory, MenuStyleUndoList, MenuStyleOutline:
 		return true
 	default:
 		return false
@@ -238,12 +242,58 @@ func ExecuteSelectedMenuItem(state *EditorState) {
 
 func executeMenuItemAction(state *EditorState, item menu.Item) {
 	log.Printf("Executing menu item %q\n", item.Name)
-	actionFunc, ok := item.Action.(func(*EditorState))
-	if !ok {
+	switch actionFunc := item.Action.(type) {
+	case func(*EditorState):
+		actionFunc(state)
+	case func(*EditorState, string):
+		actionFunc(state, item.Arg)
+	case func(*EditorState, LineRange):
+		if r, ok := lineRangeForMenuItem(state, item); ok {
+			actionFunc(state, r)
+		}
+	case func(*EditorState, LineRange, string):
+		if r, ok := lineRangeForMenuItem(state, item); ok {
+			actionFunc(state, r, item.Arg)
+		}
+	case func(*EditorState, *LineRange, string):
+		// The range is optional, so the action receives nil if the user did not type a range or select lines.
+		if item.Range == "" && state.documentBuffer.selector.Mode() == selection.ModeNone {
+			actionFunc(state, nil, item.Arg)
+		} else if r, ok := lineRangeForMenuItem(state, item); ok {
+			actionFunc(state, &r, item.Arg)
+		}
+	default:
 		log.Printf("Invalid action for menu item %q\n", item.Name)
-		return
 	}
-	actionFunc(state)
+}
+
+// lineRangeForMenuItem returns the line range for a menu item that accepts a range.
+// This is the range the user typed before the alias, or else the lines in the visual mode selection,
+// or else the item's default range. The editor returns to normal mode, since the command replaces the selection.
+// If the range is invalid, this sets an error status and returns false.
+func lineRangeForMenuItem(state *EditorState, item menu.Item) (LineRange, bool) {
+	buffer := state.documentBuffer
+	r, hasSelection := selectedLineRange(buffer)
+	SetInputMode(state, InputModeNormal)
+	if item.Range == "" && hasSelection {
+		return r, true
+	}
+
+	rangeStr := item.Range
+	if rangeStr == "" {
+		rangeStr = item.DefaultRange
+	}
+
+	currentLine := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	r, err := ParseLineRange(rangeStr, currentLine, buffer.textTree.NumLines())
+	if err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  err.Error(),
+		})
+		return LineRange{}, false
+	}
+	return r, true
 }
 
 // MoveMenuSelection moves the menu selection up or down with wraparound.
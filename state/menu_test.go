@@ -68,6 +68,28 @@ func TestSelectAndExecuteMenuItem(t *testing.T) {
 	assert.True(t, state.QuitFlag())
 }
 
+func TestSelectAndExecuteMenuItemWithArg(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	var executedArg string
+	items := []menu.Item{
+		{
+			Name:       "test item",
+			Aliases:    []string{"t"},
+			AcceptsArg: true,
+			Action: func(s *EditorState, arg string) {
+				executedArg = arg
+			},
+		},
+	}
+	ShowMenu(state, MenuStyleCommand, items)
+	for _, r := range "t foo bar" {
+		AppendRuneToMenuSearch(state, r)
+	}
+	ExecuteSelectedMenuItem(state)
+	assert.False(t, state.Menu().Visible())
+	assert.Equal(t, "foo bar", executedArg)
+}
+
 func TestMoveMenuSelection(t *testing.T) {
 	testCases := []struct {
 		name              string
@@ -2,6 +2,7 @@ package state
 
 import (
 	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/text"
 )
 
 // InputMode controls how the editor interprets input events.
@@ -47,14 +48,87 @@ func SetInputMode(state *EditorState, mode InputMode) {
 	}
 
 	if state.inputMode == InputModeVisual && (mode == InputModeNormal || mode == InputModeInsert) {
-		// Clear selection when exiting visual mode.
+		// Clear selection when exiting visual mode, remembering it so "gv" can restore it.
+		saveLastSelection(state.documentBuffer)
 		state.documentBuffer.selector.Clear()
 	}
 
+	if mode != InputModeInsert {
+		// Tab inserts a tab again after leaving insert mode.
+		endSnippetSession(state)
+	}
+
+	if mode != InputModeNormal && mode != InputModeInsert {
+		// Extra cursors apply only to edits in insert mode, so other modes use only the main cursor.
+		state.documentBuffer.extraCursors = nil
+	}
+
+	if state.inputMode != InputModeInsert && mode == InputModeInsert {
+		startInsertSession(state)
+	} else if state.inputMode == InputModeInsert && mode != InputModeInsert {
+		endInsertSession(state)
+	}
+
+	if state.inputMode != mode {
+		recordEvent(state, EventTypeInputModeChanged)
+	}
+
 	state.prevInputMode = state.inputMode
 	state.inputMode = mode
 }
 
+// lastSelectionState is the most recent visual mode selection.
+type lastSelectionState struct {
+	mode      selection.Mode // ModeNone if there was no selection.
+	anchorPos uint64
+	cursorPos uint64
+}
+
+func saveLastSelection(buffer *BufferState) {
+	buffer.lastSelection = lastSelectionState{
+		mode:      buffer.selector.Mode(),
+		anchorPos: buffer.selector.AnchorPos(),
+		cursorPos: buffer.cursor.position,
+	}
+}
+
+// ReselectLastSelection restores the most recent visual mode selection, like vim's "gv".
+// If the document changed since then, positions past the end of the document move to the last character.
+func ReselectLastSelection(state *EditorState) {
+	buffer := state.documentBuffer
+	last := buffer.lastSelection
+	if last.mode == selection.ModeNone {
+		return
+	}
+
+	SetInputMode(state, InputModeVisual)
+	buffer.selector.Start(last.mode, clampPosToLastChar(buffer.textTree, last.anchorPos))
+	buffer.cursor = cursorState{position: clampPosToLastChar(buffer.textTree, last.cursorPos)}
+}
+
+// SwapSelectionEnds moves the cursor to the other end of the visual mode selection, like vim's "o".
+// The selected region stays the same, but moving the cursor now extends or shrinks the selection from the other end.
+func SwapSelectionEnds(state *EditorState) {
+	buffer := state.documentBuffer
+	if buffer.selector.Mode() == selection.ModeNone {
+		return
+	}
+
+	anchorPos := buffer.selector.AnchorPos()
+	buffer.selector.Start(buffer.selector.Mode(), buffer.cursor.position)
+	buffer.cursor = cursorState{position: anchorPos}
+}
+
+func clampPosToLastChar(tree *text.Tree, pos uint64) uint64 {
+	n := tree.NumChars()
+	if n == 0 {
+		return 0
+	} else if pos >= n {
+		return n - 1
+	}
+	return pos
+}
+
 // ToggleVisualMode transitions to/from visual selection mode.
 func ToggleVisualMode(state *EditorState, selectionMode selection.Mode) {
 	buffer := state.documentBuffer
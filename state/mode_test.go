@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/text"
 )
 
 func TestNormalToVisualMode(t *testing.T) {
@@ -86,3 +88,148 @@ func TestToggleVisualModeDifferentSelectionMode(t *testing.T) {
 		})
 	}
 }
+
+func TestCursorShapeForInputMode(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	assert.Equal(t, "block", state.CursorShape())
+
+	SetInputMode(state, InputModeInsert)
+	assert.Equal(t, "bar", state.CursorShape())
+
+	SetInputMode(state, InputModeNormal)
+	ToggleVisualMode(state, selection.ModeChar)
+	assert.Equal(t, "block", state.CursorShape())
+
+	SetOptions(state, "cursorShapeVisual=underline", false)
+	assert.Equal(t, "underline", state.CursorShape())
+
+	SetInputMode(state, InputModeSearch)
+	assert.Equal(t, "bar", state.CursorShape())
+
+	SetInputMode(state, InputModeTask)
+	assert.Equal(t, "default", state.CursorShape())
+}
+
+func TestReselectLastSelection(t *testing.T) {
+	testCases := []struct {
+		name           string
+		initialText    string
+		selectionMode  selection.Mode
+		anchorPos      uint64
+		cursorPos      uint64
+		editFunc       func(*EditorState)
+		expectedRegion selection.Region
+		expectedCursor uint64
+	}{
+		{
+			name:           "charwise",
+			initialText:    "abc def ghi",
+			selectionMode:  selection.ModeChar,
+			anchorPos:      4,
+			cursorPos:      6,
+			expectedRegion: selection.Region{StartPos: 4, EndPos: 7},
+			expectedCursor: 6,
+		},
+		{
+			name:           "charwise with cursor before anchor",
+			initialText:    "abc def ghi",
+			selectionMode:  selection.ModeChar,
+			anchorPos:      6,
+			cursorPos:      2,
+			expectedRegion: selection.Region{StartPos: 2, EndPos: 7},
+			expectedCursor: 2,
+		},
+		{
+			name:           "linewise",
+			initialText:    "abc\ndef\nghi",
+			selectionMode:  selection.ModeLine,
+			anchorPos:      1,
+			cursorPos:      5,
+			expectedRegion: selection.Region{StartPos: 0, EndPos: 7},
+			expectedCursor: 5,
+		},
+		{
+			name:          "text deleted after selection clamps to end of document",
+			initialText:   "abc def ghi",
+			selectionMode: selection.ModeChar,
+			anchorPos:     4,
+			cursorPos:     9,
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 3, 8, false)
+			},
+			expectedRegion: selection.Region{StartPos: 2, EndPos: 3},
+			expectedCursor: 2,
+		},
+		{
+			name:          "all text deleted after selection",
+			initialText:   "abc\ndef",
+			selectionMode: selection.ModeLine,
+			anchorPos:     1,
+			cursorPos:     5,
+			editFunc: func(state *EditorState) {
+				deleteRunes(state, 0, 7, false)
+			},
+			expectedRegion: selection.Region{StartPos: 0, EndPos: 0},
+			expectedCursor: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.initialText)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.anchorPos
+			ToggleVisualMode(state, tc.selectionMode)
+			buffer.cursor.position = tc.cursorPos
+			SetInputMode(state, InputModeNormal)
+			buffer.cursor.position = 0
+
+			if tc.editFunc != nil {
+				tc.editFunc(state)
+			}
+
+			ReselectLastSelection(state)
+			assert.Equal(t, InputModeVisual, state.InputMode())
+			assert.Equal(t, tc.selectionMode, buffer.SelectionMode())
+			assert.Equal(t, tc.expectedRegion, buffer.SelectedRegion())
+			assert.Equal(t, tc.expectedCursor, buffer.CursorPosition())
+		})
+	}
+}
+
+func TestReselectLastSelectionWithoutPreviousSelection(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	ReselectLastSelection(state)
+	assert.Equal(t, InputModeNormal, state.InputMode())
+	assert.Equal(t, selection.ModeNone, state.documentBuffer.SelectionMode())
+}
+
+func TestSwapSelectionEnds(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc def ghi")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.cursor.position = 4
+	ToggleVisualMode(state, selection.ModeChar)
+	buffer.cursor.position = 6
+
+	SwapSelectionEnds(state)
+	assert.Equal(t, uint64(4), buffer.CursorPosition())
+	assert.Equal(t, selection.Region{StartPos: 4, EndPos: 7}, buffer.SelectedRegion())
+
+	// Moving the cursor now extends the selection from the start.
+	buffer.cursor.position = 2
+	assert.Equal(t, selection.Region{StartPos: 2, EndPos: 7}, buffer.SelectedRegion())
+
+	SwapSelectionEnds(state)
+	assert.Equal(t, uint64(6), buffer.CursorPosition())
+	assert.Equal(t, selection.Region{StartPos: 2, EndPos: 7}, buffer.SelectedRegion())
+
+	// Moving the cursor shrinks the selection from the end.
+	buffer.cursor.position = 5
+	assert.Equal(t, selection.Region{StartPos: 2, EndPos: 6}, buffer.SelectedRegion())
+}
@@ -0,0 +1,166 @@
+package state
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/text"
+)
+
+// maxModelineSearchLines is the number of lines at the start and end of a document to search for a modeline,
+// the same as vim's default "modelines" setting.
+const maxModelineSearchLines = 5
+
+// maxModelineTabSize is the largest tab size a modeline can set,
+// so a document can't make the editor render unreasonably wide tabs.
+const maxModelineTabSize = 64
+
+// modelineRegexp matches a vim modeline like "vim: ts=4 sw=4 et" or "vi: set ts=4 sw=4 et:".
+// The "vi:" or "vim:" must be at the start of the line or follow whitespace.
+var modelineRegexp = regexp.MustCompile(`(?:^|\s)(?:vi|vim|Vim):\s*(.*)$`)
+
+// modelineSetRegexp matches the "set" form of a modeline, which ends at the next colon.
+var modelineSetRegexp = regexp.MustCompile(`^se(?:t)?\s+([^:]*):`)
+
+// modelineOptions are the options set by a modeline.
+// Only options that change how the document is indented are supported,
+// since a modeline comes from the document, which might not be trusted.
+type modelineOptions struct {
+	tabStop     uint64 // Zero if not set.
+	shiftWidth  uint64 // Zero if not set.
+	softTabStop uint64 // Zero if not set.
+	tabExpand   bool
+	hasExpand   bool
+}
+
+// findModeline searches the first and last lines of a document for a modeline.
+// If there are several modelines, the last one takes precedence, as in vim.
+func findModeline(tree *text.Tree) (modelineOptions, bool) {
+	var opts modelineOptions
+	var found bool
+	numLines := tree.NumLines()
+	for lineNum := uint64(0); lineNum < numLines; lineNum++ {
+		if lineNum == maxModelineSearchLines && numLines > 2*maxModelineSearchLines {
+			// Skip to the last lines of the document.
+			lineNum = numLines - maxModelineSearchLines
+		}
+
+		startPos := tree.LineStartPosition(lineNum)
+		endPos := locate.NextLineBoundary(tree, true, startPos)
+		line := copyText(tree, startPos, endPos-startPos)
+		if lineOpts, ok := parseModeline(line); ok {
+			opts = lineOpts
+			found = true
+		}
+	}
+	return opts, found
+}
+
+// parseModeline parses a modeline in either the form "vim: ts=4 sw=4 et" or "vim: set ts=4 sw=4 et:".
+// Unsupported options are ignored, and the second return value is false if the line has no supported options.
+func parseModeline(line string) (modelineOptions, bool) {
+	var opts modelineOptions
+	submatches := modelineRegexp.FindStringSubmatch(line)
+	if submatches == nil {
+		return opts, false
+	}
+
+	var args []string
+	if setSubmatches := modelineSetRegexp.FindStringSubmatch(submatches[1]); setSubmatches != nil {
+		args = strings.Fields(setSubmatches[1])
+	} else {
+		args = strings.FieldsFunc(submatches[1], func(r rune) bool {
+			return r == ':' || r == ' ' || r == '\t'
+		})
+	}
+
+	var found bool
+	for _, arg := range args {
+		if parseModelineOption(arg, &opts) {
+			found = true
+		} else {
+			log.Printf("Ignoring unsupported modeline option %q\n", arg)
+		}
+	}
+	return opts, found
+}
+
+// parseModelineOption sets a supported option from a modeline argument like "ts=4" or "noet".
+// It returns false if the option is unsupported or the value is invalid.
+func parseModelineOption(arg string, opts *modelineOptions) bool {
+	name, value, hasValue := strings.Cut(arg, "=")
+	if !hasValue {
+		switch name {
+		case "et", "expandtab":
+			opts.tabExpand, opts.hasExpand = true, true
+			return true
+		case "noet", "noexpandtab":
+			opts.tabExpand, opts.hasExpand = false, true
+			return true
+		default:
+			return false
+		}
+	}
+
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || n > maxModelineTabSize {
+		return false
+	}
+
+	switch name {
+	case "ts", "tabstop":
+		if n == 0 {
+			return false
+		}
+		opts.tabStop = n
+	case "sw", "shiftwidth":
+		opts.shiftWidth = n
+	case "sts", "softtabstop":
+		opts.softTabStop = n
+	default:
+		return false
+	}
+	return true
+}
+
+// applyModeline sets the document's tab settings from a modeline in the document.
+// The modeline takes precedence over the guessed indentation, but options set explicitly
+// by config rules or the "set" command take precedence over the modeline.
+func applyModeline(state *EditorState, configKeys map[string]struct{}) {
+	buffer := state.documentBuffer
+	opts, ok := findModeline(buffer.textTree)
+	if !ok {
+		return
+	}
+
+	log.Printf("Found modeline: %+v\n", opts)
+
+	if _, ok := configKeys["tabExpand"]; !ok && opts.hasExpand {
+		buffer.tabExpand = opts.tabExpand
+	}
+
+	// aretext uses the tab size both for the width of a tab and for the number of spaces to indent,
+	// so prefer vim's indent width when indenting with spaces, and the tab width otherwise.
+	var tabSize uint64
+	if buffer.tabExpand {
+		tabSize = firstNonZero(opts.shiftWidth, opts.softTabStop, opts.tabStop)
+	} else {
+		tabSize = firstNonZero(opts.tabStop, opts.shiftWidth, opts.softTabStop)
+	}
+
+	if _, ok := configKeys["tabSize"]; !ok && tabSize > 0 {
+		buffer.tabSize = tabSize
+	}
+}
+
+func firstNonZero(values ...uint64) uint64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,218 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/text"
+)
+
+func TestParseModeline(t *testing.T) {
+	testCases := []struct {
+		name         string
+		line         string
+		expectedOk   bool
+		expectedOpts modelineOptions
+	}{
+		{
+			name:       "no modeline",
+			line:       "func main() {",
+			expectedOk: false,
+		},
+		{
+			name:         "vim short names",
+			line:         "// vim: ts=4 sw=4 et",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 4, shiftWidth: 4, tabExpand: true, hasExpand: true},
+		},
+		{
+			name:         "vi long names",
+			line:         "# vi: tabstop=8 shiftwidth=2 softtabstop=2 expandtab",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 8, shiftWidth: 2, softTabStop: 2, tabExpand: true, hasExpand: true},
+		},
+		{
+			name:         "options separated by colons",
+			line:         "vim:ts=8:noet",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 8, tabExpand: false, hasExpand: true},
+		},
+		{
+			name:         "set form",
+			line:         "/* vim: set ts=2 sw=2 noexpandtab: */",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 2, shiftWidth: 2, tabExpand: false, hasExpand: true},
+		},
+		{
+			name:         "se form ignores text after colon",
+			line:         "# Vim: se ts=3: et",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 3},
+		},
+		{
+			name:       "vim not preceded by whitespace",
+			line:       "//vim: ts=4",
+			expectedOk: false,
+		},
+		{
+			name:       "text that is not a modeline",
+			line:       "Use vi: it is a text editor.",
+			expectedOk: false,
+		},
+		{
+			name:       "unsafe options rejected",
+			line:       "# vim: set foldexpr=system('rm') modelineexpr:",
+			expectedOk: false,
+		},
+		{
+			name:         "unsafe options rejected with safe options",
+			line:         "# vim: set ts=4 foldexpr=system('rm') et:",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 4, tabExpand: true, hasExpand: true},
+		},
+		{
+			name:       "unknown option rejected",
+			line:       "// vim: ft=go",
+			expectedOk: false,
+		},
+		{
+			name:       "invalid value rejected",
+			line:       "// vim: ts=abc",
+			expectedOk: false,
+		},
+		{
+			name:       "zero tabstop rejected",
+			line:       "// vim: ts=0",
+			expectedOk: false,
+		},
+		{
+			name:       "tabstop too large rejected",
+			line:       "// vim: ts=1000",
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, ok := parseModeline(tc.line)
+			assert.Equal(t, tc.expectedOk, ok)
+			if tc.expectedOk {
+				assert.Equal(t, tc.expectedOpts, opts)
+			}
+		})
+	}
+}
+
+func TestFindModeline(t *testing.T) {
+	manyLines := strings.Repeat("x\n", 20)
+
+	testCases := []struct {
+		name         string
+		inputString  string
+		expectedOk   bool
+		expectedOpts modelineOptions
+	}{
+		{
+			name:        "empty",
+			inputString: "",
+			expectedOk:  false,
+		},
+		{
+			name:         "first line",
+			inputString:  "// vim: ts=4\n" + manyLines,
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 4},
+		},
+		{
+			name:         "last line",
+			inputString:  manyLines + "// vim: ts=4\n",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 4},
+		},
+		{
+			name:        "middle of document",
+			inputString: manyLines + "// vim: ts=4\n" + manyLines,
+			expectedOk:  false,
+		},
+		{
+			name:         "last modeline takes precedence",
+			inputString:  "// vim: ts=4\n" + manyLines + "// vim: ts=2\n",
+			expectedOk:   true,
+			expectedOpts: modelineOptions{tabStop: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			opts, ok := findModeline(tree)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedOpts, opts)
+		})
+	}
+}
+
+func TestLoadDocumentModeline(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "default",
+			Pattern: "**",
+			Config:  map[string]any{"autoIndent": true},
+		},
+		{
+			Name:    "no modeline",
+			Pattern: "**/*.go",
+			Config:  map[string]any{"modeline": false},
+		},
+		{
+			Name:    "explicit",
+			Pattern: "**/*.py",
+			Config:  map[string]any{"tabSize": 8, "tabExpand": false},
+		},
+	}
+
+	dir := t.TempDir()
+	spacesPath := filepath.Join(dir, "spaces.txt")
+	tabsPath := filepath.Join(dir, "tabs.txt")
+	goPath := filepath.Join(dir, "test.go")
+	pyPath := filepath.Join(dir, "test.py")
+	require.NoError(t, os.WriteFile(spacesPath, []byte("a\n  b\n    c\n# vim: ts=8 sw=4 et\n"), 0644))
+	require.NoError(t, os.WriteFile(tabsPath, []byte("/* vi: set ts=2 sw=4 noet: */\na\n  b\n    c\n"), 0644))
+	require.NoError(t, os.WriteFile(goPath, []byte("a\n\tb\n// vim: ts=3\n"), 0644))
+	require.NoError(t, os.WriteFile(pyPath, []byte("a\n  b\n# vim: ts=2 sw=2 et\n"), 0644))
+
+	state := NewEditorState(100, 100, configRuleSet, nil)
+	defer state.fileWatcher.Stop()
+
+	// The modeline overrides the guessed indentation.
+	LoadDocument(state, spacesPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(4), state.documentBuffer.tabSize)
+
+	LoadDocument(state, tabsPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(2), state.documentBuffer.tabSize)
+
+	// Modelines can be disabled in the config.
+	LoadDocument(state, goPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(config.DefaultTabSize), state.documentBuffer.tabSize)
+
+	// Tab settings from a config rule override the modeline.
+	LoadDocument(state, pyPath, true, startOfDocLocator)
+	assert.False(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(8), state.documentBuffer.tabSize)
+
+	// Options set with "set" override the modeline.
+	SetOptions(state, "tabSize=5", false)
+	LoadDocument(state, spacesPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.tabExpand)
+	assert.Equal(t, uint64(5), state.documentBuffer.tabSize)
+}
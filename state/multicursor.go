@@ -0,0 +1,170 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/locate"
+)
+
+// ExtraCursors returns the positions of cursors added with AddCursorAtNextMatch, sorted ascending.
+// The main cursor is not included.
+func (s *BufferState) ExtraCursors() []uint64 {
+	return s.extraCursors
+}
+
+// AddCursorAtNextMatch adds a cursor at the next occurrence of the word under the cursor, similar to ctrl-d in other editors.
+// The main cursor moves to the match, and its previous position becomes an extra cursor.
+// Edits in insert mode then apply at every cursor. This does nothing unless the multiCursor option is enabled.
+func AddCursorAtNextMatch(state *EditorState) {
+	buffer := state.documentBuffer
+	if !buffer.multiCursor {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "Multiple cursors are disabled. Set multiCursor to true to enable them.",
+		})
+		return
+	}
+
+	word, wordStartPos, ok := wordAtCursor(buffer)
+	if !ok {
+		return
+	}
+
+	// Keep the cursor's offset within the word, or start at the beginning of the word
+	// if the cursor is on whitespace before it.
+	mainPos := buffer.cursor.position
+	if mainPos < wordStartPos || mainPos >= wordStartPos+uint64(utf8.RuneCountInString(word)) {
+		mainPos = wordStartPos
+	}
+	offset := mainPos - wordStartPos
+
+	// Search for the next match that doesn't already have a cursor, wrapping at the end of the document.
+	parsedQuery := parseQuery(fmt.Sprintf("%s\\C", word), false) // Force case-sensitive search.
+	searchPos := wordStartPos + 1
+	for i := 0; i <= len(buffer.extraCursors); i++ {
+		found, matchPos, _ := searchTextForward(searchPos, buffer.textTree, parsedQuery, true)
+		if !found {
+			break
+		}
+
+		newPos := matchPos + offset
+		if newPos == mainPos {
+			// Wrapped back to the main cursor, so every match has a cursor.
+			break
+		}
+
+		if !extraCursorsContain(buffer.extraCursors, newPos) {
+			addExtraCursor(buffer, mainPos)
+			buffer.cursor = cursorState{position: newPos}
+			return
+		}
+		searchPos = matchPos + 1
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  fmt.Sprintf("No more matches for %q", word),
+	})
+}
+
+// wordAtCursor returns the word under the cursor and the position where it starts.
+// If the cursor is on whitespace, this returns the word after the whitespace.
+func wordAtCursor(buffer *BufferState) (string, uint64, bool) {
+	wordStartPos, wordEndPos := locate.WordObject(buffer.textTree, buffer.cursor.position, 1)
+	text := copyText(buffer.textTree, wordStartPos, wordEndPos-wordStartPos)
+	word := strings.TrimSpace(text)
+	if word == "" {
+		return "", 0, false
+	}
+
+	leadingSpace := strings.TrimSuffix(text, strings.TrimLeftFunc(text, unicode.IsSpace))
+	return word, wordStartPos + uint64(utf8.RuneCountInString(leadingSpace)), true
+}
+
+// RemoveExtraCursors removes all cursors except the main cursor.
+func RemoveExtraCursors(state *EditorState) {
+	state.documentBuffer.extraCursors = nil
+}
+
+// ApplyAtAllCursors performs an edit at the main cursor, then at each extra cursor.
+// Edits at one cursor shift the positions of the other cursors, and cursors that end up
+// at the same position merge into one.
+func ApplyAtAllCursors(state *EditorState, f func(*EditorState)) {
+	buffer := state.documentBuffer
+	if len(buffer.extraCursors) == 0 {
+		f(state)
+		return
+	}
+
+	// Track the main cursor at index zero with the extra cursors, so edits at any cursor shift all the others.
+	// Skip duplicate positions, which can occur after a deletion, to avoid repeating the edit.
+	positions := make([]uint64, 0, len(buffer.extraCursors)+1)
+	positions = append(positions, buffer.cursor.position)
+	for _, pos := range buffer.extraCursors {
+		if pos != positions[len(positions)-1] && pos != buffer.cursor.position {
+			positions = append(positions, pos)
+		}
+	}
+	buffer.extraCursors = positions
+
+	for i := range positions {
+		buffer.cursor = cursorState{position: buffer.extraCursors[i]}
+		f(state)
+		buffer.extraCursors[i] = buffer.cursor.position
+	}
+
+	mainPos := buffer.extraCursors[0]
+	buffer.cursor = cursorState{position: mainPos}
+	extraCursors := buffer.extraCursors[1:]
+	buffer.extraCursors = nil
+	for _, pos := range extraCursors {
+		if pos != mainPos {
+			addExtraCursor(buffer, pos)
+		}
+	}
+}
+
+// addExtraCursor adds a cursor at pos, keeping the extra cursors sorted and unique.
+func addExtraCursor(buffer *BufferState, pos uint64) {
+	i := sort.Search(len(buffer.extraCursors), func(i int) bool {
+		return buffer.extraCursors[i] >= pos
+	})
+	if i < len(buffer.extraCursors) && buffer.extraCursors[i] == pos {
+		return
+	}
+	buffer.extraCursors = append(buffer.extraCursors, 0)
+	copy(buffer.extraCursors[i+1:], buffer.extraCursors[i:])
+	buffer.extraCursors[i] = pos
+}
+
+func extraCursorsContain(extraCursors []uint64, pos uint64) bool {
+	i := sort.Search(len(extraCursors), func(i int) bool {
+		return extraCursors[i] >= pos
+	})
+	return i < len(extraCursors) && extraCursors[i] == pos
+}
+
+// adjustExtraCursorsForInsert updates extra cursor positions after n runes were inserted at pos.
+func adjustExtraCursorsForInsert(buffer *BufferState, pos uint64, n uint64) {
+	for i, p := range buffer.extraCursors {
+		if p >= pos {
+			buffer.extraCursors[i] = p + n
+		}
+	}
+}
+
+// adjustExtraCursorsForDelete updates extra cursor positions after n runes were deleted at pos.
+// A cursor within the deleted text moves to the start of the deletion.
+func adjustExtraCursorsForDelete(buffer *BufferState, pos uint64, n uint64) {
+	for i, p := range buffer.extraCursors {
+		if p >= pos+n {
+			buffer.extraCursors[i] = p - n
+		} else if p > pos {
+			buffer.extraCursors[i] = pos
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestAddCursorAtNextMatch(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		inputString          string
+		initialCursorPos     uint64
+		numTimes             int
+		expectedCursorPos    uint64
+		expectedExtraCursors []uint64
+	}{
+		{
+			name:                 "cursor at start of word",
+			inputString:          "foo bar foo",
+			initialCursorPos:     0,
+			numTimes:             1,
+			expectedCursorPos:    8,
+			expectedExtraCursors: []uint64{0},
+		},
+		{
+			name:                 "cursor in middle of word keeps offset",
+			inputString:          "foo bar foo",
+			initialCursorPos:     1,
+			numTimes:             1,
+			expectedCursorPos:    9,
+			expectedExtraCursors: []uint64{1},
+		},
+		{
+			name:                 "cursor on whitespace before word",
+			inputString:          "  foo foo",
+			initialCursorPos:     0,
+			numTimes:             1,
+			expectedCursorPos:    6,
+			expectedExtraCursors: []uint64{2},
+		},
+		{
+			name:                 "wrap to start of document",
+			inputString:          "foo bar foo baz foo",
+			initialCursorPos:     8,
+			numTimes:             2,
+			expectedCursorPos:    0,
+			expectedExtraCursors: []uint64{8, 16},
+		},
+		{
+			name:                 "no more matches",
+			inputString:          "foo bar foo",
+			initialCursorPos:     0,
+			numTimes:             3,
+			expectedCursorPos:    8,
+			expectedExtraCursors: []uint64{0},
+		},
+		{
+			name:                 "case-sensitive",
+			inputString:          "foo Foo foo",
+			initialCursorPos:     0,
+			numTimes:             1,
+			expectedCursorPos:    8,
+			expectedExtraCursors: []uint64{0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.multiCursor = true
+			state.documentBuffer.cursor = cursorState{position: tc.initialCursorPos}
+			for i := 0; i < tc.numTimes; i++ {
+				AddCursorAtNextMatch(state)
+			}
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, tc.expectedExtraCursors, state.documentBuffer.ExtraCursors())
+		})
+	}
+}
+
+func TestAddCursorAtNextMatchDisabled(t *testing.T) {
+	textTree, err := text.NewTreeFromString("foo foo")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	AddCursorAtNextMatch(state)
+	assert.Equal(t, uint64(0), state.documentBuffer.cursor.position)
+	assert.Empty(t, state.documentBuffer.ExtraCursors())
+	assert.Equal(t, StatusMsgStyleError, state.statusMsg.Style)
+}
+
+func TestApplyAtAllCursors(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		inputString          string
+		cursorPos            uint64
+		extraCursors         []uint64
+		action               func(*EditorState)
+		expectedText         string
+		expectedCursorPos    uint64
+		expectedExtraCursors []uint64
+	}{
+		{
+			name:         "insert at each cursor",
+			inputString:  "ab\ncd\nef",
+			cursorPos:    3,
+			extraCursors: []uint64{0, 6},
+			action: func(state *EditorState) {
+				InsertRune(state, 'x')
+			},
+			expectedText:         "xab\nxcd\nxef",
+			expectedCursorPos:    5,
+			expectedExtraCursors: []uint64{1, 9},
+		},
+		{
+			name:         "delete at each cursor",
+			inputString:  "abc abc",
+			cursorPos:    6,
+			extraCursors: []uint64{2},
+			action: func(state *EditorState) {
+				deleteRunes(state, state.documentBuffer.cursor.position-1, 1, true)
+				state.documentBuffer.cursor.position--
+			},
+			expectedText:         "ac ac",
+			expectedCursorPos:    4,
+			expectedExtraCursors: []uint64{1},
+		},
+		{
+			name:         "overlapping deletes merge cursors",
+			inputString:  "abcd",
+			cursorPos:    2,
+			extraCursors: []uint64{1},
+			action: func(state *EditorState) {
+				pos := state.documentBuffer.cursor.position
+				if pos > 0 {
+					deleteRunes(state, pos-1, 1, true)
+					state.documentBuffer.cursor.position--
+				}
+			},
+			expectedText:         "cd",
+			expectedCursorPos:    0,
+			expectedExtraCursors: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			state.documentBuffer.extraCursors = tc.extraCursors
+			ApplyAtAllCursors(state, tc.action)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, tc.expectedExtraCursors, state.documentBuffer.ExtraCursors())
+		})
+	}
+}
+
+func TestExtraCursorsAdjustForEdits(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc def ghi")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.extraCursors = []uint64{4, 8}
+
+	// Inserting before a cursor shifts it forward.
+	mustInsertTextAtPosition(state, "xy", 0, true)
+	assert.Equal(t, []uint64{6, 10}, state.documentBuffer.ExtraCursors())
+
+	// Deleting text containing a cursor moves it to the start of the deletion.
+	deleteRunes(state, 5, 3, true)
+	assert.Equal(t, []uint64{5, 7}, state.documentBuffer.ExtraCursors())
+
+	// Leaving normal and insert mode removes the extra cursors.
+	SetInputMode(state, InputModeVisual)
+	assert.Empty(t, state.documentBuffer.ExtraCursors())
+}
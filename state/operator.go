@@ -0,0 +1,163 @@
+package state
+
+import (
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/text"
+)
+
+// Operator is an edit that applies to the text selected by a motion,
+// such as "d" (delete) in "dw" or "y" (yank) in "y}".
+type Operator int
+
+const (
+	OperatorDelete  = Operator(iota) // Delete the text.
+	OperatorChange                   // Delete the text, then enter insert mode.
+	OperatorYank                     // Copy the text to the clipboard.
+	OperatorIndent                   // Indent every line in the text.
+	OperatorOutdent                  // Outdent every line in the text.
+	OperatorFormat                   // Reflow every line in the text to the text width.
+)
+
+// Motion moves the cursor to select text for an operator, such as "w" in "dw".
+type Motion struct {
+	// Move moves the cursor from its current position to the target of the motion.
+	Move func(*EditorState)
+
+	// Linewise motions select every line from the cursor to the target, such as "j" or "G".
+	Linewise bool
+
+	// Inclusive motions select the character at the end of the range, such as "e" or "$".
+	// Otherwise, the range stops just before the last character.
+	Inclusive bool
+
+	// MustMove motions fail if they do not move the cursor, such as "f" with no match or "j" on the last line.
+	// A linewise motion must move the cursor to another line.
+	MustMove bool
+}
+
+// ApplyOperator applies an operator to the text between the cursor and the target of a motion.
+// If the motion fails, this does nothing.
+func ApplyOperator(state *EditorState, op Operator, motion Motion, clipboardPage clipboard.PageId) {
+	buffer := state.documentBuffer
+	startCursor := buffer.cursor
+	motion.Move(state)
+	targetPos := buffer.cursor.position
+	buffer.cursor = startCursor
+
+	if motionFailed(buffer.textTree, motion, startCursor.position, targetPos) {
+		return
+	}
+
+	if motion.Linewise {
+		applyOperatorToLines(state, op, startCursor.position, targetPos, clipboardPage)
+		return
+	}
+
+	startPos, endPos := startCursor.position, targetPos
+	if endPos < startPos {
+		startPos, endPos = endPos, startPos
+	}
+
+	linewise := op == OperatorIndent || op == OperatorOutdent || op == OperatorFormat
+	if !motion.Inclusive {
+		var adjustedToLinewise bool
+		endPos, adjustedToLinewise = adjustExclusiveRangeEnd(buffer.textTree, startPos, endPos)
+		linewise = linewise || adjustedToLinewise
+	}
+
+	if linewise {
+		buffer.cursor = cursorState{position: startPos}
+		applyOperatorToLines(state, op, startPos, endPos, clipboardPage)
+		return
+	}
+
+	if motion.Inclusive {
+		endPos = locate.NextCharInLine(buffer.textTree, 1, true, endPos)
+	}
+
+	rangeLoc := func(LocatorParams) (uint64, uint64) { return startPos, endPos }
+	switch op {
+	case OperatorDelete:
+		DeleteRange(state, rangeLoc, clipboardPage)
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			return locate.ClosestCharOnLine(params.TextTree, params.CursorPos)
+		})
+	case OperatorChange:
+		DeleteRange(state, rangeLoc, clipboardPage)
+		SetInputMode(state, InputModeInsert)
+	case OperatorYank:
+		CopyRange(state, clipboardPage, rangeLoc)
+		buffer.cursor = cursorState{position: startPos}
+	}
+}
+
+// motionFailed returns whether a motion from startPos to targetPos selects nothing for an operator.
+// An exclusive charwise motion that does not move selects nothing, but an inclusive motion
+// always selects at least the character under the cursor, so "c$" on the last character of a line changes it.
+func motionFailed(tree *text.Tree, motion Motion, startPos uint64, targetPos uint64) bool {
+	switch {
+	case motion.Linewise:
+		return motion.MustMove && tree.LineNumForPosition(targetPos) == tree.LineNumForPosition(startPos)
+	case motion.Inclusive:
+		return motion.MustMove && targetPos == startPos
+	default:
+		return targetPos == startPos
+	}
+}
+
+// adjustExclusiveRangeEnd implements vim's special cases for exclusive motions (see ":help exclusive").
+// If the range spans multiple lines and ends at the start of a line, the range instead ends
+// at the end of the previous line, so "d}" does not delete the newline before the next paragraph.
+// If the range also starts at or before the first non-whitespace character in its line,
+// the range becomes linewise, so "d}" from the start of a paragraph deletes every line in the paragraph.
+func adjustExclusiveRangeEnd(tree *text.Tree, startPos uint64, endPos uint64) (uint64, bool) {
+	endLineNum := tree.LineNumForPosition(endPos)
+	if endLineNum == tree.LineNumForPosition(startPos) || tree.LineStartPosition(endLineNum) != endPos {
+		return endPos, false
+	}
+
+	prevLineEndPos := locate.NextLineBoundary(tree, true, tree.LineStartPosition(endLineNum-1))
+	startLineStartPos := locate.StartOfLineAtPos(tree, startPos)
+	linewise := startPos <= locate.NextNonWhitespaceOrNewline(tree, startLineStartPos)
+	return prevLineEndPos, linewise
+}
+
+func applyOperatorToLines(state *EditorState, op Operator, startPos uint64, targetPos uint64, clipboardPage clipboard.PageId) {
+	buffer := state.documentBuffer
+	targetLineLoc := func(LocatorParams) uint64 { return targetPos }
+	switch op {
+	case OperatorDelete:
+		DeleteLines(state, targetLineLoc, false, false, clipboardPage)
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			lineStartPos := locate.StartOfLineAtPos(params.TextTree, params.CursorPos)
+			return locate.NextNonWhitespaceOrNewline(params.TextTree, lineStartPos)
+		})
+	case OperatorChange:
+		// Preserve the indentation of the first line, so the user can start typing at the same indent.
+		firstLineStartPos := locate.StartOfLineAtPos(buffer.textTree, startPos)
+		if targetPos < startPos {
+			firstLineStartPos = locate.StartOfLineAtPos(buffer.textTree, targetPos)
+		}
+		indentEndPos := locate.NextNonWhitespaceOrNewline(buffer.textTree, firstLineStartPos)
+		indent := copyText(buffer.textTree, firstLineStartPos, indentEndPos-firstLineStartPos)
+		DeleteLines(state, targetLineLoc, false, true, clipboardPage)
+		pos := buffer.cursor.position
+		mustInsertTextAtPosition(state, indent, pos, true)
+		buffer.cursor = cursorState{position: pos + uint64(utf8.RuneCountInString(indent))}
+		SetInputMode(state, InputModeInsert)
+	case OperatorYank:
+		CopyLines(state, clipboardPage, targetLineLoc)
+		if targetPos < startPos {
+			buffer.cursor = cursorState{position: targetPos}
+		}
+	case OperatorIndent:
+		IndentLines(state, targetLineLoc, 1)
+	case OperatorOutdent:
+		OutdentLines(state, targetLineLoc, 1)
+	case OperatorFormat:
+		FormatLines(state, targetLineLoc)
+	}
+}
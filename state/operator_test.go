@@ -0,0 +1,473 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/text"
+)
+
+func TestApplyOperator(t *testing.T) {
+	moveTo := func(pos uint64) func(*EditorState) {
+		return func(state *EditorState) {
+			MoveCursor(state, func(LocatorParams) uint64 { return pos })
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		op                Operator
+		motion            Motion
+		expectedCursorPos uint64
+		expectedText      string
+		expectedClipboard clipboard.PageContent
+		expectedInputMode InputMode
+	}{
+		{
+			name:              "delete charwise exclusive forward",
+			inputString:       "abcdef",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(4)},
+			expectedCursorPos: 1,
+			expectedText:      "aef",
+			expectedClipboard: clipboard.PageContent{Text: "bcd"},
+		},
+		{
+			name:              "delete charwise exclusive backward",
+			inputString:       "abcdef",
+			initialCursorPos:  4,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(1)},
+			expectedCursorPos: 1,
+			expectedText:      "aef",
+			expectedClipboard: clipboard.PageContent{Text: "bcd"},
+		},
+		{
+			name:              "delete charwise inclusive",
+			inputString:       "abcdef",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(4), Inclusive: true},
+			expectedCursorPos: 1,
+			expectedText:      "af",
+			expectedClipboard: clipboard.PageContent{Text: "bcde"},
+		},
+		{
+			name:              "delete charwise to end of line",
+			inputString:       "abc\ndef",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(2), Inclusive: true},
+			expectedCursorPos: 0,
+			expectedText:      "a\ndef",
+			expectedClipboard: clipboard.PageContent{Text: "bc"},
+		},
+		{
+			name:              "delete charwise motion does not move",
+			inputString:       "abcdef",
+			initialCursorPos:  2,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(2)},
+			expectedCursorPos: 2,
+			expectedText:      "abcdef",
+		},
+		{
+			name:              "delete charwise inclusive motion does not move",
+			inputString:       "abcdef",
+			initialCursorPos:  2,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(2), Inclusive: true},
+			expectedCursorPos: 2,
+			expectedText:      "abdef",
+			expectedClipboard: clipboard.PageContent{Text: "c"},
+		},
+		{
+			name:              "delete charwise inclusive motion fails",
+			inputString:       "abcdef",
+			initialCursorPos:  2,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(2), Inclusive: true, MustMove: true},
+			expectedCursorPos: 2,
+			expectedText:      "abcdef",
+		},
+		{
+			name:              "delete linewise",
+			inputString:       "ab\ncd\nef\ngh",
+			initialCursorPos:  4,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(6), Linewise: true},
+			expectedCursorPos: 3,
+			expectedText:      "ab\ngh",
+			expectedClipboard: clipboard.PageContent{Text: "cd\nef", Linewise: true},
+		},
+		{
+			name:              "delete linewise motion does not move",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  4,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(4), Linewise: true},
+			expectedCursorPos: 3,
+			expectedText:      "ab\nef",
+			expectedClipboard: clipboard.PageContent{Text: "cd", Linewise: true},
+		},
+		{
+			name:              "delete linewise motion fails",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  7,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(7), Linewise: true, MustMove: true},
+			expectedCursorPos: 7,
+			expectedText:      "ab\ncd\nef",
+		},
+		{
+			name:              "change linewise motion fails",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  7,
+			op:                OperatorChange,
+			motion:            Motion{Move: moveTo(7), Linewise: true, MustMove: true},
+			expectedCursorPos: 7,
+			expectedText:      "ab\ncd\nef",
+		},
+		{
+			name:              "change charwise",
+			inputString:       "abcdef",
+			initialCursorPos:  1,
+			op:                OperatorChange,
+			motion:            Motion{Move: moveTo(4)},
+			expectedCursorPos: 1,
+			expectedText:      "aef",
+			expectedClipboard: clipboard.PageContent{Text: "bcd"},
+			expectedInputMode: InputModeInsert,
+		},
+		{
+			name:              "change linewise",
+			inputString:       "ab\ncd\nef\ngh",
+			initialCursorPos:  7,
+			op:                OperatorChange,
+			motion:            Motion{Move: moveTo(3), Linewise: true},
+			expectedCursorPos: 3,
+			expectedText:      "ab\n\ngh",
+			expectedClipboard: clipboard.PageContent{Text: "cd\nef", Linewise: true},
+			expectedInputMode: InputModeInsert,
+		},
+		{
+			name:              "change linewise preserves indentation",
+			inputString:       "ab\n\t  cd\n  ef\ngh",
+			initialCursorPos:  12,
+			op:                OperatorChange,
+			motion:            Motion{Move: moveTo(5), Linewise: true},
+			expectedCursorPos: 6,
+			expectedText:      "ab\n\t  \ngh",
+			expectedClipboard: clipboard.PageContent{Text: "\t  cd\n  ef", Linewise: true},
+			expectedInputMode: InputModeInsert,
+		},
+		{
+			name:              "delete linewise moves cursor to first non-whitespace",
+			inputString:       "ab\ncd\n  ef",
+			initialCursorPos:  3,
+			op:                OperatorDelete,
+			motion:            Motion{Move: moveTo(4), Linewise: true},
+			expectedCursorPos: 5,
+			expectedText:      "ab\n  ef",
+			expectedClipboard: clipboard.PageContent{Text: "cd", Linewise: true},
+		},
+		{
+			name:              "yank charwise forward",
+			inputString:       "abcdef",
+			initialCursorPos:  1,
+			op:                OperatorYank,
+			motion:            Motion{Move: moveTo(4)},
+			expectedCursorPos: 1,
+			expectedText:      "abcdef",
+			expectedClipboard: clipboard.PageContent{Text: "bcd"},
+		},
+		{
+			name:              "yank charwise backward",
+			inputString:       "abcdef",
+			initialCursorPos:  4,
+			op:                OperatorYank,
+			motion:            Motion{Move: moveTo(1)},
+			expectedCursorPos: 1,
+			expectedText:      "abcdef",
+			expectedClipboard: clipboard.PageContent{Text: "bcd"},
+		},
+		{
+			name:              "yank linewise forward",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  1,
+			op:                OperatorYank,
+			motion:            Motion{Move: moveTo(4), Linewise: true},
+			expectedCursorPos: 1,
+			expectedText:      "ab\ncd\nef",
+			expectedClipboard: clipboard.PageContent{Text: "ab\ncd", Linewise: true},
+		},
+		{
+			name:              "yank linewise backward",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  7,
+			op:                OperatorYank,
+			motion:            Motion{Move: moveTo(4), Linewise: true},
+			expectedCursorPos: 4,
+			expectedText:      "ab\ncd\nef",
+			expectedClipboard: clipboard.PageContent{Text: "cd\nef", Linewise: true},
+		},
+		{
+			name:              "indent charwise motion",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  0,
+			op:                OperatorIndent,
+			motion:            Motion{Move: moveTo(4)},
+			expectedCursorPos: 1,
+			expectedText:      "\tab\n\tcd\nef",
+		},
+		{
+			name:              "outdent linewise motion",
+			inputString:       "\tab\n\tcd\n\tef",
+			initialCursorPos:  9,
+			op:                OperatorOutdent,
+			motion:            Motion{Move: moveTo(5), Linewise: true},
+			expectedCursorPos: 4,
+			expectedText:      "\tab\ncd\nef",
+		},
+		{
+			name:              "format charwise motion",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  0,
+			op:                OperatorFormat,
+			motion:            Motion{Move: moveTo(4)},
+			expectedCursorPos: 0,
+			expectedText:      "ab cd\nef",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor.position = tc.initialCursorPos
+			ApplyOperator(state, tc.op, tc.motion, clipboard.PageDefault)
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedClipboard, state.clipboard.Get(clipboard.PageDefault))
+			assert.Equal(t, tc.expectedInputMode, state.InputMode())
+		})
+	}
+}
+
+func TestApplyOperatorMotionInclusivity(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inputString       string
+		initialCursorPos  uint64
+		op                Operator
+		targetPos         uint64
+		inclusive         bool
+		expectedCursorPos uint64
+		expectedText      string
+		expectedClipboard clipboard.PageContent
+		expectedInputMode InputMode
+	}{
+		{
+			name:              "exclusive within line",
+			inputString:       "ab cd\nef",
+			initialCursorPos:  0,
+			op:                OperatorDelete,
+			targetPos:         3,
+			expectedCursorPos: 0,
+			expectedText:      "cd\nef",
+			expectedClipboard: clipboard.PageContent{Text: "ab "},
+		},
+		{
+			name:              "inclusive within line",
+			inputString:       "ab cd\nef",
+			initialCursorPos:  0,
+			op:                OperatorDelete,
+			targetPos:         1,
+			inclusive:         true,
+			expectedCursorPos: 0,
+			expectedText:      " cd\nef",
+			expectedClipboard: clipboard.PageContent{Text: "ab"},
+		},
+		{
+			name:              "inclusive at end of line",
+			inputString:       "ab cd\nef",
+			initialCursorPos:  3,
+			op:                OperatorDelete,
+			targetPos:         4,
+			inclusive:         true,
+			expectedCursorPos: 2,
+			expectedText:      "ab \nef",
+			expectedClipboard: clipboard.PageContent{Text: "cd"},
+		},
+		{
+			name:              "inclusive ending at start of line is not adjusted",
+			inputString:       "ab\ncd",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			targetPos:         3,
+			inclusive:         true,
+			expectedCursorPos: 1,
+			expectedText:      "ad",
+			expectedClipboard: clipboard.PageContent{Text: "b\nc"},
+		},
+		{
+			name:              "exclusive ending at end of document is not adjusted",
+			inputString:       "ab\ncd",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			targetPos:         5,
+			expectedCursorPos: 0,
+			expectedText:      "a",
+			expectedClipboard: clipboard.PageContent{Text: "b\ncd"},
+		},
+		{
+			name:              "exclusive ending at start of line, from middle of line",
+			inputString:       "ab cd\nef gh\n\nij",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			targetPos:         6,
+			expectedCursorPos: 0,
+			expectedText:      "a\nef gh\n\nij",
+			expectedClipboard: clipboard.PageContent{Text: "b cd"},
+		},
+		{
+			name:              "exclusive ending at start of line, after empty line",
+			inputString:       "ab\n\ncd",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			targetPos:         4,
+			expectedCursorPos: 0,
+			expectedText:      "a\ncd",
+			expectedClipboard: clipboard.PageContent{Text: "b\n"},
+		},
+		{
+			name:              "exclusive ending at start of line, after first non-whitespace",
+			inputString:       "  ab\ncd\n\nef",
+			initialCursorPos:  3,
+			op:                OperatorDelete,
+			targetPos:         8,
+			expectedCursorPos: 2,
+			expectedText:      "  a\n\nef",
+			expectedClipboard: clipboard.PageContent{Text: "b\ncd"},
+		},
+		{
+			name:              "exclusive ending at start of line, from start of line becomes linewise",
+			inputString:       "ab cd\nef gh\n\nij",
+			initialCursorPos:  0,
+			op:                OperatorDelete,
+			targetPos:         12,
+			expectedCursorPos: 0,
+			expectedText:      "\nij",
+			expectedClipboard: clipboard.PageContent{Text: "ab cd\nef gh", Linewise: true},
+		},
+		{
+			name:              "exclusive ending at start of line, from indentation becomes linewise",
+			inputString:       "  ab\ncd\n\nef",
+			initialCursorPos:  1,
+			op:                OperatorDelete,
+			targetPos:         8,
+			expectedCursorPos: 0,
+			expectedText:      "\nef",
+			expectedClipboard: clipboard.PageContent{Text: "  ab\ncd", Linewise: true},
+		},
+		{
+			name:              "exclusive ending at start of line, from first non-whitespace becomes linewise",
+			inputString:       "  ab\ncd\n\nef",
+			initialCursorPos:  2,
+			op:                OperatorDelete,
+			targetPos:         8,
+			expectedCursorPos: 0,
+			expectedText:      "\nef",
+			expectedClipboard: clipboard.PageContent{Text: "  ab\ncd", Linewise: true},
+		},
+		{
+			name:              "exclusive backward ending at start of line",
+			inputString:       "ab cd\nef",
+			initialCursorPos:  6,
+			op:                OperatorDelete,
+			targetPos:         3,
+			expectedCursorPos: 2,
+			expectedText:      "ab \nef",
+			expectedClipboard: clipboard.PageContent{Text: "cd"},
+		},
+		{
+			name:              "exclusive backward ending at start of line, from start of line becomes linewise",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  6,
+			op:                OperatorDelete,
+			targetPos:         0,
+			expectedCursorPos: 0,
+			expectedText:      "ef",
+			expectedClipboard: clipboard.PageContent{Text: "ab\ncd", Linewise: true},
+		},
+		{
+			name:              "yank exclusive ending at start of line",
+			inputString:       "ab\ncd\n\nef",
+			initialCursorPos:  1,
+			op:                OperatorYank,
+			targetPos:         6,
+			expectedCursorPos: 1,
+			expectedText:      "ab\ncd\n\nef",
+			expectedClipboard: clipboard.PageContent{Text: "b\ncd"},
+		},
+		{
+			name:              "yank exclusive ending at start of line becomes linewise",
+			inputString:       "ab\ncd\n\nef",
+			initialCursorPos:  0,
+			op:                OperatorYank,
+			targetPos:         6,
+			expectedCursorPos: 0,
+			expectedText:      "ab\ncd\n\nef",
+			expectedClipboard: clipboard.PageContent{Text: "ab\ncd", Linewise: true},
+		},
+		{
+			name:              "change exclusive ending at start of line becomes linewise",
+			inputString:       "  ab\ncd\n\nef",
+			initialCursorPos:  2,
+			op:                OperatorChange,
+			targetPos:         8,
+			expectedCursorPos: 2,
+			expectedText:      "  \n\nef",
+			expectedClipboard: clipboard.PageContent{Text: "  ab\ncd", Linewise: true},
+			expectedInputMode: InputModeInsert,
+		},
+		{
+			name:              "indent exclusive ending at start of line excludes that line",
+			inputString:       "ab\ncd\nef",
+			initialCursorPos:  1,
+			op:                OperatorIndent,
+			targetPos:         6,
+			expectedCursorPos: 1,
+			expectedText:      "\tab\n\tcd\nef",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor.position = tc.initialCursorPos
+			motion := Motion{
+				Move: func(state *EditorState) {
+					state.documentBuffer.cursor = cursorState{position: tc.targetPos}
+				},
+				Inclusive: tc.inclusive,
+			}
+			ApplyOperator(state, tc.op, motion, clipboard.PageDefault)
+			assert.Equal(t, tc.expectedCursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedClipboard, state.clipboard.Get(clipboard.PageDefault))
+			assert.Equal(t, tc.expectedInputMode, state.InputMode())
+		})
+	}
+}
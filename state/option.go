@@ -0,0 +1,483 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aretext/aretext/config"
+	"github.com/aretext/aretext/syntax"
+)
+
+// optionKind is the type of value an option accepts.
+type optionKind int
+
+const (
+	optionKindBool = optionKind(iota)
+	optionKindInt
+	optionKindString
+)
+
+// option is a configuration option that can be changed while editing.
+// Options have the same names and values as the configuration keys.
+type option struct {
+	kind optionKind
+
+	// global options belong to the editor rather than the document, so they cannot be set locally.
+	global bool
+
+	// apply sets the option in the editor state from a validated config.
+	apply func(state *EditorState, cfg config.Config)
+
+	// value returns the current value of the option, using the same type as the config.
+	value func(state *EditorState) any
+}
+
+var options = map[string]option{
+	"syntaxLanguage": {
+		kind: optionKindString,
+		apply: func(state *EditorState, cfg config.Config) {
+			setSyntaxAndRetokenize(state.documentBuffer, syntax.Language(cfg.SyntaxLanguage))
+		},
+		value: func(state *EditorState) any {
+			return string(state.documentBuffer.syntaxLanguage)
+		},
+	},
+	"tabSize": {
+		kind: optionKindInt,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.tabSize = uint64(cfg.TabSize) // safe b/c we validated the config.
+		},
+		value: func(state *EditorState) any {
+			return int(state.documentBuffer.tabSize)
+		},
+	},
+	"tabExpand": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.tabExpand = cfg.TabExpand
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.tabExpand
+		},
+	},
+	"showTabs": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.showTabs = cfg.ShowTabs
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.showTabs
+		},
+	},
+	"showSpaces": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.showSpaces = cfg.ShowSpaces
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.showSpaces
+		},
+	},
+	"autoIndent": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.autoIndent = cfg.AutoIndent
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.autoIndent
+		},
+	},
+	"continueComments": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.continueComments = cfg.ContinueComments
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.continueComments
+		},
+	},
+	"autoCloseTags": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.autoCloseTags = cfg.AutoCloseTags
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.autoCloseTags
+		},
+	},
+	"showLineNumbers": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.showLineNum = cfg.ShowLineNumbers
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.showLineNum
+		},
+	},
+	"relativeLineNumbers": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.relativeLineNum = cfg.RelativeLineNumbers
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.relativeLineNum
+		},
+	},
+	"smartCase": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.smartCase = cfg.SmartCase
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.smartCase
+		},
+	},
+	"wrapScan": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.wrapScan = cfg.WrapScan
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.wrapScan
+		},
+	},
+	"hlSearch": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.hlSearch = cfg.HlSearch
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.hlSearch
+		},
+	},
+	"lineWrap": {
+		kind: optionKindString,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.lineWrapAllowCharBreaks = bool(cfg.LineWrap == config.LineWrapCharacter)
+		},
+		value: func(state *EditorState) any {
+			if state.documentBuffer.lineWrapAllowCharBreaks {
+				return config.LineWrapCharacter
+			}
+			return config.LineWrapWord
+		},
+	},
+	"virtualEdit": {
+		kind: optionKindString,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.virtualEdit = bool(cfg.VirtualEdit == config.VirtualEditAll)
+		},
+		value: func(state *EditorState) any {
+			if state.documentBuffer.virtualEdit {
+				return config.VirtualEditAll
+			}
+			return config.VirtualEditNone
+		},
+	},
+	"textWidth": {
+		kind: optionKindInt,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.textWidth = uint64(cfg.TextWidth) // safe b/c we validated the config.
+		},
+		value: func(state *EditorState) any {
+			return int(state.documentBuffer.textWidth)
+		},
+	},
+	"highlightYank": {
+		kind: optionKindBool,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.highlightYank = cfg.HighlightYank
+			clearYankHighlight(state.documentBuffer)
+		},
+		value: func(state *EditorState) any {
+			return state.documentBuffer.highlightYank
+		},
+	},
+	"highlightYankDuration": {
+		kind: optionKindInt,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.documentBuffer.highlightYankDuration = time.Duration(cfg.HighlightYankDuration) * time.Millisecond
+		},
+		value: func(state *EditorState) any {
+			return int(state.documentBuffer.highlightYankDuration / time.Millisecond)
+		},
+	},
+	"insertArrowKeys": {
+		kind:   optionKindString,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.insertArrowKeys = cfg.InsertArrowKeys
+		},
+		value: func(state *EditorState) any {
+			return state.insertArrowKeys
+		},
+	},
+	"statusMsgTimeout": {
+		kind:   optionKindInt,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.statusMsgTimeout = time.Duration(cfg.StatusMsgTimeout) * time.Second
+		},
+		value: func(state *EditorState) any {
+			return int(state.statusMsgTimeout / time.Second)
+		},
+	},
+	"cursorShapeNormal": {
+		kind:   optionKindString,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.cursorShapeNormal = cfg.CursorShapeNormal
+		},
+		value: func(state *EditorState) any {
+			return state.cursorShapeNormal
+		},
+	},
+	"cursorShapeInsert": {
+		kind:   optionKindString,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.cursorShapeInsert = cfg.CursorShapeInsert
+		},
+		value: func(state *EditorState) any {
+			return state.cursorShapeInsert
+		},
+	},
+	"cursorShapeVisual": {
+		kind:   optionKindString,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.cursorShapeVisual = cfg.CursorShapeVisual
+		},
+		value: func(state *EditorState) any {
+			return state.cursorShapeVisual
+		},
+	},
+	"showCmd": {
+		kind:   optionKindBool,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.showCmd = cfg.ShowCmd
+		},
+		value: func(state *EditorState) any {
+			return state.showCmd
+		},
+	},
+	"autoWrite": {
+		kind:   optionKindBool,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.autoWrite = cfg.AutoWrite
+		},
+		value: func(state *EditorState) any {
+			return state.autoWrite
+		},
+	},
+	"writeBackup": {
+		kind:   optionKindBool,
+		global: true,
+		apply: func(state *EditorState, cfg config.Config) {
+			state.writeBackup = cfg.WriteBackup
+		},
+		value: func(state *EditorState) any {
+			return state.writeBackup
+		},
+	},
+}
+
+// optionAliases maps vim option names to the equivalent options.
+// Option names are also matched ignoring case, so vim names like "tabsize" and "hlsearch" need no alias.
+// Searches ignore case only when smartCase is enabled, so vim's "ignorecase" refers to smartCase.
+// Vim's "wrap" has no equivalent, because aretext always soft-wraps long lines.
+var optionAliases = map[string]string{
+	"number":         "showLineNumbers",
+	"nu":             "showLineNumbers",
+	"relativenumber": "relativeLineNumbers",
+	"rnu":            "relativeLineNumbers",
+	"tabstop":        "tabSize",
+	"ts":             "tabSize",
+	"expandtab":      "tabExpand",
+	"et":             "tabExpand",
+	"ai":             "autoIndent",
+	"hls":            "hlSearch",
+	"scs":            "smartCase",
+	"ignorecase":     "smartCase",
+	"ic":             "smartCase",
+	"ws":             "wrapScan",
+	"tw":             "textWidth",
+	"filetype":       "syntaxLanguage",
+	"ft":             "syntaxLanguage",
+	"ve":             "virtualEdit",
+	"sc":             "showCmd",
+	"aw":             "autoWrite",
+}
+
+// lookupOption returns the name of the option with the given name or alias, ignoring case.
+func lookupOption(name string) (string, bool) {
+	if _, ok := options[name]; ok {
+		return name, true
+	}
+
+	if canonicalName, ok := optionAliases[strings.ToLower(name)]; ok {
+		return canonicalName, true
+	}
+
+	for canonicalName := range options {
+		if strings.EqualFold(name, canonicalName) {
+			return canonicalName, true
+		}
+	}
+
+	return "", false
+}
+
+// SetOptions changes options from arguments like "tabSize=2 tabExpand noshowTabs", similar to vim's ":set".
+// A boolean option without a value is set to true, a "no" prefix sets it to false, and a "!" suffix toggles it.
+// An option with a "?" suffix shows its current value in the status bar.
+// Options may be named by their config key, ignoring case, or by the equivalent vim option.
+//
+// If local is false, the options also apply to documents loaded later, overriding the configuration.
+// If local is true, the options apply only to the current document, and loading another
+// document resets them to the configured values. Global options cannot be set locally.
+//
+// If any argument is invalid, this reports an error and does not change any options.
+func SetOptions(state *EditorState, args string, local bool) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  `Expected an option, such as "tabSize=4"`,
+		})
+		return
+	}
+
+	values := make(map[string]any, len(fields))
+	var queryResults []string
+	for _, field := range fields {
+		arg, err := parseOptionArg(state, field)
+		if err == nil && local && !arg.query && options[arg.name].global {
+			err = fmt.Errorf("Option %q is global and cannot be set locally", arg.name)
+		}
+		if err != nil {
+			SetStatusMsg(state, StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  err.Error(),
+			})
+			return
+		}
+
+		if arg.query {
+			queryResults = append(queryResults, formatOption(state, arg.name))
+			continue
+		}
+		values[arg.name] = arg.value
+	}
+
+	// Options not in the map get default values, which are always valid.
+	cfg := config.ConfigFromUntypedMap(values)
+	if err := cfg.Validate(); err != nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Invalid option: %s", err),
+		})
+		return
+	}
+
+	for name, value := range values {
+		options[name].apply(state, cfg)
+		if !local {
+			if state.globalOptions == nil {
+				state.globalOptions = make(map[string]any)
+			}
+			state.globalOptions[name] = value
+		}
+	}
+
+	if len(queryResults) > 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleSuccess,
+			Text:  strings.Join(queryResults, "  "),
+		})
+	}
+}
+
+// optionArg is a parsed argument to SetOptions.
+type optionArg struct {
+	name  string // Name of the option, not an alias.
+	value any    // Value to set, using the same type as the config. Nil if query is true.
+	query bool   // If true, show the current value instead of setting it.
+}
+
+// parseOptionArg parses an argument like "tabSize=2", "tabExpand", "notabExpand", "tabExpand!", or "tabSize?".
+func parseOptionArg(state *EditorState, arg string) (optionArg, error) {
+	if name := strings.TrimSuffix(arg, "?"); name != arg {
+		canonicalName, ok := lookupOption(name)
+		if !ok {
+			return optionArg{}, fmt.Errorf("Unknown option %q", name)
+		}
+		return optionArg{name: canonicalName, query: true}, nil
+	}
+
+	if name := strings.TrimSuffix(arg, "!"); name != arg {
+		canonicalName, ok := lookupOption(name)
+		if !ok {
+			return optionArg{}, fmt.Errorf("Unknown option %q", name)
+		}
+		opt := options[canonicalName]
+		if opt.kind != optionKindBool {
+			return optionArg{}, fmt.Errorf("Option %q cannot be toggled because it is not true or false", canonicalName)
+		}
+		return optionArg{name: canonicalName, value: !opt.value(state).(bool)}, nil
+	}
+
+	name, valueStr, hasValue := strings.Cut(arg, "=")
+	canonicalName, ok := lookupOption(name)
+	if !ok && !hasValue && strings.HasPrefix(name, "no") {
+		if canonicalName, ok := lookupOption(strings.TrimPrefix(name, "no")); ok && options[canonicalName].kind == optionKindBool {
+			return optionArg{name: canonicalName, value: false}, nil
+		}
+	}
+
+	if !ok {
+		return optionArg{}, fmt.Errorf("Unknown option %q", name)
+	}
+
+	opt := options[canonicalName]
+	if !hasValue {
+		if opt.kind != optionKindBool {
+			return optionArg{}, fmt.Errorf("Option %q requires a value", canonicalName)
+		}
+		return optionArg{name: canonicalName, value: true}, nil
+	}
+
+	switch opt.kind {
+	case optionKindBool:
+		b, err := strconv.ParseBool(valueStr)
+		if err != nil {
+			return optionArg{}, fmt.Errorf("Option %q must be true or false", canonicalName)
+		}
+		return optionArg{name: canonicalName, value: b}, nil
+	case optionKindInt:
+		n, err := strconv.Atoi(valueStr)
+		if err != nil {
+			return optionArg{}, fmt.Errorf("Option %q must be an integer", canonicalName)
+		}
+		return optionArg{name: canonicalName, value: n}, nil
+	default:
+		return optionArg{name: canonicalName, value: valueStr}, nil
+	}
+}
+
+// formatOption describes the current value of an option, like "tabSize=4", "showTabs", or "noshowTabs".
+func formatOption(state *EditorState, name string) string {
+	switch value := options[name].value(state).(type) {
+	case bool:
+		if value {
+			return name
+		}
+		return "no" + name
+	default:
+		return fmt.Sprintf("%s=%v", name, value)
+	}
+}
@@ -0,0 +1,273 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/config"
+)
+
+func TestSetOptions(t *testing.T) {
+	testCases := []struct {
+		name             string
+		args             string
+		local            bool
+		expectedTabSize  uint64
+		expectTabExpand  bool
+		expectShowTabs   bool
+		expectedErrorMsg string
+	}{
+		{
+			name:            "set int option",
+			args:            "tabSize=2",
+			expectedTabSize: 2,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "set bool option",
+			args:            "tabExpand",
+			expectedTabSize: 4,
+			expectTabExpand: true,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "set bool option with value",
+			args:            "tabExpand=true",
+			expectedTabSize: 4,
+			expectTabExpand: true,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "unset bool option with prefix",
+			args:            "noshowTabs",
+			expectedTabSize: 4,
+			expectShowTabs:  false,
+		},
+		{
+			name:            "set multiple options",
+			args:            "tabSize=8  tabExpand showTabs=false",
+			expectedTabSize: 8,
+			expectTabExpand: true,
+			expectShowTabs:  false,
+		},
+		{
+			name:            "set options with vim names",
+			args:            "ts=2 expandtab",
+			expectedTabSize: 2,
+			expectTabExpand: true,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "set options ignoring case",
+			args:            "tabsize=6 TABEXPAND",
+			expectedTabSize: 6,
+			expectTabExpand: true,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "unset bool option with vim name",
+			args:            "expandtab noexpandtab",
+			expectedTabSize: 4,
+			expectShowTabs:  true,
+		},
+		{
+			name:            "toggle bool option",
+			args:            "showTabs! tabExpand!",
+			expectedTabSize: 4,
+			expectTabExpand: true,
+			expectShowTabs:  false,
+		},
+		{
+			name:            "set local option",
+			args:            "tabSize=3",
+			local:           true,
+			expectedTabSize: 3,
+			expectShowTabs:  true,
+		},
+		{
+			name:             "missing option",
+			args:             "  ",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Expected an option, such as "tabSize=4"`,
+		},
+		{
+			name:             "unknown option",
+			args:             "tabSize=2 foo=1",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Unknown option "foo"`,
+		},
+		{
+			name:             "no prefix for non-bool option",
+			args:             "notabSize",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Unknown option "notabSize"`,
+		},
+		{
+			name:             "int option without value",
+			args:             "tabSize",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Option "tabSize" requires a value`,
+		},
+		{
+			name:             "int option not an integer",
+			args:             "tabSize=x",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Option "tabSize" must be an integer`,
+		},
+		{
+			name:             "bool option not a bool",
+			args:             "tabExpand=x",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Option "tabExpand" must be true or false`,
+		},
+		{
+			name:             "invalid value",
+			args:             "tabExpand tabSize=0",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: "Invalid option: TabSize must be greater than zero",
+		},
+		{
+			name:             "toggle non-bool option",
+			args:             "tabSize!",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Option "tabSize" cannot be toggled because it is not true or false`,
+		},
+		{
+			name:             "query unknown option",
+			args:             "foo?",
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Unknown option "foo"`,
+		},
+		{
+			name:             "global option set locally",
+			args:             "statusMsgTimeout=5",
+			local:            true,
+			expectedTabSize:  4,
+			expectShowTabs:   true,
+			expectedErrorMsg: `Option "statusMsgTimeout" is global and cannot be set locally`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.showTabs = true
+			SetOptions(state, tc.args, tc.local)
+			assert.Equal(t, tc.expectedTabSize, state.documentBuffer.tabSize)
+			assert.Equal(t, tc.expectTabExpand, state.documentBuffer.tabExpand)
+			assert.Equal(t, tc.expectShowTabs, state.documentBuffer.showTabs)
+			if tc.expectedErrorMsg != "" {
+				assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: tc.expectedErrorMsg}, state.StatusMsg())
+			} else {
+				assert.Equal(t, StatusMsg{}, state.StatusMsg())
+			}
+		})
+	}
+}
+
+func TestSetOptionsQuery(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	SetOptions(state, "number lineWrap=word", false)
+	SetOptions(state, "nu? ts? expandtab? lineWrap? statusMsgTimeout?", false)
+	assert.Equal(t, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  "showLineNumbers  tabSize=4  notabExpand  lineWrap=word  statusMsgTimeout=0",
+	}, state.StatusMsg())
+}
+
+func TestSetOptionsRelativeLineNumbers(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	SetOptions(state, "number rnu", false)
+	assert.True(t, state.documentBuffer.showLineNum)
+	assert.True(t, state.documentBuffer.relativeLineNum)
+
+	SetOptions(state, "norelativenumber", false)
+	assert.False(t, state.documentBuffer.relativeLineNum)
+	assert.Equal(t, StatusMsg{}, state.StatusMsg())
+}
+
+func TestSetOptionsIgnoreCase(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	SetOptions(state, "noignorecase", false)
+	assert.False(t, state.documentBuffer.smartCase)
+
+	SetOptions(state, "ignorecase", false)
+	assert.True(t, state.documentBuffer.smartCase)
+
+	SetOptions(state, "ic!", false)
+	assert.False(t, state.documentBuffer.smartCase)
+	assert.Equal(t, StatusMsg{}, state.StatusMsg())
+
+	SetOptions(state, "ic?", false)
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "nosmartCase"}, state.StatusMsg())
+}
+
+func TestSetOptionsWrap(t *testing.T) {
+	// Lines always wrap, so vim's "wrap" is not an option.
+	state := NewEditorState(100, 100, nil, nil)
+	SetOptions(state, "wrap", false)
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: `Unknown option "wrap"`}, state.StatusMsg())
+}
+
+func TestSetOptionsLocalAndGlobal(t *testing.T) {
+	configRuleSet := config.RuleSet{
+		{
+			Name:    "go",
+			Pattern: "**/*.go",
+			Config:  map[string]any{"tabSize": 8},
+		},
+		{
+			Name:    "yaml",
+			Pattern: "**/*.yaml",
+			Config:  map[string]any{"tabSize": 2, "tabExpand": true},
+		},
+	}
+
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "test.go")
+	yamlPath := filepath.Join(dir, "test.yaml")
+	require.NoError(t, os.WriteFile(goPath, []byte("package main"), 0644))
+	require.NoError(t, os.WriteFile(yamlPath, []byte("a: 1"), 0644))
+
+	state := NewEditorState(100, 100, configRuleSet, nil)
+	defer state.fileWatcher.Stop()
+
+	// A local option applies only to the current document.
+	LoadDocument(state, goPath, true, startOfDocLocator)
+	SetOptions(state, "tabSize=3", true)
+	assert.Equal(t, uint64(3), state.documentBuffer.tabSize)
+	LoadDocument(state, yamlPath, true, startOfDocLocator)
+	assert.Equal(t, uint64(2), state.documentBuffer.tabSize)
+	LoadDocument(state, goPath, true, startOfDocLocator)
+	assert.Equal(t, uint64(8), state.documentBuffer.tabSize)
+
+	// A global option applies to the current document and documents loaded later,
+	// while other options keep their configured values.
+	SetOptions(state, "showTabs statusMsgTimeout=5", false)
+	assert.True(t, state.documentBuffer.showTabs)
+	LoadDocument(state, yamlPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.showTabs)
+	assert.Equal(t, 5*time.Second, state.statusMsgTimeout)
+	assert.Equal(t, uint64(2), state.documentBuffer.tabSize)
+	assert.True(t, state.documentBuffer.tabExpand)
+
+	// A local option overrides the global option only for the current document.
+	SetOptions(state, "noshowTabs", true)
+	assert.False(t, state.documentBuffer.showTabs)
+	LoadDocument(state, goPath, true, startOfDocLocator)
+	assert.True(t, state.documentBuffer.showTabs)
+}
@@ -0,0 +1,327 @@
+package state
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/syntax/parser"
+)
+
+const (
+	markdownHeadingRole = parser.TokenRoleCustom1 // Role the markdown parser assigns to headings.
+	jsonKeyRole         = parser.TokenRoleCustom1 // Role the JSON parser assigns to object keys.
+	yamlKeyRole         = parser.TokenRoleCustom1 // Role the YAML parser assigns to mapping keys.
+)
+
+// outlineItem is a symbol in the document, such as a markdown heading or a Go function.
+type outlineItem struct {
+	level    int    // Nesting level, starting from 1. Only markdown headings have levels greater than 1.
+	name     string // Name of the symbol to display in the outline.
+	startPos uint64 // Position of the start of the line with the symbol.
+}
+
+// outlineFuncForLanguage maps each language with an outline to a function that extracts symbols from the syntax tokens.
+// Languages without an entry have no symbols.
+var outlineFuncForLanguage = map[syntax.Language]func(*BufferState, []parser.Token) []outlineItem{
+	syntax.LanguageMarkdown: markdownOutline,
+	syntax.LanguageGo:       golangOutline,
+	syntax.LanguageJson:     jsonOutline,
+	syntax.LanguageYaml:     yamlOutline,
+}
+
+// LanguageHasOutline returns whether documents in a language can have symbols in the outline.
+func LanguageHasOutline(language syntax.Language) bool {
+	_, ok := outlineFuncForLanguage[language]
+	return ok
+}
+
+// documentOutline returns the symbols in the document, in order.
+// The symbols come from the syntax tokens, which the parser updates incrementally as the document changes.
+func documentOutline(buffer *BufferState) []outlineItem {
+	outlineFunc, ok := outlineFuncForLanguage[buffer.syntaxLanguage]
+	if !ok {
+		return nil
+	}
+	tokens := buffer.SyntaxTokensIntersectingRange(0, buffer.textTree.NumChars())
+	return outlineFunc(buffer, tokens)
+}
+
+// tokenText returns the text of a token.
+func tokenText(buffer *BufferState, token parser.Token) string {
+	return copyText(buffer.textTree, token.StartPos, token.EndPos-token.StartPos)
+}
+
+// isAtLineStart returns whether a token starts at the beginning of a line, which is where top-level symbols start.
+func isAtLineStart(buffer *BufferState, token parser.Token) bool {
+	return locate.StartOfLineAtPos(buffer.textTree, token.StartPos) == token.StartPos
+}
+
+// markdownOutline returns the headings in a markdown document, with levels from 1 to 6.
+func markdownOutline(buffer *BufferState, tokens []parser.Token) []outlineItem {
+	var items []outlineItem
+	for _, token := range tokens {
+		if token.Role != markdownHeadingRole {
+			continue
+		}
+
+		// Tokens from a language embedded in a fenced code block may use the same role,
+		// so check that the token text looks like a heading.
+		level, title := parseMarkdownHeading(tokenText(buffer, token))
+		if level == 0 {
+			continue
+		}
+
+		items = append(items, outlineItem{
+			level:    level,
+			name:     title,
+			startPos: token.StartPos,
+		})
+	}
+	return items
+}
+
+// parseMarkdownHeading returns the level and title of an ATX heading ("## Title")
+// or setext heading ("Title" underlined with "=" or "-").
+// If the text is not a heading, the level is zero.
+func parseMarkdownHeading(s string) (int, string) {
+	s = strings.TrimRight(s, "\r\n")
+	trimmed := strings.TrimLeft(s, " \t")
+
+	if strings.HasPrefix(trimmed, "#") {
+		level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		if level > 6 {
+			return 0, ""
+		}
+		title := strings.TrimSpace(trimmed[level:])
+		title = strings.TrimSpace(strings.TrimRight(title, "#"))
+		return level, title
+	}
+
+	lines := strings.Split(s, "\n")
+	if len(lines) < 2 {
+		return 0, ""
+	}
+
+	underline := strings.TrimSpace(lines[len(lines)-1])
+	var level int
+	switch {
+	case underline != "" && strings.Trim(underline, "=") == "":
+		level = 1
+	case underline != "" && strings.Trim(underline, "-") == "":
+		level = 2
+	default:
+		return 0, ""
+	}
+
+	titleLines := make([]string, 0, len(lines)-1)
+	for _, line := range lines[:len(lines)-1] {
+		titleLines = append(titleLines, strings.TrimSpace(line))
+	}
+	return level, strings.Join(titleLines, " ")
+}
+
+// golangOutline returns the top-level functions, methods, and types in a Go document.
+// Declarations are top-level if the "func" or "type" keyword starts a line, as in gofmt'd code.
+func golangOutline(buffer *BufferState, tokens []parser.Token) []outlineItem {
+	var items []outlineItem
+	for _, token := range tokens {
+		if token.Role != parser.TokenRoleKeyword || !isAtLineStart(buffer, token) {
+			continue
+		}
+
+		keyword := tokenText(buffer, token)
+		if keyword != "func" && keyword != "type" {
+			continue
+		}
+
+		lineEndPos := locate.NextLineBoundary(buffer.textTree, true, token.EndPos)
+		decl := copyText(buffer.textTree, token.EndPos, lineEndPos-token.EndPos)
+
+		var name string
+		if keyword == "func" {
+			name = golangFuncName(decl)
+		} else {
+			name = golangIdentifierPrefix(strings.TrimSpace(decl))
+		}
+
+		if name != "" {
+			items = append(items, outlineItem{level: 1, name: name, startPos: token.StartPos})
+		}
+	}
+	return items
+}
+
+// golangFuncName returns the name of a function from the declaration after the "func" keyword.
+// Methods are named after the receiver type, such as "(*T).Name" or "T.Name".
+func golangFuncName(decl string) string {
+	decl = strings.TrimSpace(decl)
+	if !strings.HasPrefix(decl, "(") {
+		return golangIdentifierPrefix(decl)
+	}
+
+	receiverEnd := strings.IndexByte(decl, ')')
+	if receiverEnd < 0 {
+		return ""
+	}
+
+	name := golangIdentifierPrefix(strings.TrimSpace(decl[receiverEnd+1:]))
+	if name == "" {
+		return ""
+	}
+
+	// The receiver is either "T", "*T", "r T", or "r *T", possibly with type parameters like "T[K]".
+	receiverFields := strings.Fields(decl[1:receiverEnd])
+	if len(receiverFields) == 0 {
+		return ""
+	}
+	receiverType := receiverFields[len(receiverFields)-1]
+	if i := strings.IndexByte(receiverType, '['); i >= 0 {
+		receiverType = receiverType[:i]
+	}
+
+	if strings.HasPrefix(receiverType, "*") {
+		return "(" + receiverType + ")." + name
+	}
+	return receiverType + "." + name
+}
+
+// golangIdentifierPrefix returns the identifier at the start of s.
+func golangIdentifierPrefix(s string) string {
+	for i, r := range s {
+		if !(r == '_' || (r >= '0' && r <= '9' && i > 0) || unicode.IsLetter(r)) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// jsonOutline returns the keys of the top-level object in a JSON document.
+func jsonOutline(buffer *BufferState, tokens []parser.Token) []outlineItem {
+	var items []outlineItem
+	var depth int
+	var prevTokenEndPos uint64
+	for _, token := range tokens {
+		// Braces and brackets are not tokens, so count them between tokens to find the nesting depth.
+		// Braces in strings are part of a token, so they are not counted.
+		between := copyText(buffer.textTree, prevTokenEndPos, token.StartPos-prevTokenEndPos)
+		depth += strings.Count(between, "{") + strings.Count(between, "[")
+		depth -= strings.Count(between, "}") + strings.Count(between, "]")
+		prevTokenEndPos = token.EndPos
+
+		if token.Role != jsonKeyRole || depth != 1 {
+			continue
+		}
+
+		items = append(items, outlineItem{
+			level:    1,
+			name:     quotedKeyName(tokenText(buffer, token)),
+			startPos: locate.StartOfLineAtPos(buffer.textTree, token.StartPos),
+		})
+	}
+	return items
+}
+
+// yamlOutline returns the top-level keys in a YAML document.
+func yamlOutline(buffer *BufferState, tokens []parser.Token) []outlineItem {
+	var items []outlineItem
+	for _, token := range tokens {
+		if token.Role != yamlKeyRole || !isAtLineStart(buffer, token) {
+			continue
+		}
+
+		// The key token includes any indentation, so check that the key starts the line.
+		keyText := tokenText(buffer, token)
+		if strings.TrimLeft(keyText, " \t") != keyText {
+			continue
+		}
+
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(keyText), ":"))
+		items = append(items, outlineItem{
+			level:    1,
+			name:     quotedKeyName(name),
+			startPos: token.StartPos,
+		})
+	}
+	return items
+}
+
+// quotedKeyName returns the text between the quotes of a key like `"name": `.
+// If the key is not quoted, it is returned without the trailing colon.
+func quotedKeyName(s string) string {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), ":"))
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// MoveCursorToNextHeading moves the cursor to the next heading in a markdown document,
+// or the previous heading if reverse is true.
+// If there is no heading in that direction, the cursor does not move.
+func MoveCursorToNextHeading(state *EditorState, reverse bool) {
+	buffer := state.documentBuffer
+	var headings []outlineItem
+	if buffer.syntaxLanguage == syntax.LanguageMarkdown {
+		headings = documentOutline(buffer)
+	}
+
+	if len(headings) == 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No headings in document",
+		})
+		return
+	}
+
+	cursorLineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.cursor.position)
+	if reverse {
+		for i := len(headings) - 1; i >= 0; i-- {
+			if headings[i].startPos < cursorLineStartPos {
+				moveCursorToOutlineItem(buffer, headings[i])
+				return
+			}
+		}
+	} else {
+		for _, h := range headings {
+			if h.startPos > cursorLineStartPos {
+				moveCursorToOutlineItem(buffer, h)
+				return
+			}
+		}
+	}
+}
+
+func moveCursorToOutlineItem(buffer *BufferState, item outlineItem) {
+	buffer.cursor = cursorState{
+		position: locate.NextNonWhitespaceOrNewline(buffer.textTree, item.startPos),
+	}
+}
+
+// ShowOutlineMenu displays a menu listing the symbols in the document, such as markdown headings
+// indented by level, Go functions and types, or top-level JSON and YAML keys.
+// Selecting an item moves the cursor to the line with the symbol.
+func ShowOutlineMenu(state *EditorState) {
+	symbols := documentOutline(state.documentBuffer)
+	if len(symbols) == 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No symbols in document",
+		})
+		return
+	}
+
+	items := make([]menu.Item, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol := symbol // reference the symbol in this iteration of the loop
+		items = append(items, menu.Item{
+			Name: strings.Repeat("  ", symbol.level-1) + symbol.name,
+			Action: func(state *EditorState) {
+				moveCursorToOutlineItem(state.documentBuffer, symbol)
+			},
+		})
+	}
+	ShowMenu(state, MenuStyleOutline, items)
+}
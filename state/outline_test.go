@@ -0,0 +1,230 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/text"
+)
+
+const testMarkdownDoc = "# Title\n\nintro\n\n## First ##\n\ntext\n\nSecond\n------\n\n```\n# not a heading\n```\n\n### Nested\n"
+
+func TestParseMarkdownHeading(t *testing.T) {
+	testCases := []struct {
+		name          string
+		text          string
+		expectedLevel int
+		expectedTitle string
+	}{
+		{name: "atx level 1", text: "# Title\n", expectedLevel: 1, expectedTitle: "Title"},
+		{name: "atx level 6", text: "###### Title", expectedLevel: 6, expectedTitle: "Title"},
+		{name: "atx with closing sequence", text: "## Title ##\n", expectedLevel: 2, expectedTitle: "Title"},
+		{name: "atx indented", text: "  # Title\n", expectedLevel: 1, expectedTitle: "Title"},
+		{name: "atx too many markers", text: "####### Title\n", expectedLevel: 0},
+		{name: "setext level 1", text: "Title\n=====\n", expectedLevel: 1, expectedTitle: "Title"},
+		{name: "setext level 2", text: "Title\n---", expectedLevel: 2, expectedTitle: "Title"},
+		{name: "setext multiple lines", text: "First\nsecond\n===\n", expectedLevel: 1, expectedTitle: "First second"},
+		{name: "not a heading", text: "func main() {}", expectedLevel: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level, title := parseMarkdownHeading(tc.text)
+			assert.Equal(t, tc.expectedLevel, level)
+			assert.Equal(t, tc.expectedTitle, title)
+		})
+	}
+}
+
+func TestMarkdownOutline(t *testing.T) {
+	textTree, err := text.NewTreeFromString(testMarkdownDoc)
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	setSyntaxAndRetokenize(buffer, syntax.LanguageMarkdown)
+
+	assert.Equal(t, []outlineItem{
+		{level: 1, name: "Title", startPos: 0},
+		{level: 2, name: "First", startPos: 16},
+		{level: 2, name: "Second", startPos: 35},
+		{level: 3, name: "Nested", startPos: 75},
+	}, documentOutline(buffer))
+
+	// The outline reflects edits to the document.
+	buffer.cursor.position = 9
+	InsertRune(state, '#')
+	InsertRune(state, ' ')
+	headings := documentOutline(buffer)
+	require.Equal(t, 5, len(headings))
+	assert.Equal(t, outlineItem{level: 1, name: "intro", startPos: 9}, headings[1])
+}
+
+func TestDocumentOutline(t *testing.T) {
+	testCases := []struct {
+		name          string
+		language      syntax.Language
+		inputString   string
+		expectedItems []outlineItem
+	}{
+		{
+			name:        "plaintext",
+			language:    syntax.LanguagePlaintext,
+			inputString: "func foo() {}\n",
+		},
+		{
+			name:     "go",
+			language: syntax.LanguageGo,
+			inputString: `package main
+
+type Point struct {
+	X, Y int
+}
+
+type (
+	grouped int
+)
+
+func main() {
+	func() {}()
+}
+
+func (p *Point) Move(dx int) {}
+
+func (p Point) String() string { return "" }
+
+func (s Set[K]) Has(k K) bool { return false }
+
+func Map[T any](xs []T) []T { return xs }
+`,
+			expectedItems: []outlineItem{
+				{level: 1, name: "Point", startPos: 14},
+				{level: 1, name: "main", startPos: 70},
+				{level: 1, name: "(*Point).Move", startPos: 100},
+				{level: 1, name: "Point.String", startPos: 133},
+				{level: 1, name: "Set.Has", startPos: 179},
+				{level: 1, name: "Map", startPos: 227},
+			},
+		},
+		{
+			name:     "json",
+			language: syntax.LanguageJson,
+			inputString: `{
+  "name": "test",
+  "nested": {"inner": [{"deep": 1}]},
+  "list": ["{", {"item": 2}],
+  "last":true
+}`,
+			expectedItems: []outlineItem{
+				{level: 1, name: "name", startPos: 2},
+				{level: 1, name: "nested", startPos: 20},
+				{level: 1, name: "list", startPos: 58},
+				{level: 1, name: "last", startPos: 88},
+			},
+		},
+		{
+			name:     "yaml",
+			language: syntax.LanguageYaml,
+			inputString: `first: 1
+nested:
+  inner: 2
+"quoted key": 3
+- item: 4
+`,
+			expectedItems: []outlineItem{
+				{level: 1, name: "first", startPos: 0},
+				{level: 1, name: "nested", startPos: 9},
+				{level: 1, name: "quoted key", startPos: 28},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			setSyntaxAndRetokenize(buffer, tc.language)
+			assert.Equal(t, tc.expectedItems, documentOutline(buffer))
+		})
+	}
+}
+
+func TestMarkdownOutlineNotMarkdown(t *testing.T) {
+	textTree, err := text.NewTreeFromString(testMarkdownDoc)
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	assert.Nil(t, documentOutline(buffer))
+}
+
+func TestMoveCursorToNextHeading(t *testing.T) {
+	testCases := []struct {
+		name              string
+		cursorPos         uint64
+		reverse           bool
+		expectedCursorPos uint64
+	}{
+		{name: "next from start", cursorPos: 0, reverse: false, expectedCursorPos: 16},
+		{name: "next from middle of heading", cursorPos: 18, reverse: false, expectedCursorPos: 35},
+		{name: "next setext to atx", cursorPos: 35, reverse: false, expectedCursorPos: 75},
+		{name: "next from last heading", cursorPos: 75, reverse: false, expectedCursorPos: 75},
+		{name: "prev from end", cursorPos: 86, reverse: true, expectedCursorPos: 75},
+		{name: "prev from middle of heading", cursorPos: 79, reverse: true, expectedCursorPos: 35},
+		{name: "prev skips code block", cursorPos: 60, reverse: true, expectedCursorPos: 35},
+		{name: "prev from first heading", cursorPos: 3, reverse: true, expectedCursorPos: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(testMarkdownDoc)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.cursorPos
+			setSyntaxAndRetokenize(buffer, syntax.LanguageMarkdown)
+			MoveCursorToNextHeading(state, tc.reverse)
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+		})
+	}
+}
+
+func TestShowOutlineMenu(t *testing.T) {
+	textTree, err := text.NewTreeFromString(testMarkdownDoc)
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	setSyntaxAndRetokenize(buffer, syntax.LanguageMarkdown)
+	ShowOutlineMenu(state)
+
+	assert.True(t, state.Menu().Visible())
+	assert.Equal(t, MenuStyleOutline, state.Menu().Style())
+
+	results, _ := state.Menu().SearchResults()
+	names := make([]string, 0, len(results))
+	for _, item := range results {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"Title", "  First", "  Second", "    Nested"}, names)
+
+	MoveMenuSelection(state, 2)
+	ExecuteSelectedMenuItem(state)
+	assert.False(t, state.Menu().Visible())
+	assert.Equal(t, uint64(35), buffer.cursor.position)
+}
+
+func TestShowOutlineMenuNoSymbols(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	setSyntaxAndRetokenize(state.documentBuffer, syntax.LanguageMarkdown)
+	ShowOutlineMenu(state)
+	assert.False(t, state.Menu().Visible())
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "No symbols in document"}, state.StatusMsg())
+}
@@ -0,0 +1,68 @@
+package state
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/selection"
+)
+
+// CursorLocation describes the position of the cursor in the document.
+type CursorLocation struct {
+	Position   uint64 // Offset in characters (runes) from the start of the document.
+	ByteOffset uint64 // Offset in bytes from the start of the UTF-8 encoded document.
+	LineNum    uint64 // Zero-indexed line number.
+	Col        uint64 // Zero-indexed column, in grapheme clusters from the start of the line.
+}
+
+// Selection describes the region selected in visual mode.
+type Selection struct {
+	Mode   selection.Mode
+	Region selection.Region
+}
+
+// Active returns whether any text is selected.
+func (s Selection) Active() bool {
+	return s.Mode != selection.ModeNone
+}
+
+// CursorLocation returns the current position of the cursor in the document.
+// Calculating the byte offset requires reading the document up to the cursor,
+// so this takes time linear in the cursor position.
+func (s *EditorState) CursorLocation() CursorLocation {
+	buffer := s.documentBuffer
+	pos := buffer.cursor.position
+	lineNum, col := locate.PosToLineNumAndCol(buffer.textTree, pos)
+	return CursorLocation{
+		Position:   pos,
+		ByteOffset: byteOffsetForPosition(buffer, pos),
+		LineNum:    lineNum,
+		Col:        col,
+	}
+}
+
+// Selection returns the current visual mode selection.
+// If nothing is selected, the mode is selection.ModeNone and the region is empty.
+func (s *EditorState) Selection() Selection {
+	buffer := s.documentBuffer
+	return Selection{
+		Mode:   buffer.selector.Mode(),
+		Region: buffer.SelectedRegion(),
+	}
+}
+
+func byteOffsetForPosition(buffer *BufferState, pos uint64) uint64 {
+	var offset uint64
+	reader := buffer.textTree.ReaderAtPosition(0)
+	for i := uint64(0); i < pos; i++ {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			panic(err) // Should never happen because the document is valid UTF-8.
+		}
+		offset += uint64(utf8.RuneLen(r))
+	}
+	return offset
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/text"
+)
+
+func TestCursorLocation(t *testing.T) {
+	testCases := []struct {
+		name             string
+		inputString      string
+		cursorPos        uint64
+		expectedLocation CursorLocation
+	}{
+		{
+			name:             "empty document",
+			inputString:      "",
+			cursorPos:        0,
+			expectedLocation: CursorLocation{},
+		},
+		{
+			name:             "first line",
+			inputString:      "abc\ndef",
+			cursorPos:        2,
+			expectedLocation: CursorLocation{Position: 2, ByteOffset: 2, LineNum: 0, Col: 2},
+		},
+		{
+			name:             "second line",
+			inputString:      "abc\ndef",
+			cursorPos:        5,
+			expectedLocation: CursorLocation{Position: 5, ByteOffset: 5, LineNum: 1, Col: 1},
+		},
+		{
+			name:             "multi-byte characters",
+			inputString:      "€a\n界b",
+			cursorPos:        4,
+			expectedLocation: CursorLocation{Position: 4, ByteOffset: 8, LineNum: 1, Col: 1},
+		},
+		{
+			name:             "grapheme cluster with multiple runes",
+			inputString:      "éx",
+			cursorPos:        2,
+			expectedLocation: CursorLocation{Position: 2, ByteOffset: 3, LineNum: 0, Col: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor = cursorState{position: tc.cursorPos}
+			assert.Equal(t, tc.expectedLocation, state.CursorLocation())
+		})
+	}
+}
+
+func TestSelection(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc\ndef\nghi")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.cursor = cursorState{position: 1}
+
+	sel := state.Selection()
+	assert.False(t, sel.Active())
+	assert.Equal(t, Selection{Mode: selection.ModeNone, Region: selection.EmptyRegion}, sel)
+
+	ToggleVisualMode(state, selection.ModeChar)
+	state.documentBuffer.cursor = cursorState{position: 5}
+	sel = state.Selection()
+	assert.True(t, sel.Active())
+	assert.Equal(t, Selection{Mode: selection.ModeChar, Region: selection.Region{StartPos: 1, EndPos: 6}}, sel)
+	assert.Equal(t, InputModeVisual, state.InputMode())
+
+	ToggleVisualMode(state, selection.ModeLine)
+	sel = state.Selection()
+	assert.Equal(t, Selection{Mode: selection.ModeLine, Region: selection.Region{StartPos: 0, EndPos: 7}}, sel)
+
+	SetInputMode(state, InputModeNormal)
+	assert.False(t, state.Selection().Active())
+	assert.Equal(t, InputModeNormal, state.InputMode())
+}
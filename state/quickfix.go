@@ -0,0 +1,193 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/file"
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/lsp"
+	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/shellcmd"
+)
+
+// QuickfixEntry is a location in a file, such as a grep match or a compiler error.
+type QuickfixEntry struct {
+	Path    string // Absolute path to the file.
+	LineNum uint64 // Zero-indexed line number.
+	Col     uint64 // Zero-indexed column, measured in grapheme clusters.
+	Text    string // Matched text or problem description.
+}
+
+// quickfixState is a list of locations that the user can step through, like vim's quickfix list.
+// Any command that produces file locations can replace the list.
+type quickfixState struct {
+	entries []QuickfixEntry
+	index   int // Index of the entry the user last visited, or -1 if the user hasn't visited any entry.
+}
+
+// SetQuickfixList replaces the quickfix list.
+// Entries without a path are discarded.
+func SetQuickfixList(state *EditorState, entries []QuickfixEntry) {
+	validEntries := make([]QuickfixEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Path != "" {
+			validEntries = append(validEntries, entry)
+		}
+	}
+	state.quickfix = quickfixState{entries: validEntries, index: -1}
+}
+
+// quickfixEntriesFromFileLocations converts file locations, such as the output of grep, to quickfix entries.
+func quickfixEntriesFromFileLocations(locations []shellcmd.FileLocation) ([]QuickfixEntry, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.Wrap(err, "os.Getwd")
+	}
+
+	entries := make([]QuickfixEntry, 0, len(locations))
+	for _, loc := range locations {
+		entries = append(entries, QuickfixEntry{
+			Path:    absPath(loc.Path, cwd),
+			LineNum: translateFileLocationLineNum(loc.LineNum),
+			Text:    loc.Snippet,
+		})
+	}
+	return entries, nil
+}
+
+// quickfixEntriesFromDiagnostics converts problems reported by a linter or compiler to quickfix entries.
+func quickfixEntriesFromDiagnostics(diagnostics []shellcmd.Diagnostic) ([]QuickfixEntry, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, errors.Wrap(err, "os.Getwd")
+	}
+
+	entries := make([]QuickfixEntry, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		var col uint64
+		if d.Col > 0 {
+			col = d.Col - 1
+		}
+
+		entries = append(entries, QuickfixEntry{
+			Path:    absPath(d.Path, cwd),
+			LineNum: translateFileLocationLineNum(d.LineNum),
+			Col:     col,
+			Text:    d.Message,
+		})
+	}
+	return entries, nil
+}
+
+// ShowQuickfixMenu displays the entries in the quickfix list as a searchable menu.
+// Selecting an entry opens the file at its location.
+func ShowQuickfixMenu(state *EditorState) {
+	entries := state.quickfix.entries
+	if len(entries) == 0 {
+		reportEmptyQuickfixList(state)
+		return
+	}
+
+	menuItems := make([]menu.Item, 0, len(entries))
+	for i, entry := range entries {
+		i := i // Copy for the closure.
+		menuItems = append(menuItems, menu.Item{
+			Name: formatQuickfixEntryName(entry),
+			Link: lsp.URIForPath(entry.Path),
+			Action: func(state *EditorState) {
+				goToQuickfixEntry(state, i)
+			},
+		})
+	}
+
+	ShowMenu(state, MenuStyleFileLocation, menuItems)
+}
+
+// formatQuickfixEntryName shows the path relative to the working directory if the file is in the working directory.
+func formatQuickfixEntryName(entry QuickfixEntry) string {
+	path := file.RelativePathCwd(entry.Path)
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		path = entry.Path
+	}
+	return fmt.Sprintf("%s:%d  %s", path, entry.LineNum+1, entry.Text)
+}
+
+// GoToNextQuickfixEntry opens the file for the next entry in the quickfix list, like vim's ":cnext".
+// If reverse is true, it opens the previous entry instead, like vim's ":cprev".
+// This wraps around at the start and end of the list, and skips entries for files that do not exist.
+func GoToNextQuickfixEntry(state *EditorState, reverse bool) {
+	n := len(state.quickfix.entries)
+	if n == 0 {
+		reportEmptyQuickfixList(state)
+		return
+	}
+
+	i := state.quickfix.index
+	if i < 0 && reverse {
+		// Before visiting any entry, the previous entry is the last one.
+		i = 0
+	}
+
+	for attempt := 0; attempt < n; attempt++ {
+		if reverse {
+			i = (i - 1 + n) % n
+		} else {
+			i = (i + 1) % n
+		}
+
+		if _, err := os.Stat(state.quickfix.entries[i].Path); err == nil {
+			goToQuickfixEntry(state, i)
+			return
+		}
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "No quickfix entries for files that exist",
+	})
+}
+
+// goToQuickfixEntry opens the file for a quickfix entry and moves the cursor to the entry's location.
+// Opening a different file records the current document in the file timeline.
+func goToQuickfixEntry(state *EditorState, i int) {
+	entry := state.quickfix.entries[i]
+	locator := func(p LocatorParams) uint64 {
+		lineNum := locate.ClosestValidLineNum(p.TextTree, entry.LineNum)
+		return locate.LineNumAndColToPos(p.TextTree, lineNum, entry.Col)
+	}
+
+	if entry.Path == state.fileWatcher.Path() {
+		buffer := state.documentBuffer
+		buffer.cursor = cursorState{position: locator(LocatorParams{TextTree: buffer.textTree})}
+		ScrollViewToCursor(state)
+		reportQuickfixEntry(state, i)
+		return
+	}
+
+	AutoWriteOrAbortIfUnsavedChanges(state, func(state *EditorState) {
+		LoadDocument(state, entry.Path, true, locator)
+		if state.fileWatcher.Path() == entry.Path {
+			reportQuickfixEntry(state, i)
+		}
+	}, true)
+}
+
+func reportQuickfixEntry(state *EditorState, i int) {
+	state.quickfix.index = i
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  fmt.Sprintf("(%d of %d) %s", i+1, len(state.quickfix.entries), state.quickfix.entries[i].Text),
+	})
+}
+
+func reportEmptyQuickfixList(state *EditorState) {
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  "No quickfix entries",
+	})
+}
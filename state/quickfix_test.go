@@ -0,0 +1,192 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/shellcmd"
+)
+
+func TestQuickfixEntriesFromFileLocations(t *testing.T) {
+	withTempDirPaths(t, nil, func(dir string) {
+		entries, err := quickfixEntriesFromFileLocations([]shellcmd.FileLocation{
+			{Path: "a.txt", LineNum: 2, Snippet: "foo"},
+			{Path: "/tmp/b.txt", LineNum: 0, Snippet: "bar"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QuickfixEntry{
+			{Path: filepath.Join(dir, "a.txt"), LineNum: 1, Text: "foo"},
+			{Path: "/tmp/b.txt", LineNum: 0, Text: "bar"},
+		}, entries)
+	})
+}
+
+func TestQuickfixEntriesFromDiagnostics(t *testing.T) {
+	withTempDirPaths(t, nil, func(dir string) {
+		entries, err := quickfixEntriesFromDiagnostics([]shellcmd.Diagnostic{
+			{Path: "a.go", LineNum: 3, Col: 5, Message: "undefined: x"},
+			{Path: "b.go", LineNum: 1, Message: "missing return"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []QuickfixEntry{
+			{Path: filepath.Join(dir, "a.go"), LineNum: 2, Col: 4, Text: "undefined: x"},
+			{Path: filepath.Join(dir, "b.go"), LineNum: 0, Text: "missing return"},
+		}, entries)
+	})
+}
+
+func TestGoToNextQuickfixEntry(t *testing.T) {
+	withTempDirPaths(t, []string{"a.txt", "b.txt"}, func(dir string) {
+		aPath, bPath := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+		err := os.WriteFile(aPath, []byte("abc\ndef\nghi"), 0644)
+		require.NoError(t, err)
+
+		state := NewEditorState(100, 100, nil, nil)
+		SetQuickfixList(state, []QuickfixEntry{
+			{Path: aPath, LineNum: 1, Col: 2, Text: "first"},
+			{Path: bPath, LineNum: 0, Text: "second"},
+			{Path: aPath, LineNum: 10, Text: "third"},
+		})
+
+		type expected struct {
+			path      string
+			cursorPos uint64
+			statusMsg string
+		}
+
+		assertEntry := func(e expected) {
+			assert.Equal(t, e.path, state.fileWatcher.Path())
+			assert.Equal(t, e.cursorPos, state.documentBuffer.cursor.position)
+			assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: e.statusMsg}, state.StatusMsg())
+		}
+
+		first := expected{path: aPath, cursorPos: 6, statusMsg: "(1 of 3) first"}
+		second := expected{path: bPath, cursorPos: 0, statusMsg: "(2 of 3) second"}
+		third := expected{path: aPath, cursorPos: 8, statusMsg: "(3 of 3) third"}
+
+		GoToNextQuickfixEntry(state, false)
+		assertEntry(first)
+		GoToNextQuickfixEntry(state, false)
+		assertEntry(second)
+		GoToNextQuickfixEntry(state, false)
+		assertEntry(third)
+
+		// Wrap around to the first entry.
+		GoToNextQuickfixEntry(state, false)
+		assertEntry(first)
+
+		// Wrap around backward to the last entry.
+		GoToNextQuickfixEntry(state, true)
+		assertEntry(third)
+		GoToNextQuickfixEntry(state, true)
+		assertEntry(second)
+
+		// Opening another file records the previous document in the file timeline.
+		assert.Equal(t, aPath, state.fileTimeline.PeekBackward().Path)
+	})
+}
+
+func TestGoToPrevQuickfixEntryBeforeVisitingAny(t *testing.T) {
+	withTempDirPaths(t, []string{"a.txt", "b.txt"}, func(dir string) {
+		state := NewEditorState(100, 100, nil, nil)
+		SetQuickfixList(state, []QuickfixEntry{
+			{Path: filepath.Join(dir, "a.txt"), Text: "first"},
+			{Path: filepath.Join(dir, "b.txt"), Text: "second"},
+		})
+		GoToNextQuickfixEntry(state, true)
+		assert.Equal(t, filepath.Join(dir, "b.txt"), state.fileWatcher.Path())
+		assert.Equal(t, "(2 of 2) second", state.StatusMsg().Text)
+	})
+}
+
+func TestGoToNextQuickfixEntryInvalidEntries(t *testing.T) {
+	testCases := []struct {
+		name              string
+		entries           []QuickfixEntry
+		expectedPath      string
+		expectedStatusMsg StatusMsg
+	}{
+		{
+			name:              "empty list",
+			entries:           nil,
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleError, Text: "No quickfix entries"},
+		},
+		{
+			name:              "entries without a path are discarded",
+			entries:           []QuickfixEntry{{Text: "no path"}},
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleError, Text: "No quickfix entries"},
+		},
+		{
+			name: "all files missing",
+			entries: []QuickfixEntry{
+				{Path: "/missing/a.txt", Text: "first"},
+				{Path: "/missing/b.txt", Text: "second"},
+			},
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleError, Text: "No quickfix entries for files that exist"},
+		},
+		{
+			name: "skip missing file",
+			entries: []QuickfixEntry{
+				{Path: "/missing/a.txt", Text: "first"},
+				{Text: "no path"},
+				{Path: "a.txt", Text: "second"},
+			},
+			expectedPath:      "a.txt",
+			expectedStatusMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: "(2 of 2) second"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withTempDirPaths(t, []string{"a.txt"}, func(dir string) {
+				state := NewEditorState(100, 100, nil, nil)
+				entries := make([]QuickfixEntry, 0, len(tc.entries))
+				for _, entry := range tc.entries {
+					if entry.Path != "" && !filepath.IsAbs(entry.Path) {
+						entry.Path = filepath.Join(dir, entry.Path)
+					}
+					entries = append(entries, entry)
+				}
+
+				SetQuickfixList(state, entries)
+				GoToNextQuickfixEntry(state, false)
+				assert.Equal(t, tc.expectedStatusMsg, state.StatusMsg())
+				if tc.expectedPath != "" {
+					assert.Equal(t, filepath.Join(dir, tc.expectedPath), state.fileWatcher.Path())
+				}
+			})
+		})
+	}
+}
+
+func TestShowQuickfixMenu(t *testing.T) {
+	withTempDirPaths(t, []string{"a.txt", "b.txt"}, func(dir string) {
+		state := NewEditorState(100, 100, nil, nil)
+		ShowQuickfixMenu(state)
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "No quickfix entries"}, state.StatusMsg())
+
+		SetQuickfixList(state, []QuickfixEntry{
+			{Path: filepath.Join(dir, "a.txt"), LineNum: 0, Text: "first"},
+			{Path: filepath.Join(dir, "b.txt"), LineNum: 1, Text: "second"},
+			{Path: "/missing/c.txt", LineNum: 2, Text: "third"},
+		})
+		ShowQuickfixMenu(state)
+		assert.Equal(t, InputModeMenu, state.InputMode())
+		menuItems, _ := state.Menu().SearchResults()
+		require.Equal(t, 3, len(menuItems))
+		assert.Equal(t, "a.txt:1  first", menuItems[0].Name)
+		assert.Equal(t, "b.txt:2  second", menuItems[1].Name)
+		assert.Equal(t, "/missing/c.txt:3  third", menuItems[2].Name)
+
+		// Selecting an entry makes it the current entry, so the next entry follows it.
+		MoveMenuSelection(state, 1)
+		ExecuteSelectedMenuItem(state)
+		assert.Equal(t, filepath.Join(dir, "b.txt"), state.fileWatcher.Path())
+		GoToNextQuickfixEntry(state, false)
+		assert.Equal(t, filepath.Join(dir, "a.txt"), state.fileWatcher.Path())
+	})
+}
@@ -1,6 +1,7 @@
 package state
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -59,3 +60,41 @@ func TestQuit(t *testing.T) {
 		})
 	}
 }
+
+func TestQuitAfterEditUndoAndSave(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	path := filepath.Join(t.TempDir(), "test-quit.txt")
+	LoadDocument(state, path, false, func(LocatorParams) uint64 { return 0 })
+	defer state.fileWatcher.Stop()
+
+	assertQuitAllowed := func(expectAllowed bool) {
+		state.quitFlag = false
+		AbortIfUnsavedChanges(state, Quit, true)
+		assert.Equal(t, expectAllowed, state.QuitFlag())
+	}
+
+	// No changes after loading the document.
+	assertQuitAllowed(true)
+
+	// An edit marks the document as modified.
+	CheckpointUndoLog(state)
+	InsertRune(state, 'a')
+	assertQuitAllowed(false)
+
+	// Undoing the edit returns to the last saved state.
+	CheckpointUndoLog(state)
+	Undo(state)
+	assertQuitAllowed(true)
+
+	// Redoing the edit modifies the document again.
+	Redo(state)
+	assertQuitAllowed(false)
+
+	// Saving clears the modified state.
+	SaveDocument(state)
+	assertQuitAllowed(true)
+
+	// Undoing after the save modifies the document relative to the saved state.
+	Undo(state)
+	assertQuitAllowed(false)
+}
@@ -71,6 +71,7 @@ func StartSearch(state *EditorState, direction SearchDirection) {
 func CompleteSearch(state *EditorState, commit bool) {
 	buffer := state.documentBuffer
 	if commit {
+		state.searchHighlightCleared = false
 		if buffer.search.match != nil {
 			buffer.cursor = cursorState{position: buffer.search.match.StartPos}
 		}
@@ -135,17 +136,19 @@ func runTextSearchQuery(state *EditorState, q string) {
 	buffer := state.documentBuffer
 	buffer.search.query = q
 	foundMatch, matchStartPos := false, uint64(0)
-	parsedQuery := parseQuery(q)
+	parsedQuery := parseQuery(q, buffer.smartCase)
 	if buffer.search.direction == SearchDirectionForward {
-		foundMatch, matchStartPos = searchTextForward(
+		foundMatch, matchStartPos, _ = searchTextForward(
 			buffer.cursor.position,
 			buffer.textTree,
-			parsedQuery)
+			parsedQuery,
+			buffer.wrapScan)
 	} else {
-		foundMatch, matchStartPos = searchTextBackward(
+		foundMatch, matchStartPos, _ = searchTextBackward(
 			buffer.cursor.position,
 			buffer.textTree,
-			parsedQuery)
+			parsedQuery,
+			buffer.wrapScan)
 	}
 
 	if !foundMatch {
@@ -162,31 +165,119 @@ func runTextSearchQuery(state *EditorState, q string) {
 }
 
 // FindNextMatch moves the cursor to the next position matching the search query.
+// If the search wraps around the end of the document, or fails to find a match, this sets a status message.
 func FindNextMatch(state *EditorState, reverse bool) {
 	buffer := state.documentBuffer
-	parsedQuery := parseQuery(buffer.search.query)
+	parsedQuery := parseQuery(buffer.search.query, buffer.smartCase)
 
 	direction := buffer.search.direction
 	if reverse {
 		direction = direction.Reverse()
 	}
 
-	foundMatch, newCursorPos := false, uint64(0)
+	foundMatch, newCursorPos, wrapped := false, uint64(0), false
 	if direction == SearchDirectionForward {
-		foundMatch, newCursorPos = searchTextForward(
+		foundMatch, newCursorPos, wrapped = searchTextForward(
 			buffer.cursor.position+1,
 			buffer.textTree,
-			parsedQuery)
+			parsedQuery,
+			buffer.wrapScan)
 	} else {
-		foundMatch, newCursorPos = searchTextBackward(
+		foundMatch, newCursorPos, wrapped = searchTextBackward(
 			buffer.cursor.position,
 			buffer.textTree,
-			parsedQuery)
+			parsedQuery,
+			buffer.wrapScan)
 	}
 
 	if foundMatch {
 		buffer.cursor = cursorState{position: newCursorPos}
 	}
+
+	state.searchHighlightCleared = false
+	reportSearchResult(state, direction, parsedQuery, foundMatch, wrapped)
+}
+
+func reportSearchResult(state *EditorState, direction SearchDirection, parsedQuery parsedQuery, foundMatch bool, wrapped bool) {
+	hitBoundary, continueBoundary := "BOTTOM", "TOP"
+	if direction == SearchDirectionBackward {
+		hitBoundary, continueBoundary = "TOP", "BOTTOM"
+	}
+
+	if !foundMatch && !state.documentBuffer.wrapScan {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Search hit %s without match for: %s", hitBoundary, parsedQuery.queryText),
+		})
+	} else if !foundMatch {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Pattern not found: %s", parsedQuery.queryText),
+		})
+	} else if wrapped {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleSuccess,
+			Text:  fmt.Sprintf("Search hit %s, continuing at %s", hitBoundary, continueBoundary),
+		})
+	}
+}
+
+// ClearSearchHighlight stops highlighting matches for the last search query, similar to vim's ":nohlsearch".
+// This does not clear the query, so "n" and "N" still find the next match.
+// The next search highlights matches again.
+func ClearSearchHighlight(state *EditorState) {
+	state.searchHighlightCleared = true
+}
+
+// SearchHighlightEnabled returns whether to highlight every match for the last search query.
+func (s *EditorState) SearchHighlightEnabled() bool {
+	return s.documentBuffer.hlSearch && !s.searchHighlightCleared && s.inputMode != InputModeSearch
+}
+
+// SearchMatchesInRange returns every match for the last search query that intersects [startPos, endPos).
+func (s *BufferState) SearchMatchesInRange(startPos uint64, endPos uint64) []SearchMatch {
+	parsedQuery := parseQuery(s.search.query, s.smartCase)
+	queryLen := uint64(utf8.RuneCountInString(parsedQuery.queryText))
+	if queryLen == 0 || startPos >= endPos {
+		return nil
+	}
+
+	transformer := transformerForSearch(parsedQuery.caseSensitive)
+	transformedQuery, _, err := transform.String(transformer, parsedQuery.queryText)
+	if err != nil {
+		panic(err)
+	}
+
+	// Start far enough before the range to find matches that overlap its first position.
+	pos := uint64(0)
+	if startPos >= queryLen {
+		pos = startPos - queryLen + 1
+	}
+
+	var matches []SearchMatch
+	searcher := text.NewSearcher(transformedQuery)
+	for pos < endPos {
+		// Limit the search to matches that start before the end of the range.
+		treeReader := s.textTree.ReaderAtPosition(pos)
+		transformedReader := transform.NewReader(&treeReader, transformer)
+		limit := endPos - pos + queryLen - 1
+		foundMatch, matchOffset, err := searcher.Limit(limit).NextInReader(transformedReader)
+		if err != nil {
+			panic(err) // should never happen for text.Reader.
+		}
+
+		if !foundMatch {
+			break
+		}
+
+		matchStartPos := pos + matchOffset
+		matches = append(matches, SearchMatch{
+			StartPos: matchStartPos,
+			EndPos:   matchStartPos + queryLen,
+		})
+		pos = matchStartPos + 1
+	}
+	return matches
 }
 
 type parsedQuery struct {
@@ -195,11 +286,11 @@ type parsedQuery struct {
 }
 
 // parseQuery interprets the user's search query.
-// By default, if the query is all lowercase, it's case-insensitive;
+// If smartCase is enabled and the query is all lowercase, it's case-insensitive;
 // otherwise, it's case-sensitive (equivalent to vim's smartcase option).
 // Users can override this by setting the suffix to "\c" for case-insensitive
 // and "\C" for case-sensitive.
-func parseQuery(rawQuery string) parsedQuery {
+func parseQuery(rawQuery string, smartCase bool) parsedQuery {
 	if strings.HasSuffix(rawQuery, `\c`) {
 		return parsedQuery{
 			queryText:     rawQuery[0 : len(rawQuery)-2],
@@ -214,6 +305,13 @@ func parseQuery(rawQuery string) parsedQuery {
 		}
 	}
 
+	if !smartCase {
+		return parsedQuery{
+			queryText:     rawQuery,
+			caseSensitive: true,
+		}
+	}
+
 	var caseSensitive bool
 	for _, r := range rawQuery {
 		if unicode.IsUpper(r) {
@@ -240,7 +338,9 @@ func transformerForSearch(caseSensitive bool) transform.Transformer {
 }
 
 // searchTextForward finds the position of the next occurrence of a query string on or after the start position.
-func searchTextForward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery) (bool, uint64) {
+// If wrapScan is enabled and there is no match before the end of the text, this continues from the beginning
+// of the text and reports that the search wrapped.
+func searchTextForward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery, wrapScan bool) (found bool, matchPos uint64, wrapped bool) {
 	transformer := transformerForSearch(parsedQuery.caseSensitive)
 	transformedQuery, _, err := transform.String(transformer, parsedQuery.queryText)
 	if err != nil {
@@ -257,7 +357,11 @@ func searchTextForward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery
 	}
 
 	if foundMatch {
-		return true, startPos + matchOffset
+		return true, startPos + matchOffset, false
+	}
+
+	if !wrapScan {
+		return false, 0, false
 	}
 
 	// Wraparound search from the beginning of the text to the start position.
@@ -267,11 +371,13 @@ func searchTextForward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery
 	if err != nil {
 		panic(err)
 	}
-	return foundMatch, matchOffset
+	return foundMatch, matchOffset, foundMatch
 }
 
 // searchTextBackward finds the beginning of the previous match before the start position.
-func searchTextBackward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery) (bool, uint64) {
+// If wrapScan is enabled and there is no match after the beginning of the text, this continues from the end
+// of the text and reports that the search wrapped.
+func searchTextBackward(startPos uint64, tree *text.Tree, parsedQuery parsedQuery, wrapScan bool) (found bool, matchPos uint64, wrapped bool) {
 	transformer := transformerForSearch(parsedQuery.caseSensitive)
 	transformedQuery, _, err := transform.String(transformer, parsedQuery.queryText)
 	if err != nil {
@@ -293,7 +399,11 @@ func searchTextBackward(startPos uint64, tree *text.Tree, parsedQuery parsedQuer
 	}
 
 	if foundMatch {
-		return true, matchOffset
+		return true, matchOffset, false
+	}
+
+	if !wrapScan {
+		return false, 0, false
 	}
 
 	// Wraparound search from the start position to the end of the text, looking for the last match.
@@ -305,5 +415,5 @@ func searchTextBackward(startPos uint64, tree *text.Tree, parsedQuery parsedQuer
 	if err != nil {
 		panic(err)
 	}
-	return foundMatch, readerStartPos + matchOffset
+	return foundMatch, readerStartPos + matchOffset, foundMatch
 }
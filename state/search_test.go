@@ -103,6 +103,7 @@ func TestSearchCaseSensitivity(t *testing.T) {
 		name             string
 		text             string
 		query            string
+		disableSmartCase bool
 		expectedMatchPos uint64
 	}{
 		{
@@ -129,6 +130,27 @@ func TestSearchCaseSensitivity(t *testing.T) {
 			query:            "FOO\\c",
 			expectedMatchPos: 4,
 		},
+		{
+			name:             "lowercase query, smartcase disabled",
+			text:             "abc Foo foo xyz",
+			query:            "foo",
+			disableSmartCase: true,
+			expectedMatchPos: 8,
+		},
+		{
+			name:             "mixed-case query, smartcase disabled",
+			text:             "abc foo Foo xyz",
+			query:            "Foo",
+			disableSmartCase: true,
+			expectedMatchPos: 8,
+		},
+		{
+			name:             "lowercase query, smartcase disabled, force case-insensitive search",
+			text:             "abc Foo foo xyz",
+			query:            "foo\\c",
+			disableSmartCase: true,
+			expectedMatchPos: 4,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -138,6 +160,7 @@ func TestSearchCaseSensitivity(t *testing.T) {
 			state := NewEditorState(100, 100, nil, nil)
 			buffer := state.documentBuffer
 			buffer.textTree = textTree
+			buffer.smartCase = !tc.disableSmartCase
 
 			StartSearch(state, SearchDirectionForward)
 			for _, r := range tc.query {
@@ -417,3 +440,305 @@ func TestSearchWordUnderCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestFindNextMatchWrapScan(t *testing.T) {
+	testCases := []struct {
+		name              string
+		text              string
+		cursorPos         uint64
+		query             string
+		direction         SearchDirection
+		reverse           bool
+		disableWrapScan   bool
+		expectedCursorPos uint64
+		expectedStatusMsg StatusMsg
+	}{
+		{
+			name:              "forward, found without wraparound",
+			text:              "foo bar baz",
+			cursorPos:         1,
+			query:             "ba",
+			direction:         SearchDirectionForward,
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "forward, found in wraparound",
+			text:              "foo bar baz",
+			cursorPos:         8,
+			query:             "ba",
+			direction:         SearchDirectionForward,
+			expectedCursorPos: 4,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleSuccess,
+				Text:  "Search hit BOTTOM, continuing at TOP",
+			},
+		},
+		{
+			name:              "forward, not found in wraparound",
+			text:              "foo bar baz",
+			cursorPos:         8,
+			query:             "xyz",
+			direction:         SearchDirectionForward,
+			expectedCursorPos: 8,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  "Pattern not found: xyz",
+			},
+		},
+		{
+			name:              "forward, wrapscan disabled, found before end",
+			text:              "foo bar baz",
+			cursorPos:         1,
+			query:             "ba",
+			direction:         SearchDirectionForward,
+			disableWrapScan:   true,
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "forward, wrapscan disabled, hit end",
+			text:              "foo bar baz",
+			cursorPos:         8,
+			query:             "ba",
+			direction:         SearchDirectionForward,
+			disableWrapScan:   true,
+			expectedCursorPos: 8,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  "Search hit BOTTOM without match for: ba",
+			},
+		},
+		{
+			name:              "backward, found in wraparound",
+			text:              "foo bar baz",
+			cursorPos:         4,
+			query:             "ba",
+			direction:         SearchDirectionBackward,
+			expectedCursorPos: 8,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleSuccess,
+				Text:  "Search hit TOP, continuing at BOTTOM",
+			},
+		},
+		{
+			name:              "backward, wrapscan disabled, found before start",
+			text:              "foo bar baz",
+			cursorPos:         8,
+			query:             "ba",
+			direction:         SearchDirectionBackward,
+			disableWrapScan:   true,
+			expectedCursorPos: 4,
+		},
+		{
+			name:              "backward, wrapscan disabled, hit start",
+			text:              "foo bar baz",
+			cursorPos:         4,
+			query:             "ba",
+			direction:         SearchDirectionBackward,
+			disableWrapScan:   true,
+			expectedCursorPos: 4,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  "Search hit TOP without match for: ba",
+			},
+		},
+		{
+			name:              "forward reversed, wrapscan disabled, hit start",
+			text:              "foo bar baz",
+			cursorPos:         4,
+			query:             "ba",
+			direction:         SearchDirectionForward,
+			reverse:           true,
+			disableWrapScan:   true,
+			expectedCursorPos: 4,
+			expectedStatusMsg: StatusMsg{
+				Style: StatusMsgStyleError,
+				Text:  "Search hit TOP without match for: ba",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.text)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.wrapScan = !tc.disableWrapScan
+			buffer.cursor = cursorState{position: tc.cursorPos}
+			buffer.search.query = tc.query
+			buffer.search.direction = tc.direction
+			FindNextMatch(state, tc.reverse)
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+			assert.Equal(t, tc.expectedStatusMsg, state.StatusMsg())
+		})
+	}
+}
+
+func TestSearchWrapScanDisabled(t *testing.T) {
+	textTree, err := text.NewTreeFromString("foo bar baz")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.wrapScan = false
+	buffer.cursor = cursorState{position: 5}
+
+	StartSearch(state, SearchDirectionForward)
+	AppendRuneToSearchQuery(state, 'f')
+	assert.Nil(t, buffer.search.match)
+
+	CompleteSearch(state, true)
+	assert.Equal(t, cursorState{position: 5}, buffer.cursor)
+}
+
+func TestSearchMatchesInRange(t *testing.T) {
+	testCases := []struct {
+		name            string
+		text            string
+		query           string
+		startPos        uint64
+		endPos          uint64
+		expectedMatches []SearchMatch
+	}{
+		{
+			name:     "empty query",
+			text:     "foo bar",
+			query:    "",
+			startPos: 0,
+			endPos:   7,
+		},
+		{
+			name:     "no matches",
+			text:     "foo bar",
+			query:    "xyz",
+			startPos: 0,
+			endPos:   7,
+		},
+		{
+			name:     "multiple matches",
+			text:     "foo bar foo baz foo",
+			query:    "foo",
+			startPos: 0,
+			endPos:   19,
+			expectedMatches: []SearchMatch{
+				{StartPos: 0, EndPos: 3},
+				{StartPos: 8, EndPos: 11},
+				{StartPos: 16, EndPos: 19},
+			},
+		},
+		{
+			name:     "overlapping matches",
+			text:     "aaaa",
+			query:    "aa",
+			startPos: 0,
+			endPos:   4,
+			expectedMatches: []SearchMatch{
+				{StartPos: 0, EndPos: 2},
+				{StartPos: 1, EndPos: 3},
+				{StartPos: 2, EndPos: 4},
+			},
+		},
+		{
+			name:     "match overlapping start of range",
+			text:     "foo bar foo",
+			query:    "foo",
+			startPos: 2,
+			endPos:   8,
+			expectedMatches: []SearchMatch{
+				{StartPos: 0, EndPos: 3},
+			},
+		},
+		{
+			name:     "match overlapping end of range",
+			text:     "foo bar foo",
+			query:    "foo",
+			startPos: 3,
+			endPos:   9,
+			expectedMatches: []SearchMatch{
+				{StartPos: 8, EndPos: 11},
+			},
+		},
+		{
+			name:     "match starting at end of range is excluded",
+			text:     "foo bar foo",
+			query:    "foo",
+			startPos: 3,
+			endPos:   8,
+		},
+		{
+			name:     "case-insensitive query",
+			text:     "Foo foo FOO",
+			query:    "foo",
+			startPos: 0,
+			endPos:   11,
+			expectedMatches: []SearchMatch{
+				{StartPos: 0, EndPos: 3},
+				{StartPos: 4, EndPos: 7},
+				{StartPos: 8, EndPos: 11},
+			},
+		},
+		{
+			name:     "case-sensitive query",
+			text:     "Foo foo FOO",
+			query:    "Foo",
+			startPos: 0,
+			endPos:   11,
+			expectedMatches: []SearchMatch{
+				{StartPos: 0, EndPos: 3},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.text)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.search.query = tc.query
+			matches := buffer.SearchMatchesInRange(tc.startPos, tc.endPos)
+			assert.Equal(t, tc.expectedMatches, matches)
+		})
+	}
+}
+
+func TestClearSearchHighlight(t *testing.T) {
+	textTree, err := text.NewTreeFromString("foo bar foo")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.hlSearch = true
+
+	// Highlighting is disabled until the search completes.
+	StartSearch(state, SearchDirectionForward)
+	for _, r := range "foo" {
+		AppendRuneToSearchQuery(state, r)
+	}
+	assert.False(t, state.SearchHighlightEnabled())
+	CompleteSearch(state, true)
+	assert.True(t, state.SearchHighlightEnabled())
+
+	// Clearing the highlight preserves the query.
+	ClearSearchHighlight(state)
+	assert.False(t, state.SearchHighlightEnabled())
+	assert.Equal(t, "foo", buffer.search.query)
+
+	// Finding the next match highlights matches again.
+	FindNextMatch(state, false)
+	assert.Equal(t, uint64(8), buffer.cursor.position)
+	assert.True(t, state.SearchHighlightEnabled())
+
+	// A new search also highlights matches again.
+	ClearSearchHighlight(state)
+	StartSearch(state, SearchDirectionForward)
+	AppendRuneToSearchQuery(state, 'b')
+	CompleteSearch(state, true)
+	assert.True(t, state.SearchHighlightEnabled())
+
+	// Nothing is highlighted if hlsearch is disabled.
+	buffer.hlSearch = false
+	assert.False(t, state.SearchHighlightEnabled())
+}
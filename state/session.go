@@ -0,0 +1,173 @@
+package state
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/file"
+	"github.com/aretext/aretext/locate"
+)
+
+// SaveSession writes the current document, cursor position, file timeline, alternate document,
+// and working directory to a session file, like vim's ":mksession".
+func SaveSession(state *EditorState, path string) {
+	path, err := validateSessionPath(path)
+	if err == nil {
+		err = saveSession(state, path)
+	}
+
+	if err != nil {
+		log.Printf("Error saving session to %q: %v\n", path, err)
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Could not save session: %s", errors.Cause(err)),
+		})
+		return
+	}
+
+	log.Printf("Saved session to %q\n", path)
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  fmt.Sprintf("Saved session to %s", file.RelativePathCwd(path)),
+	})
+}
+
+func saveSession(state *EditorState, path string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.Wrap(err, "os.Getwd")
+	}
+
+	return file.SaveSession(path, file.Session{
+		WorkingDir: cwd,
+		Current:    currentTimelineState(state),
+		Alternate:  state.alternateDocument,
+		Past:       state.fileTimeline.PastStates(),
+		Future:     state.fileTimeline.FutureStates(),
+	})
+}
+
+// LoadSession restores a session file written by SaveSession.
+// Documents that no longer exist are skipped with a warning.
+// If the current document from the session is missing, the most recent previous document is loaded instead.
+func LoadSession(state *EditorState, path string) {
+	path, err := validateSessionPath(path)
+	if err != nil {
+		reportLoadSessionError(state, err, path)
+		return
+	}
+
+	session, err := file.LoadSession(path)
+	if err != nil {
+		reportLoadSessionError(state, err, path)
+		return
+	}
+
+	var warnings []string
+	if session.WorkingDir != "" {
+		if err := os.Chdir(session.WorkingDir); err != nil {
+			log.Printf("Error changing working directory to %q: %v\n", session.WorkingDir, err)
+			warnings = append(warnings, "could not change working directory")
+		}
+	}
+
+	missingPaths := make(map[string]struct{})
+	past := existingTimelineStates(session.Past, missingPaths)
+	future := existingTimelineStates(session.Future, missingPaths)
+	alt := existingTimelineStates([]file.TimelineState{session.Alternate}, missingPaths)
+
+	current := session.Current
+	if len(existingTimelineStates([]file.TimelineState{current}, missingPaths)) == 0 {
+		if len(past) == 0 {
+			reportLoadSessionError(state, errors.New("no documents in the session exist"), path)
+			return
+		}
+		current, past = past[len(past)-1], past[:len(past)-1]
+	}
+
+	LoadDocument(state, current.Path, true, func(p LocatorParams) uint64 {
+		lineNum := locate.ClosestValidLineNum(p.TextTree, current.LineNum)
+		return locate.LineNumAndColToPos(p.TextTree, lineNum, current.Col)
+	})
+	if state.fileWatcher.Path() != current.Path {
+		// LoadDocument already reported the error.
+		return
+	}
+
+	// Replace the timeline and alternate document set by LoadDocument with the ones from the session.
+	state.fileTimeline.Restore(past, future)
+	state.alternateDocument = file.TimelineState{}
+	if len(alt) > 0 {
+		state.alternateDocument = alt[0]
+	}
+
+	if len(missingPaths) > 0 {
+		warnings = append(warnings, fmt.Sprintf("skipped %d missing documents", len(missingPaths)))
+	}
+
+	log.Printf("Loaded session from %q\n", path)
+	if len(warnings) > 0 {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Loaded session from %s, but %s", file.RelativePathCwd(path), strings.Join(warnings, " and ")),
+		})
+		return
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  fmt.Sprintf("Loaded session from %s", file.RelativePathCwd(path)),
+	})
+}
+
+// existingTimelineStates filters out empty states and states for files that do not exist,
+// adding the path of each missing file to missingPaths.
+func existingTimelineStates(states []file.TimelineState, missingPaths map[string]struct{}) []file.TimelineState {
+	result := make([]file.TimelineState, 0, len(states))
+	for _, s := range states {
+		if s.Empty() {
+			continue
+		}
+
+		if _, err := os.Stat(s.Path); err != nil {
+			log.Printf("Skipping session document %q: %v\n", s.Path, err)
+			missingPaths[s.Path] = struct{}{}
+			continue
+		}
+
+		result = append(result, s)
+	}
+	return result
+}
+
+func validateSessionPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", errors.New("no session file specified")
+	}
+
+	path, err := file.ExpandHomeDir(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve relative paths now, since loading a session can change the working directory.
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrap(err, "filepath.Abs")
+	}
+	return path, nil
+}
+
+func reportLoadSessionError(state *EditorState, err error, path string) {
+	log.Printf("Error loading session from %q: %v\n", path, err)
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleError,
+		Text:  fmt.Sprintf("Could not load session: %s", errors.Cause(err)),
+	})
+}
@@ -0,0 +1,123 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSessionDocuments loads a.txt, b.txt, and c.txt, then returns to b.txt,
+// so a.txt is in the past, c.txt is in the future and is the alternate document,
+// and the cursor is on the second line of b.txt.
+func setupSessionDocuments(t *testing.T, state *EditorState, dir string) {
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("abc\ndef\nghi"), 0644)
+		require.NoError(t, err)
+		LoadDocument(state, filepath.Join(dir, name), true, startOfDocLocator)
+	}
+	LoadPrevDocument(state)
+	MoveCursor(state, func(LocatorParams) uint64 { return 5 })
+}
+
+func TestSaveAndLoadSession(t *testing.T) {
+	withTempDirPaths(t, nil, func(dir string) {
+		state := NewEditorState(100, 100, nil, nil)
+		setupSessionDocuments(t, state, dir)
+
+		SaveSession(state, "session.json")
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "Saved session to session.json"}, state.StatusMsg())
+
+		// Restore the session from another working directory.
+		err := os.Mkdir("subdir", 0755)
+		require.NoError(t, err)
+		err = os.Chdir("subdir")
+		require.NoError(t, err)
+
+		restored := NewEditorState(100, 100, nil, nil)
+		LoadSession(restored, "../session.json")
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "Loaded session from session.json"}, restored.StatusMsg())
+		assert.Equal(t, filepath.Join(dir, "b.txt"), restored.fileWatcher.Path())
+		assert.Equal(t, uint64(5), restored.documentBuffer.cursor.position)
+		assert.Equal(t, filepath.Join(dir, "a.txt"), restored.fileTimeline.PeekBackward().Path)
+		assert.Equal(t, filepath.Join(dir, "c.txt"), restored.fileTimeline.PeekForward().Path)
+		assert.Equal(t, filepath.Join(dir, "c.txt"), restored.alternateDocument.Path)
+
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, dir, cwd)
+	})
+}
+
+func TestLoadSessionSkipsMissingDocuments(t *testing.T) {
+	withTempDirPaths(t, nil, func(dir string) {
+		state := NewEditorState(100, 100, nil, nil)
+		setupSessionDocuments(t, state, dir)
+		SaveSession(state, "session.json")
+
+		// Remove the current and next documents, so the session restores the previous document.
+		err := os.Remove(filepath.Join(dir, "b.txt"))
+		require.NoError(t, err)
+		err = os.Remove(filepath.Join(dir, "c.txt"))
+		require.NoError(t, err)
+
+		restored := NewEditorState(100, 100, nil, nil)
+		LoadSession(restored, "session.json")
+		assert.Equal(t, StatusMsgStyleError, restored.StatusMsg().Style)
+		assert.Equal(t, "Loaded session from session.json, but skipped 2 missing documents", restored.StatusMsg().Text)
+		assert.Equal(t, filepath.Join(dir, "a.txt"), restored.fileWatcher.Path())
+		assert.True(t, restored.fileTimeline.PeekBackward().Empty())
+		assert.True(t, restored.fileTimeline.PeekForward().Empty())
+		assert.True(t, restored.alternateDocument.Empty())
+	})
+}
+
+func TestLoadSessionErrors(t *testing.T) {
+	testCases := []struct {
+		name              string
+		path              string
+		contents          string
+		expectedStatusMsg string
+	}{
+		{
+			name:              "empty path",
+			path:              " ",
+			expectedStatusMsg: "Could not load session: no session file specified",
+		},
+		{
+			name:              "missing session file",
+			path:              "missing.json",
+			expectedStatusMsg: "no such file or directory",
+		},
+		{
+			name:              "invalid session file",
+			path:              "session.json",
+			contents:          "not json",
+			expectedStatusMsg: "Could not load session: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:              "no documents exist",
+			path:              "session.json",
+			contents:          `{"current": {"path": "/missing/a.txt"}, "past": [{"path": "/missing/b.txt"}]}`,
+			expectedStatusMsg: "Could not load session: no documents in the session exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withTempDirPaths(t, nil, func(dir string) {
+				if tc.contents != "" {
+					err := os.WriteFile(tc.path, []byte(tc.contents), 0644)
+					require.NoError(t, err)
+				}
+
+				state := NewEditorState(100, 100, nil, nil)
+				LoadSession(state, tc.path)
+				assert.Equal(t, StatusMsgStyleError, state.StatusMsg().Style)
+				assert.Contains(t, state.StatusMsg().Text, tc.expectedStatusMsg)
+			})
+		})
+	}
+}
@@ -82,6 +82,21 @@ func RunShellCmd(state *EditorState, shellCmd string, mode string) {
 			}
 		})
 
+	case config.CmdModeQuickfix:
+		StartTask(state, func(ctx context.Context) func(*EditorState) {
+			output, err := shellcmd.RunAndCaptureCombinedOutput(ctx, shellCmd, env)
+			return func(state *EditorState) {
+				if err == nil {
+					err = setQuickfixListFromShellCmdOutput(state, output)
+				}
+				if err != nil || len(state.quickfix.entries) == 0 {
+					setStatusForShellCmdResult(state, err)
+					return
+				}
+				GoToNextQuickfixEntry(state, false)
+			}
+		})
+
 	case config.CmdModeWorkingDir:
 		StartTask(state, func(ctx context.Context) func(*EditorState) {
 			output, err := shellcmd.RunAndCaptureOutput(ctx, shellCmd, env)
@@ -121,6 +136,9 @@ func envVars(state *EditorState) []string {
 	filePath := state.fileWatcher.Path()
 	env = append(env, fmt.Sprintf("FILEPATH=%s", filePath))
 
+	// $ALTFILEPATH is the path to the alternate file (the file open before the current one), if any.
+	env = append(env, fmt.Sprintf("ALTFILEPATH=%s", state.alternateDocument.Path))
+
 	// $WORD is the current word under the cursor (excluding whitespace).
 	currentWord := currentWordEnvVar(state)
 	env = append(env, fmt.Sprintf("WORD=%s", currentWord))
@@ -257,42 +275,29 @@ func showFileLocationsMenuForShellCmdOutput(state *EditorState, shellCmdOutput s
 		return errors.New("No file locations in cmd output")
 	}
 
-	menuItems, err := menuItemsFromFileLocations(locations)
+	entries, err := quickfixEntriesFromFileLocations(locations)
 	if err != nil {
 		return err
 	}
 
-	ShowMenu(state, MenuStyleFileLocation, menuItems)
+	SetQuickfixList(state, entries)
+	ShowQuickfixMenu(state)
 	return nil
 }
 
-func menuItemsFromFileLocations(locations []shellcmd.FileLocation) ([]menu.Item, error) {
-	cwd, err := os.Getwd()
+func setQuickfixListFromShellCmdOutput(state *EditorState, shellCmdOutput string) error {
+	diagnostics, err := shellcmd.DiagnosticsFromLines(strings.NewReader(shellCmdOutput))
 	if err != nil {
-		return nil, errors.Wrap(err, "os.Getwd")
+		return err
 	}
 
-	menuItems := make([]menu.Item, 0, len(locations))
-	for _, loc := range locations {
-		name := formatFileLocationName(loc)
-		path := absPath(loc.Path, cwd)
-		lineNum := translateFileLocationLineNum(loc.LineNum)
-		menuItems = append(menuItems, menu.Item{
-			Name: name,
-			Action: func(s *EditorState) {
-				AbortIfUnsavedChanges(s, func(s *EditorState) {
-					LoadDocument(s, path, true, func(p LocatorParams) uint64 {
-						return locate.StartOfLineNum(p.TextTree, lineNum)
-					})
-				}, true)
-			},
-		})
+	entries, err := quickfixEntriesFromDiagnostics(diagnostics)
+	if err != nil {
+		return err
 	}
-	return menuItems, nil
-}
 
-func formatFileLocationName(loc shellcmd.FileLocation) string {
-	return fmt.Sprintf("%s:%d  %s", loc.Path, loc.LineNum, loc.Snippet)
+	SetQuickfixList(state, entries)
+	return nil
 }
 
 func absPath(p, wd string) string {
@@ -57,6 +57,25 @@ func TestRunShellCmdFilePathEnvVar(t *testing.T) {
 	})
 }
 
+func TestRunShellCmdAltFilePathEnvVar(t *testing.T) {
+	setupShellCmdTest(t, func(state *EditorState, dir string) {
+		altFilePath := path.Join(dir, "test-alt.txt")
+		os.WriteFile(altFilePath, []byte("abc"), 0644)
+		LoadDocument(state, altFilePath, true, func(LocatorParams) uint64 { return 0 })
+
+		filePath := path.Join(dir, "test-input.txt")
+		os.WriteFile(filePath, []byte("xyz"), 0644)
+		LoadDocument(state, filePath, true, func(LocatorParams) uint64 { return 0 })
+
+		p := path.Join(dir, "test-output.txt")
+		cmd := fmt.Sprintf(`printenv ALTFILEPATH > %s`, p)
+		runShellCmdAndApplyAction(t, state, cmd, config.CmdModeSilent)
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		assert.Equal(t, altFilePath+"\n", string(data))
+	})
+}
+
 func TestRunShellCmdWordEnvVar(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -357,6 +376,29 @@ func TestRunShellCmdFileLocationsMenu(t *testing.T) {
 	})
 }
 
+func TestRunShellCmdQuickfix(t *testing.T) {
+	setupShellCmdTest(t, func(state *EditorState, dir string) {
+		p := path.Join(dir, "test-file.go")
+		err := os.WriteFile(p, []byte("ab\ncd\nef\ngh"), 0644)
+		require.NoError(t, err)
+
+		// The command exits with a failure status, like a linter that found problems.
+		// Lines that are not file locations, like the summary, are skipped.
+		cmd := fmt.Sprintf("printf '%s:3:2: first\\n%s:1: second\\nfound 2 problems\\n'; exit 1", p, p)
+		runShellCmdAndApplyAction(t, state, cmd, config.CmdModeQuickfix)
+
+		// Verify that the editor opens the first entry.
+		assert.Equal(t, p, state.fileWatcher.Path())
+		assert.Equal(t, uint64(7), state.documentBuffer.cursor.position)
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "(1 of 2) first"}, state.StatusMsg())
+
+		// Verify that the next entry is in the quickfix list.
+		GoToNextQuickfixEntry(state, false)
+		assert.Equal(t, uint64(0), state.documentBuffer.cursor.position)
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "(2 of 2) second"}, state.StatusMsg())
+	})
+}
+
 func TestRunShellCmdWorkingDirMenu(t *testing.T) {
 	setupShellCmdTest(t, func(state *EditorState, dir string) {
 		// Save the original working dir so we can restore it later.
@@ -0,0 +1,163 @@
+package state
+
+import (
+	"log"
+	"unicode/utf8"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/snippet"
+)
+
+// snippetSession tracks the tab stops of an expanded snippet while the user fills them in.
+// The session ends when the cursor reaches the final stop or the editor leaves insert mode.
+type snippetSession struct {
+	// stops are the positions in the document that the cursor has not yet left, in the order they are visited.
+	// The first stop is the one with the cursor, and the last stop is the final stop.
+	// This is empty if no snippet session is active.
+	stops []snippetStop
+
+	// replacePlaceholder is true if the cursor just moved to a stop with placeholder text.
+	// The next rune inserted at the start of the stop replaces the placeholder.
+	replacePlaceholder bool
+}
+
+// snippetStop is the range of a tab stop in the document.
+// The range covers the placeholder text or the text the user typed at the stop.
+type snippetStop struct {
+	startPos uint64
+	endPos   uint64
+}
+
+// ExpandSnippet replaces the word before the cursor with a snippet template if the word is a snippet trigger,
+// then moves the cursor to the template's first tab stop.
+// It returns true if a snippet was expanded.
+func ExpandSnippet(state *EditorState) bool {
+	if len(state.snippets) == 0 {
+		return false
+	}
+
+	word, wordStartPos := wordBeforeCursor(state)
+	if word == "" {
+		return false
+	}
+
+	templateText, ok := state.snippets[word]
+	if !ok {
+		return false
+	}
+
+	template, err := snippet.Parse(templateText)
+	if err != nil {
+		// This should never happen because the config validates snippet templates.
+		log.Printf("Error parsing snippet %q: %v\n", word, err)
+		return false
+	}
+
+	buffer := state.documentBuffer
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, wordStartPos)
+	indentEndPos := locate.NextNonWhitespaceOrNewline(buffer.textTree, lineStartPos)
+	if indentEndPos > wordStartPos {
+		indentEndPos = wordStartPos
+	}
+	indent := copyText(buffer.textTree, lineStartPos, indentEndPos-lineStartPos)
+	template = template.WithIndent(indent)
+
+	deleteRunes(state, wordStartPos, uint64(utf8.RuneCountInString(word)), true)
+	mustInsertTextAtPosition(state, template.Text, wordStartPos, true)
+
+	stops := make([]snippetStop, 0, len(template.Stops))
+	for _, stop := range template.Stops {
+		stops = append(stops, snippetStop{
+			startPos: wordStartPos + stop.StartOffset,
+			endPos:   wordStartPos + stop.EndOffset,
+		})
+	}
+	state.snippetSession = snippetSession{stops: stops}
+	moveToCurrentSnippetStop(state)
+	return true
+}
+
+// JumpToNextSnippetStop moves the cursor to the next tab stop of the active snippet.
+// It returns false if no snippet session is active.
+func JumpToNextSnippetStop(state *EditorState) bool {
+	if len(state.snippetSession.stops) == 0 {
+		return false
+	}
+	state.snippetSession.stops = state.snippetSession.stops[1:]
+	moveToCurrentSnippetStop(state)
+	return true
+}
+
+// moveToCurrentSnippetStop moves the cursor to the start of the first remaining stop.
+// If that is the final stop, the session ends.
+func moveToCurrentSnippetStop(state *EditorState) {
+	session := &state.snippetSession
+	stop := session.stops[0]
+	state.documentBuffer.cursor = cursorState{position: stop.startPos}
+	if len(session.stops) == 1 {
+		endSnippetSession(state)
+		return
+	}
+	session.replacePlaceholder = stop.endPos > stop.startPos
+}
+
+// endSnippetSession stops tracking tab stops, so tab inserts a tab again.
+func endSnippetSession(state *EditorState) {
+	state.snippetSession = snippetSession{}
+}
+
+// deleteSnippetPlaceholder deletes the placeholder text of the current tab stop
+// if the cursor has not moved since it jumped to the stop and nothing has been typed there yet.
+func deleteSnippetPlaceholder(state *EditorState) {
+	session := &state.snippetSession
+	if !session.replacePlaceholder {
+		return
+	}
+	session.replacePlaceholder = false
+	stop := session.stops[0]
+	if state.documentBuffer.cursor.position != stop.startPos {
+		return
+	}
+	deleteRunes(state, stop.startPos, stop.endPos-stop.startPos, true)
+}
+
+// adjustSnippetStopsForInsert updates tab stop positions after n runes were inserted at pos.
+// Text inserted at or within the current stop extends it, so typing at a stop grows the stop.
+func adjustSnippetStopsForInsert(state *EditorState, pos uint64, n uint64) {
+	session := &state.snippetSession
+	if len(session.stops) == 0 || n == 0 {
+		return
+	}
+	session.replacePlaceholder = false
+	for i := range session.stops {
+		stop := &session.stops[i]
+		if pos < stop.startPos || (pos == stop.startPos && i > 0) {
+			stop.startPos += n
+			stop.endPos += n
+		} else if pos <= stop.endPos {
+			stop.endPos += n
+		}
+	}
+}
+
+// adjustSnippetStopsForDelete updates tab stop positions after n runes were deleted at pos.
+func adjustSnippetStopsForDelete(state *EditorState, pos uint64, n uint64) {
+	session := &state.snippetSession
+	if len(session.stops) == 0 || n == 0 {
+		return
+	}
+	session.replacePlaceholder = false
+	adjust := func(p uint64) uint64 {
+		if p <= pos {
+			return p
+		} else if p < pos+n {
+			return pos
+		}
+		return p - n
+	}
+	for i := range session.stops {
+		stop := &session.stops[i]
+		stop.startPos = adjust(stop.startPos)
+		stop.endPos = adjust(stop.endPos)
+	}
+}
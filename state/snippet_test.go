@@ -0,0 +1,146 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestExpandSnippet(t *testing.T) {
+	snippets := map[string]string{
+		"fn":   "func ${1:name}($2) {\n\t$0\n}",
+		"todo": "TODO: ",
+	}
+
+	testCases := []struct {
+		name              string
+		inputString       string
+		cursorPos         uint64
+		expectExpanded    bool
+		expectedText      string
+		expectedCursorPos uint64
+		expectSession     bool
+	}{
+		{
+			name:              "empty document",
+			inputString:       "",
+			cursorPos:         0,
+			expectExpanded:    false,
+			expectedText:      "",
+			expectedCursorPos: 0,
+		},
+		{
+			name:              "not a trigger",
+			inputString:       "abc",
+			cursorPos:         3,
+			expectExpanded:    false,
+			expectedText:      "abc",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "trigger within a longer word",
+			inputString:       "xfn",
+			cursorPos:         3,
+			expectExpanded:    false,
+			expectedText:      "xfn",
+			expectedCursorPos: 3,
+		},
+		{
+			name:              "expand with tab stops",
+			inputString:       "fn",
+			cursorPos:         2,
+			expectExpanded:    true,
+			expectedText:      "func name() {\n\t\n}",
+			expectedCursorPos: 5,
+			expectSession:     true,
+		},
+		{
+			name:              "expand indented",
+			inputString:       "  fn",
+			cursorPos:         4,
+			expectExpanded:    true,
+			expectedText:      "  func name() {\n  \t\n  }",
+			expectedCursorPos: 7,
+			expectSession:     true,
+		},
+		{
+			name:              "expand with only final stop",
+			inputString:       "a todo",
+			cursorPos:         6,
+			expectExpanded:    true,
+			expectedText:      "a TODO: ",
+			expectedCursorPos: 8,
+			expectSession:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.snippets = snippets
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.cursorPos
+			expanded := ExpandSnippet(state)
+			assert.Equal(t, tc.expectExpanded, expanded)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursorPos, buffer.cursor.position)
+			assert.Equal(t, tc.expectSession, len(state.snippetSession.stops) > 0)
+		})
+	}
+}
+
+func TestSnippetSession(t *testing.T) {
+	textTree, err := text.NewTreeFromString("fn")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.snippets = map[string]string{"fn": "func ${1:name}(${2:args}) {\n\t$0\n}"}
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.cursor.position = 2
+	SetInputMode(state, InputModeInsert)
+
+	require.True(t, ExpandSnippet(state))
+	assert.Equal(t, "func name(args) {\n\t\n}", textTree.String())
+	assert.Equal(t, uint64(5), buffer.cursor.position)
+
+	// Typing at the stop replaces the placeholder.
+	InsertRune(state, 'f')
+	InsertRune(state, 'o')
+	InsertRune(state, 'o')
+	assert.Equal(t, "func foo(args) {\n\t\n}", textTree.String())
+
+	// The next stop moved with the inserted text.
+	require.True(t, JumpToNextSnippetStop(state))
+	assert.Equal(t, uint64(9), buffer.cursor.position)
+
+	// Moving the cursor away keeps the placeholder.
+	buffer.cursor.position = 0
+	InsertRune(state, 'x')
+	assert.Equal(t, "xfunc foo(args) {\n\t\n}", textTree.String())
+
+	// Jumping to the final stop ends the session.
+	require.True(t, JumpToNextSnippetStop(state))
+	assert.Equal(t, uint64(19), buffer.cursor.position)
+	assert.False(t, JumpToNextSnippetStop(state))
+}
+
+func TestSnippetSessionEndsWhenLeavingInsertMode(t *testing.T) {
+	textTree, err := text.NewTreeFromString("fn")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	state.snippets = map[string]string{"fn": "func $1() {\n\t$0\n}"}
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.cursor.position = 2
+	SetInputMode(state, InputModeInsert)
+
+	require.True(t, ExpandSnippet(state))
+	SetInputMode(state, InputModeNormal)
+	assert.False(t, JumpToNextSnippetStop(state))
+}
@@ -0,0 +1,219 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/text"
+)
+
+func TestSortLines(t *testing.T) {
+	testCases := []struct {
+		name         string
+		inputString  string
+		startLineNum uint64
+		endLineNum   uint64
+		opts         SortOptions
+		expectedText string
+	}{
+		{
+			name:         "empty document",
+			inputString:  "",
+			startLineNum: 0,
+			endLineNum:   0,
+			expectedText: "",
+		},
+		{
+			name:         "lexical",
+			inputString:  "c\na\nb",
+			startLineNum: 0,
+			endLineNum:   2,
+			expectedText: "a\nb\nc",
+		},
+		{
+			name:         "lexical ordering of numbers",
+			inputString:  "10\n9\n100\n-1",
+			startLineNum: 0,
+			endLineNum:   3,
+			expectedText: "-1\n10\n100\n9",
+		},
+		{
+			name:         "numeric ordering of numbers",
+			inputString:  "10\n9\n100\n-1",
+			startLineNum: 0,
+			endLineNum:   3,
+			opts:         SortOptions{Numeric: true},
+			expectedText: "-1\n9\n10\n100",
+		},
+		{
+			name:         "numeric uses first number in line",
+			inputString:  "item 20 of 3\nitem 3 of 20\nno number\nitem 100",
+			startLineNum: 0,
+			endLineNum:   3,
+			opts:         SortOptions{Numeric: true},
+			expectedText: "no number\nitem 3 of 20\nitem 20 of 3\nitem 100",
+		},
+		{
+			name:         "numeric is stable for equal numbers",
+			inputString:  "b 1\na 1\nc 0",
+			startLineNum: 0,
+			endLineNum:   2,
+			opts:         SortOptions{Numeric: true},
+			expectedText: "c 0\nb 1\na 1",
+		},
+		{
+			name:         "reverse",
+			inputString:  "a\nc\nb",
+			startLineNum: 0,
+			endLineNum:   2,
+			opts:         SortOptions{Reverse: true},
+			expectedText: "c\nb\na",
+		},
+		{
+			name:         "reverse numeric",
+			inputString:  "2\n10\n1",
+			startLineNum: 0,
+			endLineNum:   2,
+			opts:         SortOptions{Reverse: true, Numeric: true},
+			expectedText: "10\n2\n1",
+		},
+		{
+			name:         "case-sensitive",
+			inputString:  "b\nB\na\nA",
+			startLineNum: 0,
+			endLineNum:   3,
+			expectedText: "A\nB\na\nb",
+		},
+		{
+			name:         "ignore case",
+			inputString:  "b\nB\na\nA",
+			startLineNum: 0,
+			endLineNum:   3,
+			opts:         SortOptions{IgnoreCase: true},
+			expectedText: "a\nA\nb\nB",
+		},
+		{
+			name:         "unique",
+			inputString:  "b\na\nb\nc\na",
+			startLineNum: 0,
+			endLineNum:   4,
+			opts:         SortOptions{Unique: true},
+			expectedText: "a\nb\nc",
+		},
+		{
+			name:         "unique ignore case keeps first line",
+			inputString:  "B\na\nb\nA",
+			startLineNum: 0,
+			endLineNum:   3,
+			opts:         SortOptions{Unique: true, IgnoreCase: true},
+			expectedText: "a\nB",
+		},
+		{
+			name:         "unique numeric",
+			inputString:  "x 2\ny 1\nz 02",
+			startLineNum: 0,
+			endLineNum:   2,
+			opts:         SortOptions{Unique: true, Numeric: true},
+			expectedText: "y 1\nx 2",
+		},
+		{
+			name:         "subset of lines",
+			inputString:  "z\nc\nb\na\ny",
+			startLineNum: 1,
+			endLineNum:   3,
+			expectedText: "z\na\nb\nc\ny",
+		},
+		{
+			name:         "document ending with newline",
+			inputString:  "b\na\n",
+			startLineNum: 0,
+			endLineNum:   2,
+			expectedText: "\na\nb",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			SortLines(state, tc.startLineNum, tc.endLineNum, tc.opts)
+			assert.Equal(t, tc.expectedText, textTree.String())
+
+			// Sorting is a single undo operation.
+			Undo(state)
+			assert.Equal(t, tc.inputString, textTree.String())
+		})
+	}
+}
+
+func TestSortSelectedLines(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputString    string
+		selectionMode  selection.Mode
+		anchorPos      uint64
+		cursorPos      uint64
+		expectedText   string
+		expectedCursor uint64
+	}{
+		{
+			name:           "no selection sorts entire document",
+			inputString:    "c\nb\na",
+			selectionMode:  selection.ModeNone,
+			cursorPos:      2,
+			expectedText:   "a\nb\nc",
+			expectedCursor: 0,
+		},
+		{
+			name:           "linewise selection",
+			inputString:    "d\nc\nb\na",
+			selectionMode:  selection.ModeLine,
+			anchorPos:      2,
+			cursorPos:      4,
+			expectedText:   "d\nb\nc\na",
+			expectedCursor: 2,
+		},
+		{
+			name:           "charwise selection sorts every line in the selection",
+			inputString:    "d\nc\nb\na",
+			selectionMode:  selection.ModeChar,
+			anchorPos:      3,
+			cursorPos:      6,
+			expectedText:   "d\na\nb\nc",
+			expectedCursor: 2,
+		},
+		{
+			name:           "charwise selection ending on newline",
+			inputString:    "d\nc\nb\na",
+			selectionMode:  selection.ModeChar,
+			anchorPos:      0,
+			cursorPos:      3,
+			expectedText:   "c\nd\nb\na",
+			expectedCursor: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.cursor.position = tc.anchorPos
+			if tc.selectionMode != selection.ModeNone {
+				ToggleVisualMode(state, tc.selectionMode)
+			}
+			state.documentBuffer.cursor.position = tc.cursorPos
+			SortSelectedLines(state, SortOptions{})
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.expectedCursor, state.documentBuffer.cursor.position)
+			assert.Equal(t, InputModeNormal, state.InputMode())
+			assert.Equal(t, selection.ModeNone, state.documentBuffer.SelectionMode())
+		})
+	}
+}
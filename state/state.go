@@ -1,10 +1,13 @@
 package state
 
 import (
+	"time"
+
 	"github.com/aretext/aretext/cellwidth"
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/config"
 	"github.com/aretext/aretext/file"
+	"github.com/aretext/aretext/locate"
 	"github.com/aretext/aretext/menu"
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/syntax"
@@ -18,6 +21,8 @@ import (
 type EditorState struct {
 	screenWidth, screenHeight uint64
 	configRuleSet             config.RuleSet
+	projectConfigEnabled      bool
+	projectConfigErr          error // Error loading the project config for the current document, if any.
 	documentLoadCount         int
 	inputMode                 InputMode
 	prevInputMode             InputMode
@@ -25,18 +30,52 @@ type EditorState struct {
 	clipboard                 *clipboard.C
 	fileWatcher               *file.Watcher
 	fileTimeline              *file.Timeline
+	alternateDocument         file.TimelineState // Document open before the current one, if any.
 	menu                      *MenuState
 	task                      *TaskState
 	macroState                MacroState
+	undoGroupDepth            int // Greater than zero while undo checkpoints are suspended to group changes.
+	lastMatchChar             matchCharState
 	customMenuItems           []menu.Item
 	dirPatternsToHide         []string
+	insertArrowKeys           string
+	mouseEnabled              bool
+	hyperlinksEnabled         bool
+	cursorShapeNormal         string
+	cursorShapeInsert         string
+	cursorShapeVisual         string
+	showCmd                   bool
+	customDigraphs            map[string]string
+	abbreviations             map[string]string
+	snippets                  map[string]string
+	snippetSession            snippetSession
+	insertSession             insertSession
+	languageServer            languageServerState
+	quickfix                  quickfixState
+	globalOptions             map[string]any // Options set by the user while editing, which override the config for every document.
+	writeBackup               bool
+	backupOptions             file.BackupOptions
+	backupAbortOnError        bool
+	autoWrite                 bool
+	searchHighlightCleared    bool
 	styles                    map[string]config.StyleConfig
 	statusMsg                 StatusMsg
+	statusMsgHistory          []StatusMsgHistoryEntry
+	statusMsgTimeout          time.Duration
+	statusMsgExpireTime       time.Time // Zero if the status message should not be cleared automatically.
 	suspendScreenFunc         SuspendScreenFunc
+	events                    eventState
 	quitFlag                  bool
+	bellFlag                  bool // Set when the terminal should beep, and cleared once it has.
 }
 
+// NewEditorState constructs the state for an editor with the given screen size.
+// If suspendScreenFunc is nil, shell commands that take control of the terminal run without suspending a screen.
 func NewEditorState(screenWidth, screenHeight uint64, configRuleSet config.RuleSet, suspendScreenFunc SuspendScreenFunc) *EditorState {
+	if suspendScreenFunc == nil {
+		suspendScreenFunc = func(f func() error) error { return f() }
+	}
+
 	var documentBufferHeight uint64
 	if screenHeight > 0 {
 		// Leave one line for the status bar at the bottom.
@@ -63,6 +102,20 @@ func NewEditorState(screenWidth, screenHeight uint64, configRuleSet config.RuleS
 		showSpaces:     config.DefaultShowSpaces,
 		showTabs:       config.DefaultShowTabs,
 		autoIndent:     config.DefaultAutoIndent,
+		smartCase:      config.DefaultSmartCase,
+		wrapScan:       config.DefaultWrapScan,
+		hlSearch:       config.DefaultHlSearch,
+		textWidth:      uint64(config.DefaultTextWidth),
+		startOfLine:    config.DefaultStartOfLine,
+		matchPairs:     locate.DefaultDelimiterPairs,
+
+		lineNumSeparator:   ' ',
+		cursorLineNumAlign: config.DefaultCursorLineNumberAlign,
+
+		commitSubjectWidth: uint64(config.DefaultCommitSubjectWidth),
+		commitBodyWidth:    uint64(config.DefaultCommitBodyWidth),
+		dateFormat:         config.DefaultDateFormat,
+		timeFormat:         config.DefaultTimeFormat,
 	}
 
 	return &EditorState{
@@ -73,9 +126,15 @@ func NewEditorState(screenWidth, screenHeight uint64, configRuleSet config.RuleS
 		clipboard:         clipboard.New(),
 		fileWatcher:       file.NewEmptyWatcher(),
 		fileTimeline:      file.NewTimeline(),
+		quickfix:          quickfixState{index: -1},
 		menu:              &MenuState{},
 		customMenuItems:   nil,
 		dirPatternsToHide: nil,
+		insertArrowKeys:   config.DefaultInsertArrowKeys,
+		cursorShapeNormal: config.DefaultCursorShapeNormal,
+		cursorShapeInsert: config.DefaultCursorShapeInsert,
+		cursorShapeVisual: config.DefaultCursorShapeVisual,
+		showCmd:           config.DefaultShowCmd,
 		statusMsg:         StatusMsg{},
 		styles:            nil,
 		suspendScreenFunc: suspendScreenFunc,
@@ -95,6 +154,7 @@ func (s *EditorState) SetScreenSize(width, height uint64) {
 	s.screenHeight = height
 }
 
+// InputMode returns the current input mode.
 func (s *EditorState) InputMode() InputMode {
 	return s.inputMode
 }
@@ -122,10 +182,60 @@ func (s *EditorState) DirPatternsToHide() []string {
 	return s.dirPatternsToHide
 }
 
+func (s *EditorState) InsertArrowKeys() string {
+	return s.insertArrowKeys
+}
+
+func (s *EditorState) MouseEnabled() bool {
+	return s.mouseEnabled
+}
+
+// HyperlinksEnabled returns whether to render URLs and file locations as terminal hyperlinks.
+func (s *EditorState) HyperlinksEnabled() bool {
+	return s.hyperlinksEnabled
+}
+
+// ShowCmd returns whether to display the keys typed so far for an incomplete command.
+func (s *EditorState) ShowCmd() bool {
+	return s.showCmd
+}
+
+// CursorShape returns the configured shape of the terminal cursor for the current input mode, such as "block" or "bar".
+// Menu and search mode use the insert mode shape, since the user is typing text.
+func (s *EditorState) CursorShape() string {
+	switch s.inputMode {
+	case InputModeNormal:
+		return s.cursorShapeNormal
+	case InputModeInsert, InputModeMenu, InputModeSearch:
+		return s.cursorShapeInsert
+	case InputModeVisual:
+		return s.cursorShapeVisual
+	default:
+		return config.CursorShapeDefault
+	}
+}
+
 func (s *EditorState) StatusMsg() StatusMsg {
 	return s.statusMsg
 }
 
+// StatusMsgExpireTime returns when the status message should be cleared,
+// or the zero time if it should not be cleared automatically.
+func (s *EditorState) StatusMsgExpireTime() time.Time {
+	return s.statusMsgExpireTime
+}
+
+// YankHighlightExpireTime returns when the highlight of copied text should be cleared,
+// or the zero time if no text is highlighted.
+func (s *EditorState) YankHighlightExpireTime() time.Time {
+	return s.documentBuffer.yankHighlight.expireTime
+}
+
+// StatusMsgHistory returns recent status messages, from oldest to newest.
+func (s *EditorState) StatusMsgHistory() []StatusMsgHistoryEntry {
+	return s.statusMsgHistory
+}
+
 func (s *EditorState) Styles() map[string]config.StyleConfig {
 	return s.styles
 }
@@ -138,6 +248,10 @@ func (s *EditorState) QuitFlag() bool {
 	return s.quitFlag
 }
 
+func (s *EditorState) BellFlag() bool {
+	return s.bellFlag
+}
+
 // BufferState represents the current state of a text buffer.
 type BufferState struct {
 	textTree                *text.Tree
@@ -149,18 +263,53 @@ type BufferState struct {
 	syntaxLanguage          syntax.Language
 	syntaxParser            *parser.P
 	tabSize                 uint64
+	varTabStops             []uint64
 	tabExpand               bool
 	showTabs                bool
 	showSpaces              bool
 	autoIndent              bool
+	continueComments        bool
+	autoCloseTags           bool
 	showLineNum             bool
+	relativeLineNum         bool
+	lineNumSeparator        rune
+	cursorLineNumAlign      string
+	smartCase               bool
+	wrapScan                bool
+	hlSearch                bool
 	lineWrapAllowCharBreaks bool
+	virtualEdit             bool
+	startOfLine             bool
+	beepAtBoundary          bool
+	reselectAfterUndo       bool
+	matchPairs              []locate.DelimiterPair
+	matchWords              []locate.KeywordGroup
+	matchTags               bool
+	textWidth               uint64
+	colorColumns            []uint64
+	commitSubjectWidth      uint64
+	commitBodyWidth         uint64
+	dateFormat              string
+	timeFormat              string
+	highlightYank           bool
+	highlightYankDuration   time.Duration
+	yankHighlight           yankHighlightState
+	lastSelection           lastSelectionState
+	multiCursor             bool
+	extraCursors            []uint64 // Sorted positions of cursors other than the main cursor.
+	gitDiff                 gitDiffState
+	linter                  string
+	diagnostics             []Diagnostic // Sorted by line and column.
 }
 
 func (s *BufferState) TextTree() *text.Tree {
 	return s.textTree
 }
 
+func (s *BufferState) SyntaxLanguage() syntax.Language {
+	return s.syntaxLanguage
+}
+
 func (s *BufferState) SyntaxTokensIntersectingRange(startPos, endPos uint64) []parser.Token {
 	if s.syntaxParser == nil {
 		return nil
@@ -168,14 +317,38 @@ func (s *BufferState) SyntaxTokensIntersectingRange(startPos, endPos uint64) []p
 	return s.syntaxParser.TokensIntersectingRange(startPos, endPos)
 }
 
+// SyntaxTokenAtPosition returns the syntax token containing a position.
+// If there is no syntax parser or no token at the position, it returns the Token zero value.
+func (s *BufferState) SyntaxTokenAtPosition(pos uint64) parser.Token {
+	if s.syntaxParser == nil {
+		return parser.Token{}
+	}
+	return s.syntaxParser.TokenAtPosition(pos)
+}
+
 func (s *BufferState) CursorPosition() uint64 {
 	return s.cursor.position
 }
 
+// CursorVirtualOffset returns the number of cells between the cursor position and the column where the cursor is displayed.
+// This is non-zero only if virtual edit is enabled and the cursor is past the end of a line or in the middle of a tab.
+func (s *BufferState) CursorVirtualOffset() uint64 {
+	if !s.virtualEdit {
+		return 0
+	}
+	return s.cursor.logicalOffset
+}
+
 func (s *BufferState) SelectedRegion() selection.Region {
 	return s.selector.Region(s.textTree, s.cursor.position)
 }
 
+// YankHighlight returns the region of recently copied text to highlight.
+// The region is empty if nothing is highlighted.
+func (s *BufferState) YankHighlight() selection.Region {
+	return s.yankHighlight.region
+}
+
 func (s *BufferState) SelectionMode() selection.Mode {
 	return s.selector.Mode()
 }
@@ -204,6 +377,11 @@ func (s *BufferState) SearchMatch() *SearchMatch {
 	return s.search.match
 }
 
+// UndoTree returns a read-only copy of the undo tree for the document.
+func (s *BufferState) UndoTree() undo.Node {
+	return s.undoLog.Tree()
+}
+
 func (s *BufferState) SetViewSize(width, height uint64) {
 	s.view.width = width
 	s.view.height = height
@@ -213,6 +391,15 @@ func (s *BufferState) TabSize() uint64 {
 	return s.tabSize
 }
 
+func (s *BufferState) VarTabStops() []uint64 {
+	return s.varTabStops
+}
+
+// ColorColumns returns the zero-indexed columns to highlight, in cells from the start of the line.
+func (s *BufferState) ColorColumns() []uint64 {
+	return s.colorColumns
+}
+
 func (s *BufferState) ShowTabs() bool {
 	return s.showTabs
 }
@@ -221,6 +408,21 @@ func (s *BufferState) ShowSpaces() bool {
 	return s.showSpaces
 }
 
+// RelativeLineNums returns whether line numbers show the distance from the cursor's line.
+func (s *BufferState) RelativeLineNums() bool {
+	return s.relativeLineNum
+}
+
+// LineNumSeparator returns the character drawn between the line numbers and the document text.
+func (s *BufferState) LineNumSeparator() rune {
+	return s.lineNumSeparator
+}
+
+// CursorLineNumAlign returns the alignment of the cursor's line number when line numbers are relative.
+func (s *BufferState) CursorLineNumAlign() string {
+	return s.cursorLineNumAlign
+}
+
 func (s *BufferState) LineNumMarginWidth() uint64 {
 	if !s.showLineNum {
 		return 0
@@ -247,19 +449,49 @@ func (s *BufferState) LineNumMarginWidth() uint64 {
 	return width
 }
 
+// SignColumnWidth returns the width of the column used to display git diff and diagnostic signs.
+// This is zero if the document is not tracked in a git repository and has no diagnostics.
+func (s *BufferState) SignColumnWidth() uint64 {
+	if s.gitDiff.headText == nil && len(s.diagnostics) == 0 {
+		return 0
+	}
+
+	// Collapse the sign column if there isn't enough
+	// space for at least one column of document text.
+	if s.LineNumMarginWidth()+1 >= s.view.width {
+		return 0
+	}
+
+	return 1
+}
+
+// GitDiffSignForLine returns the git diff sign to display for a line.
+func (s *BufferState) GitDiffSignForLine(lineNum uint64) GitDiffSign {
+	return s.gitDiff.signs[lineNum]
+}
+
 func (s *BufferState) LineWrapConfig() segment.LineWrapConfig {
-	width := s.view.width - s.LineNumMarginWidth()
-	tabSize := s.tabSize
-	gcWidthFunc := func(gc []rune, offsetInLine uint64) uint64 {
-		return cellwidth.GraphemeClusterWidth(gc, offsetInLine, tabSize)
+	width := s.view.width - s.LineNumMarginWidth() - s.SignColumnWidth()
+	if width == 0 {
+		// The terminal may be resized to zero width, but wrapping requires at least one column.
+		width = 1
 	}
 	return segment.LineWrapConfig{
 		MaxLineWidth:    width,
-		WidthFunc:       gcWidthFunc,
+		WidthFunc:       s.gcWidthFunc(),
 		AllowCharBreaks: s.lineWrapAllowCharBreaks,
 	}
 }
 
+// gcWidthFunc returns a function that calculates the width of a grapheme cluster
+// using the tab stops configured for this buffer.
+func (s *BufferState) gcWidthFunc() segment.GraphemeClusterWidthFunc {
+	tabSize, varTabStops := s.tabSize, s.varTabStops
+	return func(gc []rune, offsetInLine uint64) uint64 {
+		return cellwidth.GraphemeClusterWidthWithTabStops(gc, offsetInLine, tabSize, varTabStops)
+	}
+}
+
 // viewState represents the current view of the document.
 type viewState struct {
 	// textOrigin is the location in the text tree of the first visible character.
@@ -1,5 +1,16 @@
 package state
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/aretext/aretext/menu"
+)
+
+// maxStatusMsgHistory is the maximum number of status messages retained in the history.
+// When the history is full, the oldest message is dropped.
+const maxStatusMsgHistory = 100
+
 // StatusMsgStyle controls how a status message will be displayed.
 type StatusMsgStyle int
 
@@ -8,6 +19,12 @@ const (
 	StatusMsgStyleError
 )
 
+// ClearAfterTimeout returns whether messages with this style are cleared after the configured timeout.
+// Error messages remain until replaced, so the user doesn't miss them.
+func (s StatusMsgStyle) ClearAfterTimeout() bool {
+	return s == StatusMsgStyleSuccess
+}
+
 func (s StatusMsgStyle) String() string {
 	switch s {
 	case StatusMsgStyleSuccess:
@@ -25,7 +42,62 @@ type StatusMsg struct {
 	Text  string
 }
 
+// StatusMsgHistoryEntry is a status message recorded in the history.
+type StatusMsgHistoryEntry struct {
+	StatusMsg
+	Time time.Time
+}
+
 // SetStatusMsg sets the message displayed in the status bar.
+// Non-empty messages are also recorded in the status message history.
 func SetStatusMsg(state *EditorState, statusMsg StatusMsg) {
+	now := time.Now()
 	state.statusMsg = statusMsg
+	state.statusMsgExpireTime = time.Time{}
+	if statusMsg.Text == "" {
+		return
+	}
+
+	if state.statusMsgTimeout > 0 && statusMsg.Style.ClearAfterTimeout() {
+		state.statusMsgExpireTime = now.Add(state.statusMsgTimeout)
+	}
+
+	addToStatusMsgHistory(state, StatusMsgHistoryEntry{
+		StatusMsg: statusMsg,
+		Time:      now,
+	})
+}
+
+// ClearStatusMsgIfExpired clears the status message if its timeout elapsed before the given time.
+func ClearStatusMsgIfExpired(state *EditorState, now time.Time) {
+	if state.statusMsgExpireTime.IsZero() || now.Before(state.statusMsgExpireTime) {
+		return
+	}
+	state.statusMsg = StatusMsg{}
+	state.statusMsgExpireTime = time.Time{}
+}
+
+func addToStatusMsgHistory(state *EditorState, entry StatusMsgHistoryEntry) {
+	if len(state.statusMsgHistory) < maxStatusMsgHistory {
+		state.statusMsgHistory = append(state.statusMsgHistory, entry)
+		return
+	}
+
+	// Drop the oldest message, reusing the slice to avoid reallocating.
+	copy(state.statusMsgHistory, state.statusMsgHistory[1:])
+	state.statusMsgHistory[len(state.statusMsgHistory)-1] = entry
+}
+
+// ShowStatusMsgHistory displays recent status messages in the menu, with the newest message first.
+// This is useful for reviewing messages that were replaced before the user could read them.
+func ShowStatusMsgHistory(state *EditorState) {
+	items := make([]menu.Item, 0, len(state.statusMsgHistory))
+	for i := len(state.statusMsgHistory) - 1; i >= 0; i-- {
+		entry := state.statusMsgHistory[i]
+		items = append(items, menu.Item{
+			Name:   fmt.Sprintf("%s [%s] %s", entry.Time.Format("15:04:05"), entry.Style, entry.Text),
+			Action: func(*EditorState) {},
+		})
+	}
+	ShowMenu(state, MenuStyleStatusMsgHistory, items)
 }
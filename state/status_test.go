@@ -0,0 +1,128 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusMsgHistory(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleSuccess, Text: "first"})
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleError, Text: "second"})
+	SetStatusMsg(state, StatusMsg{}) // Clearing the status message isn't recorded.
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleSuccess, Text: "third"})
+
+	history := state.StatusMsgHistory()
+	require.Equal(t, 3, len(history))
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "first"}, history[0].StatusMsg)
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "second"}, history[1].StatusMsg)
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: "third"}, history[2].StatusMsg)
+	for _, entry := range history {
+		assert.False(t, entry.Time.IsZero())
+	}
+}
+
+func TestStatusMsgHistoryDropsOldest(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	for i := 0; i < maxStatusMsgHistory+5; i++ {
+		SetStatusMsg(state, StatusMsg{Text: fmt.Sprintf("msg %d", i)})
+	}
+
+	history := state.StatusMsgHistory()
+	require.Equal(t, maxStatusMsgHistory, len(history))
+	assert.Equal(t, "msg 5", history[0].Text)
+	assert.Equal(t, fmt.Sprintf("msg %d", maxStatusMsgHistory+4), history[len(history)-1].Text)
+}
+
+func TestShowStatusMsgHistory(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleSuccess, Text: "first"})
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleError, Text: "second"})
+	ShowStatusMsgHistory(state)
+
+	assert.True(t, state.Menu().Visible())
+	assert.Equal(t, MenuStyleStatusMsgHistory, state.Menu().Style())
+
+	results, _ := state.Menu().SearchResults()
+	require.Equal(t, 2, len(results))
+	assert.Regexp(t, `^\d\d:\d\d:\d\d \[error\] second$`, results[0].Name)
+	assert.Regexp(t, `^\d\d:\d\d:\d\d \[success\] first$`, results[1].Name)
+
+	// Selecting a message closes the menu without changing the status message.
+	ExecuteSelectedMenuItem(state)
+	assert.False(t, state.Menu().Visible())
+	assert.Equal(t, StatusMsg{Style: StatusMsgStyleError, Text: "second"}, state.StatusMsg())
+}
+
+func TestStatusMsgTimeout(t *testing.T) {
+	testCases := []struct {
+		name            string
+		timeout         time.Duration
+		statusMsg       StatusMsg
+		expectExpire    bool
+		expectedCleared bool
+	}{
+		{
+			name:            "no timeout",
+			timeout:         0,
+			statusMsg:       StatusMsg{Style: StatusMsgStyleSuccess, Text: "ok"},
+			expectExpire:    false,
+			expectedCleared: false,
+		},
+		{
+			name:            "success message with timeout",
+			timeout:         time.Second,
+			statusMsg:       StatusMsg{Style: StatusMsgStyleSuccess, Text: "ok"},
+			expectExpire:    true,
+			expectedCleared: true,
+		},
+		{
+			name:            "error message with timeout",
+			timeout:         time.Second,
+			statusMsg:       StatusMsg{Style: StatusMsgStyleError, Text: "failed"},
+			expectExpire:    false,
+			expectedCleared: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := NewEditorState(100, 100, nil, nil)
+			state.statusMsgTimeout = tc.timeout
+			SetStatusMsg(state, tc.statusMsg)
+			expireTime := state.StatusMsgExpireTime()
+			assert.Equal(t, tc.expectExpire, !expireTime.IsZero())
+
+			// Not yet expired.
+			ClearStatusMsgIfExpired(state, time.Now())
+			assert.Equal(t, tc.statusMsg, state.StatusMsg())
+
+			// After the timeout elapses.
+			ClearStatusMsgIfExpired(state, time.Now().Add(tc.timeout+time.Second))
+			if tc.expectedCleared {
+				assert.Equal(t, StatusMsg{}, state.StatusMsg())
+				assert.True(t, state.StatusMsgExpireTime().IsZero())
+			} else {
+				assert.Equal(t, tc.statusMsg, state.StatusMsg())
+			}
+		})
+	}
+}
+
+func TestStatusMsgTimeoutReplacedByError(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	state.statusMsgTimeout = time.Second
+	SetStatusMsg(state, StatusMsg{Style: StatusMsgStyleSuccess, Text: "ok"})
+	assert.False(t, state.StatusMsgExpireTime().IsZero())
+
+	errMsg := StatusMsg{Style: StatusMsgStyleError, Text: "failed"}
+	SetStatusMsg(state, errMsg)
+	assert.True(t, state.StatusMsgExpireTime().IsZero())
+
+	ClearStatusMsgIfExpired(state, time.Now().Add(time.Minute))
+	assert.Equal(t, errMsg, state.StatusMsg())
+}
@@ -1,10 +1,16 @@
 package state
 
 import (
+	"fmt"
+	"unicode/utf8"
+
 	"github.com/aretext/aretext/syntax"
 	"github.com/aretext/aretext/syntax/parser"
 )
 
+// maxInspectTokenTextLen is the maximum number of runes of token text to show in the status bar.
+const maxInspectTokenTextLen = 32
+
 // SetSyntax sets the syntax language for the current document.
 func SetSyntax(state *EditorState, language syntax.Language) {
 	setSyntaxAndRetokenize(state.documentBuffer, language)
@@ -31,3 +37,37 @@ func retokenizeAfterEdit(buffer *BufferState, edit parser.Edit) {
 
 	buffer.syntaxParser.ReparseAfterEdit(buffer.textTree, edit)
 }
+
+// InspectSyntaxToken shows the role and byte range of the syntax token under the cursor in the status bar.
+// This helps explain why the text at the cursor is highlighted the way it is.
+func InspectSyntaxToken(state *EditorState) {
+	buffer := state.documentBuffer
+	if buffer.syntaxParser == nil {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("No syntax parser for language %q", buffer.syntaxLanguage),
+		})
+		return
+	}
+
+	token := buffer.SyntaxTokenAtPosition(buffer.cursor.position)
+	if token.EndPos <= token.StartPos {
+		SetStatusMsg(state, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  "No syntax token at cursor",
+		})
+		return
+	}
+
+	tokenText := copyText(buffer.textTree, token.StartPos, token.EndPos-token.StartPos)
+	startByte := byteOffsetForPosition(buffer, token.StartPos)
+	endByte := startByte + uint64(len(tokenText))
+	if utf8.RuneCountInString(tokenText) > maxInspectTokenTextLen {
+		tokenText = string([]rune(tokenText)[:maxInspectTokenTextLen]) + "..."
+	}
+
+	SetStatusMsg(state, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  fmt.Sprintf("Syntax token %s %q at bytes %d-%d", token.Role, tokenText, startByte, endByte),
+	})
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/syntax"
+	"github.com/aretext/aretext/syntax/parser"
+	"github.com/aretext/aretext/text"
+)
+
+func TestSyntaxTokenAtPosition(t *testing.T) {
+	textTree, err := text.NewTreeFromString(`{"key": 123}`)
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	assert.Equal(t, parser.Token{}, buffer.SyntaxTokenAtPosition(1))
+
+	SetSyntax(state, syntax.LanguageJson)
+	assert.Equal(t, parser.Token{Role: parser.TokenRoleCustom1, StartPos: 1, EndPos: 7}, buffer.SyntaxTokenAtPosition(1))
+	assert.Equal(t, parser.Token{Role: parser.TokenRoleNumber, StartPos: 8, EndPos: 11}, buffer.SyntaxTokenAtPosition(9))
+	assert.Equal(t, parser.Token{}, buffer.SyntaxTokenAtPosition(0))
+}
+
+func TestInspectSyntaxToken(t *testing.T) {
+	testCases := []struct {
+		name        string
+		inputString string
+		language    syntax.Language
+		cursorPos   uint64
+		expectedMsg StatusMsg
+	}{
+		{
+			name:        "no parser",
+			inputString: "abc",
+			language:    syntax.LanguagePlaintext,
+			expectedMsg: StatusMsg{Style: StatusMsgStyleError, Text: `No syntax parser for language "plaintext"`},
+		},
+		{
+			name:        "no token at cursor",
+			inputString: `{"a": 1}`,
+			language:    syntax.LanguageJson,
+			cursorPos:   0,
+			expectedMsg: StatusMsg{Style: StatusMsgStyleError, Text: "No syntax token at cursor"},
+		},
+		{
+			name:        "number token",
+			inputString: `{"a": 123}`,
+			language:    syntax.LanguageJson,
+			cursorPos:   7,
+			expectedMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: `Syntax token number "123" at bytes 6-9`},
+		},
+		{
+			name:        "multi-byte characters",
+			inputString: `{"ü": "√x"}`,
+			language:    syntax.LanguageJson,
+			cursorPos:   7,
+			expectedMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: `Syntax token string "\"√x\"" at bytes 7-13`},
+		},
+		{
+			name:        "long token",
+			inputString: `{"a": "abcdefghijklmnopqrstuvwxyz0123456789"}`,
+			language:    syntax.LanguageJson,
+			cursorPos:   10,
+			expectedMsg: StatusMsg{Style: StatusMsgStyleSuccess, Text: `Syntax token string "\"abcdefghijklmnopqrstuvwxyz01234..." at bytes 6-44`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			SetSyntax(state, tc.language)
+			state.documentBuffer.cursor.position = tc.cursorPos
+			InspectSyntaxToken(state)
+			assert.Equal(t, tc.expectedMsg, state.StatusMsg())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package state
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/aretext/aretext/text"
+)
+
+// maxOpenTagLen is the maximum number of runes to search backwards for the start of a tag.
+const maxOpenTagLen = 1024
+
+// htmlVoidElements are HTML elements that never have a closing tag.
+var htmlVoidElements = map[string]struct{}{
+	"area":   {},
+	"base":   {},
+	"br":     {},
+	"col":    {},
+	"embed":  {},
+	"hr":     {},
+	"img":    {},
+	"input":  {},
+	"link":   {},
+	"meta":   {},
+	"param":  {},
+	"source": {},
+	"track":  {},
+	"wbr":    {},
+}
+
+// AutoCloseTag inserts a closing tag after the cursor if the rune before the cursor is a ">" that ends an HTML or XML opening tag.
+// Insert mode calls this after inserting ">", so typing "<div>" produces "<div></div>" with the cursor between the tags.
+// This does nothing for self-closing tags like "<br/>", void elements like "<br>",
+// or a ">" in an attribute value or comment.
+func AutoCloseTag(state *EditorState) {
+	buffer := state.documentBuffer
+	if !buffer.autoCloseTags {
+		return
+	}
+
+	cursorPos := buffer.cursor.position
+	tagName := openTagNameEndingAt(buffer.textTree, cursorPos)
+	if tagName == "" {
+		return
+	}
+
+	if _, ok := htmlVoidElements[strings.ToLower(tagName)]; ok {
+		return
+	}
+
+	mustInsertTextAtPosition(state, "</"+tagName+">", cursorPos, true)
+	buffer.cursor = cursorState{position: cursorPos}
+}
+
+// openTagNameEndingAt returns the name of the opening tag that ends with the ">" immediately before pos.
+// If the text before pos is not the end of an opening tag, this returns an empty string.
+func openTagNameEndingAt(tree *text.Tree, pos uint64) string {
+	// Search backwards for the "<" that starts the tag.
+	reader := tree.ReverseReaderAtPosition(pos)
+	var runes []rune
+	for len(runes) < maxOpenTagLen {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return ""
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		runes = append(runes, r)
+		if r == '<' {
+			break
+		}
+	}
+
+	if len(runes) < 3 || runes[0] != '>' || runes[len(runes)-1] != '<' {
+		return ""
+	}
+
+	// The runes were read in reverse order.
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	tagName := parseOpenTag(runes)
+	if tagName == "" || insideHTMLComment(tree, pos-uint64(len(runes))) {
+		return ""
+	}
+	return tagName
+}
+
+// parseOpenTag returns the name of the opening tag in runes, which start with "<" and end with ">".
+// It returns an empty string for closing tags, self-closing tags, comments, declarations, processing instructions,
+// and when the final ">" is inside a quoted attribute value or another ">" ends the tag earlier.
+func parseOpenTag(runes []rune) string {
+	i := 1
+	for i < len(runes) && isTagNameRune(runes[i]) {
+		if i == 1 && !unicode.IsLetter(runes[i]) {
+			return ""
+		}
+		i++
+	}
+	tagName := string(runes[1:i])
+	if tagName == "" {
+		return ""
+	}
+
+	var quote rune
+	for ; i < len(runes)-1; i++ {
+		r := runes[i]
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+		} else if r == '"' || r == '\'' {
+			quote = r
+		} else if r == '>' {
+			return ""
+		}
+	}
+
+	if quote != 0 || runes[len(runes)-2] == '/' {
+		return ""
+	}
+
+	return tagName
+}
+
+func isTagNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':' || r == '.'
+}
+
+// insideHTMLComment returns whether pos is after a "<!--" that has not been closed by "-->".
+func insideHTMLComment(tree *text.Tree, pos uint64) bool {
+	// Read backwards, so "<!--" appears as "--!<" and "-->" appears as ">--".
+	reader := tree.ReverseReaderAtPosition(pos)
+	var last [4]rune
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			return false
+		} else if err != nil {
+			panic(err) // should never happen because the text is valid UTF-8.
+		}
+
+		last[0], last[1], last[2], last[3] = last[1], last[2], last[3], r
+		if last[1] == '>' && last[2] == '-' && last[3] == '-' {
+			return false
+		}
+		if last == [4]rune{'-', '-', '!', '<'} {
+			return true
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/text"
+)
+
+func TestAutoCloseTag(t *testing.T) {
+	testCases := []struct {
+		name          string
+		inputString   string
+		cursorPos     uint64
+		autoCloseTags bool
+		expectedText  string
+	}{
+		{
+			name:          "disabled",
+			inputString:   "<div>",
+			cursorPos:     5,
+			autoCloseTags: false,
+			expectedText:  "<div>",
+		},
+		{
+			name:          "open tag",
+			inputString:   "<div>",
+			cursorPos:     5,
+			autoCloseTags: true,
+			expectedText:  "<div></div>",
+		},
+		{
+			name:          "open tag with attributes",
+			inputString:   `<a href="x>y" class='z'>`,
+			cursorPos:     24,
+			autoCloseTags: true,
+			expectedText:  `<a href="x>y" class='z'></a>`,
+		},
+		{
+			name:          "open tag across lines",
+			inputString:   "<div\n  id=\"x\">",
+			cursorPos:     14,
+			autoCloseTags: true,
+			expectedText:  "<div\n  id=\"x\"></div>",
+		},
+		{
+			name:          "nested tag",
+			inputString:   "<ul><li></ul>",
+			cursorPos:     8,
+			autoCloseTags: true,
+			expectedText:  "<ul><li></li></ul>",
+		},
+		{
+			name:          "xml namespaced tag",
+			inputString:   "<svg:rect>",
+			cursorPos:     10,
+			autoCloseTags: true,
+			expectedText:  "<svg:rect></svg:rect>",
+		},
+		{
+			name:          "void element",
+			inputString:   "<br>",
+			cursorPos:     4,
+			autoCloseTags: true,
+			expectedText:  "<br>",
+		},
+		{
+			name:          "void element uppercase",
+			inputString:   `<IMG src="a.png">`,
+			cursorPos:     17,
+			autoCloseTags: true,
+			expectedText:  `<IMG src="a.png">`,
+		},
+		{
+			name:          "self-closing tag",
+			inputString:   "<item/>",
+			cursorPos:     7,
+			autoCloseTags: true,
+			expectedText:  "<item/>",
+		},
+		{
+			name:          "closing tag",
+			inputString:   "<p></p>",
+			cursorPos:     7,
+			autoCloseTags: true,
+			expectedText:  "<p></p>",
+		},
+		{
+			name:          "inside attribute value",
+			inputString:   `<a title="x>`,
+			cursorPos:     12,
+			autoCloseTags: true,
+			expectedText:  `<a title="x>`,
+		},
+		{
+			name:          "greater than in text",
+			inputString:   "<p>a >",
+			cursorPos:     6,
+			autoCloseTags: true,
+			expectedText:  "<p>a >",
+		},
+		{
+			name:          "inside comment",
+			inputString:   "<!-- <div>",
+			cursorPos:     10,
+			autoCloseTags: true,
+			expectedText:  "<!-- <div>",
+		},
+		{
+			name:          "after comment",
+			inputString:   "<!-- x --><div>",
+			cursorPos:     15,
+			autoCloseTags: true,
+			expectedText:  "<!-- x --><div></div>",
+		},
+		{
+			name:          "comment end",
+			inputString:   "<!-- x -->",
+			cursorPos:     10,
+			autoCloseTags: true,
+			expectedText:  "<!-- x -->",
+		},
+		{
+			name:          "doctype",
+			inputString:   "<!DOCTYPE html>",
+			cursorPos:     15,
+			autoCloseTags: true,
+			expectedText:  "<!DOCTYPE html>",
+		},
+		{
+			name:          "processing instruction",
+			inputString:   `<?xml version="1.0"?>`,
+			cursorPos:     21,
+			autoCloseTags: true,
+			expectedText:  `<?xml version="1.0"?>`,
+		},
+		{
+			name:          "empty tag",
+			inputString:   "<>",
+			cursorPos:     2,
+			autoCloseTags: true,
+			expectedText:  "<>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.cursorPos
+			buffer.autoCloseTags = tc.autoCloseTags
+			AutoCloseTag(state)
+			assert.Equal(t, tc.expectedText, textTree.String())
+			assert.Equal(t, tc.cursorPos, buffer.cursor.position)
+		})
+	}
+}
@@ -1,60 +1,133 @@
 package state
 
 import (
+	"fmt"
 	"log"
 	"math"
+	"strings"
 
 	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/undo"
 )
 
 // CheckpointUndoLog sets a checkpoint at the current position in the undo log.
+// This does nothing while changes are grouped by GroupUndo.
 func CheckpointUndoLog(state *EditorState) {
+	if state.undoGroupDepth > 0 {
+		return
+	}
 	state.documentBuffer.undoLog.Checkpoint()
 }
 
+// GroupUndo executes f with undo checkpoints suspended, so the next undo reverts every change f made.
+func GroupUndo(state *EditorState, f func(*EditorState)) {
+	CheckpointUndoLog(state)
+	state.undoGroupDepth++
+	f(state)
+	state.undoGroupDepth--
+	CheckpointUndoLog(state)
+}
+
 // Undo returns the document to its state at the last undo checkpoint.
 func Undo(state *EditorState) {
 	ops := state.documentBuffer.undoLog.UndoToLastCheckpoint()
-	if len(ops) == 0 {
-		return
+	applyOpsFromUndoLog(state, ops, "Undo")
+}
+
+// Redo reverses the last undo operation.
+func Redo(state *EditorState) {
+	ops := state.documentBuffer.undoLog.RedoToNextCheckpoint()
+	applyOpsFromUndoLog(state, ops, "Redo")
+}
+
+// UndoEarlier returns the document to the state created before the current state, like vim's "g-".
+// Unlike undo, this may move to a state on a different branch of the undo tree.
+func UndoEarlier(state *EditorState, count uint64) {
+	moveToUndoState(state, state.documentBuffer.undoLog.CurrentSeq()-int(count))
+}
+
+// UndoLater moves the document to the state created after the current state, like vim's "g+".
+// Unlike redo, this may move to a state on a different branch of the undo tree.
+func UndoLater(state *EditorState, count uint64) {
+	moveToUndoState(state, state.documentBuffer.undoLog.CurrentSeq()+int(count))
+}
+
+func moveToUndoState(state *EditorState, seq int) {
+	undoLog := state.documentBuffer.undoLog
+	if seq < 0 {
+		seq = 0
+	} else if maxSeq := undoLog.NumStates() - 1; seq > maxSeq {
+		seq = maxSeq
 	}
+	ops := undoLog.MoveToState(seq)
+	applyOpsFromUndoLog(state, ops, "Undo tree")
+}
 
-	minPos := uint64(math.MaxUint64)
-	for _, op := range ops {
-		log.Printf("Undo operation: %#v\n", op)
-		if err := applyOpFromUndoLog(state, op); err != nil {
-			log.Printf("Could not apply undo op %v: %v\n", op, err)
-			continue
+// ShowUndoListMenu displays a menu listing the last state in each branch of the undo tree, like vim's ":undolist".
+// Selecting an item moves the document to that state.
+func ShowUndoListMenu(state *EditorState) {
+	leaves := state.documentBuffer.undoLog.Tree().Leaves()
+	items := make([]menu.Item, 0, len(leaves))
+	for i := len(leaves) - 1; i >= 0; i-- {
+		leaf := leaves[i] // reference the leaf in this iteration of the loop
+		changes := "changes"
+		if leaf.Depth == 1 {
+			changes = "change"
 		}
-		if pos := op.Position(); pos < minPos {
-			minPos = pos
+		name := fmt.Sprintf("state %d  (%d %s)  %s", leaf.Seq, leaf.Depth, changes, leaf.Time.Format("15:04:05"))
+		if leaf.Current {
+			name += "  [current]"
 		}
+		items = append(items, menu.Item{
+			Name: name,
+			Action: func(state *EditorState) {
+				moveToUndoState(state, leaf.Seq)
+			},
+		})
 	}
-	MoveCursor(state, locateCursorAfterUndoOrRedo(minPos))
+	ShowMenu(state, MenuStyleUndoList, items)
 }
 
-// Redo reverses the last undo operation.
-func Redo(state *EditorState) {
-	ops := state.documentBuffer.undoLog.RedoToNextCheckpoint()
+func applyOpsFromUndoLog(state *EditorState, ops []undo.Op, label string) {
 	if len(ops) == 0 {
 		return
 	}
 
 	minPos := uint64(math.MaxUint64)
 	for _, op := range ops {
-		log.Printf("Redo operation: %#v\n", op)
+		log.Printf("%s operation: %#v\n", label, op)
 		if err := applyOpFromUndoLog(state, op); err != nil {
-			log.Printf("Could not apply redo op %v: %v\n", op, err)
+			log.Printf("Could not apply %s op %v: %v\n", strings.ToLower(label), op, err)
 			continue
 		}
 		if pos := op.Position(); pos < minPos {
 			minPos = pos
 		}
 	}
+
+	if state.documentBuffer.reselectAfterUndo && selectAffectedRange(state, ops) {
+		return
+	}
 	MoveCursor(state, locateCursorAfterUndoOrRedo(minPos))
 }
 
+// selectAffectedRange selects the text changed by undo or redo ops in visual mode, so the user can retry an edit.
+// It returns false if the ops only deleted text, leaving nothing to select.
+func selectAffectedRange(state *EditorState, ops []undo.Op) bool {
+	startPos, endPos, ok := undo.AffectedRange(ops)
+	if !ok || startPos == endPos {
+		return false
+	}
+
+	buffer := state.documentBuffer
+	SetInputMode(state, InputModeVisual)
+	buffer.selector.Start(selection.ModeChar, clampPosToLastChar(buffer.textTree, startPos))
+	buffer.cursor = cursorState{position: clampPosToLastChar(buffer.textTree, endPos-1)}
+	return true
+}
+
 func applyOpFromUndoLog(state *EditorState, op undo.Op) error {
 	pos := op.Position()
 	if s := op.TextToInsert(); len(s) > 0 {
@@ -4,9 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/syntax"
 )
 
@@ -56,6 +58,66 @@ func TestUndoAndRedo(t *testing.T) {
 	assert.Equal(t, "", state.documentBuffer.textTree.String())
 }
 
+func TestUndoEarlierRecoversOldBranch(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+
+	// Make an edit, undo it, then make a different edit.
+	InsertRune(state, 'a')
+	CheckpointUndoLog(state)
+	Undo(state)
+	InsertRune(state, 'b')
+	CheckpointUndoLog(state)
+	assert.Equal(t, "b", state.documentBuffer.textTree.String())
+
+	// Undo and redo stay on the new branch.
+	Undo(state)
+	assert.Equal(t, "", state.documentBuffer.textTree.String())
+	Redo(state)
+	assert.Equal(t, "b", state.documentBuffer.textTree.String())
+
+	// Moving to the earlier state recovers the old branch.
+	UndoEarlier(state, 1)
+	assert.Equal(t, "a", state.documentBuffer.textTree.String())
+	UndoEarlier(state, 1)
+	assert.Equal(t, "", state.documentBuffer.textTree.String())
+
+	// Moving past the initial state does nothing.
+	UndoEarlier(state, 1)
+	assert.Equal(t, "", state.documentBuffer.textTree.String())
+
+	// Moving to later states replays both branches in the order they were created.
+	UndoLater(state, 1)
+	assert.Equal(t, "a", state.documentBuffer.textTree.String())
+	UndoLater(state, 1)
+	assert.Equal(t, "b", state.documentBuffer.textTree.String())
+}
+
+func TestShowUndoListMenu(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	InsertRune(state, 'a')
+	CheckpointUndoLog(state)
+	Undo(state)
+	InsertRune(state, 'b')
+	InsertRune(state, 'c')
+	CheckpointUndoLog(state)
+	ShowUndoListMenu(state)
+
+	assert.True(t, state.Menu().Visible())
+	assert.Equal(t, MenuStyleUndoList, state.Menu().Style())
+
+	// The newest branch is listed first.
+	results, _ := state.Menu().SearchResults()
+	require.Equal(t, 2, len(results))
+	assert.Regexp(t, `^state 2  \(1 change\)  \d\d:\d\d:\d\d  \[current\]$`, results[0].Name)
+	assert.Regexp(t, `^state 1  \(1 change\)  \d\d:\d\d:\d\d$`, results[1].Name)
+
+	// Selecting the old branch restores it.
+	MoveMenuSelection(state, 1)
+	ExecuteSelectedMenuItem(state)
+	assert.False(t, state.Menu().Visible())
+	assert.Equal(t, "a", state.documentBuffer.textTree.String())
+}
+
 func TestUndoDeleteLinesWithIndentation(t *testing.T) {
 	state := NewEditorState(100, 100, nil, nil)
 
@@ -86,6 +148,78 @@ func TestUndoDeleteLinesWithIndentation(t *testing.T) {
 	assert.Equal(t, "\tab\n\tc\nd", state.documentBuffer.textTree.String())
 }
 
+func TestUndoAndRedoReselectAffectedRange(t *testing.T) {
+	state := NewEditorState(100, 100, nil, nil)
+	state.documentBuffer.reselectAfterUndo = true
+	for _, r := range "abcd efgh" {
+		InsertRune(state, r)
+	}
+	CheckpointUndoLog(state)
+
+	// Replace "bcd" with "xy", like a change in visual mode.
+	deleteRunes(state, 1, 3, true)
+	mustInsertTextAtPosition(state, "xy", 1, true)
+	CheckpointUndoLog(state)
+	assert.Equal(t, "axy efgh", state.documentBuffer.textTree.String())
+
+	// Undo selects the restored text.
+	Undo(state)
+	assert.Equal(t, "abcd efgh", state.documentBuffer.textTree.String())
+	assert.Equal(t, InputModeVisual, state.InputMode())
+	assert.Equal(t, selection.ModeChar, state.documentBuffer.selector.Mode())
+	assert.Equal(t, uint64(1), state.documentBuffer.selector.AnchorPos())
+	assert.Equal(t, uint64(3), state.documentBuffer.cursor.position)
+
+	// Redo selects the replacement text.
+	SetInputMode(state, InputModeNormal)
+	Redo(state)
+	assert.Equal(t, "axy efgh", state.documentBuffer.textTree.String())
+	assert.Equal(t, InputModeVisual, state.InputMode())
+	assert.Equal(t, uint64(1), state.documentBuffer.selector.AnchorPos())
+	assert.Equal(t, uint64(2), state.documentBuffer.cursor.position)
+}
+
+func TestUndoReselectNothingToSelect(t *testing.T) {
+	testCases := []struct {
+		name              string
+		reselectAfterUndo bool
+	}{
+		{name: "reselect enabled", reselectAfterUndo: true},
+		{name: "reselect disabled", reselectAfterUndo: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.reselectAfterUndo = tc.reselectAfterUndo
+			InsertRune(state, 'a')
+			CheckpointUndoLog(state)
+			InsertRune(state, 'b')
+			InsertRune(state, 'c')
+			CheckpointUndoLog(state)
+			SetInputMode(state, InputModeNormal)
+
+			// Undoing an insertion only deletes text, so the cursor moves without selecting anything.
+			Undo(state)
+			assert.Equal(t, "a", state.documentBuffer.textTree.String())
+			assert.Equal(t, InputModeNormal, state.InputMode())
+			assert.Equal(t, uint64(0), state.documentBuffer.cursor.position)
+
+			// Redo inserts text, which is selected only if reselect is enabled.
+			Redo(state)
+			assert.Equal(t, "abc", state.documentBuffer.textTree.String())
+			if tc.reselectAfterUndo {
+				assert.Equal(t, InputModeVisual, state.InputMode())
+				assert.Equal(t, uint64(1), state.documentBuffer.selector.AnchorPos())
+				assert.Equal(t, uint64(2), state.documentBuffer.cursor.position)
+			} else {
+				assert.Equal(t, InputModeNormal, state.InputMode())
+				assert.Equal(t, uint64(1), state.documentBuffer.cursor.position)
+			}
+		})
+	}
+}
+
 func TestUndoMultiByteUnicodeWithSyntaxHighlighting(t *testing.T) {
 	state := NewEditorState(100, 100, nil, nil)
 	SetSyntax(state, syntax.LanguageGo)
@@ -13,6 +13,7 @@ const (
 )
 
 // ResizeView resizes the view to the specified width and height.
+// Callers should scroll the view to the cursor afterwards, since the cursor may no longer be visible.
 func ResizeView(state *EditorState, width, height uint64) {
 	state.screenWidth = width
 	state.screenHeight = height
@@ -24,6 +25,13 @@ func ResizeView(state *EditorState, width, height uint64) {
 		// Leave one line for the status bar at the bottom.
 		state.documentBuffer.view.height = height - 1
 	}
+
+	// Soft-wrapped lines may break at different positions after the width changes,
+	// so move the view origin to the start of the row that now contains it.
+	buffer := state.documentBuffer
+	lineStartPos := locate.StartOfLineAtPos(buffer.textTree, buffer.view.textOrigin)
+	rows := displayRowsInLine(buffer, lineStartPos)
+	buffer.view.textOrigin = rows[displayRowIdxForPos(rows, buffer.view.textOrigin)].startPos
 }
 
 // ScrollViewToCursor moves the view origin so that the cursor is visible.
@@ -41,10 +49,43 @@ func scrollViewToPosition(buffer *BufferState, pos uint64) {
 		buffer.view.height)
 }
 
+// ScrollViewCursorToTop scrolls the view so the cursor is near the top, without moving the cursor.
+func ScrollViewCursorToTop(state *EditorState) {
+	buffer := state.documentBuffer
+	buffer.view.textOrigin = locate.ViewOriginWithCursorAtTop(
+		buffer.cursor.position,
+		buffer.textTree,
+		buffer.LineWrapConfig(),
+		buffer.view.height)
+}
+
+// ScrollViewCursorToCenter scrolls the view so the cursor is in the middle, without moving the cursor.
+func ScrollViewCursorToCenter(state *EditorState) {
+	buffer := state.documentBuffer
+	buffer.view.textOrigin = locate.ViewOriginWithCursorAtCenter(
+		buffer.cursor.position,
+		buffer.textTree,
+		buffer.LineWrapConfig(),
+		buffer.view.height)
+}
+
+// ScrollViewCursorToBottom scrolls the view so the cursor is near the bottom, without moving the cursor.
+func ScrollViewCursorToBottom(state *EditorState) {
+	buffer := state.documentBuffer
+	buffer.view.textOrigin = locate.ViewOriginWithCursorAtBottom(
+		buffer.cursor.position,
+		buffer.textTree,
+		buffer.LineWrapConfig(),
+		buffer.view.height)
+}
+
 // ScrollViewByNumLines moves the view origin up or down by the specified number of lines.
+// Near the end of the document, this scrolls only as far as the last lines remain visible,
+// so it never scrolls more than the specified number of lines (or backward when scrolling forward).
 func ScrollViewByNumLines(state *EditorState, direction ScrollDirection, numLines uint64) {
 	buffer := state.documentBuffer
-	lineNum := buffer.textTree.LineNumForPosition(buffer.view.textOrigin)
+	originLineNum := buffer.textTree.LineNumForPosition(buffer.view.textOrigin)
+	lineNum := originLineNum
 	if direction == ScrollDirectionForward {
 		lineNum += numLines
 	} else if lineNum >= numLines {
@@ -56,17 +97,61 @@ func ScrollViewByNumLines(state *EditorState, direction ScrollDirection, numLine
 	lineNum = locate.ClosestValidLineNum(buffer.textTree, lineNum)
 
 	// When scrolling to the end of the file, we want most of the last lines to remain visible.
-	// To achieve this, set the view origin (viewHeight - scrollMargin) lines above
+	// To achieve this, stop the view origin (viewHeight - scrollMargin) lines above
 	// the last line.  This will leave a few blank lines past the end of the document
 	// (the scroll margin) for consistency with ScrollToCursor.
 	lastLineNum := locate.ClosestValidLineNum(buffer.textTree, buffer.textTree.NumLines())
-	if lastLineNum-lineNum < buffer.view.height {
-		if lastLineNum+locate.ScrollMargin+1 > buffer.view.height {
-			lineNum = lastLineNum + locate.ScrollMargin + 1 - buffer.view.height
+	var maxLineNum uint64
+	if lastLineNum+locate.ScrollMargin+1 > buffer.view.height {
+		maxLineNum = lastLineNum + locate.ScrollMargin + 1 - buffer.view.height
+	}
+	if lineNum > maxLineNum {
+		lineNum = maxLineNum
+	}
+
+	// The view may already be past the limit (for example, after scrolling the last line to the top),
+	// in which case scrolling forward leaves the view where it is.
+	if direction == ScrollDirectionForward && lineNum < originLineNum {
+		lineNum = originLineNum
+	}
+
+	buffer.view.textOrigin = buffer.textTree.LineStartPosition(lineNum)
+}
+
+// ScrollViewAndCursorByNumLines scrolls the view up or down by the specified number of lines
+// and moves the cursor to the start of the line the same number of lines above or below.
+// This keeps the cursor on the same row of the screen, except near the start or end of the document
+// where the view cannot scroll the full distance.
+func ScrollViewAndCursorByNumLines(state *EditorState, direction ScrollDirection, numLines uint64) {
+	MoveCursor(state, func(params LocatorParams) uint64 {
+		if direction == ScrollDirectionForward {
+			return locate.StartOfLineBelow(params.TextTree, numLines, params.CursorPos)
 		} else {
-			lineNum = 0
+			return locate.StartOfLineAbove(params.TextTree, numLines, params.CursorPos)
 		}
+	})
+
+	ScrollViewByNumLines(state, direction, numLines)
+
+	// If the cursor ends up within the scroll margin, move it out of the margin.
+	// Otherwise, the view would scroll back to the cursor after the action completes.
+	buffer := state.documentBuffer
+	originLineNum := buffer.textTree.LineNumForPosition(buffer.view.textOrigin)
+	cursorLineNum := buffer.textTree.LineNumForPosition(buffer.cursor.position)
+	lastLineNum := locate.ClosestValidLineNum(buffer.textTree, buffer.textTree.NumLines())
+	margin := locate.ScrollMarginForNumLines(buffer.view.height)
+	lastVisibleLineNum := originLineNum + buffer.view.height - 1
+	targetLineNum := cursorLineNum
+	if originLineNum > 0 && cursorLineNum < originLineNum+margin {
+		targetLineNum = originLineNum + margin
+	} else if buffer.view.height > 0 && lastVisibleLineNum < lastLineNum && cursorLineNum+margin > lastVisibleLineNum {
+		targetLineNum = lastVisibleLineNum - margin
 	}
 
-	buffer.view.textOrigin = buffer.textTree.LineStartPosition(lineNum)
+	if targetLineNum != cursorLineNum {
+		targetLineNum = locate.ClosestValidLineNum(buffer.textTree, targetLineNum)
+		MoveCursor(state, func(params LocatorParams) uint64 {
+			return params.TextTree.LineStartPosition(targetLineNum)
+		})
+	}
 }
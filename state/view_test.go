@@ -57,6 +57,38 @@ func TestScrollViewByNumLines(t *testing.T) {
 			numLines:           10,
 			expectedtextOrigin: 12,
 		},
+		{
+			name:               "scroll down partial page near end of document",
+			inputString:        "ab\ncd\nef\ngh\nij\nkl\nmn",
+			initialView:        viewState{textOrigin: 3, height: 6, width: 100},
+			direction:          ScrollDirectionForward,
+			numLines:           5,
+			expectedtextOrigin: 12,
+		},
+		{
+			name:               "scroll down does not scroll more than requested near end of document",
+			inputString:        "ab\ncd\nef\ngh\nij\nkl\nmn",
+			initialView:        viewState{textOrigin: 0, height: 6, width: 100},
+			direction:          ScrollDirectionForward,
+			numLines:           1,
+			expectedtextOrigin: 3,
+		},
+		{
+			name:               "scroll down with view already past end of document",
+			inputString:        "ab\ncd\nef\ngh\nij\nkl\nmn",
+			initialView:        viewState{textOrigin: 15, height: 6, width: 100},
+			direction:          ScrollDirectionForward,
+			numLines:           3,
+			expectedtextOrigin: 15,
+		},
+		{
+			name:               "scroll up with view past end of document",
+			inputString:        "ab\ncd\nef\ngh\nij\nkl\nmn",
+			initialView:        viewState{textOrigin: 18, height: 6, width: 100},
+			direction:          ScrollDirectionBackward,
+			numLines:           1,
+			expectedtextOrigin: 12,
+		},
 		{
 			name:               "scroll down view taller than document",
 			inputString:        "ab\ncd\nef\ngh",
@@ -78,3 +110,296 @@ func TestScrollViewByNumLines(t *testing.T) {
 		})
 	}
 }
+
+func TestScrollViewAndCursorByNumLines(t *testing.T) {
+	// Twenty lines, each with one digit, so line n starts at position 2n.
+	const inputString = "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n0\n1\n2\n3\n4\n5\n6\n7\n8\n9"
+
+	testCases := []struct {
+		name               string
+		originLine         uint64
+		cursorLine         uint64
+		direction          ScrollDirection
+		numLines           uint64
+		expectedOriginLine uint64
+		expectedCursorLine uint64
+	}{
+		{
+			name:               "scroll down half page keeps cursor row",
+			originLine:         2,
+			cursorLine:         7,
+			direction:          ScrollDirectionForward,
+			numLines:           5,
+			expectedOriginLine: 7,
+			expectedCursorLine: 12,
+		},
+		{
+			name:               "scroll down full page keeps cursor row",
+			originLine:         0,
+			cursorLine:         3,
+			direction:          ScrollDirectionForward,
+			numLines:           10,
+			expectedOriginLine: 10,
+			expectedCursorLine: 13,
+		},
+		{
+			name:               "scroll down from start of document moves cursor out of scroll margin",
+			originLine:         0,
+			cursorLine:         0,
+			direction:          ScrollDirectionForward,
+			numLines:           5,
+			expectedOriginLine: 5,
+			expectedCursorLine: 8,
+		},
+		{
+			name:               "scroll down partial page near end of document",
+			originLine:         10,
+			cursorLine:         15,
+			direction:          ScrollDirectionForward,
+			numLines:           5,
+			expectedOriginLine: 13,
+			expectedCursorLine: 19,
+		},
+		{
+			name:               "scroll down full page near end of document",
+			originLine:         5,
+			cursorLine:         10,
+			direction:          ScrollDirectionForward,
+			numLines:           10,
+			expectedOriginLine: 13,
+			expectedCursorLine: 19,
+		},
+		{
+			name:               "scroll down at end of document moves only the cursor",
+			originLine:         13,
+			cursorLine:         16,
+			direction:          ScrollDirectionForward,
+			numLines:           5,
+			expectedOriginLine: 13,
+			expectedCursorLine: 19,
+		},
+		{
+			name:               "scroll up half page keeps cursor row",
+			originLine:         10,
+			cursorLine:         15,
+			direction:          ScrollDirectionBackward,
+			numLines:           5,
+			expectedOriginLine: 5,
+			expectedCursorLine: 10,
+		},
+		{
+			name:               "scroll up partial page near start of document",
+			originLine:         3,
+			cursorLine:         8,
+			direction:          ScrollDirectionBackward,
+			numLines:           5,
+			expectedOriginLine: 0,
+			expectedCursorLine: 3,
+		},
+		{
+			name:               "scroll up at start of document moves only the cursor",
+			originLine:         0,
+			cursorLine:         2,
+			direction:          ScrollDirectionBackward,
+			numLines:           5,
+			expectedOriginLine: 0,
+			expectedCursorLine: 0,
+		},
+		{
+			name:               "scroll up from last line moves cursor out of scroll margin",
+			originLine:         13,
+			cursorLine:         19,
+			direction:          ScrollDirectionBackward,
+			numLines:           10,
+			expectedOriginLine: 3,
+			expectedCursorLine: 9,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.view = viewState{textOrigin: textTree.LineStartPosition(tc.originLine), height: 10, width: 100}
+			state.documentBuffer.cursor = cursorState{position: textTree.LineStartPosition(tc.cursorLine)}
+			ScrollViewAndCursorByNumLines(state, tc.direction, tc.numLines)
+			assert.Equal(t, textTree.LineStartPosition(tc.expectedOriginLine), state.documentBuffer.view.textOrigin)
+			assert.Equal(t, textTree.LineStartPosition(tc.expectedCursorLine), state.documentBuffer.cursor.position)
+
+			// The view should stay in place when scrolling to keep the cursor visible after the command.
+			textOrigin := state.documentBuffer.view.textOrigin
+			ScrollViewToCursor(state)
+			assert.Equal(t, textOrigin, state.documentBuffer.view.textOrigin)
+		})
+	}
+}
+
+func TestScrollViewCursorToTopCenterBottom(t *testing.T) {
+	// Twenty lines, each with one digit, so line n starts at position 2n.
+	const inputString = "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n0\n1\n2\n3\n4\n5\n6\n7\n8\n9"
+
+	testCases := []struct {
+		name              string
+		viewHeight        uint64
+		cursorLine        uint64
+		scrollFunc        func(*EditorState)
+		expectedCursorRow uint64
+	}{
+		{
+			name:              "top leaves scroll margin above cursor",
+			viewHeight:        10,
+			cursorLine:        10,
+			scrollFunc:        ScrollViewCursorToTop,
+			expectedCursorRow: 3,
+		},
+		{
+			name:              "center",
+			viewHeight:        10,
+			cursorLine:        10,
+			scrollFunc:        ScrollViewCursorToCenter,
+			expectedCursorRow: 5,
+		},
+		{
+			name:              "bottom leaves scroll margin below cursor",
+			viewHeight:        10,
+			cursorLine:        10,
+			scrollFunc:        ScrollViewCursorToBottom,
+			expectedCursorRow: 6,
+		},
+		{
+			name:              "top near start of document",
+			viewHeight:        10,
+			cursorLine:        1,
+			scrollFunc:        ScrollViewCursorToTop,
+			expectedCursorRow: 1,
+		},
+		{
+			name:              "center near start of document",
+			viewHeight:        10,
+			cursorLine:        2,
+			scrollFunc:        ScrollViewCursorToCenter,
+			expectedCursorRow: 2,
+		},
+		{
+			name:              "top at end of document",
+			viewHeight:        10,
+			cursorLine:        19,
+			scrollFunc:        ScrollViewCursorToTop,
+			expectedCursorRow: 3,
+		},
+		{
+			name:              "bottom at end of document",
+			viewHeight:        10,
+			cursorLine:        19,
+			scrollFunc:        ScrollViewCursorToBottom,
+			expectedCursorRow: 6,
+		},
+		{
+			name:              "top in short view",
+			viewHeight:        4,
+			cursorLine:        10,
+			scrollFunc:        ScrollViewCursorToTop,
+			expectedCursorRow: 1,
+		},
+		{
+			name:              "bottom in short view",
+			viewHeight:        4,
+			cursorLine:        10,
+			scrollFunc:        ScrollViewCursorToBottom,
+			expectedCursorRow: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(inputString)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.view = viewState{textOrigin: 0, height: tc.viewHeight, width: 100}
+			state.documentBuffer.cursor = cursorState{position: textTree.LineStartPosition(tc.cursorLine)}
+			tc.scrollFunc(state)
+
+			originLine := textTree.LineNumForPosition(state.documentBuffer.view.textOrigin)
+			assert.Equal(t, tc.expectedCursorRow, tc.cursorLine-originLine)
+			assert.Equal(t, textTree.LineStartPosition(tc.cursorLine), state.documentBuffer.cursor.position)
+
+			// The view should stay in place when scrolling to keep the cursor visible after the command.
+			textOrigin := state.documentBuffer.view.textOrigin
+			ScrollViewToCursor(state)
+			assert.Equal(t, textOrigin, state.documentBuffer.view.textOrigin)
+		})
+	}
+}
+
+func TestScrollViewCursorToTopWithSoftWrap(t *testing.T) {
+	// With a view width of 5, the first line wraps into three rows.
+	textTree, err := text.NewTreeFromString("abcdefghijklm\nn\no\np\nq\nr\ns\nt")
+	require.NoError(t, err)
+	state := NewEditorState(5, 100, nil, nil)
+	state.documentBuffer.textTree = textTree
+	state.documentBuffer.lineWrapAllowCharBreaks = true
+	state.documentBuffer.view = viewState{textOrigin: 0, height: 7, width: 5}
+	state.documentBuffer.cursor = cursorState{position: 16} // "o" on the third line.
+	ScrollViewCursorToTop(state)
+
+	// The three rows above the cursor are "fghij", "klm", and "n",
+	// so the view starts at the second row of the wrapped line.
+	assert.Equal(t, uint64(5), state.documentBuffer.view.textOrigin)
+}
+
+func TestResizeViewWithSoftWrap(t *testing.T) {
+	testCases := []struct {
+		name               string
+		initialTextOrigin  uint64
+		width              uint64
+		expectedTextOrigin uint64
+	}{
+		{
+			name:               "origin at start of line",
+			initialTextOrigin:  0,
+			width:              10,
+			expectedTextOrigin: 0,
+		},
+		{
+			name:               "origin moves to start of wider row",
+			initialTextOrigin:  14,
+			width:              10,
+			expectedTextOrigin: 10,
+		},
+		{
+			name:               "origin moves to start of narrower row",
+			initialTextOrigin:  14,
+			width:              4,
+			expectedTextOrigin: 12,
+		},
+		{
+			name:               "origin on next line",
+			initialTextOrigin:  21,
+			width:              10,
+			expectedTextOrigin: 21,
+		},
+		{
+			name:               "zero width",
+			initialTextOrigin:  14,
+			width:              0,
+			expectedTextOrigin: 14,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// With a view width of 7, the first line wraps into rows starting at 0, 7, and 14.
+			textTree, err := text.NewTreeFromString("abcdefghijklmnopqrst\nuvw")
+			require.NoError(t, err)
+			state := NewEditorState(7, 10, nil, nil)
+			state.documentBuffer.textTree = textTree
+			state.documentBuffer.lineWrapAllowCharBreaks = true
+			state.documentBuffer.view.textOrigin = tc.initialTextOrigin
+			ResizeView(state, tc.width, 10)
+			assert.Equal(t, tc.expectedTextOrigin, state.documentBuffer.view.textOrigin)
+		})
+	}
+}
@@ -4,23 +4,77 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/file"
 )
 
 // SetWorkingDirectory changes the working directory to the specified path.
+// Relative paths are resolved from the current working directory, and a leading "~" expands to the home directory.
 func SetWorkingDirectory(s *EditorState, dirPath string) {
-	err := os.Chdir(dirPath)
+	dirPath, err := validateWorkingDirectory(dirPath)
+	if err == nil {
+		err = os.Chdir(dirPath)
+	}
+
 	if err != nil {
 		log.Printf("Error changing working directory to %q: %s", dirPath, err)
 		SetStatusMsg(s, StatusMsg{
 			Style: StatusMsgStyleError,
-			Text:  fmt.Sprintf("Error changing working directory: %s", err),
+			Text:  fmt.Sprintf("Error changing working directory: %s", errors.Cause(err)),
 		})
 		return
 	}
 
+	// Show the absolute path, even if the user typed a relative path.
+	if cwd, err := os.Getwd(); err == nil {
+		dirPath = cwd
+	}
+
 	log.Printf("Changed working directory to %q", dirPath)
 	SetStatusMsg(s, StatusMsg{
 		Style: StatusMsgStyleSuccess,
 		Text:  fmt.Sprintf("Changed working directory to %q", dirPath),
 	})
 }
+
+// ShowWorkingDirectory displays the current working directory in the status bar.
+func ShowWorkingDirectory(s *EditorState) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Printf("Error getting current working directory: %s", err)
+		SetStatusMsg(s, StatusMsg{
+			Style: StatusMsgStyleError,
+			Text:  fmt.Sprintf("Error getting working directory: %s", err),
+		})
+		return
+	}
+
+	SetStatusMsg(s, StatusMsg{
+		Style: StatusMsgStyleSuccess,
+		Text:  cwd,
+	})
+}
+
+func validateWorkingDirectory(dirPath string) (string, error) {
+	dirPath = strings.TrimSpace(dirPath)
+	if dirPath == "" {
+		return "", errors.New("no directory specified")
+	}
+
+	dirPath, err := file.ExpandHomeDir(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	fileInfo, err := os.Stat(dirPath)
+	if err != nil {
+		return dirPath, err
+	} else if !fileInfo.IsDir() {
+		return dirPath, fmt.Errorf("%s is not a directory", dirPath)
+	}
+
+	return dirPath, nil
+}
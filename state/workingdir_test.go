@@ -0,0 +1,80 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWorkingDirectory(t *testing.T) {
+	testCases := []struct {
+		name              string
+		dirPath           string
+		expectChanged     bool
+		expectedStatusMsg string
+	}{
+		{
+			name:              "relative path to child directory",
+			dirPath:           "a/b",
+			expectChanged:     true,
+			expectedStatusMsg: "Changed working directory to",
+		},
+		{
+			name:              "directory does not exist",
+			dirPath:           "missing",
+			expectChanged:     false,
+			expectedStatusMsg: "no such file or directory",
+		},
+		{
+			name:              "path is a file",
+			dirPath:           "a/b/test.txt",
+			expectChanged:     false,
+			expectedStatusMsg: "is not a directory",
+		},
+		{
+			name:              "empty path",
+			dirPath:           " ",
+			expectChanged:     false,
+			expectedStatusMsg: "no directory specified",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withTempDirPaths(t, []string{"a/b/test.txt"}, func(dir string) {
+				state := NewEditorState(100, 100, nil, nil)
+				SetWorkingDirectory(state, tc.dirPath)
+				assert.Contains(t, state.StatusMsg().Text, tc.expectedStatusMsg)
+
+				cwd, err := os.Getwd()
+				require.NoError(t, err)
+				cwd, err = filepath.EvalSymlinks(cwd)
+				require.NoError(t, err)
+				dir, err = filepath.EvalSymlinks(dir)
+				require.NoError(t, err)
+
+				if tc.expectChanged {
+					assert.Equal(t, StatusMsgStyleSuccess, state.StatusMsg().Style)
+					assert.Equal(t, filepath.Join(dir, tc.dirPath), cwd)
+				} else {
+					assert.Equal(t, StatusMsgStyleError, state.StatusMsg().Style)
+					assert.Equal(t, dir, cwd)
+				}
+			})
+		})
+	}
+}
+
+func TestShowWorkingDirectory(t *testing.T) {
+	withTempDirPaths(t, nil, func(dir string) {
+		state := NewEditorState(100, 100, nil, nil)
+		ShowWorkingDirectory(state)
+
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		assert.Equal(t, StatusMsg{Style: StatusMsgStyleSuccess, Text: cwd}, state.StatusMsg())
+	})
+}
@@ -0,0 +1,40 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aretext/aretext/selection"
+)
+
+// yankHighlightState is a region of copied text that stays highlighted until it expires.
+type yankHighlightState struct {
+	region     selection.Region
+	expireTime time.Time // Zero if no text is highlighted.
+}
+
+// highlightYankedText highlights text copied to the clipboard if the highlightYank option is enabled.
+// The highlight is cleared when it expires or when the document changes.
+func highlightYankedText(state *EditorState, startPos uint64, endPos uint64) {
+	buffer := state.documentBuffer
+	if !buffer.highlightYank || startPos >= endPos {
+		return
+	}
+	buffer.yankHighlight = yankHighlightState{
+		region:     selection.Region{StartPos: startPos, EndPos: endPos},
+		expireTime: time.Now().Add(buffer.highlightYankDuration),
+	}
+}
+
+// clearYankHighlight removes the highlight from copied text.
+func clearYankHighlight(buffer *BufferState) {
+	buffer.yankHighlight = yankHighlightState{}
+}
+
+// ClearYankHighlightIfExpired removes the highlight from copied text if its duration elapsed before the given time.
+func ClearYankHighlightIfExpired(state *EditorState, now time.Time) {
+	expireTime := state.documentBuffer.yankHighlight.expireTime
+	if expireTime.IsZero() || now.Before(expireTime) {
+		return
+	}
+	clearYankHighlight(state.documentBuffer)
+}
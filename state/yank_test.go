@@ -0,0 +1,128 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/clipboard"
+	"github.com/aretext/aretext/selection"
+	"github.com/aretext/aretext/text"
+)
+
+func TestHighlightYankedText(t *testing.T) {
+	testCases := []struct {
+		name           string
+		highlightYank  bool
+		initialText    string
+		initialCursor  uint64
+		yankFunc       func(*EditorState)
+		expectedRegion selection.Region
+	}{
+		{
+			name:          "disabled",
+			highlightYank: false,
+			initialText:   "abc def",
+			yankFunc: func(state *EditorState) {
+				CopyLine(state, clipboard.PageDefault)
+			},
+			expectedRegion: selection.EmptyRegion,
+		},
+		{
+			name:          "copy range",
+			highlightYank: true,
+			initialText:   "abc def",
+			yankFunc: func(state *EditorState) {
+				CopyRange(state, clipboard.PageDefault, func(LocatorParams) (uint64, uint64) { return 1, 5 })
+			},
+			expectedRegion: selection.Region{StartPos: 1, EndPos: 5},
+		},
+		{
+			name:          "copy line",
+			highlightYank: true,
+			initialText:   "abc\ndef\nghi",
+			initialCursor: 5,
+			yankFunc: func(state *EditorState) {
+				CopyLine(state, clipboard.PageDefault)
+			},
+			expectedRegion: selection.Region{StartPos: 4, EndPos: 7},
+		},
+		{
+			name:          "copy line range",
+			highlightYank: true,
+			initialText:   "abc\ndef\nghi",
+			yankFunc: func(state *EditorState) {
+				YankLineRange(state, LineRange{StartLine: 0, EndLine: 1}, clipboard.PageDefault)
+			},
+			expectedRegion: selection.Region{StartPos: 0, EndPos: 7},
+		},
+		{
+			name:          "copy selection",
+			highlightYank: true,
+			initialText:   "abc def",
+			initialCursor: 1,
+			yankFunc: func(state *EditorState) {
+				ToggleVisualMode(state, selection.ModeChar)
+				MoveCursor(state, func(LocatorParams) uint64 { return 3 })
+				CopySelection(state, clipboard.PageDefault)
+			},
+			expectedRegion: selection.Region{StartPos: 1, EndPos: 4},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			textTree, err := text.NewTreeFromString(tc.initialText)
+			require.NoError(t, err)
+			state := NewEditorState(100, 100, nil, nil)
+			buffer := state.documentBuffer
+			buffer.textTree = textTree
+			buffer.cursor.position = tc.initialCursor
+			buffer.highlightYank = tc.highlightYank
+			buffer.highlightYankDuration = time.Minute
+			tc.yankFunc(state)
+			assert.Equal(t, tc.expectedRegion, buffer.YankHighlight())
+			assert.Equal(t, tc.highlightYank, !state.YankHighlightExpireTime().IsZero())
+		})
+	}
+}
+
+func TestYankHighlightClearedOnEdit(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc def")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.highlightYank = true
+	buffer.highlightYankDuration = time.Minute
+
+	CopyLine(state, clipboard.PageDefault)
+	assert.Equal(t, selection.Region{StartPos: 0, EndPos: 7}, buffer.YankHighlight())
+
+	InsertRune(state, 'x')
+	assert.Equal(t, selection.EmptyRegion, buffer.YankHighlight())
+	assert.True(t, state.YankHighlightExpireTime().IsZero())
+}
+
+func TestClearYankHighlightIfExpired(t *testing.T) {
+	textTree, err := text.NewTreeFromString("abc def")
+	require.NoError(t, err)
+	state := NewEditorState(100, 100, nil, nil)
+	buffer := state.documentBuffer
+	buffer.textTree = textTree
+	buffer.highlightYank = true
+	buffer.highlightYankDuration = time.Second
+
+	CopyLine(state, clipboard.PageDefault)
+	expireTime := state.YankHighlightExpireTime()
+	require.False(t, expireTime.IsZero())
+
+	ClearYankHighlightIfExpired(state, expireTime.Add(-time.Millisecond))
+	assert.Equal(t, selection.Region{StartPos: 0, EndPos: 7}, buffer.YankHighlight())
+
+	ClearYankHighlightIfExpired(state, expireTime)
+	assert.Equal(t, selection.EmptyRegion, buffer.YankHighlight())
+	assert.True(t, state.YankHighlightExpireTime().IsZero())
+}
@@ -27,8 +27,7 @@ func cCommentParseFunc() parser.Func {
 	consumeLineComment := consumeString("//").
 		ThenMaybe(consumeToNextLineFeed)
 
-	consumeBlockComment := consumeString("/*").
-		Then(consumeToString("*/"))
+	consumeBlockComment := consumeDelimitedRegion("/*", "*/", false)
 
 	return consumeLineComment.
 		Or(consumeBlockComment).
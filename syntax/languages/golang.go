@@ -28,8 +28,7 @@ func golangLineCommentParseFunc() parser.Func {
 }
 
 func golangGeneralCommentParseFunc() parser.Func {
-	return consumeString("/*").
-		Then(consumeToString("*/")).
+	return consumeDelimitedRegion("/*", "*/", false).
 		Map(recognizeToken(parser.TokenRoleComment))
 }
 
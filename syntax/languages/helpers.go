@@ -78,6 +78,48 @@ func consumeToString(s string) parser.Func {
 	}
 }
 
+// consumeDelimitedRegion consumes a region that starts with `startDelim` and ends with `endDelim`, including both delimiters,
+// such as a block comment or a multi-line string.
+// If allowNesting is true, each `startDelim` inside the region must be closed by its own `endDelim`, as in Rust block comments.
+// If the region is not terminated before the end of the input, the parse fails.
+func consumeDelimitedRegion(startDelim string, endDelim string, allowNesting bool) parser.Func {
+	consumeStart, consumeEnd := consumeString(startDelim), consumeString(endDelim)
+	return func(iter parser.TrackingRuneIter, state parser.State) parser.Result {
+		result := consumeStart(iter, state)
+		if result.IsFailure() {
+			return parser.FailedResult
+		}
+
+		numConsumed := iter.Skip(result.NumConsumed)
+		depth := 1
+		for depth > 0 {
+			if r := consumeEnd(iter, state); r.IsSuccess() {
+				numConsumed += iter.Skip(r.NumConsumed)
+				depth--
+				continue
+			}
+
+			if allowNesting {
+				if r := consumeStart(iter, state); r.IsSuccess() {
+					numConsumed += iter.Skip(r.NumConsumed)
+					depth++
+					continue
+				}
+			}
+
+			if _, err := iter.NextRune(); err != nil {
+				return parser.FailedResult
+			}
+			numConsumed++
+		}
+
+		return parser.Result{
+			NumConsumed: numConsumed,
+			NextState:   state,
+		}
+	}
+}
+
 // consumeSingleRuneLike consumes a single rune matching a predicate.
 func consumeSingleRuneLike(predicateFn func(rune) bool) parser.Func {
 	return func(iter parser.TrackingRuneIter, state parser.State) parser.Result {
@@ -0,0 +1,132 @@
+package languages
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aretext/aretext/syntax/parser"
+	"github.com/aretext/aretext/text"
+)
+
+func TestConsumeDelimitedRegion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		allowNesting bool
+		text         string
+		expected     []TokenWithText
+	}{
+		{
+			name: "empty region",
+			text: "<<>>",
+			expected: []TokenWithText{
+				{Text: "<<>>", Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name: "multi-line region",
+			text: "a <<b\nc>> d",
+			expected: []TokenWithText{
+				{Text: "<<b\nc>>", Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name: "consecutive regions",
+			text: "<<a>><<b>>",
+			expected: []TokenWithText{
+				{Text: "<<a>>", Role: parser.TokenRoleComment},
+				{Text: "<<b>>", Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name: "nested start delimiter without nesting ends at first end delimiter",
+			text: "<<a <<b>> c>>",
+			expected: []TokenWithText{
+				{Text: "<<a <<b>>", Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name:         "nested region",
+			allowNesting: true,
+			text:         "<<a <<b>> c>> d",
+			expected: []TokenWithText{
+				{Text: "<<a <<b>> c>>", Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name:     "unterminated region at end of file",
+			text:     "<<a\nb",
+			expected: []TokenWithText{},
+		},
+		{
+			name:     "start delimiter at end of file",
+			text:     "a <<",
+			expected: []TokenWithText{},
+		},
+		{
+			name:         "unterminated outer region at end of file",
+			allowNesting: true,
+			text:         "<<a <<b>> c",
+			expected: []TokenWithText{
+				{Text: "<<b>>", Role: parser.TokenRoleComment},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parseFunc := consumeDelimitedRegion("<<", ">>", tc.allowNesting).
+				Map(recognizeToken(parser.TokenRoleComment))
+			tokens := ParseTokensWithText(parseFunc, tc.text)
+			assert.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
+func TestConsumeDelimitedRegionReparseAfterEdit(t *testing.T) {
+	parseFunc := consumeDelimitedRegion("/*", "*/", false).
+		Map(recognizeToken(parser.TokenRoleComment))
+
+	tree, err := text.NewTreeFromString("x /* a\nb\nc */ y /* z */")
+	require.NoError(t, err)
+	p := parser.New(parseFunc)
+	p.ParseAll(tree)
+
+	testCases := []struct {
+		name     string
+		editFunc func(*text.Tree) parser.Edit
+		expected []parser.Token
+	}{
+		{
+			name: "insert inside region",
+			editFunc: func(tree *text.Tree) parser.Edit {
+				require.NoError(t, tree.InsertAtPosition(7, 'q'))
+				return parser.NewInsertEdit(7, 1)
+			},
+			expected: []parser.Token{
+				{StartPos: 2, EndPos: 14, Role: parser.TokenRoleComment},
+				{StartPos: 17, EndPos: 24, Role: parser.TokenRoleComment},
+			},
+		},
+		{
+			name: "delete end delimiter continues region to the next end delimiter",
+			editFunc: func(tree *text.Tree) parser.Edit {
+				tree.DeleteAtPosition(13)
+				return parser.NewDeleteEdit(13, 1)
+			},
+			expected: []parser.Token{
+				{StartPos: 2, EndPos: 23, Role: parser.TokenRoleComment},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			edit := tc.editFunc(tree)
+			p.ReparseAfterEdit(tree, edit)
+			assert.Equal(t, tc.expected, p.TokensIntersectingRange(0, math.MaxUint64))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package languages
 
 import (
+	"strings"
 	"unicode"
 
 	"github.com/aretext/aretext/syntax/parser"
@@ -48,6 +49,19 @@ func (s markdownParseState) Equals(other parser.State) bool {
 //
 // See https://spec.commonmark.org/0.30/ for details.
 func MarkdownParseFunc() parser.Func {
+	return MarkdownWithEmbeddedParseFunc(nil)
+}
+
+// EmbeddedParseFuncLookup returns the parse func for a language name,
+// or nil if the language is not recognized.
+type EmbeddedParseFuncLookup func(language string) parser.Func
+
+// MarkdownWithEmbeddedParseFunc returns a parse func for Markdown
+// that tokenizes the contents of fenced code blocks using the parse func
+// for the language declared in the fence's info string (for example, "```go").
+// If lookupFn is nil or does not recognize the language, the code block
+// is recognized as a single token.
+func MarkdownWithEmbeddedParseFunc(lookupFn EmbeddedParseFuncLookup) parser.Func {
 	// Incrementally parse one block at a time (headings, paragraphs, list items, etc.).
 	// This ensures that each parse func invocation starts at the beginning of a line.
 	parseListItem := markdownNumberListItemParseFunc().
@@ -58,7 +72,7 @@ func MarkdownParseFunc() parser.Func {
 		markdownParseStateNormal,
 		markdownThematicBreakParseFunc())
 
-	parseCodeBlock := markdownFencedCodeBlockParseFunc().
+	parseCodeBlock := markdownFencedCodeBlockParseFunc(lookupFn).
 		Map(setState(markdownParseStateNormal))
 
 	parseHeadings := matchState(
@@ -338,13 +352,14 @@ func markdownSetextHeadingParseFunc() parser.Func {
 		Map(recognizeToken(markdownHeadingRole))
 }
 
-func markdownFencedCodeBlockParseFunc() parser.Func {
+func markdownFencedCodeBlockParseFunc(lookupFn EmbeddedParseFuncLookup) parser.Func {
 	// A fenced code block consists of a fence ("```" or "~~~" of length >= 3)
 	// until a closing fence of at least the same length or EOF.
 	// The fences may have leading indentation.
 	// Commonmark allows the opening fence to be followed by
-	// an optional "info" string (e.g. specifying the code language), which we include
-	// within the coe block token (no special treatment).
+	// an optional "info" string. If the first word of the info string
+	// names a language recognized by lookupFn, then the contents of the
+	// code block are tokenized using that language's parse func.
 	checkFenceLen := func(fenceRune rune, iter parser.TrackingRuneIter) (uint64, bool) {
 		var n uint64
 		for {
@@ -361,47 +376,43 @@ func markdownFencedCodeBlockParseFunc() parser.Func {
 		return n, true
 	}
 
-	checkClosingCodeFence := func(fenceRune rune, openFenceLen uint64, iter parser.TrackingRuneIter) (uint64, bool) {
+	// checkCodeBlockLine returns the length of the next line, whether the line
+	// is a closing code fence, and whether the line ended at EOF.
+	checkCodeBlockLine := func(fenceRune rune, openFenceLen uint64, iter parser.TrackingRuneIter) (uint64, bool, bool) {
 		var n uint64
-		for {
-			maybeFence := true
+		maybeFence := true
 
-			// Leading indentation.
-			indentCount := markdownSkipLeadingIndentation(&iter)
-			n += indentCount
+		// Leading indentation.
+		indentCount := markdownSkipLeadingIndentation(&iter)
+		n += indentCount
 
-			closeFenceLen, found := checkFenceLen(fenceRune, iter)
-			if found && closeFenceLen >= openFenceLen {
-				iter.Skip(closeFenceLen)
-				n += closeFenceLen
-			} else {
-				maybeFence = false
-			}
+		closeFenceLen, found := checkFenceLen(fenceRune, iter)
+		if found && closeFenceLen >= openFenceLen {
+			iter.Skip(closeFenceLen)
+			n += closeFenceLen
+		} else {
+			maybeFence = false
+		}
 
-			// Consume to the end of the line or file.
-			for {
-				r, err := iter.NextRune()
-				if err != nil {
-					// If we hit the EOF, then close the code block.
-					return n, true
-				}
-				n++
-				if r == '\n' {
-					break
-				} else if maybeFence && !(r == ' ' || r == '\t' || r == '\r') {
-					// Only trailing whitespace allowed after code fence.
-					maybeFence = false
-				}
+		// Consume to the end of the line or file.
+		for {
+			r, err := iter.NextRune()
+			if err != nil {
+				return n, maybeFence, true
 			}
-
-			if maybeFence {
-				return n, true
+			n++
+			if r == '\n' {
+				return n, maybeFence, false
+			} else if maybeFence && !(r == ' ' || r == '\t' || r == '\r') {
+				// Only trailing whitespace allowed after code fence.
+				maybeFence = false
 			}
 		}
 	}
 
 	return func(iter parser.TrackingRuneIter, state parser.State) parser.Result {
 		var n uint64
+		startIter := iter
 
 		// Leading indentation.
 		indentCount := markdownSkipLeadingIndentation(&iter)
@@ -422,7 +433,9 @@ func markdownFencedCodeBlockParseFunc() parser.Func {
 		iter.Skip(openFenceLen)
 		n += openFenceLen
 
-		// Consume to the end of the first line.
+		// Consume to the end of the first line, reading the language from the info string.
+		var infoLang strings.Builder
+		var infoLangDone bool
 		for {
 			r, err := iter.NextRune()
 			if err != nil {
@@ -431,32 +444,111 @@ func markdownFencedCodeBlockParseFunc() parser.Func {
 			n++
 			if r == '\n' {
 				break
+			} else if unicode.IsSpace(r) || r == '{' {
+				infoLangDone = infoLangDone || infoLang.Len() > 0
+			} else if !infoLangDone {
+				infoLang.WriteRune(r)
 			}
 		}
+		contentStartOffset := n
+		contentEndOffset := n
 
 		// Read subsequent lines until we find a closing code fence or EOF.
 		for {
-			lineLen, found := checkClosingCodeFence(fenceRune, openFenceLen, iter)
+			lineLen, isFence, eof := checkCodeBlockLine(fenceRune, openFenceLen, iter)
+			if !isFence {
+				contentEndOffset = n + lineLen
+			}
 			n += lineLen
 			iter.Skip(lineLen)
-			if found {
+			if isFence || eof {
 				break
 			}
 		}
 
-		// Found the end of the code fence, so return the token.
-		return parser.Result{
-			NumConsumed: n,
-			ComputedTokens: []parser.ComputedToken{
-				{
-					Offset: 0,
-					Length: n,
-					Role:   markdownCodeBlockRole,
+		var embeddedParseFunc parser.Func
+		if lookupFn != nil && infoLang.Len() > 0 {
+			embeddedParseFunc = lookupFn(strings.ToLower(infoLang.String()))
+		}
+
+		if embeddedParseFunc == nil {
+			// Unrecognized language, so return a single token for the entire code block.
+			return parser.Result{
+				NumConsumed: n,
+				ComputedTokens: []parser.ComputedToken{
+					{
+						Offset: 0,
+						Length: n,
+						Role:   markdownCodeBlockRole,
+					},
 				},
+				NextState: state,
+			}
+		}
+
+		// Recognize the fences as code block tokens and tokenize the contents
+		// using the embedded language's parse func.
+		tokens := []parser.ComputedToken{
+			{
+				Offset: 0,
+				Length: contentStartOffset,
+				Role:   markdownCodeBlockRole,
 			},
-			NextState: state,
 		}
+
+		contentIter := startIter
+		contentIter.Skip(contentStartOffset)
+		contentIter.Limit(contentEndOffset - contentStartOffset)
+		embeddedTokens := markdownParseEmbedded(embeddedParseFunc, contentIter, contentEndOffset-contentStartOffset)
+		for _, tok := range embeddedTokens {
+			tok.Offset += contentStartOffset
+			tokens = append(tokens, tok)
+		}
+
+		if contentEndOffset < n {
+			tokens = append(tokens, parser.ComputedToken{
+				Offset: contentEndOffset,
+				Length: n - contentEndOffset,
+				Role:   markdownCodeBlockRole,
+			})
+		}
+
+		return parser.Result{
+			NumConsumed:    n,
+			ComputedTokens: tokens,
+			NextState:      state,
+		}
+	}
+}
+
+// markdownParseEmbedded runs an embedded language's parse func until it has consumed
+// all runes from the iterator, returning the tokens with offsets relative to the
+// start of the iterator.
+func markdownParseEmbedded(f parser.Func, iter parser.TrackingRuneIter, n uint64) []parser.ComputedToken {
+	var tokens []parser.ComputedToken
+	var pos uint64
+	state := parser.State(parser.EmptyState{})
+	for pos < n {
+		result := f(iter, state)
+		if result.IsFailure() {
+			// Recover by skipping one rune ahead.
+			if iter.Skip(1) == 0 {
+				break
+			}
+			pos++
+			continue
+		}
+
+		for _, tok := range result.ComputedTokens {
+			tok.Offset += pos
+			tokens = append(tokens, tok)
+		}
+
+		iter.Skip(result.NumConsumed)
+		pos += result.NumConsumed
+		state = result.NextState
 	}
+	return tokens
 }
 
 func markdownNumberListItemParseFunc() parser.Func {
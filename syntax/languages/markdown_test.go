@@ -71,6 +71,91 @@ func TestMarkdownParseFunc(t *testing.T) {
 	}
 }
 
+func TestMarkdownWithEmbeddedParseFunc(t *testing.T) {
+	lookupFn := func(language string) parser.Func {
+		if language == "go" {
+			return GolangParseFunc()
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []TokenWithText
+	}{
+		{
+			name: "code block with recognized language",
+			text: "```go\nvar x = 1\n```\nabcd",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```go\n"},
+				{Role: parser.TokenRoleKeyword, Text: "var"},
+				{Role: parser.TokenRoleOperator, Text: "="},
+				{Role: parser.TokenRoleNumber, Text: "1"},
+				{Role: markdownCodeBlockRole, Text: "```\n"},
+			},
+		},
+		{
+			name: "code block with recognized language and info string attributes",
+			text: "~~~ Go {.numberLines}\nreturn\n~~~",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "~~~ Go {.numberLines}\n"},
+				{Role: parser.TokenRoleKeyword, Text: "return"},
+				{Role: markdownCodeBlockRole, Text: "~~~"},
+			},
+		},
+		{
+			name: "code block with unrecognized language",
+			text: "```foo\nvar x = 1\n```",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```foo\nvar x = 1\n```"},
+			},
+		},
+		{
+			name: "code block without language",
+			text: "```\nvar x = 1\n```",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```\nvar x = 1\n```"},
+			},
+		},
+		{
+			name: "unterminated code block with recognized language",
+			text: "```go\nvar x = 1\n/* comment",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```go\n"},
+				{Role: parser.TokenRoleKeyword, Text: "var"},
+				{Role: parser.TokenRoleOperator, Text: "="},
+				{Role: parser.TokenRoleNumber, Text: "1"},
+				{Role: parser.TokenRoleOperator, Text: "/"},
+				{Role: parser.TokenRoleOperator, Text: "*"},
+			},
+		},
+		{
+			name: "empty code block with recognized language",
+			text: "```go\n```",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```go\n"},
+				{Role: markdownCodeBlockRole, Text: "```"},
+			},
+		},
+		{
+			name: "embedded tokens do not extend past closing fence",
+			text: "```go\n\"abc\n```\n\"def\"",
+			expected: []TokenWithText{
+				{Role: markdownCodeBlockRole, Text: "```go\n"},
+				{Role: markdownCodeBlockRole, Text: "```\n"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens := ParseTokensWithText(MarkdownWithEmbeddedParseFunc(lookupFn), tc.text)
+			assert.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
 func FuzzMarkdownParseFunc(f *testing.F) {
 	testCases, err := loadCommonmarkTests()
 	if err != nil {
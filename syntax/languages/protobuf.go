@@ -23,8 +23,7 @@ func protobufCommentParseFunc() parser.Func {
 	consumeLineComment := consumeString("//").
 		ThenMaybe(consumeToNextLineFeed)
 
-	consumeBlockComment := consumeString("/*").
-		Then(consumeToString("*/"))
+	consumeBlockComment := consumeDelimitedRegion("/*", "*/", false)
 
 	return consumeLineComment.
 		Or(consumeBlockComment).
@@ -40,8 +40,8 @@ func pythonStringLiteralParseFunc() parser.Func {
 	// Technically byte strings (prefix "b") should include only ASCII characters,
 	// but we accept non-ASCII.
 	consumeShortString := parseCStyleString('\'', false).Or(parseCStyleString('"', false))
-	consumeLongString := (consumeString(`"""`).Then(consumeToString(`"""`))).
-		Or(consumeString(`'''`).Then(consumeToString(`'''`)))
+	consumeLongString := consumeDelimitedRegion(`"""`, `"""`, false).
+		Or(consumeDelimitedRegion(`'''`, `'''`, false))
 	consumeLongOrShortString := consumeLongString.Or(consumeShortString)
 
 	return (consumeStringPrefix.Then(consumeLongOrShortString)).
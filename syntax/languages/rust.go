@@ -24,8 +24,7 @@ func rustCommentParseFunc() parser.Func {
 	// These rules implicitly covers the doc forms ("//!", "/*!", ...)
 	consumeLineComment := consumeString("//").
 		ThenMaybe(consumeToNextLineFeed)
-	consumeBlockComment := consumeString("/*").
-		Then(consumeToString("*/"))
+	consumeBlockComment := consumeDelimitedRegion("/*", "*/", true)
 	return consumeLineComment.
 		Or(consumeBlockComment).
 		Map(recognizeToken(parser.TokenRoleComment))
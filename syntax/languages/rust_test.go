@@ -70,6 +70,13 @@ func TestRustParseFunc(t *testing.T) {
 				{Text: "/*! foo \n bar */", Role: parser.TokenRoleComment},
 			},
 		},
+		{
+			name: "nested block comment",
+			text: "/* foo /* bar */ baz */",
+			expected: []TokenWithText{
+				{Text: "/* foo /* bar */ baz */", Role: parser.TokenRoleComment},
+			},
+		},
 		{
 			name: "character",
 			text: "'H'",
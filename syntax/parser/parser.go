@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"log"
 	"math"
+	"runtime/debug"
+	"time"
 
 	"github.com/aretext/aretext/text"
 )
@@ -59,16 +62,26 @@ func (r Result) ShiftForward(n uint64) Result {
 // P parses a document into tokens.
 // It caches the results from the last parse so it can efficiently
 // reparse a document after an edit (insertion/deletion).
+//
+// If the parse func panics or parsing takes longer than the time budget,
+// the rest of the document is left without tokens (displayed as plaintext)
+// so that a malformed document can't crash or hang the editor.
 type P struct {
 	parseFunc       Func
 	lastComputation *computation
+	timeBudget      time.Duration
 }
 
+// defaultTimeBudget is the maximum time to parse a document or reparse it after an edit.
+// The budget is checked after each invocation of the parse func, so a parse func
+// that never returns can still hang, but one that makes slow progress cannot.
+const defaultTimeBudget = 2 * time.Second
+
 // New constructs a new parser for the language recognized by parseFunc.
 func New(f Func) *P {
 	// This ensures that the parse func always makes progress.
 	f = f.recoverFromFailure()
-	return &P{parseFunc: f}
+	return &P{parseFunc: f, timeBudget: defaultTimeBudget}
 }
 
 // TokenAtPosition returns the token containing a position.
@@ -92,8 +105,14 @@ func (p *P) ParseAll(tree *text.Tree) {
 	state := State(EmptyState{})
 	leafComputations := make([]*computation, 0)
 	n := tree.NumChars()
+	deadline := time.Now().Add(p.timeBudget)
 	for pos < n {
-		c := p.runParseFunc(tree, pos, state)
+		var c *computation
+		if time.Now().After(deadline) {
+			c = p.fallbackAfterTimeout(tree, pos, state)
+		} else {
+			c = p.runParseFunc(tree, pos, state)
+		}
 		pos += c.ConsumedLength()
 		state = c.EndState()
 
@@ -130,10 +149,15 @@ func (p *P) ReparseAfterEdit(tree *text.Tree, edit Edit) {
 	var c *computation
 	state := State(EmptyState{})
 	n := tree.NumChars()
+	deadline := time.Now().Add(p.timeBudget)
 	for pos < n {
 		nextComputation := p.findReusableComputation(pos, edit, state)
 		if nextComputation == nil {
-			nextComputation = p.runParseFunc(tree, pos, state)
+			if time.Now().After(deadline) {
+				nextComputation = p.fallbackAfterTimeout(tree, pos, state)
+			} else {
+				nextComputation = p.runParseFunc(tree, pos, state)
+			}
 		}
 		state = nextComputation.EndState()
 		pos += nextComputation.ConsumedLength()
@@ -142,7 +166,14 @@ func (p *P) ReparseAfterEdit(tree *text.Tree, edit Edit) {
 	p.lastComputation = c
 }
 
-func (p *P) runParseFunc(tree *text.Tree, pos uint64, state State) *computation {
+func (p *P) runParseFunc(tree *text.Tree, pos uint64, state State) (c *computation) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Syntax parser panicked at position %d, so the rest of the document will not be highlighted: %v\n%s\n", pos, r, debug.Stack())
+			c = fallbackComputation(tree, pos, state)
+		}
+	}()
+
 	reader := tree.ReaderAtPosition(pos)
 	trackingIter := NewTrackingRuneIter(reader)
 	result := p.parseFunc(trackingIter, state)
@@ -155,6 +186,17 @@ func (p *P) runParseFunc(tree *text.Tree, pos uint64, state State) *computation
 	)
 }
 
+func (p *P) fallbackAfterTimeout(tree *text.Tree, pos uint64, state State) *computation {
+	log.Printf("Syntax parser exceeded time budget of %s at position %d, so the rest of the document will not be highlighted\n", p.timeBudget, pos)
+	return fallbackComputation(tree, pos, state)
+}
+
+// fallbackComputation consumes the rest of the document without recognizing any tokens.
+func fallbackComputation(tree *text.Tree, pos uint64, state State) *computation {
+	n := tree.NumChars() - pos
+	return newComputation(n, n, state, state, nil)
+}
+
 func (p *P) findReusableComputation(pos uint64, edit Edit, state State) *computation {
 	if pos < edit.pos {
 		// If the parser is starting before the edit, look for a subcomputation
@@ -3,6 +3,7 @@ package parser
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -118,6 +119,64 @@ func TestRecoverFromFailure(t *testing.T) {
 	assert.Equal(t, 0, len(tokens))
 }
 
+func TestRecoverFromPanic(t *testing.T) {
+	// Panic on the '!' rune, otherwise recognize strings like simpleParseFunc.
+	panickingParseFunc := func(iter TrackingRuneIter, state State) Result {
+		lookaheadIter := iter
+		if r, err := lookaheadIter.NextRune(); err == nil && r == '!' {
+			panic("unexpected rune")
+		}
+		return simpleParseFunc(iter, state)
+	}
+
+	tree, err := text.NewTreeFromString(`"foo""bar""baz"`)
+	require.NoError(t, err)
+	p := New(panickingParseFunc)
+	p.ParseAll(tree)
+	assert.Equal(t, []Token{
+		{StartPos: 0, EndPos: 5, Role: TokenRoleString},
+		{StartPos: 5, EndPos: 10, Role: TokenRoleString},
+		{StartPos: 10, EndPos: 15, Role: TokenRoleString},
+	}, p.TokensIntersectingRange(0, math.MaxUint64))
+
+	// Insert a rune that causes a panic, so the rest of the document has no tokens.
+	err = tree.InsertAtPosition(5, '!')
+	require.NoError(t, err)
+	p.ReparseAfterEdit(tree, NewInsertEdit(5, 1))
+	assert.Equal(t, uint64(16), p.lastComputation.ConsumedLength())
+	assert.Equal(t, []Token{
+		{StartPos: 0, EndPos: 5, Role: TokenRoleString},
+	}, p.TokensIntersectingRange(0, math.MaxUint64))
+
+	// Delete the rune, so the parser recognizes the tokens again.
+	tree.DeleteAtPosition(5)
+	p.ReparseAfterEdit(tree, NewDeleteEdit(5, 1))
+	assert.Equal(t, []Token{
+		{StartPos: 0, EndPos: 5, Role: TokenRoleString},
+		{StartPos: 5, EndPos: 10, Role: TokenRoleString},
+		{StartPos: 10, EndPos: 15, Role: TokenRoleString},
+	}, p.TokensIntersectingRange(0, math.MaxUint64))
+}
+
+func TestExceedTimeBudget(t *testing.T) {
+	slowParseFunc := func(iter TrackingRuneIter, state State) Result {
+		time.Sleep(5 * time.Millisecond)
+		return simpleParseFunc(iter, state)
+	}
+
+	tree, err := text.NewTreeFromString(`"foo""bar""baz"`)
+	require.NoError(t, err)
+	p := New(slowParseFunc)
+	p.timeBudget = time.Millisecond
+	p.ParseAll(tree)
+
+	// The first invocation starts within the budget, then the rest of the document has no tokens.
+	assert.Equal(t, uint64(15), p.lastComputation.ConsumedLength())
+	assert.Equal(t, []Token{
+		{StartPos: 0, EndPos: 5, Role: TokenRoleString},
+	}, p.TokensIntersectingRange(0, math.MaxUint64))
+}
+
 func TestReparseAfterEditInsertion(t *testing.T) {
 	testCases := []struct {
 		name           string
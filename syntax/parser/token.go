@@ -1,5 +1,7 @@
 package parser
 
+import "fmt"
+
 // TokenRole represents the role a token plays in a document,
 // as interpreted in a particular syntax language.
 type TokenRole int
@@ -32,6 +34,28 @@ const (
 	TokenRoleCustom16
 )
 
+// String returns the name of the role, such as "keyword" or "custom1".
+func (r TokenRole) String() string {
+	switch {
+	case r == TokenRoleNone:
+		return "none"
+	case r == TokenRoleOperator:
+		return "operator"
+	case r == TokenRoleKeyword:
+		return "keyword"
+	case r == TokenRoleNumber:
+		return "number"
+	case r == TokenRoleString:
+		return "string"
+	case r == TokenRoleComment:
+		return "comment"
+	case r >= TokenRoleCustom1 && r <= TokenRoleCustom16:
+		return fmt.Sprintf("custom%d", r-TokenRoleCustom1+1)
+	default:
+		return fmt.Sprintf("TokenRole(%d)", int(r))
+	}
+}
+
 // Token represents a distinct element in a document.
 type Token struct {
 	Role     TokenRole
@@ -45,7 +45,7 @@ func init() {
 		LanguageGitRebase:    languages.GitRebaseParseFunc(),
 		LanguageProtobuf:     languages.ProtobufParseFunc(),
 		LanguageTodoTxt:      languages.TodoTxtParseFunc(),
-		LanguageMarkdown:     languages.MarkdownParseFunc(),
+		LanguageMarkdown:     languages.MarkdownWithEmbeddedParseFunc(embeddedParseFuncForLanguage),
 		LanguageCriticMarkup: languages.CriticMarkupParseFunc(),
 	}
 
@@ -54,6 +54,44 @@ func init() {
 	}
 }
 
+// languageToLineComment maps each language with line comments to the marker that starts a comment.
+var languageToLineComment = map[Language]string{
+	LanguageYaml:     "#",
+	LanguageGo:       "//",
+	LanguagePython:   "#",
+	LanguageRust:     "//",
+	LanguageC:        "//",
+	LanguageProtobuf: "//",
+}
+
+// LineCommentForLanguage returns the marker that starts a line comment in a language.
+// If the language does not have line comments, this returns an empty string.
+func LineCommentForLanguage(language Language) string {
+	return languageToLineComment[language]
+}
+
+// embeddedLanguageAliases maps alternative names for languages
+// (for example, in a markdown code fence info string) to a Language.
+var embeddedLanguageAliases = map[string]Language{
+	"golang": LanguageGo,
+	"py":     LanguagePython,
+	"rs":     LanguageRust,
+	"h":      LanguageC,
+	"yml":    LanguageYaml,
+	"proto":  LanguageProtobuf,
+	"md":     LanguageMarkdown,
+}
+
+// embeddedParseFuncForLanguage returns the parse func for a language embedded in another document.
+// If the language is not recognized, this returns nil.
+func embeddedParseFuncForLanguage(name string) parser.Func {
+	language := Language(name)
+	if alias, ok := embeddedLanguageAliases[name]; ok {
+		language = alias
+	}
+	return languageToParseFunc[language]
+}
+
 // ParseForLanguage creates a parser for a syntax language.
 // If no parser is available (e.g. for LanguagePlaintext) this returns nil.
 func ParserForLanguage(language Language) *parser.P {
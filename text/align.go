@@ -22,6 +22,11 @@ func Align(leftReader, rightReader io.Reader) ([]LineMatch, error) {
 		return nil, err
 	}
 
+	return alignLineHashes(leftLineHashes, rightLineHashes), nil
+}
+
+// alignLineHashes matches lines in the left document with identical lines in the right document.
+func alignLineHashes(leftLineHashes, rightLineHashes []lineHash) []LineMatch {
 	leftLineCount, rightLineCount := uint64(len(leftLineHashes)), uint64(len(rightLineHashes))
 
 	if allLineHashesMatch(leftLineHashes, rightLineHashes) {
@@ -30,7 +35,7 @@ func Align(leftReader, rightReader io.Reader) ([]LineMatch, error) {
 		for i := 0; i < len(leftLineHashes); i++ {
 			matches[i] = LineMatch{LeftLineNum: uint64(i), RightLineNum: uint64(i)}
 		}
-		return matches, nil
+		return matches
 	}
 
 	// Align lines that occur exactly once in each document.
@@ -68,7 +73,7 @@ func Align(leftReader, rightReader io.Reader) ([]LineMatch, error) {
 		}
 	}
 
-	return matches, nil
+	return matches
 }
 
 // lineHash represents a hash of a line in a document.
@@ -0,0 +1,65 @@
+package text
+
+import (
+	"io"
+)
+
+// DiffHunk represents a contiguous range of lines that differ between two documents.
+// A hunk with zero left lines represents lines added to the right document,
+// and a hunk with zero right lines represents lines removed from the left document.
+type DiffHunk struct {
+	LeftStartLineNum  uint64
+	LeftNumLines      uint64
+	RightStartLineNum uint64
+	RightNumLines     uint64
+}
+
+// Diff returns the hunks of lines that differ between the left and right documents.
+// Hunks are ordered ascending by line number.
+// Lines are compared including the line feed, so a final line without a line feed
+// does not match the same line with a line feed.
+func Diff(leftReader, rightReader io.Reader) ([]DiffHunk, error) {
+	leftLineHashes, rightLineHashes, err := hashLeftAndRightLines(leftReader, rightReader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lines that match at the start and end of the documents are always aligned,
+	// even if they are not unique within each document.
+	var prefixLen int
+	for prefixLen < len(leftLineHashes) && prefixLen < len(rightLineHashes) && leftLineHashes[prefixLen] == rightLineHashes[prefixLen] {
+		prefixLen++
+	}
+
+	var suffixLen int
+	for suffixLen < len(leftLineHashes)-prefixLen && suffixLen < len(rightLineHashes)-prefixLen && leftLineHashes[len(leftLineHashes)-suffixLen-1] == rightLineHashes[len(rightLineHashes)-suffixLen-1] {
+		suffixLen++
+	}
+
+	leftMiddle := leftLineHashes[prefixLen : len(leftLineHashes)-suffixLen]
+	rightMiddle := rightLineHashes[prefixLen : len(rightLineHashes)-suffixLen]
+
+	// Every line between two consecutive matches belongs to a hunk.
+	var hunks []DiffHunk
+	var leftLineNum, rightLineNum uint64
+	addHunkBefore := func(leftMatchLineNum, rightMatchLineNum uint64) {
+		if leftMatchLineNum > leftLineNum || rightMatchLineNum > rightLineNum {
+			hunks = append(hunks, DiffHunk{
+				LeftStartLineNum:  leftLineNum + uint64(prefixLen),
+				LeftNumLines:      leftMatchLineNum - leftLineNum,
+				RightStartLineNum: rightLineNum + uint64(prefixLen),
+				RightNumLines:     rightMatchLineNum - rightLineNum,
+			})
+		}
+		leftLineNum, rightLineNum = leftMatchLineNum+1, rightMatchLineNum+1
+	}
+
+	if len(leftMiddle) > 0 && len(rightMiddle) > 0 {
+		for _, match := range alignLineHashes(leftMiddle, rightMiddle) {
+			addHunkBefore(match.LeftLineNum, match.RightLineNum)
+		}
+	}
+	addHunkBefore(uint64(len(leftMiddle)), uint64(len(rightMiddle)))
+
+	return hunks, nil
+}
@@ -0,0 +1,105 @@
+package text
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name      string
+		leftText  string
+		rightText string
+		expected  []DiffHunk
+	}{
+		{
+			name:      "both empty",
+			leftText:  "",
+			rightText: "",
+			expected:  nil,
+		},
+		{
+			name:      "identical",
+			leftText:  "a\nb\nc\n",
+			rightText: "a\nb\nc\n",
+			expected:  nil,
+		},
+		{
+			name:      "left empty",
+			leftText:  "",
+			rightText: "a\nb\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 0, LeftNumLines: 0, RightStartLineNum: 0, RightNumLines: 2},
+			},
+		},
+		{
+			name:      "right empty",
+			leftText:  "a\nb\n",
+			rightText: "",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 0, LeftNumLines: 2, RightStartLineNum: 0, RightNumLines: 0},
+			},
+		},
+		{
+			name:      "line added in middle",
+			leftText:  "a\nb\nc\n",
+			rightText: "a\nb\nx\nc\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 2, LeftNumLines: 0, RightStartLineNum: 2, RightNumLines: 1},
+			},
+		},
+		{
+			name:      "line added at end",
+			leftText:  "a\nb\n",
+			rightText: "a\nb\nc\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 2, LeftNumLines: 0, RightStartLineNum: 2, RightNumLines: 1},
+			},
+		},
+		{
+			name:      "line removed",
+			leftText:  "a\nb\nc\n",
+			rightText: "a\nc\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 1, RightStartLineNum: 1, RightNumLines: 0},
+			},
+		},
+		{
+			name:      "line modified",
+			leftText:  "a\nb\nc\n",
+			rightText: "a\nx\nc\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 1, RightStartLineNum: 1, RightNumLines: 1},
+			},
+		},
+		{
+			name:      "multiple hunks",
+			leftText:  "a\nb\nc\nd\ne\nf\n",
+			rightText: "a\nx\nc\nd\nf\ny\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 1, LeftNumLines: 1, RightStartLineNum: 1, RightNumLines: 1},
+				{LeftStartLineNum: 4, LeftNumLines: 1, RightStartLineNum: 4, RightNumLines: 0},
+				{LeftStartLineNum: 6, LeftNumLines: 0, RightStartLineNum: 5, RightNumLines: 1},
+			},
+		},
+		{
+			name:      "repeated lines",
+			leftText:  "}\n}\n}\n",
+			rightText: "}\n}\n\n}\n",
+			expected: []DiffHunk{
+				{LeftStartLineNum: 2, LeftNumLines: 0, RightStartLineNum: 2, RightNumLines: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hunks, err := Diff(strings.NewReader(tc.leftText), strings.NewReader(tc.rightText))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hunks)
+		})
+	}
+}
@@ -175,6 +175,64 @@ func (t *Tree) DeleteAtPosition(charPos uint64) (bool, rune) {
 	return didDelete, r
 }
 
+// Edit replaces characters in the tree. It is used with ApplyEdits.
+type Edit struct {
+	Pos        uint64 // Position (0-indexed) in the text before any edit in the batch is applied.
+	NumDeleted uint64 // Number of characters to delete, starting at Pos.
+	Inserted   string // UTF-8 text to insert at Pos.
+}
+
+// ApplyEdits applies a batch of edits, rebuilding the tree in a single pass over the text.
+// Every edit's position refers to the original text, so the edits must be sorted by position and must not overlap.
+// Deletions past the end of the text are truncated, and insertions past the end are appended at the end.
+// This takes time proportional to the length of the text, so it is faster than
+// InsertAtPosition and DeleteAtPosition only when there are many edits, such as replacing every match in a document.
+// Returns an error if the edits are unsorted or overlapping, or if the inserted text is invalid UTF-8.
+// If an error occurs, the tree is unchanged.
+func (t *Tree) ApplyEdits(edits []Edit) error {
+	for i := 1; i < len(edits); i++ {
+		if edits[i].Pos < edits[i-1].Pos+edits[i-1].NumDeleted {
+			return errors.New("edits must be sorted by position and must not overlap")
+		}
+	}
+
+	// Decoding the text as a string is much faster than reading it one rune at a time from the tree.
+	text := t.String()
+	var sb strings.Builder
+	sb.Grow(len(text))
+
+	// Advance through the original text, returning the byte offset of a character position.
+	var charPos uint64
+	var byteOffset int
+	advanceTo := func(pos uint64) int {
+		for charPos < pos && byteOffset < len(text) {
+			if text[byteOffset] < utf8.RuneSelf {
+				byteOffset++
+			} else {
+				_, size := utf8.DecodeRuneInString(text[byteOffset:])
+				byteOffset += size
+			}
+			charPos++
+		}
+		return byteOffset
+	}
+
+	for _, edit := range edits {
+		prevOffset := byteOffset // Read before advanceTo changes it.
+		sb.WriteString(text[prevOffset:advanceTo(edit.Pos)])
+		sb.WriteString(edit.Inserted)
+		advanceTo(edit.Pos + edit.NumDeleted)
+	}
+	sb.WriteString(text[byteOffset:])
+
+	newTree, err := NewTreeFromString(sb.String())
+	if err != nil {
+		return err
+	}
+	t.root = newTree.root
+	return nil
+}
+
 // ReaderAtPosition returns a reader starting at the UTF-8 character at the specified position (0-indexed).
 // If the position is past the end of the text, the returned reader will read zero bytes.
 func (t *Tree) ReaderAtPosition(charPos uint64) Reader {
@@ -1,6 +1,7 @@
 package text
 
 import (
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -1148,6 +1149,156 @@ func TestDeleteNewline(t *testing.T) {
 	assert.Equal(t, expectLineStart, lineStart)
 }
 
+func TestApplyEdits(t *testing.T) {
+	testCases := []struct {
+		name       string
+		inputText  string
+		edits      []Edit
+		expectText string
+	}{
+		{
+			name:       "no edits",
+			inputText:  "abc",
+			edits:      nil,
+			expectText: "abc",
+		},
+		{
+			name:       "insert into empty",
+			inputText:  "",
+			edits:      []Edit{{Pos: 0, Inserted: "xyz"}},
+			expectText: "xyz",
+		},
+		{
+			name:      "insert and delete",
+			inputText: "abcdef",
+			edits: []Edit{
+				{Pos: 0, Inserted: "x"},
+				{Pos: 2, NumDeleted: 2},
+				{Pos: 5, NumDeleted: 1, Inserted: "yz"},
+			},
+			expectText: "xabeyz",
+		},
+		{
+			name:      "multiple inserts at the same position",
+			inputText: "ab",
+			edits: []Edit{
+				{Pos: 1, Inserted: "x"},
+				{Pos: 1, Inserted: "y"},
+			},
+			expectText: "axyb",
+		},
+		{
+			name:      "adjacent deletes",
+			inputText: "abcdef",
+			edits: []Edit{
+				{Pos: 1, NumDeleted: 2, Inserted: "X"},
+				{Pos: 3, NumDeleted: 2, Inserted: "Y"},
+			},
+			expectText: "aXYf",
+		},
+		{
+			name:      "multi-byte characters",
+			inputText: "a£b£c",
+			edits: []Edit{
+				{Pos: 1, NumDeleted: 1, Inserted: "€"},
+				{Pos: 3, NumDeleted: 1},
+			},
+			expectText: "a€bc",
+		},
+		{
+			name:      "newlines",
+			inputText: "ab\ncd\nef",
+			edits: []Edit{
+				{Pos: 2, NumDeleted: 1},
+				{Pos: 5, Inserted: "\n\n"},
+			},
+			expectText: "abcd\n\n\nef",
+		},
+		{
+			name:      "past end",
+			inputText: "abc",
+			edits: []Edit{
+				{Pos: 2, NumDeleted: 5},
+				{Pos: 10, Inserted: "x"},
+			},
+			expectText: "abx",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := NewTreeFromString(tc.inputText)
+			require.NoError(t, err)
+			err = tree.ApplyEdits(tc.edits)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectText, tree.String())
+			assert.Equal(t, uint64(strings.Count(tc.expectText, "\n")+1), tree.NumLines())
+		})
+	}
+}
+
+func TestApplyEditsMatchesSequentialEdits(t *testing.T) {
+	text := lines(1024, 50)
+	batchTree, err := NewTreeFromString(text)
+	require.NoError(t, err)
+	seqTree, err := NewTreeFromString(text)
+	require.NoError(t, err)
+
+	// Replace the second and third characters of every line.
+	var edits []Edit
+	for lineNum := uint64(0); lineNum < 1024; lineNum++ {
+		edits = append(edits, Edit{Pos: lineNum*51 + 1, NumDeleted: 2, Inserted: "xyz"})
+	}
+	err = batchTree.ApplyEdits(edits)
+	require.NoError(t, err)
+
+	// Apply the same edits one character at a time, starting from the end so the positions stay valid.
+	for i := len(edits) - 1; i >= 0; i-- {
+		for j := uint64(0); j < edits[i].NumDeleted; j++ {
+			seqTree.DeleteAtPosition(edits[i].Pos)
+		}
+		for j, r := range []rune(edits[i].Inserted) {
+			err := seqTree.InsertAtPosition(edits[i].Pos+uint64(j), r)
+			require.NoError(t, err)
+		}
+	}
+
+	assert.Equal(t, seqTree.String(), batchTree.String())
+	for _, lineNum := range []uint64{0, 1, 500, 1023} {
+		assert.Equal(t, seqTree.LineStartPosition(lineNum), batchTree.LineStartPosition(lineNum))
+	}
+}
+
+func TestApplyEditsInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		edits []Edit
+	}{
+		{
+			name:  "unsorted",
+			edits: []Edit{{Pos: 2, Inserted: "x"}, {Pos: 1, Inserted: "y"}},
+		},
+		{
+			name:  "overlapping",
+			edits: []Edit{{Pos: 0, NumDeleted: 2}, {Pos: 1, NumDeleted: 1}},
+		},
+		{
+			name:  "invalid UTF-8",
+			edits: []Edit{{Pos: 1, Inserted: "\xff"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := NewTreeFromString("abc")
+			require.NoError(t, err)
+			err = tree.ApplyEdits(tc.edits)
+			assert.Error(t, err)
+			assert.Equal(t, "abc", tree.String())
+		})
+	}
+}
+
 func TestNodeSplit(t *testing.T) {
 	s := Repeat('x', 1339)
 	tree, err := NewTreeFromString(s)
@@ -1282,3 +1433,61 @@ func BenchmarkInsert(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkApplyEdits(b *testing.B) {
+	benchmarks := []struct {
+		name     string
+		numLines int
+	}{
+		{name: "small", numLines: 100},
+		{name: "medium", numLines: 1000},
+		{name: "large", numLines: 10000},
+	}
+
+	for _, bm := range benchmarks {
+		// Replace two characters with three in every line, like substituting every match of a search query.
+		text := lines(bm.numLines, 50)
+		edits := make([]Edit, 0, bm.numLines)
+		for lineNum := 0; lineNum < bm.numLines; lineNum++ {
+			edits = append(edits, Edit{Pos: uint64(lineNum*51 + 1), NumDeleted: 2, Inserted: "xyz"})
+		}
+
+		b.Run(fmt.Sprintf("%s/batch", bm.name), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				tree, err := NewTreeFromString(text)
+				if err != nil {
+					b.Fatalf("err = %v", err)
+				}
+				b.StartTimer()
+				if err := tree.ApplyEdits(edits); err != nil {
+					b.Fatalf("err = %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/per-op", bm.name), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				tree, err := NewTreeFromString(text)
+				if err != nil {
+					b.Fatalf("err = %v", err)
+				}
+				b.StartTimer()
+				var offset uint64
+				for _, edit := range edits {
+					pos := edit.Pos + offset
+					for i := uint64(0); i < edit.NumDeleted; i++ {
+						tree.DeleteAtPosition(pos)
+					}
+					for i, r := range []rune(edit.Inserted) {
+						if err := tree.InsertAtPosition(pos+uint64(i), r); err != nil {
+							b.Fatalf("err = %v", err)
+						}
+					}
+					offset += uint64(len(edit.Inserted)) - edit.NumDeleted
+				}
+			}
+		})
+	}
+}
@@ -1,99 +1,217 @@
 package undo
 
-// logEntry represents an entry in the undo log.
-// Some entries are "checkpoints" that partition entries into groups that can be undone/redone together.
-type logEntry struct {
-	checkpoint bool
-	op         Op
+import (
+	"sort"
+	"time"
+)
+
+// stateNode is a document state in the undo tree.
+// Each node other than the root stores the operations that transform its parent's state into its own state.
+// Undoing a change and then making a new edit adds a new branch to the tree, so no state is ever lost.
+type stateNode struct {
+	seq       int // Sequence number, increasing in the order states were created. The root is zero.
+	time      time.Time
+	parent    *stateNode
+	children  []*stateNode
+	redoChild *stateNode // The child to restore on redo, which is the most recently visited branch.
+	ops       []Op
 }
 
 // Log tracks changes to a document and generates undo/redo operations.
+// Changes are grouped by "checkpoints" into steps that can be undone/redone together,
+// and each step creates a new state in a tree like vim's undo tree.
 type Log struct {
-	// entries[0:numUndoEntries] are changes made before the current document state.  These changes can be undone.
-	// entries[numUndoEntries:len(entries)-1] are changes made after the current document state.  These changes can be redone.
-	entries              []logEntry
-	numUndoEntries       int
-	numEntriesAtLastSave int
+	nodes      []*stateNode // Indexed by sequence number.
+	current    *stateNode
+	pendingOps []Op // Changes since the last checkpoint, not yet committed to a state.
+	savedNode  *stateNode
 }
 
 // NewLog constructs a new, empty undo log.
 func NewLog() *Log {
-	return &Log{
-		entries:              make([]logEntry, 0, 256),
-		numUndoEntries:       0,
-		numEntriesAtLastSave: 0,
-	}
+	l := &Log{}
+	l.TrackLoad()
+	return l
 }
 
 // TrackOp tracks a change to the document.
-// This reverts any changes in the redo log, then appends the new, uncommitted change.
+// The change is added to the current step until the next checkpoint.
 func (l *Log) TrackOp(op Op) {
-	// Revert all changes from the redo log.
-	// This differs from vim, which discards all changes in the redo log.
-	// Instead, we're following the approach from
-	// "Resolving the Great Undo-Redo Quandary"
-	// (https://github.com/zaboople/klonk/blob/master/TheGURQ.md)
-	// to allow restoration of changes in the redo log.
-	for i := len(l.entries) - 1; i >= l.numUndoEntries; i-- {
-		revertOp := l.entries[i].op.Inverse()
-		l.entries = append(l.entries, logEntry{op: revertOp})
-	}
-	l.numUndoEntries = len(l.entries)
-
-	// Append a new undo entry.
-	l.entries = append(l.entries, logEntry{op: op})
-	l.numUndoEntries++
+	l.pendingOps = append(l.pendingOps, op)
 }
 
 // TrackLoad removes all changes and resets the savepoint.
 func (l *Log) TrackLoad() {
-	l.entries = l.entries[:0]
-	l.numUndoEntries = 0
-	l.numEntriesAtLastSave = 0
+	root := &stateNode{seq: 0, time: time.Now()}
+	l.nodes = []*stateNode{root}
+	l.current = root
+	l.pendingOps = nil
+	l.savedNode = root
 }
 
-// TrackSave moves the savepoint to the current entry.
+// TrackSave moves the savepoint to the current state.
 func (l *Log) TrackSave() {
-	l.numEntriesAtLastSave = l.numUndoEntries
+	l.Checkpoint()
+	l.savedNode = l.current
 }
 
-// Checkpoint marks the current entry as a checkpoint.
+// Checkpoint commits changes since the last checkpoint as a new state in the undo tree.
+// If there are no changes since the last checkpoint, this does nothing.
 func (l *Log) Checkpoint() {
-	if l.numUndoEntries == 0 {
+	if len(l.pendingOps) == 0 {
 		return
 	}
-	l.entries[l.numUndoEntries-1].checkpoint = true
+
+	node := &stateNode{
+		seq:    len(l.nodes),
+		time:   time.Now(),
+		parent: l.current,
+		ops:    l.pendingOps,
+	}
+	l.current.children = append(l.current.children, node)
+	l.current.redoChild = node
+	l.nodes = append(l.nodes, node)
+	l.current = node
+	l.pendingOps = nil
 }
 
 // UndoToLastCheckpoint returns operations to transform the document back to its state at the previous checkpoint.
-// It also moves the current position backwards in the log.
+// It also moves the current state to its parent in the undo tree.
 func (l *Log) UndoToLastCheckpoint() []Op {
-	var ops []Op
-	for i := l.numUndoEntries - 1; i >= 0; i-- {
-		if i < l.numUndoEntries-1 && l.entries[i].checkpoint {
-			break
-		}
-		ops = append(ops, l.entries[i].op.Inverse())
+	l.Checkpoint()
+	if l.current.parent == nil {
+		return nil
 	}
-	l.numUndoEntries -= len(ops)
+	ops := inverseOps(l.current.ops)
+	l.current.parent.redoChild = l.current
+	l.current = l.current.parent
 	return ops
 }
 
 // RedoToNextCheckpoint returns operations to to transform the document to its state at the next checkpoint.
-// It also moves the current position forward in the log.
+// If the current state has multiple branches, this follows the most recently visited branch.
 func (l *Log) RedoToNextCheckpoint() []Op {
-	var ops []Op
-	for i := l.numUndoEntries; i < len(l.entries); i++ {
-		ops = append(ops, l.entries[i].op)
-		if i > l.numUndoEntries && l.entries[i].checkpoint {
+	l.Checkpoint()
+	child := l.current.redoChild
+	if child == nil {
+		return nil
+	}
+	ops := append([]Op(nil), child.ops...)
+	l.current = child
+	return ops
+}
+
+// MoveToState returns operations to transform the document to the state with the given sequence number,
+// which may be on a different branch of the undo tree. It also moves the current state to that state.
+// If no state has the sequence number, this returns nil.
+func (l *Log) MoveToState(seq int) []Op {
+	l.Checkpoint()
+	if seq < 0 || seq >= len(l.nodes) {
+		return nil
+	}
+
+	target := l.nodes[seq]
+
+	// Find the common ancestor of the current and target states.
+	ancestors := make(map[*stateNode]struct{})
+	for n := l.current; n != nil; n = n.parent {
+		ancestors[n] = struct{}{}
+	}
+	var redoPath []*stateNode
+	commonAncestor := target
+	for {
+		if _, ok := ancestors[commonAncestor]; ok {
 			break
 		}
+		redoPath = append(redoPath, commonAncestor)
+		commonAncestor = commonAncestor.parent
 	}
-	l.numUndoEntries += len(ops)
+
+	// Undo back to the common ancestor, then redo forward to the target.
+	var ops []Op
+	for n := l.current; n != commonAncestor; n = n.parent {
+		ops = append(ops, inverseOps(n.ops)...)
+		n.parent.redoChild = n
+	}
+	for i := len(redoPath) - 1; i >= 0; i-- {
+		n := redoPath[i]
+		ops = append(ops, n.ops...)
+		n.parent.redoChild = n
+	}
+
+	l.current = target
 	return ops
 }
 
+// CurrentSeq returns the sequence number of the current state.
+func (l *Log) CurrentSeq() int {
+	return l.current.seq
+}
+
+// NumStates returns the number of states in the undo tree, including the initial state.
+func (l *Log) NumStates() int {
+	return len(l.nodes)
+}
+
 // HasUnsavedChanges returns whether the log has unsaved changes.
 func (l *Log) HasUnsavedChanges() bool {
-	return l.numUndoEntries != l.numEntriesAtLastSave
+	return len(l.pendingOps) > 0 || l.current != l.savedNode
+}
+
+// Node is a read-only view of a state in the undo tree.
+type Node struct {
+	Seq      int       // Sequence number, increasing in the order states were created. The root is zero.
+	Time     time.Time // When the state was created.
+	Depth    int       // Number of steps from the initial state.
+	Current  bool      // Whether the document is currently in this state.
+	Children []Node    // Branches from this state, ordered from oldest to newest.
+}
+
+// Tree returns a read-only copy of the undo tree, starting from the initial state.
+func (l *Log) Tree() Node {
+	return l.buildNode(l.nodes[0], 0)
+}
+
+func (l *Log) buildNode(n *stateNode, depth int) Node {
+	node := Node{
+		Seq:     n.seq,
+		Time:    n.time,
+		Depth:   depth,
+		Current: n == l.current,
+	}
+	for _, child := range n.children {
+		node.Children = append(node.Children, l.buildNode(child, depth+1))
+	}
+	return node
+}
+
+// Leaves returns the last state in each branch of the tree, ordered by sequence number.
+// If nothing has changed since the document was loaded, this returns only the initial state.
+func (n Node) Leaves() []Node {
+	var leaves []Node
+	n.appendLeaves(&leaves)
+	sort.Slice(leaves, func(i, j int) bool {
+		return leaves[i].Seq < leaves[j].Seq
+	})
+	return leaves
+}
+
+func (n Node) appendLeaves(leaves *[]Node) {
+	if len(n.Children) == 0 {
+		*leaves = append(*leaves, n)
+		return
+	}
+
+	for _, child := range n.Children {
+		child.appendLeaves(leaves)
+	}
+}
+
+// inverseOps returns operations that reverse the effect of ops, in reverse order.
+func inverseOps(ops []Op) []Op {
+	result := make([]Op, 0, len(ops))
+	for i := len(ops) - 1; i >= 0; i-- {
+		result = append(result, ops[i].Inverse())
+	}
+	return result
 }
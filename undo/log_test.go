@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUndoToLastCheckpoint(t *testing.T) {
@@ -34,19 +35,12 @@ func TestUndoToLastCheckpoint(t *testing.T) {
 	}
 	assert.Equal(t, expectedOps, ops)
 
+	// The edits after the first undo are on a separate branch of the undo tree,
+	// so undoing them returns to the initial state.
 	ops = log.UndoToLastCheckpoint()
 	expectedOps = []Op{
 		DeleteOp(4, "yz"),
 		InsertOp(3, "x"),
-		InsertOp(0, "a"),
-		InsertOp(1, "bc"),
-	}
-	assert.Equal(t, expectedOps, ops)
-
-	ops = log.UndoToLastCheckpoint()
-	expectedOps = []Op{
-		DeleteOp(1, "bc"),
-		DeleteOp(0, "a"),
 	}
 	assert.Equal(t, expectedOps, ops)
 
@@ -137,3 +131,140 @@ func TestTrackLoad(t *testing.T) {
 	assert.Equal(t, 0, len(log.UndoToLastCheckpoint()))
 	assert.Equal(t, 0, len(log.RedoToNextCheckpoint()))
 }
+
+func TestCheckpointWithoutOpsDoesNotCreateUndoStep(t *testing.T) {
+	log := NewLog()
+
+	// Checkpoints before any changes (for example, from cursor movements) are ignored.
+	log.Checkpoint()
+	log.Checkpoint()
+	log.TrackOp(InsertOp(0, "a"))
+	log.Checkpoint()
+
+	// Repeated checkpoints without changes in between do not create empty undo steps.
+	log.Checkpoint()
+	log.Checkpoint()
+	log.TrackOp(InsertOp(1, "b"))
+	log.Checkpoint()
+	log.Checkpoint()
+
+	ops := log.UndoToLastCheckpoint()
+	assert.Equal(t, []Op{DeleteOp(1, "b")}, ops)
+
+	// Checkpoints after undo do not split or discard the redo log.
+	log.Checkpoint()
+	ops = log.UndoToLastCheckpoint()
+	assert.Equal(t, []Op{DeleteOp(0, "a")}, ops)
+
+	log.Checkpoint()
+	ops = log.UndoToLastCheckpoint()
+	assert.Equal(t, 0, len(ops))
+
+	ops = log.RedoToNextCheckpoint()
+	assert.Equal(t, []Op{InsertOp(0, "a")}, ops)
+	ops = log.RedoToNextCheckpoint()
+	assert.Equal(t, []Op{InsertOp(1, "b")}, ops)
+}
+
+func TestRedoFollowsMostRecentBranch(t *testing.T) {
+	log := NewLog()
+	log.TrackOp(InsertOp(0, "a"))
+	log.Checkpoint()
+	log.UndoToLastCheckpoint()
+	log.TrackOp(InsertOp(0, "b"))
+	log.Checkpoint()
+	log.UndoToLastCheckpoint()
+
+	ops := log.RedoToNextCheckpoint()
+	assert.Equal(t, []Op{InsertOp(0, "b")}, ops)
+
+	// After moving to the older branch, redo follows that branch instead.
+	log.MoveToState(1)
+	log.UndoToLastCheckpoint()
+	ops = log.RedoToNextCheckpoint()
+	assert.Equal(t, []Op{InsertOp(0, "a")}, ops)
+}
+
+func TestMoveToStateRecoversOldBranch(t *testing.T) {
+	log := NewLog()
+
+	// State 1: "ab"
+	log.TrackOp(InsertOp(0, "ab"))
+	log.Checkpoint()
+
+	// State 2: "abc"
+	log.TrackOp(InsertOp(2, "c"))
+	log.Checkpoint()
+
+	// Undo to state 1, then diverge to state 3: "xab"
+	log.UndoToLastCheckpoint()
+	log.TrackOp(InsertOp(0, "x"))
+	log.Checkpoint()
+	assert.Equal(t, 3, log.CurrentSeq())
+	assert.Equal(t, 0, len(log.RedoToNextCheckpoint()))
+
+	// Move back to state 2 on the old branch.
+	ops := log.MoveToState(2)
+	expectedOps := []Op{
+		DeleteOp(0, "x"),
+		InsertOp(2, "c"),
+	}
+	assert.Equal(t, expectedOps, ops)
+	assert.Equal(t, 2, log.CurrentSeq())
+
+	// Move forward again to state 3 on the new branch.
+	ops = log.MoveToState(3)
+	expectedOps = []Op{
+		DeleteOp(2, "c"),
+		InsertOp(0, "x"),
+	}
+	assert.Equal(t, expectedOps, ops)
+	assert.Equal(t, 3, log.CurrentSeq())
+
+	// Move to the initial state.
+	ops = log.MoveToState(0)
+	expectedOps = []Op{
+		DeleteOp(0, "x"),
+		DeleteOp(0, "ab"),
+	}
+	assert.Equal(t, expectedOps, ops)
+
+	// Moving to the current state or an invalid state does nothing.
+	assert.Equal(t, 0, len(log.MoveToState(0)))
+	assert.Equal(t, 0, len(log.MoveToState(-1)))
+	assert.Equal(t, 0, len(log.MoveToState(4)))
+	assert.Equal(t, 0, log.CurrentSeq())
+}
+
+func TestTree(t *testing.T) {
+	log := NewLog()
+	log.TrackOp(InsertOp(0, "a"))
+	log.Checkpoint()
+	log.TrackOp(InsertOp(1, "b"))
+	log.Checkpoint()
+	log.UndoToLastCheckpoint()
+	log.UndoToLastCheckpoint()
+	log.TrackOp(InsertOp(0, "c"))
+	log.Checkpoint()
+
+	tree := log.Tree()
+	assert.Equal(t, 0, tree.Seq)
+	assert.False(t, tree.Current)
+	require.Equal(t, 2, len(tree.Children))
+
+	assert.Equal(t, 1, tree.Children[0].Seq)
+	assert.Equal(t, 1, tree.Children[0].Depth)
+	require.Equal(t, 1, len(tree.Children[0].Children))
+	assert.Equal(t, 2, tree.Children[0].Children[0].Seq)
+	assert.Equal(t, 2, tree.Children[0].Children[0].Depth)
+
+	assert.Equal(t, 3, tree.Children[1].Seq)
+	assert.Equal(t, 1, tree.Children[1].Depth)
+	assert.True(t, tree.Children[1].Current)
+
+	var leafSeqs []int
+	for _, leaf := range tree.Leaves() {
+		leafSeqs = append(leafSeqs, leaf.Seq)
+	}
+	assert.Equal(t, []int{2, 3}, leafSeqs)
+}
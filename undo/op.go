@@ -52,3 +52,48 @@ func (op Op) TextToInsert() string {
 func (op Op) NumRunesToDelete() int {
 	return utf8.RuneCountInString(op.deleteText)
 }
+
+// AffectedRange returns the range of positions changed by applying ops in order,
+// measured in the document after the last op is applied.
+// The range covers every inserted character that was not deleted by a later op.
+// A deletion leaves a zero-width range at the position where the deleted text was.
+// If ops is empty, ok is false.
+func AffectedRange(ops []Op) (startPos uint64, endPos uint64, ok bool) {
+	for _, op := range ops {
+		if n := uint64(utf8.RuneCountInString(op.insertText)); n > 0 {
+			shift := func(p uint64) uint64 {
+				if p >= op.pos {
+					return p + n
+				}
+				return p
+			}
+			startPos, endPos = shift(startPos), shift(endPos)
+			if !ok || op.pos < startPos {
+				startPos = op.pos
+			}
+			if !ok || op.pos+n > endPos {
+				endPos = op.pos + n
+			}
+		} else if n := uint64(op.NumRunesToDelete()); n > 0 {
+			shift := func(p uint64) uint64 {
+				if p <= op.pos {
+					return p
+				} else if p >= op.pos+n {
+					return p - n
+				}
+				return op.pos
+			}
+			startPos, endPos = shift(startPos), shift(endPos)
+			if !ok || op.pos < startPos {
+				startPos = op.pos
+			}
+			if !ok || op.pos > endPos {
+				endPos = op.pos
+			}
+		} else {
+			continue
+		}
+		ok = true
+	}
+	return startPos, endPos, ok
+}
@@ -0,0 +1,115 @@
+package undo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffectedRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ops           []Op
+		expectedOk    bool
+		expectedStart uint64
+		expectedEnd   uint64
+	}{
+		{
+			name:       "no ops",
+			ops:        nil,
+			expectedOk: false,
+		},
+		{
+			name:          "single insert",
+			ops:           []Op{InsertOp(3, "abc")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   6,
+		},
+		{
+			name:          "single delete",
+			ops:           []Op{DeleteOp(3, "abc")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   3,
+		},
+		{
+			name:          "consecutive inserts",
+			ops:           []Op{InsertOp(3, "ab"), InsertOp(5, "cd")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   7,
+		},
+		{
+			name:          "insert before earlier insert shifts it",
+			ops:           []Op{InsertOp(10, "xy"), InsertOp(2, "abc")},
+			expectedOk:    true,
+			expectedStart: 2,
+			expectedEnd:   15,
+		},
+		{
+			name:          "insert inside earlier insert",
+			ops:           []Op{InsertOp(3, "abcd"), InsertOp(5, "x")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   8,
+		},
+		{
+			name:          "delete after insert",
+			ops:           []Op{InsertOp(3, "abc"), DeleteOp(10, "xy")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   10,
+		},
+		{
+			name:          "delete part of earlier insert",
+			ops:           []Op{InsertOp(3, "abcd"), DeleteOp(4, "bc")},
+			expectedOk:    true,
+			expectedStart: 3,
+			expectedEnd:   5,
+		},
+		{
+			name:          "delete before earlier insert shifts it",
+			ops:           []Op{InsertOp(10, "abc"), DeleteOp(2, "xy")},
+			expectedOk:    true,
+			expectedStart: 2,
+			expectedEnd:   11,
+		},
+		{
+			name:          "multi-byte characters count as one position",
+			ops:           []Op{InsertOp(0, "äöü")},
+			expectedOk:    true,
+			expectedStart: 0,
+			expectedEnd:   3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			startPos, endPos, ok := AffectedRange(tc.ops)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedStart, startPos)
+			assert.Equal(t, tc.expectedEnd, endPos)
+		})
+	}
+}
+
+func TestAffectedRangeOfUndo(t *testing.T) {
+	// Replace "bc" in "abcd" with "xyz", as a visual mode change would.
+	log := NewLog()
+	log.TrackOp(DeleteOp(1, "bc"))
+	log.TrackOp(InsertOp(1, "xyz"))
+	log.Checkpoint()
+
+	// Undo deletes "xyz" and restores "bc", so the affected range is "bc".
+	startPos, endPos, ok := AffectedRange(log.UndoToLastCheckpoint())
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), startPos)
+	assert.Equal(t, uint64(3), endPos)
+
+	// Redo deletes "bc" and inserts "xyz", so the affected range is "xyz".
+	startPos, endPos, ok = AffectedRange(log.RedoToNextCheckpoint())
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), startPos)
+	assert.Equal(t, uint64(4), endPos)
+}